@@ -44,13 +44,15 @@ func (dst *Perses) ConvertFrom(srcRaw conv.Hub) error {
 // Convert_v1alpha2_PersesSpec_To_v1alpha1_PersesSpec converts a PersesSpec from v1alpha2 to v1alpha1.
 func Convert_v1alpha2_PersesSpec_To_v1alpha1_PersesSpec(in *v1alpha2.PersesSpec, out *PersesSpec, s conversion.Scope) error {
 	// NOTE: The following v1alpha2 fields are not supported in v1alpha1 and will be dropped during conversion:
-	// PodSecurityContext, LogLevel, LogMethodTrace, Provisioning, Volumes, VolumeMounts, Env, EnvFrom, PriorityClassName
+	// PodSecurityContext, LogLevel, LogMethodTrace, Provisioning, Volumes, VolumeMounts, Env, EnvFrom, PriorityClassName,
+	// ResourceNamespaceSelector, ResourceSelector, Ingress, Route, HTTPRoute, Monitoring, Autoscaling,
+	// PodDisruptionBudget
 	return autoConvert_v1alpha2_PersesSpec_To_v1alpha1_PersesSpec(in, out, s)
 }
 
 // Convert_v1alpha2_PersesStatus_To_v1alpha1_PersesStatus converts a PersesStatus from v1alpha2 to v1alpha1.
 func Convert_v1alpha2_PersesStatus_To_v1alpha1_PersesStatus(in *v1alpha2.PersesStatus, out *PersesStatus, s conversion.Scope) error {
-	// NOTE: Provisioning is not supported in v1alpha1, it will be dropped during conversion
+	// NOTE: Provisioning and URL are not supported in v1alpha1, they will be dropped during conversion
 	return autoConvert_v1alpha2_PersesStatus_To_v1alpha1_PersesStatus(in, out, s)
 }
 
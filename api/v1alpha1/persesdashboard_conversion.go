@@ -46,3 +46,9 @@ func Convert_v1alpha2_PersesDashboardSpec_To_v1alpha1_Dashboard(in *v1alpha2.Per
 	out.Spec = in.Config.Spec
 	return nil
 }
+
+// Convert_v1alpha2_PersesDashboardStatus_To_v1alpha1_PersesDashboardStatus converts a PersesDashboardStatus from v1alpha2 to v1alpha1.
+func Convert_v1alpha2_PersesDashboardStatus_To_v1alpha1_PersesDashboardStatus(in *v1alpha2.PersesDashboardStatus, out *PersesDashboardStatus, s conversion.Scope) error {
+	// NOTE: SyncedInstances and Instances are not supported in v1alpha1, they will be dropped during conversion
+	return autoConvert_v1alpha2_PersesDashboardStatus_To_v1alpha1_PersesDashboardStatus(in, out, s)
+}
@@ -46,3 +46,9 @@ func Convert_v1alpha2_DatasourceSpec_To_v1alpha1_DatasourceSpec(in *v1alpha2.Dat
 	out.Config.Spec = in.Config.Spec
 	return nil
 }
+
+// Convert_v1alpha2_PersesDatasourceStatus_To_v1alpha1_PersesDatasourceStatus converts a PersesDatasourceStatus from v1alpha2 to v1alpha1.
+func Convert_v1alpha2_PersesDatasourceStatus_To_v1alpha1_PersesDatasourceStatus(in *v1alpha2.PersesDatasourceStatus, out *PersesDatasourceStatus, s conversion.Scope) error {
+	// NOTE: SyncedInstances and Instances are not supported in v1alpha1, they will be dropped during conversion
+	return autoConvert_v1alpha2_PersesDatasourceStatus_To_v1alpha1_PersesDatasourceStatus(in, out, s)
+}
@@ -152,11 +152,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1alpha2.PersesDashboardStatus)(nil), (*PersesDashboardStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha2_PersesDashboardStatus_To_v1alpha1_PersesDashboardStatus(a.(*v1alpha2.PersesDashboardStatus), b.(*PersesDashboardStatus), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*PersesDatasource)(nil), (*v1alpha2.PersesDatasource)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_PersesDatasource_To_v1alpha2_PersesDatasource(a.(*PersesDatasource), b.(*v1alpha2.PersesDatasource), scope)
 	}); err != nil {
@@ -182,11 +177,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1alpha2.PersesDatasourceStatus)(nil), (*PersesDatasourceStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha2_PersesDatasourceStatus_To_v1alpha1_PersesDatasourceStatus(a.(*v1alpha2.PersesDatasourceStatus), b.(*PersesDatasourceStatus), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*PersesList)(nil), (*v1alpha2.PersesList)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_PersesList_To_v1alpha2_PersesList(a.(*PersesList), b.(*v1alpha2.PersesList), scope)
 	}); err != nil {
@@ -267,6 +257,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1alpha2.PersesDashboardStatus)(nil), (*PersesDashboardStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_PersesDashboardStatus_To_v1alpha1_PersesDashboardStatus(a.(*v1alpha2.PersesDashboardStatus), b.(*PersesDashboardStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1alpha2.PersesDatasourceStatus)(nil), (*PersesDatasourceStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_PersesDatasourceStatus_To_v1alpha1_PersesDatasourceStatus(a.(*v1alpha2.PersesDatasourceStatus), b.(*PersesDatasourceStatus), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*v1alpha2.PersesSpec)(nil), (*PersesSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_PersesSpec_To_v1alpha1_PersesSpec(a.(*v1alpha2.PersesSpec), b.(*PersesSpec), scope)
 	}); err != nil {
@@ -509,7 +509,10 @@ func autoConvert_v1alpha2_DatasourceSpec_To_v1alpha1_DatasourceSpec(in *v1alpha2
 	} else {
 		out.Client = nil
 	}
+	// WARNING: in.SecretRef requires manual conversion: does not exist in peer-type
 	// WARNING: in.InstanceSelector requires manual conversion: does not exist in peer-type
+	// WARNING: in.ConflictPolicy requires manual conversion: does not exist in peer-type
+	// WARNING: in.ResyncPeriodSeconds requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -731,14 +734,11 @@ func Convert_v1alpha1_PersesDashboardStatus_To_v1alpha2_PersesDashboardStatus(in
 
 func autoConvert_v1alpha2_PersesDashboardStatus_To_v1alpha1_PersesDashboardStatus(in *v1alpha2.PersesDashboardStatus, out *PersesDashboardStatus, s conversion.Scope) error {
 	out.Conditions = in.Conditions
+	// WARNING: in.SyncedInstances requires manual conversion: does not exist in peer-type
+	// WARNING: in.Instances requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1alpha2_PersesDashboardStatus_To_v1alpha1_PersesDashboardStatus is an autogenerated conversion function.
-func Convert_v1alpha2_PersesDashboardStatus_To_v1alpha1_PersesDashboardStatus(in *v1alpha2.PersesDashboardStatus, out *PersesDashboardStatus, s conversion.Scope) error {
-	return autoConvert_v1alpha2_PersesDashboardStatus_To_v1alpha1_PersesDashboardStatus(in, out, s)
-}
-
 func autoConvert_v1alpha1_PersesDatasource_To_v1alpha2_PersesDatasource(in *PersesDatasource, out *v1alpha2.PersesDatasource, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_v1alpha1_DatasourceSpec_To_v1alpha2_DatasourceSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -825,14 +825,11 @@ func Convert_v1alpha1_PersesDatasourceStatus_To_v1alpha2_PersesDatasourceStatus(
 
 func autoConvert_v1alpha2_PersesDatasourceStatus_To_v1alpha1_PersesDatasourceStatus(in *v1alpha2.PersesDatasourceStatus, out *PersesDatasourceStatus, s conversion.Scope) error {
 	out.Conditions = in.Conditions
+	// WARNING: in.SyncedInstances requires manual conversion: does not exist in peer-type
+	// WARNING: in.Instances requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1alpha2_PersesDatasourceStatus_To_v1alpha1_PersesDatasourceStatus is an autogenerated conversion function.
-func Convert_v1alpha2_PersesDatasourceStatus_To_v1alpha1_PersesDatasourceStatus(in *v1alpha2.PersesDatasourceStatus, out *PersesDatasourceStatus, s conversion.Scope) error {
-	return autoConvert_v1alpha2_PersesDatasourceStatus_To_v1alpha1_PersesDatasourceStatus(in, out, s)
-}
-
 func autoConvert_v1alpha1_PersesList_To_v1alpha2_PersesList(in *PersesList, out *v1alpha2.PersesList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
 	if in.Items != nil {
@@ -1001,6 +998,8 @@ func autoConvert_v1alpha2_PersesSpec_To_v1alpha1_PersesSpec(in *v1alpha2.PersesS
 		return err
 	}
 	out.Replicas = in.Replicas
+	// WARNING: in.Autoscaling requires manual conversion: does not exist in peer-type
+	// WARNING: in.PodDisruptionBudget requires manual conversion: does not exist in peer-type
 	// WARNING: in.Resources requires manual conversion: does not exist in peer-type
 	out.NodeSelector = in.NodeSelector
 	out.Tolerations = in.Tolerations
@@ -1018,6 +1017,10 @@ func autoConvert_v1alpha2_PersesSpec_To_v1alpha1_PersesSpec(in *v1alpha2.PersesS
 	} else {
 		out.Service = nil
 	}
+	// WARNING: in.Ingress requires manual conversion: does not exist in peer-type
+	// WARNING: in.Route requires manual conversion: does not exist in peer-type
+	// WARNING: in.HTTPRoute requires manual conversion: does not exist in peer-type
+	// WARNING: in.Monitoring requires manual conversion: does not exist in peer-type
 	out.LivenessProbe = in.LivenessProbe
 	out.ReadinessProbe = in.ReadinessProbe
 	if in.TLS != nil {
@@ -1050,6 +1053,8 @@ func autoConvert_v1alpha2_PersesSpec_To_v1alpha1_PersesSpec(in *v1alpha2.PersesS
 	// WARNING: in.VolumeMounts requires manual conversion: does not exist in peer-type
 	// WARNING: in.Env requires manual conversion: does not exist in peer-type
 	// WARNING: in.EnvFrom requires manual conversion: does not exist in peer-type
+	// WARNING: in.ResourceNamespaceSelector requires manual conversion: does not exist in peer-type
+	// WARNING: in.ResourceSelector requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -1066,6 +1071,7 @@ func Convert_v1alpha1_PersesStatus_To_v1alpha2_PersesStatus(in *PersesStatus, ou
 func autoConvert_v1alpha2_PersesStatus_To_v1alpha1_PersesStatus(in *v1alpha2.PersesStatus, out *PersesStatus, s conversion.Scope) error {
 	out.Conditions = in.Conditions
 	// WARNING: in.Provisioning requires manual conversion: does not exist in peer-type
+	// WARNING: in.URL requires manual conversion: does not exist in peer-type
 	return nil
 }
 
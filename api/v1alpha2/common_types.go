@@ -19,6 +19,7 @@ import (
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // ProvisioningSecretPrefix is the prefix for provisioning secrets volume names
@@ -65,3 +66,86 @@ type SecretVersion struct {
 	// +kubebuilder:validation:MinLength=1
 	Version string `json:"version,omitempty"`
 }
+
+// PersesInstanceReference identifies a Perses instance
+type PersesInstanceReference struct {
+	// namespace is the namespace of the Perses instance
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace,omitempty"`
+	// name is the name of the Perses instance
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name,omitempty"`
+}
+
+// InstanceSyncState is the sync state of a resource in a Perses instance
+// +kubebuilder:validation:Enum=Synced;Failed;Unavailable;NotAllowed
+type InstanceSyncState string
+
+const (
+	// InstanceSyncStateSynced means the resource matches its custom resource in the Perses instance
+	InstanceSyncStateSynced InstanceSyncState = "Synced"
+	// InstanceSyncStateFailed means the last sync of the resource to the Perses instance failed
+	InstanceSyncStateFailed InstanceSyncState = "Failed"
+	// InstanceSyncStateUnavailable means the Perses instance was not available during the last reconciliation
+	InstanceSyncStateUnavailable InstanceSyncState = "Unavailable"
+	// InstanceSyncStateNotAllowed means the resource is rejected by the resource selectors of the Perses instance
+	InstanceSyncStateNotAllowed InstanceSyncState = "NotAllowed"
+)
+
+// PersesInstanceStatus is the sync status of a resource in a Perses instance
+type PersesInstanceStatus struct {
+	// namespace is the namespace of the Perses instance
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace,omitempty"`
+	// name is the name of the Perses instance
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name,omitempty"`
+	// state is the sync state of the resource in the Perses instance
+	// +required
+	State InstanceSyncState `json:"state,omitempty"`
+	// lastSyncTime is the last time the resource was written to the Perses instance,
+	// or found matching its custom resource for the first time
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+	// observedGeneration is the generation of the custom resource last synced to the Perses instance
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// lastErrorReason is the reason of the last failed sync, cleared once the resource is synced again
+	// +optional
+	// +kubebuilder:validation:MaxLength=256
+	LastErrorReason string `json:"lastErrorReason,omitempty"`
+	// lastAppliedHash is the hash of the spec and tags last applied to the Perses instance.
+	// The calls to the Perses instance are skipped while the hash and the generation match,
+	// until the next verification
+	// +optional
+	// +kubebuilder:validation:MaxLength=64
+	LastAppliedHash string `json:"lastAppliedHash,omitempty"`
+	// lastVerifiedTime is the last time the resource was compared with its copy in the Perses instance
+	// +optional
+	LastVerifiedTime *metav1.Time `json:"lastVerifiedTime,omitempty"`
+	// url is the address of the resource in the Perses instance, set for dashboards
+	// +optional
+	// +kubebuilder:validation:MaxLength=2048
+	URL string `json:"url,omitempty"`
+}
+
+// ConflictPolicy defines what happens when an object with the same name, not created
+// by the operator, already exists in Perses
+// +kubebuilder:validation:Enum=Adopt;Fail;Overwrite
+type ConflictPolicy string
+
+const (
+	// ConflictPolicyAdopt takes ownership of the existing object: it is updated from the
+	// custom resource and removed from Perses with it
+	ConflictPolicyAdopt ConflictPolicy = "Adopt"
+	// ConflictPolicyFail leaves the existing object untouched and reports a conflict
+	ConflictPolicyFail ConflictPolicy = "Fail"
+	// ConflictPolicyOverwrite updates the existing object from the custom resource without
+	// taking ownership of it: it is kept in Perses when the custom resource is deleted
+	ConflictPolicyOverwrite ConflictPolicy = "Overwrite"
+)
@@ -0,0 +1,57 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha2
+
+import (
+	"fmt"
+
+	"github.com/brunoga/deep"
+	persesv1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// Role represents the Perses role configuration: the list of permissions,
+// each one granting a set of actions on a set of resource kinds (scopes).
+type Role struct {
+	persesv1.RoleSpec `json:",inline"`
+}
+
+func (in *Role) DeepCopyInto(out *Role) {
+	if in == nil {
+		return
+	}
+
+	copied, err := deep.Copy(in)
+	if err != nil {
+		panic(fmt.Errorf("failed to deep copy Role: %w", err))
+	}
+	*out = *copied
+}
+
+// RoleBinding represents the Perses role binding configuration: the name of
+// the role to bind and the users that inherit its permissions.
+type RoleBinding struct {
+	persesv1.RoleBindingSpec `json:",inline"`
+}
+
+func (in *RoleBinding) DeepCopyInto(out *RoleBinding) {
+	if in == nil {
+		return
+	}
+
+	copied, err := deep.Copy(in)
+	if err != nil {
+		panic(fmt.Errorf("failed to deep copy RoleBinding: %w", err))
+	}
+	*out = *copied
+}
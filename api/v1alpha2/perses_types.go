@@ -14,11 +14,15 @@
 package v1alpha2
 
 import (
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // PersesSpec defines the desired state of Perses
+// +kubebuilder:validation:XValidation:rule="!has(self.autoscaling) || (has(self.config) && has(self.config.database) && has(self.config.database.sql))",message="autoscaling requires a SQL database, the file database only supports a single replica"
+// +kubebuilder:validation:XValidation:rule="!has(self.podDisruptionBudget) || (has(self.config) && has(self.config.database) && has(self.config.database.sql))",message="podDisruptionBudget requires a SQL database, the file database only supports a single replica"
 type PersesSpec struct {
 	// metadata specifies additional metadata to add to deployed pods
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
@@ -45,9 +49,20 @@ type PersesSpec struct {
 	// +kubebuilder:validation:Maximum=65535
 	ContainerPort *int32 `json:"containerPort,omitempty"`
 	// replicas is the number of desired pod replicas for the Perses deployment
+	// It is ignored when autoscaling is specified
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	// +optional
 	Replicas *int32 `json:"replicas,omitempty"`
+	// autoscaling specifies the HorizontalPodAutoscaler scaling the Perses deployment
+	// It requires a SQL database. The HorizontalPodAutoscaler is deleted when this field is removed.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	Autoscaling *PersesAutoscaling `json:"autoscaling,omitempty"`
+	// podDisruptionBudget specifies the PodDisruptionBudget limiting the voluntary disruptions of the Perses pods
+	// It requires a SQL database. The PodDisruptionBudget is deleted when this field is removed.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	PodDisruptionBudget *PersesPodDisruptionBudget `json:"podDisruptionBudget,omitempty"`
 	// resources defines the compute resources configured for the container
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	// +optional
@@ -78,6 +93,29 @@ type PersesSpec struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	// +optional
 	Service *PersesService `json:"service,omitempty"`
+	// ingress specifies the Ingress exposing the Perses instance outside of the cluster.
+	// The Ingress is deleted when this field is removed.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	Ingress *PersesIngress `json:"ingress,omitempty"`
+	// route specifies the OpenShift Route exposing the Perses instance outside of the cluster.
+	// It is only reconciled when the route.openshift.io API is served by the cluster.
+	// The Route is deleted when this field is removed.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	Route *PersesRoute `json:"route,omitempty"`
+	// httpRoute specifies the Gateway API HTTPRoute exposing the Perses instance outside of the cluster.
+	// It is only reconciled when the gateway.networking.k8s.io API is served by the cluster.
+	// The HTTPRoute is deleted when this field is removed.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	HTTPRoute *PersesHTTPRoute `json:"httpRoute,omitempty"`
+	// monitoring specifies the ServiceMonitor or PodMonitor scraping the metrics of the Perses instance.
+	// It is only reconciled when the monitoring.coreos.com API is served by the cluster.
+	// The monitor is deleted when this field is removed.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	Monitoring *PersesMonitoring `json:"monitoring,omitempty"`
 	// livenessProbe specifies the liveness probe configuration for the Perses container
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	// +optional
@@ -162,6 +200,18 @@ type PersesSpec struct {
 	// +kubebuilder:validation:MaxItems=50
 	// corev1.EnvFromSource is the canonical Kubernetes envFrom type
 	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+	// resourceNamespaceSelector selects the namespaces whose dashboards and datasources may be synced
+	// to this Perses instance, based on the namespace labels. It does not apply to global datasources.
+	// If not specified, resources from all namespaces are accepted.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	ResourceNamespaceSelector *metav1.LabelSelector `json:"resourceNamespaceSelector,omitempty"`
+	// resourceSelector selects the dashboards, datasources and global datasources that may be synced
+	// to this Perses instance, based on their labels.
+	// If not specified, all resources are accepted.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	ResourceSelector *metav1.LabelSelector `json:"resourceSelector,omitempty"`
 }
 
 // Metadata to add to deployed pods
@@ -187,6 +237,227 @@ type PersesService struct {
 	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
+// PersesAutoscaling defines the HorizontalPodAutoscaler scaling the Perses deployment
+// +kubebuilder:validation:XValidation:rule="!has(self.minReplicas) || self.minReplicas <= self.maxReplicas",message="minReplicas must not be greater than maxReplicas"
+type PersesAutoscaling struct {
+	// minReplicas is the lower limit for the number of replicas
+	// If not specified, it defaults to 1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// maxReplicas is the upper limit for the number of replicas
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +required
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas,omitempty"`
+	// targetCPUUtilizationPercentage is the target average CPU utilization of the pods,
+	// as a percentage of their requested CPU
+	// If neither target is specified, the CPU utilization is targeted at 80%
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+	// targetMemoryUtilizationPercentage is the target average memory utilization of the pods,
+	// as a percentage of their requested memory
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	TargetMemoryUtilizationPercentage *int32 `json:"targetMemoryUtilizationPercentage,omitempty"`
+}
+
+// PersesPodDisruptionBudget defines the PodDisruptionBudget of the Perses pods
+// +kubebuilder:validation:XValidation:rule="has(self.minAvailable) != has(self.maxUnavailable)",message="exactly one of minAvailable and maxUnavailable must be specified"
+type PersesPodDisruptionBudget struct {
+	// minAvailable is the number or percentage of pods that must remain available during a disruption
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+	// maxUnavailable is the number or percentage of pods that can be unavailable during a disruption
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// PersesIngress defines the Ingress exposing the Perses instance
+type PersesIngress struct {
+	// host is the fully qualified domain name the Perses instance is exposed on
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Host string `json:"host,omitempty"`
+	// path is the path the Perses instance is exposed on
+	// If not specified, config.api_prefix is used, or / when it is not set
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	// +kubebuilder:validation:Pattern=`^/`
+	Path *string `json:"path,omitempty"`
+	// ingressClassName is the name of the IngressClass handling the Ingress
+	// If not specified, the default IngressClass of the cluster is used
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+	// annotations are key/value pairs attached to the Ingress for non-identifying metadata
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// tls terminates TLS on the Ingress for the host
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	TLS *PersesIngressTLS `json:"tls,omitempty"`
+}
+
+// PersesIngressTLS defines the TLS termination of the Ingress exposing the Perses instance
+type PersesIngressTLS struct {
+	// secretName is the name of the Secret holding the certificate of the host
+	// If not specified, the default certificate of the ingress controller is used
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	SecretName *string `json:"secretName,omitempty"`
+}
+
+// PersesRoute defines the OpenShift Route exposing the Perses instance
+type PersesRoute struct {
+	// host is the fully qualified domain name the Perses instance is exposed on
+	// If not specified, the router generates one
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	Host *string `json:"host,omitempty"`
+	// path is the path the Perses instance is exposed on
+	// If not specified, config.api_prefix is used, or / when it is not set
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	// +kubebuilder:validation:Pattern=`^/`
+	Path *string `json:"path,omitempty"`
+	// annotations are key/value pairs attached to the Route for non-identifying metadata
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// tls terminates TLS on the router for the host
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	TLS *PersesRouteTLS `json:"tls,omitempty"`
+}
+
+// PersesRouteTLS defines the TLS termination of the Route exposing the Perses instance
+type PersesRouteTLS struct {
+	// termination is where TLS is terminated: edge terminates it on the router,
+	// reencrypt terminates it on the router and opens a new TLS connection to Perses,
+	// passthrough lets Perses terminate it, in which case the whole host is routed to Perses
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +required
+	// +kubebuilder:validation:Enum=edge;reencrypt;passthrough
+	Termination string `json:"termination,omitempty"`
+	// insecureEdgeTerminationPolicy is how the plain HTTP requests are handled
+	// If not specified, they are rejected
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	// +kubebuilder:validation:Enum=None;Allow;Redirect
+	InsecureEdgeTerminationPolicy *string `json:"insecureEdgeTerminationPolicy,omitempty"`
+}
+
+// PersesHTTPRoute defines the Gateway API HTTPRoute exposing the Perses instance.
+// TLS is terminated by the listeners of the parent Gateways.
+type PersesHTTPRoute struct {
+	// host is the fully qualified domain name the Perses instance is exposed on
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Host string `json:"host,omitempty"`
+	// path is the path the Perses instance is exposed on
+	// If not specified, config.api_prefix is used, or / when it is not set
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	// +kubebuilder:validation:Pattern=`^/`
+	Path *string `json:"path,omitempty"`
+	// annotations are key/value pairs attached to the HTTPRoute for non-identifying metadata
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// parentRefs are the Gateways the HTTPRoute attaches to
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +required
+	// +listType=atomic
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=32
+	ParentRefs []GatewayReference `json:"parentRefs,omitempty"`
+}
+
+// GatewayReference identifies a Gateway, and optionally one of its listeners
+type GatewayReference struct {
+	// name is the name of the Gateway
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name,omitempty"`
+	// namespace is the namespace of the Gateway
+	// If not specified, the namespace of the Perses instance is used
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	Namespace *string `json:"namespace,omitempty"`
+	// sectionName is the name of the listener of the Gateway the HTTPRoute attaches to
+	// If not specified, the HTTPRoute attaches to every listener accepting it
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	SectionName *string `json:"sectionName,omitempty"`
+}
+
+// MonitorKind is the kind of the Prometheus Operator resource scraping the metrics of the Perses instance
+// +kubebuilder:validation:Enum=ServiceMonitor;PodMonitor
+type MonitorKind string
+
+const (
+	MonitorKindServiceMonitor MonitorKind = "ServiceMonitor"
+	MonitorKindPodMonitor     MonitorKind = "PodMonitor"
+)
+
+// PersesMonitoring defines the ServiceMonitor or PodMonitor scraping the metrics of the Perses instance.
+// The metrics are scraped over HTTPS when TLS is enabled in spec.tls, verifying the certificate of
+// Perses with the CA certificate of spec.tls.
+type PersesMonitoring struct {
+	// kind is the kind of the monitor: a ServiceMonitor scrapes the pods through the Service of the
+	// Perses instance, a PodMonitor scrapes them directly
+	// If not specified, a ServiceMonitor is created
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	Kind *MonitorKind `json:"kind,omitempty"`
+	// labels are key/value pairs attached to the monitor, e.g. to match the monitor selector of a Prometheus
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// interval is the interval at which the metrics are scraped
+	// If not specified, the scrape interval of Prometheus is used
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	Interval *monitoringv1.Duration `json:"interval,omitempty"`
+	// scrapeTimeout is the timeout of a scrape
+	// If not specified, the scrape timeout of Prometheus is used
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	ScrapeTimeout *monitoringv1.Duration `json:"scrapeTimeout,omitempty"`
+	// serverName is the name used to verify the certificate of Perses when TLS is enabled
+	// If not specified, the DNS name of the Service of the Perses instance is used
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	ServerName *string `json:"serverName,omitempty"`
+	// relabelings are applied to the labels of the targets before scraping
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	// +listType=atomic
+	Relabelings []monitoringv1.RelabelConfig `json:"relabelings,omitempty"`
+	// metricRelabelings are applied to the scraped samples before ingestion
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	// +listType=atomic
+	MetricRelabelings []monitoringv1.RelabelConfig `json:"metricRelabelings,omitempty"`
+}
+
 // Client defines how the client should authenticate
 // +kubebuilder:validation:XValidation:rule="!(has(self.kubernetesAuth) && has(self.kubernetesAuth.enable) && self.kubernetesAuth.enable == true && has(self.oauth))",message="kubernetesAuth and oauth are mutually exclusive; both cannot be enabled simultaneously"
 // +kubebuilder:validation:XValidation:rule="!(has(self.kubernetesAuth) && has(self.kubernetesAuth.enable) && self.kubernetesAuth.enable == true && has(self.basicAuth))",message="kubernetesAuth and basicAuth are mutually exclusive; both cannot be enabled simultaneously"
@@ -343,6 +614,10 @@ type PersesStatus struct {
 	// +optional
 	// +listType=atomic
 	Provisioning []SecretVersion `json:"provisioning,omitempty"`
+	// url is the external URL of the Perses instance, exposed by its Ingress, Route or HTTPRoute
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	URL string `json:"url,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -378,6 +653,12 @@ func (p *Perses) RequiresDeployment() bool {
 	return usesSQLDatabase || usesFileWithEmptyDir
 }
 
+// SupportsHorizontalScaling returns true if several replicas of the Perses instance can share its
+// database, which is only the case when using SQL database. The file database has a single writer.
+func (p *Perses) SupportsHorizontalScaling() bool {
+	return p.Spec.Config.Database.SQL != nil
+}
+
 // RequiresStatefulSet returns true if the Perses instance should be deployed as a StatefulSet.
 // This is the case when using file database with persistent volume storage (not EmptyDir).
 func (p *Perses) RequiresStatefulSet() bool {
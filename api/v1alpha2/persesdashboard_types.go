@@ -27,6 +27,22 @@ type PersesDashboardStatus struct {
 	// +patchStrategy=merge
 	// +patchMergeKey=type
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+	// syncedInstances lists the Perses instances the dashboard is synced to. An instance is
+	// recorded before the dashboard is first written to it. On deletion, the dashboard is
+	// removed from each of them before the finalizer is released.
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	// +listType=map
+	// +listMapKey=namespace
+	// +listMapKey=name
+	SyncedInstances []PersesInstanceReference `json:"syncedInstances,omitempty"`
+	// instances reports the sync status of the dashboard in each Perses instance it selects
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	// +listType=map
+	// +listMapKey=namespace
+	// +listMapKey=name
+	Instances []PersesInstanceStatus `json:"instances,omitempty"`
 }
 
 // PersesDashboardSpec defines the desired state of PersesDashboard
@@ -40,6 +56,20 @@ type PersesDashboardSpec struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	// +optional
 	InstanceSelector *metav1.LabelSelector `json:"instanceSelector,omitempty"`
+	// conflictPolicy defines what happens when a dashboard with the same name, not created by the operator,
+	// already exists in Perses. Adopt takes ownership of it, Fail leaves it untouched and reports a Conflict,
+	// Overwrite updates it but keeps it in Perses when the PersesDashboard is deleted.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default=Adopt
+	// +optional
+	ConflictPolicy ConflictPolicy `json:"conflictPolicy,omitempty"`
+	// resyncPeriodSeconds overrides the operator --resync-period flag for this dashboard. Every period,
+	// the dashboard is compared with its copy in each Perses instance and any drift is corrected.
+	// 0 disables the periodic resync.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ResyncPeriodSeconds *int32 `json:"resyncPeriodSeconds,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -27,9 +27,26 @@ type PersesDatasourceStatus struct {
 	// +patchStrategy=merge
 	// +patchMergeKey=type
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+	// syncedInstances lists the Perses instances the datasource is synced to. An instance is
+	// recorded before the datasource is first written to it. On deletion, the datasource is
+	// removed from each of them before the finalizer is released.
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	// +listType=map
+	// +listMapKey=namespace
+	// +listMapKey=name
+	SyncedInstances []PersesInstanceReference `json:"syncedInstances,omitempty"`
+	// instances reports the sync status of the datasource in each Perses instance it selects
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	// +listType=map
+	// +listMapKey=namespace
+	// +listMapKey=name
+	Instances []PersesInstanceStatus `json:"instances,omitempty"`
 }
 
 // DatasourceSpec defines the desired state of a Perses datasource
+// +kubebuilder:validation:XValidation:rule="!(has(self.client) && has(self.secretRef))",message="client and secretRef are mutually exclusive"
 type DatasourceSpec struct {
 	// config specifies the Perses datasource configuration
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
@@ -40,10 +57,38 @@ type DatasourceSpec struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	// +optional
 	Client *Client `json:"client,omitempty"`
+	// secretRef references a PersesSecret, or a PersesGlobalSecret for global datasources,
+	// holding the authentication and TLS configuration shared with other datasources.
+	// The datasource proxy configuration must use the same secret name.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	SecretRef *SecretReference `json:"secretRef,omitempty"`
 	// instanceSelector selects Perses instances where this datasource will be created
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	// +optional
 	InstanceSelector *metav1.LabelSelector `json:"instanceSelector,omitempty"`
+	// conflictPolicy defines what happens when a datasource with the same name, not created by the operator,
+	// already exists in Perses. Adopt takes ownership of it, Fail leaves it untouched and reports a Conflict,
+	// Overwrite updates it but keeps it in Perses when the custom resource is deleted.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default=Adopt
+	// +optional
+	ConflictPolicy ConflictPolicy `json:"conflictPolicy,omitempty"`
+	// resyncPeriodSeconds overrides the operator --resync-period flag for this datasource. Every period,
+	// the datasource is compared with its copy in each Perses instance and any drift is corrected.
+	// 0 disables the periodic resync.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ResyncPeriodSeconds *int32 `json:"resyncPeriodSeconds,omitempty"`
+}
+
+// SecretReference references a PersesSecret or a PersesGlobalSecret by name
+type SecretReference struct {
+	// name is the name of the referenced secret
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
 }
 
 //+kubebuilder:object:root=true
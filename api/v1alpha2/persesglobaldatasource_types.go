@@ -27,6 +27,22 @@ type PersesGlobalDatasourceStatus struct {
 	// +patchStrategy=merge
 	// +patchMergeKey=type
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+	// syncedInstances lists the Perses instances the global datasource is synced to. An instance is
+	// recorded before the global datasource is first written to it. On deletion, the global datasource is
+	// removed from each of them before the finalizer is released.
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	// +listType=map
+	// +listMapKey=namespace
+	// +listMapKey=name
+	SyncedInstances []PersesInstanceReference `json:"syncedInstances,omitempty"`
+	// instances reports the sync status of the global datasource in each Perses instance it selects
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	// +listType=map
+	// +listMapKey=namespace
+	// +listMapKey=name
+	Instances []PersesInstanceStatus `json:"instances,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -0,0 +1,65 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PersesGlobalRoleStatus defines the observed state of PersesGlobalRole
+type PersesGlobalRoleStatus struct {
+	// conditions represent the latest observations of the PersesGlobalRole resource state
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster,shortName=pergrole
+//+versionName=v1alpha2
+//+kubebuilder:storageversion
+
+// PersesGlobalRole is the Schema for the persesglobalroles API
+type PersesGlobalRole struct {
+	metav1.TypeMeta `json:",inline"`
+	// metadata is the standard Kubernetes ObjectMeta
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// spec is the desired state of the PersesGlobalRole resource
+	// +required
+	Spec RoleSpec `json:"spec,omitzero"`
+	// status is the observed state of the PersesGlobalRole resource
+	// +optional
+	//nolint:kubeapilinter // non-pointer Status is the standard pattern for Kubernetes controllers
+	Status PersesGlobalRoleStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PersesGlobalRoleList contains a list of PersesGlobalRole
+type PersesGlobalRoleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PersesGlobalRole `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PersesGlobalRole{}, &PersesGlobalRoleList{})
+}
@@ -0,0 +1,107 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ProjectDeletionPolicy defines what happens to the Perses project when the PersesProject is deleted
+// +kubebuilder:validation:Enum=Retain;Delete
+type ProjectDeletionPolicy string
+
+const (
+	// ProjectDeletionPolicyRetain keeps the project in Perses when the PersesProject is deleted
+	ProjectDeletionPolicyRetain ProjectDeletionPolicy = "Retain"
+	// ProjectDeletionPolicyDelete removes the project, and everything it contains, from Perses
+	// when the PersesProject is deleted, unless the project was created by hand
+	ProjectDeletionPolicyDelete ProjectDeletionPolicy = "Delete"
+)
+
+// PersesProjectStatus defines the observed state of PersesProject
+type PersesProjectStatus struct {
+	// conditions represent the latest observations of the PersesProject resource state
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+}
+
+// PersesProjectSpec defines the desired state of PersesProject.
+// The Perses project is always named after the namespace of the PersesProject,
+// so that dashboards and datasources of the same namespace end up in it.
+type PersesProjectSpec struct {
+	// displayName is the human-readable name of the project shown in the Perses UI.
+	// Defaults to the namespace name when not set.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=256
+	// +optional
+	DisplayName string `json:"displayName,omitempty"`
+	// description is a free-form description of the project shown in the Perses UI
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=1024
+	// +optional
+	Description string `json:"description,omitempty"`
+	// deletionPolicy defines whether the project is removed from Perses when the PersesProject is deleted.
+	// Deleting a project in Perses also deletes every dashboard, datasource and secret it contains.
+	// A project of the same name created by hand in Perses is never deleted.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default=Retain
+	// +optional
+	DeletionPolicy ProjectDeletionPolicy `json:"deletionPolicy,omitempty"`
+	// instanceSelector selects Perses instances where this project will be created
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	InstanceSelector *metav1.LabelSelector `json:"instanceSelector,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=perproj
+//+versionName=v1alpha2
+//+kubebuilder:storageversion
+
+// PersesProject is the Schema for the persesprojects API
+type PersesProject struct {
+	metav1.TypeMeta `json:",inline"`
+	// metadata is the standard Kubernetes ObjectMeta
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// spec is the desired state of the PersesProject resource
+	// +required
+	Spec PersesProjectSpec `json:"spec,omitzero"`
+	// status is the observed state of the PersesProject resource
+	// +optional
+	//nolint:kubeapilinter // non-pointer Status is the standard pattern for Kubernetes controllers
+	Status PersesProjectStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PersesProjectList contains a list of PersesProject
+type PersesProjectList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PersesProject `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PersesProject{}, &PersesProjectList{})
+}
@@ -0,0 +1,85 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PersesRoleStatus defines the observed state of PersesRole
+type PersesRoleStatus struct {
+	// conditions represent the latest observations of the PersesRole resource state
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+}
+
+// RoleSpec defines the desired state of a Perses role
+type RoleSpec struct {
+	// config specifies the Perses role permissions
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:validation:XValidation:rule="size(self.permissions) > 0",message="at least one permission is required"
+	// +required
+	Config Role `json:"config"`
+	// instanceSelector selects Perses instances where this role will be created
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	InstanceSelector *metav1.LabelSelector `json:"instanceSelector,omitempty"`
+	// conflictPolicy defines what happens when a role with the same name, not created by the operator,
+	// already exists in Perses. Adopt takes ownership of it, Fail leaves it untouched and reports a Conflict,
+	// Overwrite updates it but keeps it in Perses when the custom resource is deleted.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default=Adopt
+	// +optional
+	ConflictPolicy ConflictPolicy `json:"conflictPolicy,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=perrole
+//+versionName=v1alpha2
+//+kubebuilder:storageversion
+
+// PersesRole is the Schema for the persesroles API
+type PersesRole struct {
+	metav1.TypeMeta `json:",inline"`
+	// metadata is the standard Kubernetes ObjectMeta
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// spec is the desired state of the PersesRole resource
+	// +required
+	Spec RoleSpec `json:"spec,omitzero"`
+	// status is the observed state of the PersesRole resource
+	// +optional
+	//nolint:kubeapilinter // non-pointer Status is the standard pattern for Kubernetes controllers
+	Status PersesRoleStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PersesRoleList contains a list of PersesRole
+type PersesRoleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PersesRole `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PersesRole{}, &PersesRoleList{})
+}
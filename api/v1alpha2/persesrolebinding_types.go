@@ -0,0 +1,86 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PersesRoleBindingStatus defines the observed state of PersesRoleBinding
+type PersesRoleBindingStatus struct {
+	// conditions represent the latest observations of the PersesRoleBinding resource state
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+}
+
+// RoleBindingSpec defines the desired state of a Perses role binding
+type RoleBindingSpec struct {
+	// config specifies the Perses role binding: the role to bind and its subjects
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:validation:XValidation:rule="size(self.role) > 0",message="role is required"
+	// +kubebuilder:validation:XValidation:rule="size(self.subjects) > 0 && self.subjects.all(s, s.kind == 'User')",message="at least one subject is required and subjects must be of kind User"
+	// +required
+	Config RoleBinding `json:"config"`
+	// instanceSelector selects Perses instances where this role binding will be created
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	InstanceSelector *metav1.LabelSelector `json:"instanceSelector,omitempty"`
+	// conflictPolicy defines what happens when a role binding with the same name, not created by the operator,
+	// already exists in Perses. Adopt takes ownership of it, Fail leaves it untouched and reports a Conflict,
+	// Overwrite updates it but keeps it in Perses when the custom resource is deleted.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default=Adopt
+	// +optional
+	ConflictPolicy ConflictPolicy `json:"conflictPolicy,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=perrb
+//+versionName=v1alpha2
+//+kubebuilder:storageversion
+
+// PersesRoleBinding is the Schema for the persesrolebindings API
+type PersesRoleBinding struct {
+	metav1.TypeMeta `json:",inline"`
+	// metadata is the standard Kubernetes ObjectMeta
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// spec is the desired state of the PersesRoleBinding resource
+	// +required
+	Spec RoleBindingSpec `json:"spec,omitzero"`
+	// status is the observed state of the PersesRoleBinding resource
+	// +optional
+	//nolint:kubeapilinter // non-pointer Status is the standard pattern for Kubernetes controllers
+	Status PersesRoleBindingStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PersesRoleBindingList contains a list of PersesRoleBinding
+type PersesRoleBindingList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PersesRoleBinding `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PersesRoleBinding{}, &PersesRoleBindingList{})
+}
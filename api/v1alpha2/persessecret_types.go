@@ -0,0 +1,85 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PersesSecretStatus defines the observed state of PersesSecret
+type PersesSecretStatus struct {
+	// conditions represent the latest observations of the PersesSecret resource state
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+}
+
+// SecretSpec defines the desired state of a Perses secret
+type SecretSpec struct {
+	// client specifies the authentication and TLS configuration stored in the secret
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:validation:XValidation:rule="has(self.basicAuth) || has(self.oauth) || has(self.tls)",message="at least one of basicAuth, oauth or tls is required"
+	// +required
+	Client Client `json:"client"`
+	// instanceSelector selects Perses instances where this secret will be created
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	InstanceSelector *metav1.LabelSelector `json:"instanceSelector,omitempty"`
+	// conflictPolicy defines what happens when a secret with the same name, not created by the operator,
+	// already exists in Perses. Adopt takes ownership of it, Fail leaves it untouched and reports a Conflict,
+	// Overwrite updates it but keeps it in Perses when the custom resource is deleted.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default=Adopt
+	// +optional
+	ConflictPolicy ConflictPolicy `json:"conflictPolicy,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=persec
+//+versionName=v1alpha2
+//+kubebuilder:storageversion
+
+// PersesSecret is the Schema for the persessecrets API
+type PersesSecret struct {
+	metav1.TypeMeta `json:",inline"`
+	// metadata is the standard Kubernetes ObjectMeta
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// spec is the desired state of the PersesSecret resource
+	// +required
+	Spec SecretSpec `json:"spec,omitzero"`
+	// status is the observed state of the PersesSecret resource
+	// +optional
+	//nolint:kubeapilinter // non-pointer Status is the standard pattern for Kubernetes controllers
+	Status PersesSecretStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PersesSecretList contains a list of PersesSecret
+type PersesSecretList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PersesSecret `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PersesSecret{}, &PersesSecretList{})
+}
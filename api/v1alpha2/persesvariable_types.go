@@ -0,0 +1,90 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PersesVariableStatus defines the observed state of PersesVariable
+type PersesVariableStatus struct {
+	// conditions represent the latest observations of the PersesVariable resource state
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+}
+
+// VariableSpec defines the desired state of a Perses variable
+type VariableSpec struct {
+	// config specifies the Perses variable configuration
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:XValidation:rule="has(self.kind) && self.kind in ['ListVariable', 'TextVariable']",message="kind must be either ListVariable or TextVariable"
+	// +kubebuilder:validation:XValidation:rule="has(self.spec)",message="spec is required"
+	// +required
+	//nolint:kubeapilinter // Variable uses flexible JSON schema; struct-level required fields are not applicable
+	Config Variable `json:"config"`
+	// instanceSelector selects Perses instances where this variable will be created
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	InstanceSelector *metav1.LabelSelector `json:"instanceSelector,omitempty"`
+	// conflictPolicy defines what happens when a variable with the same name, not created by the operator,
+	// already exists in Perses. Adopt takes ownership of it, Fail leaves it untouched and reports a Conflict,
+	// Overwrite updates it but keeps it in Perses when the custom resource is deleted.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default=Adopt
+	// +optional
+	ConflictPolicy ConflictPolicy `json:"conflictPolicy,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=pervar
+//+versionName=v1alpha2
+//+kubebuilder:storageversion
+
+// PersesVariable is the Schema for the persesvariables API
+type PersesVariable struct {
+	metav1.TypeMeta `json:",inline"`
+	// metadata is the standard Kubernetes ObjectMeta
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// spec is the desired state of the PersesVariable resource
+	// +required
+	Spec VariableSpec `json:"spec,omitzero"`
+	// status is the observed state of the PersesVariable resource
+	// +optional
+	//nolint:kubeapilinter // non-pointer Status is the standard pattern for Kubernetes controllers
+	Status PersesVariableStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PersesVariableList contains a list of PersesVariable
+type PersesVariableList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PersesVariable `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PersesVariable{}, &PersesVariableList{})
+}
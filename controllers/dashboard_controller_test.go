@@ -109,6 +109,7 @@ var _ = Describe("Dashboard controller", Ordered, func() {
 				Metadata: persesv1.ProjectMetadata{
 					Metadata: persesv1.Metadata{
 						Name: DashboardName,
+						Tags: common.WithManagedTag(nil),
 					},
 				},
 				Spec: specdashboard.Spec{
@@ -192,7 +193,7 @@ var _ = Describe("Dashboard controller", Ordered, func() {
 
 			// The dashboard was created in the Perses API
 			getDashboard.Unset()
-			mockDashboard.On("Get", DashboardName).Return(&persesv1.Dashboard{}, nil)
+			mockDashboard.On("Get", DashboardName).Return(newDashboard, nil)
 
 			By("Checking if the Perses API was called to create a dashboard")
 			Eventually(func() error {
@@ -236,6 +237,11 @@ var _ = Describe("Dashboard controller", Ordered, func() {
 				return err
 			}, time.Minute, time.Second).Should(Succeed())
 
+			By("Checking the Perses instance was recorded as synced")
+			syncedDashboard := &persesv1alpha2.PersesDashboard{}
+			Expect(k8sClient.Get(ctx, dashboardNamespaceName, syncedDashboard)).To(Succeed())
+			Expect(syncedDashboard.Status.SyncedInstances).To(ContainElement(persesv1alpha2.PersesInstanceReference{Namespace: PersesNamespace, Name: PersesName}))
+
 			mockDashboard.On("Delete", DashboardName).Return(nil)
 
 			dashboardToDelete := &persesv1alpha2.PersesDashboard{}
@@ -259,6 +265,11 @@ var _ = Describe("Dashboard controller", Ordered, func() {
 				}
 				return nil
 			}, time.Minute, time.Second).Should(Succeed())
+
+			By("Checking the finalizer was released once the dashboard was deleted in Perses")
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, dashboardNamespaceName, &persesv1alpha2.PersesDashboard{}))
+			}, time.Minute, time.Second).Should(BeTrue())
 		})
 
 		It("should call Update on the Perses API when the dashboard spec changes", func() {
@@ -328,6 +339,7 @@ var _ = Describe("Dashboard controller", Ordered, func() {
 				Metadata: persesv1.ProjectMetadata{
 					Metadata: persesv1.Metadata{
 						Name: DashboardName,
+						Tags: common.WithManagedTag(nil),
 					},
 				},
 				Spec: newDashboard.Spec,
@@ -339,6 +351,7 @@ var _ = Describe("Dashboard controller", Ordered, func() {
 				Metadata: persesv1.ProjectMetadata{
 					Metadata: persesv1.Metadata{
 						Name: DashboardName,
+						Tags: common.WithManagedTag(nil),
 					},
 				},
 				Spec: specdashboard.Spec{
@@ -484,8 +497,6 @@ var _ = Describe("Dashboard controller", Ordered, func() {
 				return err
 			}, time.Minute, time.Second).Should(Succeed())
 
-			mockDashboard.On("Delete", DashboardName).Return(nil)
-
 			dashboardToDelete := &persesv1alpha2.PersesDashboard{}
 			err = k8sClient.Get(ctx, dashboardNamespaceName, dashboardToDelete)
 			Expect(err).To(Not(HaveOccurred()))
@@ -500,13 +511,8 @@ var _ = Describe("Dashboard controller", Ordered, func() {
 
 			Expect(err).To(Not(HaveOccurred()))
 
-			By("Checking if the Perses API was called to delete a dashboard")
-			Eventually(func() error {
-				if !mockDashboard.AssertExpectations(GinkgoT()) {
-					return fmt.Errorf("The Perses API was not called to create a dashboard")
-				}
-				return nil
-			}, time.Minute, time.Second).Should(Succeed())
+			By("Checking the Perses API was not called to delete a dashboard that was never created")
+			mockDashboard.AssertNotCalled(GinkgoT(), "Delete", DashboardName)
 		})
 
 		It("should return an error when the Perses API delete call fails", func() {
@@ -516,8 +522,9 @@ var _ = Describe("Dashboard controller", Ordered, func() {
 			if err != nil && errors.IsNotFound(err) {
 				perses := &persesv1alpha2.PersesDashboard{
 					ObjectMeta: metav1.ObjectMeta{
-						Name:      DashboardName,
-						Namespace: PersesNamespace,
+						Name:       DashboardName,
+						Namespace:  PersesNamespace,
+						Finalizers: []string{common.PersesFinalizer},
 					},
 					Spec: persesv1alpha2.PersesDashboardSpec{
 						Config: persesv1alpha2.Dashboard{
@@ -530,12 +537,22 @@ var _ = Describe("Dashboard controller", Ordered, func() {
 				Expect(err).To(Not(HaveOccurred()))
 			}
 
+			By("Recording the Perses instance the dashboard was synced to")
+			Eventually(func() error {
+				if err := k8sClient.Get(ctx, dashboardNamespaceName, dashboard); err != nil {
+					return err
+				}
+				dashboard.Status.SyncedInstances = []persesv1alpha2.PersesInstanceReference{{Namespace: PersesNamespace, Name: PersesName}}
+				return k8sClient.Status().Update(ctx, dashboard)
+			}, time.Second*10, time.Millisecond*250).Should(Succeed())
+
 			mockPersesClient, validateServer := internal.NewMockClientWithValidation()
 			defer validateServer.Close()
 			mockDashboard := new(internal.MockDashboard)
 
 			mockPersesClient.On("Dashboard", PersesNamespace).Return(mockDashboard)
-			mockDashboard.On("Delete", DashboardName).Return(perseshttp.RequestInternalError)
+			mockDashboard.On("Get", DashboardName).Return(newDashboard, nil)
+			deleteCall := mockDashboard.On("Delete", DashboardName).Return(perseshttp.RequestInternalError)
 
 			dashboardReconciler := &dashboardcontroller.PersesDashboardReconciler{
 				Client:        k8sClient,
@@ -557,7 +574,22 @@ var _ = Describe("Dashboard controller", Ordered, func() {
 				NamespacedName: dashboardNamespaceName,
 			})
 			Expect(err).To(HaveOccurred())
+			Expect(common.ExtractReason(err, "")).To(Equal(common.ReasonDeletionBlocked))
 			mockDashboard.AssertCalled(GinkgoT(), "Delete", DashboardName)
+
+			By("Checking the finalizer keeps the dashboard until the Perses API confirms the deletion")
+			Expect(k8sClient.Get(ctx, dashboardNamespaceName, &persesv1alpha2.PersesDashboard{})).To(Succeed())
+
+			deleteCall.Unset()
+			mockDashboard.On("Delete", DashboardName).Return(nil)
+
+			_, err = dashboardReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: dashboardNamespaceName,
+			})
+			Expect(err).To(Not(HaveOccurred()))
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, dashboardNamespaceName, &persesv1alpha2.PersesDashboard{}))
+			}, time.Minute, time.Second).Should(BeTrue())
 		})
 
 		It("should set degraded status with ValidationFailed reason when server-side validation fails", func() {
@@ -655,6 +687,15 @@ var _ = Describe("Dashboard controller", Ordered, func() {
 			Expect(err).To(Not(HaveOccurred()))
 			err = k8sClient.Delete(ctx, dashboardToDelete)
 			Expect(err).To(Not(HaveOccurred()))
+
+			By("Releasing the finalizer once Perses reports the dashboard as absent")
+			_, err = dashboardReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: dashboardNamespaceName,
+			})
+			Expect(err).To(Not(HaveOccurred()))
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, dashboardNamespaceName, &persesv1alpha2.PersesDashboard{}))
+			}, time.Minute, time.Second).Should(BeTrue())
 		})
 	})
 
@@ -752,6 +793,7 @@ var _ = Describe("Dashboard controller", Ordered, func() {
 				Metadata: persesv1.ProjectMetadata{
 					Metadata: persesv1.Metadata{
 						Name: SelectorDashboardName,
+						Tags: common.WithManagedTag(nil),
 					},
 				},
 				Spec: specdashboard.Spec{
@@ -816,7 +858,7 @@ var _ = Describe("Dashboard controller", Ordered, func() {
 			mockDashboard := new(internal.MockDashboard)
 
 			mockPersesClient.On("Dashboard", SelectorNamespace).Return(mockDashboard)
-			mockDashboard.On("Get", SelectorDashboardName).Return(&persesv1.Dashboard{}, perseshttp.RequestNotFoundError)
+			getDashboard := mockDashboard.On("Get", SelectorDashboardName).Return(&persesv1.Dashboard{}, perseshttp.RequestNotFoundError)
 			mockDashboard.On("Create", selectorDashboard).Return(&persesv1.Dashboard{}, nil)
 
 			By("Reconciling the custom resource created")
@@ -838,6 +880,8 @@ var _ = Describe("Dashboard controller", Ordered, func() {
 			mockDashboard.AssertNumberOfCalls(GinkgoT(), "Create", 1)
 
 			By("Cleaning up the dashboard resource")
+			getDashboard.Unset()
+			mockDashboard.On("Get", SelectorDashboardName).Return(selectorDashboard, nil)
 			mockDashboard.On("Delete", SelectorDashboardName).Return(nil)
 
 			dashboardToDelete := &persesv1alpha2.PersesDashboard{}
@@ -851,6 +895,9 @@ var _ = Describe("Dashboard controller", Ordered, func() {
 				NamespacedName: selectorDashboardNamespaceName,
 			})
 			Expect(err).To(Not(HaveOccurred()))
+
+			By("Checking that the dashboard was only deleted from the instance it was synced to")
+			mockDashboard.AssertNumberOfCalls(GinkgoT(), "Delete", 1)
 		})
 
 		It("should sync the dashboard with all Perses instances when no instance selector is provided", func() {
@@ -894,7 +941,7 @@ var _ = Describe("Dashboard controller", Ordered, func() {
 			mockDashboard := new(internal.MockDashboard)
 
 			mockPersesClient.On("Dashboard", SelectorNamespace).Return(mockDashboard)
-			mockDashboard.On("Get", SelectorDashboardName).Return(&persesv1.Dashboard{}, perseshttp.RequestNotFoundError)
+			getDashboard := mockDashboard.On("Get", SelectorDashboardName).Return(&persesv1.Dashboard{}, perseshttp.RequestNotFoundError)
 			mockDashboard.On("Create", selectorDashboard).Return(&persesv1.Dashboard{}, nil)
 
 			By("Reconciling the custom resource created")
@@ -916,6 +963,8 @@ var _ = Describe("Dashboard controller", Ordered, func() {
 			mockDashboard.AssertNumberOfCalls(GinkgoT(), "Create", availableInstances)
 
 			By("Cleaning up the dashboard resource")
+			getDashboard.Unset()
+			mockDashboard.On("Get", SelectorDashboardName).Return(selectorDashboard, nil)
 			mockDashboard.On("Delete", SelectorDashboardName).Return(nil)
 
 			dashboardToDelete := &persesv1alpha2.PersesDashboard{}
@@ -1039,7 +1088,7 @@ var _ = Describe("Dashboard controller", Ordered, func() {
 				Metadata: persesv1.ProjectMetadata{
 					Metadata: persesv1.Metadata{
 						Name: TagsDashboardName,
-						Tags: set.New("oncall", "high_severity", "production"),
+						Tags: set.New("oncall", "high_severity", "production", common.ManagedTag),
 					},
 				},
 				Spec: dashboard.Spec.Config.Spec,
@@ -1067,7 +1116,7 @@ var _ = Describe("Dashboard controller", Ordered, func() {
 			Expect(err).To(Not(HaveOccurred()))
 
 			getDashboard.Unset()
-			mockDashboard.On("Get", TagsDashboardName).Return(&persesv1.Dashboard{}, nil)
+			mockDashboard.On("Get", TagsDashboardName).Return(expectedDashboard, nil)
 
 			By("Checking if the Perses API was called with lowercase-normalized tags")
 			Eventually(func() error {
@@ -17,17 +17,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/perses/common/set"
 	"github.com/perses/perses/pkg/client/api/validate"
 	"github.com/perses/perses/pkg/client/perseshttp"
 	persesv1 "github.com/perses/perses/pkg/model/api/v1"
-	persesv1Common "github.com/perses/perses/pkg/model/api/v1/common"
 
 	logger "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -68,22 +72,169 @@ func (r *PersesDashboardReconciler) reconcileDashboardInAllInstances(ctx context
 		return r.setStatusToDegraded(ctx, req, res, common.ReasonMissingPerses, err)
 	}
 
+	r.instanceSelectors.Set(req.NamespacedName, labelSelector, dashboard.Status.SyncedInstances)
+	// The instances rejecting the dashboard are checked first, so that it is removed from them
+	// as from the instances no longer selected.
+	allowing := make([]persesv1alpha2.Perses, 0, len(persesInstances.Items))
+	rejecting := make(map[persesv1alpha2.PersesInstanceReference]bool)
+	for _, persesInstance := range persesInstances.Items {
+		allowed, err := common.InstanceAllowsResource(ctx, r.APIReader, persesInstance, dashboard)
+		if err != nil {
+			dlog.WithError(err).Errorf("Failed to check if Perses instance %s/%s allows dashboard %s", persesInstance.Namespace, persesInstance.Name, dashboard.Name)
+			res, err := subreconciler.RequeueWithError(err)
+			return r.setStatusToDegraded(ctx, req, res, common.ReasonInvalidConfiguration, err)
+		}
+		if !allowed {
+			rejecting[common.InstanceReference(persesInstance)] = true
+			continue
+		}
+		allowing = append(allowing, persesInstance)
+	}
+
+	blockedRemovals, res, err := r.removeDashboardFromDeselectedInstances(ctx, req, dashboard, allowing)
+	if subreconciler.ShouldHaltOrRequeue(res, err) {
+		return res, err
+	}
+
 	if len(persesInstances.Items) == 0 {
 		dlog.Info("No Perses instances found, retrying in 1 minute")
 		return r.setStatusToDegraded(ctx, req, &ctrl.Result{RequeueAfter: time.Minute}, common.ReasonMissingPerses, fmt.Errorf("no Perses instances found matching the label selector"))
 	}
 
+	available := make([]persesv1alpha2.Perses, 0, len(persesInstances.Items))
+	instances := make([]persesv1alpha2.PersesInstanceStatus, 0, len(persesInstances.Items))
+	var notAllowed []string
 	for _, persesInstance := range persesInstances.Items {
 		if !meta.IsStatusConditionTrue(persesInstance.Status.Conditions, common.TypeAvailablePerses) {
 			dlog.Infof("Skipping Perses instance %s/%s (not yet available)", persesInstance.Namespace, persesInstance.Name)
+			instances = append(instances, common.UnavailableInstanceStatus(dashboard.Status.Instances, persesInstance))
 			continue
 		}
-		if res, reason, err := r.syncPersesDashboard(ctx, persesInstance, dashboard); subreconciler.ShouldHaltOrRequeue(res, err) {
-			return r.setStatusToDegraded(ctx, req, res, reason, err)
+		if rejecting[common.InstanceReference(persesInstance)] {
+			dlog.Infof("Skipping Perses instance %s/%s (dashboard %s not allowed by its resource selectors)", persesInstance.Namespace, persesInstance.Name, dashboard.Name)
+			notAllowed = append(notAllowed, fmt.Sprintf("%s/%s", persesInstance.Namespace, persesInstance.Name))
+			instances = append(instances, common.NotAllowedInstanceStatus(persesInstance))
+			continue
 		}
+		available = append(available, persesInstance)
 	}
 
-	return subreconciler.ContinueReconciling()
+	// Nothing is written to the instances in a dry run, so they are not recorded for deletion.
+	dryRun := common.IsDryRun(dashboard, r.DryRun)
+	if !dryRun {
+		if res, err := r.recordSyncedInstances(ctx, req, available); subreconciler.ShouldHaltOrRequeue(res, err) {
+			return res, err
+		}
+	}
+
+	// A dashboard whose spec and tags were already applied to an instance is not compared
+	// with it again before the next verification.
+	verificationPeriod := common.VerificationPeriod(common.ResyncPeriod(dashboard.Spec.ResyncPeriodSeconds, r.ResyncPeriod), r.VerificationPeriod)
+	tags := common.ParseTags(dashboard.Annotations)
+
+	// Every instance is synced, so that a failing instance does not hold back the others.
+	outcomes := subreconciler.ForEach(available, r.InstanceSyncConcurrency, func(persesInstance persesv1alpha2.Perses) (*ctrl.Result, common.ConditionStatusReason, error) {
+		if common.AlreadyApplied(dashboard.Status.Instances, persesInstance, dashboard.Generation, common.AppliedHash(persesInstance, dashboard.Spec.Config.Spec, tags), verificationPeriod) {
+			dlog.Debugf("Dashboard already applied to Perses instance %s/%s: %s", persesInstance.Namespace, persesInstance.Name, dashboard.Name)
+			res, err := subreconciler.ContinueReconciling()
+			return res, common.ReasonAlreadyApplied, err
+		}
+		return r.syncPersesDashboard(ctx, persesInstance, dashboard)
+	})
+
+	var drifted, planned, failed, failures []string
+	var firstFailure subreconciler.Outcome
+	for i, persesInstance := range available {
+		instanceName := fmt.Sprintf("%s/%s", persesInstance.Namespace, persesInstance.Name)
+		outcome := outcomes[i]
+		if outcome.Halted() {
+			failed = append(failed, instanceName)
+			if len(failures) == 0 {
+				firstFailure = outcome
+			}
+			failures = append(failures, fmt.Sprintf("%s: %v", instanceName, outcome.Err))
+			instances = append(instances, common.FailedInstanceStatus(dashboard.Status.Instances, persesInstance, outcome.Reason))
+			common.RecordInstanceEvent(r.Recorder, dashboard, persesInstance, corev1.EventTypeWarning, string(outcome.Reason), "Failed to sync dashboard: %v", outcome.Err)
+			continue
+		}
+		// The planned changes were not applied, so the last sync status of the instance is kept.
+		if outcome.Reason == common.ReasonDryRun {
+			planned = append(planned, instanceName)
+			if status, found := common.FindInstanceStatus(dashboard.Status.Instances, persesInstance); found {
+				instances = append(instances, status)
+			}
+			continue
+		}
+		driftCorrected := outcome.Reason == common.ReasonDriftCorrected
+		if driftCorrected {
+			drifted = append(drifted, instanceName)
+		}
+		status := common.SyncedInstanceStatus(dashboard.Status.Instances, persesInstance, dashboard.Generation, driftCorrected, common.DashboardURL(persesInstance, dashboard.Namespace, dashboard.Name))
+		if verificationPeriod > 0 && outcome.Reason != common.ReasonAlreadyApplied {
+			status = common.VerifiedInstanceStatus(status, common.AppliedHash(persesInstance, dashboard.Spec.Config.Spec, tags))
+		}
+		instances = append(instances, status)
+	}
+	common.SortInstanceStatuses(instances)
+
+	res, err = r.updateDashboardStatus(ctx, req, func(dashboard *persesv1alpha2.PersesDashboard) {
+		dashboard.Status.Instances = instances
+		if dryRun {
+			meta.SetStatusCondition(&dashboard.Status.Conditions, common.DryRunCondition("Dashboard", dashboard.Name, planned))
+		} else {
+			meta.RemoveStatusCondition(&dashboard.Status.Conditions, common.TypeDryRunPerses)
+			meta.SetStatusCondition(&dashboard.Status.Conditions, common.DriftCondition("Dashboard", dashboard.Name, drifted))
+		}
+		meta.SetStatusCondition(&dashboard.Status.Conditions, common.PartialSyncCondition("Dashboard", dashboard.Name, len(available)-len(failed), failed))
+		meta.SetStatusCondition(&dashboard.Status.Conditions, common.NotAllowedCondition("Dashboard", dashboard.Name, notAllowed))
+	})
+	if subreconciler.ShouldHaltOrRequeue(res, err) {
+		return res, err
+	}
+	if len(available) == 0 && len(notAllowed) > 0 {
+		dlog.Infof("Dashboard %s is not allowed by any available Perses instance", dashboard.Name)
+		return r.setStatusToDegraded(ctx, req, &ctrl.Result{}, common.ReasonNotAllowedByInstance,
+			fmt.Errorf("not allowed by the resource selectors of the Perses instances: %s", strings.Join(notAllowed, ", ")))
+	}
+	if len(failed) == 0 && len(blockedRemovals) > 0 {
+		return r.setStatusToDegraded(ctx, req, &ctrl.Result{RequeueAfter: time.Minute}, common.ReasonDeletionBlocked,
+			fmt.Errorf("dashboard removal is waiting for Perses instances no longer selecting or allowing it: %s", strings.Join(blockedRemovals, "; ")))
+	}
+	if len(failed) == 0 {
+		return res, err
+	}
+
+	dlog.Errorf("Dashboard %s failed to sync to %d of %d Perses instances", dashboard.Name, len(failed), len(available))
+	// A single failure is reported as is, so that its message stays actionable.
+	failure := firstFailure.Err
+	if len(failed) > 1 {
+		failure = fmt.Errorf("failed to sync to %d of %d Perses instances: %s", len(failed), len(available), strings.Join(failures, "; "))
+	}
+	return r.setStatusToDegraded(ctx, req, firstFailure.Result, firstFailure.Reason, failure)
+}
+
+// removeDashboardFromDeselectedInstances removes the dashboard from the Perses instances it was
+// synced to that its instanceSelector no longer selects, e.g. after they were relabeled,
+// or whose resource selectors no longer allow it.
+// It returns a description of every instance that could not confirm the removal.
+func (r *PersesDashboardReconciler) removeDashboardFromDeselectedInstances(ctx context.Context, req ctrl.Request, dashboard *persesv1alpha2.PersesDashboard, selected []persesv1alpha2.Perses) ([]string, *ctrl.Result, error) {
+	deselected := common.DeselectedInstances(dashboard.Status.SyncedInstances, selected)
+	if len(deselected) == 0 {
+		return nil, nil, nil
+	}
+
+	dlog.Infof("Dashboard %s is no longer selected or allowed by %d Perses instances, removing it from them", dashboard.Name, len(deselected))
+	removed, blocked := r.deleteDashboardInInstances(ctx, dashboard, deselected)
+	// Nothing is removed in a dry run, so the instances are kept for the next reconciliation.
+	if len(removed) > 0 && !common.IsDryRun(dashboard, r.DryRun) {
+		res, err := r.updateDashboardStatus(ctx, req, func(dashboard *persesv1alpha2.PersesDashboard) {
+			dashboard.Status.SyncedInstances = common.RemoveInstanceReferences(dashboard.Status.SyncedInstances, removed)
+		})
+		if subreconciler.ShouldHaltOrRequeue(res, err) {
+			return blocked, res, err
+		}
+	}
+	return blocked, nil, nil
 }
 
 func (r *PersesDashboardReconciler) syncPersesDashboard(ctx context.Context, perses persesv1alpha2.Perses, dashboard *persesv1alpha2.PersesDashboard) (*ctrl.Result, common.ConditionStatusReason, error) {
@@ -93,50 +244,46 @@ func (r *PersesDashboardReconciler) syncPersesDashboard(ctx context.Context, per
 		return subreconciler.RequeueWithErrorAndReason(err, common.ReasonConnectionFailed)
 	}
 
-	_, err = persesClient.Project().Get(dashboard.Namespace)
-	if err != nil {
-		if errors.Is(err, perseshttp.RequestNotFoundError) {
-			_, err := persesClient.Project().Create(&persesv1.Project{
-				Kind: "Project",
-				Metadata: persesv1.Metadata{
-					Name: dashboard.Namespace,
-				},
-				Spec: persesv1.ProjectSpec{
-					Display: &persesv1Common.Display{
-						Name: dashboard.Namespace,
-					},
-				},
-			})
-			if err != nil {
-				dlog.WithError(err).Errorf("Failed to create perses project: %s", dashboard.Namespace)
-				return subreconciler.RequeueWithErrorAndReason(err, common.ReasonBackendError)
-			}
-
-			dlog.Infof("Project created: %s", dashboard.Namespace)
-		} else {
-			dlog.WithError(err).Errorf("project error: %s", dashboard.Namespace)
-			return subreconciler.RequeueWithErrorAndReason(err, common.ReasonBackendError)
+	// A dry run does not create the project, the dashboard is then planned for creation.
+	dryRun := common.IsDryRun(dashboard, r.DryRun)
+	if !dryRun {
+		if reason, err := common.EnsureProject(ctx, r.APIReader, persesClient, perses, dashboard.Namespace); err != nil {
+			return subreconciler.RequeueWithErrorAndReason(err, reason)
 		}
 	}
 
+	existing, err := persesClient.Dashboard(dashboard.Namespace).Get(dashboard.Name)
+	notFound := err != nil && errors.Is(err, perseshttp.RequestNotFoundError)
+
+	if err != nil && !notFound {
+		return subreconciler.RequeueWithErrorAndReason(err, common.ReasonBackendError)
+	}
+
+	tags := common.ParseTags(dashboard.Annotations)
+	var existingTags set.Set[string]
+	if !notFound {
+		existingTags = existing.Metadata.Tags
+	}
+	managed, err := common.ClaimOwnership(dashboard.Spec.ConflictPolicy, "dashboard", dashboard.Name, !notFound, existingTags)
+	if err != nil {
+		dlog.WithError(err).Errorf("Dashboard conflict: %s", dashboard.Name)
+		return subreconciler.RequeueWithErrorAndReason(err, common.ReasonConflict)
+	}
+	if managed {
+		tags = common.WithManagedTag(tags)
+	}
+
 	persesDashboard := &persesv1.Dashboard{
 		Kind: persesv1.KindDashboard,
 		Metadata: persesv1.ProjectMetadata{
 			Metadata: persesv1.Metadata{
 				Name: dashboard.Name,
-				Tags: common.ParseTags(dashboard.Annotations),
+				Tags: tags,
 			},
 		},
 		Spec: dashboard.Spec.Config.Spec,
 	}
 
-	existing, err := persesClient.Dashboard(dashboard.Namespace).Get(dashboard.Name)
-	notFound := err != nil && errors.Is(err, perseshttp.RequestNotFoundError)
-
-	if err != nil && !notFound {
-		return subreconciler.RequeueWithErrorAndReason(err, common.ReasonBackendError)
-	}
-
 	if !notFound && common.DashboardInSync(existing, persesDashboard) {
 		dlog.Debugf("Dashboard already in sync: %s", dashboard.Name)
 		res, err := subreconciler.ContinueReconciling()
@@ -158,6 +305,17 @@ func (r *PersesDashboardReconciler) syncPersesDashboard(ctx context.Context, per
 		)
 	}
 
+	if dryRun {
+		plan := "created"
+		if !notFound {
+			plan = fmt.Sprintf("updated: %s", common.FormatDiff(common.DashboardDiff(existing, persesDashboard), common.MaxEventDiffLength))
+		}
+		dlog.Infof("Dry run, dashboard %s would be %s in Perses instance %s/%s", dashboard.Name, plan, perses.Namespace, perses.Name)
+		common.RecordInstanceEvent(r.Recorder, dashboard, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: dashboard would be %s", plan)
+		res, err := subreconciler.ContinueReconciling()
+		return res, common.ReasonDryRun, err
+	}
+
 	if notFound {
 		_, err = persesClient.Dashboard(dashboard.Namespace).Create(persesDashboard)
 		if err != nil {
@@ -165,43 +323,77 @@ func (r *PersesDashboardReconciler) syncPersesDashboard(ctx context.Context, per
 			return subreconciler.RequeueWithErrorAndReason(err, common.ReasonBackendError)
 		}
 		dlog.Infof("Dashboard created: %s", dashboard.Name)
+		common.RecordInstanceEvent(r.Recorder, dashboard, perses, corev1.EventTypeNormal, common.EventReasonCreated, "Dashboard created")
 	} else {
+		changes := common.DashboardDiff(existing, persesDashboard)
 		_, err = persesClient.Dashboard(dashboard.Namespace).Update(persesDashboard)
 		if err != nil {
 			dlog.WithError(err).Errorf("Failed to update dashboard: %s", dashboard.Name)
 			return subreconciler.RequeueWithErrorAndReason(err, common.ReasonBackendError)
 		}
 		dlog.Infof("Dashboard updated: %s", dashboard.Name)
+		dlog.WithField("diff", common.FormatDiff(changes, 0)).Debugf("Dashboard %s changed in Perses instance %s/%s", dashboard.Name, perses.Namespace, perses.Name)
+		eventDiff := common.FormatDiff(changes, common.MaxEventDiffLength)
+
+		// The dashboard already matched this generation of the custom resource,
+		// so the difference was introduced in Perses. Claiming an object written by an earlier
+		// version of the operator is not a drift.
+		if common.SyncedAtGeneration(dashboard.Status.Conditions, dashboard.Generation) && !common.OwnershipClaimOnly(changes) {
+			dlog.Infof("Dashboard drift corrected in Perses instance %s/%s: %s", perses.Namespace, perses.Name, dashboard.Name)
+			if r.Metrics != nil {
+				r.Metrics.DriftCorrections("dashboard").Inc()
+			}
+			common.RecordInstanceEvent(r.Recorder, dashboard, perses, corev1.EventTypeNormal, string(common.ReasonDriftCorrected), "Dashboard modified in Perses, restored from its custom resource: %s", eventDiff)
+			res, err := subreconciler.ContinueReconciling()
+			return res, common.ReasonDriftCorrected, err
+		}
+		common.RecordInstanceEvent(r.Recorder, dashboard, perses, corev1.EventTypeNormal, common.EventReasonUpdated, "Dashboard updated: %s", eventDiff)
 	}
 
 	res, err := subreconciler.ContinueReconciling()
 	return res, "", err
 }
 
-func (r *PersesDashboardReconciler) deleteDashboardInAllInstances(ctx context.Context, _ ctrl.Request, dashbboardNamespace string, dashboardName string) (*ctrl.Result, error) {
-	persesInstances := &persesv1alpha2.PersesList{}
-	var opts []client.ListOption
-	err := r.List(ctx, persesInstances, opts...)
-	if err != nil {
-		dlog.WithError(err).Error("Failed to get perses instances")
-		return subreconciler.RequeueWithError(err)
-	}
+// deleteDashboardInInstances removes the dashboard from the given Perses instances it was
+// synced to. It returns the instances that no longer hold the dashboard, and a
+// description of every instance that could not confirm the removal.
+func (r *PersesDashboardReconciler) deleteDashboardInInstances(ctx context.Context, dashboard *persesv1alpha2.PersesDashboard, refs []persesv1alpha2.PersesInstanceReference) ([]persesv1alpha2.PersesInstanceReference, []string) {
+	var removed []persesv1alpha2.PersesInstanceReference
+	var blocked []string
+
+	for _, ref := range refs {
+		persesInstance := &persesv1alpha2.Perses{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, persesInstance); err != nil {
+			if apierrors.IsNotFound(err) {
+				dlog.Infof("Perses instance %s/%s no longer exists", ref.Namespace, ref.Name)
+				removed = append(removed, ref)
+				continue
+			}
+			dlog.WithError(err).Errorf("Failed to get perses instance %s/%s", ref.Namespace, ref.Name)
+			blocked = append(blocked, fmt.Sprintf("%s/%s (%v)", ref.Namespace, ref.Name, err))
+			continue
+		}
 
-	if len(persesInstances.Items) == 0 {
-		dlog.Info("No Perses instances found")
-		return subreconciler.DoNotRequeue()
-	}
+		if !meta.IsStatusConditionTrue(persesInstance.Status.Conditions, common.TypeAvailablePerses) {
+			dlog.Infof("Perses instance %s/%s is not available, dashboard deletion is blocked", ref.Namespace, ref.Name)
+			blocked = append(blocked, fmt.Sprintf("%s/%s (not available)", ref.Namespace, ref.Name))
+			common.RecordInstanceEvent(r.Recorder, dashboard, *persesInstance, corev1.EventTypeWarning, string(common.ReasonDeletionBlocked), "Dashboard deletion is waiting for the instance to be available")
+			continue
+		}
 
-	for _, persesInstance := range persesInstances.Items {
-		if r, err := r.deleteDashboard(ctx, persesInstance, dashbboardNamespace, dashboardName); subreconciler.ShouldHaltOrRequeue(r, err) {
-			return r, err
+		if _, err := r.deleteDashboard(ctx, *persesInstance, dashboard); err != nil {
+			blocked = append(blocked, fmt.Sprintf("%s/%s (%v)", ref.Namespace, ref.Name, err))
+			common.RecordInstanceEvent(r.Recorder, dashboard, *persesInstance, corev1.EventTypeWarning, string(common.ReasonDeletionBlocked), "Failed to delete dashboard: %v", err)
+			continue
 		}
+		removed = append(removed, ref)
 	}
 
-	return subreconciler.DoNotRequeue()
+	return removed, blocked
 }
 
-func (r *PersesDashboardReconciler) deleteDashboard(ctx context.Context, perses persesv1alpha2.Perses, dashboardNamespace string, dashboardName string) (*ctrl.Result, error) {
+func (r *PersesDashboardReconciler) deleteDashboard(ctx context.Context, perses persesv1alpha2.Perses, dashboard *persesv1alpha2.PersesDashboard) (*ctrl.Result, error) {
+	dashboardNamespace, dashboardName := dashboard.Namespace, dashboard.Name
 	persesClient, err := r.ClientFactory.CreateClient(ctx, r.APIReader, perses)
 	if err != nil {
 		dlog.WithError(err).Error("Failed to create perses rest client")
@@ -210,11 +402,38 @@ func (r *PersesDashboardReconciler) deleteDashboard(ctx context.Context, perses
 
 	_, err = persesClient.Project().Get(dashboardNamespace)
 	if err != nil {
+		// The dashboard went away with its project.
+		if errors.Is(err, perseshttp.RequestNotFoundError) {
+			dlog.Infof("Project not found: %s", dashboardNamespace)
+			return subreconciler.ContinueReconciling()
+		}
 		dlog.WithError(err).Errorf("project error: %s", dashboardNamespace)
 
 		return subreconciler.RequeueWithError(err)
 	}
 
+	existing, err := persesClient.Dashboard(dashboardNamespace).Get(dashboardName)
+	if err != nil {
+		if errors.Is(err, perseshttp.RequestNotFoundError) {
+			dlog.Infof("Dashboard not found: %s", dashboardName)
+			return subreconciler.ContinueReconciling()
+		}
+		dlog.WithError(err).Errorf("Failed to get dashboard: %s", dashboardName)
+		return subreconciler.RequeueWithError(err)
+	}
+
+	// A dashboard the operator does not own was either left untouched or overwritten, it is not removed.
+	if !common.IsManaged(existing.Metadata.Tags) {
+		dlog.Infof("Dashboard not managed by the operator, keeping it: %s", dashboardName)
+		return subreconciler.ContinueReconciling()
+	}
+
+	if common.IsDryRun(dashboard, r.DryRun) {
+		dlog.Infof("Dry run, dashboard %s would be deleted from Perses instance %s/%s", dashboardName, perses.Namespace, perses.Name)
+		common.RecordInstanceEvent(r.Recorder, dashboard, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: dashboard would be deleted")
+		return subreconciler.ContinueReconciling()
+	}
+
 	err = persesClient.Dashboard(dashboardNamespace).Delete(dashboardName)
 	// Ignore NotFound — the resource may have already been deleted from Perses directly.
 	// Any other error means the delete failed and should be retried.
@@ -228,6 +447,11 @@ func (r *PersesDashboardReconciler) deleteDashboard(ctx context.Context, perses
 	}
 
 	dlog.Infof("Dashboard deleted: %s", dashboardName)
+	common.RecordInstanceEvent(r.Recorder, dashboard, perses, corev1.EventTypeNormal, common.EventReasonDeleted, "Dashboard deleted")
+
+	if err := common.CleanupProject(ctx, r.APIReader, persesClient, perses, dashboardNamespace); err != nil {
+		dlog.WithError(err).Errorf("Failed to clean up project: %s", dashboardNamespace)
+	}
 
 	return subreconciler.ContinueReconciling()
 }
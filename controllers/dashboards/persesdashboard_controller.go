@@ -16,6 +16,8 @@ package dashboards
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	logger "github.com/sirupsen/logrus"
@@ -27,8 +29,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/handler"
-	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -60,6 +61,20 @@ type PersesDashboardReconciler struct {
 	ClientFactory         common.PersesClientFactory
 	Metrics               *operatormetrics.Metrics
 	ReconciliationTracker *operatormetrics.ReconciliationTracker
+	// InstanceSyncConcurrency is the maximum number of Perses instances the dashboard
+	// is synced to at the same time. Lower than 1 syncs one instance at a time.
+	InstanceSyncConcurrency int
+	// ResyncPeriod is the period after which a dashboard is synced again to correct drift,
+	// unless overridden by its resyncPeriodSeconds. 0 disables it.
+	ResyncPeriod time.Duration
+	// VerificationPeriod is the period during which a dashboard whose spec and tags were
+	// already applied to a Perses instance is not compared with it again. 0 disables it.
+	VerificationPeriod time.Duration
+	// DryRun only plans the changes to the dashboards in Perses, without applying them,
+	// as for the dashboards with the perses.dev/dry-run annotation.
+	DryRun bool
+	// instanceSelectors lets a change of a Perses instance only enqueue the dashboards it concerns.
+	instanceSelectors *common.InstanceSelectorIndex
 }
 
 var log = logger.WithField("module", "perses_dashboards_controller")
@@ -67,6 +82,8 @@ var log = logger.WithField("module", "perses_dashboards_controller")
 // +kubebuilder:rbac:groups=perses.dev,resources=persesdashboards,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=perses.dev,resources=persesdashboards/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=perses.dev,resources=persesdashboards/finalizers,verbs=update
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get
 func (r *PersesDashboardReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	start := time.Now()
 	objKey := req.String()
@@ -77,18 +94,20 @@ func (r *PersesDashboardReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 
 	log.Infof("Reconciling PersesDashboard: %s/%s", req.Namespace, req.Name)
 
-	// Find once and store in context for all sub-reconcilers, handle deletion if not found
+	// Find once and store in context for all sub-reconcilers
 	dashboard := &persesv1alpha2.PersesDashboard{}
 	if err := r.APIReader.Get(ctx, req.NamespacedName, dashboard); err != nil {
 		if apierrors.IsNotFound(err) {
-			log.Infof("perses dashboard resource not found. Deleting '%s' in '%s'", req.Name, req.Namespace)
+			log.Infof("perses dashboard resource not found. Ignoring '%s' in '%s'", req.Name, req.Namespace)
 			if r.ReconciliationTracker != nil {
 				r.ReconciliationTracker.ForgetObject(objKey)
 			}
 			if r.Metrics != nil {
 				r.Metrics.ForgetObject(objKey)
 			}
-			return subreconciler.Evaluate(r.deleteDashboardInAllInstances(ctx, req, req.Namespace, req.Name))
+			r.instanceSelectors.Forget(req.NamespacedName)
+			// Deletion is handled through the finalizer, so a missing object has nothing left to clean up.
+			return subreconciler.Evaluate(subreconciler.DoNotRequeue())
 		}
 		log.WithError(err).Error("Failed to get perses dashboard")
 		if r.Metrics != nil {
@@ -100,6 +119,9 @@ func (r *PersesDashboardReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	ctx = withDashboard(ctx, dashboard)
 
 	subreconcilersForPerses := []subreconciler.FnWithRequest{
+		r.handleSuspend,
+		r.handleDelete,
+		r.addFinalizer,
 		r.setStatusToUnknown,
 		r.reconcileDashboardInAllInstances,
 		r.setStatusToComplete,
@@ -123,6 +145,11 @@ func (r *PersesDashboardReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 
 	// Track metrics
 	if r.Metrics != nil {
+		suspended := 0
+		if common.IsReconcilePaused(dashboard) {
+			suspended = 1
+		}
+		r.Metrics.SetSuspendedResources(objKey, "dashboard", req.Namespace, suspended)
 		if reconcileErr != nil {
 			reason := string(common.ExtractReason(reconcileErr, "reconciliation_failed"))
 			r.Metrics.ReconcileErrors("persesdashboard", reason).Inc()
@@ -137,9 +164,129 @@ func (r *PersesDashboardReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	}
 
 	log.WithField("duration", time.Since(start)).Debug("dashboard reconciliation completed")
+	if resyncPeriod := common.ResyncPeriod(dashboard.Spec.ResyncPeriodSeconds, r.ResyncPeriod); resyncPeriod > 0 && dashboard.GetDeletionTimestamp() == nil && !common.IsReconcilePaused(dashboard) {
+		return subreconciler.Evaluate(subreconciler.RequeueWithDelay(resyncPeriod))
+	}
 	return subreconciler.Evaluate(subreconciler.DoNotRequeue())
 }
 
+// handleDelete removes the dashboard from every Perses instance it was synced to,
+// then releases the finalizer. While an instance cannot confirm the removal, the
+// finalizer is kept and the blocking instances are reported in the status conditions.
+func (r *PersesDashboardReconciler) handleDelete(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	dashboard, ok := dashboardFromContext(ctx)
+	if !ok {
+		log.Error("dashboard not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("dashboard not found in context"))
+	}
+
+	if dashboard.GetDeletionTimestamp() == nil {
+		return subreconciler.ContinueReconciling()
+	}
+
+	if !controllerutil.ContainsFinalizer(dashboard, common.PersesFinalizer) {
+		return subreconciler.DoNotRequeue()
+	}
+
+	removed, blocked := r.deleteDashboardInInstances(ctx, dashboard, dashboard.Status.SyncedInstances)
+	if len(removed) > 0 {
+		if res, err := r.updateDashboardStatus(ctx, req, func(dashboard *persesv1alpha2.PersesDashboard) {
+			dashboard.Status.SyncedInstances = common.RemoveInstanceReferences(dashboard.Status.SyncedInstances, removed)
+		}); subreconciler.ShouldHaltOrRequeue(res, err) {
+			return res, err
+		}
+	}
+
+	if len(blocked) > 0 {
+		return r.setStatusToDegraded(ctx, req, &ctrl.Result{RequeueAfter: time.Minute}, common.ReasonDeletionBlocked,
+			fmt.Errorf("dashboard deletion is waiting for Perses instances: %s", strings.Join(blocked, "; ")))
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesDashboard{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		if !controllerutil.RemoveFinalizer(fresh, common.PersesFinalizer) {
+			return nil
+		}
+		return r.Update(ctx, fresh)
+	})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to remove finalizer from perses dashboard")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	r.instanceSelectors.Forget(req.NamespacedName)
+	log.Infof("PersesDashboard %s/%s deleted", dashboard.Namespace, dashboard.Name)
+	return subreconciler.DoNotRequeue()
+}
+
+// addFinalizer makes sure the dashboard is not removed from the cluster before
+// it has been removed from the Perses instances it was synced to.
+func (r *PersesDashboardReconciler) addFinalizer(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	dashboard, ok := dashboardFromContext(ctx)
+	if !ok {
+		log.Error("dashboard not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("dashboard not found in context"))
+	}
+
+	if controllerutil.ContainsFinalizer(dashboard, common.PersesFinalizer) {
+		return subreconciler.ContinueReconciling()
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesDashboard{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		if !controllerutil.AddFinalizer(fresh, common.PersesFinalizer) {
+			return nil
+		}
+		return r.Update(ctx, fresh)
+	})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to add finalizer to perses dashboard")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+// recordSyncedInstances adds the Perses instances to the instances the dashboard
+// is synced to, so that the dashboard is removed from them on deletion. It is called
+// before the dashboard is written to the instances, so that a failure in between
+// cannot leave anything behind in Perses.
+func (r *PersesDashboardReconciler) recordSyncedInstances(ctx context.Context, req ctrl.Request, persesInstances []persesv1alpha2.Perses) (*ctrl.Result, error) {
+	dashboard, ok := dashboardFromContext(ctx)
+	if !ok {
+		log.Error("dashboard not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("dashboard not found in context"))
+	}
+
+	var refs []persesv1alpha2.PersesInstanceReference
+	for _, perses := range persesInstances {
+		if ref := common.InstanceReference(perses); !common.HasInstanceReference(dashboard.Status.SyncedInstances, ref) {
+			refs = append(refs, ref)
+		}
+	}
+	if len(refs) == 0 {
+		return subreconciler.ContinueReconciling()
+	}
+
+	return r.updateDashboardStatus(ctx, req, func(dashboard *persesv1alpha2.PersesDashboard) {
+		for _, ref := range refs {
+			dashboard.Status.SyncedInstances = common.AddInstanceReference(dashboard.Status.SyncedInstances, ref)
+		}
+	})
+}
+
 func (r *PersesDashboardReconciler) updateDashboardStatus(
 	ctx context.Context,
 	req ctrl.Request,
@@ -157,8 +304,11 @@ func (r *PersesDashboardReconciler) updateDashboardStatus(
 			return err
 		}
 		before := common.SnapshotConditions(fresh.Status.Conditions)
+		beforeInstances := slices.Clone(fresh.Status.SyncedInstances)
+		beforeInstanceStatuses := slices.Clone(fresh.Status.Instances)
 		updateFn(fresh)
-		if !common.ConditionsChanged(before, fresh.Status.Conditions) {
+		if !common.ConditionsChanged(before, fresh.Status.Conditions) && slices.Equal(beforeInstances, fresh.Status.SyncedInstances) &&
+			!common.InstanceStatusesChanged(beforeInstanceStatuses, fresh.Status.Instances) {
 			return nil
 		}
 		return r.Status().Update(ctx, fresh)
@@ -176,6 +326,37 @@ func (r *PersesDashboardReconciler) updateDashboardStatus(
 	return subreconciler.ContinueReconciling()
 }
 
+// handleSuspend stops the reconciliation of a dashboard paused with the perses.dev/reconcile
+// annotation, leaving it untouched in Perses, and clears the Suspended condition once it is resumed.
+// It runs before handleDelete, but a dashboard being deleted is never paused, so its finalizer still
+// removes it from Perses. The status is only written when the Suspended condition changes.
+func (r *PersesDashboardReconciler) handleSuspend(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	dashboard, ok := dashboardFromContext(ctx)
+	if !ok {
+		log.Error("dashboard not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("dashboard not found in context"))
+	}
+
+	paused := common.IsReconcilePaused(dashboard)
+	if common.SuspendedStatusChanged(dashboard.Status.Conditions, paused) {
+		if res, err := r.updateDashboardStatus(ctx, req, func(dashboard *persesv1alpha2.PersesDashboard) {
+			if paused {
+				meta.SetStatusCondition(&dashboard.Status.Conditions, common.SuspendedCondition("Dashboard", dashboard.Name))
+			} else {
+				meta.RemoveStatusCondition(&dashboard.Status.Conditions, common.TypeSuspendedPerses)
+			}
+		}); subreconciler.ShouldHaltOrRequeue(res, err) {
+			return res, err
+		}
+	}
+
+	if !paused {
+		return subreconciler.ContinueReconciling()
+	}
+	log.Infof("PersesDashboard %s is paused, skipping reconciliation", req.String())
+	return subreconciler.DoNotRequeue()
+}
+
 func (r *PersesDashboardReconciler) setStatusToUnknown(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
 	return r.updateDashboardStatus(ctx, req, func(dashboard *persesv1alpha2.PersesDashboard) {
 		if len(dashboard.Status.Conditions) == 0 {
@@ -187,12 +368,24 @@ func (r *PersesDashboardReconciler) setStatusToUnknown(ctx context.Context, req
 }
 
 func (r *PersesDashboardReconciler) setStatusToComplete(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	// The observed generation is the one that was synced, the custom resource may have changed since.
+	synced, ok := dashboardFromContext(ctx)
+	if !ok {
+		log.Error("dashboard not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("dashboard not found in context"))
+	}
+
+	// Nothing was applied in a dry run, so the availability of the dashboard is left as is.
+	if common.IsDryRun(synced, r.DryRun) {
+		return subreconciler.ContinueReconciling()
+	}
+
 	return r.updateDashboardStatus(ctx, req, func(dashboard *persesv1alpha2.PersesDashboard) {
 		meta.SetStatusCondition(&dashboard.Status.Conditions, metav1.Condition{
-			Type: common.TypeDegradedPerses, Status: metav1.ConditionFalse,
+			Type: common.TypeDegradedPerses, Status: metav1.ConditionFalse, ObservedGeneration: synced.Generation,
 			Reason: "Reconciled", Message: fmt.Sprintf("Dashboard (%s) reconciled successfully", dashboard.Name)})
 		meta.SetStatusCondition(&dashboard.Status.Conditions, metav1.Condition{
-			Type: common.TypeAvailablePerses, Status: metav1.ConditionTrue,
+			Type: common.TypeAvailablePerses, Status: metav1.ConditionTrue, ObservedGeneration: synced.Generation,
 			Reason: "Reconciled", Message: fmt.Sprintf("Dashboard (%s) created successfully", dashboard.Name)})
 	})
 }
@@ -228,28 +421,23 @@ func (r *PersesDashboardReconciler) setStatusToDegraded(
 	return degradedResult, nil
 }
 
-// findDashboardsForPerses returns reconcile requests for all PersesDashboards
-// across all namespaces when a Perses instance becomes available.
-// Each dashboard's instanceSelector labels determine which Perses instances it syncs to.
-// If no instanceSelector is set, the dashboard syncs to all Perses instances.
-func (r *PersesDashboardReconciler) findDashboardsForPerses(ctx context.Context, _ client.Object) []reconcile.Request {
-	return common.MetadataListToRequests(ctx, r.Client, persesv1alpha2.GroupVersion.WithKind("PersesDashboardList"))
-}
-
 // SetupWithManager sets up the controller with the Manager.
 // It watches PersesDashboard resources and also watches Perses instances
-// to trigger re-reconciliation of all dashboards when a Perses instance becomes
-// available. Dashboards are matched to Perses instances via instanceSelector labels.
+// to reconcile the dashboards a Perses instance concerns when it becomes available or changes
+// its resource selectors or its labels: the dashboards whose instanceSelector matches its
+// labels, before or after the change, and the dashboards synced to it, so that a relabeled
+// instance gets the dashboards it is now selected by and loses the others.
 // Create and delete events for Perses instances are ignored because
 // the instance is not yet ready at creation, and deletion is handled by the dashboard's
-// own reconciliation loop.
+// own reconciliation loop through its finalizer.
 func (r *PersesDashboardReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.instanceSelectors = common.NewInstanceSelectorIndex()
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&persesv1alpha2.PersesDashboard{}, builder.OnlyMetadata).
 		Watches(
 			&persesv1alpha2.Perses{},
-			handler.EnqueueRequestsFromMapFunc(r.findDashboardsForPerses),
-			builder.WithPredicates(common.PersesAvailabilityPredicate()),
+			common.EnqueueConcernedResources(r.Client, persesv1alpha2.GroupVersion.WithKind("PersesDashboardList"), r.instanceSelectors),
+			builder.WithPredicates(common.PersesAdmissionPredicate()),
 		).
 		Complete(r)
 }
@@ -21,16 +21,37 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/perses/common/set"
 	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	internal "github.com/perses/perses-operator/internal/perses"
 	"github.com/perses/perses-operator/internal/perses/common"
+	persesclient "github.com/perses/perses/pkg/client/api/v1"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	persesv1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/stretchr/testify/mock"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
+// instanceClientFactory returns the client of each Perses instance by name,
+// and fails to connect to the other instances.
+type instanceClientFactory map[string]persesclient.ClientInterface
+
+func (f instanceClientFactory) CreateClient(_ context.Context, _ client.Reader, perses persesv1alpha2.Perses) (persesclient.ClientInterface, error) {
+	if persesClient, ok := f[perses.Name]; ok {
+		return persesClient, nil
+	}
+	return nil, fmt.Errorf("connection refused")
+}
+
 func TestDashboardController(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Dashboard Controller Suite")
@@ -128,4 +149,729 @@ var _ = Describe("Dashboard controller", func() {
 			Expect(degradedCond.Reason).To(Equal(string(common.ReasonMissingPerses)))
 		})
 	})
+
+	Context("handleDelete", func() {
+		const DashboardName = "test-dashboard"
+		const DashboardNamespace = "default"
+
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: DashboardName, Namespace: DashboardNamespace}}
+
+		newPerses := func(name string, available bool) *persesv1alpha2.Perses {
+			status := metav1.ConditionFalse
+			if available {
+				status = metav1.ConditionTrue
+			}
+			return &persesv1alpha2.Perses{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "monitoring"},
+				Status: persesv1alpha2.PersesStatus{
+					Conditions: []metav1.Condition{{
+						Type:               common.TypeAvailablePerses,
+						Status:             status,
+						Reason:             "Reconciled",
+						LastTransitionTime: metav1.Now(),
+					}},
+				},
+			}
+		}
+
+		deletingDashboard := func(instances ...string) *persesv1alpha2.PersesDashboard {
+			dashboard := &persesv1alpha2.PersesDashboard{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              DashboardName,
+					Namespace:         DashboardNamespace,
+					Finalizers:        []string{common.PersesFinalizer},
+					DeletionTimestamp: &metav1.Time{Time: time.Now()},
+				},
+			}
+			for _, name := range instances {
+				dashboard.Status.SyncedInstances = append(dashboard.Status.SyncedInstances,
+					persesv1alpha2.PersesInstanceReference{Namespace: "monitoring", Name: name})
+			}
+			return dashboard
+		}
+
+		persesDashboard := func(tags set.Set[string]) *persesv1.Dashboard {
+			return &persesv1.Dashboard{
+				Metadata: persesv1.ProjectMetadata{Metadata: persesv1.Metadata{Name: DashboardName, Tags: tags}},
+			}
+		}
+
+		It("should keep the finalizer while a synced instance is not available", func() {
+			dashboard := deletingDashboard("perses")
+			mockPersesClient := &internal.MockClient{}
+
+			r := newTestDashboardReconciler(dashboard, newPerses("perses", false))
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			_, err := r.handleDelete(withDashboard(context.Background(), dashboard), req)
+			Expect(err).To(HaveOccurred())
+			Expect(common.ExtractReason(err, "")).To(Equal(common.ReasonDeletionBlocked))
+			mockPersesClient.AssertNotCalled(GinkgoT(), "Dashboard", DashboardNamespace)
+
+			fresh := &persesv1alpha2.PersesDashboard{}
+			Expect(r.Get(context.Background(), req.NamespacedName, fresh)).To(Succeed())
+			Expect(controllerutil.ContainsFinalizer(fresh, common.PersesFinalizer)).To(BeTrue())
+			Expect(fresh.Status.SyncedInstances).To(HaveLen(1))
+
+			degraded := apimeta.FindStatusCondition(fresh.Status.Conditions, common.TypeDegradedPerses)
+			Expect(degraded).ToNot(BeNil())
+			Expect(degraded.Reason).To(Equal(string(common.ReasonDeletionBlocked)))
+			Expect(degraded.Message).To(ContainSubstring("monitoring/perses (not available)"))
+		})
+
+		It("should only forget the instances that confirmed the deletion", func() {
+			dashboard := deletingDashboard("perses", "perses-broken")
+			mockPersesClient := &internal.MockClient{}
+			mockDashboard := &internal.MockDashboard{}
+			mockPersesClient.On("Dashboard", DashboardNamespace).Return(mockDashboard)
+			mockDashboard.On("Get", DashboardName).Return(persesDashboard(common.WithManagedTag(nil)), nil)
+			mockDashboard.On("Delete", DashboardName).Return(nil).Once()
+			mockDashboard.On("Delete", DashboardName).Return(fmt.Errorf("connection refused")).Once()
+
+			r := newTestDashboardReconciler(dashboard, newPerses("perses", true), newPerses("perses-broken", true))
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			_, err := r.handleDelete(withDashboard(context.Background(), dashboard), req)
+			Expect(common.ExtractReason(err, "")).To(Equal(common.ReasonDeletionBlocked))
+			mockDashboard.AssertExpectations(GinkgoT())
+
+			fresh := &persesv1alpha2.PersesDashboard{}
+			Expect(r.Get(context.Background(), req.NamespacedName, fresh)).To(Succeed())
+			Expect(controllerutil.ContainsFinalizer(fresh, common.PersesFinalizer)).To(BeTrue())
+			Expect(fresh.Status.SyncedInstances).To(Equal([]persesv1alpha2.PersesInstanceReference{
+				{Namespace: "monitoring", Name: "perses-broken"},
+			}))
+		})
+
+		It("should release the finalizer once every synced instance confirmed the deletion", func() {
+			dashboard := deletingDashboard("perses", "perses-gone")
+			mockPersesClient := &internal.MockClient{}
+			mockDashboard := &internal.MockDashboard{}
+			mockPersesClient.On("Dashboard", DashboardNamespace).Return(mockDashboard)
+			mockDashboard.On("Get", DashboardName).Return(persesDashboard(common.WithManagedTag(nil)), nil)
+			mockDashboard.On("Delete", DashboardName).Return(nil).Once()
+
+			r := newTestDashboardReconciler(dashboard, newPerses("perses", true))
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			_, err := r.handleDelete(withDashboard(context.Background(), dashboard), req)
+			Expect(err).ToNot(HaveOccurred())
+			mockDashboard.AssertExpectations(GinkgoT())
+
+			err = r.Get(context.Background(), req.NamespacedName, &persesv1alpha2.PersesDashboard{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should keep a dashboard not managed by the operator in Perses", func() {
+			dashboard := deletingDashboard("perses")
+			mockPersesClient := &internal.MockClient{}
+			mockDashboard := &internal.MockDashboard{}
+			mockPersesClient.On("Dashboard", DashboardNamespace).Return(mockDashboard)
+			mockDashboard.On("Get", DashboardName).Return(persesDashboard(set.New("production")), nil)
+
+			r := newTestDashboardReconciler(dashboard, newPerses("perses", true))
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			_, err := r.handleDelete(withDashboard(context.Background(), dashboard), req)
+			Expect(err).ToNot(HaveOccurred())
+			mockDashboard.AssertNotCalled(GinkgoT(), "Delete", DashboardName)
+
+			err = r.Get(context.Background(), req.NamespacedName, &persesv1alpha2.PersesDashboard{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Context("syncPersesDashboard", func() {
+		const DashboardName = "test-dashboard"
+		const DashboardNamespace = "default"
+
+		perses := persesv1alpha2.Perses{ObjectMeta: metav1.ObjectMeta{Name: "perses", Namespace: "monitoring"}}
+
+		newDashboard := func(policy persesv1alpha2.ConflictPolicy) *persesv1alpha2.PersesDashboard {
+			return &persesv1alpha2.PersesDashboard{
+				ObjectMeta: metav1.ObjectMeta{Name: DashboardName, Namespace: DashboardNamespace},
+				Spec:       persesv1alpha2.PersesDashboardSpec{ConflictPolicy: policy},
+			}
+		}
+
+		unmanagedDashboard := &persesv1.Dashboard{
+			Metadata: persesv1.ProjectMetadata{Metadata: persesv1.Metadata{Name: DashboardName, Tags: set.New("production")}},
+		}
+
+		It("should report a conflict and leave a dashboard not managed by the operator untouched", func() {
+			mockPersesClient := &internal.MockClient{}
+			mockDashboard := &internal.MockDashboard{}
+			mockPersesClient.On("Dashboard", DashboardNamespace).Return(mockDashboard)
+			mockDashboard.On("Get", DashboardName).Return(unmanagedDashboard, nil)
+
+			r := newTestDashboardReconciler()
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			_, reason, err := r.syncPersesDashboard(context.Background(), perses, newDashboard(persesv1alpha2.ConflictPolicyFail))
+			Expect(err).To(HaveOccurred())
+			Expect(reason).To(Equal(common.ReasonConflict))
+			mockDashboard.AssertNotCalled(GinkgoT(), "Update", mock.Anything)
+		})
+
+		It("should overwrite a dashboard not managed by the operator without taking ownership of it", func() {
+			mockPersesClient, validateServer := internal.NewMockClientWithValidation()
+			defer validateServer.Close()
+			mockDashboard := &internal.MockDashboard{}
+			mockPersesClient.On("Dashboard", DashboardNamespace).Return(mockDashboard)
+			mockDashboard.On("Get", DashboardName).Return(unmanagedDashboard, nil)
+			mockDashboard.On("Update", mock.MatchedBy(func(dashboard *persesv1.Dashboard) bool {
+				return !common.IsManaged(dashboard.Metadata.Tags)
+			})).Return(&persesv1.Dashboard{}, nil).Once()
+
+			r := newTestDashboardReconciler()
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			_, _, err := r.syncPersesDashboard(context.Background(), perses, newDashboard(persesv1alpha2.ConflictPolicyOverwrite))
+			Expect(err).ToNot(HaveOccurred())
+			mockDashboard.AssertExpectations(GinkgoT())
+		})
+
+		It("should report drift only when the dashboard was already synced at its current generation", func() {
+			mockPersesClient, validateServer := internal.NewMockClientWithValidation()
+			defer validateServer.Close()
+			mockDashboard := &internal.MockDashboard{}
+			mockPersesClient.On("Dashboard", DashboardNamespace).Return(mockDashboard)
+			mockDashboard.On("Get", DashboardName).Return(&persesv1.Dashboard{
+				Metadata: persesv1.ProjectMetadata{Metadata: persesv1.Metadata{Name: DashboardName, Tags: common.WithManagedTag(set.New("edited-in-perses"))}},
+			}, nil)
+			mockDashboard.On("Update", mock.Anything).Return(&persesv1.Dashboard{}, nil)
+
+			recorder := record.NewFakeRecorder(2)
+			r := newTestDashboardReconciler()
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+			r.Recorder = recorder
+
+			dashboard := newDashboard(persesv1alpha2.ConflictPolicyAdopt)
+			dashboard.Generation = 2
+			dashboard.Status.Conditions = []metav1.Condition{{
+				Type: common.TypeAvailablePerses, Status: metav1.ConditionTrue, ObservedGeneration: 2,
+			}}
+
+			_, reason, err := r.syncPersesDashboard(context.Background(), perses, dashboard)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reason).To(Equal(common.ReasonDriftCorrected))
+			Expect(recorder.Events).To(Receive(And(
+				HavePrefix("Normal DriftCorrected Perses instance monitoring/perses: Dashboard modified in Perses, restored from its custom resource: "),
+				ContainSubstring(`{"path":"tags","old":["edited-in-perses",`),
+			)))
+
+			dashboard.Generation = 3
+			_, reason, err = r.syncPersesDashboard(context.Background(), perses, dashboard)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reason).To(BeEmpty())
+		})
+
+		It("should tag a dashboard written by an earlier version of the operator without reporting drift", func() {
+			mockPersesClient, validateServer := internal.NewMockClientWithValidation()
+			defer validateServer.Close()
+			mockDashboard := &internal.MockDashboard{}
+			mockPersesClient.On("Dashboard", DashboardNamespace).Return(mockDashboard)
+			mockDashboard.On("Get", DashboardName).Return(&persesv1.Dashboard{
+				Metadata: persesv1.ProjectMetadata{Metadata: persesv1.Metadata{Name: DashboardName}},
+			}, nil)
+			mockDashboard.On("Update", mock.MatchedBy(func(dashboard *persesv1.Dashboard) bool {
+				return common.IsManaged(dashboard.Metadata.Tags)
+			})).Return(&persesv1.Dashboard{}, nil).Once()
+
+			recorder := record.NewFakeRecorder(1)
+			r := newTestDashboardReconciler()
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+			r.Recorder = recorder
+
+			dashboard := newDashboard(persesv1alpha2.ConflictPolicyAdopt)
+			dashboard.Generation = 2
+			dashboard.Status.Conditions = []metav1.Condition{{
+				Type: common.TypeAvailablePerses, Status: metav1.ConditionTrue, ObservedGeneration: 2,
+			}}
+
+			_, reason, err := r.syncPersesDashboard(context.Background(), perses, dashboard)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reason).To(BeEmpty())
+			mockDashboard.AssertExpectations(GinkgoT())
+			Expect(recorder.Events).To(Receive(HavePrefix("Normal Updated Perses instance monitoring/perses: Dashboard updated: ")))
+		})
+	})
+
+	Context("reconcileDashboardInAllInstances", func() {
+		const DashboardName = "test-dashboard"
+		const DashboardNamespace = "default"
+
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: DashboardName, Namespace: DashboardNamespace}}
+
+		var recorder *record.FakeRecorder
+		BeforeEach(func() {
+			recorder = record.NewFakeRecorder(10)
+		})
+
+		newPerses := func(name string, available bool) *persesv1alpha2.Perses {
+			status := metav1.ConditionFalse
+			if available {
+				status = metav1.ConditionTrue
+			}
+			return &persesv1alpha2.Perses{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "monitoring"},
+				Status: persesv1alpha2.PersesStatus{
+					Conditions: []metav1.Condition{{Type: common.TypeAvailablePerses, Status: status}},
+				},
+			}
+		}
+
+		reconcile := func(clientFactory common.PersesClientFactory, instances ...runtime.Object) (*persesv1alpha2.PersesDashboard, error) {
+			dashboard := &persesv1alpha2.PersesDashboard{
+				ObjectMeta: metav1.ObjectMeta{Name: DashboardName, Namespace: DashboardNamespace, Generation: 1},
+			}
+			r := newTestDashboardReconciler(append(instances, dashboard)...)
+			r.ClientFactory = clientFactory
+			r.InstanceSyncConcurrency = 2
+			r.Recorder = recorder
+
+			fresh := &persesv1alpha2.PersesDashboard{}
+			Expect(r.Get(context.Background(), req.NamespacedName, fresh)).To(Succeed())
+			_, err := r.reconcileDashboardInAllInstances(withDashboard(context.Background(), fresh), req)
+
+			Expect(r.Get(context.Background(), req.NamespacedName, fresh)).To(Succeed())
+			return fresh, err
+		}
+
+		It("should report the sync status of the dashboard in each selected Perses instance", func() {
+			mockPersesClient, validateServer := internal.NewMockClientWithValidation()
+			defer validateServer.Close()
+			mockDashboard := &internal.MockDashboard{}
+			mockPersesClient.On("Dashboard", DashboardNamespace).Return(mockDashboard)
+			mockDashboard.On("Get", DashboardName).Return(&persesv1.Dashboard{}, perseshttp.RequestNotFoundError)
+			mockDashboard.On("Create", mock.Anything).Return(&persesv1.Dashboard{}, nil)
+
+			dashboard, err := reconcile(common.NewWithClient(mockPersesClient), newPerses("perses", true), newPerses("perses-staging", false))
+			Expect(err).ToNot(HaveOccurred())
+
+			synced, found := common.FindInstanceStatus(dashboard.Status.Instances, *newPerses("perses", true))
+			Expect(found).To(BeTrue())
+			Expect(synced.State).To(Equal(persesv1alpha2.InstanceSyncStateSynced))
+			Expect(synced.ObservedGeneration).To(Equal(int64(1)))
+			Expect(synced.LastSyncTime).ToNot(BeNil())
+			Expect(synced.URL).To(Equal("http://perses.monitoring.svc.cluster.local:8080/projects/default/dashboards/test-dashboard"))
+
+			unavailable, found := common.FindInstanceStatus(dashboard.Status.Instances, *newPerses("perses-staging", false))
+			Expect(found).To(BeTrue())
+			Expect(unavailable.State).To(Equal(persesv1alpha2.InstanceSyncStateUnavailable))
+		})
+
+		It("should report the reason of a failed sync in the failing Perses instance", func() {
+			mockPersesClient, validateServer := internal.NewMockClientWithValidation()
+			defer validateServer.Close()
+			mockDashboard := &internal.MockDashboard{}
+			mockPersesClient.On("Dashboard", DashboardNamespace).Return(mockDashboard)
+			mockDashboard.On("Get", DashboardName).Return(&persesv1.Dashboard{}, perseshttp.RequestNotFoundError)
+			mockDashboard.On("Create", mock.Anything).Return(&persesv1.Dashboard{}, perseshttp.RequestInternalError)
+
+			dashboard, err := reconcile(common.NewWithClient(mockPersesClient), newPerses("perses", true), newPerses("perses-staging", false))
+			Expect(err).To(HaveOccurred())
+
+			failed, found := common.FindInstanceStatus(dashboard.Status.Instances, *newPerses("perses", true))
+			Expect(found).To(BeTrue())
+			Expect(failed.State).To(Equal(persesv1alpha2.InstanceSyncStateFailed))
+			Expect(failed.LastErrorReason).To(Equal(string(common.ReasonBackendError)))
+		})
+
+		It("should sync the dashboard to the healthy Perses instances when another one fails", func() {
+			mockPersesClient, validateServer := internal.NewMockClientWithValidation()
+			defer validateServer.Close()
+			mockDashboard := &internal.MockDashboard{}
+			mockPersesClient.On("Dashboard", DashboardNamespace).Return(mockDashboard)
+			mockDashboard.On("Get", DashboardName).Return(&persesv1.Dashboard{}, perseshttp.RequestNotFoundError)
+			mockDashboard.On("Create", mock.Anything).Return(&persesv1.Dashboard{}, nil)
+
+			clientFactory := instanceClientFactory{"perses-a": mockPersesClient, "perses-c": mockPersesClient}
+			dashboard, err := reconcile(clientFactory, newPerses("perses-a", true), newPerses("perses-b", true), newPerses("perses-c", true))
+			Expect(err).To(HaveOccurred())
+			mockDashboard.AssertNumberOfCalls(GinkgoT(), "Create", 2)
+
+			Expect(dashboard.Status.Instances).To(HaveLen(3))
+			Expect(dashboard.Status.Instances[0].State).To(Equal(persesv1alpha2.InstanceSyncStateSynced))
+			Expect(dashboard.Status.Instances[1].State).To(Equal(persesv1alpha2.InstanceSyncStateFailed))
+			Expect(dashboard.Status.Instances[1].LastErrorReason).To(Equal(string(common.ReasonConnectionFailed)))
+			Expect(dashboard.Status.Instances[2].State).To(Equal(persesv1alpha2.InstanceSyncStateSynced))
+
+			partiallySynced := apimeta.FindStatusCondition(dashboard.Status.Conditions, common.TypePartiallySyncedPerses)
+			Expect(partiallySynced).ToNot(BeNil())
+			Expect(partiallySynced.Status).To(Equal(metav1.ConditionTrue))
+			Expect(partiallySynced.Message).To(ContainSubstring("monitoring/perses-b"))
+			Expect(apimeta.IsStatusConditionTrue(dashboard.Status.Conditions, common.TypeDegradedPerses)).To(BeTrue())
+		})
+
+		It("should not call Perses for a dashboard already applied until its spec or tags change", func() {
+			mockPersesClient, validateServer := internal.NewMockClientWithValidation()
+			defer validateServer.Close()
+			mockDashboard := &internal.MockDashboard{}
+			mockPersesClient.On("Dashboard", DashboardNamespace).Return(mockDashboard)
+			mockDashboard.On("Get", DashboardName).Return(&persesv1.Dashboard{}, perseshttp.RequestNotFoundError)
+			mockDashboard.On("Create", mock.Anything).Return(&persesv1.Dashboard{}, nil)
+
+			r := newTestDashboardReconciler(newPerses("perses", true), &persesv1alpha2.PersesDashboard{
+				ObjectMeta: metav1.ObjectMeta{Name: DashboardName, Namespace: DashboardNamespace, Generation: 1},
+			})
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+			r.VerificationPeriod = time.Hour
+
+			sync := func() *persesv1alpha2.PersesDashboard {
+				fresh := &persesv1alpha2.PersesDashboard{}
+				Expect(r.Get(context.Background(), req.NamespacedName, fresh)).To(Succeed())
+				_, err := r.reconcileDashboardInAllInstances(withDashboard(context.Background(), fresh), req)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(r.Get(context.Background(), req.NamespacedName, fresh)).To(Succeed())
+				return fresh
+			}
+
+			dashboard := sync()
+			applied, found := common.FindInstanceStatus(dashboard.Status.Instances, *newPerses("perses", true))
+			Expect(found).To(BeTrue())
+			Expect(applied.LastAppliedHash).ToNot(BeEmpty())
+			Expect(applied.LastVerifiedTime).ToNot(BeNil())
+
+			dashboard = sync()
+			mockDashboard.AssertNumberOfCalls(GinkgoT(), "Get", 1)
+			Expect(dashboard.Status.Instances).To(Equal([]persesv1alpha2.PersesInstanceStatus{applied}))
+
+			By("Changing the tags of the dashboard")
+			dashboard.Annotations = map[string]string{common.TagsAnnotation: "production"}
+			Expect(r.Update(context.Background(), dashboard)).To(Succeed())
+			dashboard = sync()
+			mockDashboard.AssertNumberOfCalls(GinkgoT(), "Get", 2)
+
+			retagged, _ := common.FindInstanceStatus(dashboard.Status.Instances, *newPerses("perses", true))
+			Expect(retagged.LastAppliedHash).ToNot(Equal(applied.LastAppliedHash))
+		})
+
+		It("should remove the dashboard from a Perses instance relabeled away from its instanceSelector", func() {
+			mockOldClient, oldValidateServer := internal.NewMockClientWithValidation()
+			defer oldValidateServer.Close()
+			mockOldDashboard := &internal.MockDashboard{}
+			mockOldClient.On("Dashboard", DashboardNamespace).Return(mockOldDashboard)
+			mockOldDashboard.On("Get", DashboardName).Return(&persesv1.Dashboard{
+				Metadata: persesv1.ProjectMetadata{Metadata: persesv1.Metadata{Name: DashboardName, Tags: common.WithManagedTag(nil)}},
+			}, nil)
+			mockOldDashboard.On("Delete", DashboardName).Return(nil)
+
+			mockNewClient, newValidateServer := internal.NewMockClientWithValidation()
+			defer newValidateServer.Close()
+			mockNewDashboard := &internal.MockDashboard{}
+			mockNewClient.On("Dashboard", DashboardNamespace).Return(mockNewDashboard)
+			mockNewDashboard.On("Get", DashboardName).Return(&persesv1.Dashboard{}, perseshttp.RequestNotFoundError)
+			mockNewDashboard.On("Create", mock.Anything).Return(&persesv1.Dashboard{}, nil)
+
+			relabeled := newPerses("perses-old", true)
+			selected := newPerses("perses-new", true)
+			selected.Labels = map[string]string{"env": "prod"}
+			dashboard := &persesv1alpha2.PersesDashboard{
+				ObjectMeta: metav1.ObjectMeta{Name: DashboardName, Namespace: DashboardNamespace, Generation: 1},
+				Spec: persesv1alpha2.PersesDashboardSpec{
+					InstanceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+				},
+				Status: persesv1alpha2.PersesDashboardStatus{
+					SyncedInstances: []persesv1alpha2.PersesInstanceReference{{Namespace: "monitoring", Name: "perses-old"}},
+				},
+			}
+
+			r := newTestDashboardReconciler(relabeled, selected, dashboard)
+			r.ClientFactory = instanceClientFactory{"perses-old": mockOldClient, "perses-new": mockNewClient}
+			r.Recorder = recorder
+
+			fresh := &persesv1alpha2.PersesDashboard{}
+			Expect(r.Get(context.Background(), req.NamespacedName, fresh)).To(Succeed())
+			_, err := r.reconcileDashboardInAllInstances(withDashboard(context.Background(), fresh), req)
+			Expect(err).ToNot(HaveOccurred())
+			mockOldDashboard.AssertCalled(GinkgoT(), "Delete", DashboardName)
+			mockNewDashboard.AssertNumberOfCalls(GinkgoT(), "Create", 1)
+
+			Expect(r.Get(context.Background(), req.NamespacedName, fresh)).To(Succeed())
+			Expect(fresh.Status.SyncedInstances).To(Equal([]persesv1alpha2.PersesInstanceReference{
+				{Namespace: "monitoring", Name: "perses-new"},
+			}))
+			_, found := common.FindInstanceStatus(fresh.Status.Instances, *relabeled)
+			Expect(found).To(BeFalse())
+		})
+
+		It("should record an event for each Perses instance the dashboard is synced to or failed to sync to", func() {
+			mockPersesClient, validateServer := internal.NewMockClientWithValidation()
+			defer validateServer.Close()
+			mockDashboard := &internal.MockDashboard{}
+			mockPersesClient.On("Dashboard", DashboardNamespace).Return(mockDashboard)
+			mockDashboard.On("Get", DashboardName).Return(&persesv1.Dashboard{}, perseshttp.RequestNotFoundError)
+			mockDashboard.On("Create", mock.Anything).Return(&persesv1.Dashboard{}, nil)
+
+			clientFactory := instanceClientFactory{"perses-a": mockPersesClient}
+			_, err := reconcile(clientFactory, newPerses("perses-a", true), newPerses("perses-b", true))
+			Expect(err).To(HaveOccurred())
+
+			Expect(recorder.Events).To(HaveLen(2))
+			Expect(recorder.Events).To(Receive(Equal("Normal Created Perses instance monitoring/perses-a: Dashboard created")))
+			Expect(recorder.Events).To(Receive(HavePrefix("Warning PersesConnectionFailed Perses instance monitoring/perses-b: Failed to sync dashboard:")))
+		})
+
+		It("should only plan the changes of a dashboard in dry run", func() {
+			mockPersesClient, validateServer := internal.NewMockClientWithValidation()
+			defer validateServer.Close()
+			mockDashboard := &internal.MockDashboard{}
+			mockPersesClient.On("Dashboard", DashboardNamespace).Return(mockDashboard)
+			mockDashboard.On("Get", DashboardName).Return(&persesv1.Dashboard{}, perseshttp.RequestNotFoundError)
+
+			dashboard := &persesv1alpha2.PersesDashboard{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: DashboardName, Namespace: DashboardNamespace, Generation: 1,
+					Annotations: map[string]string{common.PersesDryRunAnnotation: common.PersesDryRunEnabled},
+				},
+			}
+			r := newTestDashboardReconciler(newPerses("perses", true), dashboard)
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+			r.Recorder = recorder
+
+			ctx := withDashboard(context.Background(), dashboard)
+			_, err := r.reconcileDashboardInAllInstances(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = r.setStatusToComplete(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			mockDashboard.AssertNotCalled(GinkgoT(), "Create", mock.Anything)
+
+			Expect(recorder.Events).To(Receive(Equal("Normal DryRun Perses instance monitoring/perses: Dry run: dashboard would be created")))
+
+			fresh := &persesv1alpha2.PersesDashboard{}
+			Expect(r.Get(context.Background(), req.NamespacedName, fresh)).To(Succeed())
+			Expect(fresh.Status.SyncedInstances).To(BeEmpty())
+			Expect(fresh.Status.Instances).To(BeEmpty())
+			Expect(apimeta.FindStatusCondition(fresh.Status.Conditions, common.TypeAvailablePerses)).To(BeNil())
+
+			dryRun := apimeta.FindStatusCondition(fresh.Status.Conditions, common.TypeDryRunPerses)
+			Expect(dryRun).ToNot(BeNil())
+			Expect(dryRun.Reason).To(Equal(string(common.ReasonDryRun)))
+			Expect(dryRun.Message).To(ContainSubstring("monitoring/perses"))
+		})
+
+		It("should not sync the dashboard to the Perses instances whose resource selectors reject it", func() {
+			mockPersesClient, validateServer := internal.NewMockClientWithValidation()
+			defer validateServer.Close()
+			mockDashboard := &internal.MockDashboard{}
+			mockPersesClient.On("Dashboard", DashboardNamespace).Return(mockDashboard)
+			mockDashboard.On("Get", DashboardName).Return(&persesv1.Dashboard{}, perseshttp.RequestNotFoundError)
+			mockDashboard.On("Create", mock.Anything).Return(&persesv1.Dashboard{}, nil)
+
+			restricted := newPerses("perses-prod", true)
+			restricted.Spec.ResourceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}}
+
+			clientFactory := instanceClientFactory{"perses": mockPersesClient}
+			dashboard, err := reconcile(clientFactory, newPerses("perses", true), restricted)
+			Expect(err).ToNot(HaveOccurred())
+			mockDashboard.AssertNumberOfCalls(GinkgoT(), "Create", 1)
+
+			notAllowed, found := common.FindInstanceStatus(dashboard.Status.Instances, *restricted)
+			Expect(found).To(BeTrue())
+			Expect(notAllowed.State).To(Equal(persesv1alpha2.InstanceSyncStateNotAllowed))
+			Expect(dashboard.Status.SyncedInstances).To(Equal([]persesv1alpha2.PersesInstanceReference{
+				{Namespace: "monitoring", Name: "perses"},
+			}))
+
+			condition := apimeta.FindStatusCondition(dashboard.Status.Conditions, common.TypeNotAllowedPerses)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Message).To(ContainSubstring("monitoring/perses-prod"))
+		})
+
+		It("should remove the dashboard from a Perses instance whose resource selectors stopped allowing it", func() {
+			mockPersesClient, validateServer := internal.NewMockClientWithValidation()
+			defer validateServer.Close()
+			mockDashboard := &internal.MockDashboard{}
+			mockPersesClient.On("Dashboard", DashboardNamespace).Return(mockDashboard)
+			mockDashboard.On("Get", DashboardName).Return(&persesv1.Dashboard{
+				Metadata: persesv1.ProjectMetadata{Metadata: persesv1.Metadata{Name: DashboardName, Tags: common.WithManagedTag(nil)}},
+			}, nil)
+			mockDashboard.On("Delete", DashboardName).Return(nil)
+
+			restricted := newPerses("perses-prod", true)
+			restricted.Spec.ResourceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}}
+			lastSync := metav1.Now()
+			dashboard := &persesv1alpha2.PersesDashboard{
+				ObjectMeta: metav1.ObjectMeta{Name: DashboardName, Namespace: DashboardNamespace, Generation: 1},
+				Status: persesv1alpha2.PersesDashboardStatus{
+					SyncedInstances: []persesv1alpha2.PersesInstanceReference{{Namespace: "monitoring", Name: "perses-prod"}},
+					Instances: []persesv1alpha2.PersesInstanceStatus{{
+						Namespace: "monitoring", Name: "perses-prod", State: persesv1alpha2.InstanceSyncStateSynced,
+						LastSyncTime: &lastSync, ObservedGeneration: 1,
+					}},
+				},
+			}
+
+			r := newTestDashboardReconciler(restricted, dashboard)
+			r.ClientFactory = instanceClientFactory{"perses-prod": mockPersesClient}
+			r.Recorder = recorder
+
+			fresh := &persesv1alpha2.PersesDashboard{}
+			Expect(r.Get(context.Background(), req.NamespacedName, fresh)).To(Succeed())
+			_, err := r.reconcileDashboardInAllInstances(withDashboard(context.Background(), fresh), req)
+			Expect(err).To(HaveOccurred())
+			mockDashboard.AssertCalled(GinkgoT(), "Delete", DashboardName)
+			mockDashboard.AssertNotCalled(GinkgoT(), "Create", mock.Anything)
+
+			Expect(r.Get(context.Background(), req.NamespacedName, fresh)).To(Succeed())
+			Expect(fresh.Status.SyncedInstances).To(BeEmpty())
+			Expect(fresh.Status.Instances).To(Equal([]persesv1alpha2.PersesInstanceStatus{{
+				Namespace: "monitoring", Name: "perses-prod", State: persesv1alpha2.InstanceSyncStateNotAllowed,
+			}}))
+			Expect(recorder.Events).To(Receive(ContainSubstring("monitoring/perses-prod: Dashboard deleted")))
+		})
+
+		It("should degrade the dashboard when every available Perses instance rejects it", func() {
+			restricted := newPerses("perses-prod", true)
+			restricted.Spec.ResourceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}}
+
+			dashboard, err := reconcile(instanceClientFactory{}, restricted)
+			Expect(err).To(HaveOccurred())
+
+			degraded := apimeta.FindStatusCondition(dashboard.Status.Conditions, common.TypeDegradedPerses)
+			Expect(degraded).ToNot(BeNil())
+			Expect(degraded.Status).To(Equal(metav1.ConditionTrue))
+			Expect(degraded.Reason).To(Equal(string(common.ReasonNotAllowedByInstance)))
+		})
+	})
+
+	Context("handleSuspend", func() {
+		const DashboardName = "test-dashboard"
+		const DashboardNamespace = "default"
+
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: DashboardName, Namespace: DashboardNamespace}}
+
+		It("should leave a paused dashboard untouched in Perses until it is resumed", func() {
+			dashboard := &persesv1alpha2.PersesDashboard{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: DashboardName, Namespace: DashboardNamespace,
+					Annotations: map[string]string{common.PersesReconcileAnnotation: common.PersesReconcilePaused},
+				},
+			}
+			perses := &persesv1alpha2.Perses{
+				ObjectMeta: metav1.ObjectMeta{Name: "perses", Namespace: "monitoring"},
+				Status: persesv1alpha2.PersesStatus{
+					Conditions: []metav1.Condition{{Type: common.TypeAvailablePerses, Status: metav1.ConditionTrue}},
+				},
+			}
+			mockPersesClient, validateServer := internal.NewMockClientWithValidation()
+			defer validateServer.Close()
+			mockDashboard := &internal.MockDashboard{}
+			mockPersesClient.On("Dashboard", DashboardNamespace).Return(mockDashboard)
+			mockDashboard.On("Get", DashboardName).Return(&persesv1.Dashboard{}, perseshttp.RequestNotFoundError)
+			mockDashboard.On("Create", mock.Anything).Return(&persesv1.Dashboard{}, nil)
+
+			r := newTestDashboardReconciler(dashboard, perses)
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			_, err := r.Reconcile(context.Background(), req)
+			Expect(err).ToNot(HaveOccurred())
+			mockPersesClient.AssertNotCalled(GinkgoT(), "Dashboard", DashboardNamespace)
+
+			fresh := &persesv1alpha2.PersesDashboard{}
+			Expect(r.Get(context.Background(), req.NamespacedName, fresh)).To(Succeed())
+			Expect(apimeta.IsStatusConditionTrue(fresh.Status.Conditions, common.TypeSuspendedPerses)).To(BeTrue())
+
+			fresh.Annotations = nil
+			Expect(r.Update(context.Background(), fresh)).To(Succeed())
+			_, err = r.Reconcile(context.Background(), req)
+			Expect(err).ToNot(HaveOccurred())
+			mockDashboard.AssertNumberOfCalls(GinkgoT(), "Create", 1)
+
+			Expect(r.Get(context.Background(), req.NamespacedName, fresh)).To(Succeed())
+			Expect(apimeta.FindStatusCondition(fresh.Status.Conditions, common.TypeSuspendedPerses)).To(BeNil())
+		})
+
+		It("should only write the status of a paused dashboard once", func() {
+			dashboard := &persesv1alpha2.PersesDashboard{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: DashboardName, Namespace: DashboardNamespace,
+					Annotations: map[string]string{common.PersesReconcileAnnotation: common.PersesReconcilePaused},
+				},
+			}
+
+			r := newTestDashboardReconciler(dashboard)
+
+			_, err := r.Reconcile(context.Background(), req)
+			Expect(err).ToNot(HaveOccurred())
+			suspended := &persesv1alpha2.PersesDashboard{}
+			Expect(r.Get(context.Background(), req.NamespacedName, suspended)).To(Succeed())
+			Expect(apimeta.IsStatusConditionTrue(suspended.Status.Conditions, common.TypeSuspendedPerses)).To(BeTrue())
+
+			_, err = r.Reconcile(context.Background(), req)
+			Expect(err).ToNot(HaveOccurred())
+			fresh := &persesv1alpha2.PersesDashboard{}
+			Expect(r.Get(context.Background(), req.NamespacedName, fresh)).To(Succeed())
+			Expect(fresh.ResourceVersion).To(Equal(suspended.ResourceVersion))
+		})
+
+		It("should still remove a paused dashboard from Perses when it is deleted", func() {
+			dashboard := &persesv1alpha2.PersesDashboard{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: DashboardName, Namespace: DashboardNamespace,
+					Annotations:       map[string]string{common.PersesReconcileAnnotation: common.PersesReconcilePaused},
+					Finalizers:        []string{common.PersesFinalizer},
+					DeletionTimestamp: &metav1.Time{Time: time.Now()},
+				},
+				Status: persesv1alpha2.PersesDashboardStatus{
+					Conditions:      []metav1.Condition{common.SuspendedCondition("Dashboard", DashboardName)},
+					SyncedInstances: []persesv1alpha2.PersesInstanceReference{{Namespace: "monitoring", Name: "perses"}},
+				},
+			}
+			dashboard.Status.Conditions[0].LastTransitionTime = metav1.Now()
+			perses := &persesv1alpha2.Perses{
+				ObjectMeta: metav1.ObjectMeta{Name: "perses", Namespace: "monitoring"},
+				Status: persesv1alpha2.PersesStatus{
+					Conditions: []metav1.Condition{{
+						Type: common.TypeAvailablePerses, Status: metav1.ConditionTrue,
+						Reason: "Reconciled", LastTransitionTime: metav1.Now(),
+					}},
+				},
+			}
+			mockPersesClient := &internal.MockClient{}
+			mockDashboard := &internal.MockDashboard{}
+			mockPersesClient.On("Dashboard", DashboardNamespace).Return(mockDashboard)
+			mockDashboard.On("Get", DashboardName).Return(&persesv1.Dashboard{
+				Metadata: persesv1.ProjectMetadata{Metadata: persesv1.Metadata{Name: DashboardName, Tags: common.WithManagedTag(nil)}},
+			}, nil)
+			mockDashboard.On("Delete", DashboardName).Return(nil).Once()
+
+			r := newTestDashboardReconciler(dashboard, perses)
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			_, err := r.Reconcile(context.Background(), req)
+			Expect(err).ToNot(HaveOccurred())
+			mockDashboard.AssertExpectations(GinkgoT())
+
+			err = r.Get(context.Background(), req.NamespacedName, &persesv1alpha2.PersesDashboard{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Context("recordSyncedInstances", func() {
+		It("should record each Perses instance once", func() {
+			dashboard := &persesv1alpha2.PersesDashboard{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-dashboard", Namespace: "default"},
+			}
+			perses := persesv1alpha2.Perses{ObjectMeta: metav1.ObjectMeta{Name: "perses", Namespace: "monitoring"}}
+
+			r := newTestDashboardReconciler(dashboard)
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-dashboard", Namespace: "default"}}
+
+			for range 2 {
+				fresh := &persesv1alpha2.PersesDashboard{}
+				Expect(r.Get(context.Background(), req.NamespacedName, fresh)).To(Succeed())
+				_, err := r.recordSyncedInstances(withDashboard(context.Background(), fresh), req, []persesv1alpha2.Perses{perses})
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			fresh := &persesv1alpha2.PersesDashboard{}
+			Expect(r.Get(context.Background(), req.NamespacedName, fresh)).To(Succeed())
+			Expect(fresh.Status.SyncedInstances).To(Equal([]persesv1alpha2.PersesInstanceReference{
+				{Namespace: "monitoring", Name: "perses"},
+			}))
+		})
+	})
 })
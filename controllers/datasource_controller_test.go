@@ -131,6 +131,7 @@ var _ = Describe("Datasource controller", Ordered, func() {
 				Metadata: persesv1.ProjectMetadata{
 					Metadata: persesv1.Metadata{
 						Name: PersesSecretName,
+						Tags: common.WithManagedTag(nil),
 					},
 				},
 				Spec: persesv1.SecretSpec{},
@@ -140,6 +141,7 @@ var _ = Describe("Datasource controller", Ordered, func() {
 				Metadata: persesv1.ProjectMetadata{
 					Metadata: persesv1.Metadata{
 						Name: DatasourceName,
+						Tags: common.WithManagedTag(nil),
 					},
 				},
 				Spec: specdatasource.Spec{
@@ -216,7 +218,7 @@ var _ = Describe("Datasource controller", Ordered, func() {
 
 			// The datasource was created in the Perses API
 			getDatasource.Unset()
-			mockDatasource.On("Get", DatasourceName).Return(&persesv1.Datasource{}, nil)
+			mockDatasource.On("Get", DatasourceName).Return(newDatasource, nil)
 
 			By("Checking if the Perses API was called to create a datasource")
 			Eventually(func() error {
@@ -261,6 +263,7 @@ var _ = Describe("Datasource controller", Ordered, func() {
 			}, time.Minute, time.Second).Should(Succeed())
 
 			mockDatasource.On("Delete", DatasourceName).Return(nil)
+			mockSecret.On("Get", PersesSecretName).Return(newSecret, nil)
 			mockSecret.On("Delete", PersesSecretName).Return(nil)
 
 			datasourceToDelete := &persesv1alpha2.PersesDatasource{}
@@ -381,7 +384,7 @@ var _ = Describe("Datasource controller", Ordered, func() {
 				return err
 			}, time.Minute, time.Second).Should(Succeed())
 
-			mockDatasource.On("Delete", DatasourceName).Return(nil)
+			mockSecret.On("Get", PersesSecretName).Return(newSecret, nil)
 			mockSecret.On("Delete", PersesSecretName).Return(nil)
 
 			datasourceToDelete := &persesv1alpha2.PersesDatasource{}
@@ -398,13 +401,8 @@ var _ = Describe("Datasource controller", Ordered, func() {
 
 			Expect(err).To(Not(HaveOccurred()))
 
-			By("Checking if the Perses API was called to delete a datasource")
-			Eventually(func() error {
-				if !mockDatasource.AssertExpectations(GinkgoT()) {
-					return fmt.Errorf("The Perses API was not called to create a datasource")
-				}
-				return nil
-			}, time.Minute, time.Second).Should(Succeed())
+			By("Checking the Perses API was not called to delete a datasource that was never created")
+			mockDatasource.AssertNotCalled(GinkgoT(), "Delete", DatasourceName)
 		})
 
 		It("should return an error when the Perses API delete call fails", func() {
@@ -414,8 +412,9 @@ var _ = Describe("Datasource controller", Ordered, func() {
 			if err != nil && errors.IsNotFound(err) {
 				datasource = &persesv1alpha2.PersesDatasource{
 					ObjectMeta: metav1.ObjectMeta{
-						Name:      DatasourceName,
-						Namespace: PersesNamespace,
+						Name:       DatasourceName,
+						Namespace:  PersesNamespace,
+						Finalizers: []string{common.PersesFinalizer},
 					},
 					Spec: persesv1alpha2.DatasourceSpec{
 						Config: persesv1alpha2.Datasource{
@@ -428,12 +427,22 @@ var _ = Describe("Datasource controller", Ordered, func() {
 				Expect(err).To(Not(HaveOccurred()))
 			}
 
+			By("Recording the Perses instance the datasource was synced to")
+			Eventually(func() error {
+				if err := k8sClient.Get(ctx, datasourceNamespaceName, datasource); err != nil {
+					return err
+				}
+				datasource.Status.SyncedInstances = []persesv1alpha2.PersesInstanceReference{{Namespace: PersesNamespace, Name: PersesName}}
+				return k8sClient.Status().Update(ctx, datasource)
+			}, time.Second*10, time.Millisecond*250).Should(Succeed())
+
 			mockPersesClient, validateServer := internal.NewMockClientWithValidation()
 			defer validateServer.Close()
 			mockDatasource := new(internal.MockDatasource)
 
 			mockPersesClient.On("Datasource", PersesNamespace).Return(mockDatasource)
-			mockDatasource.On("Delete", DatasourceName).Return(perseshttp.RequestInternalError)
+			mockDatasource.On("Get", DatasourceName).Return(newDatasource, nil)
+			deleteCall := mockDatasource.On("Delete", DatasourceName).Return(perseshttp.RequestInternalError)
 
 			datasourceReconciler := &datasourcecontroller.PersesDatasourceReconciler{
 				Client:        k8sClient,
@@ -455,7 +464,25 @@ var _ = Describe("Datasource controller", Ordered, func() {
 				NamespacedName: datasourceNamespaceName,
 			})
 			Expect(err).To(HaveOccurred())
+			Expect(common.ExtractReason(err, "")).To(Equal(common.ReasonDeletionBlocked))
 			mockDatasource.AssertCalled(GinkgoT(), "Delete", DatasourceName)
+
+			By("Checking the finalizer keeps the datasource until the Perses API confirms the deletion")
+			Expect(k8sClient.Get(ctx, datasourceNamespaceName, &persesv1alpha2.PersesDatasource{})).To(Succeed())
+
+			deleteCall.Unset()
+			mockSecret := new(internal.MockSecret)
+			mockPersesClient.On("Secret", PersesNamespace).Return(mockSecret)
+			mockDatasource.On("Delete", DatasourceName).Return(nil)
+			mockSecret.On("Get", DatasourceName+common.SecretNameSuffix).Return(&persesv1.Secret{}, perseshttp.RequestNotFoundError)
+
+			_, err = datasourceReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: datasourceNamespaceName,
+			})
+			Expect(err).To(Not(HaveOccurred()))
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, datasourceNamespaceName, &persesv1alpha2.PersesDatasource{}))
+			}, time.Minute, time.Second).Should(BeTrue())
 		})
 
 		It("should set degraded status with ValidationFailed reason when server-side validation fails", func() {
@@ -550,6 +577,18 @@ var _ = Describe("Datasource controller", Ordered, func() {
 			Expect(err).To(Not(HaveOccurred()))
 			err = k8sClient.Delete(ctx, datasourceToDelete)
 			Expect(err).To(Not(HaveOccurred()))
+
+			By("Releasing the finalizer once Perses reports the datasource as absent")
+			mockSecret := new(internal.MockSecret)
+			mockPersesClient.On("Secret", PersesNamespace).Return(mockSecret)
+			mockSecret.On("Get", DatasourceName+common.SecretNameSuffix).Return(&persesv1.Secret{}, perseshttp.RequestNotFoundError)
+			_, err = datasourceReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: datasourceNamespaceName,
+			})
+			Expect(err).To(Not(HaveOccurred()))
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, datasourceNamespaceName, &persesv1alpha2.PersesDatasource{}))
+			}, time.Minute, time.Second).Should(BeTrue())
 		})
 	})
 
@@ -629,6 +668,7 @@ var _ = Describe("Datasource controller", Ordered, func() {
 				Metadata: persesv1.ProjectMetadata{
 					Metadata: persesv1.Metadata{
 						Name: PersesSecretName,
+						Tags: common.WithManagedTag(nil),
 					},
 				},
 				Spec: persesv1.SecretSpec{},
@@ -638,6 +678,7 @@ var _ = Describe("Datasource controller", Ordered, func() {
 				Metadata: persesv1.ProjectMetadata{
 					Metadata: persesv1.Metadata{
 						Name: DatasourceName,
+						Tags: common.WithManagedTag(nil),
 					},
 				},
 				Spec: specdatasource.Spec{
@@ -714,7 +755,7 @@ var _ = Describe("Datasource controller", Ordered, func() {
 
 			// The datasource was created in the Perses API
 			getDatasource.Unset()
-			mockDatasource.On("Get", DatasourceName).Return(&persesv1.Datasource{}, nil)
+			mockDatasource.On("Get", DatasourceName).Return(newDatasource, nil)
 
 			By("Checking if the Perses API was called to create a datasource")
 			Eventually(func() error {
@@ -759,6 +800,7 @@ var _ = Describe("Datasource controller", Ordered, func() {
 			}, time.Minute, time.Second).Should(Succeed())
 
 			mockDatasource.On("Delete", DatasourceName).Return(nil)
+			mockSecret.On("Get", PersesSecretName).Return(newSecret, nil)
 			mockSecret.On("Delete", PersesSecretName).Return(nil)
 
 			datasourceToDelete := &persesv1alpha2.PersesDatasource{}
@@ -879,7 +921,7 @@ var _ = Describe("Datasource controller", Ordered, func() {
 				return err
 			}, time.Minute, time.Second).Should(Succeed())
 
-			mockDatasource.On("Delete", DatasourceName).Return(nil)
+			mockSecret.On("Get", PersesSecretName).Return(newSecret, nil)
 			mockSecret.On("Delete", PersesSecretName).Return(nil)
 
 			datasourceToDelete := &persesv1alpha2.PersesDatasource{}
@@ -896,13 +938,8 @@ var _ = Describe("Datasource controller", Ordered, func() {
 
 			Expect(err).To(Not(HaveOccurred()))
 
-			By("Checking if the Perses API was called to delete a datasource")
-			Eventually(func() error {
-				if !mockDatasource.AssertExpectations(GinkgoT()) {
-					return fmt.Errorf("The Perses API was not called to create a datasource")
-				}
-				return nil
-			}, time.Minute, time.Second).Should(Succeed())
+			By("Checking the Perses API was not called to delete a datasource that was never created")
+			mockDatasource.AssertNotCalled(GinkgoT(), "Delete", DatasourceName)
 		})
 	})
 
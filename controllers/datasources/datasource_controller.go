@@ -17,20 +17,22 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
+	"strings"
 	"time"
 
+	"github.com/perses/common/set"
 	v1 "github.com/perses/perses/pkg/client/api/v1"
 	"github.com/perses/perses/pkg/client/api/validate"
 	"github.com/perses/perses/pkg/client/perseshttp"
 	persesv1 "github.com/perses/perses/pkg/model/api/v1"
-	"github.com/perses/perses/pkg/model/api/v1/common"
 
-	"github.com/perses/perses/pkg/model/api/v1/secret"
 	logger "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -71,22 +73,174 @@ func (r *PersesDatasourceReconciler) reconcileDatasourcesInAllInstances(ctx cont
 		return r.setStatusToDegraded(ctx, req, res, persescommon.ReasonMissingPerses, err)
 	}
 
+	r.instanceSelectors.Set(req.NamespacedName, labelSelector, datasource.Status.SyncedInstances)
+	// The instances rejecting the datasource are checked first, so that it is removed from them
+	// as from the instances no longer selected.
+	allowing := make([]persesv1alpha2.Perses, 0, len(persesInstances.Items))
+	rejecting := make(map[persesv1alpha2.PersesInstanceReference]bool)
+	for _, persesInstance := range persesInstances.Items {
+		allowed, err := persescommon.InstanceAllowsResource(ctx, r.APIReader, persesInstance, datasource)
+		if err != nil {
+			dlog.WithError(err).Errorf("Failed to check if Perses instance %s/%s allows datasource %s", persesInstance.Namespace, persesInstance.Name, datasource.Name)
+			res, err := subreconciler.RequeueWithError(err)
+			return r.setStatusToDegraded(ctx, req, res, persescommon.ReasonInvalidConfiguration, err)
+		}
+		if !allowed {
+			rejecting[persescommon.InstanceReference(persesInstance)] = true
+			continue
+		}
+		allowing = append(allowing, persesInstance)
+	}
+
+	blockedRemovals, res, err := r.removeDatasourceFromDeselectedInstances(ctx, req, datasource, allowing)
+	if subreconciler.ShouldHaltOrRequeue(res, err) {
+		return res, err
+	}
+
 	if len(persesInstances.Items) == 0 {
 		dlog.Info("No Perses instances found, requeue in 1 minute")
 		return r.setStatusToDegraded(ctx, req, &ctrl.Result{RequeueAfter: time.Minute}, persescommon.ReasonMissingPerses, fmt.Errorf("no Perses instances found matching the label selector"))
 	}
 
+	available := make([]persesv1alpha2.Perses, 0, len(persesInstances.Items))
+	instances := make([]persesv1alpha2.PersesInstanceStatus, 0, len(persesInstances.Items))
+	var notAllowed []string
 	for _, persesInstance := range persesInstances.Items {
 		if !meta.IsStatusConditionTrue(persesInstance.Status.Conditions, persescommon.TypeAvailablePerses) {
 			dlog.Infof("Skipping Perses instance %s/%s (not yet available)", persesInstance.Namespace, persesInstance.Name)
+			instances = append(instances, persescommon.UnavailableInstanceStatus(datasource.Status.Instances, persesInstance))
 			continue
 		}
-		if res, reason, err := r.syncPersesDatasource(ctx, persesInstance, datasource); subreconciler.ShouldHaltOrRequeue(res, err) {
-			return r.setStatusToDegraded(ctx, req, res, reason, err)
+		if rejecting[persescommon.InstanceReference(persesInstance)] {
+			dlog.Infof("Skipping Perses instance %s/%s (datasource %s not allowed by its resource selectors)", persesInstance.Namespace, persesInstance.Name, datasource.Name)
+			notAllowed = append(notAllowed, fmt.Sprintf("%s/%s", persesInstance.Namespace, persesInstance.Name))
+			instances = append(instances, persescommon.NotAllowedInstanceStatus(persesInstance))
+			continue
 		}
+		available = append(available, persesInstance)
 	}
 
-	return subreconciler.ContinueReconciling()
+	// Nothing is written to the instances in a dry run, so they are not recorded for deletion.
+	dryRun := persescommon.IsDryRun(datasource, r.DryRun)
+	if !dryRun {
+		if res, err := r.recordSyncedInstances(ctx, req, available); subreconciler.ShouldHaltOrRequeue(res, err) {
+			return res, err
+		}
+	}
+
+	// A datasource whose spec and tags were already applied to an instance is not compared
+	// with it again before the next verification.
+	verificationPeriod := persescommon.VerificationPeriod(persescommon.ResyncPeriod(datasource.Spec.ResyncPeriodSeconds, r.ResyncPeriod), r.VerificationPeriod)
+	// The credentials of a datasource are read again on every sync, so that their rotation
+	// reaches Perses: a datasource holding them is never skipped.
+	if persescommon.HasSecretConfig(datasource.Spec.Client) {
+		verificationPeriod = 0
+	}
+	tags := persescommon.ParseTags(datasource.Annotations)
+
+	// Every instance is synced, so that a failing instance does not hold back the others.
+	outcomes := subreconciler.ForEach(available, r.InstanceSyncConcurrency, func(persesInstance persesv1alpha2.Perses) (*ctrl.Result, persescommon.ConditionStatusReason, error) {
+		if persescommon.AlreadyApplied(datasource.Status.Instances, persesInstance, datasource.Generation, persescommon.AppliedHash(persesInstance, datasource.Spec.Config.Spec, tags), verificationPeriod) {
+			dlog.Debugf("Datasource already applied to Perses instance %s/%s: %s", persesInstance.Namespace, persesInstance.Name, datasource.Name)
+			res, err := subreconciler.ContinueReconciling()
+			return res, persescommon.ReasonAlreadyApplied, err
+		}
+		return r.syncPersesDatasource(ctx, persesInstance, datasource)
+	})
+
+	var drifted, planned, failed, failures []string
+	var firstFailure subreconciler.Outcome
+	for i, persesInstance := range available {
+		instanceName := fmt.Sprintf("%s/%s", persesInstance.Namespace, persesInstance.Name)
+		outcome := outcomes[i]
+		if outcome.Halted() {
+			failed = append(failed, instanceName)
+			if len(failures) == 0 {
+				firstFailure = outcome
+			}
+			failures = append(failures, fmt.Sprintf("%s: %v", instanceName, outcome.Err))
+			instances = append(instances, persescommon.FailedInstanceStatus(datasource.Status.Instances, persesInstance, outcome.Reason))
+			persescommon.RecordInstanceEvent(r.Recorder, datasource, persesInstance, corev1.EventTypeWarning, string(outcome.Reason), "Failed to sync datasource: %v", outcome.Err)
+			continue
+		}
+		// The planned changes were not applied, so the last sync status of the instance is kept.
+		if outcome.Reason == persescommon.ReasonDryRun {
+			planned = append(planned, instanceName)
+			if status, found := persescommon.FindInstanceStatus(datasource.Status.Instances, persesInstance); found {
+				instances = append(instances, status)
+			}
+			continue
+		}
+		driftCorrected := outcome.Reason == persescommon.ReasonDriftCorrected
+		if driftCorrected {
+			drifted = append(drifted, instanceName)
+		}
+		status := persescommon.SyncedInstanceStatus(datasource.Status.Instances, persesInstance, datasource.Generation, driftCorrected, "")
+		if verificationPeriod > 0 && outcome.Reason != persescommon.ReasonAlreadyApplied {
+			status = persescommon.VerifiedInstanceStatus(status, persescommon.AppliedHash(persesInstance, datasource.Spec.Config.Spec, tags))
+		}
+		instances = append(instances, status)
+	}
+	persescommon.SortInstanceStatuses(instances)
+
+	res, err = r.updateDatasourceStatus(ctx, req, func(datasource *persesv1alpha2.PersesDatasource) {
+		datasource.Status.Instances = instances
+		if dryRun {
+			meta.SetStatusCondition(&datasource.Status.Conditions, persescommon.DryRunCondition("Datasource", datasource.Name, planned))
+		} else {
+			meta.RemoveStatusCondition(&datasource.Status.Conditions, persescommon.TypeDryRunPerses)
+			meta.SetStatusCondition(&datasource.Status.Conditions, persescommon.DriftCondition("Datasource", datasource.Name, drifted))
+		}
+		meta.SetStatusCondition(&datasource.Status.Conditions, persescommon.PartialSyncCondition("Datasource", datasource.Name, len(available)-len(failed), failed))
+		meta.SetStatusCondition(&datasource.Status.Conditions, persescommon.NotAllowedCondition("Datasource", datasource.Name, notAllowed))
+	})
+	if subreconciler.ShouldHaltOrRequeue(res, err) {
+		return res, err
+	}
+	if len(available) == 0 && len(notAllowed) > 0 {
+		dlog.Infof("Datasource %s is not allowed by any available Perses instance", datasource.Name)
+		return r.setStatusToDegraded(ctx, req, &ctrl.Result{}, persescommon.ReasonNotAllowedByInstance,
+			fmt.Errorf("not allowed by the resource selectors of the Perses instances: %s", strings.Join(notAllowed, ", ")))
+	}
+	if len(failed) == 0 && len(blockedRemovals) > 0 {
+		return r.setStatusToDegraded(ctx, req, &ctrl.Result{RequeueAfter: time.Minute}, persescommon.ReasonDeletionBlocked,
+			fmt.Errorf("datasource removal is waiting for Perses instances no longer selecting or allowing it: %s", strings.Join(blockedRemovals, "; ")))
+	}
+	if len(failed) == 0 {
+		return res, err
+	}
+
+	dlog.Errorf("Datasource %s failed to sync to %d of %d Perses instances", datasource.Name, len(failed), len(available))
+	// A single failure is reported as is, so that its message stays actionable.
+	failure := firstFailure.Err
+	if len(failed) > 1 {
+		failure = fmt.Errorf("failed to sync to %d of %d Perses instances: %s", len(failed), len(available), strings.Join(failures, "; "))
+	}
+	return r.setStatusToDegraded(ctx, req, firstFailure.Result, firstFailure.Reason, failure)
+}
+
+// removeDatasourceFromDeselectedInstances removes the datasource from the Perses instances it was
+// synced to that its instanceSelector no longer selects, e.g. after they were relabeled,
+// or whose resource selectors no longer allow it.
+// It returns a description of every instance that could not confirm the removal.
+func (r *PersesDatasourceReconciler) removeDatasourceFromDeselectedInstances(ctx context.Context, req ctrl.Request, datasource *persesv1alpha2.PersesDatasource, selected []persesv1alpha2.Perses) ([]string, *ctrl.Result, error) {
+	deselected := persescommon.DeselectedInstances(datasource.Status.SyncedInstances, selected)
+	if len(deselected) == 0 {
+		return nil, nil, nil
+	}
+
+	dlog.Infof("Datasource %s is no longer selected or allowed by %d Perses instances, removing it from them", datasource.Name, len(deselected))
+	removed, blocked := r.deleteDatasourceInInstances(ctx, datasource, deselected)
+	// Nothing is removed in a dry run, so the instances are kept for the next reconciliation.
+	if len(removed) > 0 && !persescommon.IsDryRun(datasource, r.DryRun) {
+		res, err := r.updateDatasourceStatus(ctx, req, func(datasource *persesv1alpha2.PersesDatasource) {
+			datasource.Status.SyncedInstances = persescommon.RemoveInstanceReferences(datasource.Status.SyncedInstances, removed)
+		})
+		if subreconciler.ShouldHaltOrRequeue(res, err) {
+			return blocked, res, err
+		}
+	}
+	return blocked, nil, nil
 }
 
 func (r *PersesDatasourceReconciler) syncPersesDatasource(ctx context.Context, perses persesv1alpha2.Perses, datasource *persesv1alpha2.PersesDatasource) (*ctrl.Result, persescommon.ConditionStatusReason, error) {
@@ -97,32 +251,33 @@ func (r *PersesDatasourceReconciler) syncPersesDatasource(ctx context.Context, p
 		return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonConnectionFailed)
 	}
 
-	_, err = persesClient.Project().Get(datasource.Namespace)
+	// A dry run does not create the project, the datasource is then planned for creation.
+	dryRun := persescommon.IsDryRun(datasource, r.DryRun)
+	if !dryRun {
+		if reason, err := persescommon.EnsureProject(ctx, r.APIReader, persesClient, perses, datasource.Namespace); err != nil {
+			return subreconciler.RequeueWithErrorAndReason(err, reason)
+		}
+	}
 
-	if err != nil {
-		if errors.Is(err, perseshttp.RequestNotFoundError) {
-			_, err := persesClient.Project().Create(&persesv1.Project{
-				Kind: "Project",
-				Metadata: persesv1.Metadata{
-					Name: datasource.Namespace,
-				},
-				Spec: persesv1.ProjectSpec{
-					Display: &common.Display{
-						Name: datasource.Namespace,
-					},
-				},
-			})
-
-			if err != nil {
-				dlog.WithError(err).Errorf("Failed to create perses project: %s", datasource.Namespace)
-				return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonBackendError)
-			}
+	existing, err := persesClient.Datasource(datasource.Namespace).Get(datasource.Name)
+	notFound := err != nil && errors.Is(err, perseshttp.RequestNotFoundError)
 
-			dlog.Infof("Project created: %s", datasource.Namespace)
-		} else {
-			dlog.WithError(err).Errorf("project error: %s", datasource.Namespace)
-			return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonBackendError)
-		}
+	if err != nil && !notFound {
+		return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonBackendError)
+	}
+
+	tags := persescommon.ParseTags(datasource.Annotations)
+	var existingTags set.Set[string]
+	if !notFound {
+		existingTags = existing.Metadata.Tags
+	}
+	managed, err := persescommon.ClaimOwnership(datasource.Spec.ConflictPolicy, "datasource", datasource.Name, !notFound, existingTags)
+	if err != nil {
+		dlog.WithError(err).Errorf("Datasource conflict: %s", datasource.Name)
+		return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonConflict)
+	}
+	if managed {
+		tags = persescommon.WithManagedTag(tags)
 	}
 
 	datasourceWithName := &persesv1.Datasource{
@@ -130,47 +285,75 @@ func (r *PersesDatasourceReconciler) syncPersesDatasource(ctx context.Context, p
 		Metadata: persesv1.ProjectMetadata{
 			Metadata: persesv1.Metadata{
 				Name: datasource.Name,
-				Tags: persescommon.ParseTags(datasource.Annotations),
+				Tags: tags,
 			},
 		},
 		Spec: datasource.Spec.Config.Spec,
 	}
 
-	existing, err := persesClient.Datasource(datasource.Namespace).Get(datasource.Name)
-	notFound := err != nil && errors.Is(err, perseshttp.RequestNotFoundError)
-
-	if err != nil && !notFound {
-		return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonBackendError)
-	}
-
-	if !notFound && persescommon.DatasourceInSync(existing, datasourceWithName) {
-		dlog.Debugf("Datasource already in sync: %s", datasource.Name)
-		res, err := subreconciler.ContinueReconciling()
-		return res, "", err
-	}
+	inSync := !notFound && persescommon.DatasourceInSync(existing, datasourceWithName)
 
-	if validateErr := validate.New(persesClient.RESTClient()).Datasource(datasourceWithName); validateErr != nil {
-		if persescommon.IsClientError(validateErr) {
-			dlog.WithError(validateErr).Errorf("Datasource validation failed: %s", datasource.Name)
+	if !inSync {
+		if validateErr := validate.New(persesClient.RESTClient()).Datasource(datasourceWithName); validateErr != nil {
+			if persescommon.IsClientError(validateErr) {
+				dlog.WithError(validateErr).Errorf("Datasource validation failed: %s", datasource.Name)
+				return subreconciler.RequeueWithErrorAndReason(
+					fmt.Errorf("datasource %q failed server-side validation: %w", datasource.Name, validateErr),
+					persescommon.ReasonValidationFailed,
+				)
+			}
+			dlog.WithError(validateErr).Errorf("Datasource validation request failed: %s", datasource.Name)
 			return subreconciler.RequeueWithErrorAndReason(
-				fmt.Errorf("datasource %q failed server-side validation: %w", datasource.Name, validateErr),
-				persescommon.ReasonValidationFailed,
+				fmt.Errorf("datasource %q validation request failed: %w", datasource.Name, validateErr),
+				persescommon.ReasonBackendError,
 			)
 		}
-		dlog.WithError(validateErr).Errorf("Datasource validation request failed: %s", datasource.Name)
-		return subreconciler.RequeueWithErrorAndReason(
-			fmt.Errorf("datasource %q validation request failed: %w", datasource.Name, validateErr),
-			persescommon.ReasonBackendError,
-		)
 	}
 
-	// Sync secret only after validation passes to avoid orphaned secrets
+	// A dry run plans the datasource only, the secret holding its credentials is not synced.
+	if dryRun {
+		if inSync {
+			dlog.Debugf("Datasource already in sync: %s", datasource.Name)
+			res, err := subreconciler.ContinueReconciling()
+			return res, "", err
+		}
+		plan := "created"
+		if !notFound {
+			plan = fmt.Sprintf("updated: %s", persescommon.FormatDiff(persescommon.DatasourceDiff(existing, datasourceWithName), persescommon.MaxEventDiffLength))
+		}
+		dlog.Infof("Dry run, datasource %s would be %s in Perses instance %s/%s", datasource.Name, plan, perses.Namespace, perses.Name)
+		persescommon.RecordInstanceEvent(r.Recorder, datasource, perses, corev1.EventTypeNormal, string(persescommon.ReasonDryRun), "Dry run: datasource would be %s", plan)
+		res, err := subreconciler.ContinueReconciling()
+		return res, persescommon.ReasonDryRun, err
+	}
+
+	// Sync secret only after validation passes to avoid orphaned secrets.
+	// It is synced even when the datasource is in sync, so that credentials rotated
+	// in the referenced Secrets or ConfigMaps reach Perses.
 	if persescommon.HasSecretConfig(datasource.Spec.Client) {
-		_, reason, err := r.syncPersesSecret(ctx, persesClient, datasource)
+		_, reason, err := r.syncPersesSecret(ctx, perses, persesClient, datasource)
 		if err != nil {
 			dlog.WithError(err).Errorf("Failed to create datasource secret: %s", datasource.Name)
 			return subreconciler.RequeueWithErrorAndReason(err, reason)
 		}
+	} else if datasource.Spec.SecretRef != nil {
+		// A shared secret is synced by its own PersesSecret, it only has to exist in Perses.
+		secretName := datasource.Spec.SecretRef.Name
+		if _, err := persesClient.Secret(datasource.Namespace).Get(secretName); err != nil {
+			if errors.Is(err, perseshttp.RequestNotFoundError) {
+				return subreconciler.RequeueWithErrorAndReason(
+					fmt.Errorf("secret %q referenced by datasource %q not found in Perses instance %s/%s: %w", secretName, datasource.Name, perses.Namespace, perses.Name, err),
+					persescommon.ReasonMissingResource,
+				)
+			}
+			return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonBackendError)
+		}
+	}
+
+	if inSync {
+		dlog.Debugf("Datasource already in sync: %s", datasource.Name)
+		res, err := subreconciler.ContinueReconciling()
+		return res, "", err
 	}
 
 	if notFound {
@@ -180,13 +363,31 @@ func (r *PersesDatasourceReconciler) syncPersesDatasource(ctx context.Context, p
 			return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonBackendError)
 		}
 		dlog.Infof("Datasource created: %s", datasource.Name)
+		persescommon.RecordInstanceEvent(r.Recorder, datasource, perses, corev1.EventTypeNormal, persescommon.EventReasonCreated, "Datasource created")
 	} else {
+		changes := persescommon.DatasourceDiff(existing, datasourceWithName)
 		_, err = persesClient.Datasource(datasource.Namespace).Update(datasourceWithName)
 		if err != nil {
 			dlog.WithError(err).Errorf("Failed to update datasource: %s", datasource.Name)
 			return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonBackendError)
 		}
 		dlog.Infof("Datasource updated: %s", datasource.Name)
+		dlog.WithField("diff", persescommon.FormatDiff(changes, 0)).Debugf("Datasource %s changed in Perses instance %s/%s", datasource.Name, perses.Namespace, perses.Name)
+		eventDiff := persescommon.FormatDiff(changes, persescommon.MaxEventDiffLength)
+
+		// The datasource already matched this generation of the custom resource,
+		// so the difference was introduced in Perses. Claiming an object written by an earlier
+		// version of the operator is not a drift.
+		if persescommon.SyncedAtGeneration(datasource.Status.Conditions, datasource.Generation) && !persescommon.OwnershipClaimOnly(changes) {
+			dlog.Infof("Datasource drift corrected in Perses instance %s/%s: %s", perses.Namespace, perses.Name, datasource.Name)
+			if r.Metrics != nil {
+				r.Metrics.DriftCorrections("datasource").Inc()
+			}
+			persescommon.RecordInstanceEvent(r.Recorder, datasource, perses, corev1.EventTypeNormal, string(persescommon.ReasonDriftCorrected), "Datasource modified in Perses, restored from its custom resource: %s", eventDiff)
+			res, err := subreconciler.ContinueReconciling()
+			return res, persescommon.ReasonDriftCorrected, err
+		}
+		persescommon.RecordInstanceEvent(r.Recorder, datasource, perses, corev1.EventTypeNormal, persescommon.EventReasonUpdated, "Datasource updated: %s", eventDiff)
 	}
 
 	res, err := subreconciler.ContinueReconciling()
@@ -195,213 +396,128 @@ func (r *PersesDatasourceReconciler) syncPersesDatasource(ctx context.Context, p
 
 // creates/updates a Perses Secret with configuration,
 // retrieving cert/key data from Secrets, ConfigMaps, or files specified in the PersesDatasource.
-func (r *PersesDatasourceReconciler) syncPersesSecret(ctx context.Context, persesClient v1.ClientInterface, datasource *persesv1alpha2.PersesDatasource) (*ctrl.Result, persescommon.ConditionStatusReason, error) {
+// An existing secret is only updated when its content differs from the content last pushed,
+// and one the operator does not own is handled according to the conflict policy of the datasource.
+func (r *PersesDatasourceReconciler) syncPersesSecret(ctx context.Context, perses persesv1alpha2.Perses, persesClient v1.ClientInterface, datasource *persesv1alpha2.PersesDatasource) (*ctrl.Result, persescommon.ConditionStatusReason, error) {
 	namespace := datasource.Namespace
 	datasourceName := datasource.Name
 	secretName := datasourceName + persescommon.SecretNameSuffix
-	basicAuth := datasource.Spec.Client.BasicAuth
-	oauth := datasource.Spec.Client.OAuth
-	tls := datasource.Spec.Client.TLS
+
+	secretSpec, reason, err := persescommon.SecretSpecFromClient(ctx, r.APIReader, namespace, datasourceName, datasource.Spec.Client)
+	if err != nil {
+		return subreconciler.RequeueWithErrorAndReason(err, reason)
+	}
+
+	existing, err := persesClient.Secret(namespace).Get(secretName)
+	notFound := err != nil && errors.Is(err, perseshttp.RequestNotFoundError)
+	if err != nil && !notFound {
+		return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonBackendError)
+	}
+
+	var existingTags set.Set[string]
+	if !notFound {
+		existingTags = existing.Metadata.Tags
+	}
+	managed, err := persescommon.ClaimOwnership(datasource.Spec.ConflictPolicy, "secret", secretName, !notFound, existingTags)
+	if err != nil {
+		dlog.WithError(err).Errorf("Secret conflict: %s", secretName)
+		return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonConflict)
+	}
+
+	var tags set.Set[string]
+	if managed {
+		tags = persescommon.WithManagedTag(nil)
+	}
 
 	secretWithName := &persesv1.Secret{
 		Kind: persesv1.KindSecret,
 		Metadata: persesv1.ProjectMetadata{
 			Metadata: persesv1.Metadata{
 				Name: secretName,
+				Tags: tags,
 			},
 		},
-		Spec: persesv1.SecretSpec{},
+		Spec: secretSpec,
 	}
 
-	if basicAuth != nil {
-		basicAuthConfig := &secret.BasicAuth{}
-		basicAuthConfig.Username = basicAuth.Username
-
-		switch basicAuth.Type {
-		case persesv1alpha2.SecretSourceTypeSecret, persesv1alpha2.SecretSourceTypeConfigMap:
-			passwordData, err := persescommon.GetBasicAuthData(ctx, r.APIReader, namespace, datasourceName, basicAuth)
-
-			if err != nil {
-				dlog.WithFields(logger.Fields{
-					"datasource": datasourceName,
-					"namespace":  namespace,
-					"secretName": basicAuth.Name,
-				}).WithError(err).Error("Failed to get user basic auth password data for datasource")
-				return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonInvalidConfiguration)
-			}
-
-			basicAuthConfig.Password = passwordData
-		case persesv1alpha2.SecretSourceTypeFile:
-			basicAuthConfig.PasswordFile = basicAuth.PasswordPath
-		}
-
-		secretWithName.Spec.BasicAuth = basicAuthConfig
-	}
+	secretKey := persescommon.SecretContentKey(perses, namespace, secretName)
 
-	if oauth != nil {
-		oAuthConfig := &secret.OAuth{
-			TokenURL:       oauth.TokenURL,
-			Scopes:         oauth.Scopes,
-			EndpointParams: oauth.EndpointParams,
-		}
+	if notFound {
+		_, err = persesClient.Secret(namespace).Create(secretWithName)
 
-		if oauth.AuthStyle != nil && *oauth.AuthStyle != 0 {
-			oAuthConfig.AuthStyle = int(*oauth.AuthStyle)
+		if err != nil {
+			dlog.WithError(err).Errorf("Failed to create secret: %s", secretName)
+			return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonBackendError)
 		}
 
-		oAuthConfig.TokenURL = oauth.TokenURL
-		switch oauth.Type {
-		case persesv1alpha2.SecretSourceTypeSecret, persesv1alpha2.SecretSourceTypeConfigMap:
-			clientIDData, clientSecretData, err := persescommon.GetOAuthData(ctx, r.APIReader, namespace, datasourceName, oauth)
-
-			if err != nil {
-				dlog.WithFields(logger.Fields{
-					"datasource": datasourceName,
-					"namespace":  namespace,
-					"secretName": oauth.Name,
-				}).WithError(err).Error("Failed to get user oauth data for datasource")
-				return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonInvalidConfiguration)
-			}
-
-			oAuthConfig.ClientID = clientIDData
-			oAuthConfig.ClientSecret = clientSecretData
-		case persesv1alpha2.SecretSourceTypeFile:
-			// the clientID is a Hidden field in perses API,
-			// but doesn't expose it as a file field for it, so we need to read it and use the value
-			if oauth.ClientIDPath == nil {
-				return subreconciler.RequeueWithErrorAndReason(
-					fmt.Errorf("clientIDPath is required when OAuth type is File for datasource %s", datasourceName),
-					persescommon.ReasonInvalidConfiguration,
-				)
-			}
-			clientID, err := os.ReadFile(*oauth.ClientIDPath)
-			if err != nil {
-				err = fmt.Errorf("failed to read the OAuth client ID file %s: %w", *oauth.ClientIDPath, err)
-				return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonInvalidConfiguration)
-			}
-			oAuthConfig.ClientID = string(clientID)
-			if oauth.ClientSecretPath != nil {
-				oAuthConfig.ClientSecretFile = *oauth.ClientSecretPath
-			}
-		}
+		r.secrets.Record(secretKey, secretSpec)
+		dlog.Infof("Secret created: %s", secretName)
 
-		secretWithName.Spec.OAuth = oAuthConfig
+		res, err := subreconciler.ContinueReconciling()
+		return res, "", err
 	}
 
-	if tls != nil {
-		insecureSkipVerify := false
-		if tls.InsecureSkipVerify != nil {
-			insecureSkipVerify = *tls.InsecureSkipVerify
-		}
-		tlsConfig := &secret.TLSConfig{
-			InsecureSkipVerify: insecureSkipVerify,
-		}
-
-		if tls.CaCert != nil {
-			switch tls.CaCert.Type {
-			case persesv1alpha2.SecretSourceTypeSecret, persesv1alpha2.SecretSourceTypeConfigMap:
-				caData, _, err := persescommon.GetTLSCertData(ctx, r.APIReader, namespace, datasourceName, tls.CaCert)
-
-				if err != nil {
-					dlog.WithFields(logger.Fields{
-						"datasource": datasourceName,
-						"namespace":  namespace,
-						"secretName": tls.CaCert.Name,
-					}).WithError(err).Error("Failed to get CA data for datasource")
-					return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonInvalidConfiguration)
-				}
-
-				tlsConfig.CA = caData
-			case persesv1alpha2.SecretSourceTypeFile:
-				tlsConfig.CAFile = tls.CaCert.CertPath
-			}
-		}
-
-		if tls.UserCert != nil {
-			switch tls.UserCert.Type {
-			case persesv1alpha2.SecretSourceTypeSecret, persesv1alpha2.SecretSourceTypeConfigMap:
-				certData, keyData, err := persescommon.GetTLSCertData(ctx, r.APIReader, namespace, datasourceName, tls.UserCert)
-
-				if err != nil {
-					dlog.WithFields(logger.Fields{
-						"datasource": datasourceName,
-						"namespace":  namespace,
-						"secretName": tls.UserCert.Name,
-					}).WithError(err).Error("Failed to get user certificate data for datasource")
-					return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonInvalidConfiguration)
-				}
-
-				tlsConfig.Cert = certData
-				tlsConfig.Key = keyData
-			case persesv1alpha2.SecretSourceTypeFile:
-				tlsConfig.CertFile = tls.UserCert.CertPath
-
-				if tls.UserCert.PrivateKeyPath != nil && len(*tls.UserCert.PrivateKeyPath) > 0 {
-					tlsConfig.KeyFile = *tls.UserCert.PrivateKeyPath
-				}
-			}
-		}
-
-		secretWithName.Spec.TLSConfig = tlsConfig
+	// A secret adopted by the operator is written again to carry the ManagedTag.
+	if r.secrets.InSync(secretKey, secretSpec) && persescommon.IsManaged(existingTags) == managed {
+		dlog.Debugf("Secret already in sync: %s", secretName)
+		res, err := subreconciler.ContinueReconciling()
+		return res, "", err
 	}
 
-	_, err := persesClient.Secret(namespace).Get(secretName)
+	_, err = persesClient.Secret(namespace).Update(secretWithName)
 
 	if err != nil {
-		if errors.Is(err, perseshttp.RequestNotFoundError) {
-			_, err = persesClient.Secret(namespace).Create(secretWithName)
-
-			if err != nil {
-				dlog.WithError(err).Errorf("Failed to create secret: %s", secretName)
-				return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonBackendError)
-			}
-
-			dlog.Infof("Secret created: %s", secretName)
-
-			res, err := subreconciler.ContinueReconciling()
-			return res, "", err
-		}
-
+		dlog.WithError(err).Errorf("Failed to update secret: %s", secretName)
 		return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonBackendError)
-	} else {
-		_, err = persesClient.Secret(namespace).Update(secretWithName)
-
-		if err != nil {
-			dlog.WithError(err).Errorf("Failed to update secret: %s", secretName)
-			return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonBackendError)
-		}
-
-		dlog.Infof("Secret updated: %s", secretName)
 	}
 
+	r.secrets.Record(secretKey, secretSpec)
+	dlog.Infof("Secret updated: %s", secretName)
+
 	res, err := subreconciler.ContinueReconciling()
 	return res, "", err
 }
 
-func (r *PersesDatasourceReconciler) deleteDatasourceInAllInstances(ctx context.Context, datasourceNamespace string, datasourceName string) (*ctrl.Result, error) {
-	persesInstances := &persesv1alpha2.PersesList{}
-	var opts []client.ListOption
-	err := r.List(ctx, persesInstances, opts...)
-	if err != nil {
-		dlog.WithError(err).Error("Failed to get perses instances")
-		return subreconciler.RequeueWithError(err)
-	}
+// deleteDatasourceInInstances removes the datasource from the given Perses instances it was
+// synced to. It returns the instances that no longer hold the datasource, and a
+// description of every instance that could not confirm the removal.
+func (r *PersesDatasourceReconciler) deleteDatasourceInInstances(ctx context.Context, datasource *persesv1alpha2.PersesDatasource, refs []persesv1alpha2.PersesInstanceReference) ([]persesv1alpha2.PersesInstanceReference, []string) {
+	var removed []persesv1alpha2.PersesInstanceReference
+	var blocked []string
+
+	for _, ref := range refs {
+		persesInstance := &persesv1alpha2.Perses{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, persesInstance); err != nil {
+			if apierrors.IsNotFound(err) {
+				dlog.Infof("Perses instance %s/%s no longer exists", ref.Namespace, ref.Name)
+				removed = append(removed, ref)
+				continue
+			}
+			dlog.WithError(err).Errorf("Failed to get perses instance %s/%s", ref.Namespace, ref.Name)
+			blocked = append(blocked, fmt.Sprintf("%s/%s (%v)", ref.Namespace, ref.Name, err))
+			continue
+		}
 
-	if len(persesInstances.Items) == 0 {
-		dlog.Info("No Perses instances found")
-		return subreconciler.DoNotRequeue()
-	}
+		if !meta.IsStatusConditionTrue(persesInstance.Status.Conditions, persescommon.TypeAvailablePerses) {
+			dlog.Infof("Perses instance %s/%s is not available, datasource deletion is blocked", ref.Namespace, ref.Name)
+			blocked = append(blocked, fmt.Sprintf("%s/%s (not available)", ref.Namespace, ref.Name))
+			persescommon.RecordInstanceEvent(r.Recorder, datasource, *persesInstance, corev1.EventTypeWarning, string(persescommon.ReasonDeletionBlocked), "Datasource deletion is waiting for the instance to be available")
+			continue
+		}
 
-	for _, persesInstance := range persesInstances.Items {
-		if r, err := r.deleteDatasource(ctx, persesInstance, datasourceNamespace, datasourceName); subreconciler.ShouldHaltOrRequeue(r, err) {
-			return r, err
+		if _, err := r.deleteDatasource(ctx, *persesInstance, datasource); err != nil {
+			blocked = append(blocked, fmt.Sprintf("%s/%s (%v)", ref.Namespace, ref.Name, err))
+			persescommon.RecordInstanceEvent(r.Recorder, datasource, *persesInstance, corev1.EventTypeWarning, string(persescommon.ReasonDeletionBlocked), "Failed to delete datasource: %v", err)
+			continue
 		}
+		removed = append(removed, ref)
 	}
 
-	return subreconciler.DoNotRequeue()
+	return removed, blocked
 }
 
-func (r *PersesDatasourceReconciler) deleteDatasource(ctx context.Context, perses persesv1alpha2.Perses, datasourceNamespace string, datasourceName string) (*ctrl.Result, error) {
+func (r *PersesDatasourceReconciler) deleteDatasource(ctx context.Context, perses persesv1alpha2.Perses, datasource *persesv1alpha2.PersesDatasource) (*ctrl.Result, error) {
+	datasourceNamespace, datasourceName := datasource.Namespace, datasource.Name
 	persesClient, err := r.ClientFactory.CreateClient(ctx, r.APIReader, perses)
 
 	if err != nil {
@@ -412,6 +528,12 @@ func (r *PersesDatasourceReconciler) deleteDatasource(ctx context.Context, perse
 	_, err = persesClient.Project().Get(datasourceNamespace)
 
 	if err != nil {
+		// The datasource and its secret went away with their project.
+		if errors.Is(err, perseshttp.RequestNotFoundError) {
+			dlog.Infof("Project not found: %s", datasourceNamespace)
+			r.secrets.Forget(persescommon.SecretContentKey(perses, datasourceNamespace, datasourceName+persescommon.SecretNameSuffix))
+			return subreconciler.ContinueReconciling()
+		}
 		dlog.WithError(err).Errorf("project error: %s", datasourceNamespace)
 
 		return subreconciler.RequeueWithError(err)
@@ -420,33 +542,72 @@ func (r *PersesDatasourceReconciler) deleteDatasource(ctx context.Context, perse
 	// Ignore NotFound — the resource may have already been deleted from Perses directly.
 	// Any other error means the delete failed and should be retried.
 	// Secret delete is attempted regardless of whether the datasource was found or not.
-	err = persesClient.Datasource(datasourceNamespace).Delete(datasourceName)
-
-	if err != nil {
-		if errors.Is(err, perseshttp.RequestNotFoundError) {
-			dlog.Infof("Datasource not found: %s", datasourceName)
-		} else {
+	existing, err := persesClient.Datasource(datasourceNamespace).Get(datasourceName)
+	dryRun := persescommon.IsDryRun(datasource, r.DryRun)
+
+	switch {
+	case err != nil && errors.Is(err, perseshttp.RequestNotFoundError):
+		dlog.Infof("Datasource not found: %s", datasourceName)
+	case err != nil:
+		dlog.WithError(err).Errorf("Failed to get datasource: %s", datasourceName)
+		return subreconciler.RequeueWithError(err)
+	case !persescommon.IsManaged(existing.Metadata.Tags):
+		// A datasource the operator does not own was either left untouched or overwritten, it is not removed.
+		dlog.Infof("Datasource not managed by the operator, keeping it: %s", datasourceName)
+	case dryRun:
+		dlog.Infof("Dry run, datasource %s would be deleted from Perses instance %s/%s", datasourceName, perses.Namespace, perses.Name)
+		persescommon.RecordInstanceEvent(r.Recorder, datasource, perses, corev1.EventTypeNormal, string(persescommon.ReasonDryRun), "Dry run: datasource would be deleted")
+	default:
+		err = persesClient.Datasource(datasourceNamespace).Delete(datasourceName)
+		if err != nil && !errors.Is(err, perseshttp.RequestNotFoundError) {
 			dlog.WithError(err).Errorf("Failed to delete datasource: %s", datasourceName)
 			return subreconciler.RequeueWithError(err)
 		}
-	} else {
 		dlog.Infof("Datasource deleted: %s", datasourceName)
+		persescommon.RecordInstanceEvent(r.Recorder, datasource, perses, corev1.EventTypeNormal, persescommon.EventReasonDeleted, "Datasource deleted")
 	}
 
 	secretName := datasourceName + persescommon.SecretNameSuffix
 
-	err = persesClient.Secret(datasourceNamespace).Delete(secretName)
+	// Never delete a shared secret managed by a PersesSecret that happens to use the generated name.
+	err = r.APIReader.Get(ctx, types.NamespacedName{Namespace: datasourceNamespace, Name: secretName}, &persesv1alpha2.PersesSecret{})
+	if err == nil {
+		dlog.Infof("Secret %s is managed by a PersesSecret, skipping deletion", secretName)
+		return subreconciler.ContinueReconciling()
+	}
+	if !apierrors.IsNotFound(err) {
+		dlog.WithError(err).Errorf("Failed to get PersesSecret: %s", secretName)
+		return subreconciler.RequeueWithError(err)
+	}
 
-	if err != nil {
-		if errors.Is(err, perseshttp.RequestNotFoundError) {
-			dlog.Infof("Secret not found: %s", secretName)
-		} else {
+	existingSecret, err := persesClient.Secret(datasourceNamespace).Get(secretName)
+
+	switch {
+	case err != nil && errors.Is(err, perseshttp.RequestNotFoundError):
+		dlog.Infof("Secret not found: %s", secretName)
+	case err != nil:
+		dlog.WithError(err).Errorf("Failed to get secret: %s", secretName)
+		return subreconciler.RequeueWithError(err)
+	case !persescommon.IsManaged(existingSecret.Metadata.Tags):
+		dlog.Infof("Secret not managed by the operator, keeping it: %s", secretName)
+	case dryRun:
+		dlog.Infof("Dry run, secret %s would be deleted from Perses instance %s/%s", secretName, perses.Namespace, perses.Name)
+		persescommon.RecordInstanceEvent(r.Recorder, datasource, perses, corev1.EventTypeNormal, string(persescommon.ReasonDryRun), "Dry run: secret %s would be deleted", secretName)
+		return subreconciler.ContinueReconciling()
+	default:
+		err = persesClient.Secret(datasourceNamespace).Delete(secretName)
+		if err != nil && !errors.Is(err, perseshttp.RequestNotFoundError) {
 			dlog.WithError(err).Errorf("Failed to delete secret: %s", secretName)
 			return subreconciler.RequeueWithError(err)
 		}
-	} else {
 		dlog.Infof("Secret deleted: %s", secretName)
 	}
 
+	r.secrets.Forget(persescommon.SecretContentKey(perses, datasourceNamespace, secretName))
+
+	if err := persescommon.CleanupProject(ctx, r.APIReader, persesClient, perses, datasourceNamespace); err != nil {
+		dlog.WithError(err).Errorf("Failed to clean up project: %s", datasourceNamespace)
+	}
+
 	return subreconciler.ContinueReconciling()
 }
@@ -16,22 +16,30 @@ package datasources
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	logger "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	internalcache "github.com/perses/perses-operator/internal/cache"
 	operatormetrics "github.com/perses/perses-operator/internal/metrics"
 	"github.com/perses/perses-operator/internal/perses/common"
 	"github.com/perses/perses-operator/internal/subreconciler"
@@ -59,6 +67,25 @@ type PersesDatasourceReconciler struct {
 	ClientFactory         common.PersesClientFactory
 	Metrics               *operatormetrics.Metrics
 	ReconciliationTracker *operatormetrics.ReconciliationTracker
+	// InstanceSyncConcurrency is the maximum number of Perses instances the datasource
+	// is synced to at the same time. Lower than 1 syncs one instance at a time.
+	InstanceSyncConcurrency int
+	// ResyncPeriod is the period after which a datasource is synced again to correct drift,
+	// unless overridden by its resyncPeriodSeconds. 0 disables it.
+	ResyncPeriod time.Duration
+	// VerificationPeriod is the period during which a datasource whose spec and tags were
+	// already applied to a Perses instance is not compared with it again. 0 disables it.
+	VerificationPeriod time.Duration
+	// DryRun only plans the changes to the datasources in Perses, without applying them,
+	// as for the datasources with the perses.dev/dry-run annotation.
+	DryRun bool
+	// instanceSelectors lets a change of a Perses instance only enqueue the datasources it concerns.
+	instanceSelectors *common.InstanceSelectorIndex
+	// ConfigMapCache holds the metadata of the ConfigMaps labeled for watching.
+	// When nil, changes to referenced ConfigMaps do not trigger reconciliation.
+	ConfigMapCache cache.Cache
+
+	secrets common.SecretContentTracker
 }
 
 var log = logger.WithField("module", "perses_datasource_controller")
@@ -66,7 +93,10 @@ var log = logger.WithField("module", "perses_datasource_controller")
 // +kubebuilder:rbac:groups=perses.dev,resources=persesdatasources,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=perses.dev,resources=persesdatasources/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=perses.dev,resources=persesdatasources/finalizers,verbs=update
-// +kubebuilder:rbac:groups="",resources=configmaps;secrets,verbs=watch;get
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get
+// +kubebuilder:rbac:groups="",resources=configmaps;secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=perses.dev,resources=persessecrets,verbs=get;list;watch
 func (r *PersesDatasourceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	start := time.Now()
 	objKey := req.String()
@@ -77,18 +107,20 @@ func (r *PersesDatasourceReconciler) Reconcile(ctx context.Context, req ctrl.Req
 
 	log.Infof("Reconciling PersesDatasource: %s/%s", req.Namespace, req.Name)
 
-	// Find once and store in context for all sub-reconcilers, handle deletion if not found
+	// Find once and store in context for all sub-reconcilers
 	datasource := &persesv1alpha2.PersesDatasource{}
 	if err := r.APIReader.Get(ctx, req.NamespacedName, datasource); err != nil {
 		if apierrors.IsNotFound(err) {
-			log.Infof("perses datasource resource not found. Deleting '%s' in '%s'", req.Name, req.Namespace)
+			log.Infof("perses datasource resource not found. Ignoring '%s' in '%s'", req.Name, req.Namespace)
 			if r.ReconciliationTracker != nil {
 				r.ReconciliationTracker.ForgetObject(objKey)
 			}
 			if r.Metrics != nil {
 				r.Metrics.ForgetObject(objKey)
 			}
-			return subreconciler.Evaluate(r.deleteDatasourceInAllInstances(ctx, req.Namespace, req.Name))
+			r.instanceSelectors.Forget(req.NamespacedName)
+			// Deletion is handled through the finalizer, so a missing object has nothing left to clean up.
+			return subreconciler.Evaluate(subreconciler.DoNotRequeue())
 		}
 		log.WithError(err).Error("Failed to get perses datasource")
 		if r.Metrics != nil {
@@ -100,6 +132,9 @@ func (r *PersesDatasourceReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	ctx = withDatasource(ctx, datasource)
 
 	subreconcilersForPerses := []subreconciler.FnWithRequest{
+		r.handleSuspend,
+		r.handleDelete,
+		r.addFinalizer,
 		r.setStatusToUnknown,
 		r.reconcileDatasourcesInAllInstances,
 		r.setStatusToComplete,
@@ -123,6 +158,11 @@ func (r *PersesDatasourceReconciler) Reconcile(ctx context.Context, req ctrl.Req
 
 	// Track metrics
 	if r.Metrics != nil {
+		suspended := 0
+		if common.IsReconcilePaused(datasource) {
+			suspended = 1
+		}
+		r.Metrics.SetSuspendedResources(objKey, "datasource", req.Namespace, suspended)
 		if reconcileErr != nil {
 			reason := string(common.ExtractReason(reconcileErr, "reconciliation_failed"))
 			r.Metrics.ReconcileErrors("persesdatasource", reason).Inc()
@@ -137,9 +177,129 @@ func (r *PersesDatasourceReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	}
 
 	log.WithField("duration", time.Since(start)).Debug("datasource reconciliation completed")
+	if resyncPeriod := common.ResyncPeriod(datasource.Spec.ResyncPeriodSeconds, r.ResyncPeriod); resyncPeriod > 0 && datasource.GetDeletionTimestamp() == nil && !common.IsReconcilePaused(datasource) {
+		return subreconciler.Evaluate(subreconciler.RequeueWithDelay(resyncPeriod))
+	}
 	return subreconciler.Evaluate(subreconciler.DoNotRequeue())
 }
 
+// handleDelete removes the datasource from every Perses instance it was synced to,
+// then releases the finalizer. While an instance cannot confirm the removal, the
+// finalizer is kept and the blocking instances are reported in the status conditions.
+func (r *PersesDatasourceReconciler) handleDelete(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	datasource, ok := datasourceFromContext(ctx)
+	if !ok {
+		log.Error("datasource not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("datasource not found in context"))
+	}
+
+	if datasource.GetDeletionTimestamp() == nil {
+		return subreconciler.ContinueReconciling()
+	}
+
+	if !controllerutil.ContainsFinalizer(datasource, common.PersesFinalizer) {
+		return subreconciler.DoNotRequeue()
+	}
+
+	removed, blocked := r.deleteDatasourceInInstances(ctx, datasource, datasource.Status.SyncedInstances)
+	if len(removed) > 0 {
+		if res, err := r.updateDatasourceStatus(ctx, req, func(datasource *persesv1alpha2.PersesDatasource) {
+			datasource.Status.SyncedInstances = common.RemoveInstanceReferences(datasource.Status.SyncedInstances, removed)
+		}); subreconciler.ShouldHaltOrRequeue(res, err) {
+			return res, err
+		}
+	}
+
+	if len(blocked) > 0 {
+		return r.setStatusToDegraded(ctx, req, &ctrl.Result{RequeueAfter: time.Minute}, common.ReasonDeletionBlocked,
+			fmt.Errorf("datasource deletion is waiting for Perses instances: %s", strings.Join(blocked, "; ")))
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesDatasource{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		if !controllerutil.RemoveFinalizer(fresh, common.PersesFinalizer) {
+			return nil
+		}
+		return r.Update(ctx, fresh)
+	})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to remove finalizer from perses datasource")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	r.instanceSelectors.Forget(req.NamespacedName)
+	log.Infof("PersesDatasource %s/%s deleted", datasource.Namespace, datasource.Name)
+	return subreconciler.DoNotRequeue()
+}
+
+// addFinalizer makes sure the datasource is not removed from the cluster before
+// it has been removed from the Perses instances it was synced to.
+func (r *PersesDatasourceReconciler) addFinalizer(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	datasource, ok := datasourceFromContext(ctx)
+	if !ok {
+		log.Error("datasource not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("datasource not found in context"))
+	}
+
+	if controllerutil.ContainsFinalizer(datasource, common.PersesFinalizer) {
+		return subreconciler.ContinueReconciling()
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesDatasource{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		if !controllerutil.AddFinalizer(fresh, common.PersesFinalizer) {
+			return nil
+		}
+		return r.Update(ctx, fresh)
+	})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to add finalizer to perses datasource")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+// recordSyncedInstances adds the Perses instances to the instances the datasource
+// is synced to, so that the datasource is removed from them on deletion. It is called
+// before the datasource is written to the instances, so that a failure in between
+// cannot leave anything behind in Perses.
+func (r *PersesDatasourceReconciler) recordSyncedInstances(ctx context.Context, req ctrl.Request, persesInstances []persesv1alpha2.Perses) (*ctrl.Result, error) {
+	datasource, ok := datasourceFromContext(ctx)
+	if !ok {
+		log.Error("datasource not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("datasource not found in context"))
+	}
+
+	var refs []persesv1alpha2.PersesInstanceReference
+	for _, perses := range persesInstances {
+		if ref := common.InstanceReference(perses); !common.HasInstanceReference(datasource.Status.SyncedInstances, ref) {
+			refs = append(refs, ref)
+		}
+	}
+	if len(refs) == 0 {
+		return subreconciler.ContinueReconciling()
+	}
+
+	return r.updateDatasourceStatus(ctx, req, func(datasource *persesv1alpha2.PersesDatasource) {
+		for _, ref := range refs {
+			datasource.Status.SyncedInstances = common.AddInstanceReference(datasource.Status.SyncedInstances, ref)
+		}
+	})
+}
+
 func (r *PersesDatasourceReconciler) updateDatasourceStatus(
 	ctx context.Context,
 	req ctrl.Request,
@@ -157,8 +317,11 @@ func (r *PersesDatasourceReconciler) updateDatasourceStatus(
 			return err
 		}
 		before := common.SnapshotConditions(fresh.Status.Conditions)
+		beforeInstances := slices.Clone(fresh.Status.SyncedInstances)
+		beforeInstanceStatuses := slices.Clone(fresh.Status.Instances)
 		updateFn(fresh)
-		if !common.ConditionsChanged(before, fresh.Status.Conditions) {
+		if !common.ConditionsChanged(before, fresh.Status.Conditions) && slices.Equal(beforeInstances, fresh.Status.SyncedInstances) &&
+			!common.InstanceStatusesChanged(beforeInstanceStatuses, fresh.Status.Instances) {
 			return nil
 		}
 		return r.Status().Update(ctx, fresh)
@@ -176,6 +339,37 @@ func (r *PersesDatasourceReconciler) updateDatasourceStatus(
 	return subreconciler.ContinueReconciling()
 }
 
+// handleSuspend stops the reconciliation of a datasource paused with the perses.dev/reconcile
+// annotation, leaving it untouched in Perses, and clears the Suspended condition once it is resumed.
+// It runs before handleDelete, but a datasource being deleted is never paused, so its finalizer still
+// removes it from Perses. The status is only written when the Suspended condition changes.
+func (r *PersesDatasourceReconciler) handleSuspend(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	datasource, ok := datasourceFromContext(ctx)
+	if !ok {
+		log.Error("datasource not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("datasource not found in context"))
+	}
+
+	paused := common.IsReconcilePaused(datasource)
+	if common.SuspendedStatusChanged(datasource.Status.Conditions, paused) {
+		if res, err := r.updateDatasourceStatus(ctx, req, func(datasource *persesv1alpha2.PersesDatasource) {
+			if paused {
+				meta.SetStatusCondition(&datasource.Status.Conditions, common.SuspendedCondition("Datasource", datasource.Name))
+			} else {
+				meta.RemoveStatusCondition(&datasource.Status.Conditions, common.TypeSuspendedPerses)
+			}
+		}); subreconciler.ShouldHaltOrRequeue(res, err) {
+			return res, err
+		}
+	}
+
+	if !paused {
+		return subreconciler.ContinueReconciling()
+	}
+	log.Infof("PersesDatasource %s is paused, skipping reconciliation", req.String())
+	return subreconciler.DoNotRequeue()
+}
+
 func (r *PersesDatasourceReconciler) setStatusToUnknown(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
 	return r.updateDatasourceStatus(ctx, req, func(datasource *persesv1alpha2.PersesDatasource) {
 		if len(datasource.Status.Conditions) == 0 {
@@ -187,12 +381,24 @@ func (r *PersesDatasourceReconciler) setStatusToUnknown(ctx context.Context, req
 }
 
 func (r *PersesDatasourceReconciler) setStatusToComplete(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	// The observed generation is the one that was synced, the custom resource may have changed since.
+	synced, ok := datasourceFromContext(ctx)
+	if !ok {
+		log.Error("datasource not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("datasource not found in context"))
+	}
+
+	// Nothing was applied in a dry run, so the availability of the datasource is left as is.
+	if common.IsDryRun(synced, r.DryRun) {
+		return subreconciler.ContinueReconciling()
+	}
+
 	return r.updateDatasourceStatus(ctx, req, func(datasource *persesv1alpha2.PersesDatasource) {
 		meta.SetStatusCondition(&datasource.Status.Conditions, metav1.Condition{
-			Type: common.TypeDegradedPerses, Status: metav1.ConditionFalse,
+			Type: common.TypeDegradedPerses, Status: metav1.ConditionFalse, ObservedGeneration: synced.Generation,
 			Reason: "Reconciled", Message: fmt.Sprintf("Datasource (%s) reconciled successfully", datasource.Name)})
 		meta.SetStatusCondition(&datasource.Status.Conditions, metav1.Condition{
-			Type: common.TypeAvailablePerses, Status: metav1.ConditionTrue,
+			Type: common.TypeAvailablePerses, Status: metav1.ConditionTrue, ObservedGeneration: synced.Generation,
 			Reason: "Reconciled", Message: fmt.Sprintf("Datasource (%s) created successfully", datasource.Name)})
 	})
 }
@@ -228,28 +434,123 @@ func (r *PersesDatasourceReconciler) setStatusToDegraded(
 	return degradedResult, nil
 }
 
-// findDatasourcesForPerses returns reconcile requests for all PersesDatasources
-// across all namespaces when a Perses instance becomes available.
-// Each datasource's instanceSelector labels determine which Perses instances it syncs to.
-// If no instanceSelector is set, the datasource syncs to all Perses instances.
-func (r *PersesDatasourceReconciler) findDatasourcesForPerses(ctx context.Context, _ client.Object) []reconcile.Request {
-	return common.MetadataListToRequests(ctx, r.Client, persesv1alpha2.GroupVersion.WithKind("PersesDatasourceList"))
+// findDatasourcesForSecret returns reconcile requests for the PersesDatasources whose client
+// configuration reads credentials or certificates from the changed Secret.
+func (r *PersesDatasourceReconciler) findDatasourcesForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	return r.findDatasourcesReferencing(ctx, persesv1alpha2.SecretSourceTypeSecret, obj)
+}
+
+// findDatasourcesForConfigMap returns reconcile requests for the PersesDatasources whose client
+// configuration reads credentials or certificates from the changed ConfigMap.
+func (r *PersesDatasourceReconciler) findDatasourcesForConfigMap(ctx context.Context, obj *metav1.PartialObjectMetadata) []reconcile.Request {
+	return r.findDatasourcesReferencing(ctx, persesv1alpha2.SecretSourceTypeConfigMap, obj)
+}
+
+// findDatasourcesReferencing returns reconcile requests for the PersesDatasources whose client
+// configuration references the changed Secret or ConfigMap, and for those whose secretRef names
+// a PersesSecret of their namespace that references it.
+func (r *PersesDatasourceReconciler) findDatasourcesReferencing(ctx context.Context, sourceType persesv1alpha2.SecretSourceType, obj client.Object) []reconcile.Request {
+	// The cache only holds the metadata of the datasources, the client configuration is read via APIReader.
+	dsList := &persesv1alpha2.PersesDatasourceList{}
+	if err := r.APIReader.List(ctx, dsList); err != nil {
+		log.WithError(err).Errorf("failed to list PersesDatasources for %s %s/%s", sourceType, obj.GetNamespace(), obj.GetName())
+		return nil
+	}
+
+	secretList := &persesv1alpha2.PersesSecretList{}
+	if err := r.APIReader.List(ctx, secretList); err != nil {
+		log.WithError(err).Errorf("failed to list PersesSecrets for %s %s/%s", sourceType, obj.GetNamespace(), obj.GetName())
+		return nil
+	}
+	referencingSecrets := map[types.NamespacedName]bool{}
+	for _, secret := range secretList.Items {
+		if common.ClientReferences(&secret.Spec.Client, secret.Namespace, sourceType, obj) {
+			referencingSecrets[types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}] = true
+		}
+	}
+
+	var requests []reconcile.Request
+	for _, ds := range dsList.Items {
+		if common.ClientReferences(ds.Spec.Client, ds.Namespace, sourceType, obj) ||
+			ds.Spec.SecretRef != nil && referencingSecrets[types.NamespacedName{Name: ds.Spec.SecretRef.Name, Namespace: ds.Namespace}] {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      ds.Name,
+					Namespace: ds.Namespace,
+				},
+			})
+		}
+	}
+
+	return requests
+}
+
+// findDatasourcesForPersesSecret returns reconcile requests for the PersesDatasources of the
+// namespace of the changed PersesSecret that reference it through their secretRef, so that a
+// datasource waiting for its secret is synced once the secret reaches Perses.
+func (r *PersesDatasourceReconciler) findDatasourcesForPersesSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	dsList := &persesv1alpha2.PersesDatasourceList{}
+	if err := r.APIReader.List(ctx, dsList, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.WithError(err).Errorf("failed to list PersesDatasources for PersesSecret %s/%s", obj.GetNamespace(), obj.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, ds := range dsList.Items {
+		if ds.Spec.SecretRef != nil && ds.Spec.SecretRef.Name == obj.GetName() {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      ds.Name,
+					Namespace: ds.Namespace,
+				},
+			})
+		}
+	}
+
+	return requests
 }
 
 // SetupWithManager sets up the controller with the Manager.
 // It watches PersesDatasource resources and also watches Perses instances
-// to trigger re-reconciliation of all datasources when a Perses instance becomes
-// available. Datasources are matched to Perses instances via instanceSelector labels.
+// to reconcile the datasources a Perses instance concerns when it becomes available or changes
+// its resource selectors or its labels: the datasources whose instanceSelector matches its
+// labels, before or after the change, and the datasources synced to it, so that a relabeled
+// instance gets the datasources it is now selected by and loses the others.
 // Create and delete events for Perses instances are ignored because
 // the instance is not yet ready at creation, and deletion is handled by the datasource's
-// own reconciliation loop.
+// own reconciliation loop through its finalizer.
+// It also watches the Secrets and, when ConfigMapCache is set, the ConfigMaps referenced by the
+// client configuration, so that rotated credentials are pushed to Perses. Only the Secrets and
+// ConfigMaps matching the watch label selector of their cache trigger reconciliation.
+// The datasources using a shared secret through their secretRef are reconciled as well when their
+// PersesSecret, or a Secret or ConfigMap it references, changes.
 func (r *PersesDatasourceReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	r.instanceSelectors = common.NewInstanceSelectorIndex()
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&persesv1alpha2.PersesDatasource{}, builder.OnlyMetadata).
 		Watches(
 			&persesv1alpha2.Perses{},
-			handler.EnqueueRequestsFromMapFunc(r.findDatasourcesForPerses),
-			builder.WithPredicates(common.PersesAvailabilityPredicate()),
+			common.EnqueueConcernedResources(r.Client, persesv1alpha2.GroupVersion.WithKind("PersesDatasourceList"), r.instanceSelectors),
+			builder.WithPredicates(common.PersesAdmissionPredicate()),
 		).
-		Complete(r)
+		// WatchesMetadata only caches metadata (not Data) to reduce memory.
+		// The referenced data is read via APIReader when the datasource is reconciled.
+		WatchesMetadata(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.findDatasourcesForSecret),
+		).
+		WatchesMetadata(
+			&persesv1alpha2.PersesSecret{},
+			handler.EnqueueRequestsFromMapFunc(r.findDatasourcesForPersesSecret),
+		)
+
+	if r.ConfigMapCache != nil {
+		b = b.WatchesRawSource(source.Kind(
+			r.ConfigMapCache,
+			internalcache.ConfigMapMetadata(),
+			handler.TypedEnqueueRequestsFromMapFunc(r.findDatasourcesForConfigMap),
+		))
+	}
+
+	return b.Complete(r)
 }
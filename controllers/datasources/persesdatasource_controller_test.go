@@ -21,14 +21,28 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/perses/common/set"
 	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	internal "github.com/perses/perses-operator/internal/perses"
 	"github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	persesv1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/secret"
+	specdatasource "github.com/perses/spec/go/datasource"
+	specplugin "github.com/perses/spec/go/plugin"
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 func TestDatasourceController(t *testing.T) {
@@ -39,6 +53,7 @@ func TestDatasourceController(t *testing.T) {
 func newTestDatasourceReconciler(objects ...runtime.Object) *PersesDatasourceReconciler {
 	scheme := runtime.NewScheme()
 	Expect(persesv1alpha2.AddToScheme(scheme)).To(Succeed())
+	Expect(corev1.AddToScheme(scheme)).To(Succeed())
 
 	clientBuilder := fake.NewClientBuilder().WithScheme(scheme)
 	for _, obj := range objects {
@@ -147,4 +162,426 @@ var _ = Describe("Datasource controller", func() {
 			Expect(result).ToNot(BeNil())
 		})
 	})
+
+	Context("syncPersesSecret", func() {
+		const DatasourceName = "prometheus"
+		const SecretName = "prometheus-secret"
+		const DatasourceNamespace = "monitoring"
+
+		perses := persesv1alpha2.Perses{
+			ObjectMeta: metav1.ObjectMeta{Name: "perses", Namespace: "perses-dev"},
+		}
+
+		credentials := func(password string) *corev1.Secret {
+			return &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "prometheus-credentials", Namespace: DatasourceNamespace},
+				Data:       map[string][]byte{"password": []byte(password)},
+			}
+		}
+
+		newDatasource := func() *persesv1alpha2.PersesDatasource {
+			return &persesv1alpha2.PersesDatasource{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      DatasourceName,
+					Namespace: DatasourceNamespace,
+				},
+				Spec: persesv1alpha2.DatasourceSpec{
+					Client: &persesv1alpha2.Client{
+						BasicAuth: &persesv1alpha2.BasicAuth{
+							SecretSource: persesv1alpha2.SecretSource{
+								Type: persesv1alpha2.SecretSourceTypeSecret,
+								Name: ptr.To("prometheus-credentials"),
+							},
+							Username:     "admin",
+							PasswordPath: "password",
+						},
+					},
+				},
+			}
+		}
+
+		expectedSecret := func(password string) *persesv1.Secret {
+			return &persesv1.Secret{
+				Kind: persesv1.KindSecret,
+				Metadata: persesv1.ProjectMetadata{
+					Metadata: persesv1.Metadata{Name: SecretName, Tags: common.WithManagedTag(nil)},
+				},
+				Spec: persesv1.SecretSpec{
+					BasicAuth: &secret.BasicAuth{Username: "admin", Password: password},
+				},
+			}
+		}
+
+		It("should create the secret and skip the update while its content is unchanged", func() {
+			mockPersesClient := &internal.MockClient{}
+			mockSecret := &internal.MockSecret{}
+			mockPersesClient.On("Secret", DatasourceNamespace).Return(mockSecret)
+			mockSecret.On("Get", SecretName).Return(&persesv1.Secret{}, perseshttp.RequestNotFoundError).Once()
+			mockSecret.On("Create", expectedSecret("s3cret")).Return(expectedSecret("s3cret"), nil).Once()
+
+			r := newTestDatasourceReconciler(credentials("s3cret"))
+
+			_, _, err := r.syncPersesSecret(context.Background(), perses, mockPersesClient, newDatasource())
+			Expect(err).ToNot(HaveOccurred())
+
+			mockSecret.On("Get", SecretName).Return(expectedSecret("<secret>"), nil).Once()
+			_, _, err = r.syncPersesSecret(context.Background(), perses, mockPersesClient, newDatasource())
+			Expect(err).ToNot(HaveOccurred())
+
+			mockSecret.AssertExpectations(GinkgoT())
+			mockSecret.AssertNotCalled(GinkgoT(), "Update", expectedSecret("s3cret"))
+		})
+
+		It("should update the secret when the referenced credentials are rotated", func() {
+			mockPersesClient := &internal.MockClient{}
+			mockSecret := &internal.MockSecret{}
+			mockPersesClient.On("Secret", DatasourceNamespace).Return(mockSecret)
+			mockSecret.On("Get", SecretName).Return(expectedSecret("<secret>"), nil)
+			mockSecret.On("Update", expectedSecret("s3cret")).Return(expectedSecret("s3cret"), nil).Once()
+			mockSecret.On("Update", expectedSecret("r0tated")).Return(expectedSecret("r0tated"), nil).Once()
+
+			r := newTestDatasourceReconciler(credentials("s3cret"))
+
+			_, _, err := r.syncPersesSecret(context.Background(), perses, mockPersesClient, newDatasource())
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(r.Update(context.Background(), credentials("r0tated"))).To(Succeed())
+			_, _, err = r.syncPersesSecret(context.Background(), perses, mockPersesClient, newDatasource())
+			Expect(err).ToNot(HaveOccurred())
+
+			mockSecret.AssertExpectations(GinkgoT())
+		})
+
+		It("should push the secret again once it was forgotten", func() {
+			mockPersesClient := &internal.MockClient{}
+			mockSecret := &internal.MockSecret{}
+			mockPersesClient.On("Secret", DatasourceNamespace).Return(mockSecret)
+			mockSecret.On("Get", SecretName).Return(expectedSecret("<secret>"), nil)
+			mockSecret.On("Update", expectedSecret("s3cret")).Return(expectedSecret("s3cret"), nil).Twice()
+
+			r := newTestDatasourceReconciler(credentials("s3cret"))
+
+			_, _, err := r.syncPersesSecret(context.Background(), perses, mockPersesClient, newDatasource())
+			Expect(err).ToNot(HaveOccurred())
+
+			r.secrets.Forget(common.SecretContentKey(perses, DatasourceNamespace, SecretName))
+			_, _, err = r.syncPersesSecret(context.Background(), perses, mockPersesClient, newDatasource())
+			Expect(err).ToNot(HaveOccurred())
+
+			mockSecret.AssertExpectations(GinkgoT())
+		})
+
+		It("should handle a secret not managed by the operator according to the conflict policy", func() {
+			unmanaged := expectedSecret("<secret>")
+			unmanaged.Metadata.Tags = nil
+			overwritten := expectedSecret("s3cret")
+			overwritten.Metadata.Tags = nil
+
+			mockPersesClient := &internal.MockClient{}
+			mockSecret := &internal.MockSecret{}
+			mockPersesClient.On("Secret", DatasourceNamespace).Return(mockSecret)
+			mockSecret.On("Get", SecretName).Return(unmanaged, nil)
+			mockSecret.On("Update", overwritten).Return(overwritten, nil).Once()
+			mockSecret.On("Update", expectedSecret("s3cret")).Return(expectedSecret("s3cret"), nil).Once()
+
+			r := newTestDatasourceReconciler(credentials("s3cret"))
+
+			By("Failing on the conflict")
+			datasource := newDatasource()
+			datasource.Spec.ConflictPolicy = persesv1alpha2.ConflictPolicyFail
+			_, reason, err := r.syncPersesSecret(context.Background(), perses, mockPersesClient, datasource)
+			Expect(err).To(HaveOccurred())
+			Expect(reason).To(Equal(common.ReasonConflict))
+			mockSecret.AssertNotCalled(GinkgoT(), "Update", mock.Anything)
+
+			By("Overwriting the secret without taking it over")
+			datasource.Spec.ConflictPolicy = persesv1alpha2.ConflictPolicyOverwrite
+			_, _, err = r.syncPersesSecret(context.Background(), perses, mockPersesClient, datasource)
+			Expect(err).ToNot(HaveOccurred())
+
+			By("Adopting the secret with unchanged content")
+			datasource.Spec.ConflictPolicy = persesv1alpha2.ConflictPolicyAdopt
+			_, _, err = r.syncPersesSecret(context.Background(), perses, mockPersesClient, datasource)
+			Expect(err).ToNot(HaveOccurred())
+
+			mockSecret.AssertExpectations(GinkgoT())
+		})
+	})
+
+	Context("findDatasourcesForSecret", func() {
+		It("should only enqueue the datasources referencing the changed secret", func() {
+			referencing := &persesv1alpha2.PersesDatasource{
+				ObjectMeta: metav1.ObjectMeta{Name: "referencing", Namespace: "monitoring"},
+				Spec: persesv1alpha2.DatasourceSpec{
+					Config: persesv1alpha2.Datasource{
+						Spec: specdatasource.Spec{
+							Plugin: specplugin.Plugin{Kind: "PrometheusDatasource", Spec: map[string]interface{}{}},
+						},
+					},
+					Client: &persesv1alpha2.Client{
+						BasicAuth: &persesv1alpha2.BasicAuth{
+							SecretSource: persesv1alpha2.SecretSource{
+								Type: persesv1alpha2.SecretSourceTypeSecret,
+								Name: ptr.To("prometheus-credentials"),
+							},
+						},
+					},
+				},
+			}
+			other := &persesv1alpha2.PersesDatasource{
+				ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "monitoring"},
+				Spec: persesv1alpha2.DatasourceSpec{
+					Config: persesv1alpha2.Datasource{
+						Spec: specdatasource.Spec{
+							Plugin: specplugin.Plugin{Kind: "PrometheusDatasource", Spec: map[string]interface{}{}},
+						},
+					},
+					Client: &persesv1alpha2.Client{
+						BasicAuth: &persesv1alpha2.BasicAuth{
+							SecretSource: persesv1alpha2.SecretSource{
+								Type: persesv1alpha2.SecretSourceTypeConfigMap,
+								Name: ptr.To("prometheus-credentials"),
+							},
+						},
+					},
+				},
+			}
+
+			r := newTestDatasourceReconciler(referencing, other)
+			changed := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "prometheus-credentials", Namespace: "monitoring"},
+			}
+
+			requests := r.findDatasourcesForSecret(context.Background(), changed)
+			Expect(requests).To(ConsistOf(reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "referencing", Namespace: "monitoring"},
+			}))
+
+			changedConfigMap := &metav1.PartialObjectMetadata{
+				ObjectMeta: metav1.ObjectMeta{Name: "prometheus-credentials", Namespace: "monitoring"},
+			}
+			requests = r.findDatasourcesForConfigMap(context.Background(), changedConfigMap)
+			Expect(requests).To(ConsistOf(reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "other", Namespace: "monitoring"},
+			}))
+		})
+
+		It("should enqueue the datasources whose shared secret references the changed secret", func() {
+			usingSecretRef := &persesv1alpha2.PersesDatasource{
+				ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "monitoring"},
+				Spec: persesv1alpha2.DatasourceSpec{
+					Config: persesv1alpha2.Datasource{
+						Spec: specdatasource.Spec{
+							Plugin: specplugin.Plugin{Kind: "PrometheusDatasource", Spec: map[string]interface{}{}},
+						},
+					},
+					SecretRef: &persesv1alpha2.SecretReference{Name: "prometheus-shared"},
+				},
+			}
+			otherNamespace := usingSecretRef.DeepCopy()
+			otherNamespace.Namespace = "default"
+			sharedSecret := &persesv1alpha2.PersesSecret{
+				ObjectMeta: metav1.ObjectMeta{Name: "prometheus-shared", Namespace: "monitoring"},
+				Spec: persesv1alpha2.SecretSpec{
+					Client: persesv1alpha2.Client{
+						BasicAuth: &persesv1alpha2.BasicAuth{
+							SecretSource: persesv1alpha2.SecretSource{
+								Type: persesv1alpha2.SecretSourceTypeSecret,
+								Name: ptr.To("prometheus-credentials"),
+							},
+						},
+					},
+				},
+			}
+
+			r := newTestDatasourceReconciler(usingSecretRef, otherNamespace, sharedSecret)
+
+			By("Rotating the credentials read by the shared secret")
+			changed := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "prometheus-credentials", Namespace: "monitoring"},
+			}
+			requests := r.findDatasourcesForSecret(context.Background(), changed)
+			Expect(requests).To(ConsistOf(reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "shared", Namespace: "monitoring"},
+			}))
+
+			By("Changing the shared secret itself")
+			requests = r.findDatasourcesForPersesSecret(context.Background(), sharedSecret)
+			Expect(requests).To(ConsistOf(reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "shared", Namespace: "monitoring"},
+			}))
+		})
+	})
+
+	Context("handleDelete", func() {
+		const DatasourceName = "prometheus"
+		const DatasourceNamespace = "monitoring"
+
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: DatasourceName, Namespace: DatasourceNamespace}}
+
+		newPerses := func(available bool) *persesv1alpha2.Perses {
+			status := metav1.ConditionFalse
+			if available {
+				status = metav1.ConditionTrue
+			}
+			return &persesv1alpha2.Perses{
+				ObjectMeta: metav1.ObjectMeta{Name: "perses", Namespace: "perses-dev"},
+				Status: persesv1alpha2.PersesStatus{
+					Conditions: []metav1.Condition{{
+						Type:               common.TypeAvailablePerses,
+						Status:             status,
+						Reason:             "Reconciled",
+						LastTransitionTime: metav1.Now(),
+					}},
+				},
+			}
+		}
+
+		deletingDatasource := func() *persesv1alpha2.PersesDatasource {
+			return &persesv1alpha2.PersesDatasource{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              DatasourceName,
+					Namespace:         DatasourceNamespace,
+					Finalizers:        []string{common.PersesFinalizer},
+					DeletionTimestamp: &metav1.Time{Time: time.Now()},
+				},
+				Spec: persesv1alpha2.DatasourceSpec{
+					Config: persesv1alpha2.Datasource{
+						Spec: specdatasource.Spec{
+							Plugin: specplugin.Plugin{Kind: "PrometheusDatasource", Spec: map[string]interface{}{}},
+						},
+					},
+				},
+				Status: persesv1alpha2.PersesDatasourceStatus{
+					SyncedInstances: []persesv1alpha2.PersesInstanceReference{{Namespace: "perses-dev", Name: "perses"}},
+				},
+			}
+		}
+
+		persesDatasource := func(tags set.Set[string]) *persesv1.Datasource {
+			return &persesv1.Datasource{
+				Metadata: persesv1.ProjectMetadata{Metadata: persesv1.Metadata{Name: DatasourceName, Tags: tags}},
+			}
+		}
+
+		persesSecret := func(tags set.Set[string]) *persesv1.Secret {
+			return &persesv1.Secret{
+				Metadata: persesv1.ProjectMetadata{Metadata: persesv1.Metadata{Name: DatasourceName + common.SecretNameSuffix, Tags: tags}},
+			}
+		}
+
+		It("should keep the finalizer while a synced instance is not available", func() {
+			datasource := deletingDatasource()
+			mockPersesClient := &internal.MockClient{}
+
+			r := newTestDatasourceReconciler(datasource, newPerses(false))
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			_, err := r.handleDelete(withDatasource(context.Background(), datasource), req)
+			Expect(common.ExtractReason(err, "")).To(Equal(common.ReasonDeletionBlocked))
+			mockPersesClient.AssertNotCalled(GinkgoT(), "Datasource", DatasourceNamespace)
+
+			fresh := &persesv1alpha2.PersesDatasource{}
+			Expect(r.Get(context.Background(), req.NamespacedName, fresh)).To(Succeed())
+			Expect(controllerutil.ContainsFinalizer(fresh, common.PersesFinalizer)).To(BeTrue())
+
+			degraded := apimeta.FindStatusCondition(fresh.Status.Conditions, common.TypeDegradedPerses)
+			Expect(degraded).ToNot(BeNil())
+			Expect(degraded.Reason).To(Equal(string(common.ReasonDeletionBlocked)))
+			Expect(degraded.Message).To(ContainSubstring("perses-dev/perses (not available)"))
+		})
+
+		It("should remove the datasource and its secret before releasing the finalizer", func() {
+			datasource := deletingDatasource()
+			mockPersesClient := &internal.MockClient{}
+			mockDatasource := &internal.MockDatasource{}
+			mockSecret := &internal.MockSecret{}
+			mockPersesClient.On("Datasource", DatasourceNamespace).Return(mockDatasource)
+			mockPersesClient.On("Secret", DatasourceNamespace).Return(mockSecret)
+			mockDatasource.On("Get", DatasourceName).Return(persesDatasource(common.WithManagedTag(nil)), nil)
+			mockDatasource.On("Delete", DatasourceName).Return(nil).Once()
+			mockSecret.On("Get", DatasourceName+common.SecretNameSuffix).Return(persesSecret(common.WithManagedTag(nil)), nil)
+			mockSecret.On("Delete", DatasourceName+common.SecretNameSuffix).Return(nil).Once()
+
+			r := newTestDatasourceReconciler(datasource, newPerses(true))
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			_, err := r.handleDelete(withDatasource(context.Background(), datasource), req)
+			Expect(err).ToNot(HaveOccurred())
+			mockDatasource.AssertExpectations(GinkgoT())
+			mockSecret.AssertExpectations(GinkgoT())
+
+			err = r.Get(context.Background(), req.NamespacedName, &persesv1alpha2.PersesDatasource{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should keep a datasource not managed by the operator in Perses", func() {
+			datasource := deletingDatasource()
+			mockPersesClient := &internal.MockClient{}
+			mockDatasource := &internal.MockDatasource{}
+			mockSecret := &internal.MockSecret{}
+			mockPersesClient.On("Datasource", DatasourceNamespace).Return(mockDatasource)
+			mockPersesClient.On("Secret", DatasourceNamespace).Return(mockSecret)
+			mockDatasource.On("Get", DatasourceName).Return(persesDatasource(nil), nil)
+			mockSecret.On("Get", DatasourceName+common.SecretNameSuffix).Return(&persesv1.Secret{}, perseshttp.RequestNotFoundError)
+
+			r := newTestDatasourceReconciler(datasource, newPerses(true))
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			_, err := r.handleDelete(withDatasource(context.Background(), datasource), req)
+			Expect(err).ToNot(HaveOccurred())
+			mockDatasource.AssertNotCalled(GinkgoT(), "Delete", DatasourceName)
+		})
+
+		It("should only plan the deletion of the secret of a missing or unmanaged datasource in a dry run", func() {
+			for _, existing := range []struct {
+				datasource *persesv1.Datasource
+				err        error
+			}{
+				{datasource: &persesv1.Datasource{}, err: perseshttp.RequestNotFoundError},
+				{datasource: persesDatasource(nil)},
+			} {
+				datasource := deletingDatasource()
+				mockPersesClient := &internal.MockClient{}
+				mockDatasource := &internal.MockDatasource{}
+				mockSecret := &internal.MockSecret{}
+				mockPersesClient.On("Datasource", DatasourceNamespace).Return(mockDatasource)
+				mockPersesClient.On("Secret", DatasourceNamespace).Return(mockSecret)
+				mockDatasource.On("Get", DatasourceName).Return(existing.datasource, existing.err)
+				mockSecret.On("Get", DatasourceName+common.SecretNameSuffix).Return(persesSecret(common.WithManagedTag(nil)), nil)
+
+				recorder := record.NewFakeRecorder(10)
+				r := newTestDatasourceReconciler(datasource, newPerses(true))
+				r.ClientFactory = common.NewWithClient(mockPersesClient)
+				r.Recorder = recorder
+				r.DryRun = true
+
+				_, err := r.deleteDatasource(context.Background(), *newPerses(true), datasource)
+				Expect(err).ToNot(HaveOccurred())
+				mockDatasource.AssertNotCalled(GinkgoT(), "Delete", DatasourceName)
+				mockSecret.AssertNotCalled(GinkgoT(), "Delete", DatasourceName+common.SecretNameSuffix)
+				Expect(recorder.Events).To(Receive(ContainSubstring("Dry run: secret prometheus-secret would be deleted")))
+			}
+		})
+
+		It("should keep a secret not managed by the operator in Perses", func() {
+			datasource := deletingDatasource()
+			mockPersesClient := &internal.MockClient{}
+			mockDatasource := &internal.MockDatasource{}
+			mockSecret := &internal.MockSecret{}
+			mockPersesClient.On("Datasource", DatasourceNamespace).Return(mockDatasource)
+			mockPersesClient.On("Secret", DatasourceNamespace).Return(mockSecret)
+			mockDatasource.On("Get", DatasourceName).Return(persesDatasource(common.WithManagedTag(nil)), nil)
+			mockDatasource.On("Delete", DatasourceName).Return(nil).Once()
+			mockSecret.On("Get", DatasourceName+common.SecretNameSuffix).Return(persesSecret(nil), nil)
+
+			r := newTestDatasourceReconciler(datasource, newPerses(true))
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			_, err := r.handleDelete(withDatasource(context.Background(), datasource), req)
+			Expect(err).ToNot(HaveOccurred())
+			mockDatasource.AssertExpectations(GinkgoT())
+			mockSecret.AssertNotCalled(GinkgoT(), "Delete", DatasourceName+common.SecretNameSuffix)
+		})
+	})
 })
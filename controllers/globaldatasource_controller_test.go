@@ -108,6 +108,7 @@ var _ = Describe("GlobalDatasource controller", Ordered, func() {
 				Kind: persesv1.KindGlobalSecret,
 				Metadata: persesv1.Metadata{
 					Name: PersesSecretName,
+					Tags: common.WithManagedTag(nil),
 				},
 				Spec: persesv1.SecretSpec{},
 			}
@@ -116,6 +117,7 @@ var _ = Describe("GlobalDatasource controller", Ordered, func() {
 				Kind: persesv1.KindGlobalDatasource,
 				Metadata: persesv1.Metadata{
 					Name: GlobalDatasourceName,
+					Tags: common.WithManagedTag(nil),
 				},
 				Spec: specdatasource.Spec{
 					Display: &speccommon.Display{
@@ -190,7 +192,7 @@ var _ = Describe("GlobalDatasource controller", Ordered, func() {
 
 			// The globaldatasource was created in the Perses API
 			getGlobalDatasource.Unset()
-			mockGlobalDatasource.On("Get", GlobalDatasourceName).Return(&persesv1.GlobalDatasource{}, nil)
+			mockGlobalDatasource.On("Get", GlobalDatasourceName).Return(newGlobalDatasource, nil)
 
 			By("Checking if the Perses API was called to create a globaldatasource")
 			Eventually(func() error {
@@ -235,6 +237,7 @@ var _ = Describe("GlobalDatasource controller", Ordered, func() {
 			}, time.Minute, time.Second).Should(Succeed())
 
 			mockGlobalDatasource.On("Delete", GlobalDatasourceName).Return(nil)
+			mockGlobalSecret.On("Get", PersesSecretName).Return(newSecret, nil)
 			mockGlobalSecret.On("Delete", PersesSecretName).Return(nil)
 
 			globaldatasourceToDelete := &persesv1alpha2.PersesGlobalDatasource{}
@@ -355,7 +358,7 @@ var _ = Describe("GlobalDatasource controller", Ordered, func() {
 				return err
 			}, time.Minute, time.Second).Should(Succeed())
 
-			mockGlobalDatasource.On("Delete", GlobalDatasourceName).Return(nil)
+			mockGlobalSecret.On("Get", PersesSecretName).Return(newSecret, nil)
 			mockGlobalSecret.On("Delete", PersesSecretName).Return(nil)
 
 			globaldatasourceToDelete := &persesv1alpha2.PersesGlobalDatasource{}
@@ -372,13 +375,8 @@ var _ = Describe("GlobalDatasource controller", Ordered, func() {
 
 			Expect(err).To(Not(HaveOccurred()))
 
-			By("Checking if the Perses API was called to delete a globaldatasource")
-			Eventually(func() error {
-				if !mockGlobalDatasource.AssertExpectations(GinkgoT()) {
-					return fmt.Errorf("The Perses API was not called to create a globaldatasource")
-				}
-				return nil
-			}, time.Minute, time.Second).Should(Succeed())
+			By("Checking the Perses API was not called to delete a globaldatasource that was never created")
+			mockGlobalDatasource.AssertNotCalled(GinkgoT(), "Delete", GlobalDatasourceName)
 		})
 
 		It("should return an error when the Perses API delete call fails", func() {
@@ -388,7 +386,8 @@ var _ = Describe("GlobalDatasource controller", Ordered, func() {
 			if err != nil && errors.IsNotFound(err) {
 				globaldatasource = &persesv1alpha2.PersesGlobalDatasource{
 					ObjectMeta: metav1.ObjectMeta{
-						Name: GlobalDatasourceName,
+						Name:       GlobalDatasourceName,
+						Finalizers: []string{common.PersesFinalizer},
 					},
 					Spec: persesv1alpha2.DatasourceSpec{
 						Config: persesv1alpha2.Datasource{
@@ -401,12 +400,22 @@ var _ = Describe("GlobalDatasource controller", Ordered, func() {
 				Expect(err).To(Not(HaveOccurred()))
 			}
 
+			By("Recording the Perses instance the global datasource was synced to")
+			Eventually(func() error {
+				if err := k8sClient.Get(ctx, globaldatasourceNamespaceName, globaldatasource); err != nil {
+					return err
+				}
+				globaldatasource.Status.SyncedInstances = []persesv1alpha2.PersesInstanceReference{{Namespace: PersesNamespace, Name: PersesName}}
+				return k8sClient.Status().Update(ctx, globaldatasource)
+			}, time.Second*10, time.Millisecond*250).Should(Succeed())
+
 			mockPersesClient, validateServer := internal.NewMockClientWithValidation()
 			defer validateServer.Close()
 			mockGlobalDatasource := new(internal.MockGlobalDatasource)
 
 			mockPersesClient.On("GlobalDatasource").Return(mockGlobalDatasource)
-			mockGlobalDatasource.On("Delete", GlobalDatasourceName).Return(perseshttp.RequestInternalError)
+			mockGlobalDatasource.On("Get", GlobalDatasourceName).Return(newGlobalDatasource, nil)
+			deleteCall := mockGlobalDatasource.On("Delete", GlobalDatasourceName).Return(perseshttp.RequestInternalError)
 
 			globaldatasourceReconciler := &globaldatasourcecontroller.PersesGlobalDatasourceReconciler{
 				Client:        k8sClient,
@@ -428,7 +437,25 @@ var _ = Describe("GlobalDatasource controller", Ordered, func() {
 				NamespacedName: globaldatasourceNamespaceName,
 			})
 			Expect(err).To(HaveOccurred())
+			Expect(common.ExtractReason(err, "")).To(Equal(common.ReasonDeletionBlocked))
 			mockGlobalDatasource.AssertCalled(GinkgoT(), "Delete", GlobalDatasourceName)
+
+			By("Checking the finalizer keeps the global datasource until the Perses API confirms the deletion")
+			Expect(k8sClient.Get(ctx, globaldatasourceNamespaceName, &persesv1alpha2.PersesGlobalDatasource{})).To(Succeed())
+
+			deleteCall.Unset()
+			mockGlobalSecret := new(internal.MockGlobalSecret)
+			mockPersesClient.On("GlobalSecret").Return(mockGlobalSecret)
+			mockGlobalDatasource.On("Delete", GlobalDatasourceName).Return(nil)
+			mockGlobalSecret.On("Get", GlobalDatasourceName+common.SecretNameSuffix).Return(&persesv1.GlobalSecret{}, perseshttp.RequestNotFoundError)
+
+			_, err = globaldatasourceReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: globaldatasourceNamespaceName,
+			})
+			Expect(err).To(Not(HaveOccurred()))
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, globaldatasourceNamespaceName, &persesv1alpha2.PersesGlobalDatasource{}))
+			}, time.Minute, time.Second).Should(BeTrue())
 		})
 
 		It("should set degraded status with ValidationFailed reason when server-side validation fails", func() {
@@ -522,6 +549,18 @@ var _ = Describe("GlobalDatasource controller", Ordered, func() {
 			Expect(err).To(Not(HaveOccurred()))
 			err = k8sClient.Delete(ctx, gdToDelete)
 			Expect(err).To(Not(HaveOccurred()))
+
+			By("Releasing the finalizer once Perses reports the global datasource as absent")
+			mockGlobalSecret := new(internal.MockGlobalSecret)
+			mockPersesClient.On("GlobalSecret").Return(mockGlobalSecret)
+			mockGlobalSecret.On("Get", GlobalDatasourceName+common.SecretNameSuffix).Return(&persesv1.GlobalSecret{}, perseshttp.RequestNotFoundError)
+			_, err = globaldatasourceReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: globaldatasourceNamespaceName,
+			})
+			Expect(err).To(Not(HaveOccurred()))
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, globaldatasourceNamespaceName, &persesv1alpha2.PersesGlobalDatasource{}))
+			}, time.Minute, time.Second).Should(BeTrue())
 		})
 	})
 })
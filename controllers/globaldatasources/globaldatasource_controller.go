@@ -17,18 +17,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
+	"strings"
 	"time"
 
+	"github.com/perses/common/set"
 	v1 "github.com/perses/perses/pkg/client/api/v1"
 	"github.com/perses/perses/pkg/client/api/validate"
 	"github.com/perses/perses/pkg/client/perseshttp"
 	persesv1 "github.com/perses/perses/pkg/model/api/v1"
-	"github.com/perses/perses/pkg/model/api/v1/secret"
 	logger "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -69,22 +72,174 @@ func (r *PersesGlobalDatasourceReconciler) reconcileGlobalDatasourcesInAllInstan
 		return r.setStatusToDegraded(ctx, req, res, persescommon.ReasonMissingPerses, err)
 	}
 
+	r.instanceSelectors.Set(req.NamespacedName, labelSelector, globaldatasource.Status.SyncedInstances)
+	// The instances rejecting the global datasource are checked first, so that it is removed from them
+	// as from the instances no longer selected.
+	allowing := make([]persesv1alpha2.Perses, 0, len(persesInstances.Items))
+	rejecting := make(map[persesv1alpha2.PersesInstanceReference]bool)
+	for _, persesInstance := range persesInstances.Items {
+		allowed, err := persescommon.InstanceAllowsResource(ctx, r.APIReader, persesInstance, globaldatasource)
+		if err != nil {
+			gdlog.WithError(err).Errorf("Failed to check if Perses instance %s/%s allows global datasource %s", persesInstance.Namespace, persesInstance.Name, globaldatasource.Name)
+			res, err := subreconciler.RequeueWithError(err)
+			return r.setStatusToDegraded(ctx, req, res, persescommon.ReasonInvalidConfiguration, err)
+		}
+		if !allowed {
+			rejecting[persescommon.InstanceReference(persesInstance)] = true
+			continue
+		}
+		allowing = append(allowing, persesInstance)
+	}
+
+	blockedRemovals, res, err := r.removeGlobalDatasourceFromDeselectedInstances(ctx, req, globaldatasource, allowing)
+	if subreconciler.ShouldHaltOrRequeue(res, err) {
+		return res, err
+	}
+
 	if len(persesInstances.Items) == 0 {
 		gdlog.Info("No Perses instances found, requeue in 1 minute")
 		return r.setStatusToDegraded(ctx, req, &ctrl.Result{RequeueAfter: time.Minute}, persescommon.ReasonMissingPerses, fmt.Errorf("no Perses instances found matching the label selector"))
 	}
 
+	available := make([]persesv1alpha2.Perses, 0, len(persesInstances.Items))
+	instances := make([]persesv1alpha2.PersesInstanceStatus, 0, len(persesInstances.Items))
+	var notAllowed []string
 	for _, persesInstance := range persesInstances.Items {
 		if !meta.IsStatusConditionTrue(persesInstance.Status.Conditions, persescommon.TypeAvailablePerses) {
 			gdlog.Infof("Skipping Perses instance %s/%s (not yet available)", persesInstance.Namespace, persesInstance.Name)
+			instances = append(instances, persescommon.UnavailableInstanceStatus(globaldatasource.Status.Instances, persesInstance))
 			continue
 		}
-		if res, reason, err := r.syncPersesGlobalDatasource(ctx, persesInstance, globaldatasource); subreconciler.ShouldHaltOrRequeue(res, err) {
-			return r.setStatusToDegraded(ctx, req, res, reason, err)
+		if rejecting[persescommon.InstanceReference(persesInstance)] {
+			gdlog.Infof("Skipping Perses instance %s/%s (global datasource %s not allowed by its resource selectors)", persesInstance.Namespace, persesInstance.Name, globaldatasource.Name)
+			notAllowed = append(notAllowed, fmt.Sprintf("%s/%s", persesInstance.Namespace, persesInstance.Name))
+			instances = append(instances, persescommon.NotAllowedInstanceStatus(persesInstance))
+			continue
 		}
+		available = append(available, persesInstance)
 	}
 
-	return subreconciler.ContinueReconciling()
+	// Nothing is written to the instances in a dry run, so they are not recorded for deletion.
+	dryRun := persescommon.IsDryRun(globaldatasource, r.DryRun)
+	if !dryRun {
+		if res, err := r.recordSyncedInstances(ctx, req, available); subreconciler.ShouldHaltOrRequeue(res, err) {
+			return res, err
+		}
+	}
+
+	// A global datasource whose spec and tags were already applied to an instance is not compared
+	// with it again before the next verification.
+	verificationPeriod := persescommon.VerificationPeriod(persescommon.ResyncPeriod(globaldatasource.Spec.ResyncPeriodSeconds, r.ResyncPeriod), r.VerificationPeriod)
+	// The credentials of a global datasource are read again on every sync, so that their rotation
+	// reaches Perses: a global datasource holding them is never skipped.
+	if persescommon.HasSecretConfig(globaldatasource.Spec.Client) {
+		verificationPeriod = 0
+	}
+	tags := persescommon.ParseTags(globaldatasource.Annotations)
+
+	// Every instance is synced, so that a failing instance does not hold back the others.
+	outcomes := subreconciler.ForEach(available, r.InstanceSyncConcurrency, func(persesInstance persesv1alpha2.Perses) (*ctrl.Result, persescommon.ConditionStatusReason, error) {
+		if persescommon.AlreadyApplied(globaldatasource.Status.Instances, persesInstance, globaldatasource.Generation, persescommon.AppliedHash(persesInstance, globaldatasource.Spec.Config.Spec, tags), verificationPeriod) {
+			gdlog.Debugf("Global datasource already applied to Perses instance %s/%s: %s", persesInstance.Namespace, persesInstance.Name, globaldatasource.Name)
+			res, err := subreconciler.ContinueReconciling()
+			return res, persescommon.ReasonAlreadyApplied, err
+		}
+		return r.syncPersesGlobalDatasource(ctx, persesInstance, globaldatasource)
+	})
+
+	var drifted, planned, failed, failures []string
+	var firstFailure subreconciler.Outcome
+	for i, persesInstance := range available {
+		instanceName := fmt.Sprintf("%s/%s", persesInstance.Namespace, persesInstance.Name)
+		outcome := outcomes[i]
+		if outcome.Halted() {
+			failed = append(failed, instanceName)
+			if len(failures) == 0 {
+				firstFailure = outcome
+			}
+			failures = append(failures, fmt.Sprintf("%s: %v", instanceName, outcome.Err))
+			instances = append(instances, persescommon.FailedInstanceStatus(globaldatasource.Status.Instances, persesInstance, outcome.Reason))
+			persescommon.RecordInstanceEvent(r.Recorder, globaldatasource, persesInstance, corev1.EventTypeWarning, string(outcome.Reason), "Failed to sync global datasource: %v", outcome.Err)
+			continue
+		}
+		// The planned changes were not applied, so the last sync status of the instance is kept.
+		if outcome.Reason == persescommon.ReasonDryRun {
+			planned = append(planned, instanceName)
+			if status, found := persescommon.FindInstanceStatus(globaldatasource.Status.Instances, persesInstance); found {
+				instances = append(instances, status)
+			}
+			continue
+		}
+		driftCorrected := outcome.Reason == persescommon.ReasonDriftCorrected
+		if driftCorrected {
+			drifted = append(drifted, instanceName)
+		}
+		status := persescommon.SyncedInstanceStatus(globaldatasource.Status.Instances, persesInstance, globaldatasource.Generation, driftCorrected, "")
+		if verificationPeriod > 0 && outcome.Reason != persescommon.ReasonAlreadyApplied {
+			status = persescommon.VerifiedInstanceStatus(status, persescommon.AppliedHash(persesInstance, globaldatasource.Spec.Config.Spec, tags))
+		}
+		instances = append(instances, status)
+	}
+	persescommon.SortInstanceStatuses(instances)
+
+	res, err = r.updateGlobalDatasourceStatus(ctx, req, func(globaldatasource *persesv1alpha2.PersesGlobalDatasource) {
+		globaldatasource.Status.Instances = instances
+		if dryRun {
+			meta.SetStatusCondition(&globaldatasource.Status.Conditions, persescommon.DryRunCondition("GlobalDatasource", globaldatasource.Name, planned))
+		} else {
+			meta.RemoveStatusCondition(&globaldatasource.Status.Conditions, persescommon.TypeDryRunPerses)
+			meta.SetStatusCondition(&globaldatasource.Status.Conditions, persescommon.DriftCondition("GlobalDatasource", globaldatasource.Name, drifted))
+		}
+		meta.SetStatusCondition(&globaldatasource.Status.Conditions, persescommon.PartialSyncCondition("GlobalDatasource", globaldatasource.Name, len(available)-len(failed), failed))
+		meta.SetStatusCondition(&globaldatasource.Status.Conditions, persescommon.NotAllowedCondition("GlobalDatasource", globaldatasource.Name, notAllowed))
+	})
+	if subreconciler.ShouldHaltOrRequeue(res, err) {
+		return res, err
+	}
+	if len(available) == 0 && len(notAllowed) > 0 {
+		gdlog.Infof("GlobalDatasource %s is not allowed by any available Perses instance", globaldatasource.Name)
+		return r.setStatusToDegraded(ctx, req, &ctrl.Result{}, persescommon.ReasonNotAllowedByInstance,
+			fmt.Errorf("not allowed by the resource selectors of the Perses instances: %s", strings.Join(notAllowed, ", ")))
+	}
+	if len(failed) == 0 && len(blockedRemovals) > 0 {
+		return r.setStatusToDegraded(ctx, req, &ctrl.Result{RequeueAfter: time.Minute}, persescommon.ReasonDeletionBlocked,
+			fmt.Errorf("global datasource removal is waiting for Perses instances no longer selecting or allowing it: %s", strings.Join(blockedRemovals, "; ")))
+	}
+	if len(failed) == 0 {
+		return res, err
+	}
+
+	gdlog.Errorf("GlobalDatasource %s failed to sync to %d of %d Perses instances", globaldatasource.Name, len(failed), len(available))
+	// A single failure is reported as is, so that its message stays actionable.
+	failure := firstFailure.Err
+	if len(failed) > 1 {
+		failure = fmt.Errorf("failed to sync to %d of %d Perses instances: %s", len(failed), len(available), strings.Join(failures, "; "))
+	}
+	return r.setStatusToDegraded(ctx, req, firstFailure.Result, firstFailure.Reason, failure)
+}
+
+// removeGlobalDatasourceFromDeselectedInstances removes the global datasource from the Perses instances it was
+// synced to that its instanceSelector no longer selects, e.g. after they were relabeled,
+// or whose resource selectors no longer allow it.
+// It returns a description of every instance that could not confirm the removal.
+func (r *PersesGlobalDatasourceReconciler) removeGlobalDatasourceFromDeselectedInstances(ctx context.Context, req ctrl.Request, globaldatasource *persesv1alpha2.PersesGlobalDatasource, selected []persesv1alpha2.Perses) ([]string, *ctrl.Result, error) {
+	deselected := persescommon.DeselectedInstances(globaldatasource.Status.SyncedInstances, selected)
+	if len(deselected) == 0 {
+		return nil, nil, nil
+	}
+
+	gdlog.Infof("Global datasource %s is no longer selected or allowed by %d Perses instances, removing it from them", globaldatasource.Name, len(deselected))
+	removed, blocked := r.deleteGlobalDatasourceInInstances(ctx, globaldatasource, deselected)
+	// Nothing is removed in a dry run, so the instances are kept for the next reconciliation.
+	if len(removed) > 0 && !persescommon.IsDryRun(globaldatasource, r.DryRun) {
+		res, err := r.updateGlobalDatasourceStatus(ctx, req, func(globaldatasource *persesv1alpha2.PersesGlobalDatasource) {
+			globaldatasource.Status.SyncedInstances = persescommon.RemoveInstanceReferences(globaldatasource.Status.SyncedInstances, removed)
+		})
+		if subreconciler.ShouldHaltOrRequeue(res, err) {
+			return blocked, res, err
+		}
+	}
+	return blocked, nil, nil
 }
 
 func (r *PersesGlobalDatasourceReconciler) syncPersesGlobalDatasource(ctx context.Context, perses persesv1alpha2.Perses, globaldatasource *persesv1alpha2.PersesGlobalDatasource) (*ctrl.Result, persescommon.ConditionStatusReason, error) {
@@ -96,15 +251,6 @@ func (r *PersesGlobalDatasourceReconciler) syncPersesGlobalDatasource(ctx contex
 
 	}
 
-	globalDatasourceWithName := &persesv1.GlobalDatasource{
-		Kind: persesv1.KindGlobalDatasource,
-		Metadata: persesv1.Metadata{
-			Name: globaldatasource.Name,
-			Tags: persescommon.ParseTags(globaldatasource.Annotations),
-		},
-		Spec: globaldatasource.Spec.Config.Spec,
-	}
-
 	existing, err := persesClient.GlobalDatasource().Get(globaldatasource.Name)
 	notFound := err != nil && errors.Is(err, perseshttp.RequestNotFoundError)
 
@@ -113,34 +259,93 @@ func (r *PersesGlobalDatasourceReconciler) syncPersesGlobalDatasource(ctx contex
 		return res, persescommon.ReasonBackendError, err
 	}
 
-	if !notFound && persescommon.GlobalDatasourceInSync(existing, globalDatasourceWithName) {
-		gdlog.Debugf("GlobalDatasource already in sync: %s", globaldatasource.Name)
-		res, err := subreconciler.ContinueReconciling()
-		return res, "", err
+	tags := persescommon.ParseTags(globaldatasource.Annotations)
+	var existingTags set.Set[string]
+	if !notFound {
+		existingTags = existing.Metadata.Tags
+	}
+	managed, err := persescommon.ClaimOwnership(globaldatasource.Spec.ConflictPolicy, "global datasource", globaldatasource.Name, !notFound, existingTags)
+	if err != nil {
+		gdlog.WithError(err).Errorf("GlobalDatasource conflict: %s", globaldatasource.Name)
+		return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonConflict)
+	}
+	if managed {
+		tags = persescommon.WithManagedTag(tags)
 	}
 
-	if validateErr := validate.New(persesClient.RESTClient()).GlobalDatasource(globalDatasourceWithName); validateErr != nil {
-		if persescommon.IsClientError(validateErr) {
-			gdlog.WithError(validateErr).Errorf("GlobalDatasource validation failed: %s", globaldatasource.Name)
+	globalDatasourceWithName := &persesv1.GlobalDatasource{
+		Kind: persesv1.KindGlobalDatasource,
+		Metadata: persesv1.Metadata{
+			Name: globaldatasource.Name,
+			Tags: tags,
+		},
+		Spec: globaldatasource.Spec.Config.Spec,
+	}
+
+	inSync := !notFound && persescommon.GlobalDatasourceInSync(existing, globalDatasourceWithName)
+	dryRun := persescommon.IsDryRun(globaldatasource, r.DryRun)
+
+	if !inSync {
+		if validateErr := validate.New(persesClient.RESTClient()).GlobalDatasource(globalDatasourceWithName); validateErr != nil {
+			if persescommon.IsClientError(validateErr) {
+				gdlog.WithError(validateErr).Errorf("GlobalDatasource validation failed: %s", globaldatasource.Name)
+				return subreconciler.RequeueWithErrorAndReason(
+					fmt.Errorf("global datasource %q failed server-side validation: %w", globaldatasource.Name, validateErr),
+					persescommon.ReasonValidationFailed,
+				)
+			}
+			gdlog.WithError(validateErr).Errorf("GlobalDatasource validation request failed: %s", globaldatasource.Name)
 			return subreconciler.RequeueWithErrorAndReason(
-				fmt.Errorf("global datasource %q failed server-side validation: %w", globaldatasource.Name, validateErr),
-				persescommon.ReasonValidationFailed,
+				fmt.Errorf("global datasource %q validation request failed: %w", globaldatasource.Name, validateErr),
+				persescommon.ReasonBackendError,
 			)
 		}
-		gdlog.WithError(validateErr).Errorf("GlobalDatasource validation request failed: %s", globaldatasource.Name)
-		return subreconciler.RequeueWithErrorAndReason(
-			fmt.Errorf("global datasource %q validation request failed: %w", globaldatasource.Name, validateErr),
-			persescommon.ReasonBackendError,
-		)
 	}
 
-	// Sync secret only after validation passes to avoid orphaned secrets
+	// A dry run plans the global datasource only, the secret holding its credentials is not synced.
+	if dryRun {
+		if inSync {
+			gdlog.Debugf("GlobalDatasource already in sync: %s", globaldatasource.Name)
+			res, err := subreconciler.ContinueReconciling()
+			return res, "", err
+		}
+		plan := "created"
+		if !notFound {
+			plan = fmt.Sprintf("updated: %s", persescommon.FormatDiff(persescommon.GlobalDatasourceDiff(existing, globalDatasourceWithName), persescommon.MaxEventDiffLength))
+		}
+		gdlog.Infof("Dry run, global datasource %s would be %s in Perses instance %s/%s", globaldatasource.Name, plan, perses.Namespace, perses.Name)
+		persescommon.RecordInstanceEvent(r.Recorder, globaldatasource, perses, corev1.EventTypeNormal, string(persescommon.ReasonDryRun), "Dry run: global datasource would be %s", plan)
+		res, err := subreconciler.ContinueReconciling()
+		return res, persescommon.ReasonDryRun, err
+	}
+
+	// Sync secret only after validation passes to avoid orphaned secrets.
+	// It is synced even when the datasource is in sync, so that credentials rotated
+	// in the referenced Secrets or ConfigMaps reach Perses.
 	if persescommon.HasSecretConfig(globaldatasource.Spec.Client) {
-		_, reason, err := r.syncPersesGlobalSecret(ctx, persesClient, globaldatasource)
+		_, reason, err := r.syncPersesGlobalSecret(ctx, perses, persesClient, globaldatasource)
 		if err != nil {
 			gdlog.WithError(err).Errorf("Failed to create globaldatasource secret: %s", globaldatasource.Name)
 			return subreconciler.RequeueWithErrorAndReason(err, reason)
 		}
+	} else if globaldatasource.Spec.SecretRef != nil {
+		// A shared secret is synced by its own PersesGlobalSecret, it only has to exist in Perses.
+		secretName := globaldatasource.Spec.SecretRef.Name
+		if _, err := persesClient.GlobalSecret().Get(secretName); err != nil {
+			if errors.Is(err, perseshttp.RequestNotFoundError) {
+				return subreconciler.RequeueWithErrorAndReason(
+					fmt.Errorf("global secret %q referenced by global datasource %q not found in Perses instance %s/%s: %w", secretName, globaldatasource.Name, perses.Namespace, perses.Name, err),
+					persescommon.ReasonMissingResource,
+				)
+			}
+			return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonBackendError)
+		}
+	}
+
+	if inSync {
+		gdlog.Debugf("GlobalDatasource already in sync: %s", globaldatasource.Name)
+		res, err := subreconciler.ContinueReconciling()
+		return res, "", err
 	}
 
 	if notFound {
@@ -150,13 +355,31 @@ func (r *PersesGlobalDatasourceReconciler) syncPersesGlobalDatasource(ctx contex
 			return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonBackendError)
 		}
 		gdlog.Infof("GlobalDatasource created: %s", globaldatasource.Name)
+		persescommon.RecordInstanceEvent(r.Recorder, globaldatasource, perses, corev1.EventTypeNormal, persescommon.EventReasonCreated, "Global datasource created")
 	} else {
+		changes := persescommon.GlobalDatasourceDiff(existing, globalDatasourceWithName)
 		_, err = persesClient.GlobalDatasource().Update(globalDatasourceWithName)
 		if err != nil {
 			gdlog.WithError(err).Errorf("Failed to update globaldatasource: %s", globaldatasource.Name)
 			return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonBackendError)
 		}
 		gdlog.Infof("GlobalDatasource updated: %s", globaldatasource.Name)
+		gdlog.WithField("diff", persescommon.FormatDiff(changes, 0)).Debugf("GlobalDatasource %s changed in Perses instance %s/%s", globaldatasource.Name, perses.Namespace, perses.Name)
+		eventDiff := persescommon.FormatDiff(changes, persescommon.MaxEventDiffLength)
+
+		// The global datasource already matched this generation of the custom resource,
+		// so the difference was introduced in Perses. Claiming an object written by an earlier
+		// version of the operator is not a drift.
+		if persescommon.SyncedAtGeneration(globaldatasource.Status.Conditions, globaldatasource.Generation) && !persescommon.OwnershipClaimOnly(changes) {
+			gdlog.Infof("GlobalDatasource drift corrected in Perses instance %s/%s: %s", perses.Namespace, perses.Name, globaldatasource.Name)
+			if r.Metrics != nil {
+				r.Metrics.DriftCorrections("globaldatasource").Inc()
+			}
+			persescommon.RecordInstanceEvent(r.Recorder, globaldatasource, perses, corev1.EventTypeNormal, string(persescommon.ReasonDriftCorrected), "Global datasource modified in Perses, restored from its custom resource: %s", eventDiff)
+			res, err := subreconciler.ContinueReconciling()
+			return res, persescommon.ReasonDriftCorrected, err
+		}
+		persescommon.RecordInstanceEvent(r.Recorder, globaldatasource, perses, corev1.EventTypeNormal, persescommon.EventReasonUpdated, "Global datasource updated: %s", eventDiff)
 	}
 
 	res, err := subreconciler.ContinueReconciling()
@@ -165,226 +388,125 @@ func (r *PersesGlobalDatasourceReconciler) syncPersesGlobalDatasource(ctx contex
 
 // creates/updates a Perses Global Secret with configuration,
 // retrieving cert/key data from Secrets, ConfigMaps, or files specified in the PersesGlobalDatasource.
-func (r *PersesGlobalDatasourceReconciler) syncPersesGlobalSecret(ctx context.Context, persesClient v1.ClientInterface, datasource *persesv1alpha2.PersesGlobalDatasource) (*ctrl.Result, persescommon.ConditionStatusReason, error) {
+// An existing secret is only updated when its content differs from the content last pushed,
+// and one the operator does not own is handled according to the conflict policy of the global datasource.
+func (r *PersesGlobalDatasourceReconciler) syncPersesGlobalSecret(ctx context.Context, perses persesv1alpha2.Perses, persesClient v1.ClientInterface, datasource *persesv1alpha2.PersesGlobalDatasource) (*ctrl.Result, persescommon.ConditionStatusReason, error) {
 	datasourceName := datasource.Name
 	secretName := datasourceName + persescommon.SecretNameSuffix
-	basicAuth := datasource.Spec.Client.BasicAuth
-	oauth := datasource.Spec.Client.OAuth
-	tls := datasource.Spec.Client.TLS
+
+	secretSpec, reason, err := persescommon.SecretSpecFromClient(ctx, r.APIReader, "", datasourceName, datasource.Spec.Client)
+	if err != nil {
+		return subreconciler.RequeueWithErrorAndReason(err, reason)
+	}
+
+	existing, err := persesClient.GlobalSecret().Get(secretName)
+	notFound := err != nil && errors.Is(err, perseshttp.RequestNotFoundError)
+	if err != nil && !notFound {
+		return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonBackendError)
+	}
+
+	var existingTags set.Set[string]
+	if !notFound {
+		existingTags = existing.Metadata.Tags
+	}
+	managed, err := persescommon.ClaimOwnership(datasource.Spec.ConflictPolicy, "global secret", secretName, !notFound, existingTags)
+	if err != nil {
+		gdlog.WithError(err).Errorf("GlobalSecret conflict: %s", secretName)
+		return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonConflict)
+	}
+
+	var tags set.Set[string]
+	if managed {
+		tags = persescommon.WithManagedTag(nil)
+	}
 
 	secretWithName := &persesv1.GlobalSecret{
 		Kind: persesv1.KindGlobalSecret,
 		Metadata: persesv1.Metadata{
 			Name: secretName,
+			Tags: tags,
 		},
-		Spec: persesv1.SecretSpec{},
+		Spec: secretSpec,
 	}
 
-	if basicAuth != nil {
-		basicAuthConfig := &secret.BasicAuth{}
-		basicAuthConfig.Username = basicAuth.Username
-
-		switch basicAuth.Type {
-		case persesv1alpha2.SecretSourceTypeSecret, persesv1alpha2.SecretSourceTypeConfigMap:
-			namespace := ""
-			if basicAuth.Namespace != nil {
-				namespace = *basicAuth.Namespace
-			}
-			passwordData, err := persescommon.GetBasicAuthData(ctx, r.APIReader, namespace, datasourceName, basicAuth)
-
-			if err != nil {
-				gdlog.WithFields(logger.Fields{
-					"globaldatasource": datasourceName,
-					"namespace":        basicAuth.Namespace,
-					"secretName":       basicAuth.Name,
-				}).WithError(err).Error("Failed to get user basic auth password data for globaldatasource")
-				return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonInvalidConfiguration)
-			}
-
-			basicAuthConfig.Password = passwordData
-		case persesv1alpha2.SecretSourceTypeFile:
-			basicAuthConfig.PasswordFile = basicAuth.PasswordPath
-		}
+	secretKey := persescommon.SecretContentKey(perses, "", secretName)
 
-		secretWithName.Spec.BasicAuth = basicAuthConfig
-	}
-
-	if oauth != nil {
-		oAuthConfig := &secret.OAuth{
-			TokenURL:       oauth.TokenURL,
-			Scopes:         oauth.Scopes,
-			EndpointParams: oauth.EndpointParams,
-		}
+	if notFound {
+		_, err = persesClient.GlobalSecret().Create(secretWithName)
 
-		if oauth.AuthStyle != nil && *oauth.AuthStyle != 0 {
-			oAuthConfig.AuthStyle = int(*oauth.AuthStyle)
+		if err != nil {
+			gdlog.WithError(err).Errorf("Failed to create globalsecret: %s", secretName)
+			return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonBackendError)
 		}
 
-		oAuthConfig.TokenURL = oauth.TokenURL
-		switch oauth.Type {
-		case persesv1alpha2.SecretSourceTypeSecret, persesv1alpha2.SecretSourceTypeConfigMap:
-			namespace := ""
-			if oauth.Namespace != nil {
-				namespace = *oauth.Namespace
-			}
-			clientIDData, clientSecretData, err := persescommon.GetOAuthData(ctx, r.APIReader, namespace, datasourceName, oauth)
-			if err != nil {
-				gdlog.WithFields(logger.Fields{
-					"globaldatasource": datasourceName,
-					"namespace":        oauth.Namespace,
-					"secretName":       oauth.Name,
-				}).WithError(err).Error("Failed to get user oauth data for globaldatasource")
-				return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonInvalidConfiguration)
-			}
+		r.secrets.Record(secretKey, secretSpec)
+		gdlog.Infof("GlobalSecret created: %s", secretName)
 
-			oAuthConfig.ClientID = clientIDData
-			oAuthConfig.ClientSecret = clientSecretData
-		case persesv1alpha2.SecretSourceTypeFile:
-			// the clientID is a Hidden field in perses API,
-			// but doesn't expose it as a file field for it, so we need to read it and use the value
-			if oauth.ClientIDPath == nil {
-				return subreconciler.RequeueWithErrorAndReason(
-					fmt.Errorf("clientIDPath is required when OAuth type is File for globaldatasource %s", datasourceName),
-					persescommon.ReasonInvalidConfiguration,
-				)
-			}
-			clientID, err := os.ReadFile(*oauth.ClientIDPath)
-			if err != nil {
-				err = fmt.Errorf("failed to read the OAuth client ID file %s: %w", *oauth.ClientIDPath, err)
-				return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonInvalidConfiguration)
-			}
-			oAuthConfig.ClientID = string(clientID)
-			if oauth.ClientSecretPath != nil {
-				oAuthConfig.ClientSecretFile = *oauth.ClientSecretPath
-			}
-		}
-
-		secretWithName.Spec.OAuth = oAuthConfig
+		res, err := subreconciler.ContinueReconciling()
+		return res, "", err
 	}
 
-	if tls != nil {
-		insecureSkipVerify := false
-		if tls.InsecureSkipVerify != nil {
-			insecureSkipVerify = *tls.InsecureSkipVerify
-		}
-		tlsConfig := &secret.TLSConfig{
-			InsecureSkipVerify: insecureSkipVerify,
-		}
-
-		if tls.CaCert != nil {
-			switch tls.CaCert.Type {
-			case persesv1alpha2.SecretSourceTypeSecret, persesv1alpha2.SecretSourceTypeConfigMap:
-				caCertNamespace := ""
-				if tls.CaCert.Namespace != nil {
-					caCertNamespace = *tls.CaCert.Namespace
-				}
-				caData, _, err := persescommon.GetTLSCertData(ctx, r.APIReader, caCertNamespace, datasourceName, tls.CaCert)
-
-				if err != nil {
-					gdlog.WithFields(logger.Fields{
-						"globaldatasource": datasourceName,
-						"namespace":        tls.CaCert.Namespace,
-						"secretName":       tls.CaCert.Name,
-					}).WithError(err).Error("Failed to get CA data for globaldatasource")
-					return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonInvalidConfiguration)
-				}
-
-				tlsConfig.CA = caData
-			case persesv1alpha2.SecretSourceTypeFile:
-				tlsConfig.CAFile = tls.CaCert.CertPath
-			}
-		}
-
-		if tls.UserCert != nil {
-			switch tls.UserCert.Type {
-			case persesv1alpha2.SecretSourceTypeSecret, persesv1alpha2.SecretSourceTypeConfigMap:
-				userCertNamespace := ""
-				if tls.UserCert.Namespace != nil {
-					userCertNamespace = *tls.UserCert.Namespace
-				}
-				certData, keyData, err := persescommon.GetTLSCertData(ctx, r.APIReader, userCertNamespace, datasourceName, tls.UserCert)
-
-				if err != nil {
-					gdlog.WithFields(logger.Fields{
-						"globaldatasource": datasourceName,
-						"namespace":        tls.UserCert.Namespace,
-						"secretName":       tls.UserCert.Name,
-					}).WithError(err).Error("Failed to get user certificate data for globaldatasource")
-					return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonInvalidConfiguration)
-				}
-
-				tlsConfig.Cert = certData
-				tlsConfig.Key = keyData
-			case persesv1alpha2.SecretSourceTypeFile:
-				tlsConfig.CertFile = tls.UserCert.CertPath
-
-				if tls.UserCert.PrivateKeyPath != nil && len(*tls.UserCert.PrivateKeyPath) > 0 {
-					tlsConfig.KeyFile = *tls.UserCert.PrivateKeyPath
-				}
-			}
-		}
-
-		secretWithName.Spec.TLSConfig = tlsConfig
+	// A secret adopted by the operator is written again to carry the ManagedTag.
+	if r.secrets.InSync(secretKey, secretSpec) && persescommon.IsManaged(existingTags) == managed {
+		gdlog.Debugf("GlobalSecret already in sync: %s", secretName)
+		res, err := subreconciler.ContinueReconciling()
+		return res, "", err
 	}
 
-	_, err := persesClient.GlobalSecret().Get(secretName)
+	_, err = persesClient.GlobalSecret().Update(secretWithName)
 
 	if err != nil {
-		if errors.Is(err, perseshttp.RequestNotFoundError) {
-			_, err = persesClient.GlobalSecret().Create(secretWithName)
-
-			if err != nil {
-				gdlog.WithError(err).Errorf("Failed to create globalsecret: %s", secretName)
-				return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonBackendError)
-			}
-
-			gdlog.Infof("GlobalSecret created: %s", secretName)
-
-			res, err := subreconciler.ContinueReconciling()
-			return res, "", err
-		}
-
+		gdlog.WithError(err).Errorf("Failed to update globalsecret: %s", secretName)
 		return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonBackendError)
-	} else {
-		_, err = persesClient.GlobalSecret().Update(secretWithName)
-
-		if err != nil {
-			gdlog.WithError(err).Errorf("Failed to update globalsecret: %s", secretName)
-			return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonBackendError)
-		}
-
-		gdlog.Infof("GlobalSecret updated: %s", secretName)
 	}
 
+	r.secrets.Record(secretKey, secretSpec)
+	gdlog.Infof("GlobalSecret updated: %s", secretName)
+
 	res, err := subreconciler.ContinueReconciling()
 	return res, "", err
 }
 
-func (r *PersesGlobalDatasourceReconciler) deleteGlobalDatasourceInAllInstances(ctx context.Context, datasourceName string) (*ctrl.Result, error) {
-	persesInstances := &persesv1alpha2.PersesList{}
-	var opts []client.ListOption
-	err := r.List(ctx, persesInstances, opts...)
-	if err != nil {
-		gdlog.WithError(err).Error("Failed to get perses instances")
-		return subreconciler.RequeueWithError(err)
-	}
+// deleteGlobalDatasourceInInstances removes the global datasource from the given Perses instances it was
+// synced to. It returns the instances that no longer hold the global datasource, and a
+// description of every instance that could not confirm the removal.
+func (r *PersesGlobalDatasourceReconciler) deleteGlobalDatasourceInInstances(ctx context.Context, globaldatasource *persesv1alpha2.PersesGlobalDatasource, refs []persesv1alpha2.PersesInstanceReference) ([]persesv1alpha2.PersesInstanceReference, []string) {
+	var removed []persesv1alpha2.PersesInstanceReference
+	var blocked []string
+
+	for _, ref := range refs {
+		persesInstance := &persesv1alpha2.Perses{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, persesInstance); err != nil {
+			if apierrors.IsNotFound(err) {
+				gdlog.Infof("Perses instance %s/%s no longer exists", ref.Namespace, ref.Name)
+				removed = append(removed, ref)
+				continue
+			}
+			gdlog.WithError(err).Errorf("Failed to get perses instance %s/%s", ref.Namespace, ref.Name)
+			blocked = append(blocked, fmt.Sprintf("%s/%s (%v)", ref.Namespace, ref.Name, err))
+			continue
+		}
 
-	if len(persesInstances.Items) == 0 {
-		gdlog.Info("No Perses instances found")
-		return subreconciler.DoNotRequeue()
-	}
+		if !meta.IsStatusConditionTrue(persesInstance.Status.Conditions, persescommon.TypeAvailablePerses) {
+			gdlog.Infof("Perses instance %s/%s is not available, global datasource deletion is blocked", ref.Namespace, ref.Name)
+			blocked = append(blocked, fmt.Sprintf("%s/%s (not available)", ref.Namespace, ref.Name))
+			persescommon.RecordInstanceEvent(r.Recorder, globaldatasource, *persesInstance, corev1.EventTypeWarning, string(persescommon.ReasonDeletionBlocked), "Global datasource deletion is waiting for the instance to be available")
+			continue
+		}
 
-	for _, persesInstance := range persesInstances.Items {
-		gdlog.Infof("Deleting perses instance: %s", persesInstance.Name)
-		if r, err := r.deleteGlobalDatasource(ctx, persesInstance, datasourceName); subreconciler.ShouldHaltOrRequeue(r, err) {
-			return r, err
+		if _, err := r.deleteGlobalDatasource(ctx, *persesInstance, globaldatasource); err != nil {
+			blocked = append(blocked, fmt.Sprintf("%s/%s (%v)", ref.Namespace, ref.Name, err))
+			persescommon.RecordInstanceEvent(r.Recorder, globaldatasource, *persesInstance, corev1.EventTypeWarning, string(persescommon.ReasonDeletionBlocked), "Failed to delete global datasource: %v", err)
+			continue
 		}
+		removed = append(removed, ref)
 	}
 
-	return subreconciler.DoNotRequeue()
+	return removed, blocked
 }
 
-func (r *PersesGlobalDatasourceReconciler) deleteGlobalDatasource(ctx context.Context, perses persesv1alpha2.Perses, datasourceName string) (*ctrl.Result, error) {
+func (r *PersesGlobalDatasourceReconciler) deleteGlobalDatasource(ctx context.Context, perses persesv1alpha2.Perses, globaldatasource *persesv1alpha2.PersesGlobalDatasource) (*ctrl.Result, error) {
+	datasourceName := globaldatasource.Name
 	persesClient, err := r.ClientFactory.CreateClient(ctx, r.APIReader, perses)
 
 	if err != nil {
@@ -395,33 +517,68 @@ func (r *PersesGlobalDatasourceReconciler) deleteGlobalDatasource(ctx context.Co
 	// Ignore NotFound — the resource may have already been deleted from Perses directly.
 	// Any other error means the delete failed and should be retried.
 	// Secret delete is attempted regardless of whether the datasource was found or not.
-	err = persesClient.GlobalDatasource().Delete(datasourceName)
-
-	if err != nil {
-		if errors.Is(err, perseshttp.RequestNotFoundError) {
-			gdlog.Infof("GlobalDatasource not found: %s", datasourceName)
-		} else {
+	existing, err := persesClient.GlobalDatasource().Get(datasourceName)
+	dryRun := persescommon.IsDryRun(globaldatasource, r.DryRun)
+
+	switch {
+	case err != nil && errors.Is(err, perseshttp.RequestNotFoundError):
+		gdlog.Infof("GlobalDatasource not found: %s", datasourceName)
+	case err != nil:
+		gdlog.WithError(err).Errorf("Failed to get global datasource: %s", datasourceName)
+		return subreconciler.RequeueWithError(err)
+	case !persescommon.IsManaged(existing.Metadata.Tags):
+		// A global datasource the operator does not own was either left untouched or overwritten, it is not removed.
+		gdlog.Infof("GlobalDatasource not managed by the operator, keeping it: %s", datasourceName)
+	case dryRun:
+		gdlog.Infof("Dry run, global datasource %s would be deleted from Perses instance %s/%s", datasourceName, perses.Namespace, perses.Name)
+		persescommon.RecordInstanceEvent(r.Recorder, globaldatasource, perses, corev1.EventTypeNormal, string(persescommon.ReasonDryRun), "Dry run: global datasource would be deleted")
+	default:
+		err = persesClient.GlobalDatasource().Delete(datasourceName)
+		if err != nil && !errors.Is(err, perseshttp.RequestNotFoundError) {
 			gdlog.WithError(err).Errorf("Failed to delete global datasource: %s", datasourceName)
 			return subreconciler.RequeueWithError(err)
 		}
-	} else {
 		gdlog.Infof("GlobalDatasource deleted: %s", datasourceName)
+		persescommon.RecordInstanceEvent(r.Recorder, globaldatasource, perses, corev1.EventTypeNormal, persescommon.EventReasonDeleted, "Global datasource deleted")
 	}
 
 	secretName := datasourceName + persescommon.SecretNameSuffix
 
-	err = persesClient.GlobalSecret().Delete(secretName)
+	// Never delete a shared secret managed by a PersesGlobalSecret that happens to use the generated name.
+	err = r.APIReader.Get(ctx, types.NamespacedName{Name: secretName}, &persesv1alpha2.PersesGlobalSecret{})
+	if err == nil {
+		gdlog.Infof("GlobalSecret %s is managed by a PersesGlobalSecret, skipping deletion", secretName)
+		return subreconciler.ContinueReconciling()
+	}
+	if !apierrors.IsNotFound(err) {
+		gdlog.WithError(err).Errorf("Failed to get PersesGlobalSecret: %s", secretName)
+		return subreconciler.RequeueWithError(err)
+	}
 
-	if err != nil {
-		if errors.Is(err, perseshttp.RequestNotFoundError) {
-			gdlog.Infof("GlobalSecret not found: %s", secretName)
-		} else {
+	existingSecret, err := persesClient.GlobalSecret().Get(secretName)
+
+	switch {
+	case err != nil && errors.Is(err, perseshttp.RequestNotFoundError):
+		gdlog.Infof("GlobalSecret not found: %s", secretName)
+	case err != nil:
+		gdlog.WithError(err).Errorf("Failed to get global secret: %s", secretName)
+		return subreconciler.RequeueWithError(err)
+	case !persescommon.IsManaged(existingSecret.Metadata.Tags):
+		gdlog.Infof("GlobalSecret not managed by the operator, keeping it: %s", secretName)
+	case dryRun:
+		gdlog.Infof("Dry run, global secret %s would be deleted from Perses instance %s/%s", secretName, perses.Namespace, perses.Name)
+		persescommon.RecordInstanceEvent(r.Recorder, globaldatasource, perses, corev1.EventTypeNormal, string(persescommon.ReasonDryRun), "Dry run: global secret %s would be deleted", secretName)
+		return subreconciler.ContinueReconciling()
+	default:
+		err = persesClient.GlobalSecret().Delete(secretName)
+		if err != nil && !errors.Is(err, perseshttp.RequestNotFoundError) {
 			gdlog.WithError(err).Errorf("Failed to delete global secret: %s", secretName)
 			return subreconciler.RequeueWithError(err)
 		}
-	} else {
 		gdlog.Infof("GlobalSecret deleted: %s", secretName)
 	}
 
+	r.secrets.Forget(persescommon.SecretContentKey(perses, "", secretName))
+
 	return subreconciler.ContinueReconciling()
 }
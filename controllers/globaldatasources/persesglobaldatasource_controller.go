@@ -16,22 +16,30 @@ package globaldatasources
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	logger "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	internalcache "github.com/perses/perses-operator/internal/cache"
 	operatormetrics "github.com/perses/perses-operator/internal/metrics"
 	"github.com/perses/perses-operator/internal/perses/common"
 	"github.com/perses/perses-operator/internal/subreconciler"
@@ -59,6 +67,25 @@ type PersesGlobalDatasourceReconciler struct {
 	ClientFactory         common.PersesClientFactory
 	Metrics               *operatormetrics.Metrics
 	ReconciliationTracker *operatormetrics.ReconciliationTracker
+	// InstanceSyncConcurrency is the maximum number of Perses instances the global datasource
+	// is synced to at the same time. Lower than 1 syncs one instance at a time.
+	InstanceSyncConcurrency int
+	// ResyncPeriod is the period after which a global datasource is synced again to correct drift,
+	// unless overridden by its resyncPeriodSeconds. 0 disables it.
+	ResyncPeriod time.Duration
+	// VerificationPeriod is the period during which a global datasource whose spec and tags were
+	// already applied to a Perses instance is not compared with it again. 0 disables it.
+	VerificationPeriod time.Duration
+	// DryRun only plans the changes to the global datasources in Perses, without applying them,
+	// as for the global datasources with the perses.dev/dry-run annotation.
+	DryRun bool
+	// instanceSelectors lets a change of a Perses instance only enqueue the global datasources it concerns.
+	instanceSelectors *common.InstanceSelectorIndex
+	// ConfigMapCache holds the metadata of the ConfigMaps labeled for watching.
+	// When nil, changes to referenced ConfigMaps do not trigger reconciliation.
+	ConfigMapCache cache.Cache
+
+	secrets common.SecretContentTracker
 }
 
 var log = logger.WithField("module", "perses_globaldatasource_controller")
@@ -66,7 +93,9 @@ var log = logger.WithField("module", "perses_globaldatasource_controller")
 // +kubebuilder:rbac:groups=perses.dev,resources=persesglobaldatasources,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=perses.dev,resources=persesglobaldatasources/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=perses.dev,resources=persesglobaldatasources/finalizers,verbs=update
-// +kubebuilder:rbac:groups="",resources=configmaps;secrets,verbs=watch;get
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=configmaps;secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=perses.dev,resources=persesglobalsecrets,verbs=get;list;watch
 func (r *PersesGlobalDatasourceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	start := time.Now()
 	objKey := req.String()
@@ -80,14 +109,16 @@ func (r *PersesGlobalDatasourceReconciler) Reconcile(ctx context.Context, req ct
 	globaldatasource := &persesv1alpha2.PersesGlobalDatasource{}
 	if err := r.APIReader.Get(ctx, req.NamespacedName, globaldatasource); err != nil {
 		if apierrors.IsNotFound(err) {
-			log.Infof("perses globaldatasource resource not found. Deleting '%s'", req.Name)
+			log.Infof("perses globaldatasource resource not found. Ignoring '%s'", req.Name)
 			if r.ReconciliationTracker != nil {
 				r.ReconciliationTracker.ForgetObject(objKey)
 			}
 			if r.Metrics != nil {
 				r.Metrics.ForgetObject(objKey)
 			}
-			return subreconciler.Evaluate(r.deleteGlobalDatasourceInAllInstances(ctx, req.Name))
+			r.instanceSelectors.Forget(req.NamespacedName)
+			// Deletion is handled through the finalizer, so a missing object has nothing left to clean up.
+			return subreconciler.Evaluate(subreconciler.DoNotRequeue())
 		}
 		log.WithError(err).Error("Failed to get perses globaldatasource")
 		if r.Metrics != nil {
@@ -100,6 +131,9 @@ func (r *PersesGlobalDatasourceReconciler) Reconcile(ctx context.Context, req ct
 	ctx = withGlobalDatasource(ctx, globaldatasource)
 
 	subreconcilersForPerses := []subreconciler.FnWithRequest{
+		r.handleSuspend,
+		r.handleDelete,
+		r.addFinalizer,
 		r.setStatusToUnknown,
 		r.reconcileGlobalDatasourcesInAllInstances,
 		r.setStatusToComplete,
@@ -123,6 +157,11 @@ func (r *PersesGlobalDatasourceReconciler) Reconcile(ctx context.Context, req ct
 
 	// Track metrics
 	if r.Metrics != nil {
+		suspended := 0
+		if common.IsReconcilePaused(globaldatasource) {
+			suspended = 1
+		}
+		r.Metrics.SetSuspendedResources(objKey, "globaldatasource", "", suspended)
 		if reconcileErr != nil {
 			reason := string(common.ExtractReason(reconcileErr, "reconciliation_failed"))
 			r.Metrics.ReconcileErrors("persesglobaldatasource", reason).Inc()
@@ -137,9 +176,129 @@ func (r *PersesGlobalDatasourceReconciler) Reconcile(ctx context.Context, req ct
 	}
 
 	log.WithField("duration", time.Since(start)).Debug("globaldatasource reconciliation completed")
+	if resyncPeriod := common.ResyncPeriod(globaldatasource.Spec.ResyncPeriodSeconds, r.ResyncPeriod); resyncPeriod > 0 && globaldatasource.GetDeletionTimestamp() == nil && !common.IsReconcilePaused(globaldatasource) {
+		return subreconciler.Evaluate(subreconciler.RequeueWithDelay(resyncPeriod))
+	}
 	return subreconciler.Evaluate(subreconciler.DoNotRequeue())
 }
 
+// handleDelete removes the global datasource from every Perses instance it was synced to,
+// then releases the finalizer. While an instance cannot confirm the removal, the
+// finalizer is kept and the blocking instances are reported in the status conditions.
+func (r *PersesGlobalDatasourceReconciler) handleDelete(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	globaldatasource, ok := globalDatasourceFromContext(ctx)
+	if !ok {
+		log.Error("globaldatasource not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("globaldatasource not found in context"))
+	}
+
+	if globaldatasource.GetDeletionTimestamp() == nil {
+		return subreconciler.ContinueReconciling()
+	}
+
+	if !controllerutil.ContainsFinalizer(globaldatasource, common.PersesFinalizer) {
+		return subreconciler.DoNotRequeue()
+	}
+
+	removed, blocked := r.deleteGlobalDatasourceInInstances(ctx, globaldatasource, globaldatasource.Status.SyncedInstances)
+	if len(removed) > 0 {
+		if res, err := r.updateGlobalDatasourceStatus(ctx, req, func(globaldatasource *persesv1alpha2.PersesGlobalDatasource) {
+			globaldatasource.Status.SyncedInstances = common.RemoveInstanceReferences(globaldatasource.Status.SyncedInstances, removed)
+		}); subreconciler.ShouldHaltOrRequeue(res, err) {
+			return res, err
+		}
+	}
+
+	if len(blocked) > 0 {
+		return r.setStatusToDegraded(ctx, req, &ctrl.Result{RequeueAfter: time.Minute}, common.ReasonDeletionBlocked,
+			fmt.Errorf("global datasource deletion is waiting for Perses instances: %s", strings.Join(blocked, "; ")))
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesGlobalDatasource{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		if !controllerutil.RemoveFinalizer(fresh, common.PersesFinalizer) {
+			return nil
+		}
+		return r.Update(ctx, fresh)
+	})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to remove finalizer from perses globaldatasource")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	r.instanceSelectors.Forget(req.NamespacedName)
+	log.Infof("PersesGlobalDatasource %s deleted", globaldatasource.Name)
+	return subreconciler.DoNotRequeue()
+}
+
+// addFinalizer makes sure the global datasource is not removed from the cluster before
+// it has been removed from the Perses instances it was synced to.
+func (r *PersesGlobalDatasourceReconciler) addFinalizer(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	globaldatasource, ok := globalDatasourceFromContext(ctx)
+	if !ok {
+		log.Error("globaldatasource not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("globaldatasource not found in context"))
+	}
+
+	if controllerutil.ContainsFinalizer(globaldatasource, common.PersesFinalizer) {
+		return subreconciler.ContinueReconciling()
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesGlobalDatasource{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		if !controllerutil.AddFinalizer(fresh, common.PersesFinalizer) {
+			return nil
+		}
+		return r.Update(ctx, fresh)
+	})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to add finalizer to perses globaldatasource")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+// recordSyncedInstances adds the Perses instances to the instances the global datasource
+// is synced to, so that the global datasource is removed from them on deletion. It is called
+// before the global datasource is written to the instances, so that a failure in between
+// cannot leave anything behind in Perses.
+func (r *PersesGlobalDatasourceReconciler) recordSyncedInstances(ctx context.Context, req ctrl.Request, persesInstances []persesv1alpha2.Perses) (*ctrl.Result, error) {
+	globaldatasource, ok := globalDatasourceFromContext(ctx)
+	if !ok {
+		log.Error("globaldatasource not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("globaldatasource not found in context"))
+	}
+
+	var refs []persesv1alpha2.PersesInstanceReference
+	for _, perses := range persesInstances {
+		if ref := common.InstanceReference(perses); !common.HasInstanceReference(globaldatasource.Status.SyncedInstances, ref) {
+			refs = append(refs, ref)
+		}
+	}
+	if len(refs) == 0 {
+		return subreconciler.ContinueReconciling()
+	}
+
+	return r.updateGlobalDatasourceStatus(ctx, req, func(globaldatasource *persesv1alpha2.PersesGlobalDatasource) {
+		for _, ref := range refs {
+			globaldatasource.Status.SyncedInstances = common.AddInstanceReference(globaldatasource.Status.SyncedInstances, ref)
+		}
+	})
+}
+
 func (r *PersesGlobalDatasourceReconciler) updateGlobalDatasourceStatus(
 	ctx context.Context,
 	req ctrl.Request,
@@ -157,8 +316,11 @@ func (r *PersesGlobalDatasourceReconciler) updateGlobalDatasourceStatus(
 			return err
 		}
 		before := common.SnapshotConditions(fresh.Status.Conditions)
+		beforeInstances := slices.Clone(fresh.Status.SyncedInstances)
+		beforeInstanceStatuses := slices.Clone(fresh.Status.Instances)
 		updateFn(fresh)
-		if !common.ConditionsChanged(before, fresh.Status.Conditions) {
+		if !common.ConditionsChanged(before, fresh.Status.Conditions) && slices.Equal(beforeInstances, fresh.Status.SyncedInstances) &&
+			!common.InstanceStatusesChanged(beforeInstanceStatuses, fresh.Status.Instances) {
 			return nil
 		}
 		return r.Status().Update(ctx, fresh)
@@ -176,6 +338,37 @@ func (r *PersesGlobalDatasourceReconciler) updateGlobalDatasourceStatus(
 	return subreconciler.ContinueReconciling()
 }
 
+// handleSuspend stops the reconciliation of a global datasource paused with the perses.dev/reconcile
+// annotation, leaving it untouched in Perses, and clears the Suspended condition once it is resumed.
+// It runs before handleDelete, but a global datasource being deleted is never paused, so its
+// finalizer still removes it from Perses. The status is only written when the Suspended condition changes.
+func (r *PersesGlobalDatasourceReconciler) handleSuspend(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	globaldatasource, ok := globalDatasourceFromContext(ctx)
+	if !ok {
+		log.Error("globaldatasource not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("globaldatasource not found in context"))
+	}
+
+	paused := common.IsReconcilePaused(globaldatasource)
+	if common.SuspendedStatusChanged(globaldatasource.Status.Conditions, paused) {
+		if res, err := r.updateGlobalDatasourceStatus(ctx, req, func(globaldatasource *persesv1alpha2.PersesGlobalDatasource) {
+			if paused {
+				meta.SetStatusCondition(&globaldatasource.Status.Conditions, common.SuspendedCondition("GlobalDatasource", globaldatasource.Name))
+			} else {
+				meta.RemoveStatusCondition(&globaldatasource.Status.Conditions, common.TypeSuspendedPerses)
+			}
+		}); subreconciler.ShouldHaltOrRequeue(res, err) {
+			return res, err
+		}
+	}
+
+	if !paused {
+		return subreconciler.ContinueReconciling()
+	}
+	log.Infof("PersesGlobalDatasource %s is paused, skipping reconciliation", req.String())
+	return subreconciler.DoNotRequeue()
+}
+
 func (r *PersesGlobalDatasourceReconciler) setStatusToUnknown(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
 	return r.updateGlobalDatasourceStatus(ctx, req, func(globaldatasource *persesv1alpha2.PersesGlobalDatasource) {
 		if len(globaldatasource.Status.Conditions) == 0 {
@@ -187,12 +380,24 @@ func (r *PersesGlobalDatasourceReconciler) setStatusToUnknown(ctx context.Contex
 }
 
 func (r *PersesGlobalDatasourceReconciler) setStatusToComplete(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	// The observed generation is the one that was synced, the custom resource may have changed since.
+	synced, ok := globalDatasourceFromContext(ctx)
+	if !ok {
+		log.Error("globaldatasource not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("globaldatasource not found in context"))
+	}
+
+	// Nothing was applied in a dry run, so the availability of the global datasource is left as is.
+	if common.IsDryRun(synced, r.DryRun) {
+		return subreconciler.ContinueReconciling()
+	}
+
 	return r.updateGlobalDatasourceStatus(ctx, req, func(globaldatasource *persesv1alpha2.PersesGlobalDatasource) {
 		meta.SetStatusCondition(&globaldatasource.Status.Conditions, metav1.Condition{
-			Type: common.TypeDegradedPerses, Status: metav1.ConditionFalse,
+			Type: common.TypeDegradedPerses, Status: metav1.ConditionFalse, ObservedGeneration: synced.Generation,
 			Reason: "Reconciled", Message: fmt.Sprintf("GlobalDatasource (%s) reconciled successfully", globaldatasource.Name)})
 		meta.SetStatusCondition(&globaldatasource.Status.Conditions, metav1.Condition{
-			Type: common.TypeAvailablePerses, Status: metav1.ConditionTrue,
+			Type: common.TypeAvailablePerses, Status: metav1.ConditionTrue, ObservedGeneration: synced.Generation,
 			Reason: "Reconciled", Message: fmt.Sprintf("GlobalDatasource (%s) created successfully", globaldatasource.Name)})
 	})
 }
@@ -228,27 +433,123 @@ func (r *PersesGlobalDatasourceReconciler) setStatusToDegraded(
 	return degradedResult, nil
 }
 
-// findGlobalDatasourcesForPerses returns reconcile requests for all PersesGlobalDatasources
-// when a Perses instance becomes available.
-// Global datasources are cluster-scoped and their instanceSelector labels determine
-// which Perses instances they sync to. If no instanceSelector is set, they sync to all instances.
-func (r *PersesGlobalDatasourceReconciler) findGlobalDatasourcesForPerses(ctx context.Context, _ client.Object) []reconcile.Request {
-	return common.MetadataListToRequests(ctx, r.Client, persesv1alpha2.GroupVersion.WithKind("PersesGlobalDatasourceList"))
+// findGlobalDatasourcesForSecret returns reconcile requests for the PersesGlobalDatasources whose client
+// configuration reads credentials or certificates from the changed Secret.
+func (r *PersesGlobalDatasourceReconciler) findGlobalDatasourcesForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	return r.findGlobalDatasourcesReferencing(ctx, persesv1alpha2.SecretSourceTypeSecret, obj)
+}
+
+// findGlobalDatasourcesForConfigMap returns reconcile requests for the PersesGlobalDatasources whose client
+// configuration reads credentials or certificates from the changed ConfigMap.
+func (r *PersesGlobalDatasourceReconciler) findGlobalDatasourcesForConfigMap(ctx context.Context, obj *metav1.PartialObjectMetadata) []reconcile.Request {
+	return r.findGlobalDatasourcesReferencing(ctx, persesv1alpha2.SecretSourceTypeConfigMap, obj)
+}
+
+// findGlobalDatasourcesReferencing returns reconcile requests for the PersesGlobalDatasources whose client
+// configuration references the changed Secret or ConfigMap, and for those whose secretRef names
+// a PersesGlobalSecret that references it.
+func (r *PersesGlobalDatasourceReconciler) findGlobalDatasourcesReferencing(ctx context.Context, sourceType persesv1alpha2.SecretSourceType, obj client.Object) []reconcile.Request {
+	// The cache only holds the metadata of the global datasources, the client configuration is read via APIReader.
+	dsList := &persesv1alpha2.PersesGlobalDatasourceList{}
+	if err := r.APIReader.List(ctx, dsList); err != nil {
+		log.WithError(err).Errorf("failed to list PersesGlobalDatasources for %s %s/%s", sourceType, obj.GetNamespace(), obj.GetName())
+		return nil
+	}
+
+	secretList := &persesv1alpha2.PersesGlobalSecretList{}
+	if err := r.APIReader.List(ctx, secretList); err != nil {
+		log.WithError(err).Errorf("failed to list PersesGlobalSecrets for %s %s/%s", sourceType, obj.GetNamespace(), obj.GetName())
+		return nil
+	}
+	referencingSecrets := map[string]bool{}
+	for _, secret := range secretList.Items {
+		if common.ClientReferences(&secret.Spec.Client, "", sourceType, obj) {
+			referencingSecrets[secret.Name] = true
+		}
+	}
+
+	var requests []reconcile.Request
+	for _, ds := range dsList.Items {
+		if common.ClientReferences(ds.Spec.Client, "", sourceType, obj) ||
+			ds.Spec.SecretRef != nil && referencingSecrets[ds.Spec.SecretRef.Name] {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      ds.Name,
+					Namespace: ds.Namespace,
+				},
+			})
+		}
+	}
+
+	return requests
+}
+
+// findGlobalDatasourcesForPersesGlobalSecret returns reconcile requests for the PersesGlobalDatasources
+// that reference the changed PersesGlobalSecret through their secretRef, so that a global datasource
+// waiting for its secret is synced once the secret reaches Perses.
+func (r *PersesGlobalDatasourceReconciler) findGlobalDatasourcesForPersesGlobalSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	dsList := &persesv1alpha2.PersesGlobalDatasourceList{}
+	if err := r.APIReader.List(ctx, dsList); err != nil {
+		log.WithError(err).Errorf("failed to list PersesGlobalDatasources for PersesGlobalSecret %s", obj.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, ds := range dsList.Items {
+		if ds.Spec.SecretRef != nil && ds.Spec.SecretRef.Name == obj.GetName() {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      ds.Name,
+					Namespace: ds.Namespace,
+				},
+			})
+		}
+	}
+
+	return requests
 }
 
 // SetupWithManager sets up the controller with the Manager.
 // It watches PersesGlobalDatasource resources and also watches Perses instances
-// to trigger re-reconciliation of all global datasources when a Perses instance becomes
-// available. Global datasources are matched to Perses instances via instanceSelector labels.
+// to reconcile the global datasources a Perses instance concerns when it becomes available or changes
+// its resource selectors or its labels: the global datasources whose instanceSelector matches its
+// labels, before or after the change, and the global datasources synced to it, so that a relabeled
+// instance gets the global datasources it is now selected by and loses the others.
 // Create and delete events for Perses instances are ignored because the instance is not yet
-// ready at creation, and deletion is handled by the global datasource's own reconciliation loop.
+// ready at creation, and deletion is handled by the global datasource's own reconciliation loop
+// through its finalizer.
+// It also watches the Secrets and, when ConfigMapCache is set, the ConfigMaps referenced by the
+// client configuration, so that rotated credentials are pushed to Perses. Only the Secrets and
+// ConfigMaps matching the watch label selector of their cache trigger reconciliation.
+// The global datasources using a shared secret through their secretRef are reconciled as well when
+// their PersesGlobalSecret, or a Secret or ConfigMap it references, changes.
 func (r *PersesGlobalDatasourceReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	r.instanceSelectors = common.NewInstanceSelectorIndex()
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&persesv1alpha2.PersesGlobalDatasource{}, builder.OnlyMetadata).
 		Watches(
 			&persesv1alpha2.Perses{},
-			handler.EnqueueRequestsFromMapFunc(r.findGlobalDatasourcesForPerses),
-			builder.WithPredicates(common.PersesAvailabilityPredicate()),
+			common.EnqueueConcernedResources(r.Client, persesv1alpha2.GroupVersion.WithKind("PersesGlobalDatasourceList"), r.instanceSelectors),
+			builder.WithPredicates(common.PersesAdmissionPredicate()),
 		).
-		Complete(r)
+		// WatchesMetadata only caches metadata (not Data) to reduce memory.
+		// The referenced data is read via APIReader when the datasource is reconciled.
+		WatchesMetadata(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.findGlobalDatasourcesForSecret),
+		).
+		WatchesMetadata(
+			&persesv1alpha2.PersesGlobalSecret{},
+			handler.EnqueueRequestsFromMapFunc(r.findGlobalDatasourcesForPersesGlobalSecret),
+		)
+
+	if r.ConfigMapCache != nil {
+		b = b.WatchesRawSource(source.Kind(
+			r.ConfigMapCache,
+			internalcache.ConfigMapMetadata(),
+			handler.TypedEnqueueRequestsFromMapFunc(r.findGlobalDatasourcesForConfigMap),
+		))
+	}
+
+	return b.Complete(r)
 }
@@ -21,14 +21,28 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/perses/common/set"
 	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	internal "github.com/perses/perses-operator/internal/perses"
 	"github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	persesv1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/secret"
+	specdatasource "github.com/perses/spec/go/datasource"
+	specplugin "github.com/perses/spec/go/plugin"
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 func TestGlobalDatasourceController(t *testing.T) {
@@ -39,6 +53,7 @@ func TestGlobalDatasourceController(t *testing.T) {
 func newTestGlobalDatasourceReconciler(objects ...runtime.Object) *PersesGlobalDatasourceReconciler {
 	scheme := runtime.NewScheme()
 	Expect(persesv1alpha2.AddToScheme(scheme)).To(Succeed())
+	Expect(corev1.AddToScheme(scheme)).To(Succeed())
 
 	clientBuilder := fake.NewClientBuilder().WithScheme(scheme)
 	for _, obj := range objects {
@@ -125,4 +140,379 @@ var _ = Describe("GlobalDatasource controller", func() {
 			Expect(degradedCond.Reason).To(Equal(string(common.ReasonMissingPerses)))
 		})
 	})
+
+	Context("syncPersesGlobalSecret", func() {
+		const DatasourceName = "prometheus"
+		const SecretName = "prometheus-secret"
+
+		perses := persesv1alpha2.Perses{
+			ObjectMeta: metav1.ObjectMeta{Name: "perses", Namespace: "perses-dev"},
+		}
+
+		credentials := func(password string) *corev1.Secret {
+			return &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "prometheus-credentials", Namespace: "monitoring"},
+				Data:       map[string][]byte{"password": []byte(password)},
+			}
+		}
+
+		newDatasource := func() *persesv1alpha2.PersesGlobalDatasource {
+			return &persesv1alpha2.PersesGlobalDatasource{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: DatasourceName,
+				},
+				Spec: persesv1alpha2.DatasourceSpec{
+					Client: &persesv1alpha2.Client{
+						BasicAuth: &persesv1alpha2.BasicAuth{
+							SecretSource: persesv1alpha2.SecretSource{
+								Type:      persesv1alpha2.SecretSourceTypeSecret,
+								Name:      ptr.To("prometheus-credentials"),
+								Namespace: ptr.To("monitoring"),
+							},
+							Username:     "admin",
+							PasswordPath: "password",
+						},
+					},
+				},
+			}
+		}
+
+		expectedSecret := func(password string) *persesv1.GlobalSecret {
+			return &persesv1.GlobalSecret{
+				Kind:     persesv1.KindGlobalSecret,
+				Metadata: persesv1.Metadata{Name: SecretName, Tags: common.WithManagedTag(nil)},
+				Spec: persesv1.SecretSpec{
+					BasicAuth: &secret.BasicAuth{Username: "admin", Password: password},
+				},
+			}
+		}
+
+		It("should create the secret and skip the update while its content is unchanged", func() {
+			mockPersesClient := &internal.MockClient{}
+			mockSecret := &internal.MockGlobalSecret{}
+			mockPersesClient.On("GlobalSecret").Return(mockSecret)
+			mockSecret.On("Get", SecretName).Return(&persesv1.GlobalSecret{}, perseshttp.RequestNotFoundError).Once()
+			mockSecret.On("Create", expectedSecret("s3cret")).Return(expectedSecret("s3cret"), nil).Once()
+
+			r := newTestGlobalDatasourceReconciler(credentials("s3cret"))
+
+			_, _, err := r.syncPersesGlobalSecret(context.Background(), perses, mockPersesClient, newDatasource())
+			Expect(err).ToNot(HaveOccurred())
+
+			mockSecret.On("Get", SecretName).Return(expectedSecret("<secret>"), nil).Once()
+			_, _, err = r.syncPersesGlobalSecret(context.Background(), perses, mockPersesClient, newDatasource())
+			Expect(err).ToNot(HaveOccurred())
+
+			mockSecret.AssertExpectations(GinkgoT())
+			mockSecret.AssertNotCalled(GinkgoT(), "Update", expectedSecret("s3cret"))
+		})
+
+		It("should update the secret when the referenced credentials are rotated", func() {
+			mockPersesClient := &internal.MockClient{}
+			mockSecret := &internal.MockGlobalSecret{}
+			mockPersesClient.On("GlobalSecret").Return(mockSecret)
+			mockSecret.On("Get", SecretName).Return(expectedSecret("<secret>"), nil)
+			mockSecret.On("Update", expectedSecret("s3cret")).Return(expectedSecret("s3cret"), nil).Once()
+			mockSecret.On("Update", expectedSecret("r0tated")).Return(expectedSecret("r0tated"), nil).Once()
+
+			r := newTestGlobalDatasourceReconciler(credentials("s3cret"))
+
+			_, _, err := r.syncPersesGlobalSecret(context.Background(), perses, mockPersesClient, newDatasource())
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(r.Update(context.Background(), credentials("r0tated"))).To(Succeed())
+			_, _, err = r.syncPersesGlobalSecret(context.Background(), perses, mockPersesClient, newDatasource())
+			Expect(err).ToNot(HaveOccurred())
+
+			mockSecret.AssertExpectations(GinkgoT())
+		})
+
+		It("should push the secret again once it was forgotten", func() {
+			mockPersesClient := &internal.MockClient{}
+			mockSecret := &internal.MockGlobalSecret{}
+			mockPersesClient.On("GlobalSecret").Return(mockSecret)
+			mockSecret.On("Get", SecretName).Return(expectedSecret("<secret>"), nil)
+			mockSecret.On("Update", expectedSecret("s3cret")).Return(expectedSecret("s3cret"), nil).Twice()
+
+			r := newTestGlobalDatasourceReconciler(credentials("s3cret"))
+
+			_, _, err := r.syncPersesGlobalSecret(context.Background(), perses, mockPersesClient, newDatasource())
+			Expect(err).ToNot(HaveOccurred())
+
+			r.secrets.Forget(common.SecretContentKey(perses, "", SecretName))
+			_, _, err = r.syncPersesGlobalSecret(context.Background(), perses, mockPersesClient, newDatasource())
+			Expect(err).ToNot(HaveOccurred())
+
+			mockSecret.AssertExpectations(GinkgoT())
+		})
+
+		It("should leave a secret not managed by the operator untouched with the Fail conflict policy", func() {
+			unmanaged := expectedSecret("<secret>")
+			unmanaged.Metadata.Tags = nil
+
+			mockPersesClient := &internal.MockClient{}
+			mockSecret := &internal.MockGlobalSecret{}
+			mockPersesClient.On("GlobalSecret").Return(mockSecret)
+			mockSecret.On("Get", SecretName).Return(unmanaged, nil)
+
+			r := newTestGlobalDatasourceReconciler(credentials("s3cret"))
+
+			datasource := newDatasource()
+			datasource.Spec.ConflictPolicy = persesv1alpha2.ConflictPolicyFail
+			_, reason, err := r.syncPersesGlobalSecret(context.Background(), perses, mockPersesClient, datasource)
+			Expect(err).To(HaveOccurred())
+			Expect(reason).To(Equal(common.ReasonConflict))
+			mockSecret.AssertNotCalled(GinkgoT(), "Update", mock.Anything)
+		})
+	})
+
+	Context("findGlobalDatasourcesForSecret", func() {
+		It("should only enqueue the datasources referencing the changed secret", func() {
+			referencing := &persesv1alpha2.PersesGlobalDatasource{
+				ObjectMeta: metav1.ObjectMeta{Name: "referencing"},
+				Spec: persesv1alpha2.DatasourceSpec{
+					Config: persesv1alpha2.Datasource{
+						Spec: specdatasource.Spec{
+							Plugin: specplugin.Plugin{Kind: "PrometheusDatasource", Spec: map[string]interface{}{}},
+						},
+					},
+					Client: &persesv1alpha2.Client{
+						BasicAuth: &persesv1alpha2.BasicAuth{
+							SecretSource: persesv1alpha2.SecretSource{
+								Type:      persesv1alpha2.SecretSourceTypeSecret,
+								Name:      ptr.To("prometheus-credentials"),
+								Namespace: ptr.To("monitoring"),
+							},
+						},
+					},
+				},
+			}
+			other := &persesv1alpha2.PersesGlobalDatasource{
+				ObjectMeta: metav1.ObjectMeta{Name: "other"},
+				Spec: persesv1alpha2.DatasourceSpec{
+					Config: persesv1alpha2.Datasource{
+						Spec: specdatasource.Spec{
+							Plugin: specplugin.Plugin{Kind: "PrometheusDatasource", Spec: map[string]interface{}{}},
+						},
+					},
+					Client: &persesv1alpha2.Client{
+						BasicAuth: &persesv1alpha2.BasicAuth{
+							SecretSource: persesv1alpha2.SecretSource{
+								Type:      persesv1alpha2.SecretSourceTypeConfigMap,
+								Name:      ptr.To("prometheus-credentials"),
+								Namespace: ptr.To("monitoring"),
+							},
+						},
+					},
+				},
+			}
+
+			r := newTestGlobalDatasourceReconciler(referencing, other)
+			changed := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "prometheus-credentials", Namespace: "monitoring"},
+			}
+
+			requests := r.findGlobalDatasourcesForSecret(context.Background(), changed)
+			Expect(requests).To(ConsistOf(reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "referencing"},
+			}))
+
+			changedConfigMap := &metav1.PartialObjectMetadata{
+				ObjectMeta: metav1.ObjectMeta{Name: "prometheus-credentials", Namespace: "monitoring"},
+			}
+			requests = r.findGlobalDatasourcesForConfigMap(context.Background(), changedConfigMap)
+			Expect(requests).To(ConsistOf(reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "other"},
+			}))
+		})
+
+		It("should enqueue the datasources whose shared global secret references the changed secret", func() {
+			usingSecretRef := &persesv1alpha2.PersesGlobalDatasource{
+				ObjectMeta: metav1.ObjectMeta{Name: "shared"},
+				Spec: persesv1alpha2.DatasourceSpec{
+					Config: persesv1alpha2.Datasource{
+						Spec: specdatasource.Spec{
+							Plugin: specplugin.Plugin{Kind: "PrometheusDatasource", Spec: map[string]interface{}{}},
+						},
+					},
+					SecretRef: &persesv1alpha2.SecretReference{Name: "prometheus-shared"},
+				},
+			}
+			sharedSecret := &persesv1alpha2.PersesGlobalSecret{
+				ObjectMeta: metav1.ObjectMeta{Name: "prometheus-shared"},
+				Spec: persesv1alpha2.SecretSpec{
+					Client: persesv1alpha2.Client{
+						BasicAuth: &persesv1alpha2.BasicAuth{
+							SecretSource: persesv1alpha2.SecretSource{
+								Type:      persesv1alpha2.SecretSourceTypeSecret,
+								Name:      ptr.To("prometheus-credentials"),
+								Namespace: ptr.To("monitoring"),
+							},
+						},
+					},
+				},
+			}
+
+			r := newTestGlobalDatasourceReconciler(usingSecretRef, sharedSecret)
+
+			By("Rotating the credentials read by the shared global secret")
+			changed := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "prometheus-credentials", Namespace: "monitoring"},
+			}
+			requests := r.findGlobalDatasourcesForSecret(context.Background(), changed)
+			Expect(requests).To(ConsistOf(reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "shared"},
+			}))
+
+			By("Changing the shared global secret itself")
+			requests = r.findGlobalDatasourcesForPersesGlobalSecret(context.Background(), sharedSecret)
+			Expect(requests).To(ConsistOf(reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "shared"},
+			}))
+		})
+	})
+
+	Context("handleDelete", func() {
+		const DatasourceName = "prometheus"
+
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: DatasourceName}}
+
+		newPerses := func(available bool) *persesv1alpha2.Perses {
+			status := metav1.ConditionFalse
+			if available {
+				status = metav1.ConditionTrue
+			}
+			return &persesv1alpha2.Perses{
+				ObjectMeta: metav1.ObjectMeta{Name: "perses", Namespace: "perses-dev"},
+				Status: persesv1alpha2.PersesStatus{
+					Conditions: []metav1.Condition{{
+						Type:               common.TypeAvailablePerses,
+						Status:             status,
+						Reason:             "Reconciled",
+						LastTransitionTime: metav1.Now(),
+					}},
+				},
+			}
+		}
+
+		deletingDatasource := func() *persesv1alpha2.PersesGlobalDatasource {
+			return &persesv1alpha2.PersesGlobalDatasource{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              DatasourceName,
+					Finalizers:        []string{common.PersesFinalizer},
+					DeletionTimestamp: &metav1.Time{Time: time.Now()},
+				},
+				Spec: persesv1alpha2.DatasourceSpec{
+					Config: persesv1alpha2.Datasource{
+						Spec: specdatasource.Spec{
+							Plugin: specplugin.Plugin{Kind: "PrometheusDatasource", Spec: map[string]interface{}{}},
+						},
+					},
+				},
+				Status: persesv1alpha2.PersesGlobalDatasourceStatus{
+					SyncedInstances: []persesv1alpha2.PersesInstanceReference{{Namespace: "perses-dev", Name: "perses"}},
+				},
+			}
+		}
+
+		persesSecret := func(tags set.Set[string]) *persesv1.GlobalSecret {
+			return &persesv1.GlobalSecret{Metadata: persesv1.Metadata{Name: DatasourceName + common.SecretNameSuffix, Tags: tags}}
+		}
+
+		It("should keep the finalizer while a synced instance is not available", func() {
+			datasource := deletingDatasource()
+			mockPersesClient := &internal.MockClient{}
+
+			r := newTestGlobalDatasourceReconciler(datasource, newPerses(false))
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			_, err := r.handleDelete(withGlobalDatasource(context.Background(), datasource), req)
+			Expect(common.ExtractReason(err, "")).To(Equal(common.ReasonDeletionBlocked))
+			mockPersesClient.AssertNotCalled(GinkgoT(), "GlobalDatasource")
+
+			fresh := &persesv1alpha2.PersesGlobalDatasource{}
+			Expect(r.Get(context.Background(), req.NamespacedName, fresh)).To(Succeed())
+			Expect(controllerutil.ContainsFinalizer(fresh, common.PersesFinalizer)).To(BeTrue())
+
+			degraded := apimeta.FindStatusCondition(fresh.Status.Conditions, common.TypeDegradedPerses)
+			Expect(degraded).ToNot(BeNil())
+			Expect(degraded.Reason).To(Equal(string(common.ReasonDeletionBlocked)))
+			Expect(degraded.Message).To(ContainSubstring("perses-dev/perses (not available)"))
+		})
+
+		It("should remove the global datasource and its secret before releasing the finalizer", func() {
+			datasource := deletingDatasource()
+			mockPersesClient := &internal.MockClient{}
+			mockGlobalDatasource := &internal.MockGlobalDatasource{}
+			mockGlobalSecret := &internal.MockGlobalSecret{}
+			mockPersesClient.On("GlobalDatasource").Return(mockGlobalDatasource)
+			mockPersesClient.On("GlobalSecret").Return(mockGlobalSecret)
+			mockGlobalDatasource.On("Get", DatasourceName).Return(&persesv1.GlobalDatasource{
+				Metadata: persesv1.Metadata{Name: DatasourceName, Tags: common.WithManagedTag(nil)},
+			}, nil)
+			mockGlobalDatasource.On("Delete", DatasourceName).Return(nil).Once()
+			mockGlobalSecret.On("Get", DatasourceName+common.SecretNameSuffix).Return(persesSecret(common.WithManagedTag(nil)), nil)
+			mockGlobalSecret.On("Delete", DatasourceName+common.SecretNameSuffix).Return(nil).Once()
+
+			r := newTestGlobalDatasourceReconciler(datasource, newPerses(true))
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			_, err := r.handleDelete(withGlobalDatasource(context.Background(), datasource), req)
+			Expect(err).ToNot(HaveOccurred())
+			mockGlobalDatasource.AssertExpectations(GinkgoT())
+			mockGlobalSecret.AssertExpectations(GinkgoT())
+
+			err = r.Get(context.Background(), req.NamespacedName, &persesv1alpha2.PersesGlobalDatasource{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should only plan the deletion of the secret of a missing or unmanaged global datasource in a dry run", func() {
+			for _, existing := range []struct {
+				datasource *persesv1.GlobalDatasource
+				err        error
+			}{
+				{datasource: &persesv1.GlobalDatasource{}, err: perseshttp.RequestNotFoundError},
+				{datasource: &persesv1.GlobalDatasource{Metadata: persesv1.Metadata{Name: DatasourceName}}},
+			} {
+				datasource := deletingDatasource()
+				mockPersesClient := &internal.MockClient{}
+				mockGlobalDatasource := &internal.MockGlobalDatasource{}
+				mockGlobalSecret := &internal.MockGlobalSecret{}
+				mockPersesClient.On("GlobalDatasource").Return(mockGlobalDatasource)
+				mockPersesClient.On("GlobalSecret").Return(mockGlobalSecret)
+				mockGlobalDatasource.On("Get", DatasourceName).Return(existing.datasource, existing.err)
+				mockGlobalSecret.On("Get", DatasourceName+common.SecretNameSuffix).Return(persesSecret(common.WithManagedTag(nil)), nil)
+
+				recorder := record.NewFakeRecorder(10)
+				r := newTestGlobalDatasourceReconciler(datasource, newPerses(true))
+				r.ClientFactory = common.NewWithClient(mockPersesClient)
+				r.Recorder = recorder
+				r.DryRun = true
+
+				_, err := r.deleteGlobalDatasource(context.Background(), *newPerses(true), datasource)
+				Expect(err).ToNot(HaveOccurred())
+				mockGlobalDatasource.AssertNotCalled(GinkgoT(), "Delete", DatasourceName)
+				mockGlobalSecret.AssertNotCalled(GinkgoT(), "Delete", DatasourceName+common.SecretNameSuffix)
+				Expect(recorder.Events).To(Receive(ContainSubstring("Dry run: global secret prometheus-secret would be deleted")))
+			}
+		})
+
+		It("should keep a secret not managed by the operator in Perses", func() {
+			datasource := deletingDatasource()
+			mockPersesClient := &internal.MockClient{}
+			mockGlobalDatasource := &internal.MockGlobalDatasource{}
+			mockGlobalSecret := &internal.MockGlobalSecret{}
+			mockPersesClient.On("GlobalDatasource").Return(mockGlobalDatasource)
+			mockPersesClient.On("GlobalSecret").Return(mockGlobalSecret)
+			mockGlobalDatasource.On("Get", DatasourceName).Return(&persesv1.GlobalDatasource{}, perseshttp.RequestNotFoundError)
+			mockGlobalSecret.On("Get", DatasourceName+common.SecretNameSuffix).Return(persesSecret(nil), nil)
+
+			r := newTestGlobalDatasourceReconciler(datasource, newPerses(true))
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			_, err := r.handleDelete(withGlobalDatasource(context.Background(), datasource), req)
+			Expect(err).ToNot(HaveOccurred())
+			mockGlobalSecret.AssertNotCalled(GinkgoT(), "Delete", DatasourceName+common.SecretNameSuffix)
+		})
+	})
 })
@@ -0,0 +1,301 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package globalrolebindings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/perses/common/set"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	persesv1 "github.com/perses/perses/pkg/model/api/v1"
+	logger "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	persescommon "github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses-operator/internal/subreconciler"
+)
+
+var grblog = logger.WithField("module", "globalrolebinding_controller")
+
+func (r *PersesGlobalRoleBindingReconciler) reconcileGlobalRoleBindingsInAllInstances(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	globalrolebinding, ok := globalRoleBindingFromContext(ctx)
+	if !ok {
+		grblog.Error("global role binding not found in context")
+		res, err := subreconciler.RequeueWithError(fmt.Errorf("global role binding not found in context"))
+		return r.setStatusToDegraded(ctx, req, res, persescommon.ReasonMissingResource, err)
+	}
+
+	persesInstances, err := r.listSelectedInstances(ctx, globalrolebinding)
+	if err != nil {
+		grblog.WithError(err).Error("Failed to get perses instances")
+		res, err := subreconciler.RequeueWithError(err)
+		return r.setStatusToDegraded(ctx, req, res, persescommon.ReasonMissingPerses, err)
+	}
+
+	if len(persesInstances.Items) == 0 {
+		grblog.Info("No Perses instances found, requeue in 1 minute")
+		return r.setStatusToDegraded(ctx, req, &ctrl.Result{RequeueAfter: time.Minute}, persescommon.ReasonMissingPerses, fmt.Errorf("no Perses instances found matching the label selector"))
+	}
+
+	available := make([]persesv1alpha2.Perses, 0, len(persesInstances.Items))
+	for _, persesInstance := range persesInstances.Items {
+		if !meta.IsStatusConditionTrue(persesInstance.Status.Conditions, persescommon.TypeAvailablePerses) {
+			grblog.Infof("Skipping Perses instance %s/%s (not yet available)", persesInstance.Namespace, persesInstance.Name)
+			continue
+		}
+		available = append(available, persesInstance)
+	}
+
+	// Every instance is synced, so that a failing instance does not hold back the others.
+	outcomes := subreconciler.ForEach(available, r.InstanceSyncConcurrency, func(persesInstance persesv1alpha2.Perses) (*ctrl.Result, persescommon.ConditionStatusReason, error) {
+		return r.syncPersesGlobalRoleBinding(ctx, persesInstance, globalrolebinding)
+	})
+
+	var planned, failures []string
+	var firstFailure subreconciler.Outcome
+	for i, persesInstance := range available {
+		instanceName := fmt.Sprintf("%s/%s", persesInstance.Namespace, persesInstance.Name)
+		outcome := outcomes[i]
+		if outcome.Halted() {
+			if len(failures) == 0 {
+				firstFailure = outcome
+			}
+			failures = append(failures, fmt.Sprintf("%s: %v", instanceName, outcome.Err))
+			persescommon.RecordInstanceEvent(r.Recorder, globalrolebinding, persesInstance, corev1.EventTypeWarning, string(outcome.Reason), "Failed to sync global role binding: %v", outcome.Err)
+			continue
+		}
+		if outcome.Reason == persescommon.ReasonDryRun {
+			planned = append(planned, instanceName)
+		}
+	}
+
+	if len(failures) > 0 {
+		grblog.Errorf("GlobalRoleBinding %s failed to sync to %d of %d Perses instances", globalrolebinding.Name, len(failures), len(available))
+		// A single failure is reported as is, so that its message stays actionable.
+		failure := firstFailure.Err
+		if len(failures) > 1 {
+			failure = fmt.Errorf("failed to sync to %d of %d Perses instances: %s", len(failures), len(available), strings.Join(failures, "; "))
+		}
+		return r.setStatusToDegraded(ctx, req, firstFailure.Result, firstFailure.Reason, failure)
+	}
+
+	if persescommon.IsDryRun(globalrolebinding, r.DryRun) {
+		return r.updateGlobalRoleBindingStatus(ctx, req, func(globalrolebinding *persesv1alpha2.PersesGlobalRoleBinding) {
+			meta.SetStatusCondition(&globalrolebinding.Status.Conditions, persescommon.DryRunCondition("GlobalRoleBinding", globalrolebinding.Name, planned))
+		})
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesGlobalRoleBindingReconciler) listSelectedInstances(ctx context.Context, globalrolebinding *persesv1alpha2.PersesGlobalRoleBinding) (*persesv1alpha2.PersesList, error) {
+	var labelSelector labels.Selector
+	if globalrolebinding.Spec.InstanceSelector == nil {
+		labelSelector = labels.Everything()
+	} else {
+		var err error
+		labelSelector, err = metav1.LabelSelectorAsSelector(globalrolebinding.Spec.InstanceSelector)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	persesInstances := &persesv1alpha2.PersesList{}
+	opts := &client.ListOptions{
+		LabelSelector: labelSelector,
+	}
+	if err := r.List(ctx, persesInstances, opts); err != nil {
+		return nil, err
+	}
+	return persesInstances, nil
+}
+
+func (r *PersesGlobalRoleBindingReconciler) syncPersesGlobalRoleBinding(ctx context.Context, perses persesv1alpha2.Perses, globalrolebinding *persesv1alpha2.PersesGlobalRoleBinding) (*ctrl.Result, persescommon.ConditionStatusReason, error) {
+	persesClient, err := r.ClientFactory.CreateClient(ctx, r.APIReader, perses)
+
+	if err != nil {
+		grblog.WithError(err).Error("Failed to create perses rest client")
+		return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonConnectionFailed)
+
+	}
+
+	existing, err := persesClient.GlobalRoleBinding().Get(globalrolebinding.Name)
+	notFound := err != nil && errors.Is(err, perseshttp.RequestNotFoundError)
+
+	if err != nil && !notFound {
+		res, err := subreconciler.RequeueWithError(err)
+		return res, persescommon.ReasonBackendError, err
+	}
+
+	var existingTags, tags set.Set[string]
+	if !notFound {
+		existingTags = existing.Metadata.Tags
+	}
+	managed, err := persescommon.ClaimOwnership(globalrolebinding.Spec.ConflictPolicy, "global role binding", globalrolebinding.Name, !notFound, existingTags)
+	if err != nil {
+		grblog.WithError(err).Errorf("GlobalRoleBinding conflict: %s", globalrolebinding.Name)
+		return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonConflict)
+	}
+	if managed {
+		tags = persescommon.WithManagedTag(nil)
+	}
+
+	globalRoleBindingWithName := &persesv1.GlobalRoleBinding{
+		Kind: persesv1.KindGlobalRoleBinding,
+		Metadata: persesv1.Metadata{
+			Name: globalrolebinding.Name,
+			Tags: tags,
+		},
+		Spec: globalrolebinding.Spec.Config.RoleBindingSpec,
+	}
+
+	if !notFound && persescommon.GlobalRoleBindingInSync(existing, globalRoleBindingWithName) {
+		grblog.Debugf("GlobalRoleBinding already in sync: %s", globalrolebinding.Name)
+		res, err := subreconciler.ContinueReconciling()
+		return res, "", err
+	}
+
+	dryRun := persescommon.IsDryRun(globalrolebinding, r.DryRun)
+	if dryRun {
+		plan := "created"
+		if !notFound {
+			plan = fmt.Sprintf("updated: %s", persescommon.FormatDiff(persescommon.GlobalRoleBindingDiff(existing, globalRoleBindingWithName), persescommon.MaxEventDiffLength))
+		}
+		grblog.Infof("Dry run, global role binding %s would be %s in Perses instance %s/%s", globalrolebinding.Name, plan, perses.Namespace, perses.Name)
+		persescommon.RecordInstanceEvent(r.Recorder, globalrolebinding, perses, corev1.EventTypeNormal, string(persescommon.ReasonDryRun), "Dry run: global role binding would be %s", plan)
+		res, err := subreconciler.ContinueReconciling()
+		return res, persescommon.ReasonDryRun, err
+	}
+
+	if notFound {
+		_, err = persesClient.GlobalRoleBinding().Create(globalRoleBindingWithName)
+		if err != nil {
+			grblog.WithError(err).Errorf("Failed to create global role binding: %s", globalrolebinding.Name)
+			return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonBackendError)
+		}
+		grblog.Infof("GlobalRoleBinding created: %s", globalrolebinding.Name)
+		persescommon.RecordInstanceEvent(r.Recorder, globalrolebinding, perses, corev1.EventTypeNormal, persescommon.EventReasonCreated, "Global role binding created")
+	} else {
+		_, err = persesClient.GlobalRoleBinding().Update(globalRoleBindingWithName)
+		if err != nil {
+			grblog.WithError(err).Errorf("Failed to update global role binding: %s", globalrolebinding.Name)
+			return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonBackendError)
+		}
+		grblog.Infof("GlobalRoleBinding updated: %s", globalrolebinding.Name)
+		persescommon.RecordInstanceEvent(r.Recorder, globalrolebinding, perses, corev1.EventTypeNormal, persescommon.EventReasonUpdated, "Global role binding updated: %s", persescommon.FormatDiff(persescommon.GlobalRoleBindingDiff(existing, globalRoleBindingWithName), persescommon.MaxEventDiffLength))
+	}
+
+	res, err := subreconciler.ContinueReconciling()
+	return res, "", err
+}
+
+// deleteGlobalRoleBindingInAllInstances removes the global role binding from the Perses instances selected by the
+// PersesGlobalRoleBinding. The deletion is blocked while one of them cannot confirm the removal.
+func (r *PersesGlobalRoleBindingReconciler) deleteGlobalRoleBindingInAllInstances(ctx context.Context, req ctrl.Request, globalrolebinding *persesv1alpha2.PersesGlobalRoleBinding) (*ctrl.Result, error) {
+	persesInstances, err := r.listSelectedInstances(ctx, globalrolebinding)
+	if err != nil {
+		grblog.WithError(err).Error("Failed to get perses instances")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	var blocked []string
+	available := make([]persesv1alpha2.Perses, 0, len(persesInstances.Items))
+	for _, persesInstance := range persesInstances.Items {
+		if !meta.IsStatusConditionTrue(persesInstance.Status.Conditions, persescommon.TypeAvailablePerses) {
+			grblog.Infof("Perses instance %s/%s is not available, global role binding deletion is blocked", persesInstance.Namespace, persesInstance.Name)
+			blocked = append(blocked, fmt.Sprintf("%s/%s (not available)", persesInstance.Namespace, persesInstance.Name))
+			persescommon.RecordInstanceEvent(r.Recorder, globalrolebinding, persesInstance, corev1.EventTypeWarning, string(persescommon.ReasonDeletionBlocked), "Global role binding deletion is waiting for the instance to be available")
+			continue
+		}
+		available = append(available, persesInstance)
+	}
+
+	outcomes := subreconciler.ForEach(available, r.InstanceSyncConcurrency, func(persesInstance persesv1alpha2.Perses) (*ctrl.Result, persescommon.ConditionStatusReason, error) {
+		res, err := r.deleteGlobalRoleBinding(ctx, persesInstance, globalrolebinding)
+		return res, "", err
+	})
+	for i, persesInstance := range available {
+		if outcome := outcomes[i]; outcome.Halted() {
+			blocked = append(blocked, fmt.Sprintf("%s/%s (%v)", persesInstance.Namespace, persesInstance.Name, outcome.Err))
+			persescommon.RecordInstanceEvent(r.Recorder, globalrolebinding, persesInstance, corev1.EventTypeWarning, string(persescommon.ReasonDeletionBlocked), "Failed to delete global role binding: %v", outcome.Err)
+		}
+	}
+
+	if len(blocked) > 0 {
+		return r.setStatusToDegraded(ctx, req, &ctrl.Result{RequeueAfter: time.Minute}, persescommon.ReasonDeletionBlocked,
+			fmt.Errorf("global role binding deletion is waiting for Perses instances: %s", strings.Join(blocked, "; ")))
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesGlobalRoleBindingReconciler) deleteGlobalRoleBinding(ctx context.Context, perses persesv1alpha2.Perses, globalrolebinding *persesv1alpha2.PersesGlobalRoleBinding) (*ctrl.Result, error) {
+	roleBindingName := globalrolebinding.Name
+	persesClient, err := r.ClientFactory.CreateClient(ctx, r.APIReader, perses)
+
+	if err != nil {
+		grblog.WithError(err).Error("Failed to create perses rest client")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	existing, err := persesClient.GlobalRoleBinding().Get(roleBindingName)
+	if err != nil {
+		if errors.Is(err, perseshttp.RequestNotFoundError) {
+			grblog.Infof("GlobalRoleBinding not found: %s", roleBindingName)
+			return subreconciler.ContinueReconciling()
+		}
+		grblog.WithError(err).Errorf("Failed to get global role binding: %s", roleBindingName)
+		return subreconciler.RequeueWithError(err)
+	}
+
+	// A global role binding the operator does not own was either left untouched or overwritten, it is not removed.
+	if !persescommon.IsManaged(existing.Metadata.Tags) {
+		grblog.Infof("GlobalRoleBinding not managed by the operator, keeping it: %s", roleBindingName)
+		return subreconciler.ContinueReconciling()
+	}
+
+	if persescommon.IsDryRun(globalrolebinding, r.DryRun) {
+		grblog.Infof("Dry run, global role binding %s would be deleted from Perses instance %s/%s", roleBindingName, perses.Namespace, perses.Name)
+		persescommon.RecordInstanceEvent(r.Recorder, globalrolebinding, perses, corev1.EventTypeNormal, string(persescommon.ReasonDryRun), "Dry run: global role binding would be deleted")
+		return subreconciler.ContinueReconciling()
+	}
+
+	// Ignore NotFound — the resource may have already been deleted from Perses directly.
+	// Any other error means the delete failed and should be retried.
+	err = persesClient.GlobalRoleBinding().Delete(roleBindingName)
+
+	if err != nil {
+		if errors.Is(err, perseshttp.RequestNotFoundError) {
+			grblog.Infof("GlobalRoleBinding not found: %s", roleBindingName)
+			return subreconciler.ContinueReconciling()
+		}
+		grblog.WithError(err).Errorf("Failed to delete global role binding: %s", roleBindingName)
+		return subreconciler.RequeueWithError(err)
+	}
+
+	grblog.Infof("GlobalRoleBinding deleted: %s", roleBindingName)
+	persescommon.RecordInstanceEvent(r.Recorder, globalrolebinding, perses, corev1.EventTypeNormal, persescommon.EventReasonDeleted, "Global role binding deleted")
+
+	return subreconciler.ContinueReconciling()
+}
@@ -0,0 +1,352 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package globalrolebindings
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	logger "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	operatormetrics "github.com/perses/perses-operator/internal/metrics"
+	"github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses-operator/internal/subreconciler"
+)
+
+type globalRoleBindingContextKey string
+
+const contextKey globalRoleBindingContextKey = "globalrolebinding"
+
+func withGlobalRoleBinding(ctx context.Context, gv *persesv1alpha2.PersesGlobalRoleBinding) context.Context {
+	return context.WithValue(ctx, contextKey, gv)
+}
+
+func globalRoleBindingFromContext(ctx context.Context) (*persesv1alpha2.PersesGlobalRoleBinding, bool) {
+	gv, ok := ctx.Value(contextKey).(*persesv1alpha2.PersesGlobalRoleBinding)
+	return gv, ok
+}
+
+// PersesGlobalRoleBindingReconciler reconciles a PersesGlobalRoleBinding object
+type PersesGlobalRoleBindingReconciler struct {
+	client.Client
+	APIReader             client.Reader // uncached reader — OnlyMetadata watch caches metadata only
+	Scheme                *runtime.Scheme
+	Recorder              record.EventRecorder
+	ClientFactory         common.PersesClientFactory
+	Metrics               *operatormetrics.Metrics
+	ReconciliationTracker *operatormetrics.ReconciliationTracker
+	// InstanceSyncConcurrency is the maximum number of Perses instances the global role binding
+	// is synced to at the same time. Lower than 1 syncs one instance at a time.
+	InstanceSyncConcurrency int
+	// DryRun only plans the changes to the global role bindings in Perses, without applying them,
+	// as for the global role bindings with the perses.dev/dry-run annotation.
+	DryRun bool
+}
+
+var log = logger.WithField("module", "perses_globalrolebinding_controller")
+
+// +kubebuilder:rbac:groups=perses.dev,resources=persesglobalrolebindings,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=perses.dev,resources=persesglobalrolebindings/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=perses.dev,resources=persesglobalrolebindings/finalizers,verbs=update
+func (r *PersesGlobalRoleBindingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	start := time.Now()
+	objKey := req.String()
+
+	if r.Metrics != nil {
+		r.Metrics.ReconcileOperations("persesglobalrolebinding").Inc()
+	}
+
+	log.Infof("Reconciling PersesGlobalRoleBinding: %s", req.Name)
+
+	// Deletion is handled through the finalizer, so a missing object has nothing left to clean up.
+	globalrolebinding := &persesv1alpha2.PersesGlobalRoleBinding{}
+	if err := r.APIReader.Get(ctx, req.NamespacedName, globalrolebinding); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Infof("perses global role binding resource not found. Ignoring since object must be deleted")
+			if r.ReconciliationTracker != nil {
+				r.ReconciliationTracker.ForgetObject(objKey)
+			}
+			if r.Metrics != nil {
+				r.Metrics.ForgetObject(objKey)
+			}
+			return subreconciler.Evaluate(subreconciler.DoNotRequeue())
+		}
+		log.WithError(err).Error("Failed to get perses global role binding")
+		if r.Metrics != nil {
+			r.Metrics.ReconcileErrors("persesglobalrolebinding", "get_failed").Inc()
+		}
+		return subreconciler.Evaluate(subreconciler.RequeueWithError(err))
+	}
+
+	// Store global role binding in context for all sub-reconcilers
+	ctx = withGlobalRoleBinding(ctx, globalrolebinding)
+
+	subreconcilersForPerses := []subreconciler.FnWithRequest{
+		r.handleDelete,
+		r.addFinalizer,
+		r.setStatusToUnknown,
+		r.reconcileGlobalRoleBindingsInAllInstances,
+		r.setStatusToComplete,
+	}
+
+	var reconcileErr error
+	for _, f := range subreconcilersForPerses {
+		if r, err := f(ctx, req); subreconciler.ShouldHaltOrRequeue(r, err) {
+			reconcileErr = err
+			break
+		}
+	}
+
+	// Track reconciliation status
+	if r.ReconciliationTracker != nil {
+		r.ReconciliationTracker.SetStatus(objKey, reconcileErr)
+		if reconcileErr == nil {
+			r.ReconciliationTracker.SetReasonAndMessage(objKey, "ReconciliationSuccessful", "GlobalRoleBinding reconciled successfully")
+		}
+	}
+
+	// Track metrics
+	if r.Metrics != nil {
+		if reconcileErr != nil {
+			reason := string(common.ExtractReason(reconcileErr, "reconciliation_failed"))
+			r.Metrics.ReconcileErrors("persesglobalrolebinding", reason).Inc()
+			r.Metrics.SetFailedResources(objKey, "globalrolebinding", "", 1)
+		} else {
+			r.Metrics.SetSyncedResources(objKey, "globalrolebinding", "", 1)
+		}
+	}
+
+	if reconcileErr != nil {
+		return subreconciler.Evaluate(subreconciler.RequeueWithError(reconcileErr))
+	}
+
+	log.WithField("duration", time.Since(start)).Debug("global role binding reconciliation completed")
+	return subreconciler.Evaluate(subreconciler.DoNotRequeue())
+}
+
+// handleDelete removes the global role binding from the selected Perses instances, then releases the finalizer.
+func (r *PersesGlobalRoleBindingReconciler) handleDelete(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	globalrolebinding, ok := globalRoleBindingFromContext(ctx)
+	if !ok {
+		log.Error("global role binding not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("global role binding not found in context"))
+	}
+
+	if globalrolebinding.GetDeletionTimestamp() == nil {
+		return subreconciler.ContinueReconciling()
+	}
+
+	if !controllerutil.ContainsFinalizer(globalrolebinding, common.PersesFinalizer) {
+		return subreconciler.DoNotRequeue()
+	}
+
+	if res, err := r.deleteGlobalRoleBindingInAllInstances(ctx, req, globalrolebinding); subreconciler.ShouldHaltOrRequeue(res, err) {
+		return res, err
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesGlobalRoleBinding{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		if !controllerutil.RemoveFinalizer(fresh, common.PersesFinalizer) {
+			return nil
+		}
+		return r.Update(ctx, fresh)
+	})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to remove finalizer from perses global role binding")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	log.Infof("PersesGlobalRoleBinding %s deleted", globalrolebinding.Name)
+	return subreconciler.DoNotRequeue()
+}
+
+// addFinalizer makes sure the global role binding is not removed from the cluster before
+// it has been removed from the Perses instances it was synced to.
+func (r *PersesGlobalRoleBindingReconciler) addFinalizer(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	globalrolebinding, ok := globalRoleBindingFromContext(ctx)
+	if !ok {
+		log.Error("global role binding not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("global role binding not found in context"))
+	}
+
+	if controllerutil.ContainsFinalizer(globalrolebinding, common.PersesFinalizer) {
+		return subreconciler.ContinueReconciling()
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesGlobalRoleBinding{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		if !controllerutil.AddFinalizer(fresh, common.PersesFinalizer) {
+			return nil
+		}
+		return r.Update(ctx, fresh)
+	})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to add finalizer to perses global role binding")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesGlobalRoleBindingReconciler) updateGlobalRoleBindingStatus(
+	ctx context.Context,
+	req ctrl.Request,
+	updateFn func(*persesv1alpha2.PersesGlobalRoleBinding),
+) (*ctrl.Result, error) {
+	_, ok := globalRoleBindingFromContext(ctx)
+	if !ok {
+		log.Error("global role binding not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("global role binding not found in context"))
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesGlobalRoleBinding{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		before := common.SnapshotConditions(fresh.Status.Conditions)
+		updateFn(fresh)
+		if !common.ConditionsChanged(before, fresh.Status.Conditions) {
+			return nil
+		}
+		return r.Status().Update(ctx, fresh)
+	})
+
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("perses global role binding resource not found. Ignoring since object must be deleted")
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to update Perses global role binding status")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesGlobalRoleBindingReconciler) setStatusToUnknown(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	return r.updateGlobalRoleBindingStatus(ctx, req, func(globalrolebinding *persesv1alpha2.PersesGlobalRoleBinding) {
+		if len(globalrolebinding.Status.Conditions) == 0 {
+			meta.SetStatusCondition(&globalrolebinding.Status.Conditions, metav1.Condition{
+				Type: common.TypeAvailablePerses, Status: metav1.ConditionUnknown,
+				Reason: "Reconciling", Message: "Starting reconciliation"})
+		}
+	})
+}
+
+func (r *PersesGlobalRoleBindingReconciler) setStatusToComplete(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	// The observed state is the one that was synced, the custom resource may have changed since.
+	synced, ok := globalRoleBindingFromContext(ctx)
+	if !ok {
+		log.Error("global role binding not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("global role binding not found in context"))
+	}
+
+	// Nothing was applied in a dry run, so the availability of the global role binding is left as is.
+	if common.IsDryRun(synced, r.DryRun) {
+		return subreconciler.ContinueReconciling()
+	}
+
+	return r.updateGlobalRoleBindingStatus(ctx, req, func(globalrolebinding *persesv1alpha2.PersesGlobalRoleBinding) {
+		meta.RemoveStatusCondition(&globalrolebinding.Status.Conditions, common.TypeDryRunPerses)
+		meta.SetStatusCondition(&globalrolebinding.Status.Conditions, metav1.Condition{
+			Type: common.TypeDegradedPerses, Status: metav1.ConditionFalse,
+			Reason: "Reconciled", Message: fmt.Sprintf("GlobalRoleBinding (%s) reconciled successfully", globalrolebinding.Name)})
+		meta.SetStatusCondition(&globalrolebinding.Status.Conditions, metav1.Condition{
+			Type: common.TypeAvailablePerses, Status: metav1.ConditionTrue,
+			Reason: "Reconciled", Message: fmt.Sprintf("GlobalRoleBinding (%s) created successfully", globalrolebinding.Name)})
+	})
+}
+
+func (r *PersesGlobalRoleBindingReconciler) setStatusToDegraded(
+	ctx context.Context,
+	req ctrl.Request,
+	degradedResult *ctrl.Result,
+	degradedReason common.ConditionStatusReason,
+	degradedError error,
+) (*ctrl.Result, error) {
+	msg := "unknown error"
+	if degradedError != nil {
+		msg = degradedError.Error()
+	}
+
+	result, err := r.updateGlobalRoleBindingStatus(ctx, req, func(globalrolebinding *persesv1alpha2.PersesGlobalRoleBinding) {
+		meta.SetStatusCondition(&globalrolebinding.Status.Conditions, metav1.Condition{
+			Type: common.TypeAvailablePerses, Status: metav1.ConditionFalse,
+			Reason: string(degradedReason), Message: msg})
+		meta.SetStatusCondition(&globalrolebinding.Status.Conditions, metav1.Condition{
+			Type: common.TypeDegradedPerses, Status: metav1.ConditionTrue,
+			Reason: string(degradedReason), Message: msg})
+	})
+
+	if err != nil {
+		return result, err
+	}
+
+	if degradedError != nil {
+		return degradedResult, common.NewReasonError(degradedError, degradedReason)
+	}
+	return degradedResult, nil
+}
+
+// findGlobalRoleBindingsForPerses returns reconcile requests for all PersesGlobalRoleBindings
+// when a Perses instance becomes available.
+// Global role bindings are cluster-scoped and their instanceSelector labels determine
+// which Perses instances they sync to. If no instanceSelector is set, they sync to all instances.
+func (r *PersesGlobalRoleBindingReconciler) findGlobalRoleBindingsForPerses(ctx context.Context, _ client.Object) []reconcile.Request {
+	return common.MetadataListToRequests(ctx, r.Client, persesv1alpha2.GroupVersion.WithKind("PersesGlobalRoleBindingList"))
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// It watches PersesGlobalRoleBinding resources and also watches Perses instances
+// to trigger re-reconciliation of all global role bindings when a Perses instance becomes
+// available. Global role bindings are matched to Perses instances via instanceSelector labels.
+// Create and delete events for Perses instances are ignored because the instance is not yet
+// ready at creation, and deletion is handled by the global role binding's own reconciliation loop.
+func (r *PersesGlobalRoleBindingReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&persesv1alpha2.PersesGlobalRoleBinding{}, builder.OnlyMetadata).
+		Watches(
+			&persesv1alpha2.Perses{},
+			handler.EnqueueRequestsFromMapFunc(r.findGlobalRoleBindingsForPerses),
+			builder.WithPredicates(common.PersesAvailabilityPredicate()),
+		).
+		Complete(r)
+}
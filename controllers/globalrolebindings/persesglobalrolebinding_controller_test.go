@@ -0,0 +1,298 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package globalrolebindings
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/perses/common/set"
+	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	internal "github.com/perses/perses-operator/internal/perses"
+	"github.com/perses/perses-operator/internal/perses/common"
+	persesclient "github.com/perses/perses/pkg/client/api/v1"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	persesv1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/stretchr/testify/mock"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func TestGlobalRoleBindingController(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GlobalRoleBinding Controller Suite")
+}
+
+func newTestGlobalRoleBindingReconciler(objects ...runtime.Object) *PersesGlobalRoleBindingReconciler {
+	scheme := runtime.NewScheme()
+	Expect(persesv1alpha2.AddToScheme(scheme)).To(Succeed())
+
+	clientBuilder := fake.NewClientBuilder().WithScheme(scheme)
+	for _, obj := range objects {
+		clientBuilder = clientBuilder.WithRuntimeObjects(obj)
+	}
+	clientBuilder = clientBuilder.WithStatusSubresource(&persesv1alpha2.PersesGlobalRoleBinding{})
+
+	c := clientBuilder.Build()
+	return &PersesGlobalRoleBindingReconciler{
+		Client:    c,
+		APIReader: c,
+		Scheme:    scheme,
+	}
+}
+
+var _ = Describe("GlobalRoleBinding controller", func() {
+	const RoleBindingName = "admins"
+
+	newPerses := func(name string) *persesv1alpha2.Perses {
+		return &persesv1alpha2.Perses{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "perses-dev"},
+		}
+	}
+
+	Context("syncPersesGlobalRoleBinding", func() {
+		newRoleBinding := func() *persesv1alpha2.PersesGlobalRoleBinding {
+			return &persesv1alpha2.PersesGlobalRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: RoleBindingName,
+				},
+				Spec: persesv1alpha2.RoleBindingSpec{
+					Config: persesv1alpha2.RoleBinding{
+						RoleBindingSpec: persesv1.RoleBindingSpec{
+							Role:     "admin",
+							Subjects: []persesv1.Subject{{Kind: persesv1.KindUser, Name: "alice"}},
+						},
+					},
+				},
+			}
+		}
+
+		expectedRoleBinding := func() *persesv1.GlobalRoleBinding {
+			return &persesv1.GlobalRoleBinding{
+				Kind: persesv1.KindGlobalRoleBinding,
+				Metadata: persesv1.Metadata{
+					Name: RoleBindingName,
+					Tags: common.WithManagedTag(nil),
+				},
+				Spec: newRoleBinding().Spec.Config.RoleBindingSpec,
+			}
+		}
+
+		It("should claim a global role binding of the same name created in Perses by hand", func() {
+			existing := expectedRoleBinding()
+			existing.Metadata.Tags = nil
+			existing.Spec.Subjects = []persesv1.Subject{{Kind: persesv1.KindUser, Name: "mallory"}}
+
+			mockPersesClient := &internal.MockClient{}
+			mockGlobalRoleBinding := &internal.MockGlobalRoleBinding{}
+			mockPersesClient.On("GlobalRoleBinding").Return(mockGlobalRoleBinding)
+			mockGlobalRoleBinding.On("Get", RoleBindingName).Return(existing, nil)
+			mockGlobalRoleBinding.On("Update", expectedRoleBinding()).Return(expectedRoleBinding(), nil).Once()
+
+			recorder := record.NewFakeRecorder(10)
+			r := newTestGlobalRoleBindingReconciler()
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+			r.Recorder = recorder
+
+			_, _, err := r.syncPersesGlobalRoleBinding(context.Background(), *newPerses("perses"), newRoleBinding())
+			Expect(err).ToNot(HaveOccurred())
+			mockGlobalRoleBinding.AssertExpectations(GinkgoT())
+			Expect(recorder.Events).To(Receive(And(
+				ContainSubstring(common.EventReasonUpdated),
+				ContainSubstring(common.ManagedTag),
+				ContainSubstring(`"path":"spec.subjects[0].name"`),
+			)))
+		})
+
+		It("should report a conflict and leave a global role binding not managed by the operator untouched", func() {
+			existing := expectedRoleBinding()
+			existing.Metadata.Tags = set.New("infra")
+
+			mockPersesClient := &internal.MockClient{}
+			mockGlobalRoleBinding := &internal.MockGlobalRoleBinding{}
+			mockPersesClient.On("GlobalRoleBinding").Return(mockGlobalRoleBinding)
+			mockGlobalRoleBinding.On("Get", RoleBindingName).Return(existing, nil)
+
+			r := newTestGlobalRoleBindingReconciler()
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			globalRoleBinding := newRoleBinding()
+			globalRoleBinding.Spec.ConflictPolicy = persesv1alpha2.ConflictPolicyFail
+			_, reason, err := r.syncPersesGlobalRoleBinding(context.Background(), *newPerses("perses"), globalRoleBinding)
+			Expect(err).To(HaveOccurred())
+			Expect(reason).To(Equal(common.ReasonConflict))
+			mockGlobalRoleBinding.AssertNotCalled(GinkgoT(), "Update", mock.Anything)
+		})
+
+		It("should only plan the creation of a global role binding in a dry run", func() {
+			mockPersesClient := &internal.MockClient{}
+			mockGlobalRoleBinding := &internal.MockGlobalRoleBinding{}
+			mockPersesClient.On("GlobalRoleBinding").Return(mockGlobalRoleBinding)
+			mockGlobalRoleBinding.On("Get", RoleBindingName).Return(&persesv1.GlobalRoleBinding{}, perseshttp.RequestNotFoundError)
+
+			recorder := record.NewFakeRecorder(10)
+			r := newTestGlobalRoleBindingReconciler()
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+			r.Recorder = recorder
+			r.DryRun = true
+
+			_, reason, err := r.syncPersesGlobalRoleBinding(context.Background(), *newPerses("perses"), newRoleBinding())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reason).To(Equal(common.ReasonDryRun))
+			mockGlobalRoleBinding.AssertNotCalled(GinkgoT(), "Create", expectedRoleBinding())
+			Expect(recorder.Events).To(Receive(ContainSubstring("Dry run: global role binding would be created")))
+		})
+	})
+
+	Context("handleDelete", func() {
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: RoleBindingName}}
+
+		deletingGlobalRoleBinding := func() *persesv1alpha2.PersesGlobalRoleBinding {
+			return &persesv1alpha2.PersesGlobalRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              RoleBindingName,
+					Finalizers:        []string{common.PersesFinalizer},
+					DeletionTimestamp: &metav1.Time{Time: time.Now()},
+				},
+				Spec: persesv1alpha2.RoleBindingSpec{
+					Config: persesv1alpha2.RoleBinding{
+						RoleBindingSpec: persesv1.RoleBindingSpec{
+							Role:     "admin",
+							Subjects: []persesv1.Subject{{Kind: persesv1.KindUser, Name: "alice"}},
+						},
+					},
+					InstanceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "observability"}},
+				},
+			}
+		}
+
+		persesGlobalRoleBinding := func(tags set.Set[string]) *persesv1.GlobalRoleBinding {
+			return &persesv1.GlobalRoleBinding{
+				Metadata: persesv1.Metadata{Name: RoleBindingName, Tags: tags},
+			}
+		}
+
+		selected := map[string]string{"team": "observability"}
+
+		It("should only delete the global role binding from the selected Perses instances", func() {
+			deleting := deletingGlobalRoleBinding()
+			selectedClient := &internal.MockClient{}
+			otherClient := &internal.MockClient{}
+			mockGlobalRoleBinding := &internal.MockGlobalRoleBinding{}
+			selectedClient.On("GlobalRoleBinding").Return(mockGlobalRoleBinding)
+			mockGlobalRoleBinding.On("Get", RoleBindingName).Return(persesGlobalRoleBinding(common.WithManagedTag(nil)), nil)
+			mockGlobalRoleBinding.On("Delete", RoleBindingName).Return(nil).Once()
+
+			recorder := record.NewFakeRecorder(10)
+			r := newTestGlobalRoleBindingReconciler(deleting, availablePerses("selected", selected), availablePerses("other", nil))
+			r.ClientFactory = instanceClientFactory{"selected": selectedClient, "other": otherClient}
+			r.Recorder = recorder
+
+			_, err := r.handleDelete(withGlobalRoleBinding(context.Background(), deleting), req)
+			Expect(err).ToNot(HaveOccurred())
+			mockGlobalRoleBinding.AssertExpectations(GinkgoT())
+			otherClient.AssertNotCalled(GinkgoT(), "GlobalRoleBinding")
+			Expect(recorder.Events).To(Receive(ContainSubstring("Perses instance perses-dev/selected: Global role binding deleted")))
+
+			err = r.Get(context.Background(), req.NamespacedName, &persesv1alpha2.PersesGlobalRoleBinding{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should keep a global role binding not managed by the operator in Perses", func() {
+			deleting := deletingGlobalRoleBinding()
+			mockPersesClient := &internal.MockClient{}
+			mockGlobalRoleBinding := &internal.MockGlobalRoleBinding{}
+			mockPersesClient.On("GlobalRoleBinding").Return(mockGlobalRoleBinding)
+			mockGlobalRoleBinding.On("Get", RoleBindingName).Return(persesGlobalRoleBinding(nil), nil)
+
+			r := newTestGlobalRoleBindingReconciler(deleting, availablePerses("perses", selected))
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			_, err := r.handleDelete(withGlobalRoleBinding(context.Background(), deleting), req)
+			Expect(err).ToNot(HaveOccurred())
+			mockGlobalRoleBinding.AssertNotCalled(GinkgoT(), "Delete", RoleBindingName)
+
+			err = r.Get(context.Background(), req.NamespacedName, &persesv1alpha2.PersesGlobalRoleBinding{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should ignore a global role binding already deleted from Perses", func() {
+			deleting := deletingGlobalRoleBinding()
+			mockPersesClient := &internal.MockClient{}
+			mockGlobalRoleBinding := &internal.MockGlobalRoleBinding{}
+			mockPersesClient.On("GlobalRoleBinding").Return(mockGlobalRoleBinding)
+			mockGlobalRoleBinding.On("Get", RoleBindingName).Return(&persesv1.GlobalRoleBinding{}, perseshttp.RequestNotFoundError)
+
+			r := newTestGlobalRoleBindingReconciler(deleting, availablePerses("perses", selected))
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			res, err := r.handleDelete(withGlobalRoleBinding(context.Background(), deleting), req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(&ctrl.Result{}))
+			mockGlobalRoleBinding.AssertNotCalled(GinkgoT(), "Delete", RoleBindingName)
+		})
+
+		It("should keep the finalizer while a selected Perses instance is not available", func() {
+			deleting := deletingGlobalRoleBinding()
+			mockPersesClient := &internal.MockClient{}
+			unavailable := newPerses("perses")
+			unavailable.Labels = selected
+
+			recorder := record.NewFakeRecorder(10)
+			r := newTestGlobalRoleBindingReconciler(deleting, unavailable)
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+			r.Recorder = recorder
+
+			res, err := r.handleDelete(withGlobalRoleBinding(context.Background(), deleting), req)
+			Expect(common.ExtractReason(err, "")).To(Equal(common.ReasonDeletionBlocked))
+			Expect(res.RequeueAfter).To(Equal(time.Minute))
+			Expect(recorder.Events).To(Receive(ContainSubstring("Global role binding deletion is waiting for the instance to be available")))
+			mockPersesClient.AssertNotCalled(GinkgoT(), "GlobalRoleBinding")
+
+			fresh := &persesv1alpha2.PersesGlobalRoleBinding{}
+			Expect(r.Get(context.Background(), req.NamespacedName, fresh)).To(Succeed())
+			Expect(controllerutil.ContainsFinalizer(fresh, common.PersesFinalizer)).To(BeTrue())
+		})
+	})
+})
+
+func availablePerses(name string, labels map[string]string) *persesv1alpha2.Perses {
+	return &persesv1alpha2.Perses{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "perses-dev", Labels: labels},
+		Status: persesv1alpha2.PersesStatus{
+			Conditions: []metav1.Condition{{Type: common.TypeAvailablePerses, Status: metav1.ConditionTrue}},
+		},
+	}
+}
+
+// instanceClientFactory returns the Perses client of each instance by name.
+type instanceClientFactory map[string]persesclient.ClientInterface
+
+func (f instanceClientFactory) CreateClient(_ context.Context, _ client.Reader, perses persesv1alpha2.Perses) (persesclient.ClientInterface, error) {
+	if persesClient, ok := f[perses.Name]; ok {
+		return persesClient, nil
+	}
+	return nil, fmt.Errorf("connection refused")
+}
@@ -0,0 +1,301 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package globalroles
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/perses/common/set"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	persesv1 "github.com/perses/perses/pkg/model/api/v1"
+	logger "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	persescommon "github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses-operator/internal/subreconciler"
+)
+
+var grlog = logger.WithField("module", "globalrole_controller")
+
+func (r *PersesGlobalRoleReconciler) reconcileGlobalRolesInAllInstances(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	globalrole, ok := globalRoleFromContext(ctx)
+	if !ok {
+		grlog.Error("global role not found in context")
+		res, err := subreconciler.RequeueWithError(fmt.Errorf("global role not found in context"))
+		return r.setStatusToDegraded(ctx, req, res, persescommon.ReasonMissingResource, err)
+	}
+
+	persesInstances, err := r.listSelectedInstances(ctx, globalrole)
+	if err != nil {
+		grlog.WithError(err).Error("Failed to get perses instances")
+		res, err := subreconciler.RequeueWithError(err)
+		return r.setStatusToDegraded(ctx, req, res, persescommon.ReasonMissingPerses, err)
+	}
+
+	if len(persesInstances.Items) == 0 {
+		grlog.Info("No Perses instances found, requeue in 1 minute")
+		return r.setStatusToDegraded(ctx, req, &ctrl.Result{RequeueAfter: time.Minute}, persescommon.ReasonMissingPerses, fmt.Errorf("no Perses instances found matching the label selector"))
+	}
+
+	available := make([]persesv1alpha2.Perses, 0, len(persesInstances.Items))
+	for _, persesInstance := range persesInstances.Items {
+		if !meta.IsStatusConditionTrue(persesInstance.Status.Conditions, persescommon.TypeAvailablePerses) {
+			grlog.Infof("Skipping Perses instance %s/%s (not yet available)", persesInstance.Namespace, persesInstance.Name)
+			continue
+		}
+		available = append(available, persesInstance)
+	}
+
+	// Every instance is synced, so that a failing instance does not hold back the others.
+	outcomes := subreconciler.ForEach(available, r.InstanceSyncConcurrency, func(persesInstance persesv1alpha2.Perses) (*ctrl.Result, persescommon.ConditionStatusReason, error) {
+		return r.syncPersesGlobalRole(ctx, persesInstance, globalrole)
+	})
+
+	var planned, failures []string
+	var firstFailure subreconciler.Outcome
+	for i, persesInstance := range available {
+		instanceName := fmt.Sprintf("%s/%s", persesInstance.Namespace, persesInstance.Name)
+		outcome := outcomes[i]
+		if outcome.Halted() {
+			if len(failures) == 0 {
+				firstFailure = outcome
+			}
+			failures = append(failures, fmt.Sprintf("%s: %v", instanceName, outcome.Err))
+			persescommon.RecordInstanceEvent(r.Recorder, globalrole, persesInstance, corev1.EventTypeWarning, string(outcome.Reason), "Failed to sync global role: %v", outcome.Err)
+			continue
+		}
+		if outcome.Reason == persescommon.ReasonDryRun {
+			planned = append(planned, instanceName)
+		}
+	}
+
+	if len(failures) > 0 {
+		grlog.Errorf("GlobalRole %s failed to sync to %d of %d Perses instances", globalrole.Name, len(failures), len(available))
+		// A single failure is reported as is, so that its message stays actionable.
+		failure := firstFailure.Err
+		if len(failures) > 1 {
+			failure = fmt.Errorf("failed to sync to %d of %d Perses instances: %s", len(failures), len(available), strings.Join(failures, "; "))
+		}
+		return r.setStatusToDegraded(ctx, req, firstFailure.Result, firstFailure.Reason, failure)
+	}
+
+	if persescommon.IsDryRun(globalrole, r.DryRun) {
+		return r.updateGlobalRoleStatus(ctx, req, func(globalrole *persesv1alpha2.PersesGlobalRole) {
+			meta.SetStatusCondition(&globalrole.Status.Conditions, persescommon.DryRunCondition("GlobalRole", globalrole.Name, planned))
+		})
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesGlobalRoleReconciler) listSelectedInstances(ctx context.Context, globalrole *persesv1alpha2.PersesGlobalRole) (*persesv1alpha2.PersesList, error) {
+	var labelSelector labels.Selector
+	if globalrole.Spec.InstanceSelector == nil {
+		labelSelector = labels.Everything()
+	} else {
+		var err error
+		labelSelector, err = metav1.LabelSelectorAsSelector(globalrole.Spec.InstanceSelector)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	persesInstances := &persesv1alpha2.PersesList{}
+	opts := &client.ListOptions{
+		LabelSelector: labelSelector,
+	}
+	if err := r.List(ctx, persesInstances, opts); err != nil {
+		return nil, err
+	}
+	return persesInstances, nil
+}
+
+func (r *PersesGlobalRoleReconciler) syncPersesGlobalRole(ctx context.Context, perses persesv1alpha2.Perses, globalrole *persesv1alpha2.PersesGlobalRole) (*ctrl.Result, persescommon.ConditionStatusReason, error) {
+	persesClient, err := r.ClientFactory.CreateClient(ctx, r.APIReader, perses)
+
+	if err != nil {
+		grlog.WithError(err).Error("Failed to create perses rest client")
+		return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonConnectionFailed)
+
+	}
+
+	existing, err := persesClient.GlobalRole().Get(globalrole.Name)
+	notFound := err != nil && errors.Is(err, perseshttp.RequestNotFoundError)
+
+	if err != nil && !notFound {
+		res, err := subreconciler.RequeueWithError(err)
+		return res, persescommon.ReasonBackendError, err
+	}
+
+	var existingTags, tags set.Set[string]
+	if !notFound {
+		existingTags = existing.Metadata.Tags
+	}
+	managed, err := persescommon.ClaimOwnership(globalrole.Spec.ConflictPolicy, "global role", globalrole.Name, !notFound, existingTags)
+	if err != nil {
+		grlog.WithError(err).Errorf("GlobalRole conflict: %s", globalrole.Name)
+		return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonConflict)
+	}
+	if managed {
+		tags = persescommon.WithManagedTag(nil)
+	}
+
+	globalRoleWithName := &persesv1.GlobalRole{
+		Kind: persesv1.KindGlobalRole,
+		Metadata: persesv1.Metadata{
+			Name: globalrole.Name,
+			Tags: tags,
+		},
+		Spec: globalrole.Spec.Config.RoleSpec,
+	}
+
+	if !notFound && persescommon.GlobalRoleInSync(existing, globalRoleWithName) {
+		grlog.Debugf("GlobalRole already in sync: %s", globalrole.Name)
+		res, err := subreconciler.ContinueReconciling()
+		return res, "", err
+	}
+
+	dryRun := persescommon.IsDryRun(globalrole, r.DryRun)
+	if dryRun {
+		plan := "created"
+		if !notFound {
+			plan = fmt.Sprintf("updated: %s", persescommon.FormatDiff(persescommon.GlobalRoleDiff(existing, globalRoleWithName), persescommon.MaxEventDiffLength))
+		}
+		grlog.Infof("Dry run, global role %s would be %s in Perses instance %s/%s", globalrole.Name, plan, perses.Namespace, perses.Name)
+		persescommon.RecordInstanceEvent(r.Recorder, globalrole, perses, corev1.EventTypeNormal, string(persescommon.ReasonDryRun), "Dry run: global role would be %s", plan)
+		res, err := subreconciler.ContinueReconciling()
+		return res, persescommon.ReasonDryRun, err
+	}
+
+	if notFound {
+		_, err = persesClient.GlobalRole().Create(globalRoleWithName)
+		if err != nil {
+			grlog.WithError(err).Errorf("Failed to create global role: %s", globalrole.Name)
+			return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonBackendError)
+		}
+		grlog.Infof("GlobalRole created: %s", globalrole.Name)
+		persescommon.RecordInstanceEvent(r.Recorder, globalrole, perses, corev1.EventTypeNormal, persescommon.EventReasonCreated, "Global role created")
+	} else {
+		_, err = persesClient.GlobalRole().Update(globalRoleWithName)
+		if err != nil {
+			grlog.WithError(err).Errorf("Failed to update global role: %s", globalrole.Name)
+			return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonBackendError)
+		}
+		grlog.Infof("GlobalRole updated: %s", globalrole.Name)
+		persescommon.RecordInstanceEvent(r.Recorder, globalrole, perses, corev1.EventTypeNormal, persescommon.EventReasonUpdated, "Global role updated: %s", persescommon.FormatDiff(persescommon.GlobalRoleDiff(existing, globalRoleWithName), persescommon.MaxEventDiffLength))
+	}
+
+	res, err := subreconciler.ContinueReconciling()
+	return res, "", err
+}
+
+// deleteGlobalRoleInAllInstances removes the global role from the Perses instances selected by the
+// PersesGlobalRole. The deletion is blocked while one of them cannot confirm the removal.
+func (r *PersesGlobalRoleReconciler) deleteGlobalRoleInAllInstances(ctx context.Context, req ctrl.Request, globalrole *persesv1alpha2.PersesGlobalRole) (*ctrl.Result, error) {
+	persesInstances, err := r.listSelectedInstances(ctx, globalrole)
+	if err != nil {
+		grlog.WithError(err).Error("Failed to get perses instances")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	var blocked []string
+	available := make([]persesv1alpha2.Perses, 0, len(persesInstances.Items))
+	for _, persesInstance := range persesInstances.Items {
+		if !meta.IsStatusConditionTrue(persesInstance.Status.Conditions, persescommon.TypeAvailablePerses) {
+			grlog.Infof("Perses instance %s/%s is not available, global role deletion is blocked", persesInstance.Namespace, persesInstance.Name)
+			blocked = append(blocked, fmt.Sprintf("%s/%s (not available)", persesInstance.Namespace, persesInstance.Name))
+			persescommon.RecordInstanceEvent(r.Recorder, globalrole, persesInstance, corev1.EventTypeWarning, string(persescommon.ReasonDeletionBlocked), "Global role deletion is waiting for the instance to be available")
+			continue
+		}
+		available = append(available, persesInstance)
+	}
+
+	outcomes := subreconciler.ForEach(available, r.InstanceSyncConcurrency, func(persesInstance persesv1alpha2.Perses) (*ctrl.Result, persescommon.ConditionStatusReason, error) {
+		res, err := r.deleteGlobalRole(ctx, persesInstance, globalrole)
+		return res, "", err
+	})
+	for i, persesInstance := range available {
+		if outcome := outcomes[i]; outcome.Halted() {
+			blocked = append(blocked, fmt.Sprintf("%s/%s (%v)", persesInstance.Namespace, persesInstance.Name, outcome.Err))
+			persescommon.RecordInstanceEvent(r.Recorder, globalrole, persesInstance, corev1.EventTypeWarning, string(persescommon.ReasonDeletionBlocked), "Failed to delete global role: %v", outcome.Err)
+		}
+	}
+
+	if len(blocked) > 0 {
+		return r.setStatusToDegraded(ctx, req, &ctrl.Result{RequeueAfter: time.Minute}, persescommon.ReasonDeletionBlocked,
+			fmt.Errorf("global role deletion is waiting for Perses instances: %s", strings.Join(blocked, "; ")))
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesGlobalRoleReconciler) deleteGlobalRole(ctx context.Context, perses persesv1alpha2.Perses, globalrole *persesv1alpha2.PersesGlobalRole) (*ctrl.Result, error) {
+	roleName := globalrole.Name
+	persesClient, err := r.ClientFactory.CreateClient(ctx, r.APIReader, perses)
+
+	if err != nil {
+		grlog.WithError(err).Error("Failed to create perses rest client")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	existing, err := persesClient.GlobalRole().Get(roleName)
+	if err != nil {
+		if errors.Is(err, perseshttp.RequestNotFoundError) {
+			grlog.Infof("GlobalRole not found: %s", roleName)
+			return subreconciler.ContinueReconciling()
+		}
+		grlog.WithError(err).Errorf("Failed to get global role: %s", roleName)
+		return subreconciler.RequeueWithError(err)
+	}
+
+	// A global role the operator does not own was either left untouched or overwritten, it is not removed.
+	if !persescommon.IsManaged(existing.Metadata.Tags) {
+		grlog.Infof("GlobalRole not managed by the operator, keeping it: %s", roleName)
+		return subreconciler.ContinueReconciling()
+	}
+
+	if persescommon.IsDryRun(globalrole, r.DryRun) {
+		grlog.Infof("Dry run, global role %s would be deleted from Perses instance %s/%s", roleName, perses.Namespace, perses.Name)
+		persescommon.RecordInstanceEvent(r.Recorder, globalrole, perses, corev1.EventTypeNormal, string(persescommon.ReasonDryRun), "Dry run: global role would be deleted")
+		return subreconciler.ContinueReconciling()
+	}
+
+	// Ignore NotFound — the resource may have already been deleted from Perses directly.
+	// Any other error means the delete failed and should be retried.
+	err = persesClient.GlobalRole().Delete(roleName)
+
+	if err != nil {
+		if errors.Is(err, perseshttp.RequestNotFoundError) {
+			grlog.Infof("GlobalRole not found: %s", roleName)
+			return subreconciler.ContinueReconciling()
+		}
+		grlog.WithError(err).Errorf("Failed to delete global role: %s", roleName)
+		return subreconciler.RequeueWithError(err)
+	}
+
+	grlog.Infof("GlobalRole deleted: %s", roleName)
+	persescommon.RecordInstanceEvent(r.Recorder, globalrole, perses, corev1.EventTypeNormal, persescommon.EventReasonDeleted, "Global role deleted")
+
+	return subreconciler.ContinueReconciling()
+}
@@ -0,0 +1,352 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package globalroles
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	logger "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	operatormetrics "github.com/perses/perses-operator/internal/metrics"
+	"github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses-operator/internal/subreconciler"
+)
+
+type globalRoleContextKey string
+
+const contextKey globalRoleContextKey = "globalrole"
+
+func withGlobalRole(ctx context.Context, gv *persesv1alpha2.PersesGlobalRole) context.Context {
+	return context.WithValue(ctx, contextKey, gv)
+}
+
+func globalRoleFromContext(ctx context.Context) (*persesv1alpha2.PersesGlobalRole, bool) {
+	gv, ok := ctx.Value(contextKey).(*persesv1alpha2.PersesGlobalRole)
+	return gv, ok
+}
+
+// PersesGlobalRoleReconciler reconciles a PersesGlobalRole object
+type PersesGlobalRoleReconciler struct {
+	client.Client
+	APIReader             client.Reader // uncached reader — OnlyMetadata watch caches metadata only
+	Scheme                *runtime.Scheme
+	Recorder              record.EventRecorder
+	ClientFactory         common.PersesClientFactory
+	Metrics               *operatormetrics.Metrics
+	ReconciliationTracker *operatormetrics.ReconciliationTracker
+	// InstanceSyncConcurrency is the maximum number of Perses instances the global role
+	// is synced to at the same time. Lower than 1 syncs one instance at a time.
+	InstanceSyncConcurrency int
+	// DryRun only plans the changes to the global roles in Perses, without applying them,
+	// as for the global roles with the perses.dev/dry-run annotation.
+	DryRun bool
+}
+
+var log = logger.WithField("module", "perses_globalrole_controller")
+
+// +kubebuilder:rbac:groups=perses.dev,resources=persesglobalroles,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=perses.dev,resources=persesglobalroles/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=perses.dev,resources=persesglobalroles/finalizers,verbs=update
+func (r *PersesGlobalRoleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	start := time.Now()
+	objKey := req.String()
+
+	if r.Metrics != nil {
+		r.Metrics.ReconcileOperations("persesglobalrole").Inc()
+	}
+
+	log.Infof("Reconciling PersesGlobalRole: %s", req.Name)
+
+	// Deletion is handled through the finalizer, so a missing object has nothing left to clean up.
+	globalrole := &persesv1alpha2.PersesGlobalRole{}
+	if err := r.APIReader.Get(ctx, req.NamespacedName, globalrole); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Infof("perses global role resource not found. Ignoring since object must be deleted")
+			if r.ReconciliationTracker != nil {
+				r.ReconciliationTracker.ForgetObject(objKey)
+			}
+			if r.Metrics != nil {
+				r.Metrics.ForgetObject(objKey)
+			}
+			return subreconciler.Evaluate(subreconciler.DoNotRequeue())
+		}
+		log.WithError(err).Error("Failed to get perses global role")
+		if r.Metrics != nil {
+			r.Metrics.ReconcileErrors("persesglobalrole", "get_failed").Inc()
+		}
+		return subreconciler.Evaluate(subreconciler.RequeueWithError(err))
+	}
+
+	// Store global role in context for all sub-reconcilers
+	ctx = withGlobalRole(ctx, globalrole)
+
+	subreconcilersForPerses := []subreconciler.FnWithRequest{
+		r.handleDelete,
+		r.addFinalizer,
+		r.setStatusToUnknown,
+		r.reconcileGlobalRolesInAllInstances,
+		r.setStatusToComplete,
+	}
+
+	var reconcileErr error
+	for _, f := range subreconcilersForPerses {
+		if r, err := f(ctx, req); subreconciler.ShouldHaltOrRequeue(r, err) {
+			reconcileErr = err
+			break
+		}
+	}
+
+	// Track reconciliation status
+	if r.ReconciliationTracker != nil {
+		r.ReconciliationTracker.SetStatus(objKey, reconcileErr)
+		if reconcileErr == nil {
+			r.ReconciliationTracker.SetReasonAndMessage(objKey, "ReconciliationSuccessful", "GlobalRole reconciled successfully")
+		}
+	}
+
+	// Track metrics
+	if r.Metrics != nil {
+		if reconcileErr != nil {
+			reason := string(common.ExtractReason(reconcileErr, "reconciliation_failed"))
+			r.Metrics.ReconcileErrors("persesglobalrole", reason).Inc()
+			r.Metrics.SetFailedResources(objKey, "globalrole", "", 1)
+		} else {
+			r.Metrics.SetSyncedResources(objKey, "globalrole", "", 1)
+		}
+	}
+
+	if reconcileErr != nil {
+		return subreconciler.Evaluate(subreconciler.RequeueWithError(reconcileErr))
+	}
+
+	log.WithField("duration", time.Since(start)).Debug("global role reconciliation completed")
+	return subreconciler.Evaluate(subreconciler.DoNotRequeue())
+}
+
+// handleDelete removes the global role from the selected Perses instances, then releases the finalizer.
+func (r *PersesGlobalRoleReconciler) handleDelete(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	globalrole, ok := globalRoleFromContext(ctx)
+	if !ok {
+		log.Error("global role not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("global role not found in context"))
+	}
+
+	if globalrole.GetDeletionTimestamp() == nil {
+		return subreconciler.ContinueReconciling()
+	}
+
+	if !controllerutil.ContainsFinalizer(globalrole, common.PersesFinalizer) {
+		return subreconciler.DoNotRequeue()
+	}
+
+	if res, err := r.deleteGlobalRoleInAllInstances(ctx, req, globalrole); subreconciler.ShouldHaltOrRequeue(res, err) {
+		return res, err
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesGlobalRole{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		if !controllerutil.RemoveFinalizer(fresh, common.PersesFinalizer) {
+			return nil
+		}
+		return r.Update(ctx, fresh)
+	})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to remove finalizer from perses global role")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	log.Infof("PersesGlobalRole %s deleted", globalrole.Name)
+	return subreconciler.DoNotRequeue()
+}
+
+// addFinalizer makes sure the global role is not removed from the cluster before
+// it has been removed from the Perses instances it was synced to.
+func (r *PersesGlobalRoleReconciler) addFinalizer(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	globalrole, ok := globalRoleFromContext(ctx)
+	if !ok {
+		log.Error("global role not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("global role not found in context"))
+	}
+
+	if controllerutil.ContainsFinalizer(globalrole, common.PersesFinalizer) {
+		return subreconciler.ContinueReconciling()
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesGlobalRole{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		if !controllerutil.AddFinalizer(fresh, common.PersesFinalizer) {
+			return nil
+		}
+		return r.Update(ctx, fresh)
+	})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to add finalizer to perses global role")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesGlobalRoleReconciler) updateGlobalRoleStatus(
+	ctx context.Context,
+	req ctrl.Request,
+	updateFn func(*persesv1alpha2.PersesGlobalRole),
+) (*ctrl.Result, error) {
+	_, ok := globalRoleFromContext(ctx)
+	if !ok {
+		log.Error("global role not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("global role not found in context"))
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesGlobalRole{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		before := common.SnapshotConditions(fresh.Status.Conditions)
+		updateFn(fresh)
+		if !common.ConditionsChanged(before, fresh.Status.Conditions) {
+			return nil
+		}
+		return r.Status().Update(ctx, fresh)
+	})
+
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("perses global role resource not found. Ignoring since object must be deleted")
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to update Perses global role status")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesGlobalRoleReconciler) setStatusToUnknown(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	return r.updateGlobalRoleStatus(ctx, req, func(globalrole *persesv1alpha2.PersesGlobalRole) {
+		if len(globalrole.Status.Conditions) == 0 {
+			meta.SetStatusCondition(&globalrole.Status.Conditions, metav1.Condition{
+				Type: common.TypeAvailablePerses, Status: metav1.ConditionUnknown,
+				Reason: "Reconciling", Message: "Starting reconciliation"})
+		}
+	})
+}
+
+func (r *PersesGlobalRoleReconciler) setStatusToComplete(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	// The observed state is the one that was synced, the custom resource may have changed since.
+	synced, ok := globalRoleFromContext(ctx)
+	if !ok {
+		log.Error("global role not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("global role not found in context"))
+	}
+
+	// Nothing was applied in a dry run, so the availability of the global role is left as is.
+	if common.IsDryRun(synced, r.DryRun) {
+		return subreconciler.ContinueReconciling()
+	}
+
+	return r.updateGlobalRoleStatus(ctx, req, func(globalrole *persesv1alpha2.PersesGlobalRole) {
+		meta.RemoveStatusCondition(&globalrole.Status.Conditions, common.TypeDryRunPerses)
+		meta.SetStatusCondition(&globalrole.Status.Conditions, metav1.Condition{
+			Type: common.TypeDegradedPerses, Status: metav1.ConditionFalse,
+			Reason: "Reconciled", Message: fmt.Sprintf("GlobalRole (%s) reconciled successfully", globalrole.Name)})
+		meta.SetStatusCondition(&globalrole.Status.Conditions, metav1.Condition{
+			Type: common.TypeAvailablePerses, Status: metav1.ConditionTrue,
+			Reason: "Reconciled", Message: fmt.Sprintf("GlobalRole (%s) created successfully", globalrole.Name)})
+	})
+}
+
+func (r *PersesGlobalRoleReconciler) setStatusToDegraded(
+	ctx context.Context,
+	req ctrl.Request,
+	degradedResult *ctrl.Result,
+	degradedReason common.ConditionStatusReason,
+	degradedError error,
+) (*ctrl.Result, error) {
+	msg := "unknown error"
+	if degradedError != nil {
+		msg = degradedError.Error()
+	}
+
+	result, err := r.updateGlobalRoleStatus(ctx, req, func(globalrole *persesv1alpha2.PersesGlobalRole) {
+		meta.SetStatusCondition(&globalrole.Status.Conditions, metav1.Condition{
+			Type: common.TypeAvailablePerses, Status: metav1.ConditionFalse,
+			Reason: string(degradedReason), Message: msg})
+		meta.SetStatusCondition(&globalrole.Status.Conditions, metav1.Condition{
+			Type: common.TypeDegradedPerses, Status: metav1.ConditionTrue,
+			Reason: string(degradedReason), Message: msg})
+	})
+
+	if err != nil {
+		return result, err
+	}
+
+	if degradedError != nil {
+		return degradedResult, common.NewReasonError(degradedError, degradedReason)
+	}
+	return degradedResult, nil
+}
+
+// findGlobalRolesForPerses returns reconcile requests for all PersesGlobalRoles
+// when a Perses instance becomes available.
+// Global roles are cluster-scoped and their instanceSelector labels determine
+// which Perses instances they sync to. If no instanceSelector is set, they sync to all instances.
+func (r *PersesGlobalRoleReconciler) findGlobalRolesForPerses(ctx context.Context, _ client.Object) []reconcile.Request {
+	return common.MetadataListToRequests(ctx, r.Client, persesv1alpha2.GroupVersion.WithKind("PersesGlobalRoleList"))
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// It watches PersesGlobalRole resources and also watches Perses instances
+// to trigger re-reconciliation of all global roles when a Perses instance becomes
+// available. Global roles are matched to Perses instances via instanceSelector labels.
+// Create and delete events for Perses instances are ignored because the instance is not yet
+// ready at creation, and deletion is handled by the global role's own reconciliation loop.
+func (r *PersesGlobalRoleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&persesv1alpha2.PersesGlobalRole{}, builder.OnlyMetadata).
+		Watches(
+			&persesv1alpha2.Perses{},
+			handler.EnqueueRequestsFromMapFunc(r.findGlobalRolesForPerses),
+			builder.WithPredicates(common.PersesAvailabilityPredicate()),
+		).
+		Complete(r)
+}
@@ -0,0 +1,295 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package globalroles
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/perses/common/set"
+	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	internal "github.com/perses/perses-operator/internal/perses"
+	"github.com/perses/perses-operator/internal/perses/common"
+	persesclient "github.com/perses/perses/pkg/client/api/v1"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	persesv1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/role"
+	"github.com/stretchr/testify/mock"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func TestGlobalRoleController(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GlobalRole Controller Suite")
+}
+
+func newTestGlobalRoleReconciler(objects ...runtime.Object) *PersesGlobalRoleReconciler {
+	scheme := runtime.NewScheme()
+	Expect(persesv1alpha2.AddToScheme(scheme)).To(Succeed())
+
+	clientBuilder := fake.NewClientBuilder().WithScheme(scheme)
+	for _, obj := range objects {
+		clientBuilder = clientBuilder.WithRuntimeObjects(obj)
+	}
+	clientBuilder = clientBuilder.WithStatusSubresource(&persesv1alpha2.PersesGlobalRole{})
+
+	c := clientBuilder.Build()
+	return &PersesGlobalRoleReconciler{
+		Client:    c,
+		APIReader: c,
+		Scheme:    scheme,
+	}
+}
+
+var _ = Describe("GlobalRole controller", func() {
+	const RoleName = "admin"
+
+	newPerses := func(name string) *persesv1alpha2.Perses {
+		return &persesv1alpha2.Perses{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "perses-dev"},
+		}
+	}
+
+	Context("syncPersesGlobalRole", func() {
+		newRole := func() *persesv1alpha2.PersesGlobalRole {
+			return &persesv1alpha2.PersesGlobalRole{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: RoleName,
+				},
+				Spec: persesv1alpha2.RoleSpec{
+					Config: persesv1alpha2.Role{
+						RoleSpec: persesv1.RoleSpec{
+							Permissions: []role.Permission{{
+								Actions: []role.Action{role.ReadAction},
+								Scopes:  []role.Scope{role.GlobalDatasourceScope},
+							}},
+						},
+					},
+				},
+			}
+		}
+
+		expectedRole := func() *persesv1.GlobalRole {
+			return &persesv1.GlobalRole{
+				Kind: persesv1.KindGlobalRole,
+				Metadata: persesv1.Metadata{
+					Name: RoleName,
+					Tags: common.WithManagedTag(nil),
+				},
+				Spec: newRole().Spec.Config.RoleSpec,
+			}
+		}
+
+		It("should claim a global role of the same name created in Perses by hand", func() {
+			existing := expectedRole()
+			existing.Metadata.Tags = nil
+			existing.Spec.Permissions[0].Actions = []role.Action{role.WildcardAction}
+
+			mockPersesClient := &internal.MockClient{}
+			mockGlobalRole := &internal.MockGlobalRole{}
+			mockPersesClient.On("GlobalRole").Return(mockGlobalRole)
+			mockGlobalRole.On("Get", RoleName).Return(existing, nil)
+			mockGlobalRole.On("Update", expectedRole()).Return(expectedRole(), nil).Once()
+
+			recorder := record.NewFakeRecorder(10)
+			r := newTestGlobalRoleReconciler()
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+			r.Recorder = recorder
+
+			_, _, err := r.syncPersesGlobalRole(context.Background(), *newPerses("perses"), newRole())
+			Expect(err).ToNot(HaveOccurred())
+			mockGlobalRole.AssertExpectations(GinkgoT())
+			Expect(recorder.Events).To(Receive(And(
+				ContainSubstring(common.EventReasonUpdated),
+				ContainSubstring(common.ManagedTag),
+				ContainSubstring(`"path":"spec.permissions[0].actions[0]"`),
+			)))
+		})
+
+		It("should report a conflict and leave a global role not managed by the operator untouched", func() {
+			existing := expectedRole()
+			existing.Metadata.Tags = set.New("infra")
+
+			mockPersesClient := &internal.MockClient{}
+			mockGlobalRole := &internal.MockGlobalRole{}
+			mockPersesClient.On("GlobalRole").Return(mockGlobalRole)
+			mockGlobalRole.On("Get", RoleName).Return(existing, nil)
+
+			r := newTestGlobalRoleReconciler()
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			globalRole := newRole()
+			globalRole.Spec.ConflictPolicy = persesv1alpha2.ConflictPolicyFail
+			_, reason, err := r.syncPersesGlobalRole(context.Background(), *newPerses("perses"), globalRole)
+			Expect(err).To(HaveOccurred())
+			Expect(reason).To(Equal(common.ReasonConflict))
+			mockGlobalRole.AssertNotCalled(GinkgoT(), "Update", mock.Anything)
+		})
+
+		It("should only plan the creation of a global role in a dry run", func() {
+			mockPersesClient := &internal.MockClient{}
+			mockGlobalRole := &internal.MockGlobalRole{}
+			mockPersesClient.On("GlobalRole").Return(mockGlobalRole)
+			mockGlobalRole.On("Get", RoleName).Return(&persesv1.GlobalRole{}, perseshttp.RequestNotFoundError)
+
+			recorder := record.NewFakeRecorder(10)
+			r := newTestGlobalRoleReconciler()
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+			r.Recorder = recorder
+			r.DryRun = true
+
+			_, reason, err := r.syncPersesGlobalRole(context.Background(), *newPerses("perses"), newRole())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reason).To(Equal(common.ReasonDryRun))
+			mockGlobalRole.AssertNotCalled(GinkgoT(), "Create", expectedRole())
+			Expect(recorder.Events).To(Receive(ContainSubstring("Dry run: global role would be created")))
+		})
+	})
+
+	Context("handleDelete", func() {
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: RoleName}}
+
+		deletingGlobalRole := func() *persesv1alpha2.PersesGlobalRole {
+			return &persesv1alpha2.PersesGlobalRole{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              RoleName,
+					Finalizers:        []string{common.PersesFinalizer},
+					DeletionTimestamp: &metav1.Time{Time: time.Now()},
+				},
+				Spec: persesv1alpha2.RoleSpec{
+					InstanceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "observability"}},
+				},
+			}
+		}
+
+		persesGlobalRole := func(tags set.Set[string]) *persesv1.GlobalRole {
+			return &persesv1.GlobalRole{
+				Metadata: persesv1.Metadata{Name: RoleName, Tags: tags},
+			}
+		}
+
+		selected := map[string]string{"team": "observability"}
+
+		It("should only delete the global role from the selected Perses instances", func() {
+			deleting := deletingGlobalRole()
+			selectedClient := &internal.MockClient{}
+			otherClient := &internal.MockClient{}
+			mockGlobalRole := &internal.MockGlobalRole{}
+			selectedClient.On("GlobalRole").Return(mockGlobalRole)
+			mockGlobalRole.On("Get", RoleName).Return(persesGlobalRole(common.WithManagedTag(nil)), nil)
+			mockGlobalRole.On("Delete", RoleName).Return(nil).Once()
+
+			recorder := record.NewFakeRecorder(10)
+			r := newTestGlobalRoleReconciler(deleting, availablePerses("selected", selected), availablePerses("other", nil))
+			r.ClientFactory = instanceClientFactory{"selected": selectedClient, "other": otherClient}
+			r.Recorder = recorder
+
+			_, err := r.handleDelete(withGlobalRole(context.Background(), deleting), req)
+			Expect(err).ToNot(HaveOccurred())
+			mockGlobalRole.AssertExpectations(GinkgoT())
+			otherClient.AssertNotCalled(GinkgoT(), "GlobalRole")
+			Expect(recorder.Events).To(Receive(ContainSubstring("Perses instance perses-dev/selected: Global role deleted")))
+
+			err = r.Get(context.Background(), req.NamespacedName, &persesv1alpha2.PersesGlobalRole{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should keep a global role not managed by the operator in Perses", func() {
+			deleting := deletingGlobalRole()
+			mockPersesClient := &internal.MockClient{}
+			mockGlobalRole := &internal.MockGlobalRole{}
+			mockPersesClient.On("GlobalRole").Return(mockGlobalRole)
+			mockGlobalRole.On("Get", RoleName).Return(persesGlobalRole(nil), nil)
+
+			r := newTestGlobalRoleReconciler(deleting, availablePerses("perses", selected))
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			_, err := r.handleDelete(withGlobalRole(context.Background(), deleting), req)
+			Expect(err).ToNot(HaveOccurred())
+			mockGlobalRole.AssertNotCalled(GinkgoT(), "Delete", RoleName)
+
+			err = r.Get(context.Background(), req.NamespacedName, &persesv1alpha2.PersesGlobalRole{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should ignore a global role already deleted from Perses", func() {
+			deleting := deletingGlobalRole()
+			mockPersesClient := &internal.MockClient{}
+			mockGlobalRole := &internal.MockGlobalRole{}
+			mockPersesClient.On("GlobalRole").Return(mockGlobalRole)
+			mockGlobalRole.On("Get", RoleName).Return(&persesv1.GlobalRole{}, perseshttp.RequestNotFoundError)
+
+			r := newTestGlobalRoleReconciler(deleting, availablePerses("perses", selected))
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			res, err := r.handleDelete(withGlobalRole(context.Background(), deleting), req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(&ctrl.Result{}))
+			mockGlobalRole.AssertNotCalled(GinkgoT(), "Delete", RoleName)
+		})
+
+		It("should keep the finalizer while a selected Perses instance is not available", func() {
+			deleting := deletingGlobalRole()
+			mockPersesClient := &internal.MockClient{}
+			unavailable := newPerses("perses")
+			unavailable.Labels = selected
+
+			recorder := record.NewFakeRecorder(10)
+			r := newTestGlobalRoleReconciler(deleting, unavailable)
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+			r.Recorder = recorder
+
+			res, err := r.handleDelete(withGlobalRole(context.Background(), deleting), req)
+			Expect(common.ExtractReason(err, "")).To(Equal(common.ReasonDeletionBlocked))
+			Expect(res.RequeueAfter).To(Equal(time.Minute))
+			Expect(recorder.Events).To(Receive(ContainSubstring("Global role deletion is waiting for the instance to be available")))
+			mockPersesClient.AssertNotCalled(GinkgoT(), "GlobalRole")
+
+			fresh := &persesv1alpha2.PersesGlobalRole{}
+			Expect(r.Get(context.Background(), req.NamespacedName, fresh)).To(Succeed())
+			Expect(controllerutil.ContainsFinalizer(fresh, common.PersesFinalizer)).To(BeTrue())
+		})
+	})
+})
+
+func availablePerses(name string, labels map[string]string) *persesv1alpha2.Perses {
+	return &persesv1alpha2.Perses{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "perses-dev", Labels: labels},
+		Status: persesv1alpha2.PersesStatus{
+			Conditions: []metav1.Condition{{Type: common.TypeAvailablePerses, Status: metav1.ConditionTrue}},
+		},
+	}
+}
+
+// instanceClientFactory returns the Perses client of each instance by name.
+type instanceClientFactory map[string]persesclient.ClientInterface
+
+func (f instanceClientFactory) CreateClient(_ context.Context, _ client.Reader, perses persesv1alpha2.Perses) (persesclient.ClientInterface, error) {
+	if persesClient, ok := f[perses.Name]; ok {
+		return persesClient, nil
+	}
+	return nil, fmt.Errorf("connection refused")
+}
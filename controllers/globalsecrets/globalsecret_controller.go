@@ -0,0 +1,292 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package globalsecrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/perses/common/set"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	persesv1 "github.com/perses/perses/pkg/model/api/v1"
+	logger "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	persescommon "github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses-operator/internal/subreconciler"
+)
+
+var gseclog = logger.WithField("module", "globalsecret_controller")
+
+func (r *PersesGlobalSecretReconciler) reconcileGlobalSecretsInAllInstances(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	globalsecret, ok := globalSecretFromContext(ctx)
+	if !ok {
+		gseclog.Error("global secret not found in context")
+		res, err := subreconciler.RequeueWithError(fmt.Errorf("global secret not found in context"))
+		return r.setStatusToDegraded(ctx, req, res, persescommon.ReasonMissingResource, err)
+	}
+
+	persesInstances, err := r.listSelectedInstances(ctx, globalsecret)
+	if err != nil {
+		gseclog.WithError(err).Error("Failed to get perses instances")
+		res, err := subreconciler.RequeueWithError(err)
+		return r.setStatusToDegraded(ctx, req, res, persescommon.ReasonMissingPerses, err)
+	}
+
+	if len(persesInstances.Items) == 0 {
+		gseclog.Info("No Perses instances found, requeue in 1 minute")
+		return r.setStatusToDegraded(ctx, req, &ctrl.Result{RequeueAfter: time.Minute}, persescommon.ReasonMissingPerses, fmt.Errorf("no Perses instances found matching the label selector"))
+	}
+
+	var planned []string
+	for _, persesInstance := range persesInstances.Items {
+		if !meta.IsStatusConditionTrue(persesInstance.Status.Conditions, persescommon.TypeAvailablePerses) {
+			gseclog.Infof("Skipping Perses instance %s/%s (not yet available)", persesInstance.Namespace, persesInstance.Name)
+			continue
+		}
+		res, reason, err := r.syncPersesGlobalSecret(ctx, persesInstance, globalsecret)
+		if subreconciler.ShouldHaltOrRequeue(res, err) {
+			persescommon.RecordInstanceEvent(r.Recorder, globalsecret, persesInstance, corev1.EventTypeWarning, string(reason), "Failed to sync global secret: %v", err)
+			return r.setStatusToDegraded(ctx, req, res, reason, err)
+		}
+		if reason == persescommon.ReasonDryRun {
+			planned = append(planned, fmt.Sprintf("%s/%s", persesInstance.Namespace, persesInstance.Name))
+		}
+	}
+
+	if persescommon.IsDryRun(globalsecret, r.DryRun) {
+		return r.updateGlobalSecretStatus(ctx, req, func(globalsecret *persesv1alpha2.PersesGlobalSecret) {
+			meta.SetStatusCondition(&globalsecret.Status.Conditions, persescommon.DryRunCondition("GlobalSecret", globalsecret.Name, planned))
+		})
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesGlobalSecretReconciler) listSelectedInstances(ctx context.Context, globalsecret *persesv1alpha2.PersesGlobalSecret) (*persesv1alpha2.PersesList, error) {
+	var labelSelector labels.Selector
+	if globalsecret.Spec.InstanceSelector == nil {
+		labelSelector = labels.Everything()
+	} else {
+		var err error
+		labelSelector, err = metav1.LabelSelectorAsSelector(globalsecret.Spec.InstanceSelector)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	persesInstances := &persesv1alpha2.PersesList{}
+	opts := &client.ListOptions{
+		LabelSelector: labelSelector,
+	}
+	if err := r.List(ctx, persesInstances, opts); err != nil {
+		return nil, err
+	}
+	return persesInstances, nil
+}
+
+func (r *PersesGlobalSecretReconciler) syncPersesGlobalSecret(ctx context.Context, perses persesv1alpha2.Perses, globalsecret *persesv1alpha2.PersesGlobalSecret) (*ctrl.Result, persescommon.ConditionStatusReason, error) {
+	persesClient, err := r.ClientFactory.CreateClient(ctx, r.APIReader, perses)
+
+	if err != nil {
+		gseclog.WithError(err).Error("Failed to create perses rest client")
+		return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonConnectionFailed)
+
+	}
+
+	secretSpec, reason, err := persescommon.SecretSpecFromClient(ctx, r.APIReader, "", globalsecret.Name, &globalsecret.Spec.Client)
+	if err != nil {
+		return subreconciler.RequeueWithErrorAndReason(err, reason)
+	}
+
+	existing, err := persesClient.GlobalSecret().Get(globalsecret.Name)
+	notFound := err != nil && errors.Is(err, perseshttp.RequestNotFoundError)
+
+	if err != nil && !notFound {
+		res, err := subreconciler.RequeueWithError(err)
+		return res, persescommon.ReasonBackendError, err
+	}
+
+	var existingTags set.Set[string]
+	if !notFound {
+		existingTags = existing.Metadata.Tags
+	}
+	managed, err := persescommon.ClaimOwnership(globalsecret.Spec.ConflictPolicy, "global secret", globalsecret.Name, !notFound, existingTags)
+	if err != nil {
+		gseclog.WithError(err).Errorf("GlobalSecret conflict: %s", globalsecret.Name)
+		return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonConflict)
+	}
+
+	var tags set.Set[string]
+	if managed {
+		tags = persescommon.WithManagedTag(nil)
+	}
+
+	globalSecretWithName := &persesv1.GlobalSecret{
+		Kind: persesv1.KindGlobalSecret,
+		Metadata: persesv1.Metadata{
+			Name: globalsecret.Name,
+			Tags: tags,
+		},
+		Spec: secretSpec,
+	}
+
+	secretKey := persescommon.SecretContentKey(perses, "", globalsecret.Name)
+
+	// A global secret adopted by the operator is written again to carry the ManagedTag.
+	if !notFound && r.secrets.InSync(secretKey, secretSpec) && persescommon.IsManaged(existingTags) == managed {
+		gseclog.Debugf("GlobalSecret already in sync: %s", globalsecret.Name)
+		res, err := subreconciler.ContinueReconciling()
+		return res, "", err
+	}
+
+	dryRun := persescommon.IsDryRun(globalsecret, r.DryRun)
+	if dryRun {
+		plan := "created"
+		if !notFound {
+			plan = "updated"
+		}
+		gseclog.Infof("Dry run, global secret %s would be %s in Perses instance %s/%s", globalsecret.Name, plan, perses.Namespace, perses.Name)
+		persescommon.RecordInstanceEvent(r.Recorder, globalsecret, perses, corev1.EventTypeNormal, string(persescommon.ReasonDryRun), "Dry run: global secret would be %s", plan)
+		res, err := subreconciler.ContinueReconciling()
+		return res, persescommon.ReasonDryRun, err
+	}
+
+	if notFound {
+		_, err = persesClient.GlobalSecret().Create(globalSecretWithName)
+		if err != nil {
+			gseclog.WithError(err).Errorf("Failed to create global secret: %s", globalsecret.Name)
+			return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonBackendError)
+		}
+		r.secrets.Record(secretKey, secretSpec)
+		gseclog.Infof("GlobalSecret created: %s", globalsecret.Name)
+		persescommon.RecordInstanceEvent(r.Recorder, globalsecret, perses, corev1.EventTypeNormal, persescommon.EventReasonCreated, "Global secret created")
+	} else {
+		_, err = persesClient.GlobalSecret().Update(globalSecretWithName)
+		if err != nil {
+			gseclog.WithError(err).Errorf("Failed to update global secret: %s", globalsecret.Name)
+			return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonBackendError)
+		}
+		r.secrets.Record(secretKey, secretSpec)
+		gseclog.Infof("GlobalSecret updated: %s", globalsecret.Name)
+		persescommon.RecordInstanceEvent(r.Recorder, globalsecret, perses, corev1.EventTypeNormal, persescommon.EventReasonUpdated, "Global secret updated")
+	}
+
+	res, err := subreconciler.ContinueReconciling()
+	return res, "", err
+}
+
+// deleteGlobalSecretInAllInstances removes the global secret from every Perses instance selected by the
+// PersesGlobalSecret. The deletion is blocked while a PersesGlobalDatasource still references the secret.
+func (r *PersesGlobalSecretReconciler) deleteGlobalSecretInAllInstances(ctx context.Context, req ctrl.Request, globalsecret *persesv1alpha2.PersesGlobalSecret) (*ctrl.Result, error) {
+	referencing, err := r.globalDatasourcesReferencing(ctx, globalsecret)
+	if err != nil {
+		gseclog.WithError(err).Error("Failed to list perses global datasources")
+		return subreconciler.RequeueWithError(err)
+	}
+	if len(referencing) > 0 {
+		gseclog.Infof("GlobalSecret %s is still referenced by global datasources, deletion is blocked", globalsecret.Name)
+		persescommon.RecordEvent(r.Recorder, globalsecret, corev1.EventTypeWarning, string(persescommon.ReasonDeletionBlocked), "Global secret is still referenced by global datasources: %s", strings.Join(referencing, ", "))
+		return r.setStatusToDegraded(ctx, req, &ctrl.Result{RequeueAfter: time.Minute}, persescommon.ReasonDeletionBlocked,
+			fmt.Errorf("global secret is still referenced by PersesGlobalDatasources: %s", strings.Join(referencing, ", ")))
+	}
+
+	persesInstances, err := r.listSelectedInstances(ctx, globalsecret)
+	if err != nil {
+		gseclog.WithError(err).Error("Failed to get perses instances")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	for _, persesInstance := range persesInstances.Items {
+		if r, err := r.deleteGlobalSecret(ctx, persesInstance, globalsecret); subreconciler.ShouldHaltOrRequeue(r, err) {
+			return r, err
+		}
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+// globalDatasourcesReferencing returns the PersesGlobalDatasources that reference
+// the global secret through their secretRef.
+func (r *PersesGlobalSecretReconciler) globalDatasourcesReferencing(ctx context.Context, globalsecret *persesv1alpha2.PersesGlobalSecret) ([]string, error) {
+	globaldatasources := &persesv1alpha2.PersesGlobalDatasourceList{}
+	if err := r.APIReader.List(ctx, globaldatasources); err != nil {
+		return nil, err
+	}
+
+	var referencing []string
+	for _, globaldatasource := range globaldatasources.Items {
+		if globaldatasource.Spec.SecretRef != nil && globaldatasource.Spec.SecretRef.Name == globalsecret.Name {
+			referencing = append(referencing, globaldatasource.Name)
+		}
+	}
+	return referencing, nil
+}
+
+func (r *PersesGlobalSecretReconciler) deleteGlobalSecret(ctx context.Context, perses persesv1alpha2.Perses, globalsecret *persesv1alpha2.PersesGlobalSecret) (*ctrl.Result, error) {
+	secretName := globalsecret.Name
+	secretKey := persescommon.SecretContentKey(perses, "", secretName)
+	persesClient, err := r.ClientFactory.CreateClient(ctx, r.APIReader, perses)
+
+	if err != nil {
+		gseclog.WithError(err).Error("Failed to create perses rest client")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	// Ignore NotFound — the resource may have already been deleted from Perses directly.
+	// Any other error means the delete failed and should be retried.
+	existing, err := persesClient.GlobalSecret().Get(secretName)
+
+	switch {
+	case err != nil && errors.Is(err, perseshttp.RequestNotFoundError):
+		gseclog.Infof("GlobalSecret not found: %s", secretName)
+		r.secrets.Forget(secretKey)
+		return subreconciler.ContinueReconciling()
+	case err != nil:
+		gseclog.WithError(err).Errorf("Failed to get global secret: %s", secretName)
+		return subreconciler.RequeueWithError(err)
+	case !persescommon.IsManaged(existing.Metadata.Tags):
+		gseclog.Infof("GlobalSecret not managed by the operator, keeping it: %s", secretName)
+		r.secrets.Forget(secretKey)
+		return subreconciler.ContinueReconciling()
+	}
+
+	if persescommon.IsDryRun(globalsecret, r.DryRun) {
+		gseclog.Infof("Dry run, global secret %s would be deleted from Perses instance %s/%s", secretName, perses.Namespace, perses.Name)
+		persescommon.RecordInstanceEvent(r.Recorder, globalsecret, perses, corev1.EventTypeNormal, string(persescommon.ReasonDryRun), "Dry run: global secret would be deleted")
+		return subreconciler.ContinueReconciling()
+	}
+
+	err = persesClient.GlobalSecret().Delete(secretName)
+	if err != nil && !errors.Is(err, perseshttp.RequestNotFoundError) {
+		gseclog.WithError(err).Errorf("Failed to delete global secret: %s", secretName)
+		persescommon.RecordInstanceEvent(r.Recorder, globalsecret, perses, corev1.EventTypeWarning, string(persescommon.ReasonBackendError), "Failed to delete global secret: %v", err)
+		return subreconciler.RequeueWithError(err)
+	}
+
+	r.secrets.Forget(secretKey)
+	gseclog.Infof("GlobalSecret deleted: %s", secretName)
+	persescommon.RecordInstanceEvent(r.Recorder, globalsecret, perses, corev1.EventTypeNormal, persescommon.EventReasonDeleted, "Global secret deleted")
+
+	return subreconciler.ContinueReconciling()
+}
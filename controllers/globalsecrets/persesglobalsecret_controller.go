@@ -0,0 +1,412 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package globalsecrets
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	logger "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	internalcache "github.com/perses/perses-operator/internal/cache"
+	operatormetrics "github.com/perses/perses-operator/internal/metrics"
+	"github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses-operator/internal/subreconciler"
+)
+
+type globalSecretContextKey string
+
+const contextKey globalSecretContextKey = "globalsecret"
+
+func withGlobalSecret(ctx context.Context, gv *persesv1alpha2.PersesGlobalSecret) context.Context {
+	return context.WithValue(ctx, contextKey, gv)
+}
+
+func globalSecretFromContext(ctx context.Context) (*persesv1alpha2.PersesGlobalSecret, bool) {
+	gv, ok := ctx.Value(contextKey).(*persesv1alpha2.PersesGlobalSecret)
+	return gv, ok
+}
+
+// PersesGlobalSecretReconciler reconciles a PersesGlobalSecret object
+type PersesGlobalSecretReconciler struct {
+	client.Client
+	APIReader             client.Reader // uncached reader — OnlyMetadata watch caches metadata only
+	Scheme                *runtime.Scheme
+	Recorder              record.EventRecorder
+	ClientFactory         common.PersesClientFactory
+	Metrics               *operatormetrics.Metrics
+	ReconciliationTracker *operatormetrics.ReconciliationTracker
+	// DryRun only plans the changes to the global secrets in Perses, without applying them,
+	// as for the global secrets with the perses.dev/dry-run annotation.
+	DryRun bool
+	// ConfigMapCache holds the metadata of the ConfigMaps labeled for watching.
+	// When nil, changes to referenced ConfigMaps do not trigger reconciliation.
+	ConfigMapCache cache.Cache
+
+	secrets common.SecretContentTracker
+}
+
+var log = logger.WithField("module", "perses_globalsecret_controller")
+
+// +kubebuilder:rbac:groups=perses.dev,resources=persesglobalsecrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=perses.dev,resources=persesglobalsecrets/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=perses.dev,resources=persesglobalsecrets/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=configmaps;secrets,verbs=get;list;watch
+func (r *PersesGlobalSecretReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	start := time.Now()
+	objKey := req.String()
+
+	if r.Metrics != nil {
+		r.Metrics.ReconcileOperations("persesglobalsecret").Inc()
+	}
+
+	log.Infof("Reconciling PersesGlobalSecret: %s", req.Name)
+
+	// Deletion is handled through the finalizer, so a missing object has nothing left to clean up.
+	globalsecret := &persesv1alpha2.PersesGlobalSecret{}
+	if err := r.APIReader.Get(ctx, req.NamespacedName, globalsecret); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Infof("perses global secret resource not found. Ignoring since object must be deleted")
+			if r.ReconciliationTracker != nil {
+				r.ReconciliationTracker.ForgetObject(objKey)
+			}
+			if r.Metrics != nil {
+				r.Metrics.ForgetObject(objKey)
+			}
+			return subreconciler.Evaluate(subreconciler.DoNotRequeue())
+		}
+		log.WithError(err).Error("Failed to get perses global secret")
+		if r.Metrics != nil {
+			r.Metrics.ReconcileErrors("persesglobalsecret", "get_failed").Inc()
+		}
+		return subreconciler.Evaluate(subreconciler.RequeueWithError(err))
+	}
+
+	// Store global secret in context for all sub-reconcilers
+	ctx = withGlobalSecret(ctx, globalsecret)
+
+	subreconcilersForPerses := []subreconciler.FnWithRequest{
+		r.handleDelete,
+		r.addFinalizer,
+		r.setStatusToUnknown,
+		r.reconcileGlobalSecretsInAllInstances,
+		r.setStatusToComplete,
+	}
+
+	var reconcileErr error
+	for _, f := range subreconcilersForPerses {
+		if r, err := f(ctx, req); subreconciler.ShouldHaltOrRequeue(r, err) {
+			reconcileErr = err
+			break
+		}
+	}
+
+	// Track reconciliation status
+	if r.ReconciliationTracker != nil {
+		r.ReconciliationTracker.SetStatus(objKey, reconcileErr)
+		if reconcileErr == nil {
+			r.ReconciliationTracker.SetReasonAndMessage(objKey, "ReconciliationSuccessful", "GlobalSecret reconciled successfully")
+		}
+	}
+
+	// Track metrics
+	if r.Metrics != nil {
+		if reconcileErr != nil {
+			reason := string(common.ExtractReason(reconcileErr, "reconciliation_failed"))
+			r.Metrics.ReconcileErrors("persesglobalsecret", reason).Inc()
+			r.Metrics.SetFailedResources(objKey, "globalsecret", "", 1)
+		} else {
+			r.Metrics.SetSyncedResources(objKey, "globalsecret", "", 1)
+		}
+	}
+
+	if reconcileErr != nil {
+		return subreconciler.Evaluate(subreconciler.RequeueWithError(reconcileErr))
+	}
+
+	log.WithField("duration", time.Since(start)).Debug("global secret reconciliation completed")
+	return subreconciler.Evaluate(subreconciler.DoNotRequeue())
+}
+
+// handleDelete removes the global secret from the selected Perses instances, then releases the finalizer.
+func (r *PersesGlobalSecretReconciler) handleDelete(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	globalsecret, ok := globalSecretFromContext(ctx)
+	if !ok {
+		log.Error("global secret not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("global secret not found in context"))
+	}
+
+	if globalsecret.GetDeletionTimestamp() == nil {
+		return subreconciler.ContinueReconciling()
+	}
+
+	if !controllerutil.ContainsFinalizer(globalsecret, common.PersesFinalizer) {
+		return subreconciler.DoNotRequeue()
+	}
+
+	if res, err := r.deleteGlobalSecretInAllInstances(ctx, req, globalsecret); subreconciler.ShouldHaltOrRequeue(res, err) {
+		return res, err
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesGlobalSecret{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		if !controllerutil.RemoveFinalizer(fresh, common.PersesFinalizer) {
+			return nil
+		}
+		return r.Update(ctx, fresh)
+	})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to remove finalizer from perses global secret")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	log.Infof("PersesGlobalSecret %s deleted", globalsecret.Name)
+	return subreconciler.DoNotRequeue()
+}
+
+// addFinalizer makes sure the global secret is not removed from the cluster before
+// it has been removed from the Perses instances it was synced to.
+func (r *PersesGlobalSecretReconciler) addFinalizer(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	globalsecret, ok := globalSecretFromContext(ctx)
+	if !ok {
+		log.Error("global secret not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("global secret not found in context"))
+	}
+
+	if controllerutil.ContainsFinalizer(globalsecret, common.PersesFinalizer) {
+		return subreconciler.ContinueReconciling()
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesGlobalSecret{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		if !controllerutil.AddFinalizer(fresh, common.PersesFinalizer) {
+			return nil
+		}
+		return r.Update(ctx, fresh)
+	})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to add finalizer to perses global secret")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesGlobalSecretReconciler) updateGlobalSecretStatus(
+	ctx context.Context,
+	req ctrl.Request,
+	updateFn func(*persesv1alpha2.PersesGlobalSecret),
+) (*ctrl.Result, error) {
+	_, ok := globalSecretFromContext(ctx)
+	if !ok {
+		log.Error("global secret not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("global secret not found in context"))
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesGlobalSecret{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		before := common.SnapshotConditions(fresh.Status.Conditions)
+		updateFn(fresh)
+		if !common.ConditionsChanged(before, fresh.Status.Conditions) {
+			return nil
+		}
+		return r.Status().Update(ctx, fresh)
+	})
+
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("perses global secret resource not found. Ignoring since object must be deleted")
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to update Perses global secret status")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesGlobalSecretReconciler) setStatusToUnknown(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	return r.updateGlobalSecretStatus(ctx, req, func(globalsecret *persesv1alpha2.PersesGlobalSecret) {
+		if len(globalsecret.Status.Conditions) == 0 {
+			meta.SetStatusCondition(&globalsecret.Status.Conditions, metav1.Condition{
+				Type: common.TypeAvailablePerses, Status: metav1.ConditionUnknown,
+				Reason: "Reconciling", Message: "Starting reconciliation"})
+		}
+	})
+}
+
+func (r *PersesGlobalSecretReconciler) setStatusToComplete(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	// The observed state is the one that was synced, the custom resource may have changed since.
+	synced, ok := globalSecretFromContext(ctx)
+	if !ok {
+		log.Error("global secret not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("global secret not found in context"))
+	}
+
+	// Nothing was applied in a dry run, so the availability of the global secret is left as is.
+	if common.IsDryRun(synced, r.DryRun) {
+		return subreconciler.ContinueReconciling()
+	}
+
+	return r.updateGlobalSecretStatus(ctx, req, func(globalsecret *persesv1alpha2.PersesGlobalSecret) {
+		meta.RemoveStatusCondition(&globalsecret.Status.Conditions, common.TypeDryRunPerses)
+		meta.SetStatusCondition(&globalsecret.Status.Conditions, metav1.Condition{
+			Type: common.TypeDegradedPerses, Status: metav1.ConditionFalse,
+			Reason: "Reconciled", Message: fmt.Sprintf("GlobalSecret (%s) reconciled successfully", globalsecret.Name)})
+		meta.SetStatusCondition(&globalsecret.Status.Conditions, metav1.Condition{
+			Type: common.TypeAvailablePerses, Status: metav1.ConditionTrue,
+			Reason: "Reconciled", Message: fmt.Sprintf("GlobalSecret (%s) created successfully", globalsecret.Name)})
+	})
+}
+
+func (r *PersesGlobalSecretReconciler) setStatusToDegraded(
+	ctx context.Context,
+	req ctrl.Request,
+	degradedResult *ctrl.Result,
+	degradedReason common.ConditionStatusReason,
+	degradedError error,
+) (*ctrl.Result, error) {
+	msg := "unknown error"
+	if degradedError != nil {
+		msg = degradedError.Error()
+	}
+
+	result, err := r.updateGlobalSecretStatus(ctx, req, func(globalsecret *persesv1alpha2.PersesGlobalSecret) {
+		meta.SetStatusCondition(&globalsecret.Status.Conditions, metav1.Condition{
+			Type: common.TypeAvailablePerses, Status: metav1.ConditionFalse,
+			Reason: string(degradedReason), Message: msg})
+		meta.SetStatusCondition(&globalsecret.Status.Conditions, metav1.Condition{
+			Type: common.TypeDegradedPerses, Status: metav1.ConditionTrue,
+			Reason: string(degradedReason), Message: msg})
+	})
+
+	if err != nil {
+		return result, err
+	}
+
+	if degradedError != nil {
+		return degradedResult, common.NewReasonError(degradedError, degradedReason)
+	}
+	return degradedResult, nil
+}
+
+// findGlobalSecretsForPerses returns reconcile requests for all PersesGlobalSecrets
+// when a Perses instance becomes available.
+// Global secrets are cluster-scoped and their instanceSelector labels determine
+// which Perses instances they sync to. If no instanceSelector is set, they sync to all instances.
+func (r *PersesGlobalSecretReconciler) findGlobalSecretsForPerses(ctx context.Context, _ client.Object) []reconcile.Request {
+	return common.MetadataListToRequests(ctx, r.Client, persesv1alpha2.GroupVersion.WithKind("PersesGlobalSecretList"))
+}
+
+// findGlobalSecretsForSecret returns reconcile requests for the PersesGlobalSecrets whose client
+// configuration reads credentials or certificates from the changed Secret.
+func (r *PersesGlobalSecretReconciler) findGlobalSecretsForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	return r.findGlobalSecretsReferencing(ctx, persesv1alpha2.SecretSourceTypeSecret, obj)
+}
+
+// findGlobalSecretsForConfigMap returns reconcile requests for the PersesGlobalSecrets whose client
+// configuration reads credentials or certificates from the changed ConfigMap.
+func (r *PersesGlobalSecretReconciler) findGlobalSecretsForConfigMap(ctx context.Context, obj *metav1.PartialObjectMetadata) []reconcile.Request {
+	return r.findGlobalSecretsReferencing(ctx, persesv1alpha2.SecretSourceTypeConfigMap, obj)
+}
+
+func (r *PersesGlobalSecretReconciler) findGlobalSecretsReferencing(ctx context.Context, sourceType persesv1alpha2.SecretSourceType, obj client.Object) []reconcile.Request {
+	// The cache only holds the metadata of the global secrets, the client configuration is read via APIReader.
+	secretList := &persesv1alpha2.PersesGlobalSecretList{}
+	if err := r.APIReader.List(ctx, secretList); err != nil {
+		log.WithError(err).Errorf("failed to list PersesGlobalSecrets for %s %s/%s", sourceType, obj.GetNamespace(), obj.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, globalsecret := range secretList.Items {
+		if common.ClientReferences(&globalsecret.Spec.Client, "", sourceType, obj) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name: globalsecret.Name,
+				},
+			})
+		}
+	}
+
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// It watches PersesGlobalSecret resources and also watches Perses instances
+// to trigger re-reconciliation of all global secrets when a Perses instance becomes
+// available. Global secrets are matched to Perses instances via instanceSelector labels.
+// Create and delete events for Perses instances are ignored because the instance is not yet
+// ready at creation, and deletion is handled by the global secret's own reconciliation loop.
+// It also watches the Secrets and, when ConfigMapCache is set, the ConfigMaps referenced by the
+// client configuration, so that rotated credentials are pushed to Perses. Only the Secrets and
+// ConfigMaps matching the watch label selector of their cache trigger reconciliation.
+func (r *PersesGlobalSecretReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&persesv1alpha2.PersesGlobalSecret{}, builder.OnlyMetadata).
+		Watches(
+			&persesv1alpha2.Perses{},
+			handler.EnqueueRequestsFromMapFunc(r.findGlobalSecretsForPerses),
+			builder.WithPredicates(common.PersesAvailabilityPredicate()),
+		).
+		// WatchesMetadata only caches metadata (not Data) to reduce memory.
+		// The referenced data is read via APIReader when the global secret is reconciled.
+		WatchesMetadata(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.findGlobalSecretsForSecret),
+		)
+
+	if r.ConfigMapCache != nil {
+		b = b.WatchesRawSource(source.Kind(
+			r.ConfigMapCache,
+			internalcache.ConfigMapMetadata(),
+			handler.TypedEnqueueRequestsFromMapFunc(r.findGlobalSecretsForConfigMap),
+		))
+	}
+
+	return b.Complete(r)
+}
@@ -0,0 +1,316 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package globalsecrets
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/perses/common/set"
+	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	internal "github.com/perses/perses-operator/internal/perses"
+	"github.com/perses/perses-operator/internal/perses/common"
+	v1 "github.com/perses/perses/pkg/client/api/v1"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	persesv1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/secret"
+	specdatasource "github.com/perses/spec/go/datasource"
+	specplugin "github.com/perses/spec/go/plugin"
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func TestGlobalSecretController(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GlobalSecret Controller Suite")
+}
+
+func newTestGlobalSecretReconciler(objects ...runtime.Object) *PersesGlobalSecretReconciler {
+	scheme := runtime.NewScheme()
+	Expect(persesv1alpha2.AddToScheme(scheme)).To(Succeed())
+	Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	clientBuilder := fake.NewClientBuilder().WithScheme(scheme)
+	for _, obj := range objects {
+		clientBuilder = clientBuilder.WithRuntimeObjects(obj)
+	}
+	clientBuilder = clientBuilder.WithStatusSubresource(&persesv1alpha2.PersesGlobalSecret{})
+
+	c := clientBuilder.Build()
+	return &PersesGlobalSecretReconciler{
+		Client:    c,
+		APIReader: c,
+		Scheme:    scheme,
+	}
+}
+
+var _ = Describe("GlobalSecret controller", func() {
+	Context("syncPersesGlobalSecret", func() {
+		const SecretName = "thanos-credentials"
+
+		credentials := func() *corev1.Secret {
+			return &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "thanos-basic-auth",
+					Namespace: "monitoring",
+				},
+				Data: map[string][]byte{"password": []byte("s3cr3t")},
+			}
+		}
+
+		newSecret := func() *persesv1alpha2.PersesGlobalSecret {
+			name := "thanos-basic-auth"
+			namespace := "monitoring"
+			return &persesv1alpha2.PersesGlobalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: SecretName,
+				},
+				Spec: persesv1alpha2.SecretSpec{
+					Client: persesv1alpha2.Client{
+						BasicAuth: &persesv1alpha2.BasicAuth{
+							SecretSource: persesv1alpha2.SecretSource{
+								Type:      persesv1alpha2.SecretSourceTypeSecret,
+								Name:      &name,
+								Namespace: &namespace,
+							},
+							Username:     "thanos",
+							PasswordPath: "password",
+						},
+					},
+				},
+			}
+		}
+
+		expectedSecret := func() *persesv1.GlobalSecret {
+			return &persesv1.GlobalSecret{
+				Kind: persesv1.KindGlobalSecret,
+				Metadata: persesv1.Metadata{
+					Name: SecretName,
+					Tags: common.WithManagedTag(nil),
+				},
+				Spec: persesv1.SecretSpec{
+					BasicAuth: &secret.BasicAuth{
+						Username: "thanos",
+						Password: "s3cr3t",
+					},
+				},
+			}
+		}
+
+		It("should create the global secret with the referenced credentials", func() {
+			mockPersesClient := &internal.MockClient{}
+			mockGlobalSecret := &internal.MockGlobalSecret{}
+			mockPersesClient.On("GlobalSecret").Return(mockGlobalSecret)
+			mockGlobalSecret.On("Get", SecretName).Return(&persesv1.GlobalSecret{}, perseshttp.RequestNotFoundError)
+			mockGlobalSecret.On("Create", expectedSecret()).Return(expectedSecret(), nil)
+
+			r := newTestGlobalSecretReconciler(credentials())
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			res, reason, err := r.syncPersesGlobalSecret(context.Background(), persesv1alpha2.Perses{}, newSecret())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(BeNil())
+			Expect(reason).To(BeEmpty())
+			mockGlobalSecret.AssertExpectations(GinkgoT())
+		})
+
+		It("should claim a global secret of the same name created in Perses by hand", func() {
+			handMade := expectedSecret()
+			handMade.Metadata.Tags = nil
+
+			mockPersesClient := &internal.MockClient{}
+			mockGlobalSecret := &internal.MockGlobalSecret{}
+			mockPersesClient.On("GlobalSecret").Return(mockGlobalSecret)
+			mockGlobalSecret.On("Get", SecretName).Return(handMade, nil)
+			mockGlobalSecret.On("Update", expectedSecret()).Return(expectedSecret(), nil).Once()
+
+			r := newTestGlobalSecretReconciler(credentials())
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+			recorder := record.NewFakeRecorder(10)
+			r.Recorder = recorder
+
+			_, _, err := r.syncPersesGlobalSecret(context.Background(), persesv1alpha2.Perses{}, newSecret())
+			Expect(err).ToNot(HaveOccurred())
+			mockGlobalSecret.AssertExpectations(GinkgoT())
+			Expect(recorder.Events).To(Receive(ContainSubstring("Global secret updated")))
+		})
+
+		It("should only update the global secret when its content changes", func() {
+			mockPersesClient := &internal.MockClient{}
+			mockGlobalSecret := &internal.MockGlobalSecret{}
+			mockPersesClient.On("GlobalSecret").Return(mockGlobalSecret)
+			mockGlobalSecret.On("Get", SecretName).Return(expectedSecret(), nil)
+			mockGlobalSecret.On("Update", expectedSecret()).Return(expectedSecret(), nil).Once()
+
+			r := newTestGlobalSecretReconciler(credentials())
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			By("Pushing the content once")
+			_, _, err := r.syncPersesGlobalSecret(context.Background(), persesv1alpha2.Perses{}, newSecret())
+			Expect(err).ToNot(HaveOccurred())
+
+			By("Leaving the global secret alone while its content is unchanged")
+			_, _, err = r.syncPersesGlobalSecret(context.Background(), persesv1alpha2.Perses{}, newSecret())
+			Expect(err).ToNot(HaveOccurred())
+			mockGlobalSecret.AssertExpectations(GinkgoT())
+		})
+
+		It("should report a conflict and leave a hand-made global secret alone with the Fail policy", func() {
+			handMade := expectedSecret()
+			handMade.Metadata.Tags = nil
+
+			mockPersesClient := &internal.MockClient{}
+			mockGlobalSecret := &internal.MockGlobalSecret{}
+			mockPersesClient.On("GlobalSecret").Return(mockGlobalSecret)
+			mockGlobalSecret.On("Get", SecretName).Return(handMade, nil)
+
+			r := newTestGlobalSecretReconciler(credentials())
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			globalsecret := newSecret()
+			globalsecret.Spec.ConflictPolicy = persesv1alpha2.ConflictPolicyFail
+			_, reason, err := r.syncPersesGlobalSecret(context.Background(), persesv1alpha2.Perses{}, globalsecret)
+			Expect(err).To(HaveOccurred())
+			Expect(reason).To(Equal(common.ReasonConflict))
+			mockGlobalSecret.AssertNotCalled(GinkgoT(), "Update", mock.Anything)
+		})
+
+		It("should report an invalid configuration when the referenced credentials are missing", func() {
+			mockPersesClient := &internal.MockClient{}
+			mockGlobalSecret := &internal.MockGlobalSecret{}
+			mockPersesClient.On("GlobalSecret").Return(mockGlobalSecret)
+
+			r := newTestGlobalSecretReconciler()
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			_, reason, err := r.syncPersesGlobalSecret(context.Background(), persesv1alpha2.Perses{}, newSecret())
+			Expect(err).To(HaveOccurred())
+			Expect(reason).To(Equal(common.ReasonInvalidConfiguration))
+			mockGlobalSecret.AssertNotCalled(GinkgoT(), "Create", expectedSecret())
+		})
+	})
+
+	Context("handleDelete", func() {
+		const SecretName = "thanos-credentials"
+
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: SecretName}}
+
+		newPerses := func(name string, labels map[string]string) *persesv1alpha2.Perses {
+			return &persesv1alpha2.Perses{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "perses-dev", Labels: labels},
+			}
+		}
+
+		deletingSecret := func() *persesv1alpha2.PersesGlobalSecret {
+			return &persesv1alpha2.PersesGlobalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              SecretName,
+					Finalizers:        []string{common.PersesFinalizer},
+					DeletionTimestamp: &metav1.Time{Time: time.Now()},
+				},
+				Spec: persesv1alpha2.SecretSpec{
+					InstanceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "observability"}},
+				},
+			}
+		}
+
+		persesSecret := func(tags set.Set[string]) *persesv1.GlobalSecret {
+			return &persesv1.GlobalSecret{Metadata: persesv1.Metadata{Name: SecretName, Tags: tags}}
+		}
+
+		It("should only delete the global secret from the selected Perses instances", func() {
+			globalsecret := deletingSecret()
+			selectedClient := &internal.MockClient{}
+			otherClient := &internal.MockClient{}
+			mockGlobalSecret := &internal.MockGlobalSecret{}
+			selectedClient.On("GlobalSecret").Return(mockGlobalSecret)
+			mockGlobalSecret.On("Get", SecretName).Return(persesSecret(common.WithManagedTag(nil)), nil)
+			mockGlobalSecret.On("Delete", SecretName).Return(nil).Once()
+
+			r := newTestGlobalSecretReconciler(globalsecret,
+				newPerses("selected", map[string]string{"team": "observability"}),
+				newPerses("other", nil))
+			r.ClientFactory = instanceClientFactory{"selected": selectedClient, "other": otherClient}
+
+			_, err := r.handleDelete(withGlobalSecret(context.Background(), globalsecret), req)
+			Expect(err).ToNot(HaveOccurred())
+			mockGlobalSecret.AssertExpectations(GinkgoT())
+			otherClient.AssertNotCalled(GinkgoT(), "GlobalSecret")
+
+			err = r.Get(context.Background(), req.NamespacedName, &persesv1alpha2.PersesGlobalSecret{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should keep a global secret not managed by the operator in Perses", func() {
+			globalsecret := deletingSecret()
+			mockPersesClient := &internal.MockClient{}
+			mockGlobalSecret := &internal.MockGlobalSecret{}
+			mockPersesClient.On("GlobalSecret").Return(mockGlobalSecret)
+			mockGlobalSecret.On("Get", SecretName).Return(persesSecret(nil), nil)
+
+			r := newTestGlobalSecretReconciler(globalsecret, newPerses("perses", map[string]string{"team": "observability"}))
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			_, err := r.handleDelete(withGlobalSecret(context.Background(), globalsecret), req)
+			Expect(err).ToNot(HaveOccurred())
+			mockGlobalSecret.AssertNotCalled(GinkgoT(), "Delete", SecretName)
+		})
+
+		It("should keep the finalizer while a global datasource references the global secret", func() {
+			globalsecret := deletingSecret()
+			globaldatasource := &persesv1alpha2.PersesGlobalDatasource{
+				ObjectMeta: metav1.ObjectMeta{Name: "thanos"},
+				Spec: persesv1alpha2.DatasourceSpec{
+					Config: persesv1alpha2.Datasource{
+						Spec: specdatasource.Spec{
+							Plugin: specplugin.Plugin{Kind: "PrometheusDatasource", Spec: map[string]interface{}{}},
+						},
+					},
+					SecretRef: &persesv1alpha2.SecretReference{Name: SecretName},
+				},
+			}
+			mockPersesClient := &internal.MockClient{}
+
+			r := newTestGlobalSecretReconciler(globalsecret, globaldatasource, newPerses("perses", map[string]string{"team": "observability"}))
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			_, err := r.handleDelete(withGlobalSecret(context.Background(), globalsecret), req)
+			Expect(common.ExtractReason(err, "")).To(Equal(common.ReasonDeletionBlocked))
+			mockPersesClient.AssertNotCalled(GinkgoT(), "GlobalSecret")
+
+			fresh := &persesv1alpha2.PersesGlobalSecret{}
+			Expect(r.Get(context.Background(), req.NamespacedName, fresh)).To(Succeed())
+			Expect(controllerutil.ContainsFinalizer(fresh, common.PersesFinalizer)).To(BeTrue())
+		})
+	})
+})
+
+// instanceClientFactory returns the Perses client of each instance by name.
+type instanceClientFactory map[string]v1.ClientInterface
+
+func (f instanceClientFactory) CreateClient(_ context.Context, _ client.Reader, perses persesv1alpha2.Perses) (v1.ClientInterface, error) {
+	return f[perses.Name], nil
+}
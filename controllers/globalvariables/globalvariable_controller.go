@@ -0,0 +1,318 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package globalvariables
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/perses/common/set"
+	"github.com/perses/perses/pkg/client/api/validate"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	persesv1 "github.com/perses/perses/pkg/model/api/v1"
+	logger "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	persescommon "github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses-operator/internal/subreconciler"
+)
+
+var gvlog = logger.WithField("module", "globalvariable_controller")
+
+func (r *PersesGlobalVariableReconciler) reconcileGlobalVariablesInAllInstances(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	globalvariable, ok := globalVariableFromContext(ctx)
+	if !ok {
+		gvlog.Error("globalvariable not found in context")
+		res, err := subreconciler.RequeueWithError(fmt.Errorf("globalvariable not found in context"))
+		return r.setStatusToDegraded(ctx, req, res, persescommon.ReasonMissingResource, err)
+	}
+
+	persesInstances, err := r.listSelectedInstances(ctx, globalvariable)
+	if err != nil {
+		gvlog.WithError(err).Error("Failed to get perses instances")
+		res, err := subreconciler.RequeueWithError(err)
+		return r.setStatusToDegraded(ctx, req, res, persescommon.ReasonMissingPerses, err)
+	}
+
+	if len(persesInstances.Items) == 0 {
+		gvlog.Info("No Perses instances found, requeue in 1 minute")
+		return r.setStatusToDegraded(ctx, req, &ctrl.Result{RequeueAfter: time.Minute}, persescommon.ReasonMissingPerses, fmt.Errorf("no Perses instances found matching the label selector"))
+	}
+
+	available := make([]persesv1alpha2.Perses, 0, len(persesInstances.Items))
+	for _, persesInstance := range persesInstances.Items {
+		if !meta.IsStatusConditionTrue(persesInstance.Status.Conditions, persescommon.TypeAvailablePerses) {
+			gvlog.Infof("Skipping Perses instance %s/%s (not yet available)", persesInstance.Namespace, persesInstance.Name)
+			continue
+		}
+		available = append(available, persesInstance)
+	}
+
+	// Every instance is synced, so that a failing instance does not hold back the others.
+	outcomes := subreconciler.ForEach(available, r.InstanceSyncConcurrency, func(persesInstance persesv1alpha2.Perses) (*ctrl.Result, persescommon.ConditionStatusReason, error) {
+		return r.syncPersesGlobalVariable(ctx, persesInstance, globalvariable)
+	})
+
+	var planned, failures []string
+	var firstFailure subreconciler.Outcome
+	for i, persesInstance := range available {
+		instanceName := fmt.Sprintf("%s/%s", persesInstance.Namespace, persesInstance.Name)
+		outcome := outcomes[i]
+		if outcome.Halted() {
+			if len(failures) == 0 {
+				firstFailure = outcome
+			}
+			failures = append(failures, fmt.Sprintf("%s: %v", instanceName, outcome.Err))
+			persescommon.RecordInstanceEvent(r.Recorder, globalvariable, persesInstance, corev1.EventTypeWarning, string(outcome.Reason), "Failed to sync global variable: %v", outcome.Err)
+			continue
+		}
+		if outcome.Reason == persescommon.ReasonDryRun {
+			planned = append(planned, instanceName)
+		}
+	}
+
+	if len(failures) > 0 {
+		gvlog.Errorf("GlobalVariable %s failed to sync to %d of %d Perses instances", globalvariable.Name, len(failures), len(available))
+		// A single failure is reported as is, so that its message stays actionable.
+		failure := firstFailure.Err
+		if len(failures) > 1 {
+			failure = fmt.Errorf("failed to sync to %d of %d Perses instances: %s", len(failures), len(available), strings.Join(failures, "; "))
+		}
+		return r.setStatusToDegraded(ctx, req, firstFailure.Result, firstFailure.Reason, failure)
+	}
+
+	if persescommon.IsDryRun(globalvariable, r.DryRun) {
+		return r.updateGlobalVariableStatus(ctx, req, func(globalvariable *persesv1alpha2.PersesGlobalVariable) {
+			meta.SetStatusCondition(&globalvariable.Status.Conditions, persescommon.DryRunCondition("GlobalVariable", globalvariable.Name, planned))
+		})
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesGlobalVariableReconciler) listSelectedInstances(ctx context.Context, globalvariable *persesv1alpha2.PersesGlobalVariable) (*persesv1alpha2.PersesList, error) {
+	var labelSelector labels.Selector
+	if globalvariable.Spec.InstanceSelector == nil {
+		labelSelector = labels.Everything()
+	} else {
+		var err error
+		labelSelector, err = metav1.LabelSelectorAsSelector(globalvariable.Spec.InstanceSelector)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	persesInstances := &persesv1alpha2.PersesList{}
+	opts := &client.ListOptions{
+		LabelSelector: labelSelector,
+	}
+	if err := r.List(ctx, persesInstances, opts); err != nil {
+		return nil, err
+	}
+	return persesInstances, nil
+}
+
+func (r *PersesGlobalVariableReconciler) syncPersesGlobalVariable(ctx context.Context, perses persesv1alpha2.Perses, globalvariable *persesv1alpha2.PersesGlobalVariable) (*ctrl.Result, persescommon.ConditionStatusReason, error) {
+	persesClient, err := r.ClientFactory.CreateClient(ctx, r.APIReader, perses)
+
+	if err != nil {
+		gvlog.WithError(err).Error("Failed to create perses rest client")
+		return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonConnectionFailed)
+
+	}
+
+	existing, err := persesClient.GlobalVariable().Get(globalvariable.Name)
+	notFound := err != nil && errors.Is(err, perseshttp.RequestNotFoundError)
+
+	if err != nil && !notFound {
+		res, err := subreconciler.RequeueWithError(err)
+		return res, persescommon.ReasonBackendError, err
+	}
+
+	tags := persescommon.ParseTags(globalvariable.Annotations)
+	var existingTags set.Set[string]
+	if !notFound {
+		existingTags = existing.Metadata.Tags
+	}
+	managed, err := persescommon.ClaimOwnership(globalvariable.Spec.ConflictPolicy, "global variable", globalvariable.Name, !notFound, existingTags)
+	if err != nil {
+		gvlog.WithError(err).Errorf("GlobalVariable conflict: %s", globalvariable.Name)
+		return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonConflict)
+	}
+	if managed {
+		tags = persescommon.WithManagedTag(tags)
+	}
+
+	globalVariableWithName := &persesv1.GlobalVariable{
+		Kind: persesv1.KindGlobalVariable,
+		Metadata: persesv1.Metadata{
+			Name: globalvariable.Name,
+			Tags: tags,
+		},
+		Spec: globalvariable.Spec.Config.VariableSpec,
+	}
+
+	if !notFound && persescommon.GlobalVariableInSync(existing, globalVariableWithName) {
+		gvlog.Debugf("GlobalVariable already in sync: %s", globalvariable.Name)
+		res, err := subreconciler.ContinueReconciling()
+		return res, "", err
+	}
+
+	if validateErr := validate.New(persesClient.RESTClient()).GlobalVariable(globalVariableWithName); validateErr != nil {
+		if persescommon.IsClientError(validateErr) {
+			gvlog.WithError(validateErr).Errorf("GlobalVariable validation failed: %s", globalvariable.Name)
+			return subreconciler.RequeueWithErrorAndReason(
+				fmt.Errorf("global variable %q failed server-side validation: %w", globalvariable.Name, validateErr),
+				persescommon.ReasonValidationFailed,
+			)
+		}
+		gvlog.WithError(validateErr).Errorf("GlobalVariable validation request failed: %s", globalvariable.Name)
+		return subreconciler.RequeueWithErrorAndReason(
+			fmt.Errorf("global variable %q validation request failed: %w", globalvariable.Name, validateErr),
+			persescommon.ReasonBackendError,
+		)
+	}
+
+	dryRun := persescommon.IsDryRun(globalvariable, r.DryRun)
+	if dryRun {
+		plan := "created"
+		if !notFound {
+			plan = fmt.Sprintf("updated: %s", persescommon.FormatDiff(persescommon.GlobalVariableDiff(existing, globalVariableWithName), persescommon.MaxEventDiffLength))
+		}
+		gvlog.Infof("Dry run, global variable %s would be %s in Perses instance %s/%s", globalvariable.Name, plan, perses.Namespace, perses.Name)
+		persescommon.RecordInstanceEvent(r.Recorder, globalvariable, perses, corev1.EventTypeNormal, string(persescommon.ReasonDryRun), "Dry run: global variable would be %s", plan)
+		res, err := subreconciler.ContinueReconciling()
+		return res, persescommon.ReasonDryRun, err
+	}
+
+	if notFound {
+		_, err = persesClient.GlobalVariable().Create(globalVariableWithName)
+		if err != nil {
+			gvlog.WithError(err).Errorf("Failed to create globalvariable: %s", globalvariable.Name)
+			return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonBackendError)
+		}
+		gvlog.Infof("GlobalVariable created: %s", globalvariable.Name)
+		persescommon.RecordInstanceEvent(r.Recorder, globalvariable, perses, corev1.EventTypeNormal, persescommon.EventReasonCreated, "Global variable created")
+	} else {
+		_, err = persesClient.GlobalVariable().Update(globalVariableWithName)
+		if err != nil {
+			gvlog.WithError(err).Errorf("Failed to update globalvariable: %s", globalvariable.Name)
+			return subreconciler.RequeueWithErrorAndReason(err, persescommon.ReasonBackendError)
+		}
+		gvlog.Infof("GlobalVariable updated: %s", globalvariable.Name)
+		persescommon.RecordInstanceEvent(r.Recorder, globalvariable, perses, corev1.EventTypeNormal, persescommon.EventReasonUpdated, "Global variable updated: %s", persescommon.FormatDiff(persescommon.GlobalVariableDiff(existing, globalVariableWithName), persescommon.MaxEventDiffLength))
+	}
+
+	res, err := subreconciler.ContinueReconciling()
+	return res, "", err
+}
+
+// deleteGlobalVariableInAllInstances removes the global variable from the Perses instances selected by the
+// PersesGlobalVariable. The deletion is blocked while one of them cannot confirm the removal.
+func (r *PersesGlobalVariableReconciler) deleteGlobalVariableInAllInstances(ctx context.Context, req ctrl.Request, globalvariable *persesv1alpha2.PersesGlobalVariable) (*ctrl.Result, error) {
+	persesInstances, err := r.listSelectedInstances(ctx, globalvariable)
+	if err != nil {
+		gvlog.WithError(err).Error("Failed to get perses instances")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	var blocked []string
+	available := make([]persesv1alpha2.Perses, 0, len(persesInstances.Items))
+	for _, persesInstance := range persesInstances.Items {
+		if !meta.IsStatusConditionTrue(persesInstance.Status.Conditions, persescommon.TypeAvailablePerses) {
+			gvlog.Infof("Perses instance %s/%s is not available, global variable deletion is blocked", persesInstance.Namespace, persesInstance.Name)
+			blocked = append(blocked, fmt.Sprintf("%s/%s (not available)", persesInstance.Namespace, persesInstance.Name))
+			persescommon.RecordInstanceEvent(r.Recorder, globalvariable, persesInstance, corev1.EventTypeWarning, string(persescommon.ReasonDeletionBlocked), "Global variable deletion is waiting for the instance to be available")
+			continue
+		}
+		available = append(available, persesInstance)
+	}
+
+	outcomes := subreconciler.ForEach(available, r.InstanceSyncConcurrency, func(persesInstance persesv1alpha2.Perses) (*ctrl.Result, persescommon.ConditionStatusReason, error) {
+		res, err := r.deleteGlobalVariable(ctx, persesInstance, globalvariable)
+		return res, "", err
+	})
+	for i, persesInstance := range available {
+		if outcome := outcomes[i]; outcome.Halted() {
+			blocked = append(blocked, fmt.Sprintf("%s/%s (%v)", persesInstance.Namespace, persesInstance.Name, outcome.Err))
+			persescommon.RecordInstanceEvent(r.Recorder, globalvariable, persesInstance, corev1.EventTypeWarning, string(persescommon.ReasonDeletionBlocked), "Failed to delete global variable: %v", outcome.Err)
+		}
+	}
+
+	if len(blocked) > 0 {
+		return r.setStatusToDegraded(ctx, req, &ctrl.Result{RequeueAfter: time.Minute}, persescommon.ReasonDeletionBlocked,
+			fmt.Errorf("global variable deletion is waiting for Perses instances: %s", strings.Join(blocked, "; ")))
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesGlobalVariableReconciler) deleteGlobalVariable(ctx context.Context, perses persesv1alpha2.Perses, globalvariable *persesv1alpha2.PersesGlobalVariable) (*ctrl.Result, error) {
+	variableName := globalvariable.Name
+	persesClient, err := r.ClientFactory.CreateClient(ctx, r.APIReader, perses)
+
+	if err != nil {
+		gvlog.WithError(err).Error("Failed to create perses rest client")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	existing, err := persesClient.GlobalVariable().Get(variableName)
+	if err != nil {
+		if errors.Is(err, perseshttp.RequestNotFoundError) {
+			gvlog.Infof("GlobalVariable not found: %s", variableName)
+			return subreconciler.ContinueReconciling()
+		}
+		gvlog.WithError(err).Errorf("Failed to get global variable: %s", variableName)
+		return subreconciler.RequeueWithError(err)
+	}
+
+	// A global variable the operator does not own was either left untouched or overwritten, it is not removed.
+	if !persescommon.IsManaged(existing.Metadata.Tags) {
+		gvlog.Infof("GlobalVariable not managed by the operator, keeping it: %s", variableName)
+		return subreconciler.ContinueReconciling()
+	}
+
+	if persescommon.IsDryRun(globalvariable, r.DryRun) {
+		gvlog.Infof("Dry run, global variable %s would be deleted from Perses instance %s/%s", variableName, perses.Namespace, perses.Name)
+		persescommon.RecordInstanceEvent(r.Recorder, globalvariable, perses, corev1.EventTypeNormal, string(persescommon.ReasonDryRun), "Dry run: global variable would be deleted")
+		return subreconciler.ContinueReconciling()
+	}
+
+	// Ignore NotFound — the resource may have already been deleted from Perses directly.
+	// Any other error means the delete failed and should be retried.
+	err = persesClient.GlobalVariable().Delete(variableName)
+
+	if err != nil {
+		if errors.Is(err, perseshttp.RequestNotFoundError) {
+			gvlog.Infof("GlobalVariable not found: %s", variableName)
+			return subreconciler.ContinueReconciling()
+		}
+		gvlog.WithError(err).Errorf("Failed to delete global variable: %s", variableName)
+		return subreconciler.RequeueWithError(err)
+	}
+
+	gvlog.Infof("GlobalVariable deleted: %s", variableName)
+	persescommon.RecordInstanceEvent(r.Recorder, globalvariable, perses, corev1.EventTypeNormal, persescommon.EventReasonDeleted, "Global variable deleted")
+
+	return subreconciler.ContinueReconciling()
+}
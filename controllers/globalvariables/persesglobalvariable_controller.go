@@ -0,0 +1,352 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package globalvariables
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	logger "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	operatormetrics "github.com/perses/perses-operator/internal/metrics"
+	"github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses-operator/internal/subreconciler"
+)
+
+type globalVariableContextKey string
+
+const contextKey globalVariableContextKey = "globalvariable"
+
+func withGlobalVariable(ctx context.Context, gv *persesv1alpha2.PersesGlobalVariable) context.Context {
+	return context.WithValue(ctx, contextKey, gv)
+}
+
+func globalVariableFromContext(ctx context.Context) (*persesv1alpha2.PersesGlobalVariable, bool) {
+	gv, ok := ctx.Value(contextKey).(*persesv1alpha2.PersesGlobalVariable)
+	return gv, ok
+}
+
+// PersesGlobalVariableReconciler reconciles a PersesGlobalVariable object
+type PersesGlobalVariableReconciler struct {
+	client.Client
+	APIReader             client.Reader // uncached reader — OnlyMetadata watch caches metadata only
+	Scheme                *runtime.Scheme
+	Recorder              record.EventRecorder
+	ClientFactory         common.PersesClientFactory
+	Metrics               *operatormetrics.Metrics
+	ReconciliationTracker *operatormetrics.ReconciliationTracker
+	// InstanceSyncConcurrency is the maximum number of Perses instances the global variable
+	// is synced to at the same time. Lower than 1 syncs one instance at a time.
+	InstanceSyncConcurrency int
+	// DryRun only plans the changes to the global variables in Perses, without applying them,
+	// as for the global variables with the perses.dev/dry-run annotation.
+	DryRun bool
+}
+
+var log = logger.WithField("module", "perses_globalvariable_controller")
+
+// +kubebuilder:rbac:groups=perses.dev,resources=persesglobalvariables,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=perses.dev,resources=persesglobalvariables/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=perses.dev,resources=persesglobalvariables/finalizers,verbs=update
+func (r *PersesGlobalVariableReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	start := time.Now()
+	objKey := req.String()
+
+	if r.Metrics != nil {
+		r.Metrics.ReconcileOperations("persesglobalvariable").Inc()
+	}
+
+	log.Infof("Reconciling PersesGlobalVariable: %s", req.Name)
+
+	// Deletion is handled through the finalizer, so a missing object has nothing left to clean up.
+	globalvariable := &persesv1alpha2.PersesGlobalVariable{}
+	if err := r.APIReader.Get(ctx, req.NamespacedName, globalvariable); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Infof("perses globalvariable resource not found. Ignoring since object must be deleted")
+			if r.ReconciliationTracker != nil {
+				r.ReconciliationTracker.ForgetObject(objKey)
+			}
+			if r.Metrics != nil {
+				r.Metrics.ForgetObject(objKey)
+			}
+			return subreconciler.Evaluate(subreconciler.DoNotRequeue())
+		}
+		log.WithError(err).Error("Failed to get perses globalvariable")
+		if r.Metrics != nil {
+			r.Metrics.ReconcileErrors("persesglobalvariable", "get_failed").Inc()
+		}
+		return subreconciler.Evaluate(subreconciler.RequeueWithError(err))
+	}
+
+	// Store globalvariable in context for all sub-reconcilers
+	ctx = withGlobalVariable(ctx, globalvariable)
+
+	subreconcilersForPerses := []subreconciler.FnWithRequest{
+		r.handleDelete,
+		r.addFinalizer,
+		r.setStatusToUnknown,
+		r.reconcileGlobalVariablesInAllInstances,
+		r.setStatusToComplete,
+	}
+
+	var reconcileErr error
+	for _, f := range subreconcilersForPerses {
+		if r, err := f(ctx, req); subreconciler.ShouldHaltOrRequeue(r, err) {
+			reconcileErr = err
+			break
+		}
+	}
+
+	// Track reconciliation status
+	if r.ReconciliationTracker != nil {
+		r.ReconciliationTracker.SetStatus(objKey, reconcileErr)
+		if reconcileErr == nil {
+			r.ReconciliationTracker.SetReasonAndMessage(objKey, "ReconciliationSuccessful", "GlobalVariable reconciled successfully")
+		}
+	}
+
+	// Track metrics
+	if r.Metrics != nil {
+		if reconcileErr != nil {
+			reason := string(common.ExtractReason(reconcileErr, "reconciliation_failed"))
+			r.Metrics.ReconcileErrors("persesglobalvariable", reason).Inc()
+			r.Metrics.SetFailedResources(objKey, "globalvariable", "", 1)
+		} else {
+			r.Metrics.SetSyncedResources(objKey, "globalvariable", "", 1)
+		}
+	}
+
+	if reconcileErr != nil {
+		return subreconciler.Evaluate(subreconciler.RequeueWithError(reconcileErr))
+	}
+
+	log.WithField("duration", time.Since(start)).Debug("globalvariable reconciliation completed")
+	return subreconciler.Evaluate(subreconciler.DoNotRequeue())
+}
+
+// handleDelete removes the global variable from the selected Perses instances, then releases the finalizer.
+func (r *PersesGlobalVariableReconciler) handleDelete(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	globalvariable, ok := globalVariableFromContext(ctx)
+	if !ok {
+		log.Error("globalvariable not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("globalvariable not found in context"))
+	}
+
+	if globalvariable.GetDeletionTimestamp() == nil {
+		return subreconciler.ContinueReconciling()
+	}
+
+	if !controllerutil.ContainsFinalizer(globalvariable, common.PersesFinalizer) {
+		return subreconciler.DoNotRequeue()
+	}
+
+	if res, err := r.deleteGlobalVariableInAllInstances(ctx, req, globalvariable); subreconciler.ShouldHaltOrRequeue(res, err) {
+		return res, err
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesGlobalVariable{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		if !controllerutil.RemoveFinalizer(fresh, common.PersesFinalizer) {
+			return nil
+		}
+		return r.Update(ctx, fresh)
+	})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to remove finalizer from perses globalvariable")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	log.Infof("PersesGlobalVariable %s deleted", globalvariable.Name)
+	return subreconciler.DoNotRequeue()
+}
+
+// addFinalizer makes sure the global variable is not removed from the cluster before
+// it has been removed from the Perses instances it was synced to.
+func (r *PersesGlobalVariableReconciler) addFinalizer(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	globalvariable, ok := globalVariableFromContext(ctx)
+	if !ok {
+		log.Error("globalvariable not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("globalvariable not found in context"))
+	}
+
+	if controllerutil.ContainsFinalizer(globalvariable, common.PersesFinalizer) {
+		return subreconciler.ContinueReconciling()
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesGlobalVariable{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		if !controllerutil.AddFinalizer(fresh, common.PersesFinalizer) {
+			return nil
+		}
+		return r.Update(ctx, fresh)
+	})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to add finalizer to perses globalvariable")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesGlobalVariableReconciler) updateGlobalVariableStatus(
+	ctx context.Context,
+	req ctrl.Request,
+	updateFn func(*persesv1alpha2.PersesGlobalVariable),
+) (*ctrl.Result, error) {
+	_, ok := globalVariableFromContext(ctx)
+	if !ok {
+		log.Error("globalvariable not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("globalvariable not found in context"))
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesGlobalVariable{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		before := common.SnapshotConditions(fresh.Status.Conditions)
+		updateFn(fresh)
+		if !common.ConditionsChanged(before, fresh.Status.Conditions) {
+			return nil
+		}
+		return r.Status().Update(ctx, fresh)
+	})
+
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("perses globalvariable resource not found. Ignoring since object must be deleted")
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to update Perses globalvariable status")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesGlobalVariableReconciler) setStatusToUnknown(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	return r.updateGlobalVariableStatus(ctx, req, func(globalvariable *persesv1alpha2.PersesGlobalVariable) {
+		if len(globalvariable.Status.Conditions) == 0 {
+			meta.SetStatusCondition(&globalvariable.Status.Conditions, metav1.Condition{
+				Type: common.TypeAvailablePerses, Status: metav1.ConditionUnknown,
+				Reason: "Reconciling", Message: "Starting reconciliation"})
+		}
+	})
+}
+
+func (r *PersesGlobalVariableReconciler) setStatusToComplete(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	// The observed state is the one that was synced, the custom resource may have changed since.
+	synced, ok := globalVariableFromContext(ctx)
+	if !ok {
+		log.Error("global variable not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("global variable not found in context"))
+	}
+
+	// Nothing was applied in a dry run, so the availability of the global variable is left as is.
+	if common.IsDryRun(synced, r.DryRun) {
+		return subreconciler.ContinueReconciling()
+	}
+
+	return r.updateGlobalVariableStatus(ctx, req, func(globalvariable *persesv1alpha2.PersesGlobalVariable) {
+		meta.RemoveStatusCondition(&globalvariable.Status.Conditions, common.TypeDryRunPerses)
+		meta.SetStatusCondition(&globalvariable.Status.Conditions, metav1.Condition{
+			Type: common.TypeDegradedPerses, Status: metav1.ConditionFalse,
+			Reason: "Reconciled", Message: fmt.Sprintf("GlobalVariable (%s) reconciled successfully", globalvariable.Name)})
+		meta.SetStatusCondition(&globalvariable.Status.Conditions, metav1.Condition{
+			Type: common.TypeAvailablePerses, Status: metav1.ConditionTrue,
+			Reason: "Reconciled", Message: fmt.Sprintf("GlobalVariable (%s) created successfully", globalvariable.Name)})
+	})
+}
+
+func (r *PersesGlobalVariableReconciler) setStatusToDegraded(
+	ctx context.Context,
+	req ctrl.Request,
+	degradedResult *ctrl.Result,
+	degradedReason common.ConditionStatusReason,
+	degradedError error,
+) (*ctrl.Result, error) {
+	msg := "unknown error"
+	if degradedError != nil {
+		msg = degradedError.Error()
+	}
+
+	result, err := r.updateGlobalVariableStatus(ctx, req, func(globalvariable *persesv1alpha2.PersesGlobalVariable) {
+		meta.SetStatusCondition(&globalvariable.Status.Conditions, metav1.Condition{
+			Type: common.TypeAvailablePerses, Status: metav1.ConditionFalse,
+			Reason: string(degradedReason), Message: msg})
+		meta.SetStatusCondition(&globalvariable.Status.Conditions, metav1.Condition{
+			Type: common.TypeDegradedPerses, Status: metav1.ConditionTrue,
+			Reason: string(degradedReason), Message: msg})
+	})
+
+	if err != nil {
+		return result, err
+	}
+
+	if degradedError != nil {
+		return degradedResult, common.NewReasonError(degradedError, degradedReason)
+	}
+	return degradedResult, nil
+}
+
+// findGlobalVariablesForPerses returns reconcile requests for all PersesGlobalVariables
+// when a Perses instance becomes available.
+// Global variables are cluster-scoped and their instanceSelector labels determine
+// which Perses instances they sync to. If no instanceSelector is set, they sync to all instances.
+func (r *PersesGlobalVariableReconciler) findGlobalVariablesForPerses(ctx context.Context, _ client.Object) []reconcile.Request {
+	return common.MetadataListToRequests(ctx, r.Client, persesv1alpha2.GroupVersion.WithKind("PersesGlobalVariableList"))
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// It watches PersesGlobalVariable resources and also watches Perses instances
+// to trigger re-reconciliation of all global variables when a Perses instance becomes
+// available. Global variables are matched to Perses instances via instanceSelector labels.
+// Create and delete events for Perses instances are ignored because the instance is not yet
+// ready at creation, and deletion is handled by the global variable's own reconciliation loop.
+func (r *PersesGlobalVariableReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&persesv1alpha2.PersesGlobalVariable{}, builder.OnlyMetadata).
+		Watches(
+			&persesv1alpha2.Perses{},
+			handler.EnqueueRequestsFromMapFunc(r.findGlobalVariablesForPerses),
+			builder.WithPredicates(common.PersesAvailabilityPredicate()),
+		).
+		Complete(r)
+}
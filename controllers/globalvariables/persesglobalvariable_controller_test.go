@@ -0,0 +1,338 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package globalvariables
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/perses/common/set"
+	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	internal "github.com/perses/perses-operator/internal/perses"
+	"github.com/perses/perses-operator/internal/perses/common"
+	persesclient "github.com/perses/perses/pkg/client/api/v1"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	persesv1 "github.com/perses/perses/pkg/model/api/v1"
+	specvariable "github.com/perses/spec/go/dashboard/variable"
+	"github.com/stretchr/testify/mock"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func TestGlobalVariableController(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GlobalVariable Controller Suite")
+}
+
+func newTestGlobalVariableReconciler(objects ...runtime.Object) *PersesGlobalVariableReconciler {
+	scheme := runtime.NewScheme()
+	Expect(persesv1alpha2.AddToScheme(scheme)).To(Succeed())
+
+	clientBuilder := fake.NewClientBuilder().WithScheme(scheme)
+	for _, obj := range objects {
+		clientBuilder = clientBuilder.WithRuntimeObjects(obj)
+	}
+	clientBuilder = clientBuilder.WithStatusSubresource(&persesv1alpha2.PersesGlobalVariable{}).
+		WithTypeConverters(internal.NewUnstructuredTypeConverter())
+
+	c := clientBuilder.Build()
+	return &PersesGlobalVariableReconciler{
+		Client:    c,
+		APIReader: c,
+		Scheme:    scheme,
+	}
+}
+
+var _ = Describe("GlobalVariable controller", func() {
+	const VariableName = "region"
+
+	newPerses := func(name string) *persesv1alpha2.Perses {
+		return &persesv1alpha2.Perses{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "perses-dev"},
+		}
+	}
+
+	Context("syncPersesGlobalVariable", func() {
+		newVariable := func() *persesv1alpha2.PersesGlobalVariable {
+			return &persesv1alpha2.PersesGlobalVariable{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        VariableName,
+					Annotations: map[string]string{common.TagsAnnotation: "infra"},
+				},
+				Spec: persesv1alpha2.VariableSpec{
+					Config: persesv1alpha2.Variable{
+						VariableSpec: persesv1.VariableSpec{
+							Kind: specvariable.KindText,
+							Spec: &specvariable.TextSpec{Value: "eu-west-1"},
+						},
+					},
+				},
+			}
+		}
+
+		expectedVariable := func() *persesv1.GlobalVariable {
+			v := newVariable()
+			return &persesv1.GlobalVariable{
+				Kind: persesv1.KindGlobalVariable,
+				Metadata: persesv1.Metadata{
+					Name: VariableName,
+					Tags: common.WithManagedTag(common.ParseTags(v.Annotations)),
+				},
+				Spec: v.Spec.Config.VariableSpec,
+			}
+		}
+
+		It("should claim a global variable of the same name created in Perses by hand", func() {
+			mockPersesClient, validateServer := internal.NewMockClientWithValidation()
+			defer validateServer.Close()
+			mockGlobalVariable := &internal.MockGlobalVariable{}
+			mockPersesClient.On("GlobalVariable").Return(mockGlobalVariable)
+			existing := expectedVariable()
+			existing.Metadata.Tags = nil
+			existing.Spec.Spec = &specvariable.TextSpec{Value: "us-east-1"}
+			mockGlobalVariable.On("Get", VariableName).Return(existing, nil)
+			mockGlobalVariable.On("Update", expectedVariable()).Return(expectedVariable(), nil).Once()
+
+			recorder := record.NewFakeRecorder(10)
+			r := newTestGlobalVariableReconciler()
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+			r.Recorder = recorder
+
+			_, _, err := r.syncPersesGlobalVariable(context.Background(), *newPerses("perses"), newVariable())
+			Expect(err).ToNot(HaveOccurred())
+			mockGlobalVariable.AssertExpectations(GinkgoT())
+			Expect(recorder.Events).To(Receive(And(
+				ContainSubstring(common.EventReasonUpdated),
+				ContainSubstring(common.ManagedTag),
+				ContainSubstring(`"path":"spec.spec.value","old":"us-east-1","new":"eu-west-1"`),
+			)))
+		})
+
+		It("should report a conflict and leave a global variable not managed by the operator untouched", func() {
+			mockPersesClient := &internal.MockClient{}
+			mockGlobalVariable := &internal.MockGlobalVariable{}
+			mockPersesClient.On("GlobalVariable").Return(mockGlobalVariable)
+			existing := expectedVariable()
+			existing.Metadata.Tags = set.New("infra")
+			mockGlobalVariable.On("Get", VariableName).Return(existing, nil)
+
+			r := newTestGlobalVariableReconciler()
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			variable := newVariable()
+			variable.Spec.ConflictPolicy = persesv1alpha2.ConflictPolicyFail
+			_, reason, err := r.syncPersesGlobalVariable(context.Background(), *newPerses("perses"), variable)
+			Expect(err).To(HaveOccurred())
+			Expect(reason).To(Equal(common.ReasonConflict))
+			mockGlobalVariable.AssertNotCalled(GinkgoT(), "Update", mock.Anything)
+		})
+
+		It("should only plan the update of a global variable in a dry run", func() {
+			mockPersesClient, validateServer := internal.NewMockClientWithValidation()
+			defer validateServer.Close()
+			mockGlobalVariable := &internal.MockGlobalVariable{}
+			mockPersesClient.On("GlobalVariable").Return(mockGlobalVariable)
+			existing := expectedVariable()
+			existing.Spec.Spec = &specvariable.TextSpec{Value: "us-east-1"}
+			mockGlobalVariable.On("Get", VariableName).Return(existing, nil)
+
+			recorder := record.NewFakeRecorder(10)
+			r := newTestGlobalVariableReconciler()
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+			r.Recorder = recorder
+			r.DryRun = true
+
+			_, reason, err := r.syncPersesGlobalVariable(context.Background(), *newPerses("perses"), newVariable())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reason).To(Equal(common.ReasonDryRun))
+			mockGlobalVariable.AssertNotCalled(GinkgoT(), "Update", expectedVariable())
+			Expect(recorder.Events).To(Receive(ContainSubstring(`Dry run: global variable would be updated: [{"path":"spec.spec.value"`)))
+		})
+	})
+
+	Context("reconcileGlobalVariablesInAllInstances", func() {
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: VariableName}}
+
+		It("should sync the global variable to the healthy Perses instances when another one fails", func() {
+			mockPersesClient, validateServer := internal.NewMockClientWithValidation()
+			defer validateServer.Close()
+			mockGlobalVariable := &internal.MockGlobalVariable{}
+			mockPersesClient.On("GlobalVariable").Return(mockGlobalVariable)
+			mockGlobalVariable.On("Get", VariableName).Return(&persesv1.GlobalVariable{}, perseshttp.RequestNotFoundError)
+			mockGlobalVariable.On("Create", mock.Anything).Return(&persesv1.GlobalVariable{}, nil)
+
+			variable := &persesv1alpha2.PersesGlobalVariable{
+				ObjectMeta: metav1.ObjectMeta{Name: VariableName},
+				Spec: persesv1alpha2.VariableSpec{
+					Config: persesv1alpha2.Variable{
+						VariableSpec: persesv1.VariableSpec{
+							Kind: specvariable.KindText,
+							Spec: &specvariable.TextSpec{Value: "eu-west-1"},
+						},
+					},
+				},
+			}
+			r := newTestGlobalVariableReconciler(variable, availablePerses("perses-a", nil), availablePerses("perses-b", nil), availablePerses("perses-c", nil))
+			r.ClientFactory = instanceClientFactory{"perses-a": mockPersesClient, "perses-c": mockPersesClient}
+			r.InstanceSyncConcurrency = 2
+			r.Recorder = record.NewFakeRecorder(10)
+
+			_, err := r.reconcileGlobalVariablesInAllInstances(withGlobalVariable(context.Background(), variable), req)
+			Expect(err).To(HaveOccurred())
+			Expect(common.ExtractReason(err, "")).To(Equal(common.ReasonConnectionFailed))
+			mockGlobalVariable.AssertNumberOfCalls(GinkgoT(), "Create", 2)
+		})
+	})
+
+	Context("handleDelete", func() {
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: VariableName}}
+
+		deletingVariable := func() *persesv1alpha2.PersesGlobalVariable {
+			return &persesv1alpha2.PersesGlobalVariable{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              VariableName,
+					Finalizers:        []string{common.PersesFinalizer},
+					DeletionTimestamp: &metav1.Time{Time: time.Now()},
+				},
+				Spec: persesv1alpha2.VariableSpec{
+					Config: persesv1alpha2.Variable{
+						VariableSpec: persesv1.VariableSpec{
+							Kind: specvariable.KindText,
+							Spec: &specvariable.TextSpec{Value: "eu-west-1"},
+						},
+					},
+					InstanceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "observability"}},
+				},
+			}
+		}
+
+		persesGlobalVariable := func(tags set.Set[string]) *persesv1.GlobalVariable {
+			return &persesv1.GlobalVariable{
+				Metadata: persesv1.Metadata{Name: VariableName, Tags: tags},
+			}
+		}
+
+		selected := map[string]string{"team": "observability"}
+
+		It("should only delete the global variable from the selected Perses instances", func() {
+			variable := deletingVariable()
+			selectedClient := &internal.MockClient{}
+			otherClient := &internal.MockClient{}
+			mockGlobalVariable := &internal.MockGlobalVariable{}
+			selectedClient.On("GlobalVariable").Return(mockGlobalVariable)
+			mockGlobalVariable.On("Get", VariableName).Return(persesGlobalVariable(common.WithManagedTag(nil)), nil)
+			mockGlobalVariable.On("Delete", VariableName).Return(nil).Once()
+
+			recorder := record.NewFakeRecorder(10)
+			r := newTestGlobalVariableReconciler(variable, availablePerses("selected", selected), availablePerses("other", nil))
+			r.ClientFactory = instanceClientFactory{"selected": selectedClient, "other": otherClient}
+			r.Recorder = recorder
+
+			_, err := r.handleDelete(withGlobalVariable(context.Background(), variable), req)
+			Expect(err).ToNot(HaveOccurred())
+			mockGlobalVariable.AssertExpectations(GinkgoT())
+			otherClient.AssertNotCalled(GinkgoT(), "GlobalVariable")
+			Expect(recorder.Events).To(Receive(ContainSubstring("Perses instance perses-dev/selected: Global variable deleted")))
+
+			err = r.Get(context.Background(), req.NamespacedName, &persesv1alpha2.PersesGlobalVariable{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should keep a global variable not managed by the operator in Perses", func() {
+			variable := deletingVariable()
+			mockPersesClient := &internal.MockClient{}
+			mockGlobalVariable := &internal.MockGlobalVariable{}
+			mockPersesClient.On("GlobalVariable").Return(mockGlobalVariable)
+			mockGlobalVariable.On("Get", VariableName).Return(persesGlobalVariable(nil), nil)
+
+			r := newTestGlobalVariableReconciler(variable, availablePerses("perses", selected))
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			_, err := r.handleDelete(withGlobalVariable(context.Background(), variable), req)
+			Expect(err).ToNot(HaveOccurred())
+			mockGlobalVariable.AssertNotCalled(GinkgoT(), "Delete", VariableName)
+
+			err = r.Get(context.Background(), req.NamespacedName, &persesv1alpha2.PersesGlobalVariable{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should ignore a global variable already deleted from Perses", func() {
+			variable := deletingVariable()
+			mockPersesClient := &internal.MockClient{}
+			mockGlobalVariable := &internal.MockGlobalVariable{}
+			mockPersesClient.On("GlobalVariable").Return(mockGlobalVariable)
+			mockGlobalVariable.On("Get", VariableName).Return(&persesv1.GlobalVariable{}, perseshttp.RequestNotFoundError)
+
+			r := newTestGlobalVariableReconciler(variable, availablePerses("perses", selected))
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			res, err := r.handleDelete(withGlobalVariable(context.Background(), variable), req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(&ctrl.Result{}))
+			mockGlobalVariable.AssertNotCalled(GinkgoT(), "Delete", VariableName)
+		})
+
+		It("should keep the finalizer while a selected Perses instance is not available", func() {
+			variable := deletingVariable()
+			mockPersesClient := &internal.MockClient{}
+			unavailable := newPerses("perses")
+			unavailable.Labels = selected
+
+			recorder := record.NewFakeRecorder(10)
+			r := newTestGlobalVariableReconciler(variable, unavailable)
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+			r.Recorder = recorder
+
+			res, err := r.handleDelete(withGlobalVariable(context.Background(), variable), req)
+			Expect(common.ExtractReason(err, "")).To(Equal(common.ReasonDeletionBlocked))
+			Expect(res.RequeueAfter).To(Equal(time.Minute))
+			Expect(recorder.Events).To(Receive(ContainSubstring("Global variable deletion is waiting for the instance to be available")))
+			mockPersesClient.AssertNotCalled(GinkgoT(), "GlobalVariable")
+
+			fresh := &persesv1alpha2.PersesGlobalVariable{}
+			Expect(r.Get(context.Background(), req.NamespacedName, fresh)).To(Succeed())
+			Expect(controllerutil.ContainsFinalizer(fresh, common.PersesFinalizer)).To(BeTrue())
+		})
+	})
+})
+
+func availablePerses(name string, labels map[string]string) *persesv1alpha2.Perses {
+	return &persesv1alpha2.Perses{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "perses-dev", Labels: labels},
+		Status: persesv1alpha2.PersesStatus{
+			Conditions: []metav1.Condition{{Type: common.TypeAvailablePerses, Status: metav1.ConditionTrue}},
+		},
+	}
+}
+
+// instanceClientFactory returns the Perses client of each instance by name.
+type instanceClientFactory map[string]persesclient.ClientInterface
+
+func (f instanceClientFactory) CreateClient(_ context.Context, _ client.Reader, perses persesv1alpha2.Perses) (persesclient.ClientInterface, error) {
+	if persesClient, ok := f[perses.Name]; ok {
+		return persesClient, nil
+	}
+	return nil, fmt.Errorf("connection refused")
+}
@@ -0,0 +1,291 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orphans
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/perses/perses/pkg/client/perseshttp"
+	logger "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	operatormetrics "github.com/perses/perses-operator/internal/metrics"
+	"github.com/perses/perses-operator/internal/perses/common"
+)
+
+var log = logger.WithField("module", "orphan_sweeper")
+
+// Sweeper periodically removes from the Perses instances the dashboards, datasources,
+// global datasources and secrets created by the operator that are no longer backed by
+// a custom resource, e.g. because the custom resource was deleted or renamed while the
+// operator was down. Only the objects carrying the common.ManagedTag are considered,
+// so objects created directly in Perses are never removed.
+type Sweeper struct {
+	APIReader     client.Reader // uncached reader — the instance selectors are not in the metadata-only cache
+	ClientFactory common.PersesClientFactory
+	Metrics       *operatormetrics.Metrics
+	// Interval is the time between two sweeps
+	Interval time.Duration
+	// DryRun only reports the orphans, without removing them from Perses
+	DryRun bool
+}
+
+// owners holds the objects a Perses instance is expected to contain,
+// according to the custom resources selecting it.
+type owners struct {
+	dashboards        sets.Set[types.NamespacedName]
+	datasources       sets.Set[types.NamespacedName]
+	secrets           sets.Set[types.NamespacedName]
+	globalDatasources sets.Set[string]
+	globalSecrets     sets.Set[string]
+}
+
+// SetupWithManager adds the sweeper to the Manager.
+func (s *Sweeper) SetupWithManager(mgr ctrl.Manager) error {
+	return mgr.Add(s)
+}
+
+// NeedLeaderElection makes sure that only the leader removes objects from Perses.
+func (s *Sweeper) NeedLeaderElection() bool {
+	return true
+}
+
+// Start runs a sweep every Interval until the context is cancelled.
+func (s *Sweeper) Start(ctx context.Context) error {
+	log.Infof("Starting orphan sweeper (interval: %s, dry-run: %t)", s.Interval, s.DryRun)
+	wait.UntilWithContext(ctx, s.Sweep, s.Interval)
+	return nil
+}
+
+// Sweep removes the orphans from every available Perses instance.
+func (s *Sweeper) Sweep(ctx context.Context) {
+	persesInstances := &persesv1alpha2.PersesList{}
+	if err := s.APIReader.List(ctx, persesInstances); err != nil {
+		log.WithError(err).Error("Failed to get perses instances")
+		return
+	}
+
+	for _, persesInstance := range persesInstances.Items {
+		if !meta.IsStatusConditionTrue(persesInstance.Status.Conditions, common.TypeAvailablePerses) {
+			log.Debugf("Skipping Perses instance %s/%s (not yet available)", persesInstance.Namespace, persesInstance.Name)
+			continue
+		}
+		if err := s.sweepInstance(ctx, persesInstance); err != nil {
+			log.WithError(err).Errorf("Failed to sweep Perses instance %s/%s", persesInstance.Namespace, persesInstance.Name)
+		}
+	}
+}
+
+func (s *Sweeper) sweepInstance(ctx context.Context, perses persesv1alpha2.Perses) error {
+	persesClient, err := s.ClientFactory.CreateClient(ctx, s.APIReader, perses)
+	if err != nil {
+		return fmt.Errorf("failed to create perses rest client: %w", err)
+	}
+
+	// The objects are listed in Perses before the custom resources are listed: a custom
+	// resource always exists before the objects created for it, so an object synced in
+	// between cannot be mistaken for an orphan.
+	dashboards, err := persesClient.Dashboard("").List("")
+	if err != nil {
+		return fmt.Errorf("failed to list dashboards: %w", err)
+	}
+	datasources, err := persesClient.Datasource("").List("")
+	if err != nil {
+		return fmt.Errorf("failed to list datasources: %w", err)
+	}
+	secrets, err := persesClient.Secret("").List("")
+	if err != nil {
+		return fmt.Errorf("failed to list secrets: %w", err)
+	}
+	globalDatasources, err := persesClient.GlobalDatasource().List("")
+	if err != nil {
+		return fmt.Errorf("failed to list global datasources: %w", err)
+	}
+	globalSecrets, err := persesClient.GlobalSecret().List("")
+	if err != nil {
+		return fmt.Errorf("failed to list global secrets: %w", err)
+	}
+
+	expected, err := s.listOwners(ctx, perses)
+	if err != nil {
+		return err
+	}
+
+	// Datasources are removed before the secrets they may reference.
+	for _, dashboard := range dashboards {
+		project, name := dashboard.Metadata.Project, dashboard.Metadata.Name
+		if common.IsManaged(dashboard.Metadata.Tags) && !expected.dashboards.Has(types.NamespacedName{Namespace: project, Name: name}) {
+			s.prune(perses, "dashboard", project, name, func() error {
+				return persesClient.Dashboard(project).Delete(name)
+			})
+		}
+	}
+	for _, datasource := range datasources {
+		project, name := datasource.Metadata.Project, datasource.Metadata.Name
+		if common.IsManaged(datasource.Metadata.Tags) && !expected.datasources.Has(types.NamespacedName{Namespace: project, Name: name}) {
+			s.prune(perses, "datasource", project, name, func() error {
+				return persesClient.Datasource(project).Delete(name)
+			})
+		}
+	}
+	for _, secret := range secrets {
+		project, name := secret.Metadata.Project, secret.Metadata.Name
+		if common.IsManaged(secret.Metadata.Tags) && !expected.secrets.Has(types.NamespacedName{Namespace: project, Name: name}) {
+			s.prune(perses, "secret", project, name, func() error {
+				return persesClient.Secret(project).Delete(name)
+			})
+		}
+	}
+	for _, datasource := range globalDatasources {
+		name := datasource.Metadata.Name
+		if common.IsManaged(datasource.Metadata.Tags) && !expected.globalDatasources.Has(name) {
+			s.prune(perses, "globaldatasource", "", name, func() error {
+				return persesClient.GlobalDatasource().Delete(name)
+			})
+		}
+	}
+	for _, secret := range globalSecrets {
+		name := secret.Metadata.Name
+		if common.IsManaged(secret.Metadata.Tags) && !expected.globalSecrets.Has(name) {
+			s.prune(perses, "globalsecret", "", name, func() error {
+				return persesClient.GlobalSecret().Delete(name)
+			})
+		}
+	}
+
+	return nil
+}
+
+// listOwners lists the custom resources selecting the given Perses instance.
+// A custom resource with an invalid instanceSelector is considered as selecting
+// every instance, so that its objects are kept. Dashboards, datasources and global
+// datasources rejected by the resource selectors of the instance are not owners.
+func (s *Sweeper) listOwners(ctx context.Context, perses persesv1alpha2.Perses) (*owners, error) {
+	expected := &owners{
+		dashboards:        sets.New[types.NamespacedName](),
+		datasources:       sets.New[types.NamespacedName](),
+		secrets:           sets.New[types.NamespacedName](),
+		globalDatasources: sets.New[string](),
+		globalSecrets:     sets.New[string](),
+	}
+
+	dashboards := &persesv1alpha2.PersesDashboardList{}
+	if err := s.APIReader.List(ctx, dashboards); err != nil {
+		return nil, fmt.Errorf("failed to list PersesDashboards: %w", err)
+	}
+	for _, dashboard := range dashboards.Items {
+		if selectsInstance(dashboard.Spec.InstanceSelector, perses) && s.allowedByInstance(ctx, perses, &dashboard) {
+			expected.dashboards.Insert(types.NamespacedName{Namespace: dashboard.Namespace, Name: dashboard.Name})
+		}
+	}
+
+	datasources := &persesv1alpha2.PersesDatasourceList{}
+	if err := s.APIReader.List(ctx, datasources); err != nil {
+		return nil, fmt.Errorf("failed to list PersesDatasources: %w", err)
+	}
+	for _, datasource := range datasources.Items {
+		if !selectsInstance(datasource.Spec.InstanceSelector, perses) || !s.allowedByInstance(ctx, perses, &datasource) {
+			continue
+		}
+		expected.datasources.Insert(types.NamespacedName{Namespace: datasource.Namespace, Name: datasource.Name})
+		if common.HasSecretConfig(datasource.Spec.Client) {
+			expected.secrets.Insert(types.NamespacedName{Namespace: datasource.Namespace, Name: datasource.Name + common.SecretNameSuffix})
+		}
+	}
+
+	secrets := &persesv1alpha2.PersesSecretList{}
+	if err := s.APIReader.List(ctx, secrets); err != nil {
+		return nil, fmt.Errorf("failed to list PersesSecrets: %w", err)
+	}
+	for _, secret := range secrets.Items {
+		if selectsInstance(secret.Spec.InstanceSelector, perses) {
+			expected.secrets.Insert(types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name})
+		}
+	}
+
+	globalDatasources := &persesv1alpha2.PersesGlobalDatasourceList{}
+	if err := s.APIReader.List(ctx, globalDatasources); err != nil {
+		return nil, fmt.Errorf("failed to list PersesGlobalDatasources: %w", err)
+	}
+	for _, datasource := range globalDatasources.Items {
+		if !selectsInstance(datasource.Spec.InstanceSelector, perses) || !s.allowedByInstance(ctx, perses, &datasource) {
+			continue
+		}
+		expected.globalDatasources.Insert(datasource.Name)
+		if common.HasSecretConfig(datasource.Spec.Client) {
+			expected.globalSecrets.Insert(datasource.Name + common.SecretNameSuffix)
+		}
+	}
+
+	globalSecrets := &persesv1alpha2.PersesGlobalSecretList{}
+	if err := s.APIReader.List(ctx, globalSecrets); err != nil {
+		return nil, fmt.Errorf("failed to list PersesGlobalSecrets: %w", err)
+	}
+	for _, secret := range globalSecrets.Items {
+		if selectsInstance(secret.Spec.InstanceSelector, perses) {
+			expected.globalSecrets.Insert(secret.Name)
+		}
+	}
+
+	return expected, nil
+}
+
+func selectsInstance(instanceSelector *metav1.LabelSelector, perses persesv1alpha2.Perses) bool {
+	selected, err := common.SelectsInstance(instanceSelector, perses)
+	return selected || err != nil
+}
+
+// allowedByInstance reports whether the resource selectors of the Perses instance accept the
+// custom resource. When it cannot be decided, the custom resource is considered as allowed,
+// so that its objects are kept.
+func (s *Sweeper) allowedByInstance(ctx context.Context, perses persesv1alpha2.Perses, obj client.Object) bool {
+	allowed, err := common.InstanceAllowsResource(ctx, s.APIReader, perses, obj)
+	if err != nil {
+		log.WithError(err).Warnf("Failed to check if Perses instance %s/%s allows %s, keeping its objects", perses.Namespace, perses.Name, obj.GetName())
+		return true
+	}
+	return allowed
+}
+
+func (s *Sweeper) prune(perses persesv1alpha2.Perses, resource string, project string, name string, deleteFn func() error) {
+	objectName := name
+	if project != "" {
+		objectName = project + "/" + name
+	}
+
+	if s.DryRun {
+		log.Infof("Orphan %s %s found in Perses instance %s/%s (dry-run, not removed)", resource, objectName, perses.Namespace, perses.Name)
+	} else {
+		// Ignore NotFound — the object may have been removed from Perses in the meantime.
+		if err := deleteFn(); err != nil && !errors.Is(err, perseshttp.RequestNotFoundError) {
+			log.WithError(err).Errorf("Failed to remove orphan %s %s from Perses instance %s/%s", resource, objectName, perses.Namespace, perses.Name)
+			return
+		}
+		log.Infof("Orphan %s %s removed from Perses instance %s/%s", resource, objectName, perses.Namespace, perses.Name)
+	}
+
+	if s.Metrics != nil {
+		s.Metrics.OrphansPruned(resource, s.DryRun).Inc()
+	}
+}
@@ -0,0 +1,271 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orphans
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	internal "github.com/perses/perses-operator/internal/perses"
+	"github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	persesv1 "github.com/perses/perses/pkg/model/api/v1"
+	specdatasource "github.com/perses/spec/go/datasource"
+	specplugin "github.com/perses/spec/go/plugin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestOrphanSweeper(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Orphan Sweeper Suite")
+}
+
+const instanceNamespace = "perses-system"
+
+func newTestSweeper(mockPersesClient *internal.MockClient, objects ...runtime.Object) *Sweeper {
+	scheme := runtime.NewScheme()
+	Expect(persesv1alpha2.AddToScheme(scheme)).To(Succeed())
+
+	instance := &persesv1alpha2.Perses{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "perses",
+			Namespace: instanceNamespace,
+			Labels:    map[string]string{"env": "prod"},
+		},
+		Status: persesv1alpha2.PersesStatus{
+			Conditions: []metav1.Condition{{Type: common.TypeAvailablePerses, Status: metav1.ConditionTrue}},
+		},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(append(objects, instance)...).Build()
+	return &Sweeper{
+		APIReader:     c,
+		ClientFactory: common.NewWithClient(mockPersesClient),
+	}
+}
+
+func datasourceSpec(client *persesv1alpha2.Client, instanceSelector *metav1.LabelSelector) persesv1alpha2.DatasourceSpec {
+	return persesv1alpha2.DatasourceSpec{
+		Config: persesv1alpha2.Datasource{
+			Spec: specdatasource.Spec{
+				Plugin: specplugin.Plugin{Kind: "PrometheusDatasource", Spec: map[string]interface{}{}},
+			},
+		},
+		Client:           client,
+		InstanceSelector: instanceSelector,
+	}
+}
+
+func projectMetadata(project, name string, managed bool) persesv1.ProjectMetadata {
+	metadata := persesv1.ProjectMetadata{
+		Metadata:               persesv1.Metadata{Name: name},
+		ProjectMetadataWrapper: persesv1.ProjectMetadataWrapper{Project: project},
+	}
+	if managed {
+		metadata.Tags = common.WithManagedTag(nil)
+	}
+	return metadata
+}
+
+func globalMetadata(name string, managed bool) persesv1.Metadata {
+	metadata := persesv1.Metadata{Name: name}
+	if managed {
+		metadata.Tags = common.WithManagedTag(nil)
+	}
+	return metadata
+}
+
+type mockObjects struct {
+	client            *internal.MockClient
+	dashboard         *internal.MockDashboard
+	datasource        *internal.MockDatasource
+	secret            *internal.MockSecret
+	globalDatasource  *internal.MockGlobalDatasource
+	globalSecret      *internal.MockGlobalSecret
+	dashboards        []*persesv1.Dashboard
+	datasources       []*persesv1.Datasource
+	secrets           []*persesv1.Secret
+	globalDatasources []*persesv1.GlobalDatasource
+	globalSecrets     []*persesv1.GlobalSecret
+}
+
+func (m *mockObjects) setup() *internal.MockClient {
+	m.client = &internal.MockClient{}
+	m.dashboard = &internal.MockDashboard{}
+	m.datasource = &internal.MockDatasource{}
+	m.secret = &internal.MockSecret{}
+	m.globalDatasource = &internal.MockGlobalDatasource{}
+	m.globalSecret = &internal.MockGlobalSecret{}
+
+	m.client.On("Dashboard", "").Return(m.dashboard)
+	m.client.On("Datasource", "").Return(m.datasource)
+	m.client.On("Secret", "").Return(m.secret)
+	m.client.On("GlobalDatasource").Return(m.globalDatasource)
+	m.client.On("GlobalSecret").Return(m.globalSecret)
+	m.dashboard.On("List", "").Return(m.dashboards, nil)
+	m.datasource.On("List", "").Return(m.datasources, nil)
+	m.secret.On("List", "").Return(m.secrets, nil)
+	m.globalDatasource.On("List", "").Return(m.globalDatasources, nil)
+	m.globalSecret.On("List", "").Return(m.globalSecrets, nil)
+
+	return m.client
+}
+
+var _ = Describe("Orphan sweeper", func() {
+	It("removes the managed dashboards without a custom resource and keeps the others", func() {
+		objects := &mockObjects{
+			dashboards: []*persesv1.Dashboard{
+				{Metadata: projectMetadata("monitoring", "backed", true)},
+				{Metadata: projectMetadata("monitoring", "renamed", true)},
+				{Metadata: projectMetadata("monitoring", "created-in-perses", false)},
+			},
+		}
+		mockPersesClient := objects.setup()
+		projectDashboard := &internal.MockDashboard{}
+		mockPersesClient.On("Dashboard", "monitoring").Return(projectDashboard)
+		projectDashboard.On("Delete", "renamed").Return(nil)
+
+		s := newTestSweeper(mockPersesClient, &persesv1alpha2.PersesDashboard{
+			ObjectMeta: metav1.ObjectMeta{Name: "backed", Namespace: "monitoring"},
+		})
+		s.Sweep(context.Background())
+
+		projectDashboard.AssertExpectations(GinkgoT())
+		projectDashboard.AssertNumberOfCalls(GinkgoT(), "Delete", 1)
+	})
+
+	It("only reports the orphans in dry-run mode", func() {
+		objects := &mockObjects{
+			dashboards:        []*persesv1.Dashboard{{Metadata: projectMetadata("monitoring", "orphan", true)}},
+			globalDatasources: []*persesv1.GlobalDatasource{{Metadata: globalMetadata("orphan", true)}},
+		}
+		mockPersesClient := objects.setup()
+
+		s := newTestSweeper(mockPersesClient)
+		s.DryRun = true
+		s.Sweep(context.Background())
+
+		mockPersesClient.AssertNotCalled(GinkgoT(), "Dashboard", "monitoring")
+		objects.globalDatasource.AssertNotCalled(GinkgoT(), "Delete", "orphan")
+	})
+
+	It("keeps the secrets of the datasources and the shared secrets", func() {
+		objects := &mockObjects{
+			datasources: []*persesv1.Datasource{{Metadata: projectMetadata("monitoring", "prometheus", true)}},
+			secrets: []*persesv1.Secret{
+				{Metadata: projectMetadata("monitoring", "prometheus-secret", true)},
+				{Metadata: projectMetadata("monitoring", "shared", true)},
+				{Metadata: projectMetadata("monitoring", "removed-secret", true)},
+			},
+			globalSecrets: []*persesv1.GlobalSecret{
+				{Metadata: globalMetadata("thanos-secret", true)},
+				{Metadata: globalMetadata("removed-secret", true)},
+			},
+		}
+		mockPersesClient := objects.setup()
+		projectSecret := &internal.MockSecret{}
+		mockPersesClient.On("Secret", "monitoring").Return(projectSecret)
+		projectSecret.On("Delete", "removed-secret").Return(nil)
+		objects.globalSecret.On("Delete", "removed-secret").Return(perseshttp.RequestNotFoundError)
+
+		s := newTestSweeper(mockPersesClient,
+			&persesv1alpha2.PersesDatasource{
+				ObjectMeta: metav1.ObjectMeta{Name: "prometheus", Namespace: "monitoring"},
+				Spec:       datasourceSpec(&persesv1alpha2.Client{BasicAuth: &persesv1alpha2.BasicAuth{}}, nil),
+			},
+			&persesv1alpha2.PersesSecret{
+				ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "monitoring"},
+			},
+			&persesv1alpha2.PersesGlobalDatasource{
+				ObjectMeta: metav1.ObjectMeta{Name: "thanos"},
+				Spec:       datasourceSpec(&persesv1alpha2.Client{BasicAuth: &persesv1alpha2.BasicAuth{}}, nil),
+			},
+		)
+		s.Sweep(context.Background())
+
+		objects.datasource.AssertNotCalled(GinkgoT(), "Delete", "prometheus")
+		projectSecret.AssertExpectations(GinkgoT())
+		projectSecret.AssertNumberOfCalls(GinkgoT(), "Delete", 1)
+		objects.globalSecret.AssertNumberOfCalls(GinkgoT(), "Delete", 1)
+	})
+
+	It("removes the objects of a custom resource that no longer selects the instance", func() {
+		objects := &mockObjects{
+			globalDatasources: []*persesv1.GlobalDatasource{{Metadata: globalMetadata("thanos", true)}},
+		}
+		mockPersesClient := objects.setup()
+		objects.globalDatasource.On("Delete", "thanos").Return(nil)
+
+		s := newTestSweeper(mockPersesClient, &persesv1alpha2.PersesGlobalDatasource{
+			ObjectMeta: metav1.ObjectMeta{Name: "thanos"},
+			Spec:       datasourceSpec(nil, &metav1.LabelSelector{MatchLabels: map[string]string{"env": "staging"}}),
+		})
+		s.Sweep(context.Background())
+
+		objects.globalDatasource.AssertExpectations(GinkgoT())
+	})
+
+	It("removes the objects of a custom resource rejected by the resource selectors of the instance", func() {
+		objects := &mockObjects{
+			dashboards: []*persesv1.Dashboard{
+				{Metadata: projectMetadata("monitoring", "prod", true)},
+				{Metadata: projectMetadata("monitoring", "dev", true)},
+			},
+		}
+		mockPersesClient := objects.setup()
+		projectDashboard := &internal.MockDashboard{}
+		mockPersesClient.On("Dashboard", "monitoring").Return(projectDashboard)
+		projectDashboard.On("Delete", "dev").Return(nil)
+
+		s := newTestSweeper(mockPersesClient,
+			&persesv1alpha2.PersesDashboard{
+				ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "monitoring", Labels: map[string]string{"env": "prod"}},
+			},
+			&persesv1alpha2.PersesDashboard{
+				ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "monitoring", Labels: map[string]string{"env": "dev"}},
+			},
+		)
+		c := s.APIReader.(client.Client)
+		instance := &persesv1alpha2.Perses{}
+		Expect(c.Get(context.Background(), types.NamespacedName{Name: "perses", Namespace: instanceNamespace}, instance)).To(Succeed())
+		instance.Spec.ResourceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}}
+		Expect(c.Update(context.Background(), instance)).To(Succeed())
+		s.Sweep(context.Background())
+
+		projectDashboard.AssertExpectations(GinkgoT())
+		projectDashboard.AssertNumberOfCalls(GinkgoT(), "Delete", 1)
+	})
+
+	It("does not remove anything when an object kind cannot be listed", func() {
+		mockPersesClient := &internal.MockClient{}
+		mockDashboard := &internal.MockDashboard{}
+		mockDatasource := &internal.MockDatasource{}
+		mockPersesClient.On("Dashboard", "").Return(mockDashboard)
+		mockPersesClient.On("Datasource", "").Return(mockDatasource)
+		mockDashboard.On("List", "").Return([]*persesv1.Dashboard{{Metadata: projectMetadata("monitoring", "orphan", true)}}, nil)
+		mockDatasource.On("List", "").Return([]*persesv1.Datasource(nil), perseshttp.RequestInternalError)
+
+		s := newTestSweeper(mockPersesClient)
+		s.Sweep(context.Background())
+
+		mockPersesClient.AssertNotCalled(GinkgoT(), "Dashboard", "monitoring")
+	})
+})
@@ -0,0 +1,122 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perses
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
+	"github.com/perses/perses-operator/internal/perses/common"
+)
+
+// workloadRollout returns the progress of the rollout of the Deployment or StatefulSet of the
+// Perses instance, or an empty reason when the instance requires neither, and whether the workload
+// still has available replicas serving the API.
+func (r *PersesReconciler) workloadRollout(ctx context.Context, perses *v1alpha2.Perses) (common.ConditionStatusReason, string, bool, error) {
+	key := types.NamespacedName{Name: perses.Name, Namespace: perses.Namespace}
+
+	switch {
+	case perses.RequiresDeployment():
+		dep := &appsv1.Deployment{}
+		if err := r.Get(ctx, key, dep); err != nil {
+			return "", "", false, err
+		}
+		reason, message := common.DeploymentRollout(dep)
+		return reason, message, dep.Status.AvailableReplicas > 0, nil
+	case perses.RequiresStatefulSet():
+		sts := &appsv1.StatefulSet{}
+		if err := r.Get(ctx, key, sts); err != nil {
+			return "", "", false, err
+		}
+		reason, message := common.StatefulSetRollout(sts)
+		return reason, message, sts.Status.AvailableReplicas > 0, nil
+	}
+
+	return "", "", true, nil
+}
+
+// checkHealth calls the health endpoint of the Perses API through the client used by the
+// controllers of the resources synced to the instance, so that the instance is only reported
+// available once they can reach it.
+func (r *PersesReconciler) checkHealth(ctx context.Context, perses *v1alpha2.Perses) error {
+	if r.ClientFactory == nil {
+		return nil
+	}
+
+	persesClient, err := r.ClientFactory.CreateClient(ctx, r.APIReader, *perses)
+	if err != nil {
+		return err
+	}
+
+	health, err := persesClient.Health().Check()
+	if err != nil {
+		return err
+	}
+	if !health.Database {
+		return fmt.Errorf("the database is not reachable")
+	}
+
+	return nil
+}
+
+// findPersesForPod returns a reconcile request for the Perses instance whose workload selects
+// the changed pod.
+func (r *PersesReconciler) findPersesForPod(ctx context.Context, obj client.Object) []reconcile.Request {
+	persesList := &v1alpha2.PersesList{}
+	if err := r.List(ctx, persesList, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.WithError(err).Errorf("failed to list Perses instances for pod %s/%s", obj.GetNamespace(), obj.GetName())
+		return nil
+	}
+
+	for _, perses := range persesList.Items {
+		if labels.SelectorFromSet(common.LabelsForPerses(perses.Name, &perses)).Matches(labels.Set(obj.GetLabels())) {
+			return []reconcile.Request{{
+				NamespacedName: types.NamespacedName{
+					Name:      perses.Name,
+					Namespace: perses.Namespace,
+				},
+			}}
+		}
+	}
+
+	return nil
+}
+
+// podStatusPredicate only lets the creation, the deletion and the changes of readiness or phase
+// of a pod trigger the reconciliation of its Perses instance.
+func podStatusPredicate() predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldPod, ok := e.ObjectOld.(*corev1.Pod)
+			if !ok {
+				return false
+			}
+			newPod, ok := e.ObjectNew.(*corev1.Pod)
+			if !ok {
+				return false
+			}
+			return common.PodStatusChanged(oldPod, newPod)
+		},
+	}
+}
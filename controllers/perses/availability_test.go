@@ -0,0 +1,202 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perses
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	persesconfig "github.com/perses/perses/pkg/model/api/config"
+	persesv1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
+	internal "github.com/perses/perses-operator/internal/perses"
+	"github.com/perses/perses-operator/internal/perses/common"
+)
+
+func newAvailabilityReconciler(t *testing.T, health *internal.MockHealth, objs ...runtime.Object) *PersesReconciler {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha2.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, appsv1.AddToScheme(scheme))
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithRuntimeObjects(objs...).
+		WithStatusSubresource(&v1alpha2.Perses{}).
+		Build()
+
+	mockClient := &internal.MockClient{}
+	mockClient.On("Health").Return(health)
+
+	return &PersesReconciler{
+		Client:        fakeClient,
+		APIReader:     fakeClient,
+		Scheme:        scheme,
+		ClientFactory: common.NewWithClient(mockClient),
+	}
+}
+
+func newAvailabilityObjects(status appsv1.DeploymentStatus) (*v1alpha2.Perses, *appsv1.Deployment) {
+	perses := &v1alpha2.Perses{
+		ObjectMeta: metav1.ObjectMeta{Name: "perses", Namespace: "default"},
+		Spec: v1alpha2.PersesSpec{
+			Config: v1alpha2.PersesConfig{
+				Config: persesconfig.Config{
+					Database: persesconfig.Database{SQL: &persesconfig.SQL{}},
+				},
+			},
+		},
+	}
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "perses", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: ptr.To[int32](1)},
+		Status:     status,
+	}
+	return perses, dep
+}
+
+func setStatusToComplete(t *testing.T, r *PersesReconciler, perses *v1alpha2.Perses) (*ctrl.Result, *v1alpha2.Perses) {
+	t.Helper()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: perses.Name, Namespace: perses.Namespace}}
+	res, err := r.setStatusToComplete(withPerses(context.Background(), perses), req)
+	require.NoError(t, err)
+
+	updated := &v1alpha2.Perses{}
+	require.NoError(t, r.Get(context.Background(), req.NamespacedName, updated))
+	return res, updated
+}
+
+func TestSetStatusToComplete_RolloutWithoutAvailableReplica(t *testing.T) {
+	perses, dep := newAvailabilityObjects(appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1})
+	health := &internal.MockHealth{}
+	r := newAvailabilityReconciler(t, health, perses, dep)
+
+	res, updated := setStatusToComplete(t, r, perses)
+	assert.Nil(t, res)
+
+	available := meta.FindStatusCondition(updated.Status.Conditions, common.TypeAvailablePerses)
+	require.NotNil(t, available)
+	assert.Equal(t, metav1.ConditionFalse, available.Status)
+	assert.Equal(t, string(common.ReasonAPIUnavailable), available.Reason)
+
+	progressing := meta.FindStatusCondition(updated.Status.Conditions, common.TypeProgressingPerses)
+	require.NotNil(t, progressing)
+	assert.Equal(t, metav1.ConditionTrue, progressing.Status)
+	assert.Equal(t, "Waiting for Deployment perses rollout to finish: 0 of 1 replicas ready", progressing.Message)
+
+	health.AssertNotCalled(t, "Check")
+}
+
+func TestSetStatusToComplete_RolloutInProgress(t *testing.T) {
+	perses, dep := newAvailabilityObjects(appsv1.DeploymentStatus{Replicas: 2, UpdatedReplicas: 1, ReadyReplicas: 1, AvailableReplicas: 1})
+	health := &internal.MockHealth{}
+	health.On("Check").Return(&persesv1.Health{Database: true}, nil)
+	r := newAvailabilityReconciler(t, health, perses, dep)
+
+	res, updated := setStatusToComplete(t, r, perses)
+	assert.Nil(t, res)
+
+	// The replica of the previous pod template keeps serving the API.
+	available := meta.FindStatusCondition(updated.Status.Conditions, common.TypeAvailablePerses)
+	require.NotNil(t, available)
+	assert.Equal(t, metav1.ConditionTrue, available.Status)
+
+	progressing := meta.FindStatusCondition(updated.Status.Conditions, common.TypeProgressingPerses)
+	require.NotNil(t, progressing)
+	assert.Equal(t, metav1.ConditionTrue, progressing.Status)
+	assert.Equal(t, string(common.ReasonRolloutInProgress), progressing.Reason)
+	assert.Equal(t, "Waiting for Deployment perses rollout to finish: 1 old replicas pending termination", progressing.Message)
+
+	health.AssertNumberOfCalls(t, "Check", 1)
+}
+
+func TestSetStatusToComplete_APIUnhealthy(t *testing.T) {
+	perses, dep := newAvailabilityObjects(appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, ReadyReplicas: 1, AvailableReplicas: 1})
+	health := &internal.MockHealth{}
+	health.On("Check").Return(&persesv1.Health{}, errors.New("connection refused"))
+	r := newAvailabilityReconciler(t, health, perses, dep)
+
+	res, updated := setStatusToComplete(t, r, perses)
+	require.NotNil(t, res)
+	assert.Equal(t, healthCheckRetryPeriod, res.RequeueAfter)
+
+	available := meta.FindStatusCondition(updated.Status.Conditions, common.TypeAvailablePerses)
+	require.NotNil(t, available)
+	assert.Equal(t, metav1.ConditionFalse, available.Status)
+	assert.Equal(t, string(common.ReasonAPIUnavailable), available.Reason)
+
+	progressing := meta.FindStatusCondition(updated.Status.Conditions, common.TypeProgressingPerses)
+	require.NotNil(t, progressing)
+	assert.Equal(t, metav1.ConditionFalse, progressing.Status)
+	assert.Equal(t, string(common.ReasonRolloutComplete), progressing.Reason)
+}
+
+func TestSetStatusToComplete_Available(t *testing.T) {
+	perses, dep := newAvailabilityObjects(appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, ReadyReplicas: 1, AvailableReplicas: 1})
+	health := &internal.MockHealth{}
+	health.On("Check").Return(&persesv1.Health{Database: true}, nil)
+	r := newAvailabilityReconciler(t, health, perses, dep)
+
+	res, updated := setStatusToComplete(t, r, perses)
+	assert.Nil(t, res)
+	assert.True(t, meta.IsStatusConditionTrue(updated.Status.Conditions, common.TypeAvailablePerses))
+	assert.True(t, meta.IsStatusConditionFalse(updated.Status.Conditions, common.TypeDegradedPerses))
+	health.AssertNumberOfCalls(t, "Check", 1)
+}
+
+func TestSetStatusToComplete_RolloutStalled(t *testing.T) {
+	perses, dep := newAvailabilityObjects(appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1,
+		Conditions: []appsv1.DeploymentCondition{{
+			Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse,
+			Reason: "ProgressDeadlineExceeded",
+		}}})
+	r := newAvailabilityReconciler(t, &internal.MockHealth{}, perses, dep)
+
+	_, updated := setStatusToComplete(t, r, perses)
+	for _, conditionType := range []string{common.TypeAvailablePerses, common.TypeProgressingPerses} {
+		cond := meta.FindStatusCondition(updated.Status.Conditions, conditionType)
+		require.NotNil(t, cond)
+		assert.Equal(t, metav1.ConditionFalse, cond.Status)
+		assert.Equal(t, string(common.ReasonRolloutStalled), cond.Reason)
+	}
+	assert.True(t, meta.IsStatusConditionTrue(updated.Status.Conditions, common.TypeDegradedPerses))
+}
+
+func TestFindPersesForPod(t *testing.T) {
+	perses, _ := newAvailabilityObjects(appsv1.DeploymentStatus{})
+	r := newAvailabilityReconciler(t, &internal.MockHealth{}, perses)
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name: "perses-abc", Namespace: "default", Labels: common.LabelsForPerses(perses.Name, perses),
+	}}
+	requests := r.findPersesForPod(context.Background(), pod)
+	require.Len(t, requests, 1)
+	assert.Equal(t, "perses", requests[0].Name)
+
+	pod.Labels = map[string]string{"app": "other"}
+	assert.Empty(t, r.findPersesForPod(context.Background(), pod))
+}
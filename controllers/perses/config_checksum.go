@@ -0,0 +1,93 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perses
+
+import (
+	"context"
+	"maps"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
+	"github.com/perses/perses-operator/internal/perses/common"
+)
+
+// configChecksum returns the checksum of the effective configuration of the Perses container:
+// the rendered config.yaml and the content of the TLS certificates and envFrom sources.
+// A missing source is hashed as missing, so that the pods are rolled once it is created.
+func (r *PersesReconciler) configChecksum(ctx context.Context, perses *v1alpha2.Perses) (string, error) {
+	config, err := renderPersesConfig(perses)
+	if err != nil {
+		return "", err
+	}
+
+	sources := common.GetConfigSources(perses)
+	for i := range sources {
+		key := types.NamespacedName{Namespace: perses.Namespace, Name: sources[i].Name}
+
+		// The cached client strips the data of the Secrets and only caches the ConfigMaps
+		// created by the operator, so the sources are read via APIReader.
+		switch sources[i].Type {
+		case v1alpha2.SecretSourceTypeSecret:
+			secret := &corev1.Secret{}
+			if err := r.APIReader.Get(ctx, key, secret); err != nil {
+				if !apierrors.IsNotFound(err) {
+					return "", err
+				}
+				sources[i].Missing = true
+				continue
+			}
+			sources[i].Data = secret.Data
+		case v1alpha2.SecretSourceTypeConfigMap:
+			cm := &corev1.ConfigMap{}
+			if err := r.APIReader.Get(ctx, key, cm); err != nil {
+				if !apierrors.IsNotFound(err) {
+					return "", err
+				}
+				sources[i].Missing = true
+				continue
+			}
+			data := maps.Clone(cm.BinaryData)
+			if data == nil {
+				data = make(map[string][]byte, len(cm.Data))
+			}
+			for k, v := range cm.Data {
+				data[k] = []byte(v)
+			}
+			sources[i].Data = data
+		}
+	}
+
+	return common.GetConfigChecksum(config, sources)
+}
+
+// podAnnotations returns the annotations of the pod template of the Perses workload: the
+// annotations of the workload and the checksum of the effective configuration, so that
+// any configuration change triggers a rolling restart.
+func (r *PersesReconciler) podAnnotations(ctx context.Context, perses *v1alpha2.Perses, annotations map[string]string) (map[string]string, error) {
+	checksum, err := r.configChecksum(ctx, perses)
+	if err != nil {
+		return nil, err
+	}
+
+	podAnnotations := maps.Clone(annotations)
+	if podAnnotations == nil {
+		podAnnotations = make(map[string]string)
+	}
+	podAnnotations[common.PersesConfigChecksum] = checksum
+
+	return podAnnotations, nil
+}
@@ -0,0 +1,117 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perses
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
+	"github.com/perses/perses-operator/internal/perses/common"
+)
+
+func newChecksumReconciler(t *testing.T, objs ...runtime.Object) *PersesReconciler {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha2.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build()
+	return &PersesReconciler{Client: fakeClient, APIReader: fakeClient, Scheme: scheme}
+}
+
+func TestCreatePersesDeployment_ConfigChecksum(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "perses-env", Namespace: "default"},
+		Data:       map[string][]byte{"PERSES_SECURITY_ENCRYPTION_KEY": []byte("key")},
+	}
+	r := newChecksumReconciler(t, secret)
+	perses := newPersesWithEnv(nil, []corev1.EnvFromSource{
+		{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "perses-env"}}},
+	})
+
+	dep, err := r.createPersesDeployment(context.Background(), perses)
+	require.NoError(t, err)
+	checksum := dep.Spec.Template.Annotations[common.PersesConfigChecksum]
+	assert.NotEmpty(t, checksum)
+	assert.NotContains(t, dep.Annotations, common.PersesConfigChecksum, "the checksum only belongs to the pod template")
+
+	dep, err = r.createPersesDeployment(context.Background(), perses)
+	require.NoError(t, err)
+	assert.Equal(t, checksum, dep.Spec.Template.Annotations[common.PersesConfigChecksum], "the checksum must be stable")
+
+	perses.Spec.Config.APIPrefix = "/perses"
+	dep, err = r.createPersesDeployment(context.Background(), perses)
+	require.NoError(t, err)
+	configChecksum := dep.Spec.Template.Annotations[common.PersesConfigChecksum]
+	assert.NotEqual(t, checksum, configChecksum, "a config change must change the checksum")
+
+	secret.Data["PERSES_SECURITY_ENCRYPTION_KEY"] = []byte("rotated")
+	require.NoError(t, r.Update(context.Background(), secret))
+	dep, err = r.createPersesDeployment(context.Background(), perses)
+	require.NoError(t, err)
+	assert.NotEqual(t, configChecksum, dep.Spec.Template.Annotations[common.PersesConfigChecksum], "an envFrom change must change the checksum")
+}
+
+func TestCreatePersesStatefulSet_ConfigChecksumWithTLS(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "perses-ca", Namespace: "default"},
+		Data:       map[string]string{"ca.crt": "ca"},
+	}
+	r := newChecksumReconciler(t, cm)
+	perses := newPersesWithEnv(nil, nil)
+	perses.Spec.TLS = &v1alpha2.TLS{
+		Enable: ptr.To(true),
+		CaCert: &v1alpha2.Certificate{
+			SecretSource: v1alpha2.SecretSource{Type: v1alpha2.SecretSourceTypeConfigMap, Name: ptr.To("perses-ca")},
+			CertPath:     "ca.crt",
+		},
+	}
+
+	sts, err := r.createPersesStatefulSet(context.Background(), perses)
+	require.NoError(t, err)
+	checksum := sts.Spec.Template.Annotations[common.PersesConfigChecksum]
+	assert.NotEmpty(t, checksum)
+
+	cm.Data["ca.crt"] = "rotated"
+	require.NoError(t, r.Update(context.Background(), cm))
+	sts, err = r.createPersesStatefulSet(context.Background(), perses)
+	require.NoError(t, err)
+	assert.NotEqual(t, checksum, sts.Spec.Template.Annotations[common.PersesConfigChecksum], "a certificate change must change the checksum")
+}
+
+func TestFindPersesForConfigSources(t *testing.T) {
+	perses := newPersesWithEnv(nil, []corev1.EnvFromSource{
+		{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "perses-env"}}},
+		{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "perses-settings"}}},
+	})
+	r := newChecksumReconciler(t, perses)
+
+	source := func(name, namespace string) *metav1.PartialObjectMetadata {
+		return &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	}
+
+	assert.Len(t, r.findPersesForSecret(context.Background(), source("perses-env", "default")), 1)
+	assert.Empty(t, r.findPersesForSecret(context.Background(), source("perses-settings", "default")))
+	assert.Len(t, r.findPersesForConfigMap(context.Background(), source("perses-settings", "default")), 1)
+	assert.Empty(t, r.findPersesForConfigMap(context.Background(), source("perses-env", "default")))
+	assert.Empty(t, r.findPersesForConfigMap(context.Background(), source("perses-settings", "other")))
+}
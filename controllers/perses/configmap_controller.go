@@ -67,11 +67,18 @@ func (r *PersesReconciler) reconcileConfigMap(ctx context.Context, req ctrl.Requ
 			return subreconciler.RequeueWithError(err2)
 		}
 
+		if common.IsDryRun(perses, r.Config.DryRun) {
+			cmlog.Infof("Dry run, ConfigMap %s/%s would be created", cm.Namespace, cm.Name)
+			common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: ConfigMap %s/%s would be created", cm.Namespace, cm.Name)
+			return subreconciler.ContinueReconciling()
+		}
+
 		cmlog.Infof("Creating a new ConfigMap: ConfigMap.Namespace %s ConfigMap.Name %s", cm.Namespace, cm.Name)
 		if err = r.Create(ctx, cm); err != nil {
 			cmlog.WithError(err).Errorf("Failed to create new ConfigMap: ConfigMap.Namespace %s ConfigMap.Name %s", cm.Namespace, cm.Name)
 			return subreconciler.RequeueWithError(err)
 		}
+		common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, common.EventReasonCreated, "Created ConfigMap %s/%s", cm.Namespace, cm.Name)
 
 		return subreconciler.ContinueReconciling()
 	}
@@ -89,10 +96,19 @@ func (r *PersesReconciler) reconcileConfigMap(ctx context.Context, req ctrl.Requ
 	}
 
 	if configMapNeedsUpdate(found, cm, configName, perses) {
+		if common.IsDryRun(perses, r.Config.DryRun) {
+			changed := common.FormatDiff(common.ConfigMapDataDiff(found.Data, cm.Data), common.MaxEventDiffLength)
+			cmlog.Infof("Dry run, ConfigMap %s/%s would be updated: %s", cm.Namespace, cm.Name, changed)
+			common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: ConfigMap %s/%s would be updated: %s", cm.Namespace, cm.Name, changed)
+			return subreconciler.ContinueReconciling()
+		}
 		if err := r.Update(ctx, cm); err != nil {
 			cmlog.WithError(err).Error("Failed to update ConfigMap")
 			return subreconciler.RequeueWithError(err)
 		}
+		changes := common.ConfigMapDataDiff(found.Data, cm.Data)
+		cmlog.WithField("diff", common.FormatDiff(changes, 0)).Debugf("ConfigMap %s/%s updated", cm.Namespace, cm.Name)
+		common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, common.EventReasonUpdated, "Updated ConfigMap %s/%s: %s", cm.Namespace, cm.Name, common.FormatDiff(changes, common.MaxEventDiffLength))
 	}
 
 	return subreconciler.ContinueReconciling()
@@ -133,8 +149,7 @@ func (r *PersesReconciler) createPersesConfigMap(perses *v1alpha2.Perses) (*core
 		annotations = perses.Spec.Metadata.Annotations
 	}
 
-	persesConfig, err := yaml.Marshal(perses.Spec.Config.Config)
-
+	persesConfig, err := renderPersesConfig(perses)
 	if err != nil {
 		cmlog.WithError(err).Errorf("Failed to marshal configmap data: ConfigMap.Namespace %s ConfigMap.Name %s", perses.Namespace, configName)
 		return nil, err
@@ -160,3 +175,8 @@ func (r *PersesReconciler) createPersesConfigMap(perses *v1alpha2.Perses) (*core
 	}
 	return cm, nil
 }
+
+// renderPersesConfig renders the config.yaml mounted in the Perses container.
+func renderPersesConfig(perses *v1alpha2.Perses) ([]byte, error) {
+	return yaml.Marshal(perses.Spec.Config.Config)
+}
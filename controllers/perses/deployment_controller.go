@@ -49,11 +49,17 @@ func (r *PersesReconciler) reconcileDeployment(ctx context.Context, req ctrl.Req
 		found := &appsv1.Deployment{}
 		err := r.Get(ctx, types.NamespacedName{Name: perses.Name, Namespace: perses.Namespace}, found)
 		if err == nil {
+			if common.IsDryRun(perses, r.Config.DryRun) {
+				dlog.Infof("Dry run, Deployment %s/%s would be deleted since configuration changed", found.Namespace, found.Name)
+				common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: Deployment %s/%s would be deleted since the storage configuration changed", found.Namespace, found.Name)
+				return subreconciler.ContinueReconciling()
+			}
 			dlog.Info("Deleting Deployment since configuration changed")
 			if err := r.Delete(ctx, found); err != nil {
 				dlog.WithError(err).Error("Failed to delete Deployment")
 				return subreconciler.RequeueWithError(err)
 			}
+			common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, common.EventReasonDeleted, "Deleted Deployment %s/%s since the storage configuration changed", found.Namespace, found.Name)
 		}
 
 		return subreconciler.ContinueReconciling()
@@ -66,7 +72,7 @@ func (r *PersesReconciler) reconcileDeployment(ctx context.Context, req ctrl.Req
 			return subreconciler.RequeueWithError(err)
 		}
 
-		dep, err := r.createPersesDeployment(perses)
+		dep, err := r.createPersesDeployment(ctx, perses)
 		if err != nil {
 			dlog.WithError(err).Error("Failed to define new Deployment resource for perses")
 
@@ -82,21 +88,33 @@ func (r *PersesReconciler) reconcileDeployment(ctx context.Context, req ctrl.Req
 			return subreconciler.RequeueWithError(err)
 		}
 
+		if common.IsDryRun(perses, r.Config.DryRun) {
+			dlog.Infof("Dry run, Deployment %s/%s would be created", dep.Namespace, dep.Name)
+			common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: Deployment %s/%s would be created", dep.Namespace, dep.Name)
+			return subreconciler.ContinueReconciling()
+		}
+
 		dlog.Infof("Creating a new Deployment: Deployment.Namespace %s Deployment.Name %s", dep.Namespace, dep.Name)
 		if err = r.Create(ctx, dep); err != nil {
 			dlog.WithError(err).Errorf("Failed to create new Deployment: Deployment.Namespace %s Deployment.Name %s", dep.Namespace, dep.Name)
 			return subreconciler.RequeueWithError(err)
 		}
+		common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, common.EventReasonCreated, "Created Deployment %s/%s", dep.Namespace, dep.Name)
 
 		return subreconciler.ContinueReconciling()
 	}
 
-	dep, err := r.createPersesDeployment(perses)
+	dep, err := r.createPersesDeployment(ctx, perses)
 	if err != nil {
 		dlog.WithError(err).Error("Failed to define new Deployment resource for perses")
 		return subreconciler.RequeueWithError(err)
 	}
 
+	// the replicas of an autoscaled Deployment are left to the HorizontalPodAutoscaler
+	if perses.Spec.Autoscaling != nil {
+		dep.Spec.Replicas = found.Spec.Replicas
+	}
+
 	// call update with dry run to fill out fields that are also returned via the k8s api
 	if err = r.Update(ctx, dep, client.DryRunAll); err != nil {
 		dlog.WithError(err).Error("Failed to update Deployment with dry run")
@@ -104,16 +122,25 @@ func (r *PersesReconciler) reconcileDeployment(ctx context.Context, req ctrl.Req
 	}
 
 	if !equality.Semantic.DeepEqual(found.Spec, dep.Spec) {
+		if common.IsDryRun(perses, r.Config.DryRun) {
+			changed := common.FormatDiff(common.Diff("spec", found.Spec, dep.Spec), common.MaxEventDiffLength)
+			dlog.Infof("Dry run, Deployment %s/%s would be updated: %s", dep.Namespace, dep.Name, changed)
+			common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: Deployment %s/%s would be updated: %s", dep.Namespace, dep.Name, changed)
+			return subreconciler.ContinueReconciling()
+		}
 		if err = r.Update(ctx, dep); err != nil {
 			dlog.WithError(err).Error("Failed to update Deployment")
 			return subreconciler.RequeueWithError(err)
 		}
+		changes := common.Diff("spec", found.Spec, dep.Spec)
+		dlog.WithField("diff", common.FormatDiff(changes, 0)).Debugf("Deployment %s/%s updated", dep.Namespace, dep.Name)
+		common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, common.EventReasonUpdated, "Updated Deployment %s/%s: %s", dep.Namespace, dep.Name, common.FormatDiff(changes, common.MaxEventDiffLength))
 	}
 
 	return subreconciler.ContinueReconciling()
 }
 
-func (r *PersesReconciler) createPersesDeployment(
+func (r *PersesReconciler) createPersesDeployment(ctx context.Context,
 	perses *v1alpha2.Perses) (*appsv1.Deployment, error) {
 
 	ls := common.LabelsForPerses(perses.Name, perses)
@@ -131,6 +158,11 @@ func (r *PersesReconciler) createPersesDeployment(
 		annotations[common.PersesProvisioningVersion] = provisioningHash
 	}
 
+	podAnnotations, err := r.podAnnotations(ctx, perses, annotations)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get the Operand image
 	image, err := common.ImageForPerses(perses, r.Config.PersesImage)
 	if err != nil {
@@ -139,6 +171,12 @@ func (r *PersesReconciler) createPersesDeployment(
 
 	livenessProbe, readinessProbe := common.GetProbes(perses)
 
+	replicas := perses.Spec.Replicas
+	if perses.Spec.Autoscaling != nil {
+		// the HorizontalPodAutoscaler scales the Deployment from its lower limit
+		replicas = perses.Spec.Autoscaling.MinReplicas
+	}
+
 	dep := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        perses.Name,
@@ -150,10 +188,10 @@ func (r *PersesReconciler) createPersesDeployment(
 			Selector: &metav1.LabelSelector{
 				MatchLabels: ls,
 			},
-			Replicas: perses.Spec.Replicas,
+			Replicas: replicas,
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Annotations: annotations,
+					Annotations: podAnnotations,
 					Labels:      ls,
 				},
 				Spec: corev1.PodSpec{
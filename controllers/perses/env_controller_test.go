@@ -14,12 +14,14 @@
 package perses
 
 import (
+	"context"
 	"testing"
 
 	"github.com/perses/perses-operator/api/v1alpha2"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func newPersesWithEnv(env []corev1.EnvVar, envFrom []corev1.EnvFromSource) *v1alpha2.Perses {
@@ -40,7 +42,10 @@ func newEnvReconciler(t *testing.T) *PersesReconciler {
 	if err := v1alpha2.AddToScheme(scheme); err != nil {
 		t.Fatalf("failed to add v1alpha2 to scheme: %v", err)
 	}
-	return &PersesReconciler{Scheme: scheme}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add corev1 to scheme: %v", err)
+	}
+	return &PersesReconciler{Scheme: scheme, APIReader: fake.NewClientBuilder().WithScheme(scheme).Build()}
 }
 
 func TestCreatePersesDeployment_EnvLiteralValue(t *testing.T) {
@@ -51,7 +56,7 @@ func TestCreatePersesDeployment_EnvLiteralValue(t *testing.T) {
 		nil,
 	)
 
-	dep, err := newEnvReconciler(t).createPersesDeployment(perses)
+	dep, err := newEnvReconciler(t).createPersesDeployment(context.Background(), perses)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -84,7 +89,7 @@ func TestCreatePersesDeployment_EnvValueFrom(t *testing.T) {
 		nil,
 	)
 
-	dep, err := newEnvReconciler(t).createPersesDeployment(perses)
+	dep, err := newEnvReconciler(t).createPersesDeployment(context.Background(), perses)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -117,7 +122,7 @@ func TestCreatePersesDeployment_EnvFrom(t *testing.T) {
 		},
 	)
 
-	dep, err := newEnvReconciler(t).createPersesDeployment(perses)
+	dep, err := newEnvReconciler(t).createPersesDeployment(context.Background(), perses)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -137,7 +142,7 @@ func TestCreatePersesDeployment_EnvFrom(t *testing.T) {
 func TestCreatePersesDeployment_NoEnv(t *testing.T) {
 	perses := newPersesWithEnv(nil, nil)
 
-	dep, err := newEnvReconciler(t).createPersesDeployment(perses)
+	dep, err := newEnvReconciler(t).createPersesDeployment(context.Background(), perses)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -159,7 +164,7 @@ func TestCreatePersesStatefulSet_EnvLiteralValue(t *testing.T) {
 		nil,
 	)
 
-	ss, err := newEnvReconciler(t).createPersesStatefulSet(perses)
+	ss, err := newEnvReconciler(t).createPersesStatefulSet(context.Background(), perses)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -192,7 +197,7 @@ func TestCreatePersesStatefulSet_EnvValueFrom(t *testing.T) {
 		nil,
 	)
 
-	ss, err := newEnvReconciler(t).createPersesStatefulSet(perses)
+	ss, err := newEnvReconciler(t).createPersesStatefulSet(context.Background(), perses)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -225,7 +230,7 @@ func TestCreatePersesStatefulSet_EnvFrom(t *testing.T) {
 		},
 	)
 
-	ss, err := newEnvReconciler(t).createPersesStatefulSet(perses)
+	ss, err := newEnvReconciler(t).createPersesStatefulSet(context.Background(), perses)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -245,7 +250,7 @@ func TestCreatePersesStatefulSet_EnvFrom(t *testing.T) {
 func TestCreatePersesStatefulSet_NoEnv(t *testing.T) {
 	perses := newPersesWithEnv(nil, nil)
 
-	ss, err := newEnvReconciler(t).createPersesStatefulSet(perses)
+	ss, err := newEnvReconciler(t).createPersesStatefulSet(context.Background(), perses)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -0,0 +1,145 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perses
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	routev1 "github.com/openshift/api/route/v1"
+	logger "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
+	"github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses-operator/internal/subreconciler"
+)
+
+// reconcileExternalURL publishes in the status the URL the Perses instance is exposed on by its
+// Ingress, otherwise by its Route, otherwise by its HTTPRoute.
+func (r *PersesReconciler) reconcileExternalURL(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	perses, ok := persesFromContext(ctx)
+	if !ok {
+		log.Error("perses not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("perses not found in context"))
+	}
+
+	// Nothing was applied in a dry run, so the URL of the instance is left as is.
+	if common.IsDryRun(perses, r.Config.DryRun) {
+		return subreconciler.ContinueReconciling()
+	}
+
+	url, err := r.externalURL(ctx, perses)
+	if err != nil {
+		log.WithError(err).Error("Failed to get the external URL of perses")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	return r.updatePersesStatus(ctx, req, func(p *v1alpha2.Perses) {
+		p.Status.URL = url
+	})
+}
+
+// externalURL returns the URL the Perses instance is exposed on, or an empty string when it is not
+// exposed or its host is not known yet.
+func (r *PersesReconciler) externalURL(ctx context.Context, perses *v1alpha2.Perses) (string, error) {
+	switch {
+	case perses.Spec.Ingress != nil:
+		spec := perses.Spec.Ingress
+		return common.ExternalURL(spec.TLS != nil, spec.Host, common.ExposedPath(perses, spec.Path)), nil
+	case perses.Spec.Route != nil && r.Config.APIs.Route:
+		spec := perses.Spec.Route
+		var host string
+		if spec.Host != nil {
+			host = *spec.Host
+		} else {
+			// the host is generated by the router when the Route is created
+			route := &routev1.Route{}
+			if err := r.Get(ctx, types.NamespacedName{Name: perses.Name, Namespace: perses.Namespace}, route); err != nil {
+				if apierrors.IsNotFound(err) {
+					return "", nil
+				}
+				return "", err
+			}
+			host = route.Spec.Host
+		}
+		if len(host) == 0 {
+			return "", nil
+		}
+		return common.ExternalURL(spec.TLS != nil, host, routePath(perses)), nil
+	case perses.Spec.HTTPRoute != nil && r.Config.APIs.HTTPRoute:
+		spec := perses.Spec.HTTPRoute
+		https, err := r.gatewaysServeHTTPS(ctx, perses)
+		if err != nil {
+			return "", err
+		}
+		return common.ExternalURL(https, spec.Host, common.ExposedPath(perses, spec.Path)), nil
+	}
+	return "", nil
+}
+
+// gatewaysServeHTTPS reports whether a listener the HTTPRoute of the Perses instance attaches to
+// terminates TLS. The Gateways are read via APIReader, as they are not cached.
+func (r *PersesReconciler) gatewaysServeHTTPS(ctx context.Context, perses *v1alpha2.Perses) (bool, error) {
+	for _, ref := range perses.Spec.HTTPRoute.ParentRefs {
+		namespace := perses.Namespace
+		if ref.Namespace != nil {
+			namespace = *ref.Namespace
+		}
+
+		gateway := &gatewayv1.Gateway{}
+		if err := r.APIReader.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, gateway); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return false, fmt.Errorf("failed to get Gateway %s/%s: %w", namespace, ref.Name, err)
+		}
+
+		for _, listener := range gateway.Spec.Listeners {
+			if ref.SectionName != nil && string(listener.Name) != *ref.SectionName {
+				continue
+			}
+			if listener.Protocol == gatewayv1.HTTPSProtocolType {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// apiNotServed reports a Perses instance requesting a Route, HTTPRoute, ServiceMonitor or PodMonitor
+// whose API is not served by the cluster. The object is not created until the operator is restarted
+// with the API served.
+func (r *PersesReconciler) apiNotServed(perses *v1alpha2.Perses, field string, kind string, groupVersion string, log *logger.Entry) (*ctrl.Result, error) {
+	log.Warnf("Perses %s/%s specifies %s but %s is not served by the cluster, the %s is not created", perses.Namespace, perses.Name, field, groupVersion, kind)
+	common.RecordEvent(r.Recorder, perses, corev1.EventTypeWarning, string(common.ReasonAPINotServed),
+		"%s is ignored: %s is not served by the cluster", field, groupVersion)
+	return subreconciler.ContinueReconciling()
+}
+
+// exposingAnnotations returns the annotations of an Ingress, Route or HTTPRoute exposing the
+// Perses instance: the annotations of spec.metadata overridden by the given ones.
+func exposingAnnotations(perses *v1alpha2.Perses, annotations map[string]string) map[string]string {
+	result := map[string]string{}
+	if perses.Spec.Metadata != nil {
+		maps.Copy(result, perses.Spec.Metadata.Annotations)
+	}
+	maps.Copy(result, annotations)
+	return result
+}
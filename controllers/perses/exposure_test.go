@@ -0,0 +1,298 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perses
+
+import (
+	"context"
+	"testing"
+
+	routev1 "github.com/openshift/api/route/v1"
+	persesconfig "github.com/perses/perses/pkg/model/api/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
+	"github.com/perses/perses-operator/internal/operator"
+)
+
+func newExposureReconciler(t *testing.T, apis operator.APIs, objs ...runtime.Object) *PersesReconciler {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha2.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, networkingv1.AddToScheme(scheme))
+	require.NoError(t, routev1.Install(scheme))
+	require.NoError(t, gatewayv1.Install(scheme))
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithRuntimeObjects(objs...).
+		WithStatusSubresource(&v1alpha2.Perses{}).
+		Build()
+
+	return &PersesReconciler{
+		Client:    fakeClient,
+		APIReader: fakeClient,
+		Scheme:    scheme,
+		Recorder:  record.NewFakeRecorder(10),
+		Config:    Config{APIs: apis},
+	}
+}
+
+func newExposedPerses() *v1alpha2.Perses {
+	return &v1alpha2.Perses{
+		ObjectMeta: metav1.ObjectMeta{Name: "perses", Namespace: "default", UID: "perses-uid"},
+		Spec: v1alpha2.PersesSpec{
+			Metadata: &v1alpha2.Metadata{Annotations: map[string]string{"team": "observability"}},
+			Service:  &v1alpha2.PersesService{Name: ptr.To("perses-http")},
+			Config: v1alpha2.PersesConfig{
+				Config: persesconfig.Config{APIPrefix: "/perses"},
+			},
+		},
+	}
+}
+
+func TestCreatePersesIngress(t *testing.T) {
+	perses := newExposedPerses()
+	perses.Spec.Ingress = &v1alpha2.PersesIngress{
+		Host:             "perses.example.com",
+		IngressClassName: ptr.To("nginx"),
+		Annotations:      map[string]string{"team": "platform", "nginx.ingress.kubernetes.io/proxy-body-size": "8m"},
+		TLS:              &v1alpha2.PersesIngressTLS{SecretName: ptr.To("perses-tls")},
+	}
+
+	ing, err := newExposureReconciler(t, operator.APIs{}).createPersesIngress(perses)
+	require.NoError(t, err)
+
+	assert.Equal(t, "perses", ing.Name)
+	assert.Equal(t, map[string]string{"team": "platform", "nginx.ingress.kubernetes.io/proxy-body-size": "8m"}, ing.Annotations)
+	assert.Equal(t, ptr.To("nginx"), ing.Spec.IngressClassName)
+	require.Len(t, ing.Spec.Rules, 1)
+	assert.Equal(t, "perses.example.com", ing.Spec.Rules[0].Host)
+	path := ing.Spec.Rules[0].HTTP.Paths[0]
+	assert.Equal(t, "/perses", path.Path)
+	assert.Equal(t, "perses-http", path.Backend.Service.Name)
+	assert.Equal(t, "http", path.Backend.Service.Port.Name)
+	assert.Equal(t, []networkingv1.IngressTLS{{Hosts: []string{"perses.example.com"}, SecretName: "perses-tls"}}, ing.Spec.TLS)
+	require.Len(t, ing.OwnerReferences, 1)
+	assert.Equal(t, perses.UID, ing.OwnerReferences[0].UID)
+	assert.Equal(t, map[string]string{"team": "observability"}, perses.Spec.Metadata.Annotations, "the annotations of the instance must not be modified")
+}
+
+func TestCreatePersesRoute(t *testing.T) {
+	perses := newExposedPerses()
+	perses.Spec.Route = &v1alpha2.PersesRoute{
+		TLS: &v1alpha2.PersesRouteTLS{Termination: "edge", InsecureEdgeTerminationPolicy: ptr.To("Redirect")},
+	}
+	r := newExposureReconciler(t, operator.APIs{Route: true})
+
+	route, err := r.createPersesRoute(perses, "perses-default.apps.example.com")
+	require.NoError(t, err)
+
+	assert.Equal(t, "perses-default.apps.example.com", route.Spec.Host, "the host generated by the router must be kept")
+	assert.Equal(t, "/perses", route.Spec.Path)
+	assert.Equal(t, routev1.RouteTargetReference{Kind: "Service", Name: "perses-http"}, route.Spec.To)
+	assert.Equal(t, "http", route.Spec.Port.TargetPort.StrVal)
+	assert.Equal(t, routev1.TLSTerminationEdge, route.Spec.TLS.Termination)
+	assert.Equal(t, routev1.InsecureEdgeTerminationPolicyRedirect, route.Spec.TLS.InsecureEdgeTerminationPolicy)
+
+	perses.Spec.Route.Host = ptr.To("perses.example.com")
+	perses.Spec.Route.TLS = &v1alpha2.PersesRouteTLS{Termination: "passthrough"}
+	route, err = r.createPersesRoute(perses, "perses-default.apps.example.com")
+	require.NoError(t, err)
+
+	assert.Equal(t, "perses.example.com", route.Spec.Host)
+	assert.Empty(t, route.Spec.Path, "passthrough routes cannot have a path")
+}
+
+func TestCreatePersesHTTPRoute(t *testing.T) {
+	perses := newExposedPerses()
+	perses.Spec.ContainerPort = ptr.To[int32](9000)
+	perses.Spec.HTTPRoute = &v1alpha2.PersesHTTPRoute{
+		Host: "perses.example.com",
+		Path: ptr.To("/"),
+		ParentRefs: []v1alpha2.GatewayReference{
+			{Name: "public", Namespace: ptr.To("gateways"), SectionName: ptr.To("https")},
+		},
+	}
+
+	route, err := newExposureReconciler(t, operator.APIs{HTTPRoute: true}).createPersesHTTPRoute(perses)
+	require.NoError(t, err)
+
+	assert.Equal(t, []gatewayv1.ParentReference{{
+		Name:        "public",
+		Namespace:   ptr.To[gatewayv1.Namespace]("gateways"),
+		SectionName: ptr.To[gatewayv1.SectionName]("https"),
+	}}, route.Spec.ParentRefs)
+	assert.Equal(t, []gatewayv1.Hostname{"perses.example.com"}, route.Spec.Hostnames)
+	require.Len(t, route.Spec.Rules, 1)
+	assert.Equal(t, ptr.To("/"), route.Spec.Rules[0].Matches[0].Path.Value)
+	backend := route.Spec.Rules[0].BackendRefs[0]
+	assert.Equal(t, gatewayv1.ObjectName("perses-http"), backend.Name)
+	assert.Equal(t, ptr.To[gatewayv1.PortNumber](9000), backend.Port)
+}
+
+func TestReconcileIngress_CreatesAndDeletes(t *testing.T) {
+	perses := newExposedPerses()
+	perses.Spec.Ingress = &v1alpha2.PersesIngress{Host: "perses.example.com"}
+	r := newExposureReconciler(t, operator.APIs{}, perses)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: perses.Name, Namespace: perses.Namespace}}
+
+	_, err := r.reconcileIngress(withPerses(context.Background(), perses), req)
+	require.NoError(t, err)
+
+	ing := &networkingv1.Ingress{}
+	require.NoError(t, r.Get(context.Background(), req.NamespacedName, ing))
+	assert.Equal(t, "perses.example.com", ing.Spec.Rules[0].Host)
+
+	perses.Spec.Ingress = nil
+	_, err = r.reconcileIngress(withPerses(context.Background(), perses), req)
+	require.NoError(t, err)
+
+	err = r.Get(context.Background(), req.NamespacedName, ing)
+	assert.True(t, apierrors.IsNotFound(err), "the Ingress must be deleted once spec.ingress is removed")
+}
+
+func TestReconcileIngress_KeepsUnownedIngress(t *testing.T) {
+	perses := newExposedPerses()
+	unowned := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: perses.Name, Namespace: perses.Namespace}}
+	r := newExposureReconciler(t, operator.APIs{}, perses, unowned)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: perses.Name, Namespace: perses.Namespace}}
+
+	_, err := r.reconcileIngress(withPerses(context.Background(), perses), req)
+	require.NoError(t, err)
+
+	require.NoError(t, r.Get(context.Background(), req.NamespacedName, &networkingv1.Ingress{}))
+}
+
+func TestReconcileRoute_APINotServed(t *testing.T) {
+	perses := newExposedPerses()
+	perses.Spec.Route = &v1alpha2.PersesRoute{}
+	r := newExposureReconciler(t, operator.APIs{}, perses)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: perses.Name, Namespace: perses.Namespace}}
+
+	res, err := r.reconcileRoute(withPerses(context.Background(), perses), req)
+	require.NoError(t, err)
+	assert.Nil(t, res)
+
+	err = r.Get(context.Background(), req.NamespacedName, &routev1.Route{})
+	assert.True(t, apierrors.IsNotFound(err))
+	assert.Equal(t, "Warning APINotServed spec.route is ignored: route.openshift.io/v1 is not served by the cluster",
+		<-r.Recorder.(*record.FakeRecorder).Events)
+}
+
+func TestReconcileExternalURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		apis   operator.APIs
+		spec   func(*v1alpha2.PersesSpec)
+		objs   []runtime.Object
+		expect string
+	}{
+		{
+			name:   "not exposed",
+			spec:   func(*v1alpha2.PersesSpec) {},
+			expect: "",
+		},
+		{
+			name: "ingress with TLS",
+			spec: func(s *v1alpha2.PersesSpec) {
+				s.Ingress = &v1alpha2.PersesIngress{Host: "perses.example.com", TLS: &v1alpha2.PersesIngressTLS{}}
+			},
+			expect: "https://perses.example.com/perses",
+		},
+		{
+			name: "route with a generated host",
+			apis: operator.APIs{Route: true},
+			spec: func(s *v1alpha2.PersesSpec) {
+				s.Route = &v1alpha2.PersesRoute{Path: ptr.To("/")}
+			},
+			objs: []runtime.Object{&routev1.Route{
+				ObjectMeta: metav1.ObjectMeta{Name: "perses", Namespace: "default"},
+				Spec:       routev1.RouteSpec{Host: "perses-default.apps.example.com"},
+			}},
+			expect: "http://perses-default.apps.example.com",
+		},
+		{
+			name: "route not served",
+			spec: func(s *v1alpha2.PersesSpec) {
+				s.Route = &v1alpha2.PersesRoute{Host: ptr.To("perses.example.com")}
+			},
+			expect: "",
+		},
+		{
+			name: "httpRoute attached to an HTTPS listener",
+			apis: operator.APIs{HTTPRoute: true},
+			spec: func(s *v1alpha2.PersesSpec) {
+				s.HTTPRoute = &v1alpha2.PersesHTTPRoute{
+					Host:       "perses.example.com",
+					ParentRefs: []v1alpha2.GatewayReference{{Name: "public", SectionName: ptr.To("https")}},
+				}
+			},
+			objs: []runtime.Object{&gatewayv1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "public", Namespace: "default"},
+				Spec: gatewayv1.GatewaySpec{Listeners: []gatewayv1.Listener{
+					{Name: "http", Protocol: gatewayv1.HTTPProtocolType, Port: 80},
+					{Name: "https", Protocol: gatewayv1.HTTPSProtocolType, Port: 443},
+				}},
+			}},
+			expect: "https://perses.example.com/perses",
+		},
+		{
+			name: "httpRoute attached to an HTTP listener",
+			apis: operator.APIs{HTTPRoute: true},
+			spec: func(s *v1alpha2.PersesSpec) {
+				s.HTTPRoute = &v1alpha2.PersesHTTPRoute{
+					Host:       "perses.example.com",
+					ParentRefs: []v1alpha2.GatewayReference{{Name: "public", SectionName: ptr.To("http")}},
+				}
+			},
+			objs: []runtime.Object{&gatewayv1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "public", Namespace: "default"},
+				Spec: gatewayv1.GatewaySpec{Listeners: []gatewayv1.Listener{
+					{Name: "http", Protocol: gatewayv1.HTTPProtocolType, Port: 80},
+					{Name: "https", Protocol: gatewayv1.HTTPSProtocolType, Port: 443},
+				}},
+			}},
+			expect: "http://perses.example.com/perses",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			perses := newExposedPerses()
+			perses.Status.URL = "http://stale.example.com"
+			tt.spec(&perses.Spec)
+			r := newExposureReconciler(t, tt.apis, append(tt.objs, perses)...)
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: perses.Name, Namespace: perses.Namespace}}
+
+			_, err := r.reconcileExternalURL(withPerses(context.Background(), perses), req)
+			require.NoError(t, err)
+
+			updated := &v1alpha2.Perses{}
+			require.NoError(t, r.Get(context.Background(), req.NamespacedName, updated))
+			assert.Equal(t, tt.expect, updated.Status.URL)
+		})
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perses
+
+import (
+	"context"
+	"fmt"
+
+	logger "github.com/sirupsen/logrus"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
+	"github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses-operator/internal/subreconciler"
+)
+
+var hpalog = logger.WithField("module", "hpa_controller")
+
+// reconcileHorizontalPodAutoscaler creates or updates the HorizontalPodAutoscaler scaling the
+// Deployment of the Perses instance as specified by spec.autoscaling, and deletes it once
+// spec.autoscaling is removed.
+func (r *PersesReconciler) reconcileHorizontalPodAutoscaler(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	perses, ok := persesFromContext(ctx)
+	if !ok {
+		hpalog.Error("perses not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("perses not found in context"))
+	}
+
+	found := &autoscalingv2.HorizontalPodAutoscaler{}
+	if err := r.Get(ctx, types.NamespacedName{Name: perses.Name, Namespace: perses.Namespace}, found); err != nil {
+		if !apierrors.IsNotFound(err) {
+			hpalog.WithError(err).Error("Failed to get HorizontalPodAutoscaler")
+			return subreconciler.RequeueWithError(err)
+		}
+
+		if perses.Spec.Autoscaling == nil {
+			return subreconciler.ContinueReconciling()
+		}
+
+		hpa, err2 := r.createPersesHorizontalPodAutoscaler(perses)
+		if err2 != nil {
+			hpalog.WithError(err2).Error("Failed to define new HorizontalPodAutoscaler resource for perses")
+			return subreconciler.RequeueWithError(err2)
+		}
+
+		if common.IsDryRun(perses, r.Config.DryRun) {
+			hpalog.Infof("Dry run, HorizontalPodAutoscaler %s/%s would be created", hpa.Namespace, hpa.Name)
+			common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: HorizontalPodAutoscaler %s/%s would be created", hpa.Namespace, hpa.Name)
+			return subreconciler.ContinueReconciling()
+		}
+
+		hpalog.Infof("Creating a new HorizontalPodAutoscaler: HorizontalPodAutoscaler.Namespace %s HorizontalPodAutoscaler.Name %s", hpa.Namespace, hpa.Name)
+		if err = r.Create(ctx, hpa); err != nil {
+			hpalog.WithError(err).Errorf("Failed to create new HorizontalPodAutoscaler: HorizontalPodAutoscaler.Namespace %s HorizontalPodAutoscaler.Name %s", hpa.Namespace, hpa.Name)
+			return subreconciler.RequeueWithError(err)
+		}
+		common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, common.EventReasonCreated, "Created HorizontalPodAutoscaler %s/%s", hpa.Namespace, hpa.Name)
+
+		return subreconciler.ContinueReconciling()
+	}
+
+	if perses.Spec.Autoscaling == nil {
+		return r.deleteOwnedObject(ctx, perses, found, "HorizontalPodAutoscaler", hpalog)
+	}
+
+	hpa, err := r.createPersesHorizontalPodAutoscaler(perses)
+	if err != nil {
+		hpalog.WithError(err).Error("Failed to define new HorizontalPodAutoscaler resource for perses")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	// call update with dry run to fill out fields that are also returned via the k8s api
+	if err = r.Update(ctx, hpa, client.DryRunAll); err != nil {
+		hpalog.WithError(err).Error("Failed to update HorizontalPodAutoscaler with dry run")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	if !equality.Semantic.DeepEqual(found.Spec, hpa.Spec) || labelsChanged(found.Labels, hpa.Labels) {
+		if common.IsDryRun(perses, r.Config.DryRun) {
+			changed := common.FormatDiff(common.Diff("spec", found.Spec, hpa.Spec), common.MaxEventDiffLength)
+			hpalog.Infof("Dry run, HorizontalPodAutoscaler %s/%s would be updated: %s", hpa.Namespace, hpa.Name, changed)
+			common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: HorizontalPodAutoscaler %s/%s would be updated: %s", hpa.Namespace, hpa.Name, changed)
+			return subreconciler.ContinueReconciling()
+		}
+		if err = r.Update(ctx, hpa); err != nil {
+			hpalog.WithError(err).Error("Failed to update HorizontalPodAutoscaler")
+			return subreconciler.RequeueWithError(err)
+		}
+		common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, common.EventReasonUpdated, "Updated HorizontalPodAutoscaler %s/%s", hpa.Namespace, hpa.Name)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesReconciler) createPersesHorizontalPodAutoscaler(perses *v1alpha2.Perses) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+	spec := perses.Spec.Autoscaling
+
+	var metrics []autoscalingv2.MetricSpec
+	if spec.TargetCPUUtilizationPercentage != nil {
+		metrics = append(metrics, resourceUtilizationMetric(corev1.ResourceCPU, *spec.TargetCPUUtilizationPercentage))
+	}
+	if spec.TargetMemoryUtilizationPercentage != nil {
+		metrics = append(metrics, resourceUtilizationMetric(corev1.ResourceMemory, *spec.TargetMemoryUtilizationPercentage))
+	}
+
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      perses.Name,
+			Namespace: perses.Namespace,
+			Labels:    common.LabelsForPerses(perses.Name, perses),
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       perses.Name,
+			},
+			MinReplicas: spec.MinReplicas,
+			MaxReplicas: spec.MaxReplicas,
+			// without metrics, the API server targets an average CPU utilization of 80%
+			Metrics: metrics,
+		},
+	}
+
+	// Set the ownerRef for the HorizontalPodAutoscaler
+	// More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/owners-dependents/
+	if err := ctrl.SetControllerReference(perses, hpa, r.Scheme); err != nil {
+		return nil, err
+	}
+	return hpa, nil
+}
+
+func resourceUtilizationMetric(name corev1.ResourceName, utilization int32) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ResourceMetricSourceType,
+		Resource: &autoscalingv2.ResourceMetricSource{
+			Name: name,
+			Target: autoscalingv2.MetricTarget{
+				Type:               autoscalingv2.UtilizationMetricType,
+				AverageUtilization: &utilization,
+			},
+		},
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perses
+
+import (
+	"context"
+	"fmt"
+
+	logger "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
+	"github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses-operator/internal/subreconciler"
+)
+
+var hrlog = logger.WithField("module", "httproute_controller")
+
+// reconcileHTTPRoute creates or updates the Gateway API HTTPRoute exposing the Perses instance as
+// specified by spec.httpRoute, and deletes it once spec.httpRoute is removed. It does nothing when
+// the gateway.networking.k8s.io API is not served by the cluster.
+func (r *PersesReconciler) reconcileHTTPRoute(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	perses, ok := persesFromContext(ctx)
+	if !ok {
+		hrlog.Error("perses not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("perses not found in context"))
+	}
+
+	if !r.Config.APIs.HTTPRoute {
+		if perses.Spec.HTTPRoute != nil {
+			return r.apiNotServed(perses, "spec.httpRoute", "HTTPRoute", gatewayv1.GroupVersion.String(), hrlog)
+		}
+		return subreconciler.ContinueReconciling()
+	}
+
+	found := &gatewayv1.HTTPRoute{}
+	if err := r.Get(ctx, types.NamespacedName{Name: perses.Name, Namespace: perses.Namespace}, found); err != nil {
+		if !apierrors.IsNotFound(err) {
+			hrlog.WithError(err).Error("Failed to get HTTPRoute")
+			return subreconciler.RequeueWithError(err)
+		}
+
+		if perses.Spec.HTTPRoute == nil {
+			return subreconciler.ContinueReconciling()
+		}
+
+		route, err2 := r.createPersesHTTPRoute(perses)
+		if err2 != nil {
+			hrlog.WithError(err2).Error("Failed to define new HTTPRoute resource for perses")
+			return subreconciler.RequeueWithError(err2)
+		}
+
+		if common.IsDryRun(perses, r.Config.DryRun) {
+			hrlog.Infof("Dry run, HTTPRoute %s/%s would be created", route.Namespace, route.Name)
+			common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: HTTPRoute %s/%s would be created", route.Namespace, route.Name)
+			return subreconciler.ContinueReconciling()
+		}
+
+		hrlog.Infof("Creating a new HTTPRoute: HTTPRoute.Namespace %s HTTPRoute.Name %s", route.Namespace, route.Name)
+		if err = r.Create(ctx, route); err != nil {
+			hrlog.WithError(err).Errorf("Failed to create new HTTPRoute: HTTPRoute.Namespace %s HTTPRoute.Name %s", route.Namespace, route.Name)
+			return subreconciler.RequeueWithError(err)
+		}
+		common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, common.EventReasonCreated, "Created HTTPRoute %s/%s", route.Namespace, route.Name)
+
+		return subreconciler.ContinueReconciling()
+	}
+
+	if perses.Spec.HTTPRoute == nil {
+		return r.deleteOwnedObject(ctx, perses, found, "HTTPRoute", hrlog)
+	}
+
+	route, err := r.createPersesHTTPRoute(perses)
+	if err != nil {
+		hrlog.WithError(err).Error("Failed to define new HTTPRoute resource for perses")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	// call update with dry run to fill out fields that are also returned via the k8s api
+	if err = r.Update(ctx, route, client.DryRunAll); err != nil {
+		hrlog.WithError(err).Error("Failed to update HTTPRoute with dry run")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	if httpRouteNeedsUpdate(found, route) {
+		if common.IsDryRun(perses, r.Config.DryRun) {
+			changed := common.FormatDiff(common.Diff("spec", found.Spec, route.Spec), common.MaxEventDiffLength)
+			hrlog.Infof("Dry run, HTTPRoute %s/%s would be updated: %s", route.Namespace, route.Name, changed)
+			common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: HTTPRoute %s/%s would be updated: %s", route.Namespace, route.Name, changed)
+			return subreconciler.ContinueReconciling()
+		}
+		if err = r.Update(ctx, route); err != nil {
+			hrlog.WithError(err).Error("Failed to update HTTPRoute")
+			return subreconciler.RequeueWithError(err)
+		}
+		common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, common.EventReasonUpdated, "Updated HTTPRoute %s/%s", route.Namespace, route.Name)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func httpRouteNeedsUpdate(existing, updated *gatewayv1.HTTPRoute) bool {
+	return !equality.Semantic.DeepEqual(existing.Spec, updated.Spec) ||
+		!equality.Semantic.DeepEqual(existing.Annotations, updated.Annotations) ||
+		labelsChanged(existing.Labels, updated.Labels)
+}
+
+func (r *PersesReconciler) createPersesHTTPRoute(perses *v1alpha2.Perses) (*gatewayv1.HTTPRoute, error) {
+	spec := perses.Spec.HTTPRoute
+
+	parentRefs := make([]gatewayv1.ParentReference, 0, len(spec.ParentRefs))
+	for _, ref := range spec.ParentRefs {
+		parentRefs = append(parentRefs, gatewayv1.ParentReference{
+			Name:        gatewayv1.ObjectName(ref.Name),
+			Namespace:   (*gatewayv1.Namespace)(ref.Namespace),
+			SectionName: (*gatewayv1.SectionName)(ref.SectionName),
+		})
+	}
+
+	pathType := gatewayv1.PathMatchPathPrefix
+	path := common.ExposedPath(perses, spec.Path)
+	port := gatewayv1.PortNumber(common.ServicePort(perses))
+
+	route := &gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        perses.Name,
+			Namespace:   perses.Namespace,
+			Annotations: exposingAnnotations(perses, spec.Annotations),
+			Labels:      common.LabelsForPerses(perses.Name, perses),
+		},
+		Spec: gatewayv1.HTTPRouteSpec{
+			CommonRouteSpec: gatewayv1.CommonRouteSpec{
+				ParentRefs: parentRefs,
+			},
+			Hostnames: []gatewayv1.Hostname{gatewayv1.Hostname(spec.Host)},
+			Rules: []gatewayv1.HTTPRouteRule{{
+				Matches: []gatewayv1.HTTPRouteMatch{{
+					Path: &gatewayv1.HTTPPathMatch{
+						Type:  &pathType,
+						Value: &path,
+					},
+				}},
+				BackendRefs: []gatewayv1.HTTPBackendRef{{
+					BackendRef: gatewayv1.BackendRef{
+						BackendObjectReference: gatewayv1.BackendObjectReference{
+							Name: gatewayv1.ObjectName(common.ServiceName(perses)),
+							Port: &port,
+						},
+					},
+				}},
+			}},
+		},
+	}
+
+	// Set the ownerRef for the HTTPRoute
+	// More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/owners-dependents/
+	if err := ctrl.SetControllerReference(perses, route, r.Scheme); err != nil {
+		return nil, err
+	}
+	return route, nil
+}
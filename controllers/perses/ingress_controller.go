@@ -0,0 +1,165 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perses
+
+import (
+	"context"
+	"fmt"
+
+	logger "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
+	"github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses-operator/internal/subreconciler"
+)
+
+var inglog = logger.WithField("module", "ingress_controller")
+
+// reconcileIngress creates or updates the Ingress exposing the Perses instance as specified by
+// spec.ingress, and deletes it once spec.ingress is removed.
+func (r *PersesReconciler) reconcileIngress(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	perses, ok := persesFromContext(ctx)
+	if !ok {
+		inglog.Error("perses not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("perses not found in context"))
+	}
+
+	found := &networkingv1.Ingress{}
+	if err := r.Get(ctx, types.NamespacedName{Name: perses.Name, Namespace: perses.Namespace}, found); err != nil {
+		if !apierrors.IsNotFound(err) {
+			inglog.WithError(err).Error("Failed to get Ingress")
+			return subreconciler.RequeueWithError(err)
+		}
+
+		if perses.Spec.Ingress == nil {
+			return subreconciler.ContinueReconciling()
+		}
+
+		ing, err2 := r.createPersesIngress(perses)
+		if err2 != nil {
+			inglog.WithError(err2).Error("Failed to define new Ingress resource for perses")
+			return subreconciler.RequeueWithError(err2)
+		}
+
+		if common.IsDryRun(perses, r.Config.DryRun) {
+			inglog.Infof("Dry run, Ingress %s/%s would be created", ing.Namespace, ing.Name)
+			common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: Ingress %s/%s would be created", ing.Namespace, ing.Name)
+			return subreconciler.ContinueReconciling()
+		}
+
+		inglog.Infof("Creating a new Ingress: Ingress.Namespace %s Ingress.Name %s", ing.Namespace, ing.Name)
+		if err = r.Create(ctx, ing); err != nil {
+			inglog.WithError(err).Errorf("Failed to create new Ingress: Ingress.Namespace %s Ingress.Name %s", ing.Namespace, ing.Name)
+			return subreconciler.RequeueWithError(err)
+		}
+		common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, common.EventReasonCreated, "Created Ingress %s/%s", ing.Namespace, ing.Name)
+
+		return subreconciler.ContinueReconciling()
+	}
+
+	if perses.Spec.Ingress == nil {
+		return r.deleteOwnedObject(ctx, perses, found, "Ingress", inglog)
+	}
+
+	ing, err := r.createPersesIngress(perses)
+	if err != nil {
+		inglog.WithError(err).Error("Failed to define new Ingress resource for perses")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	// call update with dry run to fill out fields that are also returned via the k8s api
+	if err = r.Update(ctx, ing, client.DryRunAll); err != nil {
+		inglog.WithError(err).Error("Failed to update Ingress with dry run")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	if ingressNeedsUpdate(found, ing) {
+		if common.IsDryRun(perses, r.Config.DryRun) {
+			changed := common.FormatDiff(common.Diff("spec", found.Spec, ing.Spec), common.MaxEventDiffLength)
+			inglog.Infof("Dry run, Ingress %s/%s would be updated: %s", ing.Namespace, ing.Name, changed)
+			common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: Ingress %s/%s would be updated: %s", ing.Namespace, ing.Name, changed)
+			return subreconciler.ContinueReconciling()
+		}
+		if err = r.Update(ctx, ing); err != nil {
+			inglog.WithError(err).Error("Failed to update Ingress")
+			return subreconciler.RequeueWithError(err)
+		}
+		common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, common.EventReasonUpdated, "Updated Ingress %s/%s", ing.Namespace, ing.Name)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func ingressNeedsUpdate(existing, updated *networkingv1.Ingress) bool {
+	return !equality.Semantic.DeepEqual(existing.Spec, updated.Spec) ||
+		!equality.Semantic.DeepEqual(existing.Annotations, updated.Annotations) ||
+		labelsChanged(existing.Labels, updated.Labels)
+}
+
+func (r *PersesReconciler) createPersesIngress(perses *v1alpha2.Perses) (*networkingv1.Ingress, error) {
+	spec := perses.Spec.Ingress
+	pathType := networkingv1.PathTypePrefix
+
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        perses.Name,
+			Namespace:   perses.Namespace,
+			Annotations: exposingAnnotations(perses, spec.Annotations),
+			Labels:      common.LabelsForPerses(perses.Name, perses),
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: spec.IngressClassName,
+			Rules: []networkingv1.IngressRule{{
+				Host: spec.Host,
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:     common.ExposedPath(perses, spec.Path),
+							PathType: &pathType,
+							Backend: networkingv1.IngressBackend{
+								Service: &networkingv1.IngressServiceBackend{
+									Name: common.ServiceName(perses),
+									Port: networkingv1.ServiceBackendPort{Name: "http"},
+								},
+							},
+						}},
+					},
+				},
+			}},
+		},
+	}
+
+	if spec.TLS != nil {
+		tls := networkingv1.IngressTLS{Hosts: []string{spec.Host}}
+		if spec.TLS.SecretName != nil {
+			tls.SecretName = *spec.TLS.SecretName
+		}
+		ing.Spec.TLS = []networkingv1.IngressTLS{tls}
+	}
+
+	// Set the ownerRef for the Ingress
+	// More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/owners-dependents/
+	if err := ctrl.SetControllerReference(perses, ing, r.Scheme); err != nil {
+		return nil, err
+	}
+	return ing, nil
+}
@@ -0,0 +1,297 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perses
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	logger "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
+	"github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses-operator/internal/subreconciler"
+)
+
+var mlog = logger.WithField("module", "monitor_controller")
+
+// reconcileServiceMonitor creates or updates the ServiceMonitor scraping the metrics of the Perses
+// instance when spec.monitoring requests one, and deletes it otherwise. It does nothing when the
+// monitoring.coreos.com API is not served by the cluster.
+func (r *PersesReconciler) reconcileServiceMonitor(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	perses, ok := persesFromContext(ctx)
+	if !ok {
+		mlog.Error("perses not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("perses not found in context"))
+	}
+
+	wanted := perses.Spec.Monitoring != nil && common.MonitorKind(perses) == v1alpha2.MonitorKindServiceMonitor
+
+	if !r.Config.APIs.ServiceMonitor {
+		if wanted {
+			return r.apiNotServed(perses, "spec.monitoring", "ServiceMonitor", monitoringv1.SchemeGroupVersion.String(), mlog)
+		}
+		return subreconciler.ContinueReconciling()
+	}
+
+	found := &monitoringv1.ServiceMonitor{}
+	if err := r.Get(ctx, types.NamespacedName{Name: perses.Name, Namespace: perses.Namespace}, found); err != nil {
+		if !apierrors.IsNotFound(err) {
+			mlog.WithError(err).Error("Failed to get ServiceMonitor")
+			return subreconciler.RequeueWithError(err)
+		}
+
+		if !wanted {
+			return subreconciler.ContinueReconciling()
+		}
+
+		sm, err2 := r.createPersesServiceMonitor(perses)
+		if err2 != nil {
+			mlog.WithError(err2).Error("Failed to define new ServiceMonitor resource for perses")
+			return subreconciler.RequeueWithError(err2)
+		}
+
+		if common.IsDryRun(perses, r.Config.DryRun) {
+			mlog.Infof("Dry run, ServiceMonitor %s/%s would be created", sm.Namespace, sm.Name)
+			common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: ServiceMonitor %s/%s would be created", sm.Namespace, sm.Name)
+			return subreconciler.ContinueReconciling()
+		}
+
+		mlog.Infof("Creating a new ServiceMonitor: ServiceMonitor.Namespace %s ServiceMonitor.Name %s", sm.Namespace, sm.Name)
+		if err = r.Create(ctx, sm); err != nil {
+			mlog.WithError(err).Errorf("Failed to create new ServiceMonitor: ServiceMonitor.Namespace %s ServiceMonitor.Name %s", sm.Namespace, sm.Name)
+			return subreconciler.RequeueWithError(err)
+		}
+		common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, common.EventReasonCreated, "Created ServiceMonitor %s/%s", sm.Namespace, sm.Name)
+
+		return subreconciler.ContinueReconciling()
+	}
+
+	if !wanted {
+		return r.deleteOwnedObject(ctx, perses, found, "ServiceMonitor", mlog)
+	}
+
+	sm, err := r.createPersesServiceMonitor(perses)
+	if err != nil {
+		mlog.WithError(err).Error("Failed to define new ServiceMonitor resource for perses")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	// call update with dry run to fill out fields that are also returned via the k8s api
+	if err = r.Update(ctx, sm, client.DryRunAll); err != nil {
+		mlog.WithError(err).Error("Failed to update ServiceMonitor with dry run")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	if !equality.Semantic.DeepEqual(found.Spec, sm.Spec) || labelsChanged(found.Labels, sm.Labels) {
+		if common.IsDryRun(perses, r.Config.DryRun) {
+			changed := common.FormatDiff(common.Diff("spec", found.Spec, sm.Spec), common.MaxEventDiffLength)
+			mlog.Infof("Dry run, ServiceMonitor %s/%s would be updated: %s", sm.Namespace, sm.Name, changed)
+			common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: ServiceMonitor %s/%s would be updated: %s", sm.Namespace, sm.Name, changed)
+			return subreconciler.ContinueReconciling()
+		}
+		if err = r.Update(ctx, sm); err != nil {
+			mlog.WithError(err).Error("Failed to update ServiceMonitor")
+			return subreconciler.RequeueWithError(err)
+		}
+		common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, common.EventReasonUpdated, "Updated ServiceMonitor %s/%s", sm.Namespace, sm.Name)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+// reconcilePodMonitor creates or updates the PodMonitor scraping the metrics of the Perses
+// instance when spec.monitoring requests one, and deletes it otherwise. It does nothing when the
+// monitoring.coreos.com API is not served by the cluster.
+func (r *PersesReconciler) reconcilePodMonitor(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	perses, ok := persesFromContext(ctx)
+	if !ok {
+		mlog.Error("perses not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("perses not found in context"))
+	}
+
+	wanted := perses.Spec.Monitoring != nil && common.MonitorKind(perses) == v1alpha2.MonitorKindPodMonitor
+
+	if !r.Config.APIs.PodMonitor {
+		if wanted {
+			return r.apiNotServed(perses, "spec.monitoring", "PodMonitor", monitoringv1.SchemeGroupVersion.String(), mlog)
+		}
+		return subreconciler.ContinueReconciling()
+	}
+
+	found := &monitoringv1.PodMonitor{}
+	if err := r.Get(ctx, types.NamespacedName{Name: perses.Name, Namespace: perses.Namespace}, found); err != nil {
+		if !apierrors.IsNotFound(err) {
+			mlog.WithError(err).Error("Failed to get PodMonitor")
+			return subreconciler.RequeueWithError(err)
+		}
+
+		if !wanted {
+			return subreconciler.ContinueReconciling()
+		}
+
+		pm, err2 := r.createPersesPodMonitor(perses)
+		if err2 != nil {
+			mlog.WithError(err2).Error("Failed to define new PodMonitor resource for perses")
+			return subreconciler.RequeueWithError(err2)
+		}
+
+		if common.IsDryRun(perses, r.Config.DryRun) {
+			mlog.Infof("Dry run, PodMonitor %s/%s would be created", pm.Namespace, pm.Name)
+			common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: PodMonitor %s/%s would be created", pm.Namespace, pm.Name)
+			return subreconciler.ContinueReconciling()
+		}
+
+		mlog.Infof("Creating a new PodMonitor: PodMonitor.Namespace %s PodMonitor.Name %s", pm.Namespace, pm.Name)
+		if err = r.Create(ctx, pm); err != nil {
+			mlog.WithError(err).Errorf("Failed to create new PodMonitor: PodMonitor.Namespace %s PodMonitor.Name %s", pm.Namespace, pm.Name)
+			return subreconciler.RequeueWithError(err)
+		}
+		common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, common.EventReasonCreated, "Created PodMonitor %s/%s", pm.Namespace, pm.Name)
+
+		return subreconciler.ContinueReconciling()
+	}
+
+	if !wanted {
+		return r.deleteOwnedObject(ctx, perses, found, "PodMonitor", mlog)
+	}
+
+	pm, err := r.createPersesPodMonitor(perses)
+	if err != nil {
+		mlog.WithError(err).Error("Failed to define new PodMonitor resource for perses")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	// call update with dry run to fill out fields that are also returned via the k8s api
+	if err = r.Update(ctx, pm, client.DryRunAll); err != nil {
+		mlog.WithError(err).Error("Failed to update PodMonitor with dry run")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	if !equality.Semantic.DeepEqual(found.Spec, pm.Spec) || labelsChanged(found.Labels, pm.Labels) {
+		if common.IsDryRun(perses, r.Config.DryRun) {
+			changed := common.FormatDiff(common.Diff("spec", found.Spec, pm.Spec), common.MaxEventDiffLength)
+			mlog.Infof("Dry run, PodMonitor %s/%s would be updated: %s", pm.Namespace, pm.Name, changed)
+			common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: PodMonitor %s/%s would be updated: %s", pm.Namespace, pm.Name, changed)
+			return subreconciler.ContinueReconciling()
+		}
+		if err = r.Update(ctx, pm); err != nil {
+			mlog.WithError(err).Error("Failed to update PodMonitor")
+			return subreconciler.RequeueWithError(err)
+		}
+		common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, common.EventReasonUpdated, "Updated PodMonitor %s/%s", pm.Namespace, pm.Name)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesReconciler) createPersesServiceMonitor(perses *v1alpha2.Perses) (*monitoringv1.ServiceMonitor, error) {
+	spec := perses.Spec.Monitoring
+
+	endpoint := monitoringv1.Endpoint{
+		Port:                 "http",
+		Path:                 common.MetricsPath(perses),
+		Scheme:               ptr.To(common.MetricsScheme(perses)),
+		RelabelConfigs:       spec.Relabelings,
+		MetricRelabelConfigs: spec.MetricRelabelings,
+	}
+	if spec.Interval != nil {
+		endpoint.Interval = *spec.Interval
+	}
+	if spec.ScrapeTimeout != nil {
+		endpoint.ScrapeTimeout = *spec.ScrapeTimeout
+	}
+	if tlsConfig := common.MetricsTLSConfig(perses); tlsConfig != nil {
+		endpoint.TLSConfig = &monitoringv1.TLSConfig{SafeTLSConfig: *tlsConfig}
+	}
+
+	sm := &monitoringv1.ServiceMonitor{
+		ObjectMeta: monitorObjectMeta(perses),
+		Spec: monitoringv1.ServiceMonitorSpec{
+			Endpoints: []monitoringv1.Endpoint{endpoint},
+			Selector: metav1.LabelSelector{
+				MatchLabels: common.LabelsForPerses(perses.Name, perses),
+			},
+		},
+	}
+
+	// Set the ownerRef for the ServiceMonitor
+	// More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/owners-dependents/
+	if err := ctrl.SetControllerReference(perses, sm, r.Scheme); err != nil {
+		return nil, err
+	}
+	return sm, nil
+}
+
+func (r *PersesReconciler) createPersesPodMonitor(perses *v1alpha2.Perses) (*monitoringv1.PodMonitor, error) {
+	spec := perses.Spec.Monitoring
+
+	endpoint := monitoringv1.PodMetricsEndpoint{
+		Port:                 ptr.To("perses"),
+		Path:                 common.MetricsPath(perses),
+		Scheme:               ptr.To(common.MetricsScheme(perses)),
+		RelabelConfigs:       spec.Relabelings,
+		MetricRelabelConfigs: spec.MetricRelabelings,
+	}
+	if spec.Interval != nil {
+		endpoint.Interval = *spec.Interval
+	}
+	if spec.ScrapeTimeout != nil {
+		endpoint.ScrapeTimeout = *spec.ScrapeTimeout
+	}
+	endpoint.TLSConfig = common.MetricsTLSConfig(perses)
+
+	pm := &monitoringv1.PodMonitor{
+		ObjectMeta: monitorObjectMeta(perses),
+		Spec: monitoringv1.PodMonitorSpec{
+			PodMetricsEndpoints: []monitoringv1.PodMetricsEndpoint{endpoint},
+			Selector: metav1.LabelSelector{
+				MatchLabels: common.LabelsForPerses(perses.Name, perses),
+			},
+		},
+	}
+
+	// Set the ownerRef for the PodMonitor
+	// More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/owners-dependents/
+	if err := ctrl.SetControllerReference(perses, pm, r.Scheme); err != nil {
+		return nil, err
+	}
+	return pm, nil
+}
+
+// monitorObjectMeta returns the metadata of the monitor scraping the Perses instance, labelled
+// with the labels of the instance and those of spec.monitoring, which do not override them.
+func monitorObjectMeta(perses *v1alpha2.Perses) metav1.ObjectMeta {
+	labels := maps.Clone(perses.Spec.Monitoring.Labels)
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	maps.Copy(labels, common.LabelsForPerses(perses.Name, perses))
+
+	return metav1.ObjectMeta{
+		Name:      perses.Name,
+		Namespace: perses.Namespace,
+		Labels:    labels,
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perses
+
+import (
+	"context"
+	"testing"
+
+	persesconfig "github.com/perses/perses/pkg/model/api/config"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
+	"github.com/perses/perses-operator/internal/operator"
+	"github.com/perses/perses-operator/internal/perses/common"
+)
+
+func newMonitoringReconciler(t *testing.T, apis operator.APIs, objs ...runtime.Object) *PersesReconciler {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha2.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, monitoringv1.AddToScheme(scheme))
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithRuntimeObjects(objs...).
+		WithStatusSubresource(&v1alpha2.Perses{}).
+		Build()
+
+	return &PersesReconciler{
+		Client:    fakeClient,
+		APIReader: fakeClient,
+		Scheme:    scheme,
+		Recorder:  record.NewFakeRecorder(10),
+		Config:    Config{APIs: apis},
+	}
+}
+
+func newMonitoredPerses() *v1alpha2.Perses {
+	return &v1alpha2.Perses{
+		ObjectMeta: metav1.ObjectMeta{Name: "perses", Namespace: "default", UID: "perses-uid"},
+		Spec: v1alpha2.PersesSpec{
+			Config: v1alpha2.PersesConfig{
+				Config: persesconfig.Config{APIPrefix: "/perses"},
+			},
+			TLS: &v1alpha2.TLS{
+				Enable: ptr.To(true),
+				CaCert: &v1alpha2.Certificate{
+					SecretSource: v1alpha2.SecretSource{Type: v1alpha2.SecretSourceTypeSecret, Name: ptr.To("perses-tls"), Namespace: ptr.To("default")},
+					CertPath:     "ca.crt",
+				},
+			},
+			Monitoring: &v1alpha2.PersesMonitoring{
+				Labels:   map[string]string{"release": "prometheus", "app.kubernetes.io/name": "ignored"},
+				Interval: ptr.To(monitoringv1.Duration("30s")),
+				Relabelings: []monitoringv1.RelabelConfig{{
+					TargetLabel: "cluster",
+					Replacement: ptr.To("production"),
+				}},
+			},
+		},
+	}
+}
+
+func TestCreatePersesServiceMonitor(t *testing.T) {
+	perses := newMonitoredPerses()
+
+	sm, err := newMonitoringReconciler(t, operator.APIs{}).createPersesServiceMonitor(perses)
+	require.NoError(t, err)
+
+	assert.Equal(t, "prometheus", sm.Labels["release"])
+	assert.Equal(t, "perses", sm.Labels["app.kubernetes.io/name"], "the labels of spec.monitoring must not override those of the instance")
+	assert.Equal(t, common.LabelsForPerses(perses.Name, perses), sm.Spec.Selector.MatchLabels)
+	require.Len(t, sm.Spec.Endpoints, 1)
+	endpoint := sm.Spec.Endpoints[0]
+	assert.Equal(t, "http", endpoint.Port)
+	assert.Equal(t, "/perses/metrics", endpoint.Path)
+	assert.Equal(t, ptr.To(monitoringv1.SchemeHTTPS), endpoint.Scheme)
+	assert.Equal(t, monitoringv1.Duration("30s"), endpoint.Interval)
+	assert.Equal(t, perses.Spec.Monitoring.Relabelings, endpoint.RelabelConfigs)
+	require.NotNil(t, endpoint.TLSConfig)
+	assert.Equal(t, ptr.To("perses.default.svc"), endpoint.TLSConfig.ServerName)
+	assert.Equal(t, "perses-tls", endpoint.TLSConfig.CA.Secret.Name)
+	require.Len(t, sm.OwnerReferences, 1)
+	assert.Equal(t, perses.UID, sm.OwnerReferences[0].UID)
+}
+
+func TestCreatePersesPodMonitor(t *testing.T) {
+	perses := newMonitoredPerses()
+	perses.Spec.TLS = nil
+	perses.Spec.Monitoring.Kind = ptr.To(v1alpha2.MonitorKindPodMonitor)
+
+	pm, err := newMonitoringReconciler(t, operator.APIs{}).createPersesPodMonitor(perses)
+	require.NoError(t, err)
+
+	require.Len(t, pm.Spec.PodMetricsEndpoints, 1)
+	endpoint := pm.Spec.PodMetricsEndpoints[0]
+	assert.Equal(t, ptr.To("perses"), endpoint.Port)
+	assert.Equal(t, ptr.To(monitoringv1.SchemeHTTP), endpoint.Scheme)
+	assert.Nil(t, endpoint.TLSConfig)
+}
+
+func TestReconcileMonitors_SwitchesKind(t *testing.T) {
+	perses := newMonitoredPerses()
+	r := newMonitoringReconciler(t, operator.APIs{ServiceMonitor: true, PodMonitor: true}, perses)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: perses.Name, Namespace: perses.Namespace}}
+
+	reconcile := func() {
+		t.Helper()
+		ctx := withPerses(context.Background(), perses)
+		_, err := r.reconcileServiceMonitor(ctx, req)
+		require.NoError(t, err)
+		_, err = r.reconcilePodMonitor(ctx, req)
+		require.NoError(t, err)
+	}
+
+	reconcile()
+	require.NoError(t, r.Get(context.Background(), req.NamespacedName, &monitoringv1.ServiceMonitor{}))
+	err := r.Get(context.Background(), req.NamespacedName, &monitoringv1.PodMonitor{})
+	assert.True(t, apierrors.IsNotFound(err))
+
+	perses.Spec.Monitoring.Kind = ptr.To(v1alpha2.MonitorKindPodMonitor)
+	reconcile()
+	err = r.Get(context.Background(), req.NamespacedName, &monitoringv1.ServiceMonitor{})
+	assert.True(t, apierrors.IsNotFound(err), "the ServiceMonitor must be deleted once a PodMonitor is requested")
+	require.NoError(t, r.Get(context.Background(), req.NamespacedName, &monitoringv1.PodMonitor{}))
+
+	perses.Spec.Monitoring = nil
+	reconcile()
+	err = r.Get(context.Background(), req.NamespacedName, &monitoringv1.PodMonitor{})
+	assert.True(t, apierrors.IsNotFound(err), "the PodMonitor must be deleted once spec.monitoring is removed")
+}
+
+func TestReconcileServiceMonitor_APINotServed(t *testing.T) {
+	perses := newMonitoredPerses()
+	r := newMonitoringReconciler(t, operator.APIs{}, perses)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: perses.Name, Namespace: perses.Namespace}}
+
+	res, err := r.reconcileServiceMonitor(withPerses(context.Background(), perses), req)
+	require.NoError(t, err)
+	assert.Nil(t, res)
+
+	err = r.Get(context.Background(), req.NamespacedName, &monitoringv1.ServiceMonitor{})
+	assert.True(t, apierrors.IsNotFound(err))
+	assert.Equal(t, "Warning APINotServed spec.monitoring is ignored: monitoring.coreos.com/v1 is not served by the cluster",
+		<-r.Recorder.(*record.FakeRecorder).Events)
+}
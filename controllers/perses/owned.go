@@ -0,0 +1,62 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perses
+
+import (
+	"context"
+
+	logger "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
+	"github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses-operator/internal/subreconciler"
+)
+
+// deleteOwnedObject deletes an object managed for the Perses instance once the block of the spec
+// it is defined by is removed. Objects not controlled by the instance are left untouched.
+func (r *PersesReconciler) deleteOwnedObject(ctx context.Context, perses *v1alpha2.Perses, obj client.Object, kind string, log *logger.Entry) (*ctrl.Result, error) {
+	if !metav1.IsControlledBy(obj, perses) {
+		return subreconciler.ContinueReconciling()
+	}
+
+	if common.IsDryRun(perses, r.Config.DryRun) {
+		log.Infof("Dry run, %s %s/%s would be deleted", kind, obj.GetNamespace(), obj.GetName())
+		common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: %s %s/%s would be deleted", kind, obj.GetNamespace(), obj.GetName())
+		return subreconciler.ContinueReconciling()
+	}
+
+	log.Infof("Deleting %s: %s.Namespace %s %s.Name %s", kind, kind, obj.GetNamespace(), kind, obj.GetName())
+	if err := r.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+		log.WithError(err).Errorf("Failed to delete %s: %s.Namespace %s %s.Name %s", kind, kind, obj.GetNamespace(), kind, obj.GetName())
+		return subreconciler.RequeueWithError(err)
+	}
+	common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, common.EventReasonDeleted, "Deleted %s %s/%s", kind, obj.GetNamespace(), obj.GetName())
+
+	return subreconciler.ContinueReconciling()
+}
+
+// labelsChanged reports whether the labels set by the operator differ from the existing ones.
+func labelsChanged(existing, updated map[string]string) bool {
+	for k, v := range updated {
+		if existing[k] != v {
+			return true
+		}
+	}
+	return false
+}
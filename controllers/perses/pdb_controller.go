@@ -0,0 +1,136 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perses
+
+import (
+	"context"
+	"fmt"
+
+	logger "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
+	"github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses-operator/internal/subreconciler"
+)
+
+var pdblog = logger.WithField("module", "pdb_controller")
+
+// reconcilePodDisruptionBudget creates or updates the PodDisruptionBudget of the Perses pods as
+// specified by spec.podDisruptionBudget, and deletes it once spec.podDisruptionBudget is removed.
+func (r *PersesReconciler) reconcilePodDisruptionBudget(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	perses, ok := persesFromContext(ctx)
+	if !ok {
+		pdblog.Error("perses not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("perses not found in context"))
+	}
+
+	found := &policyv1.PodDisruptionBudget{}
+	if err := r.Get(ctx, types.NamespacedName{Name: perses.Name, Namespace: perses.Namespace}, found); err != nil {
+		if !apierrors.IsNotFound(err) {
+			pdblog.WithError(err).Error("Failed to get PodDisruptionBudget")
+			return subreconciler.RequeueWithError(err)
+		}
+
+		if perses.Spec.PodDisruptionBudget == nil {
+			return subreconciler.ContinueReconciling()
+		}
+
+		pdb, err2 := r.createPersesPodDisruptionBudget(perses)
+		if err2 != nil {
+			pdblog.WithError(err2).Error("Failed to define new PodDisruptionBudget resource for perses")
+			return subreconciler.RequeueWithError(err2)
+		}
+
+		if common.IsDryRun(perses, r.Config.DryRun) {
+			pdblog.Infof("Dry run, PodDisruptionBudget %s/%s would be created", pdb.Namespace, pdb.Name)
+			common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: PodDisruptionBudget %s/%s would be created", pdb.Namespace, pdb.Name)
+			return subreconciler.ContinueReconciling()
+		}
+
+		pdblog.Infof("Creating a new PodDisruptionBudget: PodDisruptionBudget.Namespace %s PodDisruptionBudget.Name %s", pdb.Namespace, pdb.Name)
+		if err = r.Create(ctx, pdb); err != nil {
+			pdblog.WithError(err).Errorf("Failed to create new PodDisruptionBudget: PodDisruptionBudget.Namespace %s PodDisruptionBudget.Name %s", pdb.Namespace, pdb.Name)
+			return subreconciler.RequeueWithError(err)
+		}
+		common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, common.EventReasonCreated, "Created PodDisruptionBudget %s/%s", pdb.Namespace, pdb.Name)
+
+		return subreconciler.ContinueReconciling()
+	}
+
+	if perses.Spec.PodDisruptionBudget == nil {
+		return r.deleteOwnedObject(ctx, perses, found, "PodDisruptionBudget", pdblog)
+	}
+
+	pdb, err := r.createPersesPodDisruptionBudget(perses)
+	if err != nil {
+		pdblog.WithError(err).Error("Failed to define new PodDisruptionBudget resource for perses")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	// call update with dry run to fill out fields that are also returned via the k8s api
+	if err = r.Update(ctx, pdb, client.DryRunAll); err != nil {
+		pdblog.WithError(err).Error("Failed to update PodDisruptionBudget with dry run")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	if !equality.Semantic.DeepEqual(found.Spec, pdb.Spec) || labelsChanged(found.Labels, pdb.Labels) {
+		if common.IsDryRun(perses, r.Config.DryRun) {
+			changed := common.FormatDiff(common.Diff("spec", found.Spec, pdb.Spec), common.MaxEventDiffLength)
+			pdblog.Infof("Dry run, PodDisruptionBudget %s/%s would be updated: %s", pdb.Namespace, pdb.Name, changed)
+			common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: PodDisruptionBudget %s/%s would be updated: %s", pdb.Namespace, pdb.Name, changed)
+			return subreconciler.ContinueReconciling()
+		}
+		if err = r.Update(ctx, pdb); err != nil {
+			pdblog.WithError(err).Error("Failed to update PodDisruptionBudget")
+			return subreconciler.RequeueWithError(err)
+		}
+		common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, common.EventReasonUpdated, "Updated PodDisruptionBudget %s/%s", pdb.Namespace, pdb.Name)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesReconciler) createPersesPodDisruptionBudget(perses *v1alpha2.Perses) (*policyv1.PodDisruptionBudget, error) {
+	ls := common.LabelsForPerses(perses.Name, perses)
+
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      perses.Name,
+			Namespace: perses.Namespace,
+			Labels:    ls,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable:   perses.Spec.PodDisruptionBudget.MinAvailable,
+			MaxUnavailable: perses.Spec.PodDisruptionBudget.MaxUnavailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: ls,
+			},
+		},
+	}
+
+	// Set the ownerRef for the PodDisruptionBudget
+	// More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/owners-dependents/
+	if err := ctrl.SetControllerReference(perses, pdb, r.Scheme); err != nil {
+		return nil, err
+	}
+	return pdb, nil
+}
@@ -17,11 +17,17 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
+	routev1 "github.com/openshift/api/route/v1"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	logger "github.com/sirupsen/logrus"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -31,13 +37,19 @@ import (
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	"github.com/perses/perses-operator/api/v1alpha2"
+	internalcache "github.com/perses/perses-operator/internal/cache"
 	operatormetrics "github.com/perses/perses-operator/internal/metrics"
+	"github.com/perses/perses-operator/internal/operator"
 	"github.com/perses/perses-operator/internal/perses/common"
 	"github.com/perses/perses-operator/internal/subreconciler"
 )
@@ -60,6 +72,17 @@ type Config struct {
 	TLSMinVersion        string
 	TLSCipherSuites      string
 	TLSConfigureOperands bool
+	// DryRun only plans the changes to the workloads of the Perses instances, without
+	// applying them, as for the instances with the perses.dev/dry-run annotation.
+	DryRun bool
+	// SkipAvailabilityChecks reports the Perses instances available as soon as their workload
+	// is applied, without waiting for its rollout nor checking the health of their API. It is
+	// meant for environments without workload controllers, such as envtest.
+	SkipAvailabilityChecks bool
+	// APIs reports the optional APIs served by the cluster. The Routes and HTTPRoutes exposing
+	// the Perses instances, and the ServiceMonitors and PodMonitors scraping them, are only
+	// managed when their API is served.
+	APIs operator.APIs
 }
 
 // PersesReconciler reconciles a Perses object
@@ -72,17 +95,36 @@ type PersesReconciler struct {
 	Metrics                *operatormetrics.Metrics
 	ReconciliationTracker  *operatormetrics.ReconciliationTracker
 	ClientCacheInvalidator common.PersesClientCacheInvalidator
+	// ClientFactory creates the client used to check the health of the Perses API.
+	// When nil, the health of the API is not checked.
+	ClientFactory common.PersesClientFactory
+	// ConfigMapCache holds the metadata of the ConfigMaps labeled for watching.
+	// When nil, changes to the ConfigMaps the Perses container reads its configuration from
+	// do not roll the pods until the next reconciliation.
+	ConfigMapCache cache.Cache
 }
 
 var log = logger.WithField("module", "perses_controller")
 
+// healthCheckRetryPeriod is the delay after which the health of the API of a Perses instance
+// whose rollout is complete is checked again, while the check fails.
+const healthCheckRetryPeriod = 15 * time.Second
+
 // +kubebuilder:rbac:groups=perses.dev,resources=perses,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=perses.dev,resources=perses/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=perses.dev,resources=perses/finalizers,verbs=update
 // +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=config.openshift.io,resources=apiservers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=route.openshift.io,resources=routes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=route.openshift.io,resources=routes/custom-host,verbs=create;update;patch
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gateways,verbs=get
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors;podmonitors,verbs=get;list;watch;create;update;patch;delete
 func (r *PersesReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	start := time.Now()
 	objKey := req.String()
@@ -123,23 +165,35 @@ func (r *PersesReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 	ctx = withPerses(ctx, perses)
 
 	subreconcilersForPerses := []subreconciler.FnWithRequest{
+		r.handleSuspend,
 		r.handleDelete,
+		r.handleDryRun,
 		r.setStatusToUnknown,
 		r.removeFinalizer,
 		r.validateVolumes,
+		r.validateScaling,
 		r.reconcileProvisioning,
 		r.reconcileService,
+		r.reconcileIngress,
+		r.reconcileRoute,
+		r.reconcileHTTPRoute,
+		r.reconcileExternalURL,
 		r.reconcileConfigMap,
 		r.reconcileDeployment,
 		r.reconcileStatefulSet,
+		r.reconcileHorizontalPodAutoscaler,
+		r.reconcilePodDisruptionBudget,
+		r.reconcileServiceMonitor,
+		r.reconcilePodMonitor,
 		r.setStatusToComplete,
 	}
 
 	// Run all subreconcilers sequentially
+	var reconcileResult *ctrl.Result
 	var reconcileErr error
 	for _, f := range subreconcilersForPerses {
-		if r, err := f(ctx, req); subreconciler.ShouldHaltOrRequeue(r, err) {
-			reconcileErr = err
+		if res, err := f(ctx, req); subreconciler.ShouldHaltOrRequeue(res, err) {
+			reconcileResult, reconcileErr = res, err
 			break
 		}
 	}
@@ -154,6 +208,11 @@ func (r *PersesReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 
 	// Track metrics
 	if r.Metrics != nil {
+		suspended := 0
+		if common.IsReconcilePaused(perses) {
+			suspended = 1
+		}
+		r.Metrics.SetSuspendedResources(objKey, "perses", req.Namespace, suspended)
 		if reconcileErr != nil {
 			reason := string(common.ExtractReason(reconcileErr, "reconciliation_failed"))
 			r.Metrics.ReconcileErrors("perses", reason).Inc()
@@ -164,10 +223,15 @@ func (r *PersesReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 	}
 
 	if reconcileErr != nil {
+		reason := string(common.ExtractReason(reconcileErr, "ReconciliationFailed"))
+		common.RecordEvent(r.Recorder, perses, corev1.EventTypeWarning, reason, "Failed to reconcile Perses instance: %v", reconcileErr)
 		return subreconciler.Evaluate(subreconciler.RequeueWithError(reconcileErr))
 	}
 
 	log.WithField("duration", time.Since(start)).Debug("reconciliation completed")
+	if reconcileResult != nil && reconcileResult.RequeueAfter > 0 {
+		return subreconciler.Evaluate(reconcileResult, nil)
+	}
 	return subreconciler.Evaluate(subreconciler.DoNotRequeue())
 }
 
@@ -305,6 +369,62 @@ func (r *PersesReconciler) handleDelete(ctx context.Context, req ctrl.Request) (
 	return subreconciler.ContinueReconciling()
 }
 
+// handleSuspend stops the reconciliation of a Perses instance paused with the perses.dev/reconcile
+// annotation, leaving its Deployment or StatefulSet, ConfigMap and Service untouched, and clears
+// the Suspended condition once it is resumed.
+// It runs before handleDelete, but an instance being deleted is never paused, so its deletion is not
+// blocked. The status is only written when the Suspended condition changes.
+func (r *PersesReconciler) handleSuspend(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	perses, ok := persesFromContext(ctx)
+	if !ok {
+		log.Error("perses not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("perses not found in context"))
+	}
+
+	paused := common.IsReconcilePaused(perses)
+	if common.SuspendedStatusChanged(perses.Status.Conditions, paused) {
+		if res, err := r.updatePersesStatus(ctx, req, func(p *v1alpha2.Perses) {
+			if paused {
+				meta.SetStatusCondition(&p.Status.Conditions, common.SuspendedCondition("Perses", p.Name))
+			} else {
+				meta.RemoveStatusCondition(&p.Status.Conditions, common.TypeSuspendedPerses)
+			}
+		}); subreconciler.ShouldHaltOrRequeue(res, err) {
+			return res, err
+		}
+	}
+
+	if !paused {
+		return subreconciler.ContinueReconciling()
+	}
+	log.Infof("Perses %s is paused, skipping reconciliation", req.String())
+	return subreconciler.DoNotRequeue()
+}
+
+// handleDryRun reports a Perses instance with the perses.dev/dry-run annotation, or reconciled
+// by an operator running with --dry-run, whose workload changes are recorded as events instead
+// of being applied, and clears the DryRun condition once the changes are applied again.
+func (r *PersesReconciler) handleDryRun(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	perses, ok := persesFromContext(ctx)
+	if !ok {
+		log.Error("perses not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("perses not found in context"))
+	}
+
+	if !common.IsDryRun(perses, r.Config.DryRun) {
+		return r.updatePersesStatus(ctx, req, func(p *v1alpha2.Perses) {
+			meta.RemoveStatusCondition(&p.Status.Conditions, common.TypeDryRunPerses)
+		})
+	}
+
+	return r.updatePersesStatus(ctx, req, func(p *v1alpha2.Perses) {
+		meta.SetStatusCondition(&p.Status.Conditions, metav1.Condition{
+			Type: common.TypeDryRunPerses, Status: metav1.ConditionTrue,
+			Reason:  string(common.ReasonDryRun),
+			Message: fmt.Sprintf("Dry run: the changes to the workload of Perses (%s) are recorded as events, not applied", p.Name)})
+	})
+}
+
 func (r *PersesReconciler) validateVolumes(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
 	perses, ok := persesFromContext(ctx)
 	if !ok {
@@ -340,15 +460,149 @@ func (r *PersesReconciler) validateVolumes(ctx context.Context, req ctrl.Request
 	return subreconciler.ContinueReconciling()
 }
 
+// validateScaling refuses the autoscaling and the PodDisruptionBudget of a Perses instance whose
+// database only supports a single replica.
+func (r *PersesReconciler) validateScaling(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	perses, ok := persesFromContext(ctx)
+	if !ok {
+		log.Error("perses not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("perses not found in context"))
+	}
+
+	if perses.SupportsHorizontalScaling() {
+		return subreconciler.ContinueReconciling()
+	}
+
+	var fields []string
+	if perses.Spec.Autoscaling != nil {
+		fields = append(fields, "spec.autoscaling")
+	}
+	if perses.Spec.PodDisruptionBudget != nil {
+		fields = append(fields, "spec.podDisruptionBudget")
+	}
+	if len(fields) == 0 {
+		return subreconciler.ContinueReconciling()
+	}
+
+	verb := "requires"
+	if len(fields) > 1 {
+		verb = "require"
+	}
+	msg := fmt.Sprintf("%s %s a SQL database, the file database only supports a single replica", strings.Join(fields, " and "), verb)
+	log.WithField("fields", fields).Error("scaling requested for a single replica Perses")
+	// The reconciliation halts before the availability of the instance is reported, so it is
+	// reported unavailable here.
+	if _, err := r.updatePersesStatus(ctx, req, func(p *v1alpha2.Perses) {
+		meta.SetStatusCondition(&p.Status.Conditions, metav1.Condition{
+			Type:    common.TypeDegradedPerses,
+			Status:  metav1.ConditionTrue,
+			Reason:  string(common.ReasonInvalidConfiguration),
+			Message: msg,
+		})
+		meta.SetStatusCondition(&p.Status.Conditions, metav1.Condition{
+			Type:    common.TypeAvailablePerses,
+			Status:  metav1.ConditionFalse,
+			Reason:  string(common.ReasonInvalidConfiguration),
+			Message: msg,
+		})
+	}); err != nil {
+		return subreconciler.RequeueWithError(err)
+	}
+	return subreconciler.RequeueWithError(fmt.Errorf("%s", msg))
+}
+
+// setStatusToComplete reports the Perses instance available while its workload has available
+// replicas and its API is healthy, with a Progressing condition following the rollout. The replicas
+// of the previous pod template keep the instance available during a rollout. While the instance is
+// not available, the controllers of the resources synced to it skip it.
 func (r *PersesReconciler) setStatusToComplete(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
-	return r.updatePersesStatus(ctx, req, func(perses *v1alpha2.Perses) {
-		meta.SetStatusCondition(&perses.Status.Conditions, metav1.Condition{
-			Type: common.TypeDegradedPerses, Status: metav1.ConditionFalse,
-			Reason: "Reconciled", Message: fmt.Sprintf("Perses (%s) reconciled successfully", perses.Name)})
-		meta.SetStatusCondition(&perses.Status.Conditions, metav1.Condition{
-			Type: common.TypeAvailablePerses, Status: metav1.ConditionTrue,
-			Reason: "Reconciled", Message: fmt.Sprintf("Perses (%s) created successfully", perses.Name)})
+	perses, ok := persesFromContext(ctx)
+	if !ok {
+		log.Error("perses not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("perses not found in context"))
+	}
+
+	// Nothing was applied in a dry run, so the availability of the instance is left as is.
+	if common.IsDryRun(perses, r.Config.DryRun) {
+		return subreconciler.ContinueReconciling()
+	}
+
+	if r.Config.SkipAvailabilityChecks {
+		return r.updatePersesStatus(ctx, req, func(perses *v1alpha2.Perses) {
+			setReconciledConditions(perses)
+		})
+	}
+
+	rollout, rolloutMessage, serving, err := r.workloadRollout(ctx, perses)
+	if err != nil {
+		log.WithError(err).Error("Failed to get the rollout of the Perses workload")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	var healthErr error
+	if serving && rollout != common.ReasonRolloutStalled {
+		if healthErr = r.checkHealth(ctx, perses); healthErr != nil {
+			log.WithError(healthErr).Infof("Perses %s is not healthy yet", req.String())
+		}
+	}
+
+	res, err := r.updatePersesStatus(ctx, req, func(perses *v1alpha2.Perses) {
+		setReconciledConditions(perses)
+
+		switch rollout {
+		case "":
+			meta.RemoveStatusCondition(&perses.Status.Conditions, common.TypeProgressingPerses)
+		case common.ReasonRolloutInProgress:
+			meta.SetStatusCondition(&perses.Status.Conditions, metav1.Condition{
+				Type: common.TypeProgressingPerses, Status: metav1.ConditionTrue,
+				Reason: string(rollout), Message: rolloutMessage})
+		default:
+			meta.SetStatusCondition(&perses.Status.Conditions, metav1.Condition{
+				Type: common.TypeProgressingPerses, Status: metav1.ConditionFalse,
+				Reason: string(rollout), Message: rolloutMessage})
+		}
+
+		switch {
+		case rollout == common.ReasonRolloutStalled:
+			meta.SetStatusCondition(&perses.Status.Conditions, metav1.Condition{
+				Type: common.TypeDegradedPerses, Status: metav1.ConditionTrue,
+				Reason: string(rollout), Message: rolloutMessage})
+			meta.SetStatusCondition(&perses.Status.Conditions, metav1.Condition{
+				Type: common.TypeAvailablePerses, Status: metav1.ConditionFalse,
+				Reason: string(rollout), Message: rolloutMessage})
+		case !serving:
+			meta.SetStatusCondition(&perses.Status.Conditions, metav1.Condition{
+				Type: common.TypeAvailablePerses, Status: metav1.ConditionFalse,
+				Reason:  string(common.ReasonAPIUnavailable),
+				Message: fmt.Sprintf("Perses (%s) has no available replica: %s", perses.Name, rolloutMessage)})
+		case healthErr != nil:
+			meta.SetStatusCondition(&perses.Status.Conditions, metav1.Condition{
+				Type: common.TypeAvailablePerses, Status: metav1.ConditionFalse,
+				Reason:  string(common.ReasonAPIUnavailable),
+				Message: fmt.Sprintf("Perses (%s) API health check failed: %s", perses.Name, healthErr)})
+		}
 	})
+	if subreconciler.ShouldHaltOrRequeue(res, err) {
+		return res, err
+	}
+
+	// The rollout is followed through the workload and pod watches, only the health of the API
+	// has to be checked again.
+	if healthErr != nil {
+		return subreconciler.RequeueWithDelay(healthCheckRetryPeriod)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+// setReconciledConditions reports the Perses instance reconciled and available.
+func setReconciledConditions(perses *v1alpha2.Perses) {
+	meta.SetStatusCondition(&perses.Status.Conditions, metav1.Condition{
+		Type: common.TypeDegradedPerses, Status: metav1.ConditionFalse,
+		Reason: "Reconciled", Message: fmt.Sprintf("Perses (%s) reconciled successfully", perses.Name)})
+	meta.SetStatusCondition(&perses.Status.Conditions, metav1.Condition{
+		Type: common.TypeAvailablePerses, Status: metav1.ConditionTrue,
+		Reason: "Reconciled", Message: fmt.Sprintf("Perses (%s) created successfully", perses.Name)})
 }
 
 func (r *PersesReconciler) reconcileProvisioning(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
@@ -408,48 +662,104 @@ func (r *PersesReconciler) reconcileProvisioning(ctx context.Context, req ctrl.R
 	})
 }
 
+// findPersesForSecret returns reconcile requests for the Perses instances that provision the
+// changed Secret or read their configuration from it.
 func (r *PersesReconciler) findPersesForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
-	// List all Perses objects in the same namespace as the secret
+	return r.findPersesReferencing(ctx, v1alpha2.SecretSourceTypeSecret, obj)
+}
+
+// findPersesForConfigMap returns reconcile requests for the Perses instances that read their
+// configuration from the changed ConfigMap.
+func (r *PersesReconciler) findPersesForConfigMap(ctx context.Context, obj *metav1.PartialObjectMetadata) []reconcile.Request {
+	return r.findPersesReferencing(ctx, v1alpha2.SecretSourceTypeConfigMap, obj)
+}
+
+func (r *PersesReconciler) findPersesReferencing(ctx context.Context, sourceType v1alpha2.SecretSourceType, obj client.Object) []reconcile.Request {
+	// List all Perses objects in the same namespace as the secret or configmap
 	persesList := &v1alpha2.PersesList{}
 	if err := r.List(ctx, persesList, client.InNamespace(obj.GetNamespace())); err != nil {
-		log.WithError(err).Errorf("failed to list Perses instances for secret %s/%s", obj.GetNamespace(), obj.GetName())
+		log.WithError(err).Errorf("failed to list Perses instances for %s %s/%s", sourceType, obj.GetNamespace(), obj.GetName())
 		return nil
 	}
 
 	var requests []reconcile.Request
 	for _, perses := range persesList.Items {
-		if perses.Spec.Provisioning == nil || len(perses.Spec.Provisioning.SecretRefs) == 0 {
-			continue
-		}
-		for _, ref := range perses.Spec.Provisioning.SecretRefs {
-			if ref.Name == obj.GetName() {
-				requests = append(requests, reconcile.Request{
-					NamespacedName: types.NamespacedName{
-						Name:      perses.Name,
-						Namespace: perses.Namespace,
-					},
-				})
-				break // found a match for this Perses instance, move to next
-			}
+		if provisions(&perses, sourceType, obj) || common.ConfigReferences(&perses, sourceType, obj) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      perses.Name,
+					Namespace: perses.Namespace,
+				},
+			})
 		}
 	}
 
 	return requests
 }
 
+// provisions reports whether the Perses instance provisions the given Secret.
+func provisions(perses *v1alpha2.Perses, sourceType v1alpha2.SecretSourceType, obj client.Object) bool {
+	if sourceType != v1alpha2.SecretSourceTypeSecret || perses.Spec.Provisioning == nil {
+		return false
+	}
+	for _, ref := range perses.Spec.Provisioning.SecretRefs {
+		if ref.Name == obj.GetName() {
+			return true
+		}
+	}
+	return false
+}
+
 // SetupWithManager sets up the controller with the Manager.
+// It watches the status of the owned workloads and the readiness of the Perses pods, so that the
+// Available and Progressing conditions follow the rollouts.
+// It watches the Secrets and, when ConfigMapCache is set, the ConfigMaps the Perses instances
+// provision or read their configuration from, so that a change rolls the Perses pods.
+// Only the Secrets and ConfigMaps matching the watch label selector of their cache trigger
+// reconciliation. The owned Routes and HTTPRoutes are only watched when their API is served.
 func (r *PersesReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha2.Perses{}).
 		Owns(&appsv1.Deployment{}).
 		Owns(&appsv1.StatefulSet{}).
 		Owns(&corev1.ConfigMap{}).
 		Owns(&corev1.Service{}).
+		Owns(&networkingv1.Ingress{}).
+		Owns(&autoscalingv2.HorizontalPodAutoscaler{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Watches(
+			&corev1.Pod{},
+			handler.EnqueueRequestsFromMapFunc(r.findPersesForPod),
+			builder.WithPredicates(podStatusPredicate()),
+		).
 		// WatchesMetadata only caches metadata (not Data) to reduce memory.
-		// Actual secret data is read via APIReader in reconcileProvisioning.
+		// Actual secret data is read via APIReader in reconcileProvisioning and
+		// when computing the config checksum of the pod template.
 		WatchesMetadata(
 			&corev1.Secret{},
 			handler.EnqueueRequestsFromMapFunc(r.findPersesForSecret),
-		).
-		Complete(r)
+		)
+
+	if r.Config.APIs.Route {
+		b = b.Owns(&routev1.Route{})
+	}
+	if r.Config.APIs.HTTPRoute {
+		b = b.Owns(&gatewayv1.HTTPRoute{})
+	}
+	if r.Config.APIs.ServiceMonitor {
+		b = b.Owns(&monitoringv1.ServiceMonitor{})
+	}
+	if r.Config.APIs.PodMonitor {
+		b = b.Owns(&monitoringv1.PodMonitor{})
+	}
+
+	if r.ConfigMapCache != nil {
+		b = b.WatchesRawSource(source.Kind(
+			r.ConfigMapCache,
+			internalcache.ConfigMapMetadata(),
+			handler.TypedEnqueueRequestsFromMapFunc(r.findPersesForConfigMap),
+		))
+	}
+
+	return b.Complete(r)
 }
@@ -0,0 +1,185 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perses
+
+import (
+	"context"
+	"fmt"
+
+	routev1 "github.com/openshift/api/route/v1"
+	logger "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
+	"github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses-operator/internal/subreconciler"
+)
+
+var rlog = logger.WithField("module", "route_controller")
+
+// reconcileRoute creates or updates the OpenShift Route exposing the Perses instance as specified
+// by spec.route, and deletes it once spec.route is removed. It does nothing when the
+// route.openshift.io API is not served by the cluster.
+func (r *PersesReconciler) reconcileRoute(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	perses, ok := persesFromContext(ctx)
+	if !ok {
+		rlog.Error("perses not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("perses not found in context"))
+	}
+
+	if !r.Config.APIs.Route {
+		if perses.Spec.Route != nil {
+			return r.apiNotServed(perses, "spec.route", "Route", routev1.GroupVersion.String(), rlog)
+		}
+		return subreconciler.ContinueReconciling()
+	}
+
+	found := &routev1.Route{}
+	if err := r.Get(ctx, types.NamespacedName{Name: perses.Name, Namespace: perses.Namespace}, found); err != nil {
+		if !apierrors.IsNotFound(err) {
+			rlog.WithError(err).Error("Failed to get Route")
+			return subreconciler.RequeueWithError(err)
+		}
+
+		if perses.Spec.Route == nil {
+			return subreconciler.ContinueReconciling()
+		}
+
+		route, err2 := r.createPersesRoute(perses, "")
+		if err2 != nil {
+			rlog.WithError(err2).Error("Failed to define new Route resource for perses")
+			return subreconciler.RequeueWithError(err2)
+		}
+
+		if common.IsDryRun(perses, r.Config.DryRun) {
+			rlog.Infof("Dry run, Route %s/%s would be created", route.Namespace, route.Name)
+			common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: Route %s/%s would be created", route.Namespace, route.Name)
+			return subreconciler.ContinueReconciling()
+		}
+
+		rlog.Infof("Creating a new Route: Route.Namespace %s Route.Name %s", route.Namespace, route.Name)
+		if err = r.Create(ctx, route); err != nil {
+			rlog.WithError(err).Errorf("Failed to create new Route: Route.Namespace %s Route.Name %s", route.Namespace, route.Name)
+			return subreconciler.RequeueWithError(err)
+		}
+		common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, common.EventReasonCreated, "Created Route %s/%s", route.Namespace, route.Name)
+
+		return subreconciler.ContinueReconciling()
+	}
+
+	if perses.Spec.Route == nil {
+		return r.deleteOwnedObject(ctx, perses, found, "Route", rlog)
+	}
+
+	route, err := r.createPersesRoute(perses, found.Spec.Host)
+	if err != nil {
+		rlog.WithError(err).Error("Failed to define new Route resource for perses")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	// call update with dry run to fill out fields that are also returned via the k8s api
+	if err = r.Update(ctx, route, client.DryRunAll); err != nil {
+		rlog.WithError(err).Error("Failed to update Route with dry run")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	if routeNeedsUpdate(found, route) {
+		if common.IsDryRun(perses, r.Config.DryRun) {
+			changed := common.FormatDiff(common.Diff("spec", found.Spec, route.Spec), common.MaxEventDiffLength)
+			rlog.Infof("Dry run, Route %s/%s would be updated: %s", route.Namespace, route.Name, changed)
+			common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: Route %s/%s would be updated: %s", route.Namespace, route.Name, changed)
+			return subreconciler.ContinueReconciling()
+		}
+		if err = r.Update(ctx, route); err != nil {
+			rlog.WithError(err).Error("Failed to update Route")
+			return subreconciler.RequeueWithError(err)
+		}
+		common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, common.EventReasonUpdated, "Updated Route %s/%s", route.Namespace, route.Name)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func routeNeedsUpdate(existing, updated *routev1.Route) bool {
+	return !equality.Semantic.DeepEqual(existing.Spec, updated.Spec) ||
+		!equality.Semantic.DeepEqual(existing.Annotations, updated.Annotations) ||
+		labelsChanged(existing.Labels, updated.Labels)
+}
+
+// createPersesRoute defines the Route exposing the Perses instance. When spec.route.host is not
+// set, the given host, generated by the router when the Route was created, is kept.
+func (r *PersesReconciler) createPersesRoute(perses *v1alpha2.Perses, generatedHost string) (*routev1.Route, error) {
+	spec := perses.Spec.Route
+
+	host := generatedHost
+	if spec.Host != nil {
+		host = *spec.Host
+	}
+
+	route := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        perses.Name,
+			Namespace:   perses.Namespace,
+			Annotations: exposingAnnotations(perses, spec.Annotations),
+			Labels:      common.LabelsForPerses(perses.Name, perses),
+		},
+		Spec: routev1.RouteSpec{
+			Host: host,
+			Path: routePath(perses),
+			To: routev1.RouteTargetReference{
+				Kind: "Service",
+				Name: common.ServiceName(perses),
+			},
+			Port: &routev1.RoutePort{
+				TargetPort: intstr.FromString("http"),
+			},
+		},
+	}
+
+	if spec.TLS != nil {
+		route.Spec.TLS = &routev1.TLSConfig{
+			Termination: routev1.TLSTerminationType(spec.TLS.Termination),
+		}
+		if spec.TLS.InsecureEdgeTerminationPolicy != nil {
+			route.Spec.TLS.InsecureEdgeTerminationPolicy = routev1.InsecureEdgeTerminationPolicyType(*spec.TLS.InsecureEdgeTerminationPolicy)
+		}
+	}
+
+	// Set the ownerRef for the Route
+	// More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/owners-dependents/
+	if err := ctrl.SetControllerReference(perses, route, r.Scheme); err != nil {
+		return nil, err
+	}
+	return route, nil
+}
+
+// routePath returns the path of the Route exposing the Perses instance, empty for the whole host.
+func routePath(perses *v1alpha2.Perses) string {
+	spec := perses.Spec.Route
+	// the router cannot route passthrough requests on their path, as they are encrypted
+	if spec.TLS != nil && spec.TLS.Termination == string(routev1.TLSTerminationPassthrough) {
+		return ""
+	}
+	if path := common.ExposedPath(perses, spec.Path); path != "/" {
+		return path
+	}
+	return ""
+}
@@ -0,0 +1,203 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perses
+
+import (
+	"context"
+	"testing"
+
+	persesconfig "github.com/perses/perses/pkg/model/api/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
+	"github.com/perses/perses-operator/internal/perses/common"
+)
+
+func newScalingReconciler(t *testing.T, objs ...runtime.Object) *PersesReconciler {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha2.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, appsv1.AddToScheme(scheme))
+	require.NoError(t, autoscalingv2.AddToScheme(scheme))
+	require.NoError(t, policyv1.AddToScheme(scheme))
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithRuntimeObjects(objs...).
+		WithStatusSubresource(&v1alpha2.Perses{}).
+		Build()
+
+	return &PersesReconciler{Client: fakeClient, APIReader: fakeClient, Scheme: scheme}
+}
+
+func newScaledPerses() *v1alpha2.Perses {
+	return &v1alpha2.Perses{
+		ObjectMeta: metav1.ObjectMeta{Name: "perses", Namespace: "default", UID: "perses-uid"},
+		Spec: v1alpha2.PersesSpec{
+			Image:    ptr.To("docker.io/persesdev/perses:latest"),
+			Replicas: ptr.To[int32](3),
+			Config: v1alpha2.PersesConfig{
+				Config: persesconfig.Config{
+					Database: persesconfig.Database{SQL: &persesconfig.SQL{}},
+				},
+			},
+			Autoscaling: &v1alpha2.PersesAutoscaling{
+				MinReplicas:                       ptr.To[int32](2),
+				MaxReplicas:                       5,
+				TargetMemoryUtilizationPercentage: ptr.To[int32](75),
+			},
+			PodDisruptionBudget: &v1alpha2.PersesPodDisruptionBudget{
+				MinAvailable: ptr.To(intstr.FromInt32(1)),
+			},
+		},
+	}
+}
+
+func TestCreatePersesHorizontalPodAutoscaler(t *testing.T) {
+	perses := newScaledPerses()
+
+	hpa, err := newScalingReconciler(t).createPersesHorizontalPodAutoscaler(perses)
+	require.NoError(t, err)
+
+	assert.Equal(t, autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "perses"}, hpa.Spec.ScaleTargetRef)
+	assert.Equal(t, ptr.To[int32](2), hpa.Spec.MinReplicas)
+	assert.Equal(t, int32(5), hpa.Spec.MaxReplicas)
+	require.Len(t, hpa.Spec.Metrics, 1)
+	assert.Equal(t, corev1.ResourceMemory, hpa.Spec.Metrics[0].Resource.Name)
+	assert.Equal(t, ptr.To[int32](75), hpa.Spec.Metrics[0].Resource.Target.AverageUtilization)
+	require.Len(t, hpa.OwnerReferences, 1)
+	assert.Equal(t, perses.UID, hpa.OwnerReferences[0].UID)
+}
+
+func TestCreatePersesPodDisruptionBudget(t *testing.T) {
+	perses := newScaledPerses()
+
+	pdb, err := newScalingReconciler(t).createPersesPodDisruptionBudget(perses)
+	require.NoError(t, err)
+
+	assert.Equal(t, ptr.To(intstr.FromInt32(1)), pdb.Spec.MinAvailable)
+	assert.Nil(t, pdb.Spec.MaxUnavailable)
+	assert.Equal(t, common.LabelsForPerses(perses.Name, perses), pdb.Spec.Selector.MatchLabels)
+}
+
+func TestCreatePersesDeployment_Autoscaling(t *testing.T) {
+	perses := newScaledPerses()
+
+	dep, err := newScalingReconciler(t).createPersesDeployment(context.Background(), perses)
+	require.NoError(t, err)
+
+	assert.Equal(t, ptr.To[int32](2), dep.Spec.Replicas, "an autoscaled Deployment starts from minReplicas")
+}
+
+func TestReconcileDeployment_KeepsAutoscaledReplicas(t *testing.T) {
+	perses := newScaledPerses()
+	r := newScalingReconciler(t, perses)
+	ctx := withPerses(context.Background(), perses)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: perses.Name, Namespace: perses.Namespace}}
+
+	dep, err := r.createPersesDeployment(ctx, perses)
+	require.NoError(t, err)
+	dep.Spec.Replicas = ptr.To[int32](4)
+	require.NoError(t, r.Create(ctx, dep))
+
+	_, err = r.reconcileDeployment(ctx, req)
+	require.NoError(t, err)
+
+	updated := &appsv1.Deployment{}
+	require.NoError(t, r.Get(ctx, req.NamespacedName, updated))
+	assert.Equal(t, ptr.To[int32](4), updated.Spec.Replicas, "the replicas set by the HorizontalPodAutoscaler must be kept")
+}
+
+func TestReconcileHorizontalPodAutoscaler_DeletedWhenRemoved(t *testing.T) {
+	perses := newScaledPerses()
+	r := newScalingReconciler(t, perses)
+	ctx := withPerses(context.Background(), perses)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: perses.Name, Namespace: perses.Namespace}}
+
+	_, err := r.reconcileHorizontalPodAutoscaler(ctx, req)
+	require.NoError(t, err)
+	require.NoError(t, r.Get(ctx, req.NamespacedName, &autoscalingv2.HorizontalPodAutoscaler{}))
+
+	perses.Spec.Autoscaling = nil
+	_, err = r.reconcileHorizontalPodAutoscaler(ctx, req)
+	require.NoError(t, err)
+
+	hpas := &autoscalingv2.HorizontalPodAutoscalerList{}
+	require.NoError(t, r.List(ctx, hpas))
+	assert.Empty(t, hpas.Items)
+}
+
+func TestValidateScaling_FileDatabase(t *testing.T) {
+	perses := newScaledPerses()
+	perses.Spec.Config.Database = persesconfig.Database{File: &persesconfig.File{Folder: "/perses"}}
+	r := newScalingReconciler(t, perses)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: perses.Name, Namespace: perses.Namespace}}
+
+	_, err := r.validateScaling(withPerses(context.Background(), perses), req)
+	require.EqualError(t, err, "spec.autoscaling and spec.podDisruptionBudget require a SQL database, the file database only supports a single replica")
+
+	updated := &v1alpha2.Perses{}
+	require.NoError(t, r.Get(context.Background(), req.NamespacedName, updated))
+	degraded := meta.FindStatusCondition(updated.Status.Conditions, common.TypeDegradedPerses)
+	require.NotNil(t, degraded)
+	assert.Equal(t, metav1.ConditionTrue, degraded.Status)
+	assert.Equal(t, string(common.ReasonInvalidConfiguration), degraded.Reason)
+	available := meta.FindStatusCondition(updated.Status.Conditions, common.TypeAvailablePerses)
+	require.NotNil(t, available)
+	assert.Equal(t, metav1.ConditionFalse, available.Status)
+	assert.Equal(t, string(common.ReasonInvalidConfiguration), available.Reason)
+}
+
+func TestValidateScaling_FileDatabaseWasAvailable(t *testing.T) {
+	perses := newScaledPerses()
+	perses.Spec.Config.Database = persesconfig.Database{File: &persesconfig.File{Folder: "/perses"}}
+	perses.Spec.Autoscaling = nil
+	perses.Status.Conditions = []metav1.Condition{{
+		Type: common.TypeAvailablePerses, Status: metav1.ConditionTrue,
+		Reason: "Reconciled", LastTransitionTime: metav1.Now(),
+	}}
+	r := newScalingReconciler(t, perses)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: perses.Name, Namespace: perses.Namespace}}
+
+	_, err := r.validateScaling(withPerses(context.Background(), perses), req)
+	require.EqualError(t, err, "spec.podDisruptionBudget requires a SQL database, the file database only supports a single replica")
+
+	updated := &v1alpha2.Perses{}
+	require.NoError(t, r.Get(context.Background(), req.NamespacedName, updated))
+	assert.True(t, meta.IsStatusConditionTrue(updated.Status.Conditions, common.TypeDegradedPerses))
+	assert.True(t, meta.IsStatusConditionFalse(updated.Status.Conditions, common.TypeAvailablePerses))
+}
+
+func TestValidateScaling_SQLDatabase(t *testing.T) {
+	perses := newScaledPerses()
+	r := newScalingReconciler(t, perses)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: perses.Name, Namespace: perses.Namespace}}
+
+	res, err := r.validateScaling(withPerses(context.Background(), perses), req)
+	require.NoError(t, err)
+	assert.Nil(t, res)
+}
@@ -43,10 +43,7 @@ func (r *PersesReconciler) reconcileService(ctx context.Context, req ctrl.Reques
 		return subreconciler.RequeueWithError(fmt.Errorf("perses not found in context"))
 	}
 
-	serviceName := perses.Name
-	if perses.Spec.Service != nil && perses.Spec.Service.Name != nil && len(*perses.Spec.Service.Name) > 0 {
-		serviceName = *perses.Spec.Service.Name
-	}
+	serviceName := common.ServiceName(perses)
 
 	found := &corev1.Service{}
 	if err := r.Get(ctx, types.NamespacedName{Name: serviceName, Namespace: perses.Namespace}, found); err != nil {
@@ -72,11 +69,18 @@ func (r *PersesReconciler) reconcileService(ctx context.Context, req ctrl.Reques
 			return subreconciler.RequeueWithError(err2)
 		}
 
+		if common.IsDryRun(perses, r.Config.DryRun) {
+			slog.Infof("Dry run, Service %s/%s would be created", ser.Namespace, ser.Name)
+			common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: Service %s/%s would be created", ser.Namespace, ser.Name)
+			return subreconciler.ContinueReconciling()
+		}
+
 		slog.Infof("Creating a new Service: Service.Namespace %s Service.Name %s", ser.Namespace, ser.Name)
 		if err = r.Create(ctx, ser); err != nil {
 			slog.WithError(err).Errorf("Failed to create new Service: Service.Namespace %s Service.Name %s", ser.Namespace, ser.Name)
 			return subreconciler.RequeueWithError(err)
 		}
+		common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, common.EventReasonCreated, "Created Service %s/%s", ser.Namespace, ser.Name)
 
 		return subreconciler.ContinueReconciling()
 	}
@@ -94,10 +98,17 @@ func (r *PersesReconciler) reconcileService(ctx context.Context, req ctrl.Reques
 	}
 
 	if serviceNeedsUpdate(found, svc, perses.Name, perses) {
+		if common.IsDryRun(perses, r.Config.DryRun) {
+			changed := common.FormatDiff(common.Diff("spec", found.Spec, svc.Spec), common.MaxEventDiffLength)
+			slog.Infof("Dry run, Service %s/%s would be updated: %s", svc.Namespace, svc.Name, changed)
+			common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: Service %s/%s would be updated: %s", svc.Namespace, svc.Name, changed)
+			return subreconciler.ContinueReconciling()
+		}
 		if err = r.Update(ctx, svc); err != nil {
 			slog.WithError(err).Error("Failed to update Service")
 			return subreconciler.RequeueWithError(err)
 		}
+		common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, common.EventReasonUpdated, "Updated Service %s/%s", svc.Namespace, svc.Name)
 	}
 
 	return subreconciler.ContinueReconciling()
@@ -147,10 +158,7 @@ func (r *PersesReconciler) createPersesService(
 		maps.Copy(annotations, perses.Spec.Service.Annotations)
 	}
 
-	port := common.DefaultContainerPort
-	if perses.Spec.ContainerPort != nil {
-		port = *perses.Spec.ContainerPort
-	}
+	port := common.ServicePort(perses)
 
 	ser := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
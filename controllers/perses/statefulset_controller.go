@@ -50,11 +50,17 @@ func (r *PersesReconciler) reconcileStatefulSet(ctx context.Context, req ctrl.Re
 		found := &appsv1.StatefulSet{}
 		err := r.Get(ctx, types.NamespacedName{Name: perses.Name, Namespace: perses.Namespace}, found)
 		if err == nil {
+			if common.IsDryRun(perses, r.Config.DryRun) {
+				stlog.Infof("Dry run, StatefulSet %s/%s would be deleted since configuration changed", found.Namespace, found.Name)
+				common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: StatefulSet %s/%s would be deleted since the storage configuration changed", found.Namespace, found.Name)
+				return subreconciler.ContinueReconciling()
+			}
 			stlog.Info("Deleting StatefulSet since configuration changed")
 			if err := r.Delete(ctx, found); err != nil {
 				stlog.WithError(err).Error("Failed to delete StatefulSet")
 				return subreconciler.RequeueWithError(err)
 			}
+			common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, common.EventReasonDeleted, "Deleted StatefulSet %s/%s since the storage configuration changed", found.Namespace, found.Name)
 		}
 
 		return subreconciler.ContinueReconciling()
@@ -68,7 +74,7 @@ func (r *PersesReconciler) reconcileStatefulSet(ctx context.Context, req ctrl.Re
 			return subreconciler.RequeueWithError(err)
 		}
 
-		sts, err := r.createPersesStatefulSet(perses)
+		sts, err := r.createPersesStatefulSet(ctx, perses)
 		if err != nil {
 			stlog.WithError(err).Error("Failed to define new StatefulSet resource for perses")
 
@@ -84,16 +90,23 @@ func (r *PersesReconciler) reconcileStatefulSet(ctx context.Context, req ctrl.Re
 			return subreconciler.RequeueWithError(err)
 		}
 
+		if common.IsDryRun(perses, r.Config.DryRun) {
+			stlog.Infof("Dry run, StatefulSet %s/%s would be created", sts.Namespace, sts.Name)
+			common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: StatefulSet %s/%s would be created", sts.Namespace, sts.Name)
+			return subreconciler.ContinueReconciling()
+		}
+
 		stlog.Infof("Creating a new StatefulSet: StatefulSet.Namespace %s StatefulSet.Name %s", sts.Namespace, sts.Name)
 		if err = r.Create(ctx, sts); err != nil {
 			stlog.WithError(err).Errorf("Failed to create new StatefulSet: StatefulSet.Namespace %s StatefulSet.Name %s", sts.Namespace, sts.Name)
 			return subreconciler.RequeueWithError(err)
 		}
+		common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, common.EventReasonCreated, "Created StatefulSet %s/%s", sts.Namespace, sts.Name)
 
 		return subreconciler.RequeueWithDelay(time.Minute)
 	}
 
-	sts, err := r.createPersesStatefulSet(perses)
+	sts, err := r.createPersesStatefulSet(ctx, perses)
 	if err != nil {
 		stlog.WithError(err).Error("Failed to define new StatefulSet resource for perses")
 		return subreconciler.RequeueWithError(err)
@@ -106,16 +119,25 @@ func (r *PersesReconciler) reconcileStatefulSet(ctx context.Context, req ctrl.Re
 	}
 
 	if !equality.Semantic.DeepEqual(found.Spec, sts.Spec) {
+		if common.IsDryRun(perses, r.Config.DryRun) {
+			changed := common.FormatDiff(common.Diff("spec", found.Spec, sts.Spec), common.MaxEventDiffLength)
+			stlog.Infof("Dry run, StatefulSet %s/%s would be updated: %s", sts.Namespace, sts.Name, changed)
+			common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: StatefulSet %s/%s would be updated: %s", sts.Namespace, sts.Name, changed)
+			return subreconciler.ContinueReconciling()
+		}
 		if err = r.Update(ctx, sts); err != nil {
 			stlog.WithError(err).Error("Failed to update StatefulSet")
 			return subreconciler.RequeueWithError(err)
 		}
+		changes := common.Diff("spec", found.Spec, sts.Spec)
+		stlog.WithField("diff", common.FormatDiff(changes, 0)).Debugf("StatefulSet %s/%s updated", sts.Namespace, sts.Name)
+		common.RecordEvent(r.Recorder, perses, corev1.EventTypeNormal, common.EventReasonUpdated, "Updated StatefulSet %s/%s: %s", sts.Namespace, sts.Name, common.FormatDiff(changes, common.MaxEventDiffLength))
 	}
 
 	return subreconciler.ContinueReconciling()
 }
 
-func (r *PersesReconciler) createPersesStatefulSet(
+func (r *PersesReconciler) createPersesStatefulSet(ctx context.Context,
 	perses *v1alpha2.Perses) (*appsv1.StatefulSet, error) {
 
 	ls := common.LabelsForPerses(perses.Name, perses)
@@ -133,6 +155,11 @@ func (r *PersesReconciler) createPersesStatefulSet(
 		annotations[common.PersesProvisioningVersion] = provisioningHash
 	}
 
+	podAnnotations, err := r.podAnnotations(ctx, perses, annotations)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get the Operand image
 	image, err := common.ImageForPerses(perses, r.Config.PersesImage)
 	if err != nil {
@@ -155,7 +182,7 @@ func (r *PersesReconciler) createPersesStatefulSet(
 			Replicas: perses.Spec.Replicas,
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Annotations: annotations,
+					Annotations: podAnnotations,
 					Labels:      ls,
 				},
 				Spec: corev1.PodSpec{
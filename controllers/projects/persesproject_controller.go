@@ -0,0 +1,348 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package projects
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	logger "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	operatormetrics "github.com/perses/perses-operator/internal/metrics"
+	"github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses-operator/internal/subreconciler"
+)
+
+type projectContextKey string
+
+const contextKey projectContextKey = "project"
+
+func withProject(ctx context.Context, project *persesv1alpha2.PersesProject) context.Context {
+	return context.WithValue(ctx, contextKey, project)
+}
+
+func projectFromContext(ctx context.Context) (*persesv1alpha2.PersesProject, bool) {
+	project, ok := ctx.Value(contextKey).(*persesv1alpha2.PersesProject)
+	return project, ok
+}
+
+// PersesProjectReconciler reconciles a PersesProject object
+type PersesProjectReconciler struct {
+	client.Client
+	APIReader             client.Reader // uncached reader — OnlyMetadata watch caches metadata only
+	Scheme                *runtime.Scheme
+	Recorder              record.EventRecorder
+	ClientFactory         common.PersesClientFactory
+	Metrics               *operatormetrics.Metrics
+	ReconciliationTracker *operatormetrics.ReconciliationTracker
+	// DryRun only plans the changes to the projects in Perses, without applying them,
+	// as for the projects with the perses.dev/dry-run annotation.
+	DryRun bool
+}
+
+var log = logger.WithField("module", "perses_projects_controller")
+
+// +kubebuilder:rbac:groups=perses.dev,resources=persesprojects,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=perses.dev,resources=persesprojects/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=perses.dev,resources=persesprojects/finalizers,verbs=update
+func (r *PersesProjectReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	start := time.Now()
+	objKey := req.String()
+
+	if r.Metrics != nil {
+		r.Metrics.ReconcileOperations("persesproject").Inc()
+	}
+
+	log.Infof("Reconciling PersesProject: %s/%s", req.Namespace, req.Name)
+
+	// Find once and store in context for all sub-reconcilers.
+	// Deletion is handled through the finalizer, so a missing object has nothing left to clean up.
+	project := &persesv1alpha2.PersesProject{}
+	if err := r.APIReader.Get(ctx, req.NamespacedName, project); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Infof("perses project resource not found. Ignoring since object must be deleted")
+			if r.ReconciliationTracker != nil {
+				r.ReconciliationTracker.ForgetObject(objKey)
+			}
+			if r.Metrics != nil {
+				r.Metrics.ForgetObject(objKey)
+			}
+			return subreconciler.Evaluate(subreconciler.DoNotRequeue())
+		}
+		log.WithError(err).Error("Failed to get perses project")
+		if r.Metrics != nil {
+			r.Metrics.ReconcileErrors("persesproject", "get_failed").Inc()
+		}
+		return subreconciler.Evaluate(subreconciler.RequeueWithError(err))
+	}
+
+	ctx = withProject(ctx, project)
+
+	subreconcilersForPerses := []subreconciler.FnWithRequest{
+		r.handleDelete,
+		r.addFinalizer,
+		r.setStatusToUnknown,
+		r.reconcileProjectInAllInstances,
+		r.setStatusToComplete,
+	}
+
+	var reconcileErr error
+	for _, f := range subreconcilersForPerses {
+		if r, err := f(ctx, req); subreconciler.ShouldHaltOrRequeue(r, err) {
+			reconcileErr = err
+			break
+		}
+	}
+
+	// Track reconciliation status
+	if r.ReconciliationTracker != nil {
+		r.ReconciliationTracker.SetStatus(objKey, reconcileErr)
+		if reconcileErr == nil {
+			r.ReconciliationTracker.SetReasonAndMessage(objKey, "ReconciliationSuccessful", "Project reconciled successfully")
+		}
+	}
+
+	// Track metrics
+	if r.Metrics != nil {
+		if reconcileErr != nil {
+			reason := string(common.ExtractReason(reconcileErr, "reconciliation_failed"))
+			r.Metrics.ReconcileErrors("persesproject", reason).Inc()
+			r.Metrics.SetFailedResources(objKey, "project", req.Namespace, 1)
+		} else {
+			r.Metrics.SetSyncedResources(objKey, "project", req.Namespace, 1)
+		}
+	}
+
+	if reconcileErr != nil {
+		return subreconciler.Evaluate(subreconciler.RequeueWithError(reconcileErr))
+	}
+
+	log.WithField("duration", time.Since(start)).Debug("project reconciliation completed")
+	return subreconciler.Evaluate(subreconciler.DoNotRequeue())
+}
+
+// handleDelete removes the project from the selected Perses instances when the
+// deletion policy asks for it, then releases the finalizer.
+func (r *PersesProjectReconciler) handleDelete(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	project, ok := projectFromContext(ctx)
+	if !ok {
+		log.Error("project not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("project not found in context"))
+	}
+
+	if project.GetDeletionTimestamp() == nil {
+		return subreconciler.ContinueReconciling()
+	}
+
+	if !controllerutil.ContainsFinalizer(project, common.PersesFinalizer) {
+		return subreconciler.DoNotRequeue()
+	}
+
+	if project.Spec.DeletionPolicy == persesv1alpha2.ProjectDeletionPolicyDelete {
+		if res, err := r.deleteProjectInAllInstances(ctx, project); subreconciler.ShouldHaltOrRequeue(res, err) {
+			return res, err
+		}
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesProject{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		if !controllerutil.RemoveFinalizer(fresh, common.PersesFinalizer) {
+			return nil
+		}
+		return r.Update(ctx, fresh)
+	})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to remove finalizer from perses project")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	log.Infof("PersesProject %s/%s deleted", project.Namespace, project.Name)
+	return subreconciler.DoNotRequeue()
+}
+
+// addFinalizer makes sure the project is not removed from the cluster before
+// its deletion policy has been applied to the Perses instances.
+func (r *PersesProjectReconciler) addFinalizer(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	project, ok := projectFromContext(ctx)
+	if !ok {
+		log.Error("project not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("project not found in context"))
+	}
+
+	if controllerutil.ContainsFinalizer(project, common.PersesFinalizer) {
+		return subreconciler.ContinueReconciling()
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesProject{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		if !controllerutil.AddFinalizer(fresh, common.PersesFinalizer) {
+			return nil
+		}
+		return r.Update(ctx, fresh)
+	})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to add finalizer to perses project")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesProjectReconciler) updateProjectStatus(
+	ctx context.Context,
+	req ctrl.Request,
+	updateFn func(*persesv1alpha2.PersesProject),
+) (*ctrl.Result, error) {
+	_, ok := projectFromContext(ctx)
+	if !ok {
+		log.Error("project not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("project not found in context"))
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesProject{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		before := common.SnapshotConditions(fresh.Status.Conditions)
+		updateFn(fresh)
+		if !common.ConditionsChanged(before, fresh.Status.Conditions) {
+			return nil
+		}
+		return r.Status().Update(ctx, fresh)
+	})
+
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("perses project resource not found. Ignoring since object must be deleted")
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to update Perses project status")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesProjectReconciler) setStatusToUnknown(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	return r.updateProjectStatus(ctx, req, func(project *persesv1alpha2.PersesProject) {
+		if len(project.Status.Conditions) == 0 {
+			meta.SetStatusCondition(&project.Status.Conditions, metav1.Condition{
+				Type: common.TypeAvailablePerses, Status: metav1.ConditionUnknown,
+				Reason: "Reconciling", Message: "Starting reconciliation"})
+		}
+	})
+}
+
+func (r *PersesProjectReconciler) setStatusToComplete(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	// The observed state is the one that was synced, the custom resource may have changed since.
+	synced, ok := projectFromContext(ctx)
+	if !ok {
+		log.Error("project not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("project not found in context"))
+	}
+
+	// Nothing was applied in a dry run, so the availability of the project is left as is.
+	if common.IsDryRun(synced, r.DryRun) {
+		return subreconciler.ContinueReconciling()
+	}
+
+	return r.updateProjectStatus(ctx, req, func(project *persesv1alpha2.PersesProject) {
+		meta.RemoveStatusCondition(&project.Status.Conditions, common.TypeDryRunPerses)
+		meta.SetStatusCondition(&project.Status.Conditions, metav1.Condition{
+			Type: common.TypeDegradedPerses, Status: metav1.ConditionFalse,
+			Reason: "Reconciled", Message: fmt.Sprintf("Project (%s) reconciled successfully", project.Namespace)})
+		meta.SetStatusCondition(&project.Status.Conditions, metav1.Condition{
+			Type: common.TypeAvailablePerses, Status: metav1.ConditionTrue,
+			Reason: "Reconciled", Message: fmt.Sprintf("Project (%s) created successfully", project.Namespace)})
+	})
+}
+
+func (r *PersesProjectReconciler) setStatusToDegraded(
+	ctx context.Context,
+	req ctrl.Request,
+	degradedResult *ctrl.Result,
+	degradedReason common.ConditionStatusReason,
+	degradedError error,
+) (*ctrl.Result, error) {
+	msg := "unknown error"
+	if degradedError != nil {
+		msg = degradedError.Error()
+	}
+
+	result, err := r.updateProjectStatus(ctx, req, func(project *persesv1alpha2.PersesProject) {
+		meta.SetStatusCondition(&project.Status.Conditions, metav1.Condition{
+			Type: common.TypeAvailablePerses, Status: metav1.ConditionFalse,
+			Reason: string(degradedReason), Message: msg})
+		meta.SetStatusCondition(&project.Status.Conditions, metav1.Condition{
+			Type: common.TypeDegradedPerses, Status: metav1.ConditionTrue,
+			Reason: string(degradedReason), Message: msg})
+	})
+
+	if err != nil {
+		return result, err
+	}
+
+	if degradedError != nil {
+		return degradedResult, common.NewReasonError(degradedError, degradedReason)
+	}
+	return degradedResult, nil
+}
+
+// findProjectsForPerses returns reconcile requests for all PersesProjects
+// across all namespaces when a Perses instance becomes available.
+func (r *PersesProjectReconciler) findProjectsForPerses(ctx context.Context, _ client.Object) []reconcile.Request {
+	return common.MetadataListToRequests(ctx, r.Client, persesv1alpha2.GroupVersion.WithKind("PersesProjectList"))
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// It watches PersesProject resources and also watches Perses instances
+// to trigger re-reconciliation of all projects when a Perses instance becomes available.
+func (r *PersesProjectReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&persesv1alpha2.PersesProject{}, builder.OnlyMetadata).
+		Watches(
+			&persesv1alpha2.Perses{},
+			handler.EnqueueRequestsFromMapFunc(r.findProjectsForPerses),
+			builder.WithPredicates(common.PersesAvailabilityPredicate()),
+		).
+		Complete(r)
+}
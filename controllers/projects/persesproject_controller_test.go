@@ -0,0 +1,321 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package projects
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	persesv1 "github.com/perses/perses/pkg/model/api/v1"
+	persesv1Common "github.com/perses/perses/pkg/model/api/v1/common"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	internal "github.com/perses/perses-operator/internal/perses"
+	"github.com/perses/perses-operator/internal/perses/common"
+)
+
+func TestProjectController(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Project Controller Suite")
+}
+
+const (
+	projectName      = "team-project"
+	projectNamespace = "team-a"
+)
+
+func newTestProjectReconciler(persesClient *internal.MockClient, objects ...runtime.Object) *PersesProjectReconciler {
+	scheme := runtime.NewScheme()
+	Expect(persesv1alpha2.AddToScheme(scheme)).To(Succeed())
+
+	clientBuilder := fake.NewClientBuilder().WithScheme(scheme)
+	for _, obj := range objects {
+		clientBuilder = clientBuilder.WithRuntimeObjects(obj)
+	}
+	clientBuilder = clientBuilder.WithStatusSubresource(&persesv1alpha2.PersesProject{})
+
+	c := clientBuilder.Build()
+	return &PersesProjectReconciler{
+		Client:        c,
+		APIReader:     c,
+		Scheme:        scheme,
+		ClientFactory: common.NewWithClient(persesClient),
+		Recorder:      record.NewFakeRecorder(10),
+	}
+}
+
+func recordedEvents(r *PersesProjectReconciler) chan string {
+	return r.Recorder.(*record.FakeRecorder).Events
+}
+
+func availablePerses() *persesv1alpha2.Perses {
+	return availablePersesNamed("perses")
+}
+
+func availablePersesNamed(name string) *persesv1alpha2.Perses {
+	return &persesv1alpha2.Perses{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "monitoring",
+		},
+		Status: persesv1alpha2.PersesStatus{
+			Conditions: []metav1.Condition{{
+				Type:               common.TypeAvailablePerses,
+				Status:             metav1.ConditionTrue,
+				Reason:             "Reconciled",
+				LastTransitionTime: metav1.Now(),
+			}},
+		},
+	}
+}
+
+func newProject(name string, policy persesv1alpha2.ProjectDeletionPolicy) *persesv1alpha2.PersesProject {
+	return &persesv1alpha2.PersesProject{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: projectNamespace,
+		},
+		Spec: persesv1alpha2.PersesProjectSpec{
+			DisplayName:    "Team A",
+			Description:    "Dashboards owned by team A",
+			DeletionPolicy: policy,
+		},
+	}
+}
+
+func managedProject() *persesv1.Project {
+	project := expectedProject()
+	project.Metadata.Tags = common.WithManagedTag(nil)
+	return project
+}
+
+func expectedProject() *persesv1.Project {
+	return &persesv1.Project{
+		Kind:     persesv1.KindProject,
+		Metadata: persesv1.Metadata{Name: projectNamespace},
+		Spec: persesv1.ProjectSpec{
+			Display: &persesv1Common.Display{
+				Name:        "Team A",
+				Description: "Dashboards owned by team A",
+			},
+		},
+	}
+}
+
+var _ = Describe("Project controller", func() {
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: projectName, Namespace: projectNamespace}}
+
+	It("should create the project with its display name and add the finalizer", func() {
+		mockProject := &internal.MockProject{}
+		mockProject.On("Get", projectNamespace).Return(&persesv1.Project{}, perseshttp.RequestNotFoundError)
+		mockProject.On("Create", managedProject()).Return(managedProject(), nil)
+
+		r := newTestProjectReconciler(&internal.MockClient{Projects: mockProject},
+			availablePerses(), newProject(projectName, persesv1alpha2.ProjectDeletionPolicyRetain))
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		mockProject.AssertExpectations(GinkgoT())
+
+		fresh := &persesv1alpha2.PersesProject{}
+		Expect(r.Get(ctx, req.NamespacedName, fresh)).To(Succeed())
+		Expect(controllerutil.ContainsFinalizer(fresh, common.PersesFinalizer)).To(BeTrue())
+		Expect(apimeta.IsStatusConditionTrue(fresh.Status.Conditions, common.TypeAvailablePerses)).To(BeTrue())
+		Expect(recordedEvents(r)).To(Receive(ContainSubstring("Project created")))
+	})
+
+	It("should replace a project created in Perses by hand", func() {
+		existing := expectedProject()
+		existing.Spec.Display.Name = projectNamespace
+
+		mockProject := &internal.MockProject{}
+		mockProject.On("Get", projectNamespace).Return(existing, nil)
+		mockProject.On("Update", expectedProject()).Return(expectedProject(), nil)
+
+		r := newTestProjectReconciler(&internal.MockClient{Projects: mockProject},
+			availablePerses(), newProject(projectName, persesv1alpha2.ProjectDeletionPolicyRetain))
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		mockProject.AssertExpectations(GinkgoT())
+		Expect(recordedEvents(r)).To(Receive(And(
+			ContainSubstring("Project updated"),
+			ContainSubstring("Team A"),
+		)))
+	})
+
+	It("should keep the tag of a project created by the operator when updating it", func() {
+		existing := managedProject()
+		existing.Spec.Display.Name = projectNamespace
+
+		mockProject := &internal.MockProject{}
+		mockProject.On("Get", projectNamespace).Return(existing, nil)
+		mockProject.On("Update", managedProject()).Return(managedProject(), nil)
+
+		r := newTestProjectReconciler(&internal.MockClient{Projects: mockProject},
+			availablePerses(), newProject(projectName, persesv1alpha2.ProjectDeletionPolicyRetain))
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		mockProject.AssertExpectations(GinkgoT())
+	})
+
+	It("should degrade a second PersesProject in the same namespace", func() {
+		older := newProject("older", persesv1alpha2.ProjectDeletionPolicyRetain)
+		older.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+
+		newer := newProject(projectName, persesv1alpha2.ProjectDeletionPolicyRetain)
+		newer.CreationTimestamp = metav1.Now()
+
+		mockProject := &internal.MockProject{}
+		r := newTestProjectReconciler(&internal.MockClient{Projects: mockProject}, availablePerses(), older, newer)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).To(HaveOccurred())
+		Expect(common.ExtractReason(err, "")).To(Equal(common.ReasonInvalidConfiguration))
+		mockProject.AssertNotCalled(GinkgoT(), "Create")
+
+		fresh := &persesv1alpha2.PersesProject{}
+		Expect(r.Get(ctx, req.NamespacedName, fresh)).To(Succeed())
+		degraded := apimeta.FindStatusCondition(fresh.Status.Conditions, common.TypeDegradedPerses)
+		Expect(degraded).ToNot(BeNil())
+		Expect(degraded.Reason).To(Equal(string(common.ReasonInvalidConfiguration)))
+	})
+
+	DescribeTable("deletion policy",
+		func(policy persesv1alpha2.ProjectDeletionPolicy, expectDelete bool) {
+			project := newProject(projectName, policy)
+			project.Finalizers = []string{common.PersesFinalizer}
+			project.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+
+			mockProject := &internal.MockProject{}
+			mockProject.On("Get", projectNamespace).Return(managedProject(), nil)
+			mockProject.On("Delete", projectNamespace).Return(nil)
+
+			r := newTestProjectReconciler(&internal.MockClient{Projects: mockProject}, availablePerses(), project)
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			if expectDelete {
+				mockProject.AssertCalled(GinkgoT(), "Delete", projectNamespace)
+			} else {
+				mockProject.AssertNotCalled(GinkgoT(), "Delete", projectNamespace)
+			}
+
+			err = r.Get(ctx, req.NamespacedName, &persesv1alpha2.PersesProject{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		},
+		Entry("Delete removes the project from Perses", persesv1alpha2.ProjectDeletionPolicyDelete, true),
+		Entry("Retain keeps the project in Perses", persesv1alpha2.ProjectDeletionPolicyRetain, false),
+	)
+
+	Context("deleteProjectInAllInstances", func() {
+		It("should delete the project from every selected instance", func() {
+			mockProject := &internal.MockProject{}
+			mockProject.On("Get", projectNamespace).Return(managedProject(), nil)
+			mockProject.On("Delete", projectNamespace).Return(nil)
+
+			r := newTestProjectReconciler(&internal.MockClient{Projects: mockProject},
+				availablePersesNamed("perses-a"), availablePersesNamed("perses-b"))
+
+			_, err := r.deleteProjectInAllInstances(ctx, newProject(projectName, persesv1alpha2.ProjectDeletionPolicyDelete))
+			Expect(err).ToNot(HaveOccurred())
+			mockProject.AssertNumberOfCalls(GinkgoT(), "Delete", 2)
+			Expect(recordedEvents(r)).To(Receive(ContainSubstring("monitoring/perses-a: Project deleted")))
+			Expect(recordedEvents(r)).To(Receive(ContainSubstring("monitoring/perses-b: Project deleted")))
+		})
+
+		It("should ignore a project already deleted from Perses", func() {
+			mockProject := &internal.MockProject{}
+			mockProject.On("Get", projectNamespace).Return(managedProject(), nil)
+			mockProject.On("Delete", projectNamespace).Return(perseshttp.RequestNotFoundError)
+
+			r := newTestProjectReconciler(&internal.MockClient{Projects: mockProject}, availablePerses())
+
+			_, err := r.deleteProjectInAllInstances(ctx, newProject(projectName, persesv1alpha2.ProjectDeletionPolicyDelete))
+			Expect(err).ToNot(HaveOccurred())
+			mockProject.AssertCalled(GinkgoT(), "Delete", projectNamespace)
+			Expect(recordedEvents(r)).ToNot(Receive())
+		})
+
+		It("should keep a project still managed by another PersesProject", func() {
+			mockProject := &internal.MockProject{}
+
+			r := newTestProjectReconciler(&internal.MockClient{Projects: mockProject},
+				availablePerses(), newProject("other", persesv1alpha2.ProjectDeletionPolicyRetain))
+
+			_, err := r.deleteProjectInAllInstances(ctx, newProject(projectName, persesv1alpha2.ProjectDeletionPolicyDelete))
+			Expect(err).ToNot(HaveOccurred())
+			mockProject.AssertNotCalled(GinkgoT(), "Delete", projectNamespace)
+		})
+
+		It("should keep a project of the same name created in Perses by hand", func() {
+			mockProject := &internal.MockProject{}
+			mockProject.On("Get", projectNamespace).Return(expectedProject(), nil)
+
+			r := newTestProjectReconciler(&internal.MockClient{Projects: mockProject}, availablePerses())
+
+			_, err := r.deleteProjectInAllInstances(ctx, newProject(projectName, persesv1alpha2.ProjectDeletionPolicyDelete))
+			Expect(err).ToNot(HaveOccurred())
+			mockProject.AssertNotCalled(GinkgoT(), "Delete", projectNamespace)
+			Expect(recordedEvents(r)).ToNot(Receive())
+		})
+
+		It("should wait for the Perses instances that are not available", func() {
+			mockProject := &internal.MockProject{}
+			mockProject.On("Get", projectNamespace).Return(managedProject(), nil)
+			mockProject.On("Delete", projectNamespace).Return(nil)
+
+			unavailable := availablePersesNamed("perses-b")
+			unavailable.Status.Conditions = nil
+			r := newTestProjectReconciler(&internal.MockClient{Projects: mockProject}, availablePersesNamed("perses-a"), unavailable)
+
+			res, err := r.deleteProjectInAllInstances(ctx, newProject(projectName, persesv1alpha2.ProjectDeletionPolicyDelete))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).ToNot(BeNil())
+			Expect(res.RequeueAfter).To(Equal(time.Minute))
+			mockProject.AssertNumberOfCalls(GinkgoT(), "Delete", 1)
+			Expect(recordedEvents(r)).To(Receive(ContainSubstring("monitoring/perses-a: Project deleted")))
+			Expect(recordedEvents(r)).To(Receive(ContainSubstring("monitoring/perses-b: Project deletion is waiting for the instance to be available")))
+		})
+
+		It("should only plan the deletion in a dry run", func() {
+			mockProject := &internal.MockProject{}
+			mockProject.On("Get", projectNamespace).Return(managedProject(), nil)
+
+			r := newTestProjectReconciler(&internal.MockClient{Projects: mockProject}, availablePerses())
+			r.DryRun = true
+
+			_, err := r.deleteProjectInAllInstances(ctx, newProject(projectName, persesv1alpha2.ProjectDeletionPolicyDelete))
+			Expect(err).ToNot(HaveOccurred())
+			mockProject.AssertNotCalled(GinkgoT(), "Delete", projectNamespace)
+			Expect(recordedEvents(r)).To(Receive(ContainSubstring("Dry run: project would be deleted")))
+		})
+	})
+})
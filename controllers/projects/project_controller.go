@@ -0,0 +1,267 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package projects
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/perses/perses/pkg/client/perseshttp"
+
+	logger "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	"github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses-operator/internal/subreconciler"
+)
+
+var plog = logger.WithField("module", "project_controller")
+
+func (r *PersesProjectReconciler) reconcileProjectInAllInstances(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	project, ok := projectFromContext(ctx)
+	if !ok {
+		plog.Error("project not found in context")
+		res, err := subreconciler.RequeueWithError(fmt.Errorf("project not found in context"))
+		return r.setStatusToDegraded(ctx, req, res, common.ReasonMissingResource, err)
+	}
+
+	// A Perses project is named after the namespace, so only one PersesProject per namespace can own it.
+	owner, err := common.ProjectForNamespace(ctx, r.APIReader, project.Namespace)
+	if err != nil {
+		plog.WithError(err).Error("Failed to list perses projects")
+		return subreconciler.RequeueWithError(err)
+	}
+	if owner != nil && owner.Name != project.Name {
+		return r.setStatusToDegraded(ctx, req, &ctrl.Result{}, common.ReasonInvalidConfiguration,
+			fmt.Errorf("project %q is already managed by PersesProject %s/%s", project.Namespace, owner.Namespace, owner.Name))
+	}
+
+	persesInstances, err := r.listSelectedInstances(ctx, project)
+	if err != nil {
+		plog.WithError(err).Error("Failed to get perses instances")
+		res, err := subreconciler.RequeueWithError(err)
+		return r.setStatusToDegraded(ctx, req, res, common.ReasonMissingPerses, err)
+	}
+
+	if len(persesInstances.Items) == 0 {
+		plog.Info("No Perses instances found, retrying in 1 minute")
+		return r.setStatusToDegraded(ctx, req, &ctrl.Result{RequeueAfter: time.Minute}, common.ReasonMissingPerses, fmt.Errorf("no Perses instances found matching the label selector"))
+	}
+
+	var planned []string
+	for _, persesInstance := range persesInstances.Items {
+		if !meta.IsStatusConditionTrue(persesInstance.Status.Conditions, common.TypeAvailablePerses) {
+			plog.Infof("Skipping Perses instance %s/%s (not yet available)", persesInstance.Namespace, persesInstance.Name)
+			continue
+		}
+		res, reason, err := r.syncPersesProject(ctx, persesInstance, project)
+		if subreconciler.ShouldHaltOrRequeue(res, err) {
+			common.RecordInstanceEvent(r.Recorder, project, persesInstance, corev1.EventTypeWarning, string(reason), "Failed to sync project: %v", err)
+			return r.setStatusToDegraded(ctx, req, res, reason, err)
+		}
+		if reason == common.ReasonDryRun {
+			planned = append(planned, fmt.Sprintf("%s/%s", persesInstance.Namespace, persesInstance.Name))
+		}
+	}
+
+	if common.IsDryRun(project, r.DryRun) {
+		return r.updateProjectStatus(ctx, req, func(project *persesv1alpha2.PersesProject) {
+			meta.SetStatusCondition(&project.Status.Conditions, common.DryRunCondition("Project", project.Namespace, planned))
+		})
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesProjectReconciler) listSelectedInstances(ctx context.Context, project *persesv1alpha2.PersesProject) (*persesv1alpha2.PersesList, error) {
+	var labelSelector labels.Selector
+	if project.Spec.InstanceSelector == nil {
+		labelSelector = labels.Everything()
+	} else {
+		var err error
+		labelSelector, err = metav1.LabelSelectorAsSelector(project.Spec.InstanceSelector)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	persesInstances := &persesv1alpha2.PersesList{}
+	opts := &client.ListOptions{
+		LabelSelector: labelSelector,
+	}
+	if err := r.List(ctx, persesInstances, opts); err != nil {
+		return nil, err
+	}
+	return persesInstances, nil
+}
+
+func (r *PersesProjectReconciler) syncPersesProject(ctx context.Context, perses persesv1alpha2.Perses, project *persesv1alpha2.PersesProject) (*ctrl.Result, common.ConditionStatusReason, error) {
+	persesClient, err := r.ClientFactory.CreateClient(ctx, r.APIReader, perses)
+	if err != nil {
+		plog.WithError(err).Error("Failed to create perses rest client")
+		return subreconciler.RequeueWithErrorAndReason(err, common.ReasonConnectionFailed)
+	}
+
+	desired := common.DesiredProject(project.Namespace, project)
+
+	existing, err := persesClient.Project().Get(project.Namespace)
+	notFound := err != nil && errors.Is(err, perseshttp.RequestNotFoundError)
+
+	if err != nil && !notFound {
+		return subreconciler.RequeueWithErrorAndReason(err, common.ReasonBackendError)
+	}
+
+	// A project created by the operator keeps its tag, so that it can be deleted later on.
+	// A project created by hand is updated without being claimed, it is never deleted.
+	if notFound || existing == nil || common.IsManaged(existing.Metadata.Tags) {
+		desired.Metadata.Tags = common.WithManagedTag(nil)
+	}
+
+	if !notFound && existing != nil && common.ProjectInSync(existing, desired) {
+		plog.Debugf("Project already in sync: %s", project.Namespace)
+		res, err := subreconciler.ContinueReconciling()
+		return res, "", err
+	}
+
+	dryRun := common.IsDryRun(project, r.DryRun)
+	if dryRun {
+		plan := "created"
+		if !notFound && existing != nil {
+			plan = fmt.Sprintf("updated: %s", common.FormatDiff(common.Diff("spec", existing.Spec, desired.Spec), common.MaxEventDiffLength))
+		}
+		plog.Infof("Dry run, project %s would be %s in Perses instance %s/%s", project.Namespace, plan, perses.Namespace, perses.Name)
+		common.RecordInstanceEvent(r.Recorder, project, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: project would be %s", plan)
+		res, err := subreconciler.ContinueReconciling()
+		return res, common.ReasonDryRun, err
+	}
+
+	if notFound {
+		_, err = persesClient.Project().Create(desired)
+		if err != nil {
+			plog.WithError(err).Errorf("Failed to create project: %s", project.Namespace)
+			return subreconciler.RequeueWithErrorAndReason(err, common.ReasonBackendError)
+		}
+		plog.Infof("Project created: %s", project.Namespace)
+		common.RecordInstanceEvent(r.Recorder, project, perses, corev1.EventTypeNormal, common.EventReasonCreated, "Project created")
+	} else {
+		_, err = persesClient.Project().Update(desired)
+		if err != nil {
+			plog.WithError(err).Errorf("Failed to update project: %s", project.Namespace)
+			return subreconciler.RequeueWithErrorAndReason(err, common.ReasonBackendError)
+		}
+		plog.Infof("Project updated: %s", project.Namespace)
+		common.RecordInstanceEvent(r.Recorder, project, perses, corev1.EventTypeNormal, common.EventReasonUpdated, "Project updated: %s", common.FormatDiff(common.Diff("spec", existing.Spec, desired.Spec), common.MaxEventDiffLength))
+	}
+
+	res, err := subreconciler.ContinueReconciling()
+	return res, "", err
+}
+
+// deleteProjectInAllInstances removes the project from every Perses instance selected
+// by the PersesProject. Only the PersesProject in charge of the namespace may delete it,
+// and only from the instances where the operator created it. The deletion waits for the
+// instances that are not available.
+func (r *PersesProjectReconciler) deleteProjectInAllInstances(ctx context.Context, project *persesv1alpha2.PersesProject) (*ctrl.Result, error) {
+	owner, err := common.ProjectForNamespace(ctx, r.APIReader, project.Namespace)
+	if err != nil {
+		plog.WithError(err).Error("Failed to list perses projects")
+		return subreconciler.RequeueWithError(err)
+	}
+	if owner != nil {
+		plog.Infof("Project %s is still managed by PersesProject %s/%s, skipping deletion", project.Namespace, owner.Namespace, owner.Name)
+		return subreconciler.ContinueReconciling()
+	}
+
+	persesInstances, err := r.listSelectedInstances(ctx, project)
+	if err != nil {
+		plog.WithError(err).Error("Failed to get perses instances")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	var unavailable bool
+	for _, persesInstance := range persesInstances.Items {
+		if !meta.IsStatusConditionTrue(persesInstance.Status.Conditions, common.TypeAvailablePerses) {
+			plog.Infof("Perses instance %s/%s is not available, project deletion is blocked", persesInstance.Namespace, persesInstance.Name)
+			common.RecordInstanceEvent(r.Recorder, project, persesInstance, corev1.EventTypeWarning, string(common.ReasonDeletionBlocked), "Project deletion is waiting for the instance to be available")
+			unavailable = true
+			continue
+		}
+		if r, err := r.deleteProject(ctx, persesInstance, project); subreconciler.ShouldHaltOrRequeue(r, err) {
+			return r, err
+		}
+	}
+
+	if unavailable {
+		return subreconciler.RequeueWithDelay(time.Minute)
+	}
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesProjectReconciler) deleteProject(ctx context.Context, perses persesv1alpha2.Perses, project *persesv1alpha2.PersesProject) (*ctrl.Result, error) {
+	projectName := project.Namespace
+	persesClient, err := r.ClientFactory.CreateClient(ctx, r.APIReader, perses)
+	if err != nil {
+		plog.WithError(err).Error("Failed to create perses rest client")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	existing, err := persesClient.Project().Get(projectName)
+	if err != nil {
+		if errors.Is(err, perseshttp.RequestNotFoundError) {
+			plog.Infof("Project not found: %s", projectName)
+			return subreconciler.ContinueReconciling()
+		}
+		plog.WithError(err).Errorf("Failed to get project: %s", projectName)
+		common.RecordInstanceEvent(r.Recorder, project, perses, corev1.EventTypeWarning, string(common.ReasonBackendError), "Failed to delete project: %v", err)
+		return subreconciler.RequeueWithError(err)
+	}
+
+	// A project the operator did not create is kept, with everything it holds.
+	if existing == nil || !common.IsManaged(existing.Metadata.Tags) {
+		plog.Infof("Project not managed by the operator, keeping it: %s", projectName)
+		return subreconciler.ContinueReconciling()
+	}
+
+	if common.IsDryRun(project, r.DryRun) {
+		plog.Infof("Dry run, project %s would be deleted from Perses instance %s/%s", projectName, perses.Namespace, perses.Name)
+		common.RecordInstanceEvent(r.Recorder, project, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: project would be deleted")
+		return subreconciler.ContinueReconciling()
+	}
+
+	err = persesClient.Project().Delete(projectName)
+	// Ignore NotFound — the project may have already been deleted from Perses directly.
+	// Any other error means the delete failed and should be retried.
+	if err != nil {
+		if errors.Is(err, perseshttp.RequestNotFoundError) {
+			plog.Infof("Project not found: %s", projectName)
+			return subreconciler.ContinueReconciling()
+		}
+		plog.WithError(err).Errorf("Failed to delete project: %s", projectName)
+		common.RecordInstanceEvent(r.Recorder, project, perses, corev1.EventTypeWarning, string(common.ReasonBackendError), "Failed to delete project: %v", err)
+		return subreconciler.RequeueWithError(err)
+	}
+
+	plog.Infof("Project deleted: %s", projectName)
+	common.RecordInstanceEvent(r.Recorder, project, perses, corev1.EventTypeNormal, common.EventReasonDeleted, "Project deleted")
+
+	return subreconciler.ContinueReconciling()
+}
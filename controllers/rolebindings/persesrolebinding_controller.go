@@ -0,0 +1,354 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rolebindings
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	logger "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	operatormetrics "github.com/perses/perses-operator/internal/metrics"
+	"github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses-operator/internal/subreconciler"
+)
+
+type roleBindingContextKey string
+
+const contextKey roleBindingContextKey = "rolebinding"
+
+func withRoleBinding(ctx context.Context, v *persesv1alpha2.PersesRoleBinding) context.Context {
+	return context.WithValue(ctx, contextKey, v)
+}
+
+func roleBindingFromContext(ctx context.Context) (*persesv1alpha2.PersesRoleBinding, bool) {
+	v, ok := ctx.Value(contextKey).(*persesv1alpha2.PersesRoleBinding)
+	return v, ok
+}
+
+// PersesRoleBindingReconciler reconciles a PersesRoleBinding object
+type PersesRoleBindingReconciler struct {
+	client.Client
+	APIReader             client.Reader // uncached reader — OnlyMetadata watch caches metadata only
+	Scheme                *runtime.Scheme
+	Recorder              record.EventRecorder
+	ClientFactory         common.PersesClientFactory
+	Metrics               *operatormetrics.Metrics
+	ReconciliationTracker *operatormetrics.ReconciliationTracker
+	// InstanceSyncConcurrency is the maximum number of Perses instances the role binding
+	// is synced to at the same time. Lower than 1 syncs one instance at a time.
+	InstanceSyncConcurrency int
+	// DryRun only plans the changes to the role bindings in Perses, without applying them,
+	// as for the role bindings with the perses.dev/dry-run annotation.
+	DryRun bool
+}
+
+var log = logger.WithField("module", "perses_rolebindings_controller")
+
+// +kubebuilder:rbac:groups=perses.dev,resources=persesrolebindings,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=perses.dev,resources=persesrolebindings/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=perses.dev,resources=persesrolebindings/finalizers,verbs=update
+func (r *PersesRoleBindingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	start := time.Now()
+	objKey := req.String()
+
+	if r.Metrics != nil {
+		r.Metrics.ReconcileOperations("persesrolebinding").Inc()
+	}
+
+	log.Infof("Reconciling PersesRoleBinding: %s/%s", req.Namespace, req.Name)
+
+	// Find once and store in context for all sub-reconcilers.
+	// Deletion is handled through the finalizer, so a missing object has nothing left to clean up.
+	roleBinding := &persesv1alpha2.PersesRoleBinding{}
+	if err := r.APIReader.Get(ctx, req.NamespacedName, roleBinding); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Infof("perses role binding resource not found. Ignoring since object must be deleted")
+			if r.ReconciliationTracker != nil {
+				r.ReconciliationTracker.ForgetObject(objKey)
+			}
+			if r.Metrics != nil {
+				r.Metrics.ForgetObject(objKey)
+			}
+			return subreconciler.Evaluate(subreconciler.DoNotRequeue())
+		}
+		log.WithError(err).Error("Failed to get perses role binding")
+		if r.Metrics != nil {
+			r.Metrics.ReconcileErrors("persesrolebinding", "get_failed").Inc()
+		}
+		return subreconciler.Evaluate(subreconciler.RequeueWithError(err))
+	}
+
+	ctx = withRoleBinding(ctx, roleBinding)
+
+	subreconcilersForPerses := []subreconciler.FnWithRequest{
+		r.handleDelete,
+		r.addFinalizer,
+		r.setStatusToUnknown,
+		r.reconcileRoleBindingInAllInstances,
+		r.setStatusToComplete,
+	}
+
+	var reconcileErr error
+	for _, f := range subreconcilersForPerses {
+		if r, err := f(ctx, req); subreconciler.ShouldHaltOrRequeue(r, err) {
+			reconcileErr = err
+			break
+		}
+	}
+
+	// Track reconciliation status
+	if r.ReconciliationTracker != nil {
+		r.ReconciliationTracker.SetStatus(objKey, reconcileErr)
+		if reconcileErr == nil {
+			r.ReconciliationTracker.SetReasonAndMessage(objKey, "ReconciliationSuccessful", "RoleBinding reconciled successfully")
+		}
+	}
+
+	// Track metrics
+	if r.Metrics != nil {
+		if reconcileErr != nil {
+			reason := string(common.ExtractReason(reconcileErr, "reconciliation_failed"))
+			r.Metrics.ReconcileErrors("persesrolebinding", reason).Inc()
+			r.Metrics.SetFailedResources(objKey, "rolebinding", req.Namespace, 1)
+		} else {
+			r.Metrics.SetSyncedResources(objKey, "rolebinding", req.Namespace, 1)
+		}
+	}
+
+	if reconcileErr != nil {
+		return subreconciler.Evaluate(subreconciler.RequeueWithError(reconcileErr))
+	}
+
+	log.WithField("duration", time.Since(start)).Debug("role binding reconciliation completed")
+	return subreconciler.Evaluate(subreconciler.DoNotRequeue())
+}
+
+// handleDelete removes the role binding from the selected Perses instances, then releases the finalizer.
+func (r *PersesRoleBindingReconciler) handleDelete(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	roleBinding, ok := roleBindingFromContext(ctx)
+	if !ok {
+		log.Error("role binding not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("role binding not found in context"))
+	}
+
+	if roleBinding.GetDeletionTimestamp() == nil {
+		return subreconciler.ContinueReconciling()
+	}
+
+	if !controllerutil.ContainsFinalizer(roleBinding, common.PersesFinalizer) {
+		return subreconciler.DoNotRequeue()
+	}
+
+	if res, err := r.deleteRoleBindingInAllInstances(ctx, req, roleBinding); subreconciler.ShouldHaltOrRequeue(res, err) {
+		return res, err
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesRoleBinding{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		if !controllerutil.RemoveFinalizer(fresh, common.PersesFinalizer) {
+			return nil
+		}
+		return r.Update(ctx, fresh)
+	})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to remove finalizer from perses role binding")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	log.Infof("PersesRoleBinding %s/%s deleted", roleBinding.Namespace, roleBinding.Name)
+	return subreconciler.DoNotRequeue()
+}
+
+// addFinalizer makes sure the role binding is not removed from the cluster before
+// it has been removed from the Perses instances it was synced to.
+func (r *PersesRoleBindingReconciler) addFinalizer(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	roleBinding, ok := roleBindingFromContext(ctx)
+	if !ok {
+		log.Error("role binding not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("role binding not found in context"))
+	}
+
+	if controllerutil.ContainsFinalizer(roleBinding, common.PersesFinalizer) {
+		return subreconciler.ContinueReconciling()
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesRoleBinding{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		if !controllerutil.AddFinalizer(fresh, common.PersesFinalizer) {
+			return nil
+		}
+		return r.Update(ctx, fresh)
+	})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to add finalizer to perses role binding")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesRoleBindingReconciler) updateRoleBindingStatus(
+	ctx context.Context,
+	req ctrl.Request,
+	updateFn func(*persesv1alpha2.PersesRoleBinding),
+) (*ctrl.Result, error) {
+	_, ok := roleBindingFromContext(ctx)
+	if !ok {
+		log.Error("role binding not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("role binding not found in context"))
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesRoleBinding{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		before := common.SnapshotConditions(fresh.Status.Conditions)
+		updateFn(fresh)
+		if !common.ConditionsChanged(before, fresh.Status.Conditions) {
+			return nil
+		}
+		return r.Status().Update(ctx, fresh)
+	})
+
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("perses role binding resource not found. Ignoring since object must be deleted")
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to update Perses role binding status")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesRoleBindingReconciler) setStatusToUnknown(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	return r.updateRoleBindingStatus(ctx, req, func(roleBinding *persesv1alpha2.PersesRoleBinding) {
+		if len(roleBinding.Status.Conditions) == 0 {
+			meta.SetStatusCondition(&roleBinding.Status.Conditions, metav1.Condition{
+				Type: common.TypeAvailablePerses, Status: metav1.ConditionUnknown,
+				Reason: "Reconciling", Message: "Starting reconciliation"})
+		}
+	})
+}
+
+func (r *PersesRoleBindingReconciler) setStatusToComplete(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	// The observed state is the one that was synced, the custom resource may have changed since.
+	synced, ok := roleBindingFromContext(ctx)
+	if !ok {
+		log.Error("role binding not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("role binding not found in context"))
+	}
+
+	// Nothing was applied in a dry run, so the availability of the role binding is left as is.
+	if common.IsDryRun(synced, r.DryRun) {
+		return subreconciler.ContinueReconciling()
+	}
+
+	return r.updateRoleBindingStatus(ctx, req, func(roleBinding *persesv1alpha2.PersesRoleBinding) {
+		meta.RemoveStatusCondition(&roleBinding.Status.Conditions, common.TypeDryRunPerses)
+		meta.SetStatusCondition(&roleBinding.Status.Conditions, metav1.Condition{
+			Type: common.TypeDegradedPerses, Status: metav1.ConditionFalse,
+			Reason: "Reconciled", Message: fmt.Sprintf("RoleBinding (%s) reconciled successfully", roleBinding.Name)})
+		meta.SetStatusCondition(&roleBinding.Status.Conditions, metav1.Condition{
+			Type: common.TypeAvailablePerses, Status: metav1.ConditionTrue,
+			Reason: "Reconciled", Message: fmt.Sprintf("RoleBinding (%s) created successfully", roleBinding.Name)})
+	})
+}
+
+func (r *PersesRoleBindingReconciler) setStatusToDegraded(
+	ctx context.Context,
+	req ctrl.Request,
+	degradedResult *ctrl.Result,
+	degradedReason common.ConditionStatusReason,
+	degradedError error,
+) (*ctrl.Result, error) {
+	msg := "unknown error"
+	if degradedError != nil {
+		msg = degradedError.Error()
+	}
+
+	result, err := r.updateRoleBindingStatus(ctx, req, func(roleBinding *persesv1alpha2.PersesRoleBinding) {
+		meta.SetStatusCondition(&roleBinding.Status.Conditions, metav1.Condition{
+			Type: common.TypeAvailablePerses, Status: metav1.ConditionFalse,
+			Reason: string(degradedReason), Message: msg})
+		meta.SetStatusCondition(&roleBinding.Status.Conditions, metav1.Condition{
+			Type: common.TypeDegradedPerses, Status: metav1.ConditionTrue,
+			Reason: string(degradedReason), Message: msg})
+	})
+
+	if err != nil {
+		return result, err
+	}
+
+	if degradedError != nil {
+		return degradedResult, common.NewReasonError(degradedError, degradedReason)
+	}
+	return degradedResult, nil
+}
+
+// findRoleBindingsForPerses returns reconcile requests for all PersesRoleBindings
+// across all namespaces when a Perses instance becomes available.
+// Each role binding's instanceSelector labels determine which Perses instances it syncs to.
+// If no instanceSelector is set, the role binding syncs to all Perses instances.
+func (r *PersesRoleBindingReconciler) findRoleBindingsForPerses(ctx context.Context, _ client.Object) []reconcile.Request {
+	return common.MetadataListToRequests(ctx, r.Client, persesv1alpha2.GroupVersion.WithKind("PersesRoleBindingList"))
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// It watches PersesRoleBinding resources and also watches Perses instances
+// to trigger re-reconciliation of all role bindings when a Perses instance becomes
+// available. Role bindings are matched to Perses instances via instanceSelector labels.
+// Create and delete events for Perses instances are ignored because
+// the instance is not yet ready at creation, and deletion is handled by the role binding's
+// own reconciliation loop.
+func (r *PersesRoleBindingReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&persesv1alpha2.PersesRoleBinding{}, builder.OnlyMetadata).
+		Watches(
+			&persesv1alpha2.Perses{},
+			handler.EnqueueRequestsFromMapFunc(r.findRoleBindingsForPerses),
+			builder.WithPredicates(common.PersesAvailabilityPredicate()),
+		).
+		Complete(r)
+}
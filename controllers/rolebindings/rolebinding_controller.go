@@ -0,0 +1,318 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rolebindings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/perses/common/set"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	persesv1 "github.com/perses/perses/pkg/model/api/v1"
+
+	logger "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	"github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses-operator/internal/subreconciler"
+)
+
+var rblog = logger.WithField("module", "rolebinding_controller")
+
+func (r *PersesRoleBindingReconciler) reconcileRoleBindingInAllInstances(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	roleBinding, ok := roleBindingFromContext(ctx)
+	if !ok {
+		rblog.Error("role binding not found in context")
+		res, err := subreconciler.RequeueWithError(fmt.Errorf("role binding not found in context"))
+		return r.setStatusToDegraded(ctx, req, res, common.ReasonMissingResource, err)
+	}
+
+	persesInstances, err := r.listSelectedInstances(ctx, roleBinding)
+	if err != nil {
+		rblog.WithError(err).Error("Failed to get perses instances")
+		res, err := subreconciler.RequeueWithError(err)
+		return r.setStatusToDegraded(ctx, req, res, common.ReasonMissingPerses, err)
+	}
+
+	if len(persesInstances.Items) == 0 {
+		rblog.Info("No Perses instances found, retrying in 1 minute")
+		return r.setStatusToDegraded(ctx, req, &ctrl.Result{RequeueAfter: time.Minute}, common.ReasonMissingPerses, fmt.Errorf("no Perses instances found matching the label selector"))
+	}
+
+	available := make([]persesv1alpha2.Perses, 0, len(persesInstances.Items))
+	for _, persesInstance := range persesInstances.Items {
+		if !meta.IsStatusConditionTrue(persesInstance.Status.Conditions, common.TypeAvailablePerses) {
+			rblog.Infof("Skipping Perses instance %s/%s (not yet available)", persesInstance.Namespace, persesInstance.Name)
+			continue
+		}
+		available = append(available, persesInstance)
+	}
+
+	// Every instance is synced, so that a failing instance does not hold back the others.
+	outcomes := subreconciler.ForEach(available, r.InstanceSyncConcurrency, func(persesInstance persesv1alpha2.Perses) (*ctrl.Result, common.ConditionStatusReason, error) {
+		return r.syncPersesRoleBinding(ctx, persesInstance, roleBinding)
+	})
+
+	var planned, failures []string
+	var firstFailure subreconciler.Outcome
+	for i, persesInstance := range available {
+		instanceName := fmt.Sprintf("%s/%s", persesInstance.Namespace, persesInstance.Name)
+		outcome := outcomes[i]
+		if outcome.Halted() {
+			if len(failures) == 0 {
+				firstFailure = outcome
+			}
+			failures = append(failures, fmt.Sprintf("%s: %v", instanceName, outcome.Err))
+			common.RecordInstanceEvent(r.Recorder, roleBinding, persesInstance, corev1.EventTypeWarning, string(outcome.Reason), "Failed to sync role binding: %v", outcome.Err)
+			continue
+		}
+		if outcome.Reason == common.ReasonDryRun {
+			planned = append(planned, instanceName)
+		}
+	}
+
+	if len(failures) > 0 {
+		rblog.Errorf("RoleBinding %s failed to sync to %d of %d Perses instances", roleBinding.Name, len(failures), len(available))
+		// A single failure is reported as is, so that its message stays actionable.
+		failure := firstFailure.Err
+		if len(failures) > 1 {
+			failure = fmt.Errorf("failed to sync to %d of %d Perses instances: %s", len(failures), len(available), strings.Join(failures, "; "))
+		}
+		return r.setStatusToDegraded(ctx, req, firstFailure.Result, firstFailure.Reason, failure)
+	}
+
+	if common.IsDryRun(roleBinding, r.DryRun) {
+		return r.updateRoleBindingStatus(ctx, req, func(roleBinding *persesv1alpha2.PersesRoleBinding) {
+			meta.SetStatusCondition(&roleBinding.Status.Conditions, common.DryRunCondition("RoleBinding", roleBinding.Name, planned))
+		})
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesRoleBindingReconciler) listSelectedInstances(ctx context.Context, roleBinding *persesv1alpha2.PersesRoleBinding) (*persesv1alpha2.PersesList, error) {
+	var labelSelector labels.Selector
+	if roleBinding.Spec.InstanceSelector == nil {
+		labelSelector = labels.Everything()
+	} else {
+		var err error
+		labelSelector, err = metav1.LabelSelectorAsSelector(roleBinding.Spec.InstanceSelector)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	persesInstances := &persesv1alpha2.PersesList{}
+	opts := &client.ListOptions{
+		LabelSelector: labelSelector,
+	}
+	if err := r.List(ctx, persesInstances, opts); err != nil {
+		return nil, err
+	}
+	return persesInstances, nil
+}
+
+func (r *PersesRoleBindingReconciler) syncPersesRoleBinding(ctx context.Context, perses persesv1alpha2.Perses, roleBinding *persesv1alpha2.PersesRoleBinding) (*ctrl.Result, common.ConditionStatusReason, error) {
+	persesClient, err := r.ClientFactory.CreateClient(ctx, r.APIReader, perses)
+	if err != nil {
+		rblog.WithError(err).Error("Failed to create perses rest client")
+		return subreconciler.RequeueWithErrorAndReason(err, common.ReasonConnectionFailed)
+	}
+
+	// A dry run does not create the project, the role binding is then planned for creation.
+	dryRun := common.IsDryRun(roleBinding, r.DryRun)
+	if !dryRun {
+		if reason, err := common.EnsureProject(ctx, r.APIReader, persesClient, perses, roleBinding.Namespace); err != nil {
+			return subreconciler.RequeueWithErrorAndReason(err, reason)
+		}
+	}
+
+	existing, err := persesClient.RoleBinding(roleBinding.Namespace).Get(roleBinding.Name)
+	notFound := err != nil && errors.Is(err, perseshttp.RequestNotFoundError)
+
+	if err != nil && !notFound {
+		return subreconciler.RequeueWithErrorAndReason(err, common.ReasonBackendError)
+	}
+
+	var existingTags, tags set.Set[string]
+	if !notFound {
+		existingTags = existing.Metadata.Tags
+	}
+	managed, err := common.ClaimOwnership(roleBinding.Spec.ConflictPolicy, "role binding", roleBinding.Name, !notFound, existingTags)
+	if err != nil {
+		rblog.WithError(err).Errorf("RoleBinding conflict: %s", roleBinding.Name)
+		return subreconciler.RequeueWithErrorAndReason(err, common.ReasonConflict)
+	}
+	if managed {
+		tags = common.WithManagedTag(nil)
+	}
+
+	persesRoleBinding := &persesv1.RoleBinding{
+		Kind: persesv1.KindRoleBinding,
+		Metadata: persesv1.ProjectMetadata{
+			Metadata: persesv1.Metadata{
+				Name: roleBinding.Name,
+				Tags: tags,
+			},
+		},
+		Spec: roleBinding.Spec.Config.RoleBindingSpec,
+	}
+
+	if !notFound && common.RoleBindingInSync(existing, persesRoleBinding) {
+		rblog.Debugf("RoleBinding already in sync: %s", roleBinding.Name)
+		res, err := subreconciler.ContinueReconciling()
+		return res, "", err
+	}
+
+	if dryRun {
+		plan := "created"
+		if !notFound {
+			plan = fmt.Sprintf("updated: %s", common.FormatDiff(common.RoleBindingDiff(existing, persesRoleBinding), common.MaxEventDiffLength))
+		}
+		rblog.Infof("Dry run, role binding %s would be %s in Perses instance %s/%s", roleBinding.Name, plan, perses.Namespace, perses.Name)
+		common.RecordInstanceEvent(r.Recorder, roleBinding, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: role binding would be %s", plan)
+		res, err := subreconciler.ContinueReconciling()
+		return res, common.ReasonDryRun, err
+	}
+
+	if notFound {
+		_, err = persesClient.RoleBinding(roleBinding.Namespace).Create(persesRoleBinding)
+		if err != nil {
+			rblog.WithError(err).Errorf("Failed to create role binding: %s", roleBinding.Name)
+			return subreconciler.RequeueWithErrorAndReason(err, common.ReasonBackendError)
+		}
+		rblog.Infof("RoleBinding created: %s", roleBinding.Name)
+		common.RecordInstanceEvent(r.Recorder, roleBinding, perses, corev1.EventTypeNormal, common.EventReasonCreated, "Role binding created")
+	} else {
+		_, err = persesClient.RoleBinding(roleBinding.Namespace).Update(persesRoleBinding)
+		if err != nil {
+			rblog.WithError(err).Errorf("Failed to update role binding: %s", roleBinding.Name)
+			return subreconciler.RequeueWithErrorAndReason(err, common.ReasonBackendError)
+		}
+		rblog.Infof("RoleBinding updated: %s", roleBinding.Name)
+		common.RecordInstanceEvent(r.Recorder, roleBinding, perses, corev1.EventTypeNormal, common.EventReasonUpdated, "Role binding updated: %s", common.FormatDiff(common.RoleBindingDiff(existing, persesRoleBinding), common.MaxEventDiffLength))
+	}
+
+	res, err := subreconciler.ContinueReconciling()
+	return res, "", err
+}
+
+// deleteRoleBindingInAllInstances removes the role binding from the Perses instances selected by the
+// PersesRoleBinding. The deletion is blocked while one of them cannot confirm the removal.
+func (r *PersesRoleBindingReconciler) deleteRoleBindingInAllInstances(ctx context.Context, req ctrl.Request, roleBinding *persesv1alpha2.PersesRoleBinding) (*ctrl.Result, error) {
+	persesInstances, err := r.listSelectedInstances(ctx, roleBinding)
+	if err != nil {
+		rblog.WithError(err).Error("Failed to get perses instances")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	var blocked []string
+	available := make([]persesv1alpha2.Perses, 0, len(persesInstances.Items))
+	for _, persesInstance := range persesInstances.Items {
+		if !meta.IsStatusConditionTrue(persesInstance.Status.Conditions, common.TypeAvailablePerses) {
+			rblog.Infof("Perses instance %s/%s is not available, role binding deletion is blocked", persesInstance.Namespace, persesInstance.Name)
+			blocked = append(blocked, fmt.Sprintf("%s/%s (not available)", persesInstance.Namespace, persesInstance.Name))
+			common.RecordInstanceEvent(r.Recorder, roleBinding, persesInstance, corev1.EventTypeWarning, string(common.ReasonDeletionBlocked), "Role binding deletion is waiting for the instance to be available")
+			continue
+		}
+		available = append(available, persesInstance)
+	}
+
+	outcomes := subreconciler.ForEach(available, r.InstanceSyncConcurrency, func(persesInstance persesv1alpha2.Perses) (*ctrl.Result, common.ConditionStatusReason, error) {
+		res, err := r.deleteRoleBinding(ctx, persesInstance, roleBinding)
+		return res, "", err
+	})
+	for i, persesInstance := range available {
+		if outcome := outcomes[i]; outcome.Halted() {
+			blocked = append(blocked, fmt.Sprintf("%s/%s (%v)", persesInstance.Namespace, persesInstance.Name, outcome.Err))
+			common.RecordInstanceEvent(r.Recorder, roleBinding, persesInstance, corev1.EventTypeWarning, string(common.ReasonDeletionBlocked), "Failed to delete role binding: %v", outcome.Err)
+		}
+	}
+
+	if len(blocked) > 0 {
+		return r.setStatusToDegraded(ctx, req, &ctrl.Result{RequeueAfter: time.Minute}, common.ReasonDeletionBlocked,
+			fmt.Errorf("role binding deletion is waiting for Perses instances: %s", strings.Join(blocked, "; ")))
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesRoleBindingReconciler) deleteRoleBinding(ctx context.Context, perses persesv1alpha2.Perses, roleBinding *persesv1alpha2.PersesRoleBinding) (*ctrl.Result, error) {
+	roleBindingNamespace, roleBindingName := roleBinding.Namespace, roleBinding.Name
+	persesClient, err := r.ClientFactory.CreateClient(ctx, r.APIReader, perses)
+	if err != nil {
+		rblog.WithError(err).Error("Failed to create perses rest client")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	_, err = persesClient.Project().Get(roleBindingNamespace)
+	if err != nil {
+		// The role binding went away with its project.
+		if errors.Is(err, perseshttp.RequestNotFoundError) {
+			rblog.Infof("Project not found: %s", roleBindingNamespace)
+			return subreconciler.ContinueReconciling()
+		}
+		rblog.WithError(err).Errorf("project error: %s", roleBindingNamespace)
+
+		return subreconciler.RequeueWithError(err)
+	}
+
+	existing, err := persesClient.RoleBinding(roleBindingNamespace).Get(roleBindingName)
+	if err != nil {
+		if errors.Is(err, perseshttp.RequestNotFoundError) {
+			rblog.Infof("RoleBinding not found: %s", roleBindingName)
+			return subreconciler.ContinueReconciling()
+		}
+		rblog.WithError(err).Errorf("Failed to get role binding: %s", roleBindingName)
+		return subreconciler.RequeueWithError(err)
+	}
+
+	// A role binding the operator does not own was either left untouched or overwritten, it is not removed.
+	if !common.IsManaged(existing.Metadata.Tags) {
+		rblog.Infof("RoleBinding not managed by the operator, keeping it: %s", roleBindingName)
+		return subreconciler.ContinueReconciling()
+	}
+
+	if common.IsDryRun(roleBinding, r.DryRun) {
+		rblog.Infof("Dry run, role binding %s would be deleted from Perses instance %s/%s", roleBindingName, perses.Namespace, perses.Name)
+		common.RecordInstanceEvent(r.Recorder, roleBinding, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: role binding would be deleted")
+		return subreconciler.ContinueReconciling()
+	}
+
+	err = persesClient.RoleBinding(roleBindingNamespace).Delete(roleBindingName)
+	// Ignore NotFound — the resource may have already been deleted from Perses directly.
+	// Any other error means the delete failed and should be retried.
+	if err != nil {
+		if errors.Is(err, perseshttp.RequestNotFoundError) {
+			rblog.Infof("RoleBinding not found: %s", roleBindingName)
+			return subreconciler.ContinueReconciling()
+		}
+		rblog.WithError(err).Errorf("Failed to delete role binding: %s", roleBindingName)
+		return subreconciler.RequeueWithError(err)
+	}
+
+	rblog.Infof("RoleBinding deleted: %s", roleBindingName)
+	common.RecordInstanceEvent(r.Recorder, roleBinding, perses, corev1.EventTypeNormal, common.EventReasonDeleted, "Role binding deleted")
+
+	return subreconciler.ContinueReconciling()
+}
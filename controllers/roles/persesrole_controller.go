@@ -0,0 +1,354 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roles
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	logger "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	operatormetrics "github.com/perses/perses-operator/internal/metrics"
+	"github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses-operator/internal/subreconciler"
+)
+
+type roleContextKey string
+
+const contextKey roleContextKey = "role"
+
+func withRole(ctx context.Context, v *persesv1alpha2.PersesRole) context.Context {
+	return context.WithValue(ctx, contextKey, v)
+}
+
+func roleFromContext(ctx context.Context) (*persesv1alpha2.PersesRole, bool) {
+	v, ok := ctx.Value(contextKey).(*persesv1alpha2.PersesRole)
+	return v, ok
+}
+
+// PersesRoleReconciler reconciles a PersesRole object
+type PersesRoleReconciler struct {
+	client.Client
+	APIReader             client.Reader // uncached reader — OnlyMetadata watch caches metadata only
+	Scheme                *runtime.Scheme
+	Recorder              record.EventRecorder
+	ClientFactory         common.PersesClientFactory
+	Metrics               *operatormetrics.Metrics
+	ReconciliationTracker *operatormetrics.ReconciliationTracker
+	// InstanceSyncConcurrency is the maximum number of Perses instances the role
+	// is synced to at the same time. Lower than 1 syncs one instance at a time.
+	InstanceSyncConcurrency int
+	// DryRun only plans the changes to the roles in Perses, without applying them,
+	// as for the roles with the perses.dev/dry-run annotation.
+	DryRun bool
+}
+
+var log = logger.WithField("module", "perses_roles_controller")
+
+// +kubebuilder:rbac:groups=perses.dev,resources=persesroles,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=perses.dev,resources=persesroles/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=perses.dev,resources=persesroles/finalizers,verbs=update
+func (r *PersesRoleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	start := time.Now()
+	objKey := req.String()
+
+	if r.Metrics != nil {
+		r.Metrics.ReconcileOperations("persesrole").Inc()
+	}
+
+	log.Infof("Reconciling PersesRole: %s/%s", req.Namespace, req.Name)
+
+	// Find once and store in context for all sub-reconcilers.
+	// Deletion is handled through the finalizer, so a missing object has nothing left to clean up.
+	role := &persesv1alpha2.PersesRole{}
+	if err := r.APIReader.Get(ctx, req.NamespacedName, role); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Infof("perses role resource not found. Ignoring since object must be deleted")
+			if r.ReconciliationTracker != nil {
+				r.ReconciliationTracker.ForgetObject(objKey)
+			}
+			if r.Metrics != nil {
+				r.Metrics.ForgetObject(objKey)
+			}
+			return subreconciler.Evaluate(subreconciler.DoNotRequeue())
+		}
+		log.WithError(err).Error("Failed to get perses role")
+		if r.Metrics != nil {
+			r.Metrics.ReconcileErrors("persesrole", "get_failed").Inc()
+		}
+		return subreconciler.Evaluate(subreconciler.RequeueWithError(err))
+	}
+
+	ctx = withRole(ctx, role)
+
+	subreconcilersForPerses := []subreconciler.FnWithRequest{
+		r.handleDelete,
+		r.addFinalizer,
+		r.setStatusToUnknown,
+		r.reconcileRoleInAllInstances,
+		r.setStatusToComplete,
+	}
+
+	var reconcileErr error
+	for _, f := range subreconcilersForPerses {
+		if r, err := f(ctx, req); subreconciler.ShouldHaltOrRequeue(r, err) {
+			reconcileErr = err
+			break
+		}
+	}
+
+	// Track reconciliation status
+	if r.ReconciliationTracker != nil {
+		r.ReconciliationTracker.SetStatus(objKey, reconcileErr)
+		if reconcileErr == nil {
+			r.ReconciliationTracker.SetReasonAndMessage(objKey, "ReconciliationSuccessful", "Role reconciled successfully")
+		}
+	}
+
+	// Track metrics
+	if r.Metrics != nil {
+		if reconcileErr != nil {
+			reason := string(common.ExtractReason(reconcileErr, "reconciliation_failed"))
+			r.Metrics.ReconcileErrors("persesrole", reason).Inc()
+			r.Metrics.SetFailedResources(objKey, "role", req.Namespace, 1)
+		} else {
+			r.Metrics.SetSyncedResources(objKey, "role", req.Namespace, 1)
+		}
+	}
+
+	if reconcileErr != nil {
+		return subreconciler.Evaluate(subreconciler.RequeueWithError(reconcileErr))
+	}
+
+	log.WithField("duration", time.Since(start)).Debug("role reconciliation completed")
+	return subreconciler.Evaluate(subreconciler.DoNotRequeue())
+}
+
+// handleDelete removes the role from the selected Perses instances, then releases the finalizer.
+func (r *PersesRoleReconciler) handleDelete(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	role, ok := roleFromContext(ctx)
+	if !ok {
+		log.Error("role not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("role not found in context"))
+	}
+
+	if role.GetDeletionTimestamp() == nil {
+		return subreconciler.ContinueReconciling()
+	}
+
+	if !controllerutil.ContainsFinalizer(role, common.PersesFinalizer) {
+		return subreconciler.DoNotRequeue()
+	}
+
+	if res, err := r.deleteRoleInAllInstances(ctx, req, role); subreconciler.ShouldHaltOrRequeue(res, err) {
+		return res, err
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesRole{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		if !controllerutil.RemoveFinalizer(fresh, common.PersesFinalizer) {
+			return nil
+		}
+		return r.Update(ctx, fresh)
+	})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to remove finalizer from perses role")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	log.Infof("PersesRole %s/%s deleted", role.Namespace, role.Name)
+	return subreconciler.DoNotRequeue()
+}
+
+// addFinalizer makes sure the role is not removed from the cluster before
+// it has been removed from the Perses instances it was synced to.
+func (r *PersesRoleReconciler) addFinalizer(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	role, ok := roleFromContext(ctx)
+	if !ok {
+		log.Error("role not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("role not found in context"))
+	}
+
+	if controllerutil.ContainsFinalizer(role, common.PersesFinalizer) {
+		return subreconciler.ContinueReconciling()
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesRole{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		if !controllerutil.AddFinalizer(fresh, common.PersesFinalizer) {
+			return nil
+		}
+		return r.Update(ctx, fresh)
+	})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to add finalizer to perses role")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesRoleReconciler) updateRoleStatus(
+	ctx context.Context,
+	req ctrl.Request,
+	updateFn func(*persesv1alpha2.PersesRole),
+) (*ctrl.Result, error) {
+	_, ok := roleFromContext(ctx)
+	if !ok {
+		log.Error("role not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("role not found in context"))
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesRole{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		before := common.SnapshotConditions(fresh.Status.Conditions)
+		updateFn(fresh)
+		if !common.ConditionsChanged(before, fresh.Status.Conditions) {
+			return nil
+		}
+		return r.Status().Update(ctx, fresh)
+	})
+
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("perses role resource not found. Ignoring since object must be deleted")
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to update Perses role status")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesRoleReconciler) setStatusToUnknown(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	return r.updateRoleStatus(ctx, req, func(role *persesv1alpha2.PersesRole) {
+		if len(role.Status.Conditions) == 0 {
+			meta.SetStatusCondition(&role.Status.Conditions, metav1.Condition{
+				Type: common.TypeAvailablePerses, Status: metav1.ConditionUnknown,
+				Reason: "Reconciling", Message: "Starting reconciliation"})
+		}
+	})
+}
+
+func (r *PersesRoleReconciler) setStatusToComplete(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	// The observed state is the one that was synced, the custom resource may have changed since.
+	synced, ok := roleFromContext(ctx)
+	if !ok {
+		log.Error("role not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("role not found in context"))
+	}
+
+	// Nothing was applied in a dry run, so the availability of the role is left as is.
+	if common.IsDryRun(synced, r.DryRun) {
+		return subreconciler.ContinueReconciling()
+	}
+
+	return r.updateRoleStatus(ctx, req, func(role *persesv1alpha2.PersesRole) {
+		meta.RemoveStatusCondition(&role.Status.Conditions, common.TypeDryRunPerses)
+		meta.SetStatusCondition(&role.Status.Conditions, metav1.Condition{
+			Type: common.TypeDegradedPerses, Status: metav1.ConditionFalse,
+			Reason: "Reconciled", Message: fmt.Sprintf("Role (%s) reconciled successfully", role.Name)})
+		meta.SetStatusCondition(&role.Status.Conditions, metav1.Condition{
+			Type: common.TypeAvailablePerses, Status: metav1.ConditionTrue,
+			Reason: "Reconciled", Message: fmt.Sprintf("Role (%s) created successfully", role.Name)})
+	})
+}
+
+func (r *PersesRoleReconciler) setStatusToDegraded(
+	ctx context.Context,
+	req ctrl.Request,
+	degradedResult *ctrl.Result,
+	degradedReason common.ConditionStatusReason,
+	degradedError error,
+) (*ctrl.Result, error) {
+	msg := "unknown error"
+	if degradedError != nil {
+		msg = degradedError.Error()
+	}
+
+	result, err := r.updateRoleStatus(ctx, req, func(role *persesv1alpha2.PersesRole) {
+		meta.SetStatusCondition(&role.Status.Conditions, metav1.Condition{
+			Type: common.TypeAvailablePerses, Status: metav1.ConditionFalse,
+			Reason: string(degradedReason), Message: msg})
+		meta.SetStatusCondition(&role.Status.Conditions, metav1.Condition{
+			Type: common.TypeDegradedPerses, Status: metav1.ConditionTrue,
+			Reason: string(degradedReason), Message: msg})
+	})
+
+	if err != nil {
+		return result, err
+	}
+
+	if degradedError != nil {
+		return degradedResult, common.NewReasonError(degradedError, degradedReason)
+	}
+	return degradedResult, nil
+}
+
+// findRolesForPerses returns reconcile requests for all PersesRoles
+// across all namespaces when a Perses instance becomes available.
+// Each role's instanceSelector labels determine which Perses instances it syncs to.
+// If no instanceSelector is set, the role syncs to all Perses instances.
+func (r *PersesRoleReconciler) findRolesForPerses(ctx context.Context, _ client.Object) []reconcile.Request {
+	return common.MetadataListToRequests(ctx, r.Client, persesv1alpha2.GroupVersion.WithKind("PersesRoleList"))
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// It watches PersesRole resources and also watches Perses instances
+// to trigger re-reconciliation of all roles when a Perses instance becomes
+// available. Roles are matched to Perses instances via instanceSelector labels.
+// Create and delete events for Perses instances are ignored because
+// the instance is not yet ready at creation, and deletion is handled by the role's
+// own reconciliation loop.
+func (r *PersesRoleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&persesv1alpha2.PersesRole{}, builder.OnlyMetadata).
+		Watches(
+			&persesv1alpha2.Perses{},
+			handler.EnqueueRequestsFromMapFunc(r.findRolesForPerses),
+			builder.WithPredicates(common.PersesAvailabilityPredicate()),
+		).
+		Complete(r)
+}
@@ -0,0 +1,405 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roles
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/perses/common/set"
+	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	internal "github.com/perses/perses-operator/internal/perses"
+	"github.com/perses/perses-operator/internal/perses/common"
+	persesclient "github.com/perses/perses/pkg/client/api/v1"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	persesv1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/role"
+	"github.com/stretchr/testify/mock"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func TestRoleController(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Role Controller Suite")
+}
+
+func newTestRoleReconciler(objects ...runtime.Object) *PersesRoleReconciler {
+	scheme := runtime.NewScheme()
+	Expect(persesv1alpha2.AddToScheme(scheme)).To(Succeed())
+
+	clientBuilder := fake.NewClientBuilder().WithScheme(scheme)
+	for _, obj := range objects {
+		clientBuilder = clientBuilder.WithRuntimeObjects(obj)
+	}
+	clientBuilder = clientBuilder.WithStatusSubresource(&persesv1alpha2.PersesRole{})
+
+	c := clientBuilder.Build()
+	return &PersesRoleReconciler{
+		Client:    c,
+		APIReader: c,
+		Scheme:    scheme,
+	}
+}
+
+var _ = Describe("Role controller", func() {
+	const RoleName = "viewer"
+	const RoleNamespace = "default"
+
+	newPerses := func(name string) *persesv1alpha2.Perses {
+		return &persesv1alpha2.Perses{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "perses-dev"},
+		}
+	}
+
+	Context("syncPersesRole", func() {
+		newRole := func() *persesv1alpha2.PersesRole {
+			return &persesv1alpha2.PersesRole{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      RoleName,
+					Namespace: RoleNamespace,
+				},
+				Spec: persesv1alpha2.RoleSpec{
+					Config: persesv1alpha2.Role{
+						RoleSpec: persesv1.RoleSpec{
+							Permissions: []role.Permission{{
+								Actions: []role.Action{role.ReadAction},
+								Scopes:  []role.Scope{role.DashboardScope},
+							}},
+						},
+					},
+				},
+			}
+		}
+
+		expectedRole := func() *persesv1.Role {
+			return &persesv1.Role{
+				Kind: persesv1.KindRole,
+				Metadata: persesv1.ProjectMetadata{
+					Metadata: persesv1.Metadata{
+						Name: RoleName,
+						Tags: common.WithManagedTag(nil),
+					},
+				},
+				Spec: newRole().Spec.Config.RoleSpec,
+			}
+		}
+
+		It("should create the project of the namespace before the role when it is missing", func() {
+			mockProject := &internal.MockProject{}
+			mockPersesClient := &internal.MockClient{Projects: mockProject}
+			mockProject.On("Get", RoleNamespace).Return(&persesv1.Project{}, perseshttp.RequestNotFoundError)
+			project := common.DesiredProject(RoleNamespace, nil)
+			project.Metadata.Tags = common.WithManagedTag(nil)
+			mockProject.On("Create", project).Return(project, nil).Once()
+			mockRole := &internal.MockRole{}
+			mockPersesClient.On("Role", RoleNamespace).Return(mockRole)
+			mockRole.On("Get", RoleName).Return(&persesv1.Role{}, perseshttp.RequestNotFoundError)
+			mockRole.On("Create", expectedRole()).Return(expectedRole(), nil).Once()
+
+			r := newTestRoleReconciler()
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			_, reason, err := r.syncPersesRole(context.Background(), *newPerses("perses"), newRole())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reason).To(BeEmpty())
+			mockProject.AssertExpectations(GinkgoT())
+			mockRole.AssertExpectations(GinkgoT())
+		})
+
+		It("should claim a role of the same name created in Perses by hand", func() {
+			existing := expectedRole()
+			existing.Metadata.Tags = nil
+			existing.Spec.Permissions[0].Actions = []role.Action{role.WildcardAction}
+
+			mockPersesClient := &internal.MockClient{}
+			mockRole := &internal.MockRole{}
+			mockPersesClient.On("Role", RoleNamespace).Return(mockRole)
+			mockRole.On("Get", RoleName).Return(existing, nil)
+			mockRole.On("Update", expectedRole()).Return(expectedRole(), nil).Once()
+
+			recorder := record.NewFakeRecorder(10)
+			r := newTestRoleReconciler()
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+			r.Recorder = recorder
+
+			_, _, err := r.syncPersesRole(context.Background(), *newPerses("perses"), newRole())
+			Expect(err).ToNot(HaveOccurred())
+			mockRole.AssertExpectations(GinkgoT())
+			Expect(recorder.Events).To(Receive(And(
+				ContainSubstring(common.EventReasonUpdated),
+				ContainSubstring(common.ManagedTag),
+				ContainSubstring(`"path":"spec.permissions[0].actions[0]"`),
+			)))
+		})
+
+		It("should write the ownership tag to a role that lacks it", func() {
+			existing := expectedRole()
+			existing.Metadata.Tags = nil
+
+			mockPersesClient := &internal.MockClient{}
+			mockRole := &internal.MockRole{}
+			mockPersesClient.On("Role", RoleNamespace).Return(mockRole)
+			mockRole.On("Get", RoleName).Return(existing, nil)
+			mockRole.On("Update", expectedRole()).Return(expectedRole(), nil).Once()
+
+			r := newTestRoleReconciler()
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+			r.Recorder = record.NewFakeRecorder(10)
+
+			_, _, err := r.syncPersesRole(context.Background(), *newPerses("perses"), newRole())
+			Expect(err).ToNot(HaveOccurred())
+			mockRole.AssertExpectations(GinkgoT())
+		})
+
+		It("should report a conflict and leave a role not managed by the operator untouched", func() {
+			existing := expectedRole()
+			existing.Metadata.Tags = set.New("infra")
+
+			mockPersesClient := &internal.MockClient{}
+			mockRole := &internal.MockRole{}
+			mockPersesClient.On("Role", RoleNamespace).Return(mockRole)
+			mockRole.On("Get", RoleName).Return(existing, nil)
+
+			r := newTestRoleReconciler()
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			persesRole := newRole()
+			persesRole.Spec.ConflictPolicy = persesv1alpha2.ConflictPolicyFail
+			_, reason, err := r.syncPersesRole(context.Background(), *newPerses("perses"), persesRole)
+			Expect(err).To(HaveOccurred())
+			Expect(reason).To(Equal(common.ReasonConflict))
+			mockRole.AssertNotCalled(GinkgoT(), "Update", mock.Anything)
+		})
+
+		It("should overwrite a role not managed by the operator without taking ownership of it", func() {
+			existing := expectedRole()
+			existing.Metadata.Tags = nil
+			existing.Spec.Permissions[0].Actions = []role.Action{role.WildcardAction}
+
+			mockPersesClient := &internal.MockClient{}
+			mockRole := &internal.MockRole{}
+			mockPersesClient.On("Role", RoleNamespace).Return(mockRole)
+			mockRole.On("Get", RoleName).Return(existing, nil)
+			mockRole.On("Update", mock.MatchedBy(func(persesRole *persesv1.Role) bool {
+				return !common.IsManaged(persesRole.Metadata.Tags)
+			})).Return(&persesv1.Role{}, nil).Once()
+
+			r := newTestRoleReconciler()
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+			r.Recorder = record.NewFakeRecorder(10)
+
+			persesRole := newRole()
+			persesRole.Spec.ConflictPolicy = persesv1alpha2.ConflictPolicyOverwrite
+			_, _, err := r.syncPersesRole(context.Background(), *newPerses("perses"), persesRole)
+			Expect(err).ToNot(HaveOccurred())
+			mockRole.AssertExpectations(GinkgoT())
+		})
+
+		It("should only plan the creation of a role in a dry run", func() {
+			mockPersesClient := &internal.MockClient{}
+			mockRole := &internal.MockRole{}
+			mockPersesClient.On("Role", RoleNamespace).Return(mockRole)
+			mockRole.On("Get", RoleName).Return(&persesv1.Role{}, perseshttp.RequestNotFoundError)
+
+			recorder := record.NewFakeRecorder(10)
+			r := newTestRoleReconciler()
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+			r.Recorder = recorder
+			r.DryRun = true
+
+			_, reason, err := r.syncPersesRole(context.Background(), *newPerses("perses"), newRole())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reason).To(Equal(common.ReasonDryRun))
+			mockRole.AssertNotCalled(GinkgoT(), "Create", expectedRole())
+			Expect(recorder.Events).To(Receive(ContainSubstring("Dry run: role would be created")))
+		})
+	})
+
+	Context("reconcileRoleInAllInstances", func() {
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: RoleName, Namespace: RoleNamespace}}
+
+		It("should sync the role to the healthy Perses instances when another one fails", func() {
+			mockPersesClient := &internal.MockClient{}
+			mockRole := &internal.MockRole{}
+			mockPersesClient.On("Role", RoleNamespace).Return(mockRole)
+			mockRole.On("Get", RoleName).Return(&persesv1.Role{}, perseshttp.RequestNotFoundError)
+			mockRole.On("Create", mock.Anything).Return(&persesv1.Role{}, nil)
+
+			persesRole := &persesv1alpha2.PersesRole{
+				ObjectMeta: metav1.ObjectMeta{Name: RoleName, Namespace: RoleNamespace},
+			}
+			r := newTestRoleReconciler(persesRole, availablePerses("perses-a", nil), availablePerses("perses-b", nil), availablePerses("perses-c", nil))
+			r.ClientFactory = instanceClientFactory{"perses-a": mockPersesClient, "perses-c": mockPersesClient}
+			r.InstanceSyncConcurrency = 2
+			r.Recorder = record.NewFakeRecorder(10)
+
+			_, err := r.reconcileRoleInAllInstances(withRole(context.Background(), persesRole), req)
+			Expect(err).To(HaveOccurred())
+			Expect(common.ExtractReason(err, "")).To(Equal(common.ReasonConnectionFailed))
+			mockRole.AssertNumberOfCalls(GinkgoT(), "Create", 2)
+		})
+	})
+
+	Context("handleDelete", func() {
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: RoleName, Namespace: RoleNamespace}}
+
+		deletingRole := func() *persesv1alpha2.PersesRole {
+			return &persesv1alpha2.PersesRole{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              RoleName,
+					Namespace:         RoleNamespace,
+					Finalizers:        []string{common.PersesFinalizer},
+					DeletionTimestamp: &metav1.Time{Time: time.Now()},
+				},
+				Spec: persesv1alpha2.RoleSpec{
+					InstanceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "observability"}},
+				},
+			}
+		}
+
+		persesRole := func(tags set.Set[string]) *persesv1.Role {
+			return &persesv1.Role{
+				Metadata: persesv1.ProjectMetadata{Metadata: persesv1.Metadata{Name: RoleName, Tags: tags}},
+			}
+		}
+
+		selected := map[string]string{"team": "observability"}
+
+		It("should only delete the role from the selected Perses instances", func() {
+			deleting := deletingRole()
+			selectedClient := &internal.MockClient{}
+			otherClient := &internal.MockClient{}
+			mockRole := &internal.MockRole{}
+			selectedClient.On("Role", RoleNamespace).Return(mockRole)
+			mockRole.On("Get", RoleName).Return(persesRole(common.WithManagedTag(nil)), nil)
+			mockRole.On("Delete", RoleName).Return(nil).Once()
+
+			recorder := record.NewFakeRecorder(10)
+			r := newTestRoleReconciler(deleting, availablePerses("selected", selected), availablePerses("other", nil))
+			r.ClientFactory = instanceClientFactory{"selected": selectedClient, "other": otherClient}
+			r.Recorder = recorder
+
+			_, err := r.handleDelete(withRole(context.Background(), deleting), req)
+			Expect(err).ToNot(HaveOccurred())
+			mockRole.AssertExpectations(GinkgoT())
+			otherClient.AssertNotCalled(GinkgoT(), "Role", RoleNamespace)
+			Expect(recorder.Events).To(Receive(ContainSubstring("Perses instance perses-dev/selected: Role deleted")))
+
+			err = r.Get(context.Background(), req.NamespacedName, &persesv1alpha2.PersesRole{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should keep a role not managed by the operator in Perses", func() {
+			deleting := deletingRole()
+			mockPersesClient := &internal.MockClient{}
+			mockRole := &internal.MockRole{}
+			mockPersesClient.On("Role", RoleNamespace).Return(mockRole)
+			mockRole.On("Get", RoleName).Return(persesRole(nil), nil)
+
+			r := newTestRoleReconciler(deleting, availablePerses("perses", selected))
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			_, err := r.handleDelete(withRole(context.Background(), deleting), req)
+			Expect(err).ToNot(HaveOccurred())
+			mockRole.AssertNotCalled(GinkgoT(), "Delete", RoleName)
+
+			err = r.Get(context.Background(), req.NamespacedName, &persesv1alpha2.PersesRole{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should ignore a role already deleted from Perses", func() {
+			deleting := deletingRole()
+			mockPersesClient := &internal.MockClient{}
+			mockRole := &internal.MockRole{}
+			mockPersesClient.On("Role", RoleNamespace).Return(mockRole)
+			mockRole.On("Get", RoleName).Return(&persesv1.Role{}, perseshttp.RequestNotFoundError)
+
+			r := newTestRoleReconciler(deleting, availablePerses("perses", selected))
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			res, err := r.handleDelete(withRole(context.Background(), deleting), req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(&ctrl.Result{}))
+			mockRole.AssertNotCalled(GinkgoT(), "Delete", RoleName)
+		})
+
+		It("should not delete anything when the project no longer exists in Perses", func() {
+			deleting := deletingRole()
+			mockProject := &internal.MockProject{}
+			mockPersesClient := &internal.MockClient{Projects: mockProject}
+			mockProject.On("Get", RoleNamespace).Return(&persesv1.Project{}, perseshttp.RequestNotFoundError)
+
+			r := newTestRoleReconciler(deleting, availablePerses("perses", selected))
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			res, err := r.handleDelete(withRole(context.Background(), deleting), req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(&ctrl.Result{}))
+			mockPersesClient.AssertNotCalled(GinkgoT(), "Role", RoleNamespace)
+		})
+
+		It("should keep the finalizer while a selected Perses instance is not available", func() {
+			deleting := deletingRole()
+			mockPersesClient := &internal.MockClient{}
+			unavailable := newPerses("perses")
+			unavailable.Labels = selected
+
+			recorder := record.NewFakeRecorder(10)
+			r := newTestRoleReconciler(deleting, unavailable)
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+			r.Recorder = recorder
+
+			res, err := r.handleDelete(withRole(context.Background(), deleting), req)
+			Expect(common.ExtractReason(err, "")).To(Equal(common.ReasonDeletionBlocked))
+			Expect(res.RequeueAfter).To(Equal(time.Minute))
+			Expect(recorder.Events).To(Receive(ContainSubstring("Role deletion is waiting for the instance to be available")))
+			mockPersesClient.AssertNotCalled(GinkgoT(), "Role", RoleNamespace)
+
+			fresh := &persesv1alpha2.PersesRole{}
+			Expect(r.Get(context.Background(), req.NamespacedName, fresh)).To(Succeed())
+			Expect(controllerutil.ContainsFinalizer(fresh, common.PersesFinalizer)).To(BeTrue())
+		})
+	})
+})
+
+func availablePerses(name string, labels map[string]string) *persesv1alpha2.Perses {
+	return &persesv1alpha2.Perses{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "perses-dev", Labels: labels},
+		Status: persesv1alpha2.PersesStatus{
+			Conditions: []metav1.Condition{{Type: common.TypeAvailablePerses, Status: metav1.ConditionTrue}},
+		},
+	}
+}
+
+// instanceClientFactory returns the Perses client of each instance by name.
+type instanceClientFactory map[string]persesclient.ClientInterface
+
+func (f instanceClientFactory) CreateClient(_ context.Context, _ client.Reader, perses persesv1alpha2.Perses) (persesclient.ClientInterface, error) {
+	if persesClient, ok := f[perses.Name]; ok {
+		return persesClient, nil
+	}
+	return nil, fmt.Errorf("connection refused")
+}
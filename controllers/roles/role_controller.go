@@ -0,0 +1,318 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roles
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/perses/common/set"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	persesv1 "github.com/perses/perses/pkg/model/api/v1"
+
+	logger "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	"github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses-operator/internal/subreconciler"
+)
+
+var rlog = logger.WithField("module", "role_controller")
+
+func (r *PersesRoleReconciler) reconcileRoleInAllInstances(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	role, ok := roleFromContext(ctx)
+	if !ok {
+		rlog.Error("role not found in context")
+		res, err := subreconciler.RequeueWithError(fmt.Errorf("role not found in context"))
+		return r.setStatusToDegraded(ctx, req, res, common.ReasonMissingResource, err)
+	}
+
+	persesInstances, err := r.listSelectedInstances(ctx, role)
+	if err != nil {
+		rlog.WithError(err).Error("Failed to get perses instances")
+		res, err := subreconciler.RequeueWithError(err)
+		return r.setStatusToDegraded(ctx, req, res, common.ReasonMissingPerses, err)
+	}
+
+	if len(persesInstances.Items) == 0 {
+		rlog.Info("No Perses instances found, retrying in 1 minute")
+		return r.setStatusToDegraded(ctx, req, &ctrl.Result{RequeueAfter: time.Minute}, common.ReasonMissingPerses, fmt.Errorf("no Perses instances found matching the label selector"))
+	}
+
+	available := make([]persesv1alpha2.Perses, 0, len(persesInstances.Items))
+	for _, persesInstance := range persesInstances.Items {
+		if !meta.IsStatusConditionTrue(persesInstance.Status.Conditions, common.TypeAvailablePerses) {
+			rlog.Infof("Skipping Perses instance %s/%s (not yet available)", persesInstance.Namespace, persesInstance.Name)
+			continue
+		}
+		available = append(available, persesInstance)
+	}
+
+	// Every instance is synced, so that a failing instance does not hold back the others.
+	outcomes := subreconciler.ForEach(available, r.InstanceSyncConcurrency, func(persesInstance persesv1alpha2.Perses) (*ctrl.Result, common.ConditionStatusReason, error) {
+		return r.syncPersesRole(ctx, persesInstance, role)
+	})
+
+	var planned, failures []string
+	var firstFailure subreconciler.Outcome
+	for i, persesInstance := range available {
+		instanceName := fmt.Sprintf("%s/%s", persesInstance.Namespace, persesInstance.Name)
+		outcome := outcomes[i]
+		if outcome.Halted() {
+			if len(failures) == 0 {
+				firstFailure = outcome
+			}
+			failures = append(failures, fmt.Sprintf("%s: %v", instanceName, outcome.Err))
+			common.RecordInstanceEvent(r.Recorder, role, persesInstance, corev1.EventTypeWarning, string(outcome.Reason), "Failed to sync role: %v", outcome.Err)
+			continue
+		}
+		if outcome.Reason == common.ReasonDryRun {
+			planned = append(planned, instanceName)
+		}
+	}
+
+	if len(failures) > 0 {
+		rlog.Errorf("Role %s failed to sync to %d of %d Perses instances", role.Name, len(failures), len(available))
+		// A single failure is reported as is, so that its message stays actionable.
+		failure := firstFailure.Err
+		if len(failures) > 1 {
+			failure = fmt.Errorf("failed to sync to %d of %d Perses instances: %s", len(failures), len(available), strings.Join(failures, "; "))
+		}
+		return r.setStatusToDegraded(ctx, req, firstFailure.Result, firstFailure.Reason, failure)
+	}
+
+	if common.IsDryRun(role, r.DryRun) {
+		return r.updateRoleStatus(ctx, req, func(role *persesv1alpha2.PersesRole) {
+			meta.SetStatusCondition(&role.Status.Conditions, common.DryRunCondition("Role", role.Name, planned))
+		})
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesRoleReconciler) listSelectedInstances(ctx context.Context, role *persesv1alpha2.PersesRole) (*persesv1alpha2.PersesList, error) {
+	var labelSelector labels.Selector
+	if role.Spec.InstanceSelector == nil {
+		labelSelector = labels.Everything()
+	} else {
+		var err error
+		labelSelector, err = metav1.LabelSelectorAsSelector(role.Spec.InstanceSelector)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	persesInstances := &persesv1alpha2.PersesList{}
+	opts := &client.ListOptions{
+		LabelSelector: labelSelector,
+	}
+	if err := r.List(ctx, persesInstances, opts); err != nil {
+		return nil, err
+	}
+	return persesInstances, nil
+}
+
+func (r *PersesRoleReconciler) syncPersesRole(ctx context.Context, perses persesv1alpha2.Perses, role *persesv1alpha2.PersesRole) (*ctrl.Result, common.ConditionStatusReason, error) {
+	persesClient, err := r.ClientFactory.CreateClient(ctx, r.APIReader, perses)
+	if err != nil {
+		rlog.WithError(err).Error("Failed to create perses rest client")
+		return subreconciler.RequeueWithErrorAndReason(err, common.ReasonConnectionFailed)
+	}
+
+	// A dry run does not create the project, the role is then planned for creation.
+	dryRun := common.IsDryRun(role, r.DryRun)
+	if !dryRun {
+		if reason, err := common.EnsureProject(ctx, r.APIReader, persesClient, perses, role.Namespace); err != nil {
+			return subreconciler.RequeueWithErrorAndReason(err, reason)
+		}
+	}
+
+	existing, err := persesClient.Role(role.Namespace).Get(role.Name)
+	notFound := err != nil && errors.Is(err, perseshttp.RequestNotFoundError)
+
+	if err != nil && !notFound {
+		return subreconciler.RequeueWithErrorAndReason(err, common.ReasonBackendError)
+	}
+
+	var existingTags, tags set.Set[string]
+	if !notFound {
+		existingTags = existing.Metadata.Tags
+	}
+	managed, err := common.ClaimOwnership(role.Spec.ConflictPolicy, "role", role.Name, !notFound, existingTags)
+	if err != nil {
+		rlog.WithError(err).Errorf("Role conflict: %s", role.Name)
+		return subreconciler.RequeueWithErrorAndReason(err, common.ReasonConflict)
+	}
+	if managed {
+		tags = common.WithManagedTag(nil)
+	}
+
+	persesRole := &persesv1.Role{
+		Kind: persesv1.KindRole,
+		Metadata: persesv1.ProjectMetadata{
+			Metadata: persesv1.Metadata{
+				Name: role.Name,
+				Tags: tags,
+			},
+		},
+		Spec: role.Spec.Config.RoleSpec,
+	}
+
+	if !notFound && common.RoleInSync(existing, persesRole) {
+		rlog.Debugf("Role already in sync: %s", role.Name)
+		res, err := subreconciler.ContinueReconciling()
+		return res, "", err
+	}
+
+	if dryRun {
+		plan := "created"
+		if !notFound {
+			plan = fmt.Sprintf("updated: %s", common.FormatDiff(common.RoleDiff(existing, persesRole), common.MaxEventDiffLength))
+		}
+		rlog.Infof("Dry run, role %s would be %s in Perses instance %s/%s", role.Name, plan, perses.Namespace, perses.Name)
+		common.RecordInstanceEvent(r.Recorder, role, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: role would be %s", plan)
+		res, err := subreconciler.ContinueReconciling()
+		return res, common.ReasonDryRun, err
+	}
+
+	if notFound {
+		_, err = persesClient.Role(role.Namespace).Create(persesRole)
+		if err != nil {
+			rlog.WithError(err).Errorf("Failed to create role: %s", role.Name)
+			return subreconciler.RequeueWithErrorAndReason(err, common.ReasonBackendError)
+		}
+		rlog.Infof("Role created: %s", role.Name)
+		common.RecordInstanceEvent(r.Recorder, role, perses, corev1.EventTypeNormal, common.EventReasonCreated, "Role created")
+	} else {
+		_, err = persesClient.Role(role.Namespace).Update(persesRole)
+		if err != nil {
+			rlog.WithError(err).Errorf("Failed to update role: %s", role.Name)
+			return subreconciler.RequeueWithErrorAndReason(err, common.ReasonBackendError)
+		}
+		rlog.Infof("Role updated: %s", role.Name)
+		common.RecordInstanceEvent(r.Recorder, role, perses, corev1.EventTypeNormal, common.EventReasonUpdated, "Role updated: %s", common.FormatDiff(common.RoleDiff(existing, persesRole), common.MaxEventDiffLength))
+	}
+
+	res, err := subreconciler.ContinueReconciling()
+	return res, "", err
+}
+
+// deleteRoleInAllInstances removes the role from the Perses instances selected by the
+// PersesRole. The deletion is blocked while one of them cannot confirm the removal.
+func (r *PersesRoleReconciler) deleteRoleInAllInstances(ctx context.Context, req ctrl.Request, role *persesv1alpha2.PersesRole) (*ctrl.Result, error) {
+	persesInstances, err := r.listSelectedInstances(ctx, role)
+	if err != nil {
+		rlog.WithError(err).Error("Failed to get perses instances")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	var blocked []string
+	available := make([]persesv1alpha2.Perses, 0, len(persesInstances.Items))
+	for _, persesInstance := range persesInstances.Items {
+		if !meta.IsStatusConditionTrue(persesInstance.Status.Conditions, common.TypeAvailablePerses) {
+			rlog.Infof("Perses instance %s/%s is not available, role deletion is blocked", persesInstance.Namespace, persesInstance.Name)
+			blocked = append(blocked, fmt.Sprintf("%s/%s (not available)", persesInstance.Namespace, persesInstance.Name))
+			common.RecordInstanceEvent(r.Recorder, role, persesInstance, corev1.EventTypeWarning, string(common.ReasonDeletionBlocked), "Role deletion is waiting for the instance to be available")
+			continue
+		}
+		available = append(available, persesInstance)
+	}
+
+	outcomes := subreconciler.ForEach(available, r.InstanceSyncConcurrency, func(persesInstance persesv1alpha2.Perses) (*ctrl.Result, common.ConditionStatusReason, error) {
+		res, err := r.deleteRole(ctx, persesInstance, role)
+		return res, "", err
+	})
+	for i, persesInstance := range available {
+		if outcome := outcomes[i]; outcome.Halted() {
+			blocked = append(blocked, fmt.Sprintf("%s/%s (%v)", persesInstance.Namespace, persesInstance.Name, outcome.Err))
+			common.RecordInstanceEvent(r.Recorder, role, persesInstance, corev1.EventTypeWarning, string(common.ReasonDeletionBlocked), "Failed to delete role: %v", outcome.Err)
+		}
+	}
+
+	if len(blocked) > 0 {
+		return r.setStatusToDegraded(ctx, req, &ctrl.Result{RequeueAfter: time.Minute}, common.ReasonDeletionBlocked,
+			fmt.Errorf("role deletion is waiting for Perses instances: %s", strings.Join(blocked, "; ")))
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesRoleReconciler) deleteRole(ctx context.Context, perses persesv1alpha2.Perses, role *persesv1alpha2.PersesRole) (*ctrl.Result, error) {
+	roleNamespace, roleName := role.Namespace, role.Name
+	persesClient, err := r.ClientFactory.CreateClient(ctx, r.APIReader, perses)
+	if err != nil {
+		rlog.WithError(err).Error("Failed to create perses rest client")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	_, err = persesClient.Project().Get(roleNamespace)
+	if err != nil {
+		// The role went away with its project.
+		if errors.Is(err, perseshttp.RequestNotFoundError) {
+			rlog.Infof("Project not found: %s", roleNamespace)
+			return subreconciler.ContinueReconciling()
+		}
+		rlog.WithError(err).Errorf("project error: %s", roleNamespace)
+
+		return subreconciler.RequeueWithError(err)
+	}
+
+	existing, err := persesClient.Role(roleNamespace).Get(roleName)
+	if err != nil {
+		if errors.Is(err, perseshttp.RequestNotFoundError) {
+			rlog.Infof("Role not found: %s", roleName)
+			return subreconciler.ContinueReconciling()
+		}
+		rlog.WithError(err).Errorf("Failed to get role: %s", roleName)
+		return subreconciler.RequeueWithError(err)
+	}
+
+	// A role the operator does not own was either left untouched or overwritten, it is not removed.
+	if !common.IsManaged(existing.Metadata.Tags) {
+		rlog.Infof("Role not managed by the operator, keeping it: %s", roleName)
+		return subreconciler.ContinueReconciling()
+	}
+
+	if common.IsDryRun(role, r.DryRun) {
+		rlog.Infof("Dry run, role %s would be deleted from Perses instance %s/%s", roleName, perses.Namespace, perses.Name)
+		common.RecordInstanceEvent(r.Recorder, role, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: role would be deleted")
+		return subreconciler.ContinueReconciling()
+	}
+
+	err = persesClient.Role(roleNamespace).Delete(roleName)
+	// Ignore NotFound — the resource may have already been deleted from Perses directly.
+	// Any other error means the delete failed and should be retried.
+	if err != nil {
+		if errors.Is(err, perseshttp.RequestNotFoundError) {
+			rlog.Infof("Role not found: %s", roleName)
+			return subreconciler.ContinueReconciling()
+		}
+		rlog.WithError(err).Errorf("Failed to delete role: %s", roleName)
+		return subreconciler.RequeueWithError(err)
+	}
+
+	rlog.Infof("Role deleted: %s", roleName)
+	common.RecordInstanceEvent(r.Recorder, role, perses, corev1.EventTypeNormal, common.EventReasonDeleted, "Role deleted")
+
+	return subreconciler.ContinueReconciling()
+}
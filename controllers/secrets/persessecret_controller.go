@@ -0,0 +1,416 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	logger "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	internalcache "github.com/perses/perses-operator/internal/cache"
+	operatormetrics "github.com/perses/perses-operator/internal/metrics"
+	"github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses-operator/internal/subreconciler"
+)
+
+type secretContextKey string
+
+const contextKey secretContextKey = "secret"
+
+func withSecret(ctx context.Context, v *persesv1alpha2.PersesSecret) context.Context {
+	return context.WithValue(ctx, contextKey, v)
+}
+
+func secretFromContext(ctx context.Context) (*persesv1alpha2.PersesSecret, bool) {
+	v, ok := ctx.Value(contextKey).(*persesv1alpha2.PersesSecret)
+	return v, ok
+}
+
+// PersesSecretReconciler reconciles a PersesSecret object
+type PersesSecretReconciler struct {
+	client.Client
+	APIReader             client.Reader // uncached reader — OnlyMetadata watch caches metadata only
+	Scheme                *runtime.Scheme
+	Recorder              record.EventRecorder
+	ClientFactory         common.PersesClientFactory
+	Metrics               *operatormetrics.Metrics
+	ReconciliationTracker *operatormetrics.ReconciliationTracker
+	// DryRun only plans the changes to the secrets in Perses, without applying them,
+	// as for the secrets with the perses.dev/dry-run annotation.
+	DryRun bool
+	// ConfigMapCache holds the metadata of the ConfigMaps labeled for watching.
+	// When nil, changes to referenced ConfigMaps do not trigger reconciliation.
+	ConfigMapCache cache.Cache
+
+	secrets common.SecretContentTracker
+}
+
+var log = logger.WithField("module", "perses_secrets_controller")
+
+// +kubebuilder:rbac:groups=perses.dev,resources=persessecrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=perses.dev,resources=persessecrets/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=perses.dev,resources=persessecrets/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get
+// +kubebuilder:rbac:groups="",resources=configmaps;secrets,verbs=get;list;watch
+func (r *PersesSecretReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	start := time.Now()
+	objKey := req.String()
+
+	if r.Metrics != nil {
+		r.Metrics.ReconcileOperations("persessecret").Inc()
+	}
+
+	log.Infof("Reconciling PersesSecret: %s/%s", req.Namespace, req.Name)
+
+	// Find once and store in context for all sub-reconcilers.
+	// Deletion is handled through the finalizer, so a missing object has nothing left to clean up.
+	secret := &persesv1alpha2.PersesSecret{}
+	if err := r.APIReader.Get(ctx, req.NamespacedName, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Infof("perses secret resource not found. Ignoring since object must be deleted")
+			if r.ReconciliationTracker != nil {
+				r.ReconciliationTracker.ForgetObject(objKey)
+			}
+			if r.Metrics != nil {
+				r.Metrics.ForgetObject(objKey)
+			}
+			return subreconciler.Evaluate(subreconciler.DoNotRequeue())
+		}
+		log.WithError(err).Error("Failed to get perses secret")
+		if r.Metrics != nil {
+			r.Metrics.ReconcileErrors("persessecret", "get_failed").Inc()
+		}
+		return subreconciler.Evaluate(subreconciler.RequeueWithError(err))
+	}
+
+	ctx = withSecret(ctx, secret)
+
+	subreconcilersForPerses := []subreconciler.FnWithRequest{
+		r.handleDelete,
+		r.addFinalizer,
+		r.setStatusToUnknown,
+		r.reconcileSecretInAllInstances,
+		r.setStatusToComplete,
+	}
+
+	var reconcileErr error
+	for _, f := range subreconcilersForPerses {
+		if r, err := f(ctx, req); subreconciler.ShouldHaltOrRequeue(r, err) {
+			reconcileErr = err
+			break
+		}
+	}
+
+	// Track reconciliation status
+	if r.ReconciliationTracker != nil {
+		r.ReconciliationTracker.SetStatus(objKey, reconcileErr)
+		if reconcileErr == nil {
+			r.ReconciliationTracker.SetReasonAndMessage(objKey, "ReconciliationSuccessful", "Secret reconciled successfully")
+		}
+	}
+
+	// Track metrics
+	if r.Metrics != nil {
+		if reconcileErr != nil {
+			reason := string(common.ExtractReason(reconcileErr, "reconciliation_failed"))
+			r.Metrics.ReconcileErrors("persessecret", reason).Inc()
+			r.Metrics.SetFailedResources(objKey, "secret", req.Namespace, 1)
+		} else {
+			r.Metrics.SetSyncedResources(objKey, "secret", req.Namespace, 1)
+		}
+	}
+
+	if reconcileErr != nil {
+		return subreconciler.Evaluate(subreconciler.RequeueWithError(reconcileErr))
+	}
+
+	log.WithField("duration", time.Since(start)).Debug("secret reconciliation completed")
+	return subreconciler.Evaluate(subreconciler.DoNotRequeue())
+}
+
+// handleDelete removes the secret from the selected Perses instances, then releases the finalizer.
+func (r *PersesSecretReconciler) handleDelete(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	secret, ok := secretFromContext(ctx)
+	if !ok {
+		log.Error("secret not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("secret not found in context"))
+	}
+
+	if secret.GetDeletionTimestamp() == nil {
+		return subreconciler.ContinueReconciling()
+	}
+
+	if !controllerutil.ContainsFinalizer(secret, common.PersesFinalizer) {
+		return subreconciler.DoNotRequeue()
+	}
+
+	if res, err := r.deleteSecretInAllInstances(ctx, req, secret); subreconciler.ShouldHaltOrRequeue(res, err) {
+		return res, err
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesSecret{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		if !controllerutil.RemoveFinalizer(fresh, common.PersesFinalizer) {
+			return nil
+		}
+		return r.Update(ctx, fresh)
+	})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to remove finalizer from perses secret")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	log.Infof("PersesSecret %s/%s deleted", secret.Namespace, secret.Name)
+	return subreconciler.DoNotRequeue()
+}
+
+// addFinalizer makes sure the secret is not removed from the cluster before
+// it has been removed from the Perses instances it was synced to.
+func (r *PersesSecretReconciler) addFinalizer(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	secret, ok := secretFromContext(ctx)
+	if !ok {
+		log.Error("secret not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("secret not found in context"))
+	}
+
+	if controllerutil.ContainsFinalizer(secret, common.PersesFinalizer) {
+		return subreconciler.ContinueReconciling()
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesSecret{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		if !controllerutil.AddFinalizer(fresh, common.PersesFinalizer) {
+			return nil
+		}
+		return r.Update(ctx, fresh)
+	})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to add finalizer to perses secret")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesSecretReconciler) updateSecretStatus(
+	ctx context.Context,
+	req ctrl.Request,
+	updateFn func(*persesv1alpha2.PersesSecret),
+) (*ctrl.Result, error) {
+	_, ok := secretFromContext(ctx)
+	if !ok {
+		log.Error("secret not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("secret not found in context"))
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesSecret{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		before := common.SnapshotConditions(fresh.Status.Conditions)
+		updateFn(fresh)
+		if !common.ConditionsChanged(before, fresh.Status.Conditions) {
+			return nil
+		}
+		return r.Status().Update(ctx, fresh)
+	})
+
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("perses secret resource not found. Ignoring since object must be deleted")
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to update Perses secret status")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesSecretReconciler) setStatusToUnknown(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	return r.updateSecretStatus(ctx, req, func(secret *persesv1alpha2.PersesSecret) {
+		if len(secret.Status.Conditions) == 0 {
+			meta.SetStatusCondition(&secret.Status.Conditions, metav1.Condition{
+				Type: common.TypeAvailablePerses, Status: metav1.ConditionUnknown,
+				Reason: "Reconciling", Message: "Starting reconciliation"})
+		}
+	})
+}
+
+func (r *PersesSecretReconciler) setStatusToComplete(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	// The observed state is the one that was synced, the custom resource may have changed since.
+	synced, ok := secretFromContext(ctx)
+	if !ok {
+		log.Error("secret not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("secret not found in context"))
+	}
+
+	// Nothing was applied in a dry run, so the availability of the secret is left as is.
+	if common.IsDryRun(synced, r.DryRun) {
+		return subreconciler.ContinueReconciling()
+	}
+
+	return r.updateSecretStatus(ctx, req, func(secret *persesv1alpha2.PersesSecret) {
+		meta.RemoveStatusCondition(&secret.Status.Conditions, common.TypeDryRunPerses)
+		meta.SetStatusCondition(&secret.Status.Conditions, metav1.Condition{
+			Type: common.TypeDegradedPerses, Status: metav1.ConditionFalse,
+			Reason: "Reconciled", Message: fmt.Sprintf("Secret (%s) reconciled successfully", secret.Name)})
+		meta.SetStatusCondition(&secret.Status.Conditions, metav1.Condition{
+			Type: common.TypeAvailablePerses, Status: metav1.ConditionTrue,
+			Reason: "Reconciled", Message: fmt.Sprintf("Secret (%s) created successfully", secret.Name)})
+	})
+}
+
+func (r *PersesSecretReconciler) setStatusToDegraded(
+	ctx context.Context,
+	req ctrl.Request,
+	degradedResult *ctrl.Result,
+	degradedReason common.ConditionStatusReason,
+	degradedError error,
+) (*ctrl.Result, error) {
+	msg := "unknown error"
+	if degradedError != nil {
+		msg = degradedError.Error()
+	}
+
+	result, err := r.updateSecretStatus(ctx, req, func(secret *persesv1alpha2.PersesSecret) {
+		meta.SetStatusCondition(&secret.Status.Conditions, metav1.Condition{
+			Type: common.TypeAvailablePerses, Status: metav1.ConditionFalse,
+			Reason: string(degradedReason), Message: msg})
+		meta.SetStatusCondition(&secret.Status.Conditions, metav1.Condition{
+			Type: common.TypeDegradedPerses, Status: metav1.ConditionTrue,
+			Reason: string(degradedReason), Message: msg})
+	})
+
+	if err != nil {
+		return result, err
+	}
+
+	if degradedError != nil {
+		return degradedResult, common.NewReasonError(degradedError, degradedReason)
+	}
+	return degradedResult, nil
+}
+
+// findSecretsForPerses returns reconcile requests for all PersesSecrets
+// across all namespaces when a Perses instance becomes available.
+// Each secret's instanceSelector labels determine which Perses instances it syncs to.
+// If no instanceSelector is set, the secret syncs to all Perses instances.
+func (r *PersesSecretReconciler) findSecretsForPerses(ctx context.Context, _ client.Object) []reconcile.Request {
+	return common.MetadataListToRequests(ctx, r.Client, persesv1alpha2.GroupVersion.WithKind("PersesSecretList"))
+}
+
+// findSecretsForSecret returns reconcile requests for the PersesSecrets whose client
+// configuration reads credentials or certificates from the changed Secret.
+func (r *PersesSecretReconciler) findSecretsForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	return r.findSecretsReferencing(ctx, persesv1alpha2.SecretSourceTypeSecret, obj)
+}
+
+// findSecretsForConfigMap returns reconcile requests for the PersesSecrets whose client
+// configuration reads credentials or certificates from the changed ConfigMap.
+func (r *PersesSecretReconciler) findSecretsForConfigMap(ctx context.Context, obj *metav1.PartialObjectMetadata) []reconcile.Request {
+	return r.findSecretsReferencing(ctx, persesv1alpha2.SecretSourceTypeConfigMap, obj)
+}
+
+func (r *PersesSecretReconciler) findSecretsReferencing(ctx context.Context, sourceType persesv1alpha2.SecretSourceType, obj client.Object) []reconcile.Request {
+	// The cache only holds the metadata of the secrets, the client configuration is read via APIReader.
+	secretList := &persesv1alpha2.PersesSecretList{}
+	if err := r.APIReader.List(ctx, secretList); err != nil {
+		log.WithError(err).Errorf("failed to list PersesSecrets for %s %s/%s", sourceType, obj.GetNamespace(), obj.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, secret := range secretList.Items {
+		if common.ClientReferences(&secret.Spec.Client, secret.Namespace, sourceType, obj) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      secret.Name,
+					Namespace: secret.Namespace,
+				},
+			})
+		}
+	}
+
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// It watches PersesSecret resources and also watches Perses instances
+// to trigger re-reconciliation of all secrets when a Perses instance becomes
+// available. Secrets are matched to Perses instances via instanceSelector labels.
+// Create and delete events for Perses instances are ignored because
+// the instance is not yet ready at creation, and deletion is handled by the secret's
+// own reconciliation loop.
+// It also watches the Secrets and, when ConfigMapCache is set, the ConfigMaps referenced by the
+// client configuration, so that rotated credentials are pushed to Perses. Only the Secrets and
+// ConfigMaps matching the watch label selector of their cache trigger reconciliation.
+func (r *PersesSecretReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&persesv1alpha2.PersesSecret{}, builder.OnlyMetadata).
+		Watches(
+			&persesv1alpha2.Perses{},
+			handler.EnqueueRequestsFromMapFunc(r.findSecretsForPerses),
+			builder.WithPredicates(common.PersesAvailabilityPredicate()),
+		).
+		// WatchesMetadata only caches metadata (not Data) to reduce memory.
+		// The referenced data is read via APIReader when the secret is reconciled.
+		WatchesMetadata(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.findSecretsForSecret),
+		)
+
+	if r.ConfigMapCache != nil {
+		b = b.WatchesRawSource(source.Kind(
+			r.ConfigMapCache,
+			internalcache.ConfigMapMetadata(),
+			handler.TypedEnqueueRequestsFromMapFunc(r.findSecretsForConfigMap),
+		))
+	}
+
+	return b.Complete(r)
+}
@@ -0,0 +1,430 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/perses/common/set"
+	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	internal "github.com/perses/perses-operator/internal/perses"
+	"github.com/perses/perses-operator/internal/perses/common"
+	v1 "github.com/perses/perses/pkg/client/api/v1"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	persesv1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/secret"
+	specdatasource "github.com/perses/spec/go/datasource"
+	specplugin "github.com/perses/spec/go/plugin"
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestSecretController(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Secret Controller Suite")
+}
+
+func newTestSecretReconciler(objects ...runtime.Object) *PersesSecretReconciler {
+	scheme := runtime.NewScheme()
+	Expect(persesv1alpha2.AddToScheme(scheme)).To(Succeed())
+	Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	clientBuilder := fake.NewClientBuilder().WithScheme(scheme)
+	for _, obj := range objects {
+		clientBuilder = clientBuilder.WithRuntimeObjects(obj)
+	}
+	clientBuilder = clientBuilder.WithStatusSubresource(&persesv1alpha2.PersesSecret{})
+
+	c := clientBuilder.Build()
+	return &PersesSecretReconciler{
+		Client:    c,
+		APIReader: c,
+		Scheme:    scheme,
+	}
+}
+
+var _ = Describe("Secret controller", func() {
+	Context("syncPersesSecret", func() {
+		const SecretName = "thanos-credentials"
+		const SecretNamespace = "default"
+
+		credentials := func() *corev1.Secret {
+			return &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "thanos-basic-auth",
+					Namespace: "monitoring",
+				},
+				Data: map[string][]byte{"password": []byte("s3cr3t")},
+			}
+		}
+
+		newSecret := func() *persesv1alpha2.PersesSecret {
+			name := "thanos-basic-auth"
+			namespace := "monitoring"
+			return &persesv1alpha2.PersesSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      SecretName,
+					Namespace: SecretNamespace,
+				},
+				Spec: persesv1alpha2.SecretSpec{
+					Client: persesv1alpha2.Client{
+						BasicAuth: &persesv1alpha2.BasicAuth{
+							SecretSource: persesv1alpha2.SecretSource{
+								Type:      persesv1alpha2.SecretSourceTypeSecret,
+								Name:      &name,
+								Namespace: &namespace,
+							},
+							Username:     "thanos",
+							PasswordPath: "password",
+						},
+					},
+				},
+			}
+		}
+
+		expectedSecret := func() *persesv1.Secret {
+			return &persesv1.Secret{
+				Kind: persesv1.KindSecret,
+				Metadata: persesv1.ProjectMetadata{
+					Metadata: persesv1.Metadata{
+						Name: SecretName,
+						Tags: common.WithManagedTag(nil),
+					},
+				},
+				Spec: persesv1.SecretSpec{
+					BasicAuth: &secret.BasicAuth{
+						Username: "thanos",
+						Password: "s3cr3t",
+					},
+				},
+			}
+		}
+
+		It("should create the secret with the referenced credentials", func() {
+			mockPersesClient := &internal.MockClient{}
+			mockSecret := &internal.MockSecret{}
+			mockPersesClient.On("Secret", SecretNamespace).Return(mockSecret)
+			mockSecret.On("Get", SecretName).Return(&persesv1.Secret{}, perseshttp.RequestNotFoundError)
+			mockSecret.On("Create", expectedSecret()).Return(expectedSecret(), nil)
+
+			r := newTestSecretReconciler(credentials())
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			res, reason, err := r.syncPersesSecret(context.Background(), persesv1alpha2.Perses{}, newSecret())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(BeNil())
+			Expect(reason).To(BeEmpty())
+			mockSecret.AssertExpectations(GinkgoT())
+		})
+
+		It("should claim a secret of the same name created in Perses by hand", func() {
+			handMade := expectedSecret()
+			handMade.Metadata.Tags = nil
+
+			mockPersesClient := &internal.MockClient{}
+			mockSecret := &internal.MockSecret{}
+			mockPersesClient.On("Secret", SecretNamespace).Return(mockSecret)
+			mockSecret.On("Get", SecretName).Return(handMade, nil)
+			mockSecret.On("Update", expectedSecret()).Return(expectedSecret(), nil).Once()
+
+			r := newTestSecretReconciler(credentials())
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+			recorder := record.NewFakeRecorder(10)
+			r.Recorder = recorder
+
+			_, _, err := r.syncPersesSecret(context.Background(), persesv1alpha2.Perses{}, newSecret())
+			Expect(err).ToNot(HaveOccurred())
+			mockSecret.AssertExpectations(GinkgoT())
+			Expect(recorder.Events).To(Receive(ContainSubstring("Secret updated")))
+		})
+
+		It("should only update the secret when its content changes", func() {
+			mockPersesClient := &internal.MockClient{}
+			mockSecret := &internal.MockSecret{}
+			mockPersesClient.On("Secret", SecretNamespace).Return(mockSecret)
+			mockSecret.On("Get", SecretName).Return(expectedSecret(), nil)
+			mockSecret.On("Update", expectedSecret()).Return(expectedSecret(), nil).Once()
+
+			r := newTestSecretReconciler(credentials())
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			By("Pushing the content once")
+			_, _, err := r.syncPersesSecret(context.Background(), persesv1alpha2.Perses{}, newSecret())
+			Expect(err).ToNot(HaveOccurred())
+
+			By("Leaving the secret alone while its content is unchanged")
+			_, _, err = r.syncPersesSecret(context.Background(), persesv1alpha2.Perses{}, newSecret())
+			Expect(err).ToNot(HaveOccurred())
+			mockSecret.AssertExpectations(GinkgoT())
+
+			By("Pushing the rotated credentials")
+			rotated := credentials()
+			rotated.Data["password"] = []byte("r0tat3d")
+			Expect(r.Update(context.Background(), rotated)).To(Succeed())
+			updated := expectedSecret()
+			updated.Spec.BasicAuth.Password = "r0tat3d"
+			mockSecret.On("Update", updated).Return(updated, nil).Once()
+
+			_, _, err = r.syncPersesSecret(context.Background(), persesv1alpha2.Perses{}, newSecret())
+			Expect(err).ToNot(HaveOccurred())
+			mockSecret.AssertExpectations(GinkgoT())
+		})
+
+		It("should report a conflict and leave a hand-made secret alone with the Fail policy", func() {
+			handMade := expectedSecret()
+			handMade.Metadata.Tags = nil
+
+			mockPersesClient := &internal.MockClient{}
+			mockSecret := &internal.MockSecret{}
+			mockPersesClient.On("Secret", SecretNamespace).Return(mockSecret)
+			mockSecret.On("Get", SecretName).Return(handMade, nil)
+
+			r := newTestSecretReconciler(credentials())
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			secret := newSecret()
+			secret.Spec.ConflictPolicy = persesv1alpha2.ConflictPolicyFail
+			_, reason, err := r.syncPersesSecret(context.Background(), persesv1alpha2.Perses{}, secret)
+			Expect(err).To(HaveOccurred())
+			Expect(reason).To(Equal(common.ReasonConflict))
+			mockSecret.AssertNotCalled(GinkgoT(), "Update", mock.Anything)
+		})
+
+		It("should report an invalid configuration when the referenced credentials are missing", func() {
+			mockPersesClient := &internal.MockClient{}
+			mockSecret := &internal.MockSecret{}
+			mockPersesClient.On("Secret", SecretNamespace).Return(mockSecret)
+
+			r := newTestSecretReconciler()
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			_, reason, err := r.syncPersesSecret(context.Background(), persesv1alpha2.Perses{}, newSecret())
+			Expect(err).To(HaveOccurred())
+			Expect(reason).To(Equal(common.ReasonInvalidConfiguration))
+			mockSecret.AssertNotCalled(GinkgoT(), "Create", expectedSecret())
+		})
+	})
+
+	Context("findSecretsForSecret", func() {
+		It("should only enqueue the secrets referencing the changed secret", func() {
+			newSecret := func(name string, sourceType persesv1alpha2.SecretSourceType) *persesv1alpha2.PersesSecret {
+				return &persesv1alpha2.PersesSecret{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "monitoring"},
+					Spec: persesv1alpha2.SecretSpec{
+						Client: persesv1alpha2.Client{
+							BasicAuth: &persesv1alpha2.BasicAuth{
+								SecretSource: persesv1alpha2.SecretSource{
+									Type: sourceType,
+									Name: ptr.To("prometheus-credentials"),
+								},
+							},
+						},
+					},
+				}
+			}
+
+			r := newTestSecretReconciler(
+				newSecret("referencing", persesv1alpha2.SecretSourceTypeSecret),
+				newSecret("other", persesv1alpha2.SecretSourceTypeConfigMap),
+			)
+			changed := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "prometheus-credentials", Namespace: "monitoring"},
+			}
+
+			requests := r.findSecretsForSecret(context.Background(), changed)
+			Expect(requests).To(ConsistOf(reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "referencing", Namespace: "monitoring"},
+			}))
+
+			changedConfigMap := &metav1.PartialObjectMetadata{
+				ObjectMeta: metav1.ObjectMeta{Name: "prometheus-credentials", Namespace: "monitoring"},
+			}
+			requests = r.findSecretsForConfigMap(context.Background(), changedConfigMap)
+			Expect(requests).To(ConsistOf(reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "other", Namespace: "monitoring"},
+			}))
+		})
+	})
+
+	Context("handleDelete", func() {
+		const SecretName = "thanos-credentials"
+		const SecretNamespace = "monitoring"
+
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: SecretName, Namespace: SecretNamespace}}
+
+		newPerses := func(name string, labels map[string]string) *persesv1alpha2.Perses {
+			return &persesv1alpha2.Perses{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "perses-dev", Labels: labels},
+			}
+		}
+
+		deletingSecret := func() *persesv1alpha2.PersesSecret {
+			return &persesv1alpha2.PersesSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              SecretName,
+					Namespace:         SecretNamespace,
+					Finalizers:        []string{common.PersesFinalizer},
+					DeletionTimestamp: &metav1.Time{Time: time.Now()},
+				},
+				Spec: persesv1alpha2.SecretSpec{
+					InstanceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "observability"}},
+				},
+			}
+		}
+
+		persesSecret := func(tags set.Set[string]) *persesv1.Secret {
+			return &persesv1.Secret{
+				Metadata: persesv1.ProjectMetadata{Metadata: persesv1.Metadata{Name: SecretName, Tags: tags}},
+			}
+		}
+
+		It("should only delete the secret from the selected Perses instances", func() {
+			secret := deletingSecret()
+			selectedClient := &internal.MockClient{}
+			otherClient := &internal.MockClient{}
+			mockSecret := &internal.MockSecret{}
+			selectedClient.On("Secret", SecretNamespace).Return(mockSecret)
+			mockSecret.On("Get", SecretName).Return(persesSecret(common.WithManagedTag(nil)), nil)
+			mockSecret.On("Delete", SecretName).Return(nil).Once()
+
+			r := newTestSecretReconciler(secret,
+				newPerses("selected", map[string]string{"team": "observability"}),
+				newPerses("other", nil))
+			r.ClientFactory = instanceClientFactory{"selected": selectedClient, "other": otherClient}
+			recorder := record.NewFakeRecorder(10)
+			r.Recorder = recorder
+
+			_, err := r.handleDelete(withSecret(context.Background(), secret), req)
+			Expect(err).ToNot(HaveOccurred())
+			mockSecret.AssertExpectations(GinkgoT())
+			otherClient.AssertNotCalled(GinkgoT(), "Secret", SecretNamespace)
+			Expect(recorder.Events).To(Receive(ContainSubstring("Perses instance perses-dev/selected: Secret deleted")))
+			Expect(recorder.Events).ToNot(Receive())
+
+			err = r.Get(context.Background(), req.NamespacedName, &persesv1alpha2.PersesSecret{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should release the finalizer when the project no longer exists in Perses", func() {
+			secret := deletingSecret()
+			mockProject := &internal.MockProject{}
+			mockPersesClient := &internal.MockClient{Projects: mockProject}
+			mockProject.On("Get", SecretNamespace).Return(&persesv1.Project{}, perseshttp.RequestNotFoundError)
+
+			r := newTestSecretReconciler(secret, newPerses("perses", map[string]string{"team": "observability"}))
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			_, err := r.handleDelete(withSecret(context.Background(), secret), req)
+			Expect(err).ToNot(HaveOccurred())
+			mockPersesClient.AssertNotCalled(GinkgoT(), "Secret", SecretNamespace)
+
+			err = r.Get(context.Background(), req.NamespacedName, &persesv1alpha2.PersesSecret{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should keep a secret not managed by the operator in Perses", func() {
+			secret := deletingSecret()
+			mockPersesClient := &internal.MockClient{}
+			mockSecret := &internal.MockSecret{}
+			mockPersesClient.On("Secret", SecretNamespace).Return(mockSecret)
+			mockSecret.On("Get", SecretName).Return(persesSecret(nil), nil)
+
+			r := newTestSecretReconciler(secret, newPerses("perses", map[string]string{"team": "observability"}))
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			_, err := r.handleDelete(withSecret(context.Background(), secret), req)
+			Expect(err).ToNot(HaveOccurred())
+			mockSecret.AssertNotCalled(GinkgoT(), "Delete", SecretName)
+		})
+
+		It("should only plan the deletion of the secret in a dry run", func() {
+			secret := deletingSecret()
+			secret.Annotations = map[string]string{common.PersesDryRunAnnotation: common.PersesDryRunEnabled}
+			mockPersesClient := &internal.MockClient{}
+			mockSecret := &internal.MockSecret{}
+			mockPersesClient.On("Secret", SecretNamespace).Return(mockSecret)
+			mockSecret.On("Get", SecretName).Return(persesSecret(common.WithManagedTag(nil)), nil)
+
+			r := newTestSecretReconciler(secret, newPerses("perses", map[string]string{"team": "observability"}))
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+			recorder := record.NewFakeRecorder(10)
+			r.Recorder = recorder
+
+			_, err := r.handleDelete(withSecret(context.Background(), secret), req)
+			Expect(err).ToNot(HaveOccurred())
+			mockSecret.AssertNotCalled(GinkgoT(), "Delete", SecretName)
+			Expect(recorder.Events).To(Receive(ContainSubstring("Dry run: secret would be deleted")))
+
+			err = r.Get(context.Background(), req.NamespacedName, &persesv1alpha2.PersesSecret{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should keep the finalizer while a datasource references the secret", func() {
+			secret := deletingSecret()
+			datasource := &persesv1alpha2.PersesDatasource{
+				ObjectMeta: metav1.ObjectMeta{Name: "thanos", Namespace: SecretNamespace},
+				Spec: persesv1alpha2.DatasourceSpec{
+					Config: persesv1alpha2.Datasource{
+						Spec: specdatasource.Spec{
+							Plugin: specplugin.Plugin{Kind: "PrometheusDatasource", Spec: map[string]interface{}{}},
+						},
+					},
+					SecretRef: &persesv1alpha2.SecretReference{Name: SecretName},
+				},
+			}
+			mockPersesClient := &internal.MockClient{}
+
+			r := newTestSecretReconciler(secret, datasource, newPerses("perses", map[string]string{"team": "observability"}))
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+			recorder := record.NewFakeRecorder(10)
+			r.Recorder = recorder
+
+			_, err := r.handleDelete(withSecret(context.Background(), secret), req)
+			Expect(common.ExtractReason(err, "")).To(Equal(common.ReasonDeletionBlocked))
+			Expect(recorder.Events).To(Receive(ContainSubstring(string(common.ReasonDeletionBlocked))))
+			mockPersesClient.AssertNotCalled(GinkgoT(), "Secret", SecretNamespace)
+
+			fresh := &persesv1alpha2.PersesSecret{}
+			Expect(r.Get(context.Background(), req.NamespacedName, fresh)).To(Succeed())
+			Expect(controllerutil.ContainsFinalizer(fresh, common.PersesFinalizer)).To(BeTrue())
+
+			degraded := apimeta.FindStatusCondition(fresh.Status.Conditions, common.TypeDegradedPerses)
+			Expect(degraded).ToNot(BeNil())
+			Expect(degraded.Message).To(ContainSubstring("thanos"))
+		})
+	})
+})
+
+// instanceClientFactory returns the Perses client of each instance by name.
+type instanceClientFactory map[string]v1.ClientInterface
+
+func (f instanceClientFactory) CreateClient(_ context.Context, _ client.Reader, perses persesv1alpha2.Perses) (v1.ClientInterface, error) {
+	return f[perses.Name], nil
+}
@@ -0,0 +1,314 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/perses/common/set"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	persesv1 "github.com/perses/perses/pkg/model/api/v1"
+
+	logger "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	"github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses-operator/internal/subreconciler"
+)
+
+var seclog = logger.WithField("module", "secret_controller")
+
+func (r *PersesSecretReconciler) reconcileSecretInAllInstances(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	secret, ok := secretFromContext(ctx)
+	if !ok {
+		seclog.Error("secret not found in context")
+		res, err := subreconciler.RequeueWithError(fmt.Errorf("secret not found in context"))
+		return r.setStatusToDegraded(ctx, req, res, common.ReasonMissingResource, err)
+	}
+
+	persesInstances, err := r.listSelectedInstances(ctx, secret)
+	if err != nil {
+		seclog.WithError(err).Error("Failed to get perses instances")
+		res, err := subreconciler.RequeueWithError(err)
+		return r.setStatusToDegraded(ctx, req, res, common.ReasonMissingPerses, err)
+	}
+
+	if len(persesInstances.Items) == 0 {
+		seclog.Info("No Perses instances found, retrying in 1 minute")
+		return r.setStatusToDegraded(ctx, req, &ctrl.Result{RequeueAfter: time.Minute}, common.ReasonMissingPerses, fmt.Errorf("no Perses instances found matching the label selector"))
+	}
+
+	var planned []string
+	for _, persesInstance := range persesInstances.Items {
+		if !meta.IsStatusConditionTrue(persesInstance.Status.Conditions, common.TypeAvailablePerses) {
+			seclog.Infof("Skipping Perses instance %s/%s (not yet available)", persesInstance.Namespace, persesInstance.Name)
+			continue
+		}
+		res, reason, err := r.syncPersesSecret(ctx, persesInstance, secret)
+		if subreconciler.ShouldHaltOrRequeue(res, err) {
+			common.RecordInstanceEvent(r.Recorder, secret, persesInstance, corev1.EventTypeWarning, string(reason), "Failed to sync secret: %v", err)
+			return r.setStatusToDegraded(ctx, req, res, reason, err)
+		}
+		if reason == common.ReasonDryRun {
+			planned = append(planned, fmt.Sprintf("%s/%s", persesInstance.Namespace, persesInstance.Name))
+		}
+	}
+
+	if common.IsDryRun(secret, r.DryRun) {
+		return r.updateSecretStatus(ctx, req, func(secret *persesv1alpha2.PersesSecret) {
+			meta.SetStatusCondition(&secret.Status.Conditions, common.DryRunCondition("Secret", secret.Name, planned))
+		})
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesSecretReconciler) listSelectedInstances(ctx context.Context, secret *persesv1alpha2.PersesSecret) (*persesv1alpha2.PersesList, error) {
+	var labelSelector labels.Selector
+	if secret.Spec.InstanceSelector == nil {
+		labelSelector = labels.Everything()
+	} else {
+		var err error
+		labelSelector, err = metav1.LabelSelectorAsSelector(secret.Spec.InstanceSelector)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	persesInstances := &persesv1alpha2.PersesList{}
+	opts := &client.ListOptions{
+		LabelSelector: labelSelector,
+	}
+	if err := r.List(ctx, persesInstances, opts); err != nil {
+		return nil, err
+	}
+	return persesInstances, nil
+}
+
+func (r *PersesSecretReconciler) syncPersesSecret(ctx context.Context, perses persesv1alpha2.Perses, secret *persesv1alpha2.PersesSecret) (*ctrl.Result, common.ConditionStatusReason, error) {
+	persesClient, err := r.ClientFactory.CreateClient(ctx, r.APIReader, perses)
+	if err != nil {
+		seclog.WithError(err).Error("Failed to create perses rest client")
+		return subreconciler.RequeueWithErrorAndReason(err, common.ReasonConnectionFailed)
+	}
+
+	// A dry run does not create the project, the secret is then planned for creation.
+	dryRun := common.IsDryRun(secret, r.DryRun)
+	if !dryRun {
+		if reason, err := common.EnsureProject(ctx, r.APIReader, persesClient, perses, secret.Namespace); err != nil {
+			return subreconciler.RequeueWithErrorAndReason(err, reason)
+		}
+	}
+
+	secretSpec, reason, err := common.SecretSpecFromClient(ctx, r.APIReader, secret.Namespace, secret.Name, &secret.Spec.Client)
+	if err != nil {
+		return subreconciler.RequeueWithErrorAndReason(err, reason)
+	}
+
+	existing, err := persesClient.Secret(secret.Namespace).Get(secret.Name)
+	notFound := err != nil && errors.Is(err, perseshttp.RequestNotFoundError)
+
+	if err != nil && !notFound {
+		return subreconciler.RequeueWithErrorAndReason(err, common.ReasonBackendError)
+	}
+
+	var existingTags set.Set[string]
+	if !notFound {
+		existingTags = existing.Metadata.Tags
+	}
+	managed, err := common.ClaimOwnership(secret.Spec.ConflictPolicy, "secret", secret.Name, !notFound, existingTags)
+	if err != nil {
+		seclog.WithError(err).Errorf("Secret conflict: %s", secret.Name)
+		return subreconciler.RequeueWithErrorAndReason(err, common.ReasonConflict)
+	}
+
+	var tags set.Set[string]
+	if managed {
+		tags = common.WithManagedTag(nil)
+	}
+
+	persesSecret := &persesv1.Secret{
+		Kind: persesv1.KindSecret,
+		Metadata: persesv1.ProjectMetadata{
+			Metadata: persesv1.Metadata{
+				Name: secret.Name,
+				Tags: tags,
+			},
+		},
+		Spec: secretSpec,
+	}
+
+	secretKey := common.SecretContentKey(perses, secret.Namespace, secret.Name)
+	// A secret adopted by the operator is written again to carry the ManagedTag.
+	if !notFound && r.secrets.InSync(secretKey, secretSpec) && common.IsManaged(existingTags) == managed {
+		seclog.Debugf("Secret already in sync: %s", secret.Name)
+		res, err := subreconciler.ContinueReconciling()
+		return res, "", err
+	}
+
+	if dryRun {
+		plan := "created"
+		if !notFound {
+			plan = "updated"
+		}
+		seclog.Infof("Dry run, secret %s would be %s in Perses instance %s/%s", secret.Name, plan, perses.Namespace, perses.Name)
+		common.RecordInstanceEvent(r.Recorder, secret, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: secret would be %s", plan)
+		res, err := subreconciler.ContinueReconciling()
+		return res, common.ReasonDryRun, err
+	}
+
+	if notFound {
+		_, err = persesClient.Secret(secret.Namespace).Create(persesSecret)
+		if err != nil {
+			seclog.WithError(err).Errorf("Failed to create secret: %s", secret.Name)
+			return subreconciler.RequeueWithErrorAndReason(err, common.ReasonBackendError)
+		}
+		r.secrets.Record(secretKey, secretSpec)
+		seclog.Infof("Secret created: %s", secret.Name)
+		common.RecordInstanceEvent(r.Recorder, secret, perses, corev1.EventTypeNormal, common.EventReasonCreated, "Secret created")
+	} else {
+		_, err = persesClient.Secret(secret.Namespace).Update(persesSecret)
+		if err != nil {
+			seclog.WithError(err).Errorf("Failed to update secret: %s", secret.Name)
+			return subreconciler.RequeueWithErrorAndReason(err, common.ReasonBackendError)
+		}
+		r.secrets.Record(secretKey, secretSpec)
+		seclog.Infof("Secret updated: %s", secret.Name)
+		common.RecordInstanceEvent(r.Recorder, secret, perses, corev1.EventTypeNormal, common.EventReasonUpdated, "Secret updated")
+	}
+
+	res, err := subreconciler.ContinueReconciling()
+	return res, "", err
+}
+
+// deleteSecretInAllInstances removes the secret from every Perses instance selected by the
+// PersesSecret. The deletion is blocked while a PersesDatasource still references the secret.
+func (r *PersesSecretReconciler) deleteSecretInAllInstances(ctx context.Context, req ctrl.Request, secret *persesv1alpha2.PersesSecret) (*ctrl.Result, error) {
+	referencing, err := r.datasourcesReferencing(ctx, secret)
+	if err != nil {
+		seclog.WithError(err).Error("Failed to list perses datasources")
+		return subreconciler.RequeueWithError(err)
+	}
+	if len(referencing) > 0 {
+		seclog.Infof("Secret %s/%s is still referenced by datasources, deletion is blocked", secret.Namespace, secret.Name)
+		common.RecordEvent(r.Recorder, secret, corev1.EventTypeWarning, string(common.ReasonDeletionBlocked), "Secret is still referenced by datasources: %s", strings.Join(referencing, ", "))
+		return r.setStatusToDegraded(ctx, req, &ctrl.Result{RequeueAfter: time.Minute}, common.ReasonDeletionBlocked,
+			fmt.Errorf("secret is still referenced by PersesDatasources: %s", strings.Join(referencing, ", ")))
+	}
+
+	persesInstances, err := r.listSelectedInstances(ctx, secret)
+	if err != nil {
+		seclog.WithError(err).Error("Failed to get perses instances")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	for _, persesInstance := range persesInstances.Items {
+		if r, err := r.deleteSecret(ctx, persesInstance, secret); subreconciler.ShouldHaltOrRequeue(r, err) {
+			return r, err
+		}
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+// datasourcesReferencing returns the PersesDatasources of the namespace of the secret
+// that reference it through their secretRef.
+func (r *PersesSecretReconciler) datasourcesReferencing(ctx context.Context, secret *persesv1alpha2.PersesSecret) ([]string, error) {
+	datasources := &persesv1alpha2.PersesDatasourceList{}
+	if err := r.APIReader.List(ctx, datasources, client.InNamespace(secret.Namespace)); err != nil {
+		return nil, err
+	}
+
+	var referencing []string
+	for _, datasource := range datasources.Items {
+		if datasource.Spec.SecretRef != nil && datasource.Spec.SecretRef.Name == secret.Name {
+			referencing = append(referencing, datasource.Name)
+		}
+	}
+	return referencing, nil
+}
+
+func (r *PersesSecretReconciler) deleteSecret(ctx context.Context, perses persesv1alpha2.Perses, secret *persesv1alpha2.PersesSecret) (*ctrl.Result, error) {
+	secretNamespace, secretName := secret.Namespace, secret.Name
+	secretKey := common.SecretContentKey(perses, secretNamespace, secretName)
+	persesClient, err := r.ClientFactory.CreateClient(ctx, r.APIReader, perses)
+	if err != nil {
+		seclog.WithError(err).Error("Failed to create perses rest client")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	_, err = persesClient.Project().Get(secretNamespace)
+	if err != nil {
+		// The secret went away with its project.
+		if errors.Is(err, perseshttp.RequestNotFoundError) {
+			seclog.Infof("Project not found: %s", secretNamespace)
+			r.secrets.Forget(secretKey)
+			return subreconciler.ContinueReconciling()
+		}
+		seclog.WithError(err).Errorf("project error: %s", secretNamespace)
+
+		return subreconciler.RequeueWithError(err)
+	}
+
+	// Ignore NotFound — the resource may have already been deleted from Perses directly.
+	// Any other error means the delete failed and should be retried.
+	existing, err := persesClient.Secret(secretNamespace).Get(secretName)
+
+	switch {
+	case err != nil && errors.Is(err, perseshttp.RequestNotFoundError):
+		seclog.Infof("Secret not found: %s", secretName)
+		r.secrets.Forget(secretKey)
+		return subreconciler.ContinueReconciling()
+	case err != nil:
+		seclog.WithError(err).Errorf("Failed to get secret: %s", secretName)
+		return subreconciler.RequeueWithError(err)
+	case !common.IsManaged(existing.Metadata.Tags):
+		seclog.Infof("Secret not managed by the operator, keeping it: %s", secretName)
+		r.secrets.Forget(secretKey)
+		return subreconciler.ContinueReconciling()
+	}
+
+	if common.IsDryRun(secret, r.DryRun) {
+		seclog.Infof("Dry run, secret %s would be deleted from Perses instance %s/%s", secretName, perses.Namespace, perses.Name)
+		common.RecordInstanceEvent(r.Recorder, secret, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: secret would be deleted")
+		return subreconciler.ContinueReconciling()
+	}
+
+	err = persesClient.Secret(secretNamespace).Delete(secretName)
+	if err != nil && !errors.Is(err, perseshttp.RequestNotFoundError) {
+		seclog.WithError(err).Errorf("Failed to delete secret: %s", secretName)
+		common.RecordInstanceEvent(r.Recorder, secret, perses, corev1.EventTypeWarning, string(common.ReasonBackendError), "Failed to delete secret: %v", err)
+		return subreconciler.RequeueWithError(err)
+	}
+
+	r.secrets.Forget(secretKey)
+	seclog.Infof("Secret deleted: %s", secretName)
+	common.RecordInstanceEvent(r.Recorder, secret, perses, corev1.EventTypeNormal, common.EventReasonDeleted, "Secret deleted")
+
+	if err := common.CleanupProject(ctx, r.APIReader, persesClient, perses, secretNamespace); err != nil {
+		seclog.WithError(err).Errorf("Failed to clean up project: %s", secretNamespace)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
@@ -94,7 +94,7 @@ var _ = BeforeSuite(func() {
 		Metrics: metricsserver.Options{
 			BindAddress: "0",
 		},
-		Cache: internalcache.BuildCacheOptions(nil, false, false),
+		Cache: internalcache.BuildCacheOptions(nil, false, false, operator.APIs{}),
 	})
 	Expect(err).ToNot(HaveOccurred())
 
@@ -104,6 +104,8 @@ var _ = BeforeSuite(func() {
 		Scheme:    k8sManager.GetScheme(),
 		Config: persesController.Config{
 			PersesImage: operator.DefaultPersesImage,
+			// envtest runs no workload controller, so the rollouts never complete.
+			SkipAvailabilityChecks: true,
 		},
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
@@ -0,0 +1,354 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package variables
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	logger "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	operatormetrics "github.com/perses/perses-operator/internal/metrics"
+	"github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses-operator/internal/subreconciler"
+)
+
+type variableContextKey string
+
+const contextKey variableContextKey = "variable"
+
+func withVariable(ctx context.Context, v *persesv1alpha2.PersesVariable) context.Context {
+	return context.WithValue(ctx, contextKey, v)
+}
+
+func variableFromContext(ctx context.Context) (*persesv1alpha2.PersesVariable, bool) {
+	v, ok := ctx.Value(contextKey).(*persesv1alpha2.PersesVariable)
+	return v, ok
+}
+
+// PersesVariableReconciler reconciles a PersesVariable object
+type PersesVariableReconciler struct {
+	client.Client
+	APIReader             client.Reader // uncached reader — OnlyMetadata watch caches metadata only
+	Scheme                *runtime.Scheme
+	Recorder              record.EventRecorder
+	ClientFactory         common.PersesClientFactory
+	Metrics               *operatormetrics.Metrics
+	ReconciliationTracker *operatormetrics.ReconciliationTracker
+	// InstanceSyncConcurrency is the maximum number of Perses instances the variable
+	// is synced to at the same time. Lower than 1 syncs one instance at a time.
+	InstanceSyncConcurrency int
+	// DryRun only plans the changes to the variables in Perses, without applying them,
+	// as for the variables with the perses.dev/dry-run annotation.
+	DryRun bool
+}
+
+var log = logger.WithField("module", "perses_variables_controller")
+
+// +kubebuilder:rbac:groups=perses.dev,resources=persesvariables,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=perses.dev,resources=persesvariables/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=perses.dev,resources=persesvariables/finalizers,verbs=update
+func (r *PersesVariableReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	start := time.Now()
+	objKey := req.String()
+
+	if r.Metrics != nil {
+		r.Metrics.ReconcileOperations("persesvariable").Inc()
+	}
+
+	log.Infof("Reconciling PersesVariable: %s/%s", req.Namespace, req.Name)
+
+	// Find once and store in context for all sub-reconcilers.
+	// Deletion is handled through the finalizer, so a missing object has nothing left to clean up.
+	variable := &persesv1alpha2.PersesVariable{}
+	if err := r.APIReader.Get(ctx, req.NamespacedName, variable); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Infof("perses variable resource not found. Ignoring since object must be deleted")
+			if r.ReconciliationTracker != nil {
+				r.ReconciliationTracker.ForgetObject(objKey)
+			}
+			if r.Metrics != nil {
+				r.Metrics.ForgetObject(objKey)
+			}
+			return subreconciler.Evaluate(subreconciler.DoNotRequeue())
+		}
+		log.WithError(err).Error("Failed to get perses variable")
+		if r.Metrics != nil {
+			r.Metrics.ReconcileErrors("persesvariable", "get_failed").Inc()
+		}
+		return subreconciler.Evaluate(subreconciler.RequeueWithError(err))
+	}
+
+	ctx = withVariable(ctx, variable)
+
+	subreconcilersForPerses := []subreconciler.FnWithRequest{
+		r.handleDelete,
+		r.addFinalizer,
+		r.setStatusToUnknown,
+		r.reconcileVariableInAllInstances,
+		r.setStatusToComplete,
+	}
+
+	var reconcileErr error
+	for _, f := range subreconcilersForPerses {
+		if r, err := f(ctx, req); subreconciler.ShouldHaltOrRequeue(r, err) {
+			reconcileErr = err
+			break
+		}
+	}
+
+	// Track reconciliation status
+	if r.ReconciliationTracker != nil {
+		r.ReconciliationTracker.SetStatus(objKey, reconcileErr)
+		if reconcileErr == nil {
+			r.ReconciliationTracker.SetReasonAndMessage(objKey, "ReconciliationSuccessful", "Variable reconciled successfully")
+		}
+	}
+
+	// Track metrics
+	if r.Metrics != nil {
+		if reconcileErr != nil {
+			reason := string(common.ExtractReason(reconcileErr, "reconciliation_failed"))
+			r.Metrics.ReconcileErrors("persesvariable", reason).Inc()
+			r.Metrics.SetFailedResources(objKey, "variable", req.Namespace, 1)
+		} else {
+			r.Metrics.SetSyncedResources(objKey, "variable", req.Namespace, 1)
+		}
+	}
+
+	if reconcileErr != nil {
+		return subreconciler.Evaluate(subreconciler.RequeueWithError(reconcileErr))
+	}
+
+	log.WithField("duration", time.Since(start)).Debug("variable reconciliation completed")
+	return subreconciler.Evaluate(subreconciler.DoNotRequeue())
+}
+
+// handleDelete removes the variable from the selected Perses instances, then releases the finalizer.
+func (r *PersesVariableReconciler) handleDelete(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	variable, ok := variableFromContext(ctx)
+	if !ok {
+		log.Error("variable not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("variable not found in context"))
+	}
+
+	if variable.GetDeletionTimestamp() == nil {
+		return subreconciler.ContinueReconciling()
+	}
+
+	if !controllerutil.ContainsFinalizer(variable, common.PersesFinalizer) {
+		return subreconciler.DoNotRequeue()
+	}
+
+	if res, err := r.deleteVariableInAllInstances(ctx, req, variable); subreconciler.ShouldHaltOrRequeue(res, err) {
+		return res, err
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesVariable{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		if !controllerutil.RemoveFinalizer(fresh, common.PersesFinalizer) {
+			return nil
+		}
+		return r.Update(ctx, fresh)
+	})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to remove finalizer from perses variable")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	log.Infof("PersesVariable %s/%s deleted", variable.Namespace, variable.Name)
+	return subreconciler.DoNotRequeue()
+}
+
+// addFinalizer makes sure the variable is not removed from the cluster before
+// it has been removed from the Perses instances it was synced to.
+func (r *PersesVariableReconciler) addFinalizer(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	variable, ok := variableFromContext(ctx)
+	if !ok {
+		log.Error("variable not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("variable not found in context"))
+	}
+
+	if controllerutil.ContainsFinalizer(variable, common.PersesFinalizer) {
+		return subreconciler.ContinueReconciling()
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesVariable{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		if !controllerutil.AddFinalizer(fresh, common.PersesFinalizer) {
+			return nil
+		}
+		return r.Update(ctx, fresh)
+	})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to add finalizer to perses variable")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesVariableReconciler) updateVariableStatus(
+	ctx context.Context,
+	req ctrl.Request,
+	updateFn func(*persesv1alpha2.PersesVariable),
+) (*ctrl.Result, error) {
+	_, ok := variableFromContext(ctx)
+	if !ok {
+		log.Error("variable not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("variable not found in context"))
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &persesv1alpha2.PersesVariable{}
+		if err := r.APIReader.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		before := common.SnapshotConditions(fresh.Status.Conditions)
+		updateFn(fresh)
+		if !common.ConditionsChanged(before, fresh.Status.Conditions) {
+			return nil
+		}
+		return r.Status().Update(ctx, fresh)
+	})
+
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("perses variable resource not found. Ignoring since object must be deleted")
+			return subreconciler.DoNotRequeue()
+		}
+		log.WithError(err).Error("Failed to update Perses variable status")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesVariableReconciler) setStatusToUnknown(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	return r.updateVariableStatus(ctx, req, func(variable *persesv1alpha2.PersesVariable) {
+		if len(variable.Status.Conditions) == 0 {
+			meta.SetStatusCondition(&variable.Status.Conditions, metav1.Condition{
+				Type: common.TypeAvailablePerses, Status: metav1.ConditionUnknown,
+				Reason: "Reconciling", Message: "Starting reconciliation"})
+		}
+	})
+}
+
+func (r *PersesVariableReconciler) setStatusToComplete(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	// The observed state is the one that was synced, the custom resource may have changed since.
+	synced, ok := variableFromContext(ctx)
+	if !ok {
+		log.Error("variable not found in context")
+		return subreconciler.RequeueWithError(fmt.Errorf("variable not found in context"))
+	}
+
+	// Nothing was applied in a dry run, so the availability of the variable is left as is.
+	if common.IsDryRun(synced, r.DryRun) {
+		return subreconciler.ContinueReconciling()
+	}
+
+	return r.updateVariableStatus(ctx, req, func(variable *persesv1alpha2.PersesVariable) {
+		meta.RemoveStatusCondition(&variable.Status.Conditions, common.TypeDryRunPerses)
+		meta.SetStatusCondition(&variable.Status.Conditions, metav1.Condition{
+			Type: common.TypeDegradedPerses, Status: metav1.ConditionFalse,
+			Reason: "Reconciled", Message: fmt.Sprintf("Variable (%s) reconciled successfully", variable.Name)})
+		meta.SetStatusCondition(&variable.Status.Conditions, metav1.Condition{
+			Type: common.TypeAvailablePerses, Status: metav1.ConditionTrue,
+			Reason: "Reconciled", Message: fmt.Sprintf("Variable (%s) created successfully", variable.Name)})
+	})
+}
+
+func (r *PersesVariableReconciler) setStatusToDegraded(
+	ctx context.Context,
+	req ctrl.Request,
+	degradedResult *ctrl.Result,
+	degradedReason common.ConditionStatusReason,
+	degradedError error,
+) (*ctrl.Result, error) {
+	msg := "unknown error"
+	if degradedError != nil {
+		msg = degradedError.Error()
+	}
+
+	result, err := r.updateVariableStatus(ctx, req, func(variable *persesv1alpha2.PersesVariable) {
+		meta.SetStatusCondition(&variable.Status.Conditions, metav1.Condition{
+			Type: common.TypeAvailablePerses, Status: metav1.ConditionFalse,
+			Reason: string(degradedReason), Message: msg})
+		meta.SetStatusCondition(&variable.Status.Conditions, metav1.Condition{
+			Type: common.TypeDegradedPerses, Status: metav1.ConditionTrue,
+			Reason: string(degradedReason), Message: msg})
+	})
+
+	if err != nil {
+		return result, err
+	}
+
+	if degradedError != nil {
+		return degradedResult, common.NewReasonError(degradedError, degradedReason)
+	}
+	return degradedResult, nil
+}
+
+// findVariablesForPerses returns reconcile requests for all PersesVariables
+// across all namespaces when a Perses instance becomes available.
+// Each variable's instanceSelector labels determine which Perses instances it syncs to.
+// If no instanceSelector is set, the variable syncs to all Perses instances.
+func (r *PersesVariableReconciler) findVariablesForPerses(ctx context.Context, _ client.Object) []reconcile.Request {
+	return common.MetadataListToRequests(ctx, r.Client, persesv1alpha2.GroupVersion.WithKind("PersesVariableList"))
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// It watches PersesVariable resources and also watches Perses instances
+// to trigger re-reconciliation of all variables when a Perses instance becomes
+// available. Variables are matched to Perses instances via instanceSelector labels.
+// Create and delete events for Perses instances are ignored because
+// the instance is not yet ready at creation, and deletion is handled by the variable's
+// own reconciliation loop.
+func (r *PersesVariableReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&persesv1alpha2.PersesVariable{}, builder.OnlyMetadata).
+		Watches(
+			&persesv1alpha2.Perses{},
+			handler.EnqueueRequestsFromMapFunc(r.findVariablesForPerses),
+			builder.WithPredicates(common.PersesAvailabilityPredicate()),
+		).
+		Complete(r)
+}
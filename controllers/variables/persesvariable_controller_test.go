@@ -0,0 +1,405 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package variables
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/perses/common/set"
+	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	internal "github.com/perses/perses-operator/internal/perses"
+	"github.com/perses/perses-operator/internal/perses/common"
+	persesclient "github.com/perses/perses/pkg/client/api/v1"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	persesv1 "github.com/perses/perses/pkg/model/api/v1"
+	specvariable "github.com/perses/spec/go/dashboard/variable"
+	"github.com/stretchr/testify/mock"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func TestVariableController(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Variable Controller Suite")
+}
+
+func newTestVariableReconciler(objects ...runtime.Object) *PersesVariableReconciler {
+	scheme := runtime.NewScheme()
+	Expect(persesv1alpha2.AddToScheme(scheme)).To(Succeed())
+
+	clientBuilder := fake.NewClientBuilder().WithScheme(scheme)
+	for _, obj := range objects {
+		clientBuilder = clientBuilder.WithRuntimeObjects(obj)
+	}
+	clientBuilder = clientBuilder.WithStatusSubresource(&persesv1alpha2.PersesVariable{}).
+		WithTypeConverters(internal.NewUnstructuredTypeConverter())
+
+	c := clientBuilder.Build()
+	return &PersesVariableReconciler{
+		Client:    c,
+		APIReader: c,
+		Scheme:    scheme,
+	}
+}
+
+var _ = Describe("Variable controller", func() {
+	const VariableName = "region"
+	const VariableNamespace = "default"
+
+	newPerses := func(name string) *persesv1alpha2.Perses {
+		return &persesv1alpha2.Perses{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "perses-dev"},
+		}
+	}
+
+	Context("syncPersesVariable", func() {
+		newVariable := func() *persesv1alpha2.PersesVariable {
+			return &persesv1alpha2.PersesVariable{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        VariableName,
+					Namespace:   VariableNamespace,
+					Annotations: map[string]string{common.TagsAnnotation: "infra"},
+				},
+				Spec: persesv1alpha2.VariableSpec{
+					Config: persesv1alpha2.Variable{
+						VariableSpec: persesv1.VariableSpec{
+							Kind: specvariable.KindText,
+							Spec: &specvariable.TextSpec{Value: "eu-west-1"},
+						},
+					},
+				},
+			}
+		}
+
+		expectedVariable := func() *persesv1.Variable {
+			v := newVariable()
+			return &persesv1.Variable{
+				Kind: persesv1.KindVariable,
+				Metadata: persesv1.ProjectMetadata{
+					Metadata: persesv1.Metadata{
+						Name: VariableName,
+						Tags: common.WithManagedTag(common.ParseTags(v.Annotations)),
+					},
+				},
+				Spec: v.Spec.Config.VariableSpec,
+			}
+		}
+
+		It("should create the project of the namespace before the variable when it is missing", func() {
+			mockPersesClient, validateServer := internal.NewMockClientWithValidation()
+			defer validateServer.Close()
+			mockProject := &internal.MockProject{}
+			mockPersesClient.Projects = mockProject
+			mockProject.On("Get", VariableNamespace).Return(&persesv1.Project{}, perseshttp.RequestNotFoundError)
+			project := common.DesiredProject(VariableNamespace, nil)
+			project.Metadata.Tags = common.WithManagedTag(nil)
+			mockProject.On("Create", project).Return(project, nil).Once()
+			mockVariable := &internal.MockVariable{}
+			mockPersesClient.On("Variable", VariableNamespace).Return(mockVariable)
+			mockVariable.On("Get", VariableName).Return(&persesv1.Variable{}, perseshttp.RequestNotFoundError)
+			mockVariable.On("Create", expectedVariable()).Return(expectedVariable(), nil).Once()
+
+			r := newTestVariableReconciler()
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			_, reason, err := r.syncPersesVariable(context.Background(), *newPerses("perses"), newVariable())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reason).To(BeEmpty())
+			mockProject.AssertExpectations(GinkgoT())
+			mockVariable.AssertExpectations(GinkgoT())
+		})
+
+		It("should claim a variable of the same name created in Perses by hand", func() {
+			mockPersesClient, validateServer := internal.NewMockClientWithValidation()
+			defer validateServer.Close()
+			mockVariable := &internal.MockVariable{}
+			mockPersesClient.On("Variable", VariableNamespace).Return(mockVariable)
+			existing := expectedVariable()
+			existing.Metadata.Tags = nil
+			existing.Spec.Spec = &specvariable.TextSpec{Value: "us-east-1"}
+			mockVariable.On("Get", VariableName).Return(existing, nil)
+			mockVariable.On("Update", expectedVariable()).Return(expectedVariable(), nil).Once()
+
+			recorder := record.NewFakeRecorder(10)
+			r := newTestVariableReconciler()
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+			r.Recorder = recorder
+
+			_, _, err := r.syncPersesVariable(context.Background(), *newPerses("perses"), newVariable())
+			Expect(err).ToNot(HaveOccurred())
+			mockVariable.AssertExpectations(GinkgoT())
+			Expect(recorder.Events).To(Receive(And(
+				ContainSubstring(common.EventReasonUpdated),
+				ContainSubstring(common.ManagedTag),
+				ContainSubstring(`"path":"spec.spec.value","old":"us-east-1","new":"eu-west-1"`),
+			)))
+		})
+
+		It("should report a conflict and leave a variable not managed by the operator untouched", func() {
+			mockPersesClient := &internal.MockClient{}
+			mockVariable := &internal.MockVariable{}
+			mockPersesClient.On("Variable", VariableNamespace).Return(mockVariable)
+			existing := expectedVariable()
+			existing.Metadata.Tags = set.New("infra")
+			mockVariable.On("Get", VariableName).Return(existing, nil)
+
+			r := newTestVariableReconciler()
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			variable := newVariable()
+			variable.Spec.ConflictPolicy = persesv1alpha2.ConflictPolicyFail
+			_, reason, err := r.syncPersesVariable(context.Background(), *newPerses("perses"), variable)
+			Expect(err).To(HaveOccurred())
+			Expect(reason).To(Equal(common.ReasonConflict))
+			mockVariable.AssertNotCalled(GinkgoT(), "Update", mock.Anything)
+		})
+
+		It("should overwrite a variable not managed by the operator without taking ownership of it", func() {
+			mockPersesClient, validateServer := internal.NewMockClientWithValidation()
+			defer validateServer.Close()
+			mockVariable := &internal.MockVariable{}
+			mockPersesClient.On("Variable", VariableNamespace).Return(mockVariable)
+			existing := expectedVariable()
+			existing.Metadata.Tags = nil
+			mockVariable.On("Get", VariableName).Return(existing, nil)
+			mockVariable.On("Update", mock.MatchedBy(func(variable *persesv1.Variable) bool {
+				return !common.IsManaged(variable.Metadata.Tags)
+			})).Return(&persesv1.Variable{}, nil).Once()
+
+			r := newTestVariableReconciler()
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			variable := newVariable()
+			variable.Spec.ConflictPolicy = persesv1alpha2.ConflictPolicyOverwrite
+			_, _, err := r.syncPersesVariable(context.Background(), *newPerses("perses"), variable)
+			Expect(err).ToNot(HaveOccurred())
+			mockVariable.AssertExpectations(GinkgoT())
+		})
+
+		It("should only plan the creation of a variable in a dry run", func() {
+			mockPersesClient, validateServer := internal.NewMockClientWithValidation()
+			defer validateServer.Close()
+			mockProject := &internal.MockProject{}
+			mockPersesClient.Projects = mockProject
+			mockVariable := &internal.MockVariable{}
+			mockPersesClient.On("Variable", VariableNamespace).Return(mockVariable)
+			mockVariable.On("Get", VariableName).Return(&persesv1.Variable{}, perseshttp.RequestNotFoundError)
+
+			recorder := record.NewFakeRecorder(10)
+			r := newTestVariableReconciler()
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+			r.Recorder = recorder
+			r.DryRun = true
+
+			_, reason, err := r.syncPersesVariable(context.Background(), *newPerses("perses"), newVariable())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reason).To(Equal(common.ReasonDryRun))
+			mockProject.AssertNotCalled(GinkgoT(), "Get", VariableNamespace)
+			mockVariable.AssertNotCalled(GinkgoT(), "Create", expectedVariable())
+			Expect(recorder.Events).To(Receive(ContainSubstring("Dry run: variable would be created")))
+		})
+	})
+
+	Context("reconcileVariableInAllInstances", func() {
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: VariableName, Namespace: VariableNamespace}}
+
+		It("should sync the variable to the healthy Perses instances when another one fails", func() {
+			mockPersesClient, validateServer := internal.NewMockClientWithValidation()
+			defer validateServer.Close()
+			mockVariable := &internal.MockVariable{}
+			mockPersesClient.On("Variable", VariableNamespace).Return(mockVariable)
+			mockVariable.On("Get", VariableName).Return(&persesv1.Variable{}, perseshttp.RequestNotFoundError)
+			mockVariable.On("Create", mock.Anything).Return(&persesv1.Variable{}, nil)
+
+			variable := &persesv1alpha2.PersesVariable{
+				ObjectMeta: metav1.ObjectMeta{Name: VariableName, Namespace: VariableNamespace},
+				Spec: persesv1alpha2.VariableSpec{
+					Config: persesv1alpha2.Variable{
+						VariableSpec: persesv1.VariableSpec{
+							Kind: specvariable.KindText,
+							Spec: &specvariable.TextSpec{Value: "eu-west-1"},
+						},
+					},
+				},
+			}
+			r := newTestVariableReconciler(variable, availablePerses("perses-a", nil), availablePerses("perses-b", nil), availablePerses("perses-c", nil))
+			r.ClientFactory = instanceClientFactory{"perses-a": mockPersesClient, "perses-c": mockPersesClient}
+			r.InstanceSyncConcurrency = 2
+			r.Recorder = record.NewFakeRecorder(10)
+
+			_, err := r.reconcileVariableInAllInstances(withVariable(context.Background(), variable), req)
+			Expect(err).To(HaveOccurred())
+			Expect(common.ExtractReason(err, "")).To(Equal(common.ReasonConnectionFailed))
+			mockVariable.AssertNumberOfCalls(GinkgoT(), "Create", 2)
+		})
+	})
+
+	Context("handleDelete", func() {
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: VariableName, Namespace: VariableNamespace}}
+
+		deletingVariable := func() *persesv1alpha2.PersesVariable {
+			return &persesv1alpha2.PersesVariable{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              VariableName,
+					Namespace:         VariableNamespace,
+					Finalizers:        []string{common.PersesFinalizer},
+					DeletionTimestamp: &metav1.Time{Time: time.Now()},
+				},
+				Spec: persesv1alpha2.VariableSpec{
+					Config: persesv1alpha2.Variable{
+						VariableSpec: persesv1.VariableSpec{
+							Kind: specvariable.KindText,
+							Spec: &specvariable.TextSpec{Value: "eu-west-1"},
+						},
+					},
+					InstanceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "observability"}},
+				},
+			}
+		}
+
+		persesVariable := func(tags set.Set[string]) *persesv1.Variable {
+			return &persesv1.Variable{
+				Metadata: persesv1.ProjectMetadata{Metadata: persesv1.Metadata{Name: VariableName, Tags: tags}},
+			}
+		}
+
+		selected := map[string]string{"team": "observability"}
+
+		It("should only delete the variable from the selected Perses instances", func() {
+			variable := deletingVariable()
+			selectedClient := &internal.MockClient{}
+			otherClient := &internal.MockClient{}
+			mockVariable := &internal.MockVariable{}
+			selectedClient.On("Variable", VariableNamespace).Return(mockVariable)
+			mockVariable.On("Get", VariableName).Return(persesVariable(common.WithManagedTag(nil)), nil)
+			mockVariable.On("Delete", VariableName).Return(nil).Once()
+
+			recorder := record.NewFakeRecorder(10)
+			r := newTestVariableReconciler(variable, availablePerses("selected", selected), availablePerses("other", nil))
+			r.ClientFactory = instanceClientFactory{"selected": selectedClient, "other": otherClient}
+			r.Recorder = recorder
+
+			_, err := r.handleDelete(withVariable(context.Background(), variable), req)
+			Expect(err).ToNot(HaveOccurred())
+			mockVariable.AssertExpectations(GinkgoT())
+			otherClient.AssertNotCalled(GinkgoT(), "Variable", VariableNamespace)
+			Expect(recorder.Events).To(Receive(ContainSubstring("Perses instance perses-dev/selected: Variable deleted")))
+
+			err = r.Get(context.Background(), req.NamespacedName, &persesv1alpha2.PersesVariable{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should keep a variable not managed by the operator in Perses", func() {
+			variable := deletingVariable()
+			mockPersesClient := &internal.MockClient{}
+			mockVariable := &internal.MockVariable{}
+			mockPersesClient.On("Variable", VariableNamespace).Return(mockVariable)
+			mockVariable.On("Get", VariableName).Return(persesVariable(nil), nil)
+
+			r := newTestVariableReconciler(variable, availablePerses("perses", selected))
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			_, err := r.handleDelete(withVariable(context.Background(), variable), req)
+			Expect(err).ToNot(HaveOccurred())
+			mockVariable.AssertNotCalled(GinkgoT(), "Delete", VariableName)
+
+			err = r.Get(context.Background(), req.NamespacedName, &persesv1alpha2.PersesVariable{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should ignore a variable already deleted from Perses", func() {
+			variable := deletingVariable()
+			mockPersesClient := &internal.MockClient{}
+			mockVariable := &internal.MockVariable{}
+			mockPersesClient.On("Variable", VariableNamespace).Return(mockVariable)
+			mockVariable.On("Get", VariableName).Return(&persesv1.Variable{}, perseshttp.RequestNotFoundError)
+
+			r := newTestVariableReconciler(variable, availablePerses("perses", selected))
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			res, err := r.handleDelete(withVariable(context.Background(), variable), req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(&ctrl.Result{}))
+			mockVariable.AssertNotCalled(GinkgoT(), "Delete", VariableName)
+		})
+
+		It("should not delete anything when the project no longer exists in Perses", func() {
+			variable := deletingVariable()
+			mockProject := &internal.MockProject{}
+			mockPersesClient := &internal.MockClient{Projects: mockProject}
+			mockProject.On("Get", VariableNamespace).Return(&persesv1.Project{}, perseshttp.RequestNotFoundError)
+
+			r := newTestVariableReconciler(variable, availablePerses("perses", selected))
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+
+			res, err := r.handleDelete(withVariable(context.Background(), variable), req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(&ctrl.Result{}))
+			mockPersesClient.AssertNotCalled(GinkgoT(), "Variable", VariableNamespace)
+		})
+
+		It("should keep the finalizer while a selected Perses instance is not available", func() {
+			variable := deletingVariable()
+			mockPersesClient := &internal.MockClient{}
+			unavailable := newPerses("perses")
+			unavailable.Labels = selected
+
+			recorder := record.NewFakeRecorder(10)
+			r := newTestVariableReconciler(variable, unavailable)
+			r.ClientFactory = common.NewWithClient(mockPersesClient)
+			r.Recorder = recorder
+
+			res, err := r.handleDelete(withVariable(context.Background(), variable), req)
+			Expect(common.ExtractReason(err, "")).To(Equal(common.ReasonDeletionBlocked))
+			Expect(res.RequeueAfter).To(Equal(time.Minute))
+			Expect(recorder.Events).To(Receive(ContainSubstring("Variable deletion is waiting for the instance to be available")))
+			mockPersesClient.AssertNotCalled(GinkgoT(), "Variable", VariableNamespace)
+
+			fresh := &persesv1alpha2.PersesVariable{}
+			Expect(r.Get(context.Background(), req.NamespacedName, fresh)).To(Succeed())
+			Expect(controllerutil.ContainsFinalizer(fresh, common.PersesFinalizer)).To(BeTrue())
+		})
+	})
+})
+
+func availablePerses(name string, labels map[string]string) *persesv1alpha2.Perses {
+	return &persesv1alpha2.Perses{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "perses-dev", Labels: labels},
+		Status: persesv1alpha2.PersesStatus{
+			Conditions: []metav1.Condition{{Type: common.TypeAvailablePerses, Status: metav1.ConditionTrue}},
+		},
+	}
+}
+
+// instanceClientFactory returns the Perses client of each instance by name.
+type instanceClientFactory map[string]persesclient.ClientInterface
+
+func (f instanceClientFactory) CreateClient(_ context.Context, _ client.Reader, perses persesv1alpha2.Perses) (persesclient.ClientInterface, error) {
+	if persesClient, ok := f[perses.Name]; ok {
+		return persesClient, nil
+	}
+	return nil, fmt.Errorf("connection refused")
+}
@@ -0,0 +1,335 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package variables
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/perses/common/set"
+	"github.com/perses/perses/pkg/client/api/validate"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	persesv1 "github.com/perses/perses/pkg/model/api/v1"
+
+	logger "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	"github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses-operator/internal/subreconciler"
+)
+
+var vlog = logger.WithField("module", "variable_controller")
+
+func (r *PersesVariableReconciler) reconcileVariableInAllInstances(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	variable, ok := variableFromContext(ctx)
+	if !ok {
+		vlog.Error("variable not found in context")
+		res, err := subreconciler.RequeueWithError(fmt.Errorf("variable not found in context"))
+		return r.setStatusToDegraded(ctx, req, res, common.ReasonMissingResource, err)
+	}
+
+	persesInstances, err := r.listSelectedInstances(ctx, variable)
+	if err != nil {
+		vlog.WithError(err).Error("Failed to get perses instances")
+		res, err := subreconciler.RequeueWithError(err)
+		return r.setStatusToDegraded(ctx, req, res, common.ReasonMissingPerses, err)
+	}
+
+	if len(persesInstances.Items) == 0 {
+		vlog.Info("No Perses instances found, retrying in 1 minute")
+		return r.setStatusToDegraded(ctx, req, &ctrl.Result{RequeueAfter: time.Minute}, common.ReasonMissingPerses, fmt.Errorf("no Perses instances found matching the label selector"))
+	}
+
+	available := make([]persesv1alpha2.Perses, 0, len(persesInstances.Items))
+	for _, persesInstance := range persesInstances.Items {
+		if !meta.IsStatusConditionTrue(persesInstance.Status.Conditions, common.TypeAvailablePerses) {
+			vlog.Infof("Skipping Perses instance %s/%s (not yet available)", persesInstance.Namespace, persesInstance.Name)
+			continue
+		}
+		available = append(available, persesInstance)
+	}
+
+	// Every instance is synced, so that a failing instance does not hold back the others.
+	outcomes := subreconciler.ForEach(available, r.InstanceSyncConcurrency, func(persesInstance persesv1alpha2.Perses) (*ctrl.Result, common.ConditionStatusReason, error) {
+		return r.syncPersesVariable(ctx, persesInstance, variable)
+	})
+
+	var planned, failures []string
+	var firstFailure subreconciler.Outcome
+	for i, persesInstance := range available {
+		instanceName := fmt.Sprintf("%s/%s", persesInstance.Namespace, persesInstance.Name)
+		outcome := outcomes[i]
+		if outcome.Halted() {
+			if len(failures) == 0 {
+				firstFailure = outcome
+			}
+			failures = append(failures, fmt.Sprintf("%s: %v", instanceName, outcome.Err))
+			common.RecordInstanceEvent(r.Recorder, variable, persesInstance, corev1.EventTypeWarning, string(outcome.Reason), "Failed to sync variable: %v", outcome.Err)
+			continue
+		}
+		if outcome.Reason == common.ReasonDryRun {
+			planned = append(planned, instanceName)
+		}
+	}
+
+	if len(failures) > 0 {
+		vlog.Errorf("Variable %s failed to sync to %d of %d Perses instances", variable.Name, len(failures), len(available))
+		// A single failure is reported as is, so that its message stays actionable.
+		failure := firstFailure.Err
+		if len(failures) > 1 {
+			failure = fmt.Errorf("failed to sync to %d of %d Perses instances: %s", len(failures), len(available), strings.Join(failures, "; "))
+		}
+		return r.setStatusToDegraded(ctx, req, firstFailure.Result, firstFailure.Reason, failure)
+	}
+
+	if common.IsDryRun(variable, r.DryRun) {
+		return r.updateVariableStatus(ctx, req, func(variable *persesv1alpha2.PersesVariable) {
+			meta.SetStatusCondition(&variable.Status.Conditions, common.DryRunCondition("Variable", variable.Name, planned))
+		})
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesVariableReconciler) listSelectedInstances(ctx context.Context, variable *persesv1alpha2.PersesVariable) (*persesv1alpha2.PersesList, error) {
+	var labelSelector labels.Selector
+	if variable.Spec.InstanceSelector == nil {
+		labelSelector = labels.Everything()
+	} else {
+		var err error
+		labelSelector, err = metav1.LabelSelectorAsSelector(variable.Spec.InstanceSelector)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	persesInstances := &persesv1alpha2.PersesList{}
+	opts := &client.ListOptions{
+		LabelSelector: labelSelector,
+	}
+	if err := r.List(ctx, persesInstances, opts); err != nil {
+		return nil, err
+	}
+	return persesInstances, nil
+}
+
+func (r *PersesVariableReconciler) syncPersesVariable(ctx context.Context, perses persesv1alpha2.Perses, variable *persesv1alpha2.PersesVariable) (*ctrl.Result, common.ConditionStatusReason, error) {
+	persesClient, err := r.ClientFactory.CreateClient(ctx, r.APIReader, perses)
+	if err != nil {
+		vlog.WithError(err).Error("Failed to create perses rest client")
+		return subreconciler.RequeueWithErrorAndReason(err, common.ReasonConnectionFailed)
+	}
+
+	// A dry run does not create the project, the variable is then planned for creation.
+	dryRun := common.IsDryRun(variable, r.DryRun)
+	if !dryRun {
+		if reason, err := common.EnsureProject(ctx, r.APIReader, persesClient, perses, variable.Namespace); err != nil {
+			return subreconciler.RequeueWithErrorAndReason(err, reason)
+		}
+	}
+
+	existing, err := persesClient.Variable(variable.Namespace).Get(variable.Name)
+	notFound := err != nil && errors.Is(err, perseshttp.RequestNotFoundError)
+
+	if err != nil && !notFound {
+		return subreconciler.RequeueWithErrorAndReason(err, common.ReasonBackendError)
+	}
+
+	tags := common.ParseTags(variable.Annotations)
+	var existingTags set.Set[string]
+	if !notFound {
+		existingTags = existing.Metadata.Tags
+	}
+	managed, err := common.ClaimOwnership(variable.Spec.ConflictPolicy, "variable", variable.Name, !notFound, existingTags)
+	if err != nil {
+		vlog.WithError(err).Errorf("Variable conflict: %s", variable.Name)
+		return subreconciler.RequeueWithErrorAndReason(err, common.ReasonConflict)
+	}
+	if managed {
+		tags = common.WithManagedTag(tags)
+	}
+
+	persesVariable := &persesv1.Variable{
+		Kind: persesv1.KindVariable,
+		Metadata: persesv1.ProjectMetadata{
+			Metadata: persesv1.Metadata{
+				Name: variable.Name,
+				Tags: tags,
+			},
+		},
+		Spec: variable.Spec.Config.VariableSpec,
+	}
+
+	if !notFound && common.VariableInSync(existing, persesVariable) {
+		vlog.Debugf("Variable already in sync: %s", variable.Name)
+		res, err := subreconciler.ContinueReconciling()
+		return res, "", err
+	}
+
+	if validateErr := validate.New(persesClient.RESTClient()).Variable(persesVariable); validateErr != nil {
+		if common.IsClientError(validateErr) {
+			vlog.WithError(validateErr).Errorf("Variable validation failed: %s", variable.Name)
+			return subreconciler.RequeueWithErrorAndReason(
+				fmt.Errorf("variable %q failed server-side validation: %w", variable.Name, validateErr),
+				common.ReasonValidationFailed,
+			)
+		}
+		vlog.WithError(validateErr).Errorf("Variable validation request failed: %s", variable.Name)
+		return subreconciler.RequeueWithErrorAndReason(
+			fmt.Errorf("variable %q validation request failed: %w", variable.Name, validateErr),
+			common.ReasonBackendError,
+		)
+	}
+
+	if dryRun {
+		plan := "created"
+		if !notFound {
+			plan = fmt.Sprintf("updated: %s", common.FormatDiff(common.VariableDiff(existing, persesVariable), common.MaxEventDiffLength))
+		}
+		vlog.Infof("Dry run, variable %s would be %s in Perses instance %s/%s", variable.Name, plan, perses.Namespace, perses.Name)
+		common.RecordInstanceEvent(r.Recorder, variable, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: variable would be %s", plan)
+		res, err := subreconciler.ContinueReconciling()
+		return res, common.ReasonDryRun, err
+	}
+
+	if notFound {
+		_, err = persesClient.Variable(variable.Namespace).Create(persesVariable)
+		if err != nil {
+			vlog.WithError(err).Errorf("Failed to create variable: %s", variable.Name)
+			return subreconciler.RequeueWithErrorAndReason(err, common.ReasonBackendError)
+		}
+		vlog.Infof("Variable created: %s", variable.Name)
+		common.RecordInstanceEvent(r.Recorder, variable, perses, corev1.EventTypeNormal, common.EventReasonCreated, "Variable created")
+	} else {
+		_, err = persesClient.Variable(variable.Namespace).Update(persesVariable)
+		if err != nil {
+			vlog.WithError(err).Errorf("Failed to update variable: %s", variable.Name)
+			return subreconciler.RequeueWithErrorAndReason(err, common.ReasonBackendError)
+		}
+		vlog.Infof("Variable updated: %s", variable.Name)
+		common.RecordInstanceEvent(r.Recorder, variable, perses, corev1.EventTypeNormal, common.EventReasonUpdated, "Variable updated: %s", common.FormatDiff(common.VariableDiff(existing, persesVariable), common.MaxEventDiffLength))
+	}
+
+	res, err := subreconciler.ContinueReconciling()
+	return res, "", err
+}
+
+// deleteVariableInAllInstances removes the variable from the Perses instances selected by the
+// PersesVariable. The deletion is blocked while one of them cannot confirm the removal.
+func (r *PersesVariableReconciler) deleteVariableInAllInstances(ctx context.Context, req ctrl.Request, variable *persesv1alpha2.PersesVariable) (*ctrl.Result, error) {
+	persesInstances, err := r.listSelectedInstances(ctx, variable)
+	if err != nil {
+		vlog.WithError(err).Error("Failed to get perses instances")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	var blocked []string
+	available := make([]persesv1alpha2.Perses, 0, len(persesInstances.Items))
+	for _, persesInstance := range persesInstances.Items {
+		if !meta.IsStatusConditionTrue(persesInstance.Status.Conditions, common.TypeAvailablePerses) {
+			vlog.Infof("Perses instance %s/%s is not available, variable deletion is blocked", persesInstance.Namespace, persesInstance.Name)
+			blocked = append(blocked, fmt.Sprintf("%s/%s (not available)", persesInstance.Namespace, persesInstance.Name))
+			common.RecordInstanceEvent(r.Recorder, variable, persesInstance, corev1.EventTypeWarning, string(common.ReasonDeletionBlocked), "Variable deletion is waiting for the instance to be available")
+			continue
+		}
+		available = append(available, persesInstance)
+	}
+
+	outcomes := subreconciler.ForEach(available, r.InstanceSyncConcurrency, func(persesInstance persesv1alpha2.Perses) (*ctrl.Result, common.ConditionStatusReason, error) {
+		res, err := r.deleteVariable(ctx, persesInstance, variable)
+		return res, "", err
+	})
+	for i, persesInstance := range available {
+		if outcome := outcomes[i]; outcome.Halted() {
+			blocked = append(blocked, fmt.Sprintf("%s/%s (%v)", persesInstance.Namespace, persesInstance.Name, outcome.Err))
+			common.RecordInstanceEvent(r.Recorder, variable, persesInstance, corev1.EventTypeWarning, string(common.ReasonDeletionBlocked), "Failed to delete variable: %v", outcome.Err)
+		}
+	}
+
+	if len(blocked) > 0 {
+		return r.setStatusToDegraded(ctx, req, &ctrl.Result{RequeueAfter: time.Minute}, common.ReasonDeletionBlocked,
+			fmt.Errorf("variable deletion is waiting for Perses instances: %s", strings.Join(blocked, "; ")))
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *PersesVariableReconciler) deleteVariable(ctx context.Context, perses persesv1alpha2.Perses, variable *persesv1alpha2.PersesVariable) (*ctrl.Result, error) {
+	variableNamespace, variableName := variable.Namespace, variable.Name
+	persesClient, err := r.ClientFactory.CreateClient(ctx, r.APIReader, perses)
+	if err != nil {
+		vlog.WithError(err).Error("Failed to create perses rest client")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	_, err = persesClient.Project().Get(variableNamespace)
+	if err != nil {
+		// The variable went away with its project.
+		if errors.Is(err, perseshttp.RequestNotFoundError) {
+			vlog.Infof("Project not found: %s", variableNamespace)
+			return subreconciler.ContinueReconciling()
+		}
+		vlog.WithError(err).Errorf("project error: %s", variableNamespace)
+
+		return subreconciler.RequeueWithError(err)
+	}
+
+	existing, err := persesClient.Variable(variableNamespace).Get(variableName)
+	if err != nil {
+		if errors.Is(err, perseshttp.RequestNotFoundError) {
+			vlog.Infof("Variable not found: %s", variableName)
+			return subreconciler.ContinueReconciling()
+		}
+		vlog.WithError(err).Errorf("Failed to get variable: %s", variableName)
+		return subreconciler.RequeueWithError(err)
+	}
+
+	// A variable the operator does not own was either left untouched or overwritten, it is not removed.
+	if !common.IsManaged(existing.Metadata.Tags) {
+		vlog.Infof("Variable not managed by the operator, keeping it: %s", variableName)
+		return subreconciler.ContinueReconciling()
+	}
+
+	if common.IsDryRun(variable, r.DryRun) {
+		vlog.Infof("Dry run, variable %s would be deleted from Perses instance %s/%s", variableName, perses.Namespace, perses.Name)
+		common.RecordInstanceEvent(r.Recorder, variable, perses, corev1.EventTypeNormal, string(common.ReasonDryRun), "Dry run: variable would be deleted")
+		return subreconciler.ContinueReconciling()
+	}
+
+	err = persesClient.Variable(variableNamespace).Delete(variableName)
+	// Ignore NotFound — the resource may have already been deleted from Perses directly.
+	// Any other error means the delete failed and should be retried.
+	if err != nil {
+		if errors.Is(err, perseshttp.RequestNotFoundError) {
+			vlog.Infof("Variable not found: %s", variableName)
+			return subreconciler.ContinueReconciling()
+		}
+		vlog.WithError(err).Errorf("Failed to delete variable: %s", variableName)
+		return subreconciler.RequeueWithError(err)
+	}
+
+	vlog.Infof("Variable deleted: %s", variableName)
+	common.RecordInstanceEvent(r.Recorder, variable, perses, corev1.EventTypeNormal, common.EventReasonDeleted, "Variable deleted")
+
+	return subreconciler.ContinueReconciling()
+}
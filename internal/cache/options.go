@@ -15,12 +15,22 @@ package cache
 
 import (
 	configv1 "github.com/openshift/api/config/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	"github.com/perses/perses-operator/internal/operator"
 	"github.com/perses/perses-operator/internal/perses/common"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
 // ParseSecretLabelSelector parses a label selector string using the native
@@ -39,8 +49,10 @@ func ParseSecretLabelSelector(raw string) (labels.Selector, error) {
 // memory usage. Per-object Transforms override DefaultTransform, so they also strip
 // ManagedFields explicitly.
 //
-// Operator-created resources (Deployment, StatefulSet, ConfigMap, Service) are filtered
-// by the fixed label app.kubernetes.io/managed-by=perses-operator.
+// Operator-created resources (Deployment, StatefulSet, ConfigMap, Service, Ingress,
+// HorizontalPodAutoscaler, PodDisruptionBudget, and the Route, HTTPRoute, ServiceMonitor and
+// PodMonitor when apis reports them served) and the Perses pods are filtered by the fixed label
+// app.kubernetes.io/managed-by=perses-operator.
 //
 // CRD resources (PersesDashboard, PersesDatasource, PersesGlobalDatasource) are not
 // cached here — their controllers use builder.OnlyMetadata and APIReader instead.
@@ -49,14 +61,14 @@ func ParseSecretLabelSelector(raw string) (labels.Selector, error) {
 // the default label perses.dev/watch=true is used. If watchAllSecrets is true, no label
 // filter is applied to secrets (preserving pre-change behavior).
 // Secret data is always stripped from the cache via Transform regardless of label filtering.
-func BuildCacheOptions(secretSelector labels.Selector, watchAllSecrets bool, tlsClusterProfile bool) cache.Options {
+func BuildCacheOptions(secretSelector labels.Selector, watchAllSecrets bool, tlsClusterProfile bool, apis operator.APIs) cache.Options {
 	return cache.Options{
 		DefaultTransform: cache.TransformStripManagedFields(),
-		ByObject:         buildCacheByObject(secretSelector, watchAllSecrets, tlsClusterProfile),
+		ByObject:         buildCacheByObject(secretSelector, watchAllSecrets, tlsClusterProfile, apis),
 	}
 }
 
-func buildCacheByObject(secretSelector labels.Selector, watchAllSecrets bool, tlsClusterProfile bool) map[client.Object]cache.ByObject {
+func buildCacheByObject(secretSelector labels.Selector, watchAllSecrets bool, tlsClusterProfile bool, apis operator.APIs) map[client.Object]cache.ByObject {
 	managedBySelector := labels.SelectorFromSet(labels.Set{
 		common.PersesManagedByLabel: common.PersesManagedByValue,
 	})
@@ -74,6 +86,40 @@ func buildCacheByObject(secretSelector labels.Selector, watchAllSecrets bool, tl
 		&corev1.Service{}: {
 			Label: managedBySelector,
 		},
+		&corev1.Pod{}: {
+			Label: managedBySelector,
+		},
+		&networkingv1.Ingress{}: {
+			Label: managedBySelector,
+		},
+		&autoscalingv2.HorizontalPodAutoscaler{}: {
+			Label: managedBySelector,
+		},
+		&policyv1.PodDisruptionBudget{}: {
+			Label: managedBySelector,
+		},
+	}
+
+	// The optional APIs are only cached when served, the cache resolves every entry on start.
+	if apis.Route {
+		byObject[&routev1.Route{}] = cache.ByObject{
+			Label: managedBySelector,
+		}
+	}
+	if apis.HTTPRoute {
+		byObject[&gatewayv1.HTTPRoute{}] = cache.ByObject{
+			Label: managedBySelector,
+		}
+	}
+	if apis.ServiceMonitor {
+		byObject[&monitoringv1.ServiceMonitor{}] = cache.ByObject{
+			Label: managedBySelector,
+		}
+	}
+	if apis.PodMonitor {
+		byObject[&monitoringv1.PodMonitor{}] = cache.ByObject{
+			Label: managedBySelector,
+		}
 	}
 
 	secretEntry := cache.ByObject{
@@ -106,3 +152,30 @@ func buildCacheByObject(secretSelector labels.Selector, watchAllSecrets bool, tl
 
 	return byObject
 }
+
+// BuildWatchedConfigMapCacheOptions builds the configuration of the cache watching the ConfigMaps
+// referenced by Perses resources.
+//
+// The manager cache only holds the ConfigMaps created by the operator, so the ConfigMaps referenced
+// by Perses instances and datasources are cached separately, filtered by the label perses.dev/watch=true.
+// Watches on this cache use ConfigMapMetadata: only metadata is cached and the data is read via APIReader.
+func BuildWatchedConfigMapCacheOptions(scheme *runtime.Scheme, mapper meta.RESTMapper) cache.Options {
+	return cache.Options{
+		Scheme:           scheme,
+		Mapper:           mapper,
+		DefaultTransform: cache.TransformStripManagedFields(),
+		DefaultLabelSelector: labels.SelectorFromSet(labels.Set{
+			common.PersesWatchLabel: common.PersesWatchLabelValue,
+		}),
+	}
+}
+
+// ConfigMapMetadata returns the object used to watch the metadata of ConfigMaps.
+func ConfigMapMetadata() *metav1.PartialObjectMetadata {
+	return &metav1.PartialObjectMetadata{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "ConfigMap",
+		},
+	}
+}
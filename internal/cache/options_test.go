@@ -18,14 +18,21 @@ import (
 	"testing"
 
 	configv1 "github.com/openshift/api/config/v1"
+	routev1 "github.com/openshift/api/route/v1"
 	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
+	"github.com/perses/perses-operator/internal/operator"
 	"github.com/perses/perses-operator/internal/perses/common"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	ctrlcache "sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
 func TestParseSecretLabelSelector(t *testing.T) {
@@ -134,7 +141,7 @@ func findByObjectEntry(byObject map[client.Object]ctrlcache.ByObject, target cli
 }
 
 func TestBuildCacheByObject_DefaultLabels(t *testing.T) {
-	byObject := buildCacheByObject(nil, false, false)
+	byObject := buildCacheByObject(nil, false, false, operator.APIs{})
 
 	managedBySelector := labels.SelectorFromSet(labels.Set{
 		common.PersesManagedByLabel: common.PersesManagedByValue,
@@ -146,6 +153,10 @@ func TestBuildCacheByObject_DefaultLabels(t *testing.T) {
 		&appsv1.StatefulSet{},
 		&corev1.ConfigMap{},
 		&corev1.Service{},
+		&corev1.Pod{},
+		&networkingv1.Ingress{},
+		&autoscalingv2.HorizontalPodAutoscaler{},
+		&policyv1.PodDisruptionBudget{},
 	}
 
 	for _, obj := range managedTypes {
@@ -182,7 +193,7 @@ func TestBuildCacheByObject_CustomSecretSelector(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to parse selector: %v", err)
 	}
-	byObject := buildCacheByObject(customSelector, false, false)
+	byObject := buildCacheByObject(customSelector, false, false, operator.APIs{})
 
 	secretEntry := findByObjectEntry(byObject, &corev1.Secret{})
 	if secretEntry == nil {
@@ -199,7 +210,7 @@ func TestBuildCacheByObject_CustomSecretSelector(t *testing.T) {
 }
 
 func TestBuildCacheByObject_WatchAllSecrets(t *testing.T) {
-	byObject := buildCacheByObject(nil, true, false)
+	byObject := buildCacheByObject(nil, true, false, operator.APIs{})
 
 	secretEntry := findByObjectEntry(byObject, &corev1.Secret{})
 	if secretEntry == nil {
@@ -220,7 +231,7 @@ func TestBuildCacheByObject_WatchAllSecretsOverridesSelector(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to parse selector: %v", err)
 	}
-	byObject := buildCacheByObject(customSelector, true, false)
+	byObject := buildCacheByObject(customSelector, true, false, operator.APIs{})
 
 	secretEntry := findByObjectEntry(byObject, &corev1.Secret{})
 	if secretEntry == nil {
@@ -234,7 +245,7 @@ func TestBuildCacheByObject_WatchAllSecretsOverridesSelector(t *testing.T) {
 
 func getSecretTransform(t *testing.T) func(obj any) (any, error) {
 	t.Helper()
-	byObject := buildCacheByObject(nil, false, false)
+	byObject := buildCacheByObject(nil, false, false, operator.APIs{})
 	secretEntry := findByObjectEntry(byObject, &corev1.Secret{})
 	if secretEntry == nil {
 		t.Fatal("expected ByObject entry for Secret")
@@ -335,7 +346,7 @@ func TestBuildCacheByObject_SecretTransformEmptySecret(t *testing.T) {
 }
 
 func TestBuildCacheByObject_TLSClusterProfile(t *testing.T) {
-	byObject := buildCacheByObject(nil, false, true)
+	byObject := buildCacheByObject(nil, false, true, operator.APIs{})
 
 	apiServerEntry := findByObjectEntry(byObject, &configv1.APIServer{})
 	if apiServerEntry == nil {
@@ -348,7 +359,7 @@ func TestBuildCacheByObject_TLSClusterProfile(t *testing.T) {
 }
 
 func TestBuildCacheByObject_NoTLSClusterProfile(t *testing.T) {
-	byObject := buildCacheByObject(nil, false, false)
+	byObject := buildCacheByObject(nil, false, false, operator.APIs{})
 
 	apiServerEntry := findByObjectEntry(byObject, &configv1.APIServer{})
 	if apiServerEntry != nil {
@@ -356,8 +367,33 @@ func TestBuildCacheByObject_NoTLSClusterProfile(t *testing.T) {
 	}
 }
 
+func TestBuildCacheByObject_OptionalAPIs(t *testing.T) {
+	managedBySelector := labels.SelectorFromSet(labels.Set{
+		common.PersesManagedByLabel: common.PersesManagedByValue,
+	})
+
+	byObject := buildCacheByObject(nil, false, false, operator.APIs{Route: true, HTTPRoute: true, ServiceMonitor: true, PodMonitor: true})
+	for _, obj := range []client.Object{&routev1.Route{}, &gatewayv1.HTTPRoute{}, &monitoringv1.ServiceMonitor{}, &monitoringv1.PodMonitor{}} {
+		entry := findByObjectEntry(byObject, obj)
+		if entry == nil {
+			t.Errorf("expected ByObject entry for %T when its API is served", obj)
+			continue
+		}
+		if entry.Label.String() != managedBySelector.String() {
+			t.Errorf("expected label selector %q for %T, got %q", managedBySelector, obj, entry.Label)
+		}
+	}
+
+	byObject = buildCacheByObject(nil, false, false, operator.APIs{})
+	for _, obj := range []client.Object{&routev1.Route{}, &gatewayv1.HTTPRoute{}, &monitoringv1.ServiceMonitor{}, &monitoringv1.PodMonitor{}} {
+		if entry := findByObjectEntry(byObject, obj); entry != nil {
+			t.Errorf("expected no ByObject entry for %T when its API is not served", obj)
+		}
+	}
+}
+
 func TestBuildCacheByObject_NoCRDEntries(t *testing.T) {
-	byObject := buildCacheByObject(nil, false, false)
+	byObject := buildCacheByObject(nil, false, false, operator.APIs{})
 
 	crdTypes := []client.Object{
 		&persesv1alpha2.PersesDashboard{},
@@ -374,7 +410,7 @@ func TestBuildCacheByObject_NoCRDEntries(t *testing.T) {
 }
 
 func TestBuildCacheOptions_SetsDefaultTransform(t *testing.T) {
-	opts := BuildCacheOptions(nil, false, false)
+	opts := BuildCacheOptions(nil, false, false, operator.APIs{})
 	if opts.DefaultTransform == nil {
 		t.Fatal("expected DefaultTransform to be set")
 	}
@@ -398,7 +434,7 @@ func TestBuildCacheOptions_SetsDefaultTransform(t *testing.T) {
 }
 
 func TestBuildCacheOptions_ContainsByObject(t *testing.T) {
-	opts := BuildCacheOptions(nil, false, false)
+	opts := BuildCacheOptions(nil, false, false, operator.APIs{})
 	if opts.ByObject == nil {
 		t.Fatal("expected ByObject to be set")
 	}
@@ -429,3 +465,28 @@ func TestBuildCacheByObject_SecretTransformStripsManagedFields(t *testing.T) {
 		t.Error("expected ManagedFields to be nil after Transform")
 	}
 }
+
+func TestBuildWatchedConfigMapCacheOptions(t *testing.T) {
+	opts := BuildWatchedConfigMapCacheOptions(nil, nil)
+	if opts.DefaultLabelSelector == nil {
+		t.Fatal("expected DefaultLabelSelector to be set")
+	}
+	expected := fmt.Sprintf("%s=%s", common.PersesWatchLabel, common.PersesWatchLabelValue)
+	if opts.DefaultLabelSelector.String() != expected {
+		t.Errorf("expected ConfigMap label selector %q, got %q", expected, opts.DefaultLabelSelector.String())
+	}
+	if opts.DefaultTransform == nil {
+		t.Error("expected DefaultTransform to be set")
+	}
+	if len(opts.ByObject) != 0 {
+		t.Error("expected no ByObject entries")
+	}
+}
+
+func TestConfigMapMetadata(t *testing.T) {
+	obj := ConfigMapMetadata()
+	gvk := obj.GroupVersionKind()
+	if gvk.Group != "" || gvk.Version != "v1" || gvk.Kind != "ConfigMap" {
+		t.Errorf("expected core/v1 ConfigMap, got %s", gvk.String())
+	}
+}
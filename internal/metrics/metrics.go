@@ -14,6 +14,7 @@
 package metrics
 
 import (
+	"strconv"
 	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -23,7 +24,7 @@ import (
 var (
 	resourcesDesc = prometheus.NewDesc(
 		"perses_operator_managed_resources",
-		"Number of resources managed by the operator per state (synced/failed)",
+		"Number of resources managed by the operator per state (synced/failed/suspended)",
 		[]string{"resource", "state", "resource_namespace"},
 		nil,
 	)
@@ -41,6 +42,12 @@ type Metrics struct {
 	// Operator readiness per controller
 	ready *prometheus.GaugeVec
 
+	// Objects removed from Perses by the orphan sweeper
+	orphansPruned *prometheus.CounterVec
+
+	// Objects modified directly in Perses and restored from their custom resource
+	driftCorrections *prometheus.CounterVec
+
 	// mtx protects all fields below
 	mtx       sync.RWMutex
 	resources map[resourceKey]map[string]int
@@ -57,6 +64,7 @@ type resourceState int
 const (
 	synced resourceState = iota
 	failed
+	suspended
 )
 
 func (r resourceState) String() string {
@@ -65,6 +73,8 @@ func (r resourceState) String() string {
 		return "synced"
 	case failed:
 		return "failed"
+	case suspended:
+		return "suspended"
 	}
 	return ""
 }
@@ -100,6 +110,20 @@ func NewMetrics() *Metrics {
 			},
 			[]string{"controller"},
 		),
+		orphansPruned: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "perses_operator_orphans_pruned_total",
+				Help: "Total number of operator-managed objects removed from Perses because no custom resource backs them anymore",
+			},
+			[]string{"resource", "dry_run"},
+		),
+		driftCorrections: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "perses_operator_drift_corrections_total",
+				Help: "Total number of objects modified directly in Perses and restored from their custom resource",
+			},
+			[]string{"resource"},
+		),
 		resources: make(map[resourceKey]map[string]int),
 	}
 
@@ -109,6 +133,8 @@ func NewMetrics() *Metrics {
 		m.reconcileErrors,
 		m.persesInstances,
 		m.ready,
+		m.orphansPruned,
+		m.driftCorrections,
 		m, // Register self as custom collector for managed_resources
 	)
 
@@ -141,6 +167,16 @@ func (m *Metrics) Ready(controller string) prometheus.Gauge {
 	return m.ready.With(prometheus.Labels{"controller": controller})
 }
 
+// OrphansPruned returns a counter to track the objects removed from Perses by the orphan sweeper.
+func (m *Metrics) OrphansPruned(resource string, dryRun bool) prometheus.Counter {
+	return m.orphansPruned.With(prometheus.Labels{"resource": resource, "dry_run": strconv.FormatBool(dryRun)})
+}
+
+// DriftCorrections returns a counter to track the objects restored in Perses after a drift.
+func (m *Metrics) DriftCorrections(resource string) prometheus.Counter {
+	return m.driftCorrections.With(prometheus.Labels{"resource": resource})
+}
+
 // SetSyncedResources sets the number of resources that synced successfully for the given object's key.
 // The namespace parameter is the Kubernetes namespace of the resource (empty for cluster-scoped resources).
 func (m *Metrics) SetSyncedResources(objKey, resource, namespace string, v int) {
@@ -153,6 +189,12 @@ func (m *Metrics) SetFailedResources(objKey, resource, namespace string, v int)
 	m.setResources(objKey, resourceKey{resource: resource, state: failed, namespace: namespace}, v)
 }
 
+// SetSuspendedResources sets the number of resources whose reconciliation is paused for the given object's key.
+// The namespace parameter is the Kubernetes namespace of the resource (empty for cluster-scoped resources).
+func (m *Metrics) SetSuspendedResources(objKey, resource, namespace string, v int) {
+	m.setResources(objKey, resourceKey{resource: resource, state: suspended, namespace: namespace}, v)
+}
+
 // ForgetObject removes all resource entries for the given object key.
 // It should be called when a controller detects that the object has been deleted.
 func (m *Metrics) ForgetObject(objKey string) {
@@ -56,16 +56,32 @@ func TestNewMetrics(t *testing.T) {
 			},
 			[]string{"controller"},
 		),
+		orphansPruned: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "perses_operator_orphans_pruned_total",
+				Help: "Total number of operator-managed objects removed from Perses",
+			},
+			[]string{"resource", "dry_run"},
+		),
+		driftCorrections: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "perses_operator_drift_corrections_total",
+				Help: "Total number of objects restored in Perses after a drift",
+			},
+			[]string{"resource"},
+		),
 		resources: make(map[resourceKey]map[string]int),
 	}
 
-	reg.MustRegister(m.reconcileOperations, m.reconcileErrors, m.persesInstances, m.ready, m)
+	reg.MustRegister(m.reconcileOperations, m.reconcileErrors, m.persesInstances, m.ready, m.orphansPruned, m.driftCorrections, m)
 
 	// Set some values so metrics appear in output
 	m.reconcileOperations.WithLabelValues("test").Add(1)
 	m.reconcileErrors.WithLabelValues("test", "test_reason").Add(0)
 	m.persesInstances.WithLabelValues("test-ns", "test-perses").Set(1)
 	m.Ready("test").Set(1)
+	m.OrphansPruned("dashboard", false).Inc()
+	m.DriftCorrections("dashboard").Inc()
 
 	// Verify metrics are registered
 	metricFamilies, err := reg.Gather()
@@ -78,6 +94,8 @@ func TestNewMetrics(t *testing.T) {
 		"perses_operator_reconcile_errors_total",
 		"perses_operator_managed_perses_instances",
 		"perses_operator_ready",
+		"perses_operator_orphans_pruned_total",
+		"perses_operator_drift_corrections_total",
 	}
 
 	foundMetrics := make(map[string]bool)
@@ -151,6 +169,61 @@ func TestReconcileErrorsCounter(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestOrphansPrunedCounter(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := &Metrics{
+		orphansPruned: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "perses_operator_orphans_pruned_total",
+				Help: "Total number of operator-managed objects removed from Perses",
+			},
+			[]string{"resource", "dry_run"},
+		),
+		resources: make(map[resourceKey]map[string]int),
+	}
+	reg.MustRegister(m.orphansPruned)
+
+	m.OrphansPruned("dashboard", false).Inc()
+	m.OrphansPruned("dashboard", false).Inc()
+	m.OrphansPruned("secret", true).Inc()
+
+	expected := `
+		# HELP perses_operator_orphans_pruned_total Total number of operator-managed objects removed from Perses
+		# TYPE perses_operator_orphans_pruned_total counter
+		perses_operator_orphans_pruned_total{dry_run="false",resource="dashboard"} 2
+		perses_operator_orphans_pruned_total{dry_run="true",resource="secret"} 1
+	`
+	err := testutil.CollectAndCompare(m.orphansPruned, strings.NewReader(expected))
+	assert.NoError(t, err)
+}
+
+func TestDriftCorrectionsCounter(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := &Metrics{
+		driftCorrections: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "perses_operator_drift_corrections_total",
+				Help: "Total number of objects restored in Perses after a drift",
+			},
+			[]string{"resource"},
+		),
+	}
+	reg.MustRegister(m.driftCorrections)
+
+	m.DriftCorrections("dashboard").Inc()
+	m.DriftCorrections("dashboard").Inc()
+	m.DriftCorrections("globaldatasource").Inc()
+
+	expected := `
+		# HELP perses_operator_drift_corrections_total Total number of objects restored in Perses after a drift
+		# TYPE perses_operator_drift_corrections_total counter
+		perses_operator_drift_corrections_total{resource="dashboard"} 2
+		perses_operator_drift_corrections_total{resource="globaldatasource"} 1
+	`
+	err := testutil.CollectAndCompare(m.driftCorrections, strings.NewReader(expected))
+	assert.NoError(t, err)
+}
+
 func TestPersesInstancesGauge(t *testing.T) {
 	reg := prometheus.NewRegistry()
 	m := &Metrics{
@@ -254,6 +327,21 @@ func TestSetSyncedAndFailedResources(t *testing.T) {
 	assert.Len(t, m.resources[failedKey], 1, "Should have 1 failed resource")
 }
 
+func TestSetSuspendedResources(t *testing.T) {
+	m := &Metrics{
+		resources: make(map[resourceKey]map[string]int),
+	}
+
+	m.SetSuspendedResources("ns1/resource1", "dashboard", "ns1", 1)
+	m.SetSuspendedResources("ns1/resource2", "dashboard", "ns1", 0)
+
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	suspendedKey := resourceKey{resource: "dashboard", state: suspended, namespace: "ns1"}
+	assert.Equal(t, map[string]int{"ns1/resource1": 1, "ns1/resource2": 0}, m.resources[suspendedKey])
+}
+
 func TestResourceStateString(t *testing.T) {
 	tests := []struct {
 		state    resourceState
@@ -261,6 +349,7 @@ func TestResourceStateString(t *testing.T) {
 	}{
 		{synced, "synced"},
 		{failed, "failed"},
+		{suspended, "suspended"},
 	}
 
 	for _, tt := range tests {
@@ -358,7 +447,7 @@ func TestForgetObjectCollectOutput(t *testing.T) {
 	// Collect and verify totals — the failed entry should disappear entirely
 	// since no objects remain in that category
 	expected := `
-		# HELP perses_operator_managed_resources Number of resources managed by the operator per state (synced/failed)
+		# HELP perses_operator_managed_resources Number of resources managed by the operator per state (synced/failed/suspended)
 		# TYPE perses_operator_managed_resources gauge
 		perses_operator_managed_resources{resource="dashboard",resource_namespace="ns1",state="synced"} 1
 	`
@@ -0,0 +1,77 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"fmt"
+
+	routev1 "github.com/openshift/api/route/v1"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/discovery"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// APIs reports which of the optional APIs the operator manages resources of are served by the cluster.
+type APIs struct {
+	// Route is true when the route.openshift.io/v1 Routes are served, on OpenShift clusters.
+	Route bool
+	// HTTPRoute is true when the gateway.networking.k8s.io/v1 HTTPRoutes are served, on clusters
+	// with the Gateway API CRDs installed.
+	HTTPRoute bool
+	// ServiceMonitor is true when the monitoring.coreos.com/v1 ServiceMonitors are served, on clusters
+	// with the Prometheus Operator CRDs installed.
+	ServiceMonitor bool
+	// PodMonitor is true when the monitoring.coreos.com/v1 PodMonitors are served.
+	PodMonitor bool
+}
+
+// DiscoverAPIs looks up the optional APIs served by the cluster.
+// The APIs are discovered once, installing them later requires restarting the operator.
+func DiscoverAPIs(client discovery.DiscoveryInterface) (APIs, error) {
+	var apis APIs
+	var err error
+
+	if apis.Route, err = servesResource(client, routev1.GroupVersion.String(), "routes"); err != nil {
+		return apis, err
+	}
+	if apis.HTTPRoute, err = servesResource(client, gatewayv1.GroupVersion.String(), "httproutes"); err != nil {
+		return apis, err
+	}
+	if apis.ServiceMonitor, err = servesResource(client, monitoringv1.SchemeGroupVersion.String(), "servicemonitors"); err != nil {
+		return apis, err
+	}
+	if apis.PodMonitor, err = servesResource(client, monitoringv1.SchemeGroupVersion.String(), "podmonitors"); err != nil {
+		return apis, err
+	}
+
+	return apis, nil
+}
+
+func servesResource(client discovery.DiscoveryInterface, groupVersion string, resource string) (bool, error) {
+	resources, err := client.ServerResourcesForGroupVersion(groupVersion)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to discover the resources of %s: %w", groupVersion, err)
+	}
+
+	for _, r := range resources.APIResources {
+		if r.Name == resource {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,87 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestDiscoverAPIs(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources []*metav1.APIResourceList
+		expect    APIs
+	}{
+		{
+			name:   "no optional API",
+			expect: APIs{},
+		},
+		{
+			name: "OpenShift",
+			resources: []*metav1.APIResourceList{
+				{GroupVersion: "route.openshift.io/v1", APIResources: []metav1.APIResource{{Name: "routes"}, {Name: "routes/status"}}},
+			},
+			expect: APIs{Route: true},
+		},
+		{
+			name: "Gateway API",
+			resources: []*metav1.APIResourceList{
+				{GroupVersion: "gateway.networking.k8s.io/v1", APIResources: []metav1.APIResource{{Name: "gateways"}, {Name: "httproutes"}}},
+			},
+			expect: APIs{HTTPRoute: true},
+		},
+		{
+			name: "Gateway API without HTTPRoute",
+			resources: []*metav1.APIResourceList{
+				{GroupVersion: "gateway.networking.k8s.io/v1", APIResources: []metav1.APIResource{{Name: "gateways"}}},
+			},
+			expect: APIs{},
+		},
+		{
+			name: "Prometheus Operator",
+			resources: []*metav1.APIResourceList{
+				{GroupVersion: "monitoring.coreos.com/v1", APIResources: []metav1.APIResource{{Name: "prometheuses"}, {Name: "servicemonitors"}, {Name: "podmonitors"}}},
+			},
+			expect: APIs{ServiceMonitor: true, PodMonitor: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &discoveryfake.FakeDiscovery{Fake: &clienttesting.Fake{Resources: tt.resources}}
+
+			apis, err := DiscoverAPIs(client)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, apis)
+		})
+	}
+}
+
+func TestDiscoverAPIs_Error(t *testing.T) {
+	fake := &clienttesting.Fake{}
+	fake.AddReactor("get", "resource", func(clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+
+	_, err := DiscoverAPIs(&discoveryfake.FakeDiscovery{Fake: fake})
+	assert.ErrorContains(t, err, "connection refused")
+}
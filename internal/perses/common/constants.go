@@ -16,11 +16,13 @@ package common
 import (
 	"context"
 	"errors"
+	"maps"
 	"net/http"
 
 	"github.com/perses/perses-operator/api/v1alpha2"
 	"github.com/perses/perses/pkg/client/perseshttp"
 	logger "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -37,21 +39,38 @@ const (
 	PersesNamespaceDomain     = "perses.dev"
 	PersesFinalizer           = PersesNamespaceDomain + "/finalizer"
 	PersesProvisioningVersion = PersesNamespaceDomain + "/provisioning-version"
+	PersesConfigChecksum      = PersesNamespaceDomain + "/config-checksum"
 	PersesWatchLabel          = PersesNamespaceDomain + "/watch"
 	PersesWatchLabelValue     = "true"
 	PersesManagedByLabel      = "app.kubernetes.io/managed-by"
 	PersesManagedByValue      = "perses-operator"
 	TypeAvailablePerses       = "Available"
 	TypeDegradedPerses        = "Degraded"
+	TypeDriftedPerses         = "Drifted"
+	TypePartiallySyncedPerses = "PartiallySynced"
+	TypeNotAllowedPerses      = "NotAllowedByInstance"
+	TypeSuspendedPerses       = "Suspended"
+	TypeDryRunPerses          = "DryRun"
+	TypeProgressingPerses     = "Progressing"
+	PersesReconcileAnnotation = PersesNamespaceDomain + "/reconcile"
+	PersesReconcilePaused     = "paused"
+	PersesDryRunAnnotation    = PersesNamespaceDomain + "/dry-run"
+	PersesDryRunEnabled       = "true"
 
 	// Flags
-	PersesServerURLFlag      = "perses-server-url"
-	WatchSecretLabelsFlag    = "watch-secret-labels"
-	WatchAllSecretsFlag      = "watch-all-secrets"
-	TLSMinVersionFlag        = "tls-min-version"
-	TLSCipherSuitesFlag      = "tls-cipher-suites"
-	TLSClusterProfileFlag    = "tls-cluster-profile"
-	TLSConfigureOperandsFlag = "tls-configure-operands"
+	PersesServerURLFlag         = "perses-server-url"
+	WatchSecretLabelsFlag       = "watch-secret-labels"
+	WatchAllSecretsFlag         = "watch-all-secrets"
+	TLSMinVersionFlag           = "tls-min-version"
+	TLSCipherSuitesFlag         = "tls-cipher-suites"
+	TLSClusterProfileFlag       = "tls-cluster-profile"
+	TLSConfigureOperandsFlag    = "tls-configure-operands"
+	OrphanGCIntervalFlag        = "orphan-gc-interval"
+	OrphanGCDryRunFlag          = "orphan-gc-dry-run"
+	ResyncPeriodFlag            = "resync-period"
+	InstanceSyncConcurrencyFlag = "instance-sync-concurrency"
+	DryRunFlag                  = "dry-run"
+	VerificationPeriodFlag      = "verification-period"
 
 	// Volume names
 	configVolumeName  = "config"
@@ -114,6 +133,42 @@ const (
 	ReasonValidationFailed ConditionStatusReason = "ValidationFailed"
 	// Generic failure for when the reason is due to the backend returning an error
 	ReasonBackendError ConditionStatusReason = "PersesBackendError"
+	// Failure to be used when a resource cannot be removed from every Perses instance it was synced to
+	ReasonDeletionBlocked ConditionStatusReason = "DeletionBlocked"
+	// Failure to be used when an object not created by the operator already exists in Perses
+	ReasonConflict ConditionStatusReason = "Conflict"
+	// Drift to be reported when an object modified directly in Perses was restored from its custom resource
+	ReasonDriftCorrected ConditionStatusReason = "DriftCorrected"
+	// Drift to be reported when every Perses instance matches the custom resource
+	ReasonNoDrift ConditionStatusReason = "NoDrift"
+	// Partial sync to be reported when a resource failed to sync to some of the available Perses instances only
+	ReasonInstancesFailed ConditionStatusReason = "InstancesFailed"
+	// Partial sync to be reported when a resource is synced to every available Perses instance
+	ReasonAllInstancesSynced ConditionStatusReason = "AllInstancesSynced"
+	// Partial sync to be reported when a resource failed to sync to every available Perses instance
+	ReasonNoInstanceSynced ConditionStatusReason = "NoInstanceSynced"
+	// Failure to be used when a resource is rejected by the resource selectors of a selected Perses instance
+	ReasonNotAllowedByInstance ConditionStatusReason = "NotAllowedByInstance"
+	// Admission to be reported when every selected Perses instance accepts a resource
+	ReasonAllowedByAllInstances ConditionStatusReason = "AllowedByAllInstances"
+	// Suspension to be reported when the reconciliation of a resource is paused with the perses.dev/reconcile annotation
+	ReasonReconcilePaused ConditionStatusReason = "ReconcilePaused"
+	// Dry run to be reported when changes were planned for a resource but not applied
+	ReasonDryRun ConditionStatusReason = "DryRun"
+	// Dry run to be reported when a resource already matches everything it would be applied to
+	ReasonNoChangePlanned ConditionStatusReason = "NoChangePlanned"
+	// Sync to be reported when the content of a resource was already applied to a Perses instance, which was not called
+	ReasonAlreadyApplied ConditionStatusReason = "AlreadyApplied"
+	// Progress to be reported while the workload of a Perses instance does not run its latest pod template on every replica
+	ReasonRolloutInProgress ConditionStatusReason = "RolloutInProgress"
+	// Progress to be reported when the workload of a Perses instance exceeded its progress deadline
+	ReasonRolloutStalled ConditionStatusReason = "RolloutStalled"
+	// Progress to be reported when every replica of the workload of a Perses instance runs its latest pod template and is available
+	ReasonRolloutComplete ConditionStatusReason = "RolloutComplete"
+	// Failure to be used when the health check of a Perses instance whose rollout is complete fails
+	ReasonAPIUnavailable ConditionStatusReason = "APIUnavailable"
+	// Failure to be used when a Perses instance requests a Route, HTTPRoute, ServiceMonitor or PodMonitor whose API is not served by the cluster
+	ReasonAPINotServed ConditionStatusReason = "APINotServed"
 )
 
 // IsClientError returns true if the error is an HTTP 4xx response from the
@@ -184,6 +239,70 @@ func PersesBecameAvailable(oldObj, newObj client.Object) bool {
 	return !wasAvailable && isAvailable
 }
 
+// IsReconcilePaused returns true when the reconciliation of the object is paused with the
+// perses.dev/reconcile annotation. An object being deleted is never paused, so that its
+// deletion is not blocked.
+func IsReconcilePaused(obj client.Object) bool {
+	return obj.GetDeletionTimestamp() == nil && obj.GetAnnotations()[PersesReconcileAnnotation] == PersesReconcilePaused
+}
+
+// IsDryRun returns true when the changes to the object are only planned, not applied,
+// either because the operator runs with --dry-run or because the object has the
+// perses.dev/dry-run annotation.
+func IsDryRun(obj client.Object, operatorDryRun bool) bool {
+	return operatorDryRun || obj.GetAnnotations()[PersesDryRunAnnotation] == PersesDryRunEnabled
+}
+
+// PersesResourceSelectorsChanged returns true when the resourceNamespaceSelector or the
+// resourceSelector of an available Perses instance changed, so that the resources it
+// now accepts or rejects are reconciled.
+func PersesResourceSelectorsChanged(oldObj, newObj client.Object) bool {
+	oldPerses, ok := oldObj.(*v1alpha2.Perses)
+	if !ok {
+		return false
+	}
+	newPerses, ok := newObj.(*v1alpha2.Perses)
+	if !ok {
+		return false
+	}
+	if !meta.IsStatusConditionTrue(newPerses.Status.Conditions, TypeAvailablePerses) {
+		return false
+	}
+	return !equality.Semantic.DeepEqual(oldPerses.Spec.ResourceNamespaceSelector, newPerses.Spec.ResourceNamespaceSelector) ||
+		!equality.Semantic.DeepEqual(oldPerses.Spec.ResourceSelector, newPerses.Spec.ResourceSelector)
+}
+
+// PersesLabelsChanged returns true when the labels of a Perses instance changed, so that
+// the resources whose instanceSelector now selects it or no longer selects it are reconciled.
+func PersesLabelsChanged(oldObj, newObj client.Object) bool {
+	if _, ok := newObj.(*v1alpha2.Perses); !ok {
+		return false
+	}
+	return !maps.Equal(oldObj.GetLabels(), newObj.GetLabels())
+}
+
+// PersesAdmissionPredicate returns a predicate that triggers reconciliation when a
+// Perses instance becomes available, changes its resource selectors or its labels. This is used by
+// the controllers of the resources the instance can reject (Dashboard, Datasource,
+// GlobalDatasource).
+func PersesAdmissionPredicate() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return PersesBecameAvailable(e.ObjectOld, e.ObjectNew) || PersesResourceSelectorsChanged(e.ObjectOld, e.ObjectNew) ||
+				PersesLabelsChanged(e.ObjectOld, e.ObjectNew)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
 // MetadataListToRequests lists all objects of the given GVK from the metadata-only
 // cache and returns a reconcile.Request for each one. It is intended for use in
 // mapper functions passed to handler.EnqueueRequestsFromMapFunc.
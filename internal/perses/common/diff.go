@@ -0,0 +1,288 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+
+	persesv1 "github.com/perses/perses/pkg/model/api/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// RedactedValue replaces the value of a sensitive field in a diff.
+	RedactedValue = "<redacted>"
+	// MaxEventDiffLength is the maximum length of a diff recorded in an event.
+	MaxEventDiffLength = 800
+)
+
+// sensitiveFieldPattern matches the names of the fields, and of the environment variables,
+// whose values are redacted from a diff.
+var sensitiveFieldPattern = regexp.MustCompile(`(?i)(password|passwd|secret|token|credential|api_?key|private_?key|encryption_?key|authorization|bearer|cookie)`)
+
+// Change is a changed value between the existing and the desired state of an object.
+// Old is missing for an added value, New is missing for a removed one.
+type Change struct {
+	Path string `json:"path"`
+	Old  any    `json:"old,omitempty"`
+	New  any    `json:"new,omitempty"`
+}
+
+// Diff returns the values that differ between the existing and the desired value, compared
+// through their JSON representation and prefixed with prefix, e.g. spec.display.name. The
+// values of sensitive fields are redacted, so that the diff can be logged and recorded.
+func Diff(prefix string, existing, desired any) []Change {
+	existingValue, existingErr := jsonValue(existing)
+	desiredValue, desiredErr := jsonValue(desired)
+	if existingErr != nil || desiredErr != nil {
+		if equality.Semantic.DeepEqual(existing, desired) {
+			return nil
+		}
+		return []Change{{Path: prefix, Old: RedactedValue, New: RedactedValue}}
+	}
+
+	var changes []Change
+	diffValues(prefix, existingValue, desiredValue, &changes)
+	return changes
+}
+
+// TagsDiff returns the change of the tags of an object in Perses, if any.
+func TagsDiff(existing, desired map[string]struct{}) []Change {
+	existingTags := sortedKeys(existing)
+	desiredTags := sortedKeys(desired)
+	if slices.Equal(existingTags, desiredTags) {
+		return nil
+	}
+	return []Change{{Path: "tags", Old: existingTags, New: desiredTags}}
+}
+
+// OwnershipClaimOnly returns true if the only change is the ManagedTag added to the tags,
+// as for the objects written by an earlier version of the operator. Writing it is not a drift.
+func OwnershipClaimOnly(changes []Change) bool {
+	if len(changes) != 1 || changes[0].Path != "tags" {
+		return false
+	}
+	existingTags, _ := changes[0].Old.([]string)
+	desiredTags, _ := changes[0].New.([]string)
+	return !slices.Contains(existingTags, ManagedTag) &&
+		slices.Equal(existingTags, slices.DeleteFunc(slices.Clone(desiredTags), func(tag string) bool { return tag == ManagedTag }))
+}
+
+// DashboardDiff returns the changes between the existing dashboard in Perses and the desired one.
+func DashboardDiff(existing, desired *persesv1.Dashboard) []Change {
+	return append(TagsDiff(existing.Metadata.Tags, desired.Metadata.Tags), Diff("spec", existing.Spec, desired.Spec)...)
+}
+
+// DatasourceDiff returns the changes between the existing datasource in Perses and the desired one.
+func DatasourceDiff(existing, desired *persesv1.Datasource) []Change {
+	return append(TagsDiff(existing.Metadata.Tags, desired.Metadata.Tags), Diff("spec", existing.Spec, desired.Spec)...)
+}
+
+// GlobalDatasourceDiff returns the changes between the existing global datasource in Perses and the desired one.
+func GlobalDatasourceDiff(existing, desired *persesv1.GlobalDatasource) []Change {
+	return append(TagsDiff(existing.Metadata.Tags, desired.Metadata.Tags), Diff("spec", existing.Spec, desired.Spec)...)
+}
+
+// VariableDiff returns the changes between the existing variable in Perses and the desired one.
+func VariableDiff(existing, desired *persesv1.Variable) []Change {
+	return append(TagsDiff(existing.Metadata.Tags, desired.Metadata.Tags), Diff("spec", existing.Spec, desired.Spec)...)
+}
+
+// GlobalVariableDiff returns the changes between the existing global variable in Perses and the desired one.
+func GlobalVariableDiff(existing, desired *persesv1.GlobalVariable) []Change {
+	return append(TagsDiff(existing.Metadata.Tags, desired.Metadata.Tags), Diff("spec", existing.Spec, desired.Spec)...)
+}
+
+// RoleDiff returns the changes between the existing role in Perses and the desired one.
+func RoleDiff(existing, desired *persesv1.Role) []Change {
+	return append(TagsDiff(existing.Metadata.Tags, desired.Metadata.Tags), Diff("spec", existing.Spec, desired.Spec)...)
+}
+
+// RoleBindingDiff returns the changes between the existing role binding in Perses and the desired one.
+func RoleBindingDiff(existing, desired *persesv1.RoleBinding) []Change {
+	return append(TagsDiff(existing.Metadata.Tags, desired.Metadata.Tags), Diff("spec", existing.Spec, desired.Spec)...)
+}
+
+// GlobalRoleDiff returns the changes between the existing global role in Perses and the desired one.
+func GlobalRoleDiff(existing, desired *persesv1.GlobalRole) []Change {
+	return append(TagsDiff(existing.Metadata.Tags, desired.Metadata.Tags), Diff("spec", existing.Spec, desired.Spec)...)
+}
+
+// GlobalRoleBindingDiff returns the changes between the existing global role binding in Perses and the desired one.
+func GlobalRoleBindingDiff(existing, desired *persesv1.GlobalRoleBinding) []Change {
+	return append(TagsDiff(existing.Metadata.Tags, desired.Metadata.Tags), Diff("spec", existing.Spec, desired.Spec)...)
+}
+
+// ConfigMapDataDiff returns the changes of the data of a ConfigMap. The YAML and JSON
+// documents it holds are compared field by field, so that their sensitive fields are
+// redacted like any other.
+func ConfigMapDataDiff(existing, desired map[string]string) []Change {
+	return Diff("data", parseDocuments(existing), parseDocuments(desired))
+}
+
+// FormatDiff returns the changes as JSON, truncated to maxLength when maxLength is positive.
+func FormatDiff(changes []Change, maxLength int) string {
+	if len(changes) == 0 {
+		return "[]"
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return fmt.Sprintf("%d changes", len(changes))
+	}
+	if maxLength <= 0 || len(data) <= maxLength {
+		return string(data)
+	}
+	return fmt.Sprintf("%s... (truncated, %d changes)", data[:maxLength], len(changes))
+}
+
+func diffValues(path string, existing, desired any, changes *[]Change) {
+	existingMap, existingIsMap := existing.(map[string]any)
+	desiredMap, desiredIsMap := desired.(map[string]any)
+	if existingIsMap && desiredIsMap {
+		keys := sortedKeys(existingMap)
+		for key := range desiredMap {
+			if _, ok := existingMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			if isSensitiveField(existingMap, key) || isSensitiveField(desiredMap, key) {
+				if !equality.Semantic.DeepEqual(existingMap[key], desiredMap[key]) {
+					*changes = append(*changes, Change{Path: childPath, Old: redactPresent(existingMap, key), New: redactPresent(desiredMap, key)})
+				}
+				continue
+			}
+			diffValues(childPath, existingMap[key], desiredMap[key], changes)
+		}
+		return
+	}
+
+	existingSlice, existingIsSlice := existing.([]any)
+	desiredSlice, desiredIsSlice := desired.([]any)
+	if existingIsSlice && desiredIsSlice {
+		for i := range max(len(existingSlice), len(desiredSlice)) {
+			var existingItem, desiredItem any
+			if i < len(existingSlice) {
+				existingItem = existingSlice[i]
+			}
+			if i < len(desiredSlice) {
+				desiredItem = desiredSlice[i]
+			}
+			diffValues(fmt.Sprintf("%s[%d]", path, i), existingItem, desiredItem, changes)
+		}
+		return
+	}
+
+	if !equality.Semantic.DeepEqual(existing, desired) {
+		*changes = append(*changes, Change{Path: path, Old: redact(existing), New: redact(desired)})
+	}
+}
+
+// isSensitiveField returns true for a field with a sensitive name, and for the value
+// of a name/value pair with a sensitive name, such as an environment variable.
+func isSensitiveField(parent map[string]any, key string) bool {
+	if sensitiveFieldPattern.MatchString(key) {
+		return true
+	}
+	name, ok := parent["name"].(string)
+	return key == "value" && ok && sensitiveFieldPattern.MatchString(name)
+}
+
+func redactPresent(parent map[string]any, key string) any {
+	if _, ok := parent[key]; !ok {
+		return nil
+	}
+	return RedactedValue
+}
+
+// redact returns a copy of the value without its sensitive fields, and without
+// the passwords of the URLs it holds.
+func redact(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		redacted := make(map[string]any, len(v))
+		for key, item := range v {
+			if isSensitiveField(v, key) {
+				redacted[key] = RedactedValue
+				continue
+			}
+			redacted[key] = redact(item)
+		}
+		return redacted
+	case []any:
+		redacted := make([]any, len(v))
+		for i, item := range v {
+			redacted[i] = redact(item)
+		}
+		return redacted
+	case string:
+		if u, err := url.Parse(v); err == nil && u.User != nil {
+			return u.Redacted()
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func jsonValue(value any) (any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+// parseDocuments decodes the YAML and JSON documents of the data of a ConfigMap.
+// A value that is not a document is kept as is.
+func parseDocuments(data map[string]string) map[string]any {
+	if data == nil {
+		return nil
+	}
+	documents := make(map[string]any, len(data))
+	for key, value := range data {
+		var document any
+		if err := yaml.Unmarshal([]byte(value), &document); err == nil {
+			if _, isObject := document.(map[string]any); isObject {
+				documents[key] = document
+				continue
+			}
+		}
+		documents[key] = value
+	}
+	return documents
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
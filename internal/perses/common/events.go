@@ -0,0 +1,45 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
+)
+
+// Reasons of the events recorded for the objects created, updated or deleted by the operator.
+// Failures are recorded with the ConditionStatusReason of the failure.
+const (
+	EventReasonCreated = "Created"
+	EventReasonUpdated = "Updated"
+	EventReasonDeleted = "Deleted"
+)
+
+// RecordEvent records an event on the given object. It does nothing when no recorder is set.
+// Identical events are aggregated by the recorder into a single event with a count.
+func RecordEvent(recorder record.EventRecorder, obj runtime.Object, eventtype string, reason string, messageFmt string, args ...any) {
+	if recorder == nil {
+		return
+	}
+	recorder.Eventf(obj, eventtype, reason, messageFmt, args...)
+}
+
+// RecordInstanceEvent records an event on the given object about one of the Perses instances it is synced to.
+func RecordInstanceEvent(recorder record.EventRecorder, obj runtime.Object, perses v1alpha2.Perses, eventtype string, reason string, messageFmt string, args ...any) {
+	RecordEvent(recorder, obj, eventtype, reason, "Perses instance %s/%s: %s", perses.Namespace, perses.Name, fmt.Sprintf(messageFmt, args...))
+}
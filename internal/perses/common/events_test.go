@@ -0,0 +1,50 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
+)
+
+func TestRecordEvent(t *testing.T) {
+	dashboard := &v1alpha2.PersesDashboard{ObjectMeta: metav1.ObjectMeta{Name: "dashboard", Namespace: "default"}}
+
+	t.Run("nil recorder", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			RecordEvent(nil, dashboard, corev1.EventTypeNormal, EventReasonCreated, "Dashboard created")
+		})
+	})
+
+	t.Run("event", func(t *testing.T) {
+		recorder := record.NewFakeRecorder(1)
+		RecordEvent(recorder, dashboard, corev1.EventTypeWarning, string(ReasonBackendError), "Failed to sync dashboard: %s", "internal error")
+		assert.Equal(t, "Warning PersesBackendError Failed to sync dashboard: internal error", <-recorder.Events)
+	})
+}
+
+func TestRecordInstanceEvent(t *testing.T) {
+	dashboard := &v1alpha2.PersesDashboard{ObjectMeta: metav1.ObjectMeta{Name: "dashboard", Namespace: "default"}}
+	perses := v1alpha2.Perses{ObjectMeta: metav1.ObjectMeta{Name: "perses", Namespace: "monitoring"}}
+
+	recorder := record.NewFakeRecorder(1)
+	RecordInstanceEvent(recorder, dashboard, perses, corev1.EventTypeNormal, EventReasonDeleted, "Dashboard %s deleted", dashboard.Name)
+	assert.Equal(t, "Normal Deleted Perses instance monitoring/perses: Dashboard dashboard deleted", <-recorder.Events)
+}
@@ -0,0 +1,63 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"net/url"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
+)
+
+// ServiceName returns the name of the Service of the Perses instance.
+func ServiceName(perses *v1alpha2.Perses) string {
+	if perses.Spec.Service != nil && perses.Spec.Service.Name != nil && len(*perses.Spec.Service.Name) > 0 {
+		return *perses.Spec.Service.Name
+	}
+	return perses.Name
+}
+
+// ServicePort returns the port of the Service of the Perses instance, which is its container port.
+func ServicePort(perses *v1alpha2.Perses) int32 {
+	if perses.Spec.ContainerPort != nil {
+		return *perses.Spec.ContainerPort
+	}
+	return DefaultContainerPort
+}
+
+// ExposedPath returns the path an Ingress, Route or HTTPRoute exposes the Perses instance on:
+// the given path when set, otherwise the API prefix of the instance, otherwise /.
+func ExposedPath(perses *v1alpha2.Perses, path *string) string {
+	if path != nil && len(*path) > 0 {
+		return *path
+	}
+	if prefix := perses.Spec.Config.APIPrefix; len(prefix) > 0 {
+		if prefix[0] != '/' {
+			return "/" + prefix
+		}
+		return prefix
+	}
+	return "/"
+}
+
+// ExternalURL returns the URL of a Perses instance exposed on the given host and path.
+func ExternalURL(https bool, host string, path string) string {
+	u := url.URL{Scheme: "http", Host: host, Path: path}
+	if https {
+		u.Scheme = "https"
+	}
+	if u.Path == "/" {
+		u.Path = ""
+	}
+	return u.String()
+}
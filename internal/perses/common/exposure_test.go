@@ -0,0 +1,66 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	persesconfig "github.com/perses/perses/pkg/model/api/config"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/ptr"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
+)
+
+func TestServiceName(t *testing.T) {
+	perses := &v1alpha2.Perses{}
+	perses.Name = "perses"
+	assert.Equal(t, "perses", ServiceName(perses))
+
+	perses.Spec.Service = &v1alpha2.PersesService{Name: ptr.To("")}
+	assert.Equal(t, "perses", ServiceName(perses))
+
+	perses.Spec.Service.Name = ptr.To("perses-http")
+	assert.Equal(t, "perses-http", ServiceName(perses))
+}
+
+func TestExposedPath(t *testing.T) {
+	tests := []struct {
+		name      string
+		apiPrefix string
+		path      *string
+		expect    string
+	}{
+		{name: "default", expect: "/"},
+		{name: "api prefix", apiPrefix: "/perses", expect: "/perses"},
+		{name: "api prefix without leading slash", apiPrefix: "perses", expect: "/perses"},
+		{name: "path overrides api prefix", apiPrefix: "/perses", path: ptr.To("/dashboards"), expect: "/dashboards"},
+		{name: "empty path", apiPrefix: "/perses", path: ptr.To(""), expect: "/perses"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			perses := &v1alpha2.Perses{Spec: v1alpha2.PersesSpec{
+				Config: v1alpha2.PersesConfig{Config: persesconfig.Config{APIPrefix: tt.apiPrefix}},
+			}}
+			assert.Equal(t, tt.expect, ExposedPath(perses, tt.path))
+		})
+	}
+}
+
+func TestExternalURL(t *testing.T) {
+	assert.Equal(t, "http://perses.example.com", ExternalURL(false, "perses.example.com", "/"))
+	assert.Equal(t, "http://perses.example.com", ExternalURL(false, "perses.example.com", ""))
+	assert.Equal(t, "https://perses.example.com/perses", ExternalURL(true, "perses.example.com", "/perses"))
+}
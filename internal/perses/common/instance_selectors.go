@@ -0,0 +1,118 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
+)
+
+type instanceSelectorEntry struct {
+	selector labels.Selector
+	synced   []v1alpha2.PersesInstanceReference
+}
+
+// InstanceSelectorIndex holds the instanceSelector and the synced instances of the resources
+// reconciled by a controller, which their metadata-only cache does not hold, so that a change
+// of a Perses instance only enqueues the resources it concerns. A nil index selects every
+// Perses instance.
+type InstanceSelectorIndex struct {
+	mtx     sync.RWMutex
+	entries map[types.NamespacedName]instanceSelectorEntry
+}
+
+func NewInstanceSelectorIndex() *InstanceSelectorIndex {
+	return &InstanceSelectorIndex{
+		entries: make(map[types.NamespacedName]instanceSelectorEntry),
+	}
+}
+
+// Set records the instance selector of a resource and the Perses instances it is synced to.
+func (i *InstanceSelectorIndex) Set(key types.NamespacedName, selector labels.Selector, synced []v1alpha2.PersesInstanceReference) {
+	if i == nil {
+		return
+	}
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	i.entries[key] = instanceSelectorEntry{selector: selector, synced: slices.Clone(synced)}
+}
+
+// Forget removes a resource that no longer exists from the index.
+func (i *InstanceSelectorIndex) Forget(key types.NamespacedName) {
+	if i == nil {
+		return
+	}
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	delete(i.entries, key)
+}
+
+// Concerns reports whether a change of the Perses instance, whose labels were oldLabels
+// and are now newLabels, concerns the resource: the resource selects the instance before
+// or after the change, or is synced to it. A resource not reconciled yet is always concerned.
+func (i *InstanceSelectorIndex) Concerns(key types.NamespacedName, perses v1alpha2.Perses, oldLabels, newLabels labels.Set) bool {
+	if i == nil {
+		return true
+	}
+	i.mtx.RLock()
+	defer i.mtx.RUnlock()
+	entry, ok := i.entries[key]
+	if !ok {
+		return true
+	}
+	return entry.selector.Matches(oldLabels) || entry.selector.Matches(newLabels) ||
+		HasInstanceReference(entry.synced, InstanceReference(perses))
+}
+
+// EnqueueConcernedResources returns an event handler enqueuing the resources of the given
+// list kind concerned by the update of a Perses instance, see InstanceSelectorIndex.Concerns.
+func EnqueueConcernedResources(r client.Reader, gvk schema.GroupVersionKind, index *InstanceSelectorIndex) handler.EventHandler {
+	return handler.Funcs{
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			perses, ok := e.ObjectNew.(*v1alpha2.Perses)
+			if !ok {
+				return
+			}
+			for _, req := range MetadataListToRequests(ctx, r, gvk) {
+				if index.Concerns(req.NamespacedName, *perses, e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels()) {
+					q.Add(req)
+				}
+			}
+		},
+	}
+}
+
+// SyncedToInstance reports whether a dashboard, datasource or global datasource is synced to
+// the Perses instance: its instanceSelector selects the instance, and the resource selectors
+// of the instance accept it, see InstanceAllowsResource.
+func SyncedToInstance(ctx context.Context, reader client.Reader, instanceSelector *metav1.LabelSelector, perses v1alpha2.Perses, obj client.Object) (bool, error) {
+	selected, err := SelectsInstance(instanceSelector, perses)
+	if err != nil || !selected {
+		return false, err
+	}
+	return InstanceAllowsResource(ctx, reader, perses, obj)
+}
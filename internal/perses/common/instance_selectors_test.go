@@ -0,0 +1,67 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
+)
+
+func TestInstanceSelectorIndex(t *testing.T) {
+	perses := v1alpha2.Perses{ObjectMeta: metav1.ObjectMeta{Name: "perses", Namespace: "monitoring"}}
+	prod := labels.Set{"env": "prod"}
+	staging := labels.Set{"env": "staging"}
+	dashboard := types.NamespacedName{Namespace: "default", Name: "overview"}
+	index := NewInstanceSelectorIndex()
+
+	assert.True(t, index.Concerns(dashboard, perses, staging, staging), "a resource not reconciled yet is always concerned")
+
+	index.Set(dashboard, labels.SelectorFromSet(prod), nil)
+	assert.True(t, index.Concerns(dashboard, perses, prod, prod))
+	assert.False(t, index.Concerns(dashboard, perses, staging, staging))
+	assert.True(t, index.Concerns(dashboard, perses, staging, prod), "an instance relabeled to match the selector gets the resource")
+	assert.True(t, index.Concerns(dashboard, perses, prod, staging), "an instance relabeled away from the selector loses the resource")
+
+	index.Set(dashboard, labels.SelectorFromSet(prod), []v1alpha2.PersesInstanceReference{InstanceReference(perses)})
+	assert.True(t, index.Concerns(dashboard, perses, staging, staging), "an instance the resource is synced to loses it")
+
+	index.Set(dashboard, labels.Everything(), nil)
+	assert.True(t, index.Concerns(dashboard, perses, staging, staging))
+
+	index.Set(dashboard, labels.SelectorFromSet(prod), nil)
+	index.Forget(dashboard)
+	assert.True(t, index.Concerns(dashboard, perses, staging, staging))
+
+	var disabled *InstanceSelectorIndex
+	disabled.Set(dashboard, labels.SelectorFromSet(prod), nil)
+	assert.True(t, disabled.Concerns(dashboard, perses, staging, staging))
+}
+
+func TestPersesLabelsChanged(t *testing.T) {
+	oldPerses := &v1alpha2.Perses{ObjectMeta: metav1.ObjectMeta{Name: "perses", Labels: map[string]string{"env": "prod"}}}
+	newPerses := oldPerses.DeepCopy()
+	assert.False(t, PersesLabelsChanged(oldPerses, newPerses))
+
+	newPerses.Labels["env"] = "staging"
+	assert.True(t, PersesLabelsChanged(oldPerses, newPerses))
+
+	newPerses.Labels = nil
+	assert.True(t, PersesLabelsChanged(oldPerses, newPerses))
+}
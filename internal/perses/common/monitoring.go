@@ -0,0 +1,82 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"path"
+
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
+)
+
+// MonitorKind returns the kind of the monitor scraping the Perses instance, a ServiceMonitor
+// unless spec.monitoring.kind says otherwise.
+func MonitorKind(perses *v1alpha2.Perses) v1alpha2.MonitorKind {
+	if perses.Spec.Monitoring != nil && perses.Spec.Monitoring.Kind != nil {
+		return *perses.Spec.Monitoring.Kind
+	}
+	return v1alpha2.MonitorKindServiceMonitor
+}
+
+// MetricsPath returns the path the Perses instance serves its metrics on, under its API prefix.
+func MetricsPath(perses *v1alpha2.Perses) string {
+	return path.Join("/", perses.Spec.Config.APIPrefix, "metrics")
+}
+
+// MetricsScheme returns the scheme the metrics of the Perses instance are scraped with.
+func MetricsScheme(perses *v1alpha2.Perses) monitoringv1.Scheme {
+	if isTLSEnabled(perses) {
+		return monitoringv1.SchemeHTTPS
+	}
+	return monitoringv1.SchemeHTTP
+}
+
+// MetricsTLSConfig returns the TLS configuration used to scrape the metrics of the Perses instance,
+// or nil when TLS is not enabled. The certificate of Perses is verified with the CA certificate of
+// spec.tls when it is stored in a Secret or ConfigMap, which must be in the namespace of the instance.
+func MetricsTLSConfig(perses *v1alpha2.Perses) *monitoringv1.SafeTLSConfig {
+	if !isTLSEnabled(perses) {
+		return nil
+	}
+
+	tlsConfig := &monitoringv1.SafeTLSConfig{
+		InsecureSkipVerify: perses.Spec.TLS.InsecureSkipVerify,
+	}
+
+	serverName := fmt.Sprintf("%s.%s.svc", ServiceName(perses), perses.Namespace)
+	if perses.Spec.Monitoring != nil && perses.Spec.Monitoring.ServerName != nil {
+		serverName = *perses.Spec.Monitoring.ServerName
+	}
+	tlsConfig.ServerName = &serverName
+
+	if ca := perses.Spec.TLS.CaCert; ca != nil && ca.Name != nil {
+		switch ca.Type {
+		case v1alpha2.SecretSourceTypeSecret:
+			tlsConfig.CA.Secret = &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: *ca.Name},
+				Key:                  ca.CertPath,
+			}
+		case v1alpha2.SecretSourceTypeConfigMap:
+			tlsConfig.CA.ConfigMap = &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: *ca.Name},
+				Key:                  ca.CertPath,
+			}
+		}
+	}
+
+	return tlsConfig
+}
@@ -0,0 +1,84 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	persesconfig "github.com/perses/perses/pkg/model/api/config"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
+)
+
+func TestMetricsPath(t *testing.T) {
+	tests := []struct {
+		name      string
+		apiPrefix string
+		expect    string
+	}{
+		{name: "no api prefix", expect: "/metrics"},
+		{name: "api prefix without leading slash", apiPrefix: "perses", expect: "/perses/metrics"},
+		{name: "api prefix", apiPrefix: "/perses", expect: "/perses/metrics"},
+		{name: "api prefix with trailing slash", apiPrefix: "/perses/", expect: "/perses/metrics"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			perses := &v1alpha2.Perses{Spec: v1alpha2.PersesSpec{
+				Config: v1alpha2.PersesConfig{Config: persesconfig.Config{APIPrefix: tt.apiPrefix}},
+			}}
+			assert.Equal(t, tt.expect, MetricsPath(perses))
+		})
+	}
+}
+
+func TestMetricsTLSConfig(t *testing.T) {
+	perses := &v1alpha2.Perses{ObjectMeta: metav1.ObjectMeta{Name: "perses", Namespace: "monitoring"}}
+	assert.Nil(t, MetricsTLSConfig(perses))
+	assert.Equal(t, monitoringv1.SchemeHTTP, MetricsScheme(perses))
+
+	perses.Spec.TLS = &v1alpha2.TLS{Enable: ptr.To(false)}
+	assert.Nil(t, MetricsTLSConfig(perses), "TLS must be enabled to scrape over HTTPS")
+
+	perses.Spec.TLS = &v1alpha2.TLS{
+		Enable:             ptr.To(true),
+		InsecureSkipVerify: ptr.To(true),
+		CaCert: &v1alpha2.Certificate{
+			SecretSource: v1alpha2.SecretSource{Type: v1alpha2.SecretSourceTypeConfigMap, Name: ptr.To("perses-ca"), Namespace: ptr.To("monitoring")},
+			CertPath:     "ca.crt",
+		},
+	}
+	assert.Equal(t, monitoringv1.SchemeHTTPS, MetricsScheme(perses))
+	tlsConfig := MetricsTLSConfig(perses)
+	require.NotNil(t, tlsConfig)
+	assert.Equal(t, ptr.To(true), tlsConfig.InsecureSkipVerify)
+	assert.Equal(t, ptr.To("perses.monitoring.svc"), tlsConfig.ServerName)
+	assert.Nil(t, tlsConfig.CA.Secret)
+	assert.Equal(t, &corev1.ConfigMapKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "perses-ca"},
+		Key:                  "ca.crt",
+	}, tlsConfig.CA.ConfigMap)
+
+	perses.Spec.Monitoring = &v1alpha2.PersesMonitoring{ServerName: ptr.To("perses.example.com")}
+	perses.Spec.TLS.CaCert.Type = v1alpha2.SecretSourceTypeFile
+	tlsConfig = MetricsTLSConfig(perses)
+	assert.Equal(t, ptr.To("perses.example.com"), tlsConfig.ServerName)
+	assert.Equal(t, monitoringv1.SecretOrConfigMap{}, tlsConfig.CA, "a CA certificate read from a file cannot be referenced by the monitor")
+}
@@ -191,25 +191,28 @@ func (f *PersesClientFactoryWithConfig) CreateClient(ctx context.Context, client
 	return newClient, nil
 }
 
-func (f *PersesClientFactoryWithConfig) buildClient(ctx context.Context, client client.Reader, perses persesv1alpha2.Perses) (v1.ClientInterface, error) {
-	var urlStr string
+// InstanceURL returns the address the operator uses to reach a Perses instance:
+// the --perses-server-url flag when set, the in-cluster service address otherwise.
+func InstanceURL(perses persesv1alpha2.Perses) string {
+	serverURLFlag := flag.Lookup(PersesServerURLFlag)
+	if serverURLFlag != nil && serverURLFlag.Value.String() != "" {
+		return serverURLFlag.Value.String()
+	}
 
 	httpProtocol := "http"
 	if isTLSEnabled(&perses) {
 		httpProtocol = "https"
 	}
 
-	serverURLFlag := flag.Lookup(PersesServerURLFlag)
-	if serverURLFlag != nil && serverURLFlag.Value.String() != "" {
-		urlStr = serverURLFlag.Value.String()
-	} else {
-		containerPort := DefaultContainerPort
-		if perses.Spec.ContainerPort != nil {
-			containerPort = *perses.Spec.ContainerPort
-		}
-		urlStr = fmt.Sprintf("%s://%s.%s.svc.cluster.local:%d%s", httpProtocol, perses.Name, perses.Namespace, containerPort, perses.Spec.Config.APIPrefix)
+	containerPort := DefaultContainerPort
+	if perses.Spec.ContainerPort != nil {
+		containerPort = *perses.Spec.ContainerPort
 	}
-	parsedURL, err := speccommon.ParseURL(urlStr)
+	return fmt.Sprintf("%s://%s.%s.svc.cluster.local:%d%s", httpProtocol, perses.Name, perses.Namespace, containerPort, perses.Spec.Config.APIPrefix)
+}
+
+func (f *PersesClientFactoryWithConfig) buildClient(ctx context.Context, client client.Reader, perses persesv1alpha2.Perses) (v1.ClientInterface, error) {
+	parsedURL, err := speccommon.ParseURL(InstanceURL(perses))
 	if err != nil {
 		return nil, err
 	}
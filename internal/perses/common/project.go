@@ -0,0 +1,346 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	v1 "github.com/perses/perses/pkg/client/api/v1"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	persesv1 "github.com/perses/perses/pkg/model/api/v1"
+	persesv1Common "github.com/perses/perses/pkg/model/api/v1/common"
+	logger "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
+)
+
+var plog = logger.WithField("module", "project")
+
+// KeepProjectAnnotation set to "true" on a namespace keeps its Perses project
+// when the last resource synced to it is removed, see CleanupProject.
+const KeepProjectAnnotation = PersesNamespaceDomain + "/keep-project"
+
+// ProjectForNamespace returns the PersesProject in charge of the given namespace,
+// or nil when the namespace has none. When several PersesProjects exist in the
+// same namespace, the oldest one wins so the choice stays stable across reconciliations.
+func ProjectForNamespace(ctx context.Context, reader client.Reader, namespace string) (*v1alpha2.PersesProject, error) {
+	projects := &v1alpha2.PersesProjectList{}
+	if err := reader.List(ctx, projects, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+
+	candidates := make([]v1alpha2.PersesProject, 0, len(projects.Items))
+	for _, p := range projects.Items {
+		if p.DeletionTimestamp == nil {
+			candidates = append(candidates, p)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		ti, tj := candidates[i].CreationTimestamp, candidates[j].CreationTimestamp
+		if !ti.Equal(&tj) {
+			return ti.Before(&tj)
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+
+	return &candidates[0], nil
+}
+
+// ProjectSelectsInstance returns true if the PersesProject's instanceSelector
+// matches the labels of the given Perses instance. A nil selector matches every instance.
+func ProjectSelectsInstance(project *v1alpha2.PersesProject, perses v1alpha2.Perses) (bool, error) {
+	return SelectsInstance(project.Spec.InstanceSelector, perses)
+}
+
+// SelectsInstance returns true if the given instanceSelector matches the labels
+// of the given Perses instance. A nil selector matches every instance.
+func SelectsInstance(instanceSelector *metav1.LabelSelector, perses v1alpha2.Perses) (bool, error) {
+	if instanceSelector == nil {
+		return true, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(instanceSelector)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(perses.Labels)), nil
+}
+
+// InstanceAllowsResource returns true if the Perses instance accepts the given dashboard,
+// datasource or global datasource according to its resourceSelector and, for namespaced
+// resources, its resourceNamespaceSelector. Nil selectors accept every resource.
+func InstanceAllowsResource(ctx context.Context, reader client.Reader, perses v1alpha2.Perses, obj client.Object) (bool, error) {
+	if perses.Spec.ResourceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(perses.Spec.ResourceSelector)
+		if err != nil {
+			return false, fmt.Errorf("invalid resourceSelector in Perses instance %s/%s: %w", perses.Namespace, perses.Name, err)
+		}
+		if !selector.Matches(labels.Set(obj.GetLabels())) {
+			return false, nil
+		}
+	}
+
+	// Global datasources are cluster-scoped, the namespace selector does not apply to them.
+	if perses.Spec.ResourceNamespaceSelector == nil || obj.GetNamespace() == "" {
+		return true, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(perses.Spec.ResourceNamespaceSelector)
+	if err != nil {
+		return false, fmt.Errorf("invalid resourceNamespaceSelector in Perses instance %s/%s: %w", perses.Namespace, perses.Name, err)
+	}
+	ns := &corev1.Namespace{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: obj.GetNamespace()}, ns); err != nil {
+		return false, fmt.Errorf("failed to get namespace %s: %w", obj.GetNamespace(), err)
+	}
+	return selector.Matches(labels.Set(ns.Labels)), nil
+}
+
+// DesiredProject builds the Perses project for the given namespace.
+// The display name and description come from the PersesProject when one is given,
+// otherwise the project is displayed with the namespace name.
+func DesiredProject(namespace string, project *v1alpha2.PersesProject) *persesv1.Project {
+	display := &persesv1Common.Display{
+		Name: namespace,
+	}
+	if project != nil {
+		if project.Spec.DisplayName != "" {
+			display.Name = project.Spec.DisplayName
+		}
+		display.Description = project.Spec.Description
+	}
+
+	return &persesv1.Project{
+		Kind: persesv1.KindProject,
+		Metadata: persesv1.Metadata{
+			Name: namespace,
+		},
+		Spec: persesv1.ProjectSpec{
+			Display: display,
+		},
+	}
+}
+
+// EnsureProject makes sure the Perses project for the given namespace exists in the
+// Perses instance before project-scoped resources are pushed to it.
+// When a PersesProject selecting this instance exists in the namespace, the project is
+// created with its display settings; keeping them up to date is left to the PersesProject controller.
+func EnsureProject(ctx context.Context, reader client.Reader, persesClient v1.ClientInterface, perses v1alpha2.Perses, namespace string) (ConditionStatusReason, error) {
+	_, err := persesClient.Project().Get(namespace)
+	if err == nil {
+		return "", nil
+	}
+	if !errors.Is(err, perseshttp.RequestNotFoundError) {
+		plog.WithError(err).Errorf("project error: %s", namespace)
+		return ReasonBackendError, err
+	}
+
+	project, err := ProjectForNamespace(ctx, reader, namespace)
+	if err != nil {
+		plog.WithError(err).Errorf("Failed to list perses projects in namespace: %s", namespace)
+		return ReasonMissingResource, fmt.Errorf("failed to list PersesProjects in namespace %s: %w", namespace, err)
+	}
+	if project != nil {
+		selected, err := ProjectSelectsInstance(project, perses)
+		if err != nil {
+			return ReasonInvalidConfiguration, err
+		}
+		if !selected {
+			project = nil
+		}
+	}
+
+	desired := DesiredProject(namespace, project)
+	if project == nil {
+		// Tagged so that the project is removed with the last resource synced to it, see CleanupProject.
+		desired.Metadata.Tags = WithManagedTag(nil)
+	}
+	if _, err := persesClient.Project().Create(desired); err != nil {
+		plog.WithError(err).Errorf("Failed to create perses project: %s", namespace)
+		return ReasonBackendError, err
+	}
+
+	plog.Infof("Project created: %s", namespace)
+	return "", nil
+}
+
+// CleanupProject removes the Perses project of the given namespace from the Perses instance
+// once the last resource synced to it is gone. Only the projects created by the operator, which
+// carry the ManagedTag, are removed, and never when:
+//   - the namespace carries the perses.dev/keep-project: "true" annotation,
+//   - a custom resource of the namespace still selects the instance, including a PersesProject,
+//   - the project still holds a dashboard, datasource, secret or variable, e.g. one created from the Perses UI.
+func CleanupProject(ctx context.Context, reader client.Reader, persesClient v1.ClientInterface, perses v1alpha2.Perses, namespace string) error {
+	project, err := persesClient.Project().Get(namespace)
+	if err != nil {
+		if errors.Is(err, perseshttp.RequestNotFoundError) {
+			return nil
+		}
+		return fmt.Errorf("failed to get project %s: %w", namespace, err)
+	}
+	if project == nil || !IsManaged(project.Metadata.Tags) {
+		return nil
+	}
+
+	ns := &corev1.Namespace{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get namespace %s: %w", namespace, err)
+		}
+	} else if ns.Annotations[KeepProjectAnnotation] == "true" {
+		plog.Debugf("Namespace %s opted out of project cleanup", namespace)
+		return nil
+	}
+
+	referenced, err := projectReferenced(ctx, reader, perses, namespace)
+	if err != nil || referenced {
+		return err
+	}
+
+	empty, err := projectEmpty(persesClient, namespace)
+	if err != nil || !empty {
+		return err
+	}
+
+	if err := persesClient.Project().Delete(namespace); err != nil && !errors.Is(err, perseshttp.RequestNotFoundError) {
+		return fmt.Errorf("failed to delete project %s: %w", namespace, err)
+	}
+	plog.Infof("Project removed from Perses instance %s/%s: %s", perses.Namespace, perses.Name, namespace)
+	return nil
+}
+
+// projectReferenced reports whether a custom resource of the namespace, not being deleted,
+// is synced to the Perses instance. The dashboards and datasources the resource selectors of
+// the instance reject are not synced to it, see SyncedToInstance. A custom resource whose
+// selection cannot be decided, e.g. with an invalid instanceSelector, is considered as synced
+// to every instance, so that its project is kept.
+func projectReferenced(ctx context.Context, reader client.Reader, perses v1alpha2.Perses, namespace string) (bool, error) {
+	var selectors []*metav1.LabelSelector
+	add := func(deletionTimestamp *metav1.Time, selector *metav1.LabelSelector) {
+		if deletionTimestamp == nil {
+			selectors = append(selectors, selector)
+		}
+	}
+	// The dashboards and datasources are also subject to the resource selectors of the instance.
+	type resource struct {
+		obj      client.Object
+		selector *metav1.LabelSelector
+	}
+	var resources []resource
+	addResource := func(obj client.Object, selector *metav1.LabelSelector) {
+		if obj.GetDeletionTimestamp() == nil {
+			resources = append(resources, resource{obj: obj, selector: selector})
+		}
+	}
+
+	dashboards := &v1alpha2.PersesDashboardList{}
+	if err := reader.List(ctx, dashboards, client.InNamespace(namespace)); err != nil {
+		return false, fmt.Errorf("failed to list PersesDashboards in namespace %s: %w", namespace, err)
+	}
+	for i := range dashboards.Items {
+		addResource(&dashboards.Items[i], dashboards.Items[i].Spec.InstanceSelector)
+	}
+
+	datasources := &v1alpha2.PersesDatasourceList{}
+	if err := reader.List(ctx, datasources, client.InNamespace(namespace)); err != nil {
+		return false, fmt.Errorf("failed to list PersesDatasources in namespace %s: %w", namespace, err)
+	}
+	for i := range datasources.Items {
+		addResource(&datasources.Items[i], datasources.Items[i].Spec.InstanceSelector)
+	}
+
+	secrets := &v1alpha2.PersesSecretList{}
+	if err := reader.List(ctx, secrets, client.InNamespace(namespace)); err != nil {
+		return false, fmt.Errorf("failed to list PersesSecrets in namespace %s: %w", namespace, err)
+	}
+	for _, secret := range secrets.Items {
+		add(secret.DeletionTimestamp, secret.Spec.InstanceSelector)
+	}
+
+	variables := &v1alpha2.PersesVariableList{}
+	if err := reader.List(ctx, variables, client.InNamespace(namespace)); err != nil {
+		return false, fmt.Errorf("failed to list PersesVariables in namespace %s: %w", namespace, err)
+	}
+	for _, variable := range variables.Items {
+		add(variable.DeletionTimestamp, variable.Spec.InstanceSelector)
+	}
+
+	roles := &v1alpha2.PersesRoleList{}
+	if err := reader.List(ctx, roles, client.InNamespace(namespace)); err != nil {
+		return false, fmt.Errorf("failed to list PersesRoles in namespace %s: %w", namespace, err)
+	}
+	for _, role := range roles.Items {
+		add(role.DeletionTimestamp, role.Spec.InstanceSelector)
+	}
+
+	roleBindings := &v1alpha2.PersesRoleBindingList{}
+	if err := reader.List(ctx, roleBindings, client.InNamespace(namespace)); err != nil {
+		return false, fmt.Errorf("failed to list PersesRoleBindings in namespace %s: %w", namespace, err)
+	}
+	for _, roleBinding := range roleBindings.Items {
+		add(roleBinding.DeletionTimestamp, roleBinding.Spec.InstanceSelector)
+	}
+
+	projects := &v1alpha2.PersesProjectList{}
+	if err := reader.List(ctx, projects, client.InNamespace(namespace)); err != nil {
+		return false, fmt.Errorf("failed to list PersesProjects in namespace %s: %w", namespace, err)
+	}
+	for _, project := range projects.Items {
+		add(project.DeletionTimestamp, project.Spec.InstanceSelector)
+	}
+
+	for _, selector := range selectors {
+		if selected, err := SelectsInstance(selector, perses); selected || err != nil {
+			return true, nil
+		}
+	}
+	for _, resource := range resources {
+		if synced, err := SyncedToInstance(ctx, reader, resource.selector, perses, resource.obj); synced || err != nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// projectEmpty reports whether the project holds no dashboard, datasource, secret or variable.
+func projectEmpty(persesClient v1.ClientInterface, namespace string) (bool, error) {
+	dashboards, err := persesClient.Dashboard(namespace).List("")
+	if err != nil {
+		return false, fmt.Errorf("failed to list dashboards in project %s: %w", namespace, err)
+	}
+	datasources, err := persesClient.Datasource(namespace).List("")
+	if err != nil {
+		return false, fmt.Errorf("failed to list datasources in project %s: %w", namespace, err)
+	}
+	secrets, err := persesClient.Secret(namespace).List("")
+	if err != nil {
+		return false, fmt.Errorf("failed to list secrets in project %s: %w", namespace, err)
+	}
+	variables, err := persesClient.Variable(namespace).List("")
+	if err != nil {
+		return false, fmt.Errorf("failed to list variables in project %s: %w", namespace, err)
+	}
+	return len(dashboards) == 0 && len(datasources) == 0 && len(secrets) == 0 && len(variables) == 0, nil
+}
@@ -0,0 +1,303 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	persesv1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
+	internal "github.com/perses/perses-operator/internal/perses"
+)
+
+func TestDesiredProject(t *testing.T) {
+	t.Run("defaults the display name to the namespace", func(t *testing.T) {
+		project := DesiredProject("team-a", nil)
+		assert.Equal(t, "team-a", project.Metadata.Name)
+		assert.Equal(t, "team-a", project.Spec.Display.Name)
+		assert.Empty(t, project.Spec.Display.Description)
+	})
+
+	t.Run("uses the PersesProject display settings", func(t *testing.T) {
+		project := DesiredProject("team-a", &v1alpha2.PersesProject{
+			Spec: v1alpha2.PersesProjectSpec{DisplayName: "Team A", Description: "Team A dashboards"},
+		})
+		assert.Equal(t, "team-a", project.Metadata.Name)
+		assert.Equal(t, "Team A", project.Spec.Display.Name)
+		assert.Equal(t, "Team A dashboards", project.Spec.Display.Description)
+	})
+
+	t.Run("falls back to the namespace when only a description is set", func(t *testing.T) {
+		project := DesiredProject("team-a", &v1alpha2.PersesProject{
+			Spec: v1alpha2.PersesProjectSpec{Description: "Team A dashboards"},
+		})
+		assert.Equal(t, "team-a", project.Spec.Display.Name)
+	})
+}
+
+func TestProjectForNamespace(t *testing.T) {
+	ctx := context.Background()
+	scheme := newScheme()
+	require.NoError(t, v1alpha2.AddToScheme(scheme))
+
+	newProject := func(name, namespace string, age time.Duration) *v1alpha2.PersesProject {
+		return &v1alpha2.PersesProject{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+			},
+		}
+	}
+
+	t.Run("returns nil when the namespace has no PersesProject", func(t *testing.T) {
+		reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(newProject("other", "team-b", 0)).Build()
+		project, err := ProjectForNamespace(ctx, reader, "team-a")
+		require.NoError(t, err)
+		assert.Nil(t, project)
+	})
+
+	t.Run("returns the oldest PersesProject of the namespace", func(t *testing.T) {
+		reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			newProject("newer", "team-a", time.Minute),
+			newProject("older", "team-a", time.Hour),
+		).Build()
+		project, err := ProjectForNamespace(ctx, reader, "team-a")
+		require.NoError(t, err)
+		require.NotNil(t, project)
+		assert.Equal(t, "older", project.Name)
+	})
+}
+
+func TestProjectSelectsInstance(t *testing.T) {
+	perses := v1alpha2.Perses{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"env": "prod"}}}
+
+	selected, err := ProjectSelectsInstance(&v1alpha2.PersesProject{}, perses)
+	require.NoError(t, err)
+	assert.True(t, selected)
+
+	selected, err = ProjectSelectsInstance(&v1alpha2.PersesProject{Spec: v1alpha2.PersesProjectSpec{
+		InstanceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "dev"}},
+	}}, perses)
+	require.NoError(t, err)
+	assert.False(t, selected)
+}
+
+func TestInstanceAllowsResource(t *testing.T) {
+	ctx := context.Background()
+	scheme := newScheme()
+	require.NoError(t, v1alpha2.AddToScheme(scheme))
+	reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"tenant": "a"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b", Labels: map[string]string{"tenant": "b"}}},
+	).Build()
+
+	restricted := v1alpha2.Perses{
+		ObjectMeta: metav1.ObjectMeta{Name: "perses", Namespace: "monitoring"},
+		Spec: v1alpha2.PersesSpec{
+			ResourceNamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "a"}},
+			ResourceSelector:          &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+		},
+	}
+	newDashboard := func(namespace string, labels map[string]string) *v1alpha2.PersesDashboard {
+		return &v1alpha2.PersesDashboard{ObjectMeta: metav1.ObjectMeta{Name: "overview", Namespace: namespace, Labels: labels}}
+	}
+	prod := map[string]string{"env": "prod"}
+
+	tests := []struct {
+		name    string
+		perses  v1alpha2.Perses
+		obj     client.Object
+		allowed bool
+	}{
+		{
+			name:    "allows every resource without selectors",
+			perses:  v1alpha2.Perses{ObjectMeta: metav1.ObjectMeta{Name: "perses", Namespace: "monitoring"}},
+			obj:     newDashboard("team-b", nil),
+			allowed: true,
+		},
+		{
+			name:    "allows a resource matching both selectors",
+			perses:  restricted,
+			obj:     newDashboard("team-a", prod),
+			allowed: true,
+		},
+		{
+			name:   "rejects a resource from a namespace not selected",
+			perses: restricted,
+			obj:    newDashboard("team-b", prod),
+		},
+		{
+			name:   "rejects a resource with labels not selected",
+			perses: restricted,
+			obj:    newDashboard("team-a", map[string]string{"env": "dev"}),
+		},
+		{
+			name:    "ignores the namespace selector for cluster-scoped resources",
+			perses:  restricted,
+			obj:     &v1alpha2.PersesGlobalDatasource{ObjectMeta: metav1.ObjectMeta{Name: "thanos", Labels: prod}},
+			allowed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, err := InstanceAllowsResource(ctx, reader, tt.perses, tt.obj)
+			require.NoError(t, err)
+			assert.Equal(t, tt.allowed, allowed)
+		})
+	}
+
+	t.Run("fails when the namespace cannot be read", func(t *testing.T) {
+		_, err := InstanceAllowsResource(ctx, reader, restricted, newDashboard("missing", prod))
+		assert.Error(t, err)
+	})
+}
+
+func TestCleanupProject(t *testing.T) {
+	ctx := context.Background()
+	scheme := newScheme()
+	require.NoError(t, v1alpha2.AddToScheme(scheme))
+	perses := v1alpha2.Perses{ObjectMeta: metav1.ObjectMeta{Name: "perses", Namespace: "monitoring"}}
+
+	// newPersesClient mocks a Perses instance holding the team-a project with the given dashboards.
+	newPersesClient := func(managed bool, dashboards []*persesv1.Dashboard) (*internal.MockClient, *internal.MockProject) {
+		mockProject := &internal.MockProject{}
+		project := &persesv1.Project{Metadata: persesv1.Metadata{Name: "team-a"}}
+		if managed {
+			project.Metadata.Tags = WithManagedTag(nil)
+		}
+		mockProject.On("Get", "team-a").Return(project, nil)
+		mockProject.On("Delete", "team-a").Return(nil)
+
+		mockDashboard := &internal.MockDashboard{}
+		mockDatasource := &internal.MockDatasource{}
+		mockSecret := &internal.MockSecret{}
+		mockVariable := &internal.MockVariable{}
+		mockDashboard.On("List", "").Return(dashboards, nil)
+		mockDatasource.On("List", "").Return([]*persesv1.Datasource{}, nil)
+		mockSecret.On("List", "").Return([]*persesv1.Secret{}, nil)
+		mockVariable.On("List", "").Return([]*persesv1.Variable{}, nil)
+
+		persesClient := &internal.MockClient{Projects: mockProject}
+		persesClient.On("Dashboard", "team-a").Return(mockDashboard)
+		persesClient.On("Datasource", "team-a").Return(mockDatasource)
+		persesClient.On("Secret", "team-a").Return(mockSecret)
+		persesClient.On("Variable", "team-a").Return(mockVariable)
+		return persesClient, mockProject
+	}
+
+	newNamespace := func(annotations map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Annotations: annotations}}
+	}
+
+	tests := []struct {
+		name       string
+		managed    bool
+		dashboards []*persesv1.Dashboard
+		objects    []client.Object
+		// resourceSelector is the resourceSelector of the Perses instance.
+		resourceSelector *metav1.LabelSelector
+		removed          bool
+	}{
+		{
+			name:    "removes an empty project created by the operator",
+			managed: true,
+			objects: []client.Object{newNamespace(nil)},
+			removed: true,
+		},
+		{
+			name:    "removes the project of a namespace that no longer exists",
+			managed: true,
+			removed: true,
+		},
+		{
+			name:    "keeps a project not created by the operator",
+			objects: []client.Object{newNamespace(nil)},
+		},
+		{
+			name:    "keeps the project of a namespace opted out",
+			managed: true,
+			objects: []client.Object{newNamespace(map[string]string{KeepProjectAnnotation: "true"})},
+		},
+		{
+			name:    "keeps a project still referenced by a custom resource",
+			managed: true,
+			objects: []client.Object{newNamespace(nil), &v1alpha2.PersesVariable{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "team-a"},
+			}},
+		},
+		{
+			name:    "ignores the custom resources selecting other instances",
+			managed: true,
+			objects: []client.Object{newNamespace(nil), &v1alpha2.PersesDashboard{
+				ObjectMeta: metav1.ObjectMeta{Name: "overview", Namespace: "team-a"},
+				Spec: v1alpha2.PersesDashboardSpec{
+					InstanceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "staging"}},
+				},
+			}},
+			removed: true,
+		},
+		{
+			name:    "ignores the custom resources the instance rejects",
+			managed: true,
+			objects: []client.Object{newNamespace(nil), &v1alpha2.PersesDashboard{
+				ObjectMeta: metav1.ObjectMeta{Name: "overview", Namespace: "team-a", Labels: map[string]string{"env": "staging"}},
+			}},
+			resourceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+			removed:          true,
+		},
+		{
+			name:    "keeps a project still referenced by a custom resource the instance accepts",
+			managed: true,
+			objects: []client.Object{newNamespace(nil), &v1alpha2.PersesDashboard{
+				ObjectMeta: metav1.ObjectMeta{Name: "overview", Namespace: "team-a", Labels: map[string]string{"env": "prod"}},
+			}},
+			resourceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+		},
+		{
+			name:       "keeps a project still holding a dashboard created in Perses",
+			managed:    true,
+			dashboards: []*persesv1.Dashboard{{Metadata: persesv1.ProjectMetadata{Metadata: persesv1.Metadata{Name: "created-in-perses"}}}},
+			objects:    []client.Object{newNamespace(nil)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tt.objects...).Build()
+			persesClient, mockProject := newPersesClient(tt.managed, tt.dashboards)
+
+			instance := perses
+			instance.Spec.ResourceSelector = tt.resourceSelector
+
+			require.NoError(t, CleanupProject(ctx, reader, persesClient, instance, "team-a"))
+			if tt.removed {
+				mockProject.AssertCalled(t, "Delete", "team-a")
+			} else {
+				mockProject.AssertNotCalled(t, "Delete", mock.Anything)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// deploymentProgressDeadlineExceeded is the reason of the Progressing condition of a Deployment
+// whose rollout exceeded its progress deadline.
+const deploymentProgressDeadlineExceeded = "ProgressDeadlineExceeded"
+
+// DeploymentRollout returns the progress of the rollout of a Deployment: ReasonRolloutComplete once
+// every replica runs the latest pod template and is ready and available, ReasonRolloutStalled when
+// the Deployment exceeded its progress deadline and ReasonRolloutInProgress otherwise, with a message
+// describing the replicas the rollout is waiting for.
+func DeploymentRollout(dep *appsv1.Deployment) (ConditionStatusReason, string) {
+	if dep.Generation > dep.Status.ObservedGeneration {
+		return ReasonRolloutInProgress, fmt.Sprintf("Waiting for Deployment %s spec update to be observed", dep.Name)
+	}
+	for _, cond := range dep.Status.Conditions {
+		if cond.Type == appsv1.DeploymentProgressing && cond.Reason == deploymentProgressDeadlineExceeded {
+			return ReasonRolloutStalled, fmt.Sprintf("Deployment %s exceeded its progress deadline: %s", dep.Name, cond.Message)
+		}
+	}
+
+	return replicasRollout("Deployment", dep.Name, specReplicas(dep.Spec.Replicas), dep.Status.Replicas,
+		dep.Status.UpdatedReplicas, dep.Status.ReadyReplicas, dep.Status.AvailableReplicas)
+}
+
+// StatefulSetRollout returns the progress of the rollout of a StatefulSet, as DeploymentRollout does
+// for a Deployment. A StatefulSet has no progress deadline, so its rollout is never reported stalled.
+func StatefulSetRollout(sts *appsv1.StatefulSet) (ConditionStatusReason, string) {
+	if sts.Generation > sts.Status.ObservedGeneration {
+		return ReasonRolloutInProgress, fmt.Sprintf("Waiting for StatefulSet %s spec update to be observed", sts.Name)
+	}
+
+	return replicasRollout("StatefulSet", sts.Name, specReplicas(sts.Spec.Replicas), sts.Status.Replicas,
+		sts.Status.UpdatedReplicas, sts.Status.ReadyReplicas, sts.Status.AvailableReplicas)
+}
+
+func replicasRollout(kind, name string, desired, current, updated, ready, available int32) (ConditionStatusReason, string) {
+	switch {
+	case updated < desired:
+		return ReasonRolloutInProgress, fmt.Sprintf("Waiting for %s %s rollout to finish: %d of %d replicas updated", kind, name, updated, desired)
+	case current > updated:
+		return ReasonRolloutInProgress, fmt.Sprintf("Waiting for %s %s rollout to finish: %d old replicas pending termination", kind, name, current-updated)
+	case ready < desired:
+		return ReasonRolloutInProgress, fmt.Sprintf("Waiting for %s %s rollout to finish: %d of %d replicas ready", kind, name, ready, desired)
+	case available < desired:
+		return ReasonRolloutInProgress, fmt.Sprintf("Waiting for %s %s rollout to finish: %d of %d replicas available", kind, name, available, desired)
+	}
+	return ReasonRolloutComplete, fmt.Sprintf("%s %s rolled out: %d of %d replicas available", kind, name, available, desired)
+}
+
+// specReplicas returns the desired number of replicas of a workload, which the API server defaults to 1.
+func specReplicas(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// PodStatusChanged reports whether the readiness or the phase of a pod changed, which may
+// change the availability of the workload it belongs to.
+func PodStatusChanged(oldPod, newPod *corev1.Pod) bool {
+	return oldPod.Status.Phase != newPod.Status.Phase || podReady(oldPod) != podReady(newPod)
+}
+
+func podReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestDeploymentRollout(t *testing.T) {
+	deployment := func(status appsv1.DeploymentStatus) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "perses", Generation: 2},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.To[int32](2)},
+			Status:     status,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		status appsv1.DeploymentStatus
+		reason ConditionStatusReason
+	}{
+		{
+			name:   "spec update not observed yet",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 2, UpdatedReplicas: 2, ReadyReplicas: 2, AvailableReplicas: 2},
+			reason: ReasonRolloutInProgress,
+		},
+		{
+			name:   "replicas not updated yet",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 1, ReadyReplicas: 2, AvailableReplicas: 2},
+			reason: ReasonRolloutInProgress,
+		},
+		{
+			name:   "old replicas pending termination",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 2, ReadyReplicas: 3, AvailableReplicas: 3},
+			reason: ReasonRolloutInProgress,
+		},
+		{
+			name:   "crash-looping replicas not ready",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, ReadyReplicas: 0, AvailableReplicas: 0},
+			reason: ReasonRolloutInProgress,
+		},
+		{
+			name:   "ready replicas not available yet",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, ReadyReplicas: 2, AvailableReplicas: 1},
+			reason: ReasonRolloutInProgress,
+		},
+		{
+			name: "progress deadline exceeded",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2,
+				Conditions: []appsv1.DeploymentCondition{{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded"}}},
+			reason: ReasonRolloutStalled,
+		},
+		{
+			name:   "rollout complete",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, ReadyReplicas: 2, AvailableReplicas: 2},
+			reason: ReasonRolloutComplete,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, message := DeploymentRollout(deployment(tt.status))
+			assert.Equal(t, tt.reason, reason)
+			assert.Contains(t, message, "Deployment perses")
+		})
+	}
+}
+
+func TestStatefulSetRollout(t *testing.T) {
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "perses", Generation: 1},
+		Status:     appsv1.StatefulSetStatus{ObservedGeneration: 1, Replicas: 1, UpdatedReplicas: 1, ReadyReplicas: 0, AvailableReplicas: 0},
+	}
+
+	reason, message := StatefulSetRollout(sts)
+	assert.Equal(t, ReasonRolloutInProgress, reason)
+	assert.Equal(t, "Waiting for StatefulSet perses rollout to finish: 0 of 1 replicas ready", message)
+
+	sts.Status.ReadyReplicas = 1
+	sts.Status.AvailableReplicas = 1
+	reason, _ = StatefulSetRollout(sts)
+	assert.Equal(t, ReasonRolloutComplete, reason, "the API server defaults the replicas to 1")
+}
+
+func TestPodStatusChanged(t *testing.T) {
+	pod := func(phase corev1.PodPhase, ready corev1.ConditionStatus) *corev1.Pod {
+		return &corev1.Pod{Status: corev1.PodStatus{
+			Phase:      phase,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}},
+		}}
+	}
+
+	assert.False(t, PodStatusChanged(pod(corev1.PodRunning, corev1.ConditionTrue), pod(corev1.PodRunning, corev1.ConditionTrue)))
+	assert.True(t, PodStatusChanged(pod(corev1.PodRunning, corev1.ConditionTrue), pod(corev1.PodRunning, corev1.ConditionFalse)))
+	assert.True(t, PodStatusChanged(pod(corev1.PodPending, corev1.ConditionFalse), pod(corev1.PodRunning, corev1.ConditionFalse)))
+}
@@ -15,8 +15,16 @@ package common
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"os"
+	"sync"
 
+	persesv1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/secret"
+	logger "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -26,10 +34,99 @@ import (
 
 const SecretNameSuffix = "-secret"
 
+var seclog = logger.WithField("module", "secret")
+
 func HasSecretConfig(c *v1alpha2.Client) bool {
 	return c != nil && (c.TLS != nil && c.TLS.Enable != nil && *c.TLS.Enable || c.BasicAuth != nil || c.OAuth != nil)
 }
 
+// ClientReferences reports whether a client configuration reads credentials or certificates
+// from the given Secret or ConfigMap, depending on sourceType.
+// References without an explicit namespace are resolved in the given namespace.
+func ClientReferences(c *v1alpha2.Client, namespace string, sourceType v1alpha2.SecretSourceType, obj client.Object) bool {
+	if c == nil {
+		return false
+	}
+
+	sources := []*v1alpha2.SecretSource{}
+	if c.BasicAuth != nil {
+		sources = append(sources, &c.BasicAuth.SecretSource)
+	}
+	if c.OAuth != nil {
+		sources = append(sources, &c.OAuth.SecretSource)
+	}
+	if c.TLS != nil {
+		if c.TLS.CaCert != nil {
+			sources = append(sources, &c.TLS.CaCert.SecretSource)
+		}
+		if c.TLS.UserCert != nil {
+			sources = append(sources, &c.TLS.UserCert.SecretSource)
+		}
+	}
+
+	for _, source := range sources {
+		if source.Type != sourceType || source.Name == nil || *source.Name != obj.GetName() {
+			continue
+		}
+		sourceNamespace := namespace
+		if source.Namespace != nil && len(*source.Namespace) != 0 {
+			sourceNamespace = *source.Namespace
+		}
+		if sourceNamespace == obj.GetNamespace() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SecretContentTracker remembers the content of the secrets pushed to the Perses instances.
+// Perses hides the credentials of the secrets it returns, so the remote content cannot be
+// compared with the desired one: the tracker compares it with the content last pushed instead.
+// The zero value is ready to use.
+type SecretContentTracker struct {
+	hashes sync.Map
+}
+
+// SecretContentKey identifies a secret of a Perses instance. The project is empty for global secrets.
+func SecretContentKey(perses v1alpha2.Perses, project string, name string) string {
+	return fmt.Sprintf("%s/%s/%s/%s", perses.Namespace, perses.Name, project, name)
+}
+
+// InSync reports whether spec is the content last pushed for the secret identified by key.
+func (t *SecretContentTracker) InSync(key string, spec persesv1.SecretSpec) bool {
+	hash, err := secretSpecHash(spec)
+	if err != nil {
+		return false
+	}
+	last, ok := t.hashes.Load(key)
+	return ok && last == hash
+}
+
+// Record stores spec as the content last pushed for the secret identified by key.
+func (t *SecretContentTracker) Record(key string, spec persesv1.SecretSpec) {
+	hash, err := secretSpecHash(spec)
+	if err != nil {
+		t.hashes.Delete(key)
+		return
+	}
+	t.hashes.Store(key, hash)
+}
+
+// Forget drops the content recorded for the secret identified by key.
+func (t *SecretContentTracker) Forget(key string) {
+	t.hashes.Delete(key)
+}
+
+func secretSpecHash(spec persesv1.SecretSpec) (string, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
 // GetBasicAuthData get basic auth from a BasicAuth resource
 func GetBasicAuthData(ctx context.Context, client client.Reader, namespace string, name string, basicAuth *v1alpha2.BasicAuth) (string, error) {
 	var passwordData string
@@ -198,3 +295,143 @@ func GetTLSCertData(ctx context.Context, client client.Reader, namespace string,
 
 	return "", "", nil
 }
+
+// SecretSpecFromClient builds the spec of a Perses secret from a client configuration,
+// retrieving the credentials and cert/key data from the Secrets, ConfigMaps or files it references.
+// References without an explicit namespace are resolved in the given namespace; name identifies
+// the resource owning the configuration in logs and error messages.
+func SecretSpecFromClient(ctx context.Context, reader client.Reader, namespace string, name string, c *v1alpha2.Client) (persesv1.SecretSpec, ConditionStatusReason, error) {
+	spec := persesv1.SecretSpec{}
+	if c == nil {
+		return spec, "", nil
+	}
+
+	if basicAuth := c.BasicAuth; basicAuth != nil {
+		basicAuthConfig := &secret.BasicAuth{}
+		basicAuthConfig.Username = basicAuth.Username
+
+		switch basicAuth.Type {
+		case v1alpha2.SecretSourceTypeSecret, v1alpha2.SecretSourceTypeConfigMap:
+			passwordData, err := GetBasicAuthData(ctx, reader, namespace, name, basicAuth)
+
+			if err != nil {
+				seclog.WithFields(logger.Fields{
+					"name":       name,
+					"namespace":  namespace,
+					"secretName": basicAuth.Name,
+				}).WithError(err).Error("Failed to get user basic auth password data")
+				return spec, ReasonInvalidConfiguration, err
+			}
+
+			basicAuthConfig.Password = passwordData
+		case v1alpha2.SecretSourceTypeFile:
+			basicAuthConfig.PasswordFile = basicAuth.PasswordPath
+		}
+
+		spec.BasicAuth = basicAuthConfig
+	}
+
+	if oauth := c.OAuth; oauth != nil {
+		oAuthConfig := &secret.OAuth{
+			TokenURL:       oauth.TokenURL,
+			Scopes:         oauth.Scopes,
+			EndpointParams: oauth.EndpointParams,
+		}
+
+		if oauth.AuthStyle != nil && *oauth.AuthStyle != 0 {
+			oAuthConfig.AuthStyle = int(*oauth.AuthStyle)
+		}
+
+		switch oauth.Type {
+		case v1alpha2.SecretSourceTypeSecret, v1alpha2.SecretSourceTypeConfigMap:
+			clientIDData, clientSecretData, err := GetOAuthData(ctx, reader, namespace, name, oauth)
+
+			if err != nil {
+				seclog.WithFields(logger.Fields{
+					"name":       name,
+					"namespace":  namespace,
+					"secretName": oauth.Name,
+				}).WithError(err).Error("Failed to get user oauth data")
+				return spec, ReasonInvalidConfiguration, err
+			}
+
+			oAuthConfig.ClientID = clientIDData
+			oAuthConfig.ClientSecret = clientSecretData
+		case v1alpha2.SecretSourceTypeFile:
+			// the clientID is a Hidden field in perses API,
+			// but doesn't expose it as a file field for it, so we need to read it and use the value
+			if oauth.ClientIDPath == nil {
+				return spec, ReasonInvalidConfiguration, fmt.Errorf("clientIDPath is required when OAuth type is File for %s", name)
+			}
+			clientID, err := os.ReadFile(*oauth.ClientIDPath)
+			if err != nil {
+				return spec, ReasonInvalidConfiguration, fmt.Errorf("failed to read the OAuth client ID file %s: %w", *oauth.ClientIDPath, err)
+			}
+			oAuthConfig.ClientID = string(clientID)
+			if oauth.ClientSecretPath != nil {
+				oAuthConfig.ClientSecretFile = *oauth.ClientSecretPath
+			}
+		}
+
+		spec.OAuth = oAuthConfig
+	}
+
+	if tls := c.TLS; tls != nil {
+		insecureSkipVerify := false
+		if tls.InsecureSkipVerify != nil {
+			insecureSkipVerify = *tls.InsecureSkipVerify
+		}
+		tlsConfig := &secret.TLSConfig{
+			InsecureSkipVerify: insecureSkipVerify,
+		}
+
+		if tls.CaCert != nil {
+			switch tls.CaCert.Type {
+			case v1alpha2.SecretSourceTypeSecret, v1alpha2.SecretSourceTypeConfigMap:
+				caData, _, err := GetTLSCertData(ctx, reader, namespace, name, tls.CaCert)
+
+				if err != nil {
+					seclog.WithFields(logger.Fields{
+						"name":       name,
+						"namespace":  namespace,
+						"secretName": tls.CaCert.Name,
+					}).WithError(err).Error("Failed to get CA data")
+					return spec, ReasonInvalidConfiguration, err
+				}
+
+				tlsConfig.CA = caData
+			case v1alpha2.SecretSourceTypeFile:
+				tlsConfig.CAFile = tls.CaCert.CertPath
+			}
+		}
+
+		if tls.UserCert != nil {
+			switch tls.UserCert.Type {
+			case v1alpha2.SecretSourceTypeSecret, v1alpha2.SecretSourceTypeConfigMap:
+				certData, keyData, err := GetTLSCertData(ctx, reader, namespace, name, tls.UserCert)
+
+				if err != nil {
+					seclog.WithFields(logger.Fields{
+						"name":       name,
+						"namespace":  namespace,
+						"secretName": tls.UserCert.Name,
+					}).WithError(err).Error("Failed to get user certificate data")
+					return spec, ReasonInvalidConfiguration, err
+				}
+
+				tlsConfig.Cert = certData
+				tlsConfig.Key = keyData
+			case v1alpha2.SecretSourceTypeFile:
+				tlsConfig.CertFile = tls.UserCert.CertPath
+
+				if tls.UserCert.PrivateKeyPath != nil && len(*tls.UserCert.PrivateKeyPath) > 0 {
+					tlsConfig.KeyFile = *tls.UserCert.PrivateKeyPath
+				}
+			}
+		}
+
+		spec.TLSConfig = tlsConfig
+	}
+
+	return spec, "", nil
+}
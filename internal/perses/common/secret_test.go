@@ -18,6 +18,8 @@ import (
 	"testing"
 
 	"github.com/perses/perses-operator/api/v1alpha2"
+	persesv1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/secret"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
@@ -488,3 +490,76 @@ func TestHasSecretConfig(t *testing.T) {
 		}))
 	})
 }
+
+func TestClientReferences(t *testing.T) {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "creds", Namespace: "monitoring"}}
+
+	t.Run("returns false for nil client", func(t *testing.T) {
+		assert.False(t, ClientReferences(nil, "monitoring", v1alpha2.SecretSourceTypeSecret, secret))
+	})
+
+	t.Run("matches a reference resolved in the default namespace", func(t *testing.T) {
+		c := &v1alpha2.Client{
+			BasicAuth: &v1alpha2.BasicAuth{
+				SecretSource: v1alpha2.SecretSource{Type: v1alpha2.SecretSourceTypeSecret, Name: ptr.To("creds")},
+			},
+		}
+		assert.True(t, ClientReferences(c, "monitoring", v1alpha2.SecretSourceTypeSecret, secret))
+		assert.False(t, ClientReferences(c, "default", v1alpha2.SecretSourceTypeSecret, secret))
+	})
+
+	t.Run("matches a reference with an explicit namespace", func(t *testing.T) {
+		c := &v1alpha2.Client{
+			TLS: &v1alpha2.TLS{
+				Enable: ptr.To(true),
+				UserCert: &v1alpha2.Certificate{
+					SecretSource: v1alpha2.SecretSource{
+						Type:      v1alpha2.SecretSourceTypeSecret,
+						Name:      ptr.To("creds"),
+						Namespace: ptr.To("monitoring"),
+					},
+				},
+			},
+		}
+		assert.True(t, ClientReferences(c, "", v1alpha2.SecretSourceTypeSecret, secret))
+	})
+
+	t.Run("ignores references of another source type", func(t *testing.T) {
+		c := &v1alpha2.Client{
+			OAuth: &v1alpha2.OAuth{
+				SecretSource: v1alpha2.SecretSource{Type: v1alpha2.SecretSourceTypeConfigMap, Name: ptr.To("creds")},
+			},
+		}
+		assert.False(t, ClientReferences(c, "monitoring", v1alpha2.SecretSourceTypeSecret, secret))
+		assert.True(t, ClientReferences(c, "monitoring", v1alpha2.SecretSourceTypeConfigMap, secret))
+	})
+
+	t.Run("ignores references to another name", func(t *testing.T) {
+		c := &v1alpha2.Client{
+			TLS: &v1alpha2.TLS{
+				CaCert: &v1alpha2.Certificate{
+					SecretSource: v1alpha2.SecretSource{Type: v1alpha2.SecretSourceTypeSecret, Name: ptr.To("ca")},
+				},
+			},
+		}
+		assert.False(t, ClientReferences(c, "monitoring", v1alpha2.SecretSourceTypeSecret, secret))
+	})
+}
+
+func TestSecretContentTracker(t *testing.T) {
+	perses := v1alpha2.Perses{ObjectMeta: metav1.ObjectMeta{Name: "perses", Namespace: "perses-dev"}}
+	key := SecretContentKey(perses, "monitoring", "prometheus-secret")
+	spec := persesv1.SecretSpec{BasicAuth: &secret.BasicAuth{Username: "admin", Password: "s3cret"}}
+	rotated := persesv1.SecretSpec{BasicAuth: &secret.BasicAuth{Username: "admin", Password: "r0tated"}}
+
+	var tracker SecretContentTracker
+	assert.False(t, tracker.InSync(key, spec), "nothing was recorded yet")
+
+	tracker.Record(key, spec)
+	assert.True(t, tracker.InSync(key, spec))
+	assert.False(t, tracker.InSync(key, rotated), "a rotated password must be detected")
+	assert.False(t, tracker.InSync(SecretContentKey(perses, "other", "prometheus-secret"), spec))
+
+	tracker.Forget(key)
+	assert.False(t, tracker.InSync(key, spec))
+}
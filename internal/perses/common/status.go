@@ -14,8 +14,17 @@
 package common
 
 import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
 )
 
 // SnapshotConditions returns a shallow copy of the conditions slice.
@@ -32,3 +41,222 @@ func SnapshotConditions(conditions []metav1.Condition) []metav1.Condition {
 func ConditionsChanged(before, after []metav1.Condition) bool {
 	return !equality.Semantic.DeepEqual(before, after)
 }
+
+// InstanceStatusesChanged reports whether the instance statuses differ from a
+// previously taken copy. Nil and empty slices are considered equal.
+func InstanceStatusesChanged(before, after []v1alpha2.PersesInstanceStatus) bool {
+	return !equality.Semantic.DeepEqual(before, after)
+}
+
+// InstanceReference returns the reference of a Perses instance, as recorded
+// in the syncedInstances status of the resources synced to it.
+func InstanceReference(perses v1alpha2.Perses) v1alpha2.PersesInstanceReference {
+	return v1alpha2.PersesInstanceReference{Namespace: perses.Namespace, Name: perses.Name}
+}
+
+// HasInstanceReference reports whether ref is part of instances.
+func HasInstanceReference(instances []v1alpha2.PersesInstanceReference, ref v1alpha2.PersesInstanceReference) bool {
+	return slices.Contains(instances, ref)
+}
+
+// AddInstanceReference appends ref to instances unless it is already present.
+func AddInstanceReference(instances []v1alpha2.PersesInstanceReference, ref v1alpha2.PersesInstanceReference) []v1alpha2.PersesInstanceReference {
+	if HasInstanceReference(instances, ref) {
+		return instances
+	}
+	return append(instances, ref)
+}
+
+// DeselectedInstances returns the instances of synced that are not part of the selected Perses instances.
+func DeselectedInstances(synced []v1alpha2.PersesInstanceReference, selected []v1alpha2.Perses) []v1alpha2.PersesInstanceReference {
+	var deselected []v1alpha2.PersesInstanceReference
+	for _, ref := range synced {
+		if !slices.ContainsFunc(selected, func(perses v1alpha2.Perses) bool { return InstanceReference(perses) == ref }) {
+			deselected = append(deselected, ref)
+		}
+	}
+	return deselected
+}
+
+// RemoveInstanceReferences returns instances without the references listed in removed.
+func RemoveInstanceReferences(instances, removed []v1alpha2.PersesInstanceReference) []v1alpha2.PersesInstanceReference {
+	return slices.DeleteFunc(slices.Clone(instances), func(ref v1alpha2.PersesInstanceReference) bool {
+		return slices.Contains(removed, ref)
+	})
+}
+
+// SyncedAtGeneration reports whether a resource was successfully synced at the given generation.
+// A difference found in Perses afterwards was not introduced by the custom resource: it is a drift.
+func SyncedAtGeneration(conditions []metav1.Condition, generation int64) bool {
+	available := meta.FindStatusCondition(conditions, TypeAvailablePerses)
+	return available != nil && available.Status == metav1.ConditionTrue && available.ObservedGeneration == generation
+}
+
+// DriftCondition returns the Drifted condition of a resource, listing the Perses
+// instances in which a drift was corrected during the last sync.
+func DriftCondition(kind string, name string, drifted []string) metav1.Condition {
+	if len(drifted) == 0 {
+		return metav1.Condition{
+			Type: TypeDriftedPerses, Status: metav1.ConditionFalse,
+			Reason: string(ReasonNoDrift), Message: fmt.Sprintf("%s (%s) matches every Perses instance", kind, name)}
+	}
+	return metav1.Condition{
+		Type: TypeDriftedPerses, Status: metav1.ConditionTrue,
+		Reason:  string(ReasonDriftCorrected),
+		Message: fmt.Sprintf("%s (%s) was modified in Perses and restored in: %s", kind, name, strings.Join(drifted, ", "))}
+}
+
+// PartialSyncCondition returns the PartiallySynced condition of a resource synced to the
+// given number of Perses instances, and that failed to sync to the failed ones.
+func PartialSyncCondition(kind string, name string, synced int, failed []string) metav1.Condition {
+	switch {
+	case len(failed) == 0:
+		return metav1.Condition{
+			Type: TypePartiallySyncedPerses, Status: metav1.ConditionFalse,
+			Reason: string(ReasonAllInstancesSynced), Message: fmt.Sprintf("%s (%s) is synced to every available Perses instance", kind, name)}
+	case synced == 0:
+		return metav1.Condition{
+			Type: TypePartiallySyncedPerses, Status: metav1.ConditionFalse,
+			Reason:  string(ReasonNoInstanceSynced),
+			Message: fmt.Sprintf("%s (%s) failed to sync to every available Perses instance: %s", kind, name, strings.Join(failed, ", "))}
+	default:
+		return metav1.Condition{
+			Type: TypePartiallySyncedPerses, Status: metav1.ConditionTrue,
+			Reason: string(ReasonInstancesFailed),
+			Message: fmt.Sprintf("%s (%s) is synced to %d of %d Perses instances, failed in: %s",
+				kind, name, synced, synced+len(failed), strings.Join(failed, ", "))}
+	}
+}
+
+// NotAllowedCondition returns the NotAllowedByInstance condition of a resource, listing the
+// selected Perses instances whose resource selectors reject it.
+func NotAllowedCondition(kind string, name string, notAllowed []string) metav1.Condition {
+	if len(notAllowed) == 0 {
+		return metav1.Condition{
+			Type: TypeNotAllowedPerses, Status: metav1.ConditionFalse,
+			Reason: string(ReasonAllowedByAllInstances), Message: fmt.Sprintf("%s (%s) is allowed by every selected Perses instance", kind, name)}
+	}
+	return metav1.Condition{
+		Type: TypeNotAllowedPerses, Status: metav1.ConditionTrue,
+		Reason:  string(ReasonNotAllowedByInstance),
+		Message: fmt.Sprintf("%s (%s) is not allowed by the resource selectors of: %s", kind, name, strings.Join(notAllowed, ", "))}
+}
+
+// SuspendedCondition returns the Suspended condition of a resource whose reconciliation is paused.
+func SuspendedCondition(kind string, name string) metav1.Condition {
+	return metav1.Condition{
+		Type: TypeSuspendedPerses, Status: metav1.ConditionTrue,
+		Reason:  string(ReasonReconcilePaused),
+		Message: fmt.Sprintf("Reconciliation of %s (%s) is paused by the %s annotation", kind, name, PersesReconcileAnnotation)}
+}
+
+// SuspendedStatusChanged reports whether the Suspended condition of a resource has to be written,
+// i.e. whether it is missing while its reconciliation is paused, or left over once it is resumed.
+func SuspendedStatusChanged(conditions []metav1.Condition, paused bool) bool {
+	suspended := meta.FindStatusCondition(conditions, TypeSuspendedPerses)
+	if !paused {
+		return suspended != nil
+	}
+	return suspended == nil || suspended.Status != metav1.ConditionTrue || suspended.Reason != string(ReasonReconcilePaused)
+}
+
+// DryRunCondition returns the DryRun condition of a resource whose changes are only planned,
+// listing the Perses instances in which it would be created or updated.
+func DryRunCondition(kind string, name string, planned []string) metav1.Condition {
+	if len(planned) == 0 {
+		return metav1.Condition{
+			Type: TypeDryRunPerses, Status: metav1.ConditionTrue,
+			Reason: string(ReasonNoChangePlanned), Message: fmt.Sprintf("Dry run: %s (%s) matches every available Perses instance", kind, name)}
+	}
+	return metav1.Condition{
+		Type: TypeDryRunPerses, Status: metav1.ConditionTrue,
+		Reason:  string(ReasonDryRun),
+		Message: fmt.Sprintf("Dry run: %s (%s) would be created or updated in: %s, see its events for the planned changes", kind, name, strings.Join(planned, ", "))}
+}
+
+// FindInstanceStatus returns the sync status recorded for the Perses instance, if any.
+func FindInstanceStatus(instances []v1alpha2.PersesInstanceStatus, perses v1alpha2.Perses) (v1alpha2.PersesInstanceStatus, bool) {
+	i := slices.IndexFunc(instances, func(status v1alpha2.PersesInstanceStatus) bool {
+		return status.Namespace == perses.Namespace && status.Name == perses.Name
+	})
+	if i < 0 {
+		return v1alpha2.PersesInstanceStatus{Namespace: perses.Namespace, Name: perses.Name}, false
+	}
+	return instances[i], true
+}
+
+// SortInstanceStatuses sorts the instance statuses by namespace and name of the Perses instance.
+func SortInstanceStatuses(instances []v1alpha2.PersesInstanceStatus) {
+	slices.SortFunc(instances, func(a, b v1alpha2.PersesInstanceStatus) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name))
+	})
+}
+
+// SetInstanceStatus replaces the sync status of the same Perses instance in instances,
+// or appends it when the instance is not part of them yet.
+func SetInstanceStatus(instances []v1alpha2.PersesInstanceStatus, status v1alpha2.PersesInstanceStatus) []v1alpha2.PersesInstanceStatus {
+	for i := range instances {
+		if instances[i].Namespace == status.Namespace && instances[i].Name == status.Name {
+			instances[i] = status
+			return instances
+		}
+	}
+	return append(instances, status)
+}
+
+// SyncedInstanceStatus returns the status of a resource successfully synced at the given
+// generation. The last sync time only moves when the resource was written to the instance,
+// i.e. when the generation changed or a drift was corrected, so that reconciling an
+// unchanged resource does not update its status.
+func SyncedInstanceStatus(instances []v1alpha2.PersesInstanceStatus, perses v1alpha2.Perses, generation int64, drifted bool, url string) v1alpha2.PersesInstanceStatus {
+	status, found := FindInstanceStatus(instances, perses)
+	unchanged := found && status.State == v1alpha2.InstanceSyncStateSynced && status.ObservedGeneration == generation && !drifted
+	if !unchanged || status.LastSyncTime == nil {
+		now := metav1.Now()
+		status.LastSyncTime = &now
+	}
+	status.State = v1alpha2.InstanceSyncStateSynced
+	status.ObservedGeneration = generation
+	status.LastErrorReason = ""
+	status.URL = url
+	return status
+}
+
+// VerifiedInstanceStatus records that the content with the given hash was compared with,
+// or written to, the Perses instance, so that it is not called again before the next verification.
+func VerifiedInstanceStatus(status v1alpha2.PersesInstanceStatus, hash string) v1alpha2.PersesInstanceStatus {
+	now := metav1.Now()
+	status.LastAppliedHash = hash
+	status.LastVerifiedTime = &now
+	return status
+}
+
+// FailedInstanceStatus returns the status of a resource that failed to sync to the
+// Perses instance. The last successful sync is kept.
+func FailedInstanceStatus(instances []v1alpha2.PersesInstanceStatus, perses v1alpha2.Perses, reason ConditionStatusReason) v1alpha2.PersesInstanceStatus {
+	status, _ := FindInstanceStatus(instances, perses)
+	status.State = v1alpha2.InstanceSyncStateFailed
+	status.LastErrorReason = string(reason)
+	return status
+}
+
+// UnavailableInstanceStatus returns the status of a resource selecting a Perses instance
+// that is not available. The last successful sync is kept.
+func UnavailableInstanceStatus(instances []v1alpha2.PersesInstanceStatus, perses v1alpha2.Perses) v1alpha2.PersesInstanceStatus {
+	status, _ := FindInstanceStatus(instances, perses)
+	status.State = v1alpha2.InstanceSyncStateUnavailable
+	return status
+}
+
+// NotAllowedInstanceStatus returns the status of a resource rejected by the resource
+// selectors of the Perses instance. The resource is removed from the instance, so its
+// last sync is not kept.
+func NotAllowedInstanceStatus(perses v1alpha2.Perses) v1alpha2.PersesInstanceStatus {
+	return v1alpha2.PersesInstanceStatus{Namespace: perses.Namespace, Name: perses.Name, State: v1alpha2.InstanceSyncStateNotAllowed}
+}
+
+// DashboardURL returns the address of a dashboard in the Perses instance.
+func DashboardURL(perses v1alpha2.Perses, project string, name string) string {
+	return fmt.Sprintf("%s/projects/%s/dashboards/%s",
+		strings.TrimSuffix(InstanceURL(perses), "/"), url.PathEscape(project), url.PathEscape(name))
+}
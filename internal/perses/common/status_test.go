@@ -20,6 +20,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
 )
 
 func makeCondition(typ string, status metav1.ConditionStatus, reason, message string) metav1.Condition {
@@ -152,3 +154,189 @@ func TestSetStatusConditionIdempotency(t *testing.T) {
 	assert.False(t, ConditionsChanged(snapshot, conditions),
 		"re-applying identical condition should not register as changed")
 }
+
+func TestInstanceReferences(t *testing.T) {
+	a := v1alpha2.PersesInstanceReference{Namespace: "monitoring", Name: "perses"}
+	b := v1alpha2.PersesInstanceReference{Namespace: "default", Name: "perses"}
+
+	instances := AddInstanceReference(nil, a)
+	instances = AddInstanceReference(instances, b)
+	instances = AddInstanceReference(instances, a)
+	assert.Equal(t, []v1alpha2.PersesInstanceReference{a, b}, instances)
+	assert.True(t, HasInstanceReference(instances, b))
+
+	remaining := RemoveInstanceReferences(instances, []v1alpha2.PersesInstanceReference{a})
+	assert.Equal(t, []v1alpha2.PersesInstanceReference{b}, remaining)
+	assert.Equal(t, []v1alpha2.PersesInstanceReference{a, b}, instances, "removal should not modify the input")
+	assert.Empty(t, RemoveInstanceReferences(remaining, remaining))
+}
+
+func TestDeselectedInstances(t *testing.T) {
+	a := v1alpha2.PersesInstanceReference{Namespace: "monitoring", Name: "perses"}
+	b := v1alpha2.PersesInstanceReference{Namespace: "default", Name: "perses"}
+	selected := []v1alpha2.Perses{{ObjectMeta: metav1.ObjectMeta{Namespace: "monitoring", Name: "perses"}}}
+
+	assert.Equal(t, []v1alpha2.PersesInstanceReference{b}, DeselectedInstances([]v1alpha2.PersesInstanceReference{a, b}, selected))
+	assert.Empty(t, DeselectedInstances([]v1alpha2.PersesInstanceReference{a}, selected))
+	assert.Equal(t, []v1alpha2.PersesInstanceReference{a}, DeselectedInstances([]v1alpha2.PersesInstanceReference{a}, nil))
+}
+
+func TestInstanceReference(t *testing.T) {
+	perses := v1alpha2.Perses{ObjectMeta: metav1.ObjectMeta{Namespace: "monitoring", Name: "perses"}}
+	assert.Equal(t, v1alpha2.PersesInstanceReference{Namespace: "monitoring", Name: "perses"}, InstanceReference(perses))
+}
+
+func TestSyncedAtGeneration(t *testing.T) {
+	available := func(status metav1.ConditionStatus, generation int64) []metav1.Condition {
+		return []metav1.Condition{{Type: TypeAvailablePerses, Status: status, ObservedGeneration: generation}}
+	}
+
+	assert.True(t, SyncedAtGeneration(available(metav1.ConditionTrue, 2), 2))
+	assert.False(t, SyncedAtGeneration(available(metav1.ConditionTrue, 1), 2), "the custom resource changed since the last sync")
+	assert.False(t, SyncedAtGeneration(available(metav1.ConditionFalse, 2), 2), "the last sync failed")
+	assert.False(t, SyncedAtGeneration(nil, 2))
+}
+
+func TestDriftCondition(t *testing.T) {
+	noDrift := DriftCondition("Dashboard", "overview", nil)
+	assert.Equal(t, metav1.ConditionFalse, noDrift.Status)
+	assert.Equal(t, string(ReasonNoDrift), noDrift.Reason)
+
+	drifted := DriftCondition("Dashboard", "overview", []string{"monitoring/perses", "monitoring/perses-2"})
+	assert.Equal(t, metav1.ConditionTrue, drifted.Status)
+	assert.Equal(t, string(ReasonDriftCorrected), drifted.Reason)
+	assert.Equal(t, "Dashboard (overview) was modified in Perses and restored in: monitoring/perses, monitoring/perses-2", drifted.Message)
+}
+
+func TestNotAllowedCondition(t *testing.T) {
+	allowed := NotAllowedCondition("Dashboard", "overview", nil)
+	assert.Equal(t, metav1.ConditionFalse, allowed.Status)
+	assert.Equal(t, string(ReasonAllowedByAllInstances), allowed.Reason)
+
+	notAllowed := NotAllowedCondition("Dashboard", "overview", []string{"monitoring/perses"})
+	assert.Equal(t, metav1.ConditionTrue, notAllowed.Status)
+	assert.Equal(t, string(ReasonNotAllowedByInstance), notAllowed.Reason)
+	assert.Equal(t, "Dashboard (overview) is not allowed by the resource selectors of: monitoring/perses", notAllowed.Message)
+}
+
+func TestIsReconcilePaused(t *testing.T) {
+	paused := &v1alpha2.PersesDashboard{ObjectMeta: metav1.ObjectMeta{
+		Annotations: map[string]string{PersesReconcileAnnotation: PersesReconcilePaused},
+	}}
+	assert.True(t, IsReconcilePaused(paused))
+	assert.Equal(t, string(ReasonReconcilePaused), SuspendedCondition("Dashboard", "overview").Reason)
+
+	assert.False(t, IsReconcilePaused(&v1alpha2.PersesDashboard{}))
+	assert.False(t, IsReconcilePaused(&v1alpha2.PersesDashboard{ObjectMeta: metav1.ObjectMeta{
+		Annotations: map[string]string{PersesReconcileAnnotation: "enabled"},
+	}}))
+
+	now := metav1.Now()
+	paused.DeletionTimestamp = &now
+	assert.False(t, IsReconcilePaused(paused), "a paused resource is still removed when deleted")
+}
+
+func TestSuspendedStatusChanged(t *testing.T) {
+	suspended := []metav1.Condition{SuspendedCondition("Dashboard", "overview")}
+
+	assert.True(t, SuspendedStatusChanged(nil, true))
+	assert.False(t, SuspendedStatusChanged(suspended, true))
+	assert.True(t, SuspendedStatusChanged(suspended, false))
+	assert.False(t, SuspendedStatusChanged(nil, false))
+}
+
+func TestIsDryRun(t *testing.T) {
+	dryRun := &v1alpha2.PersesDashboard{ObjectMeta: metav1.ObjectMeta{
+		Annotations: map[string]string{PersesDryRunAnnotation: PersesDryRunEnabled},
+	}}
+	assert.True(t, IsDryRun(dryRun, false))
+	assert.True(t, IsDryRun(&v1alpha2.PersesDashboard{}, true), "the operator flag applies to every resource")
+	assert.False(t, IsDryRun(&v1alpha2.PersesDashboard{}, false))
+}
+
+func TestDryRunCondition(t *testing.T) {
+	noChange := DryRunCondition("Dashboard", "overview", nil)
+	assert.Equal(t, metav1.ConditionTrue, noChange.Status)
+	assert.Equal(t, string(ReasonNoChangePlanned), noChange.Reason)
+
+	planned := DryRunCondition("Dashboard", "overview", []string{"monitoring/perses"})
+	assert.Equal(t, metav1.ConditionTrue, planned.Status)
+	assert.Equal(t, string(ReasonDryRun), planned.Reason)
+	assert.Contains(t, planned.Message, "monitoring/perses")
+}
+
+func TestSyncedInstanceStatus(t *testing.T) {
+	perses := v1alpha2.Perses{ObjectMeta: metav1.ObjectMeta{Name: "perses", Namespace: "monitoring"}}
+	lastSync := metav1.NewTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	instances := []v1alpha2.PersesInstanceStatus{{
+		Namespace: "monitoring", Name: "perses", State: v1alpha2.InstanceSyncStateSynced,
+		LastSyncTime: &lastSync, ObservedGeneration: 2,
+	}}
+
+	unchanged := SyncedInstanceStatus(instances, perses, 2, false, "")
+	assert.Equal(t, instances[0], unchanged)
+
+	updated := SyncedInstanceStatus(instances, perses, 3, false, "")
+	assert.Equal(t, int64(3), updated.ObservedGeneration)
+	assert.True(t, updated.LastSyncTime.After(lastSync.Time))
+
+	drifted := SyncedInstanceStatus(instances, perses, 2, true, "")
+	assert.True(t, drifted.LastSyncTime.After(lastSync.Time))
+
+	recorded := SyncedInstanceStatus(nil, perses, 1, false, "")
+	assert.Equal(t, "monitoring", recorded.Namespace)
+	assert.Equal(t, "perses", recorded.Name)
+	assert.NotNil(t, recorded.LastSyncTime)
+}
+
+func TestVerifiedInstanceStatus(t *testing.T) {
+	lastSync := metav1.NewTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	status := v1alpha2.PersesInstanceStatus{Namespace: "monitoring", Name: "perses", LastSyncTime: &lastSync}
+
+	verified := VerifiedInstanceStatus(status, "hash")
+	assert.Equal(t, "hash", verified.LastAppliedHash)
+	assert.NotNil(t, verified.LastVerifiedTime)
+	assert.Equal(t, &lastSync, verified.LastSyncTime, "verifying does not move the last sync time")
+}
+
+func TestFailedInstanceStatus(t *testing.T) {
+	perses := v1alpha2.Perses{ObjectMeta: metav1.ObjectMeta{Name: "perses", Namespace: "monitoring"}}
+	lastSync := metav1.NewTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	instances := []v1alpha2.PersesInstanceStatus{{
+		Namespace: "monitoring", Name: "perses", State: v1alpha2.InstanceSyncStateSynced,
+		LastSyncTime: &lastSync, ObservedGeneration: 2,
+	}}
+
+	failed := FailedInstanceStatus(instances, perses, ReasonBackendError)
+	assert.Equal(t, v1alpha2.InstanceSyncStateFailed, failed.State)
+	assert.Equal(t, string(ReasonBackendError), failed.LastErrorReason)
+	assert.Equal(t, &lastSync, failed.LastSyncTime)
+	assert.Equal(t, int64(2), failed.ObservedGeneration)
+
+	instances = SetInstanceStatus(instances, failed)
+	assert.Len(t, instances, 1)
+	assert.Equal(t, failed, instances[0])
+
+	synced := SyncedInstanceStatus(instances, perses, 2, false, "")
+	assert.Empty(t, synced.LastErrorReason)
+
+	other := v1alpha2.Perses{ObjectMeta: metav1.ObjectMeta{Name: "perses", Namespace: "staging"}}
+	instances = SetInstanceStatus(instances, UnavailableInstanceStatus(instances, other))
+	assert.Len(t, instances, 2)
+	assert.Equal(t, v1alpha2.InstanceSyncStateUnavailable, instances[1].State)
+
+	notAllowed := NotAllowedInstanceStatus(perses)
+	assert.Equal(t, v1alpha2.InstanceSyncStateNotAllowed, notAllowed.State)
+	assert.Nil(t, notAllowed.LastSyncTime)
+	assert.Zero(t, notAllowed.ObservedGeneration)
+
+	instances = SetInstanceStatus(instances, notAllowed)
+	assert.Len(t, instances, 2)
+	assert.Equal(t, v1alpha2.InstanceSyncStateNotAllowed, instances[0].State)
+}
+
+func TestDashboardURL(t *testing.T) {
+	perses := v1alpha2.Perses{ObjectMeta: metav1.ObjectMeta{Name: "perses", Namespace: "monitoring"}}
+	assert.Equal(t, "http://perses.monitoring.svc.cluster.local:8080/projects/default/dashboards/overview",
+		DashboardURL(perses, "default", "overview"))
+}
@@ -14,29 +14,158 @@
 package common
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"maps"
+	"slices"
+	"time"
 
+	"github.com/perses/common/set"
 	persesv1 "github.com/perses/perses/pkg/model/api/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
 )
 
 // DashboardInSync returns true if the existing dashboard in Perses
 // matches the desired state (tags and spec).
 func DashboardInSync(existing, desired *persesv1.Dashboard) bool {
-	return maps.Equal(existing.Metadata.Tags, desired.Metadata.Tags) &&
+	return tagsInSync(existing.Metadata.Tags, desired.Metadata.Tags) &&
 		equality.Semantic.DeepEqual(existing.Spec, desired.Spec)
 }
 
 // DatasourceInSync returns true if the existing datasource in Perses
 // matches the desired state (tags and spec).
 func DatasourceInSync(existing, desired *persesv1.Datasource) bool {
-	return maps.Equal(existing.Metadata.Tags, desired.Metadata.Tags) &&
+	return tagsInSync(existing.Metadata.Tags, desired.Metadata.Tags) &&
 		equality.Semantic.DeepEqual(existing.Spec, desired.Spec)
 }
 
 // GlobalDatasourceInSync returns true if the existing global datasource
 // in Perses matches the desired state (tags and spec).
 func GlobalDatasourceInSync(existing, desired *persesv1.GlobalDatasource) bool {
-	return maps.Equal(existing.Metadata.Tags, desired.Metadata.Tags) &&
+	return tagsInSync(existing.Metadata.Tags, desired.Metadata.Tags) &&
+		equality.Semantic.DeepEqual(existing.Spec, desired.Spec)
+}
+
+// VariableInSync returns true if the existing variable in Perses
+// matches the desired state (tags and spec).
+func VariableInSync(existing, desired *persesv1.Variable) bool {
+	return tagsInSync(existing.Metadata.Tags, desired.Metadata.Tags) &&
+		equality.Semantic.DeepEqual(existing.Spec, desired.Spec)
+}
+
+// GlobalVariableInSync returns true if the existing global variable
+// in Perses matches the desired state (tags and spec).
+func GlobalVariableInSync(existing, desired *persesv1.GlobalVariable) bool {
+	return tagsInSync(existing.Metadata.Tags, desired.Metadata.Tags) &&
+		equality.Semantic.DeepEqual(existing.Spec, desired.Spec)
+}
+
+// RoleInSync returns true if the existing role in Perses
+// matches the desired state (tags and permissions).
+func RoleInSync(existing, desired *persesv1.Role) bool {
+	return tagsInSync(existing.Metadata.Tags, desired.Metadata.Tags) &&
+		equality.Semantic.DeepEqual(existing.Spec, desired.Spec)
+}
+
+// RoleBindingInSync returns true if the existing role binding in Perses
+// matches the desired state (tags, role and subjects).
+func RoleBindingInSync(existing, desired *persesv1.RoleBinding) bool {
+	return tagsInSync(existing.Metadata.Tags, desired.Metadata.Tags) &&
+		equality.Semantic.DeepEqual(existing.Spec, desired.Spec)
+}
+
+// GlobalRoleInSync returns true if the existing global role in Perses
+// matches the desired state (tags and permissions).
+func GlobalRoleInSync(existing, desired *persesv1.GlobalRole) bool {
+	return tagsInSync(existing.Metadata.Tags, desired.Metadata.Tags) &&
 		equality.Semantic.DeepEqual(existing.Spec, desired.Spec)
 }
+
+// GlobalRoleBindingInSync returns true if the existing global role binding in Perses
+// matches the desired state (tags, role and subjects).
+func GlobalRoleBindingInSync(existing, desired *persesv1.GlobalRoleBinding) bool {
+	return tagsInSync(existing.Metadata.Tags, desired.Metadata.Tags) &&
+		equality.Semantic.DeepEqual(existing.Spec, desired.Spec)
+}
+
+// ProjectInSync returns true if the existing project in Perses
+// matches the desired state (display name and description).
+func ProjectInSync(existing, desired *persesv1.Project) bool {
+	return equality.Semantic.DeepEqual(existing.Spec, desired.Spec)
+}
+
+// ResyncPeriod returns the period after which a resource is synced again to correct drift:
+// its resyncPeriodSeconds when set, the operator-wide period otherwise. 0 disables the resync.
+func ResyncPeriod(resyncPeriodSeconds *int32, defaultPeriod time.Duration) time.Duration {
+	if resyncPeriodSeconds != nil {
+		return time.Duration(*resyncPeriodSeconds) * time.Second
+	}
+	return defaultPeriod
+}
+
+// VerificationPeriod returns the period after which a resource whose content was already
+// applied to a Perses instance is compared with its copy in Perses again: the verification
+// period, or the resync period of the resource when shorter, so that a resync always corrects
+// the drift. 0 disables the skipping of the resources already applied.
+func VerificationPeriod(resyncPeriod time.Duration, verificationPeriod time.Duration) time.Duration {
+	if verificationPeriod <= 0 {
+		return 0
+	}
+	if resyncPeriod > 0 && resyncPeriod < verificationPeriod {
+		return resyncPeriod
+	}
+	return verificationPeriod
+}
+
+// AppliedHash returns the hash of the content applied to a Perses instance from a custom
+// resource: its spec and tags. The UID of the instance is part of it, so that a Perses
+// instance recreated under the same name gets the resource again.
+func AppliedHash(perses v1alpha2.Perses, spec any, tags set.Set[string]) string {
+	content, err := json.Marshal(struct {
+		Instance types.UID `json:"instance"`
+		Tags     []string  `json:"tags"`
+		Spec     any       `json:"spec"`
+	}{perses.UID, slices.Sorted(maps.Keys(tags)), spec})
+	if err != nil {
+		// A content that cannot be hashed is never considered applied.
+		return ""
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// AlreadyApplied reports whether the content with the given hash was synced to the Perses
+// instance at the given generation, and compared with its copy in Perses less than period
+// ago, in which case the calls to Perses are skipped.
+func AlreadyApplied(instances []v1alpha2.PersesInstanceStatus, perses v1alpha2.Perses, generation int64, hash string, period time.Duration) bool {
+	if period <= 0 || hash == "" {
+		return false
+	}
+	status, found := FindInstanceStatus(instances, perses)
+	return found && status.State == v1alpha2.InstanceSyncStateSynced &&
+		status.ObservedGeneration == generation && status.LastAppliedHash == hash &&
+		status.LastVerifiedTime != nil && time.Since(status.LastVerifiedTime.Time) < period
+}
+
+// tagsInSync returns true if the tags of the existing object in Perses match the desired ones.
+// An object the operator claims must carry the ManagedTag, so that the objects written by an
+// earlier version of the operator get it. Otherwise the ManagedTag is left out of the comparison.
+func tagsInSync(existing, desired map[string]struct{}) bool {
+	if IsManaged(desired) && !IsManaged(existing) {
+		return false
+	}
+	return maps.Equal(withoutManagedTag(existing), withoutManagedTag(desired))
+}
+
+func withoutManagedTag(tags map[string]struct{}) map[string]struct{} {
+	if _, ok := tags[ManagedTag]; !ok {
+		return tags
+	}
+	result := maps.Clone(tags)
+	delete(result, ManagedTag)
+	return result
+}
@@ -19,11 +19,16 @@ import (
 
 	"github.com/perses/common/set"
 	persesv1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/role"
 	speccommon "github.com/perses/spec/go/common"
 	specDashboard "github.com/perses/spec/go/dashboard"
 	specdatasource "github.com/perses/spec/go/datasource"
 	specplugin "github.com/perses/spec/go/plugin"
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
 )
 
 func TestDashboardInSync(t *testing.T) {
@@ -61,6 +66,14 @@ func TestDashboardInSync(t *testing.T) {
 		assert.False(t, DashboardInSync(existing, desired))
 	})
 
+	t.Run("managed tag only", func(t *testing.T) {
+		existing := baseDashboard()
+		desired := baseDashboard()
+		desired.Metadata.Tags = WithManagedTag(desired.Metadata.Tags)
+		assert.False(t, DashboardInSync(existing, desired), "a claimed dashboard must carry the ownership tag")
+		assert.True(t, DashboardInSync(desired, existing), "the ownership tag is not removed")
+	})
+
 	t.Run("extra metadata on existing does not affect comparison", func(t *testing.T) {
 		existing := baseDashboard()
 		existing.Metadata.Version = 5
@@ -173,3 +186,124 @@ func TestGlobalDatasourceInSync(t *testing.T) {
 		assert.True(t, GlobalDatasourceInSync(existing, baseGlobalDS()))
 	})
 }
+
+func TestRoleInSync(t *testing.T) {
+	baseRole := func() *persesv1.Role {
+		return &persesv1.Role{
+			Kind: persesv1.KindRole,
+			Metadata: persesv1.ProjectMetadata{
+				Metadata: persesv1.Metadata{
+					Name: "test-role",
+					Tags: WithManagedTag(nil),
+				},
+			},
+			Spec: persesv1.RoleSpec{
+				Permissions: []role.Permission{{
+					Actions: []role.Action{role.ReadAction},
+					Scopes:  []role.Scope{role.DashboardScope},
+				}},
+			},
+		}
+	}
+
+	t.Run("identical roles", func(t *testing.T) {
+		assert.True(t, RoleInSync(baseRole(), baseRole()))
+	})
+
+	t.Run("different permissions", func(t *testing.T) {
+		existing := baseRole()
+		desired := baseRole()
+		desired.Spec.Permissions[0].Actions = []role.Action{role.WildcardAction}
+		assert.False(t, RoleInSync(existing, desired))
+	})
+
+	t.Run("managed tag missing", func(t *testing.T) {
+		existing := baseRole()
+		existing.Metadata.Tags = nil
+		assert.False(t, RoleInSync(existing, baseRole()), "a claimed role must carry the ownership tag")
+	})
+}
+
+func TestRoleBindingInSync(t *testing.T) {
+	baseRoleBinding := func() *persesv1.RoleBinding {
+		return &persesv1.RoleBinding{
+			Kind: persesv1.KindRoleBinding,
+			Metadata: persesv1.ProjectMetadata{
+				Metadata: persesv1.Metadata{
+					Name: "test-rolebinding",
+					Tags: WithManagedTag(nil),
+				},
+			},
+			Spec: persesv1.RoleBindingSpec{
+				Role:     "test-role",
+				Subjects: []persesv1.Subject{{Kind: persesv1.KindUser, Name: "alice"}},
+			},
+		}
+	}
+
+	t.Run("identical role bindings", func(t *testing.T) {
+		assert.True(t, RoleBindingInSync(baseRoleBinding(), baseRoleBinding()))
+	})
+
+	t.Run("different subjects", func(t *testing.T) {
+		existing := baseRoleBinding()
+		desired := baseRoleBinding()
+		desired.Spec.Subjects = append(desired.Spec.Subjects, persesv1.Subject{Kind: persesv1.KindUser, Name: "bob"})
+		assert.False(t, RoleBindingInSync(existing, desired))
+	})
+
+	t.Run("managed tag missing", func(t *testing.T) {
+		existing := baseRoleBinding()
+		existing.Metadata.Tags = nil
+		assert.False(t, RoleBindingInSync(existing, baseRoleBinding()), "a claimed role binding must carry the ownership tag")
+	})
+}
+
+func TestResyncPeriod(t *testing.T) {
+	assert.Equal(t, 10*time.Minute, ResyncPeriod(nil, 10*time.Minute))
+	assert.Equal(t, 30*time.Second, ResyncPeriod(ptr.To[int32](30), 10*time.Minute))
+	assert.Equal(t, time.Duration(0), ResyncPeriod(ptr.To[int32](0), 10*time.Minute), "0 disables the resync")
+}
+
+func TestVerificationPeriod(t *testing.T) {
+	assert.Equal(t, 10*time.Minute, VerificationPeriod(0, 10*time.Minute))
+	assert.Equal(t, 5*time.Minute, VerificationPeriod(5*time.Minute, 10*time.Minute), "a shorter resync period verifies on every resync")
+	assert.Equal(t, 10*time.Minute, VerificationPeriod(time.Hour, 10*time.Minute))
+	assert.Equal(t, time.Duration(0), VerificationPeriod(5*time.Minute, 0), "0 disables the skipping")
+}
+
+func TestAppliedHash(t *testing.T) {
+	perses := v1alpha2.Perses{ObjectMeta: metav1.ObjectMeta{Name: "perses", Namespace: "monitoring", UID: "1"}}
+	spec := specDashboard.Spec{Duration: "1h"}
+	hash := AppliedHash(perses, spec, set.New("a", "b"))
+
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, AppliedHash(perses, spec, set.New("b", "a")))
+	assert.NotEqual(t, hash, AppliedHash(perses, specDashboard.Spec{Duration: "2h"}, set.New("a", "b")))
+	assert.NotEqual(t, hash, AppliedHash(perses, spec, set.New("a")))
+
+	recreated := perses
+	recreated.UID = "2"
+	assert.NotEqual(t, hash, AppliedHash(recreated, spec, set.New("a", "b")), "a recreated instance gets the resource again")
+}
+
+func TestAlreadyApplied(t *testing.T) {
+	perses := v1alpha2.Perses{ObjectMeta: metav1.ObjectMeta{Name: "perses", Namespace: "monitoring"}}
+	verified := metav1.NewTime(time.Now().Add(-time.Minute))
+	instances := []v1alpha2.PersesInstanceStatus{{
+		Namespace: "monitoring", Name: "perses", State: v1alpha2.InstanceSyncStateSynced,
+		ObservedGeneration: 2, LastAppliedHash: "hash", LastVerifiedTime: &verified,
+	}}
+
+	assert.True(t, AlreadyApplied(instances, perses, 2, "hash", 10*time.Minute))
+	assert.False(t, AlreadyApplied(instances, perses, 3, "hash", 10*time.Minute), "a new generation is synced")
+	assert.False(t, AlreadyApplied(instances, perses, 2, "other", 10*time.Minute), "new tags are synced")
+	assert.False(t, AlreadyApplied(instances, perses, 2, "hash", 30*time.Second), "the verification is due")
+	assert.False(t, AlreadyApplied(instances, perses, 2, "hash", 0), "the skipping is disabled")
+
+	other := v1alpha2.Perses{ObjectMeta: metav1.ObjectMeta{Name: "perses", Namespace: "staging"}}
+	assert.False(t, AlreadyApplied(instances, other, 2, "hash", 10*time.Minute))
+
+	instances[0].State = v1alpha2.InstanceSyncStateFailed
+	assert.False(t, AlreadyApplied(instances, perses, 2, "hash", 10*time.Minute), "a failed instance is synced again")
+}
@@ -14,9 +14,12 @@
 package common
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/perses/common/set"
+
+	"github.com/perses/perses-operator/api/v1alpha2"
 )
 
 const TagsAnnotation = PersesNamespaceDomain + "/tags"
@@ -40,3 +43,40 @@ func ParseTags(annotations map[string]string) set.Set[string] {
 
 	return tags
 }
+
+// ManagedTag marks the objects created in Perses by the operator, so that the
+// orphan sweeper never removes an object it does not own.
+const ManagedTag = "managed-by-" + PersesManagedByValue
+
+// WithManagedTag returns the given tags with the ManagedTag added.
+func WithManagedTag(tags set.Set[string]) set.Set[string] {
+	if tags == nil {
+		tags = set.New[string]()
+	}
+	tags.Add(ManagedTag)
+	return tags
+}
+
+// IsManaged returns true if the given tags carry the ManagedTag.
+func IsManaged(tags set.Set[string]) bool {
+	return tags.Contains(ManagedTag)
+}
+
+// ClaimOwnership returns whether the object written to Perses must carry the ManagedTag.
+// existingTags are the tags of the object of the same name already in Perses, if any.
+// An object the operator does not own is handled according to the conflict policy,
+// which defaults to Adopt. With Fail, an error is returned and nothing must be written.
+func ClaimOwnership(policy v1alpha2.ConflictPolicy, kind string, name string, exists bool, existingTags set.Set[string]) (bool, error) {
+	if !exists || IsManaged(existingTags) {
+		return true, nil
+	}
+
+	switch policy {
+	case v1alpha2.ConflictPolicyFail:
+		return false, fmt.Errorf("%s %q already exists in Perses and is not managed by the operator", kind, name)
+	case v1alpha2.ConflictPolicyOverwrite:
+		return false, nil
+	default:
+		return true, nil
+	}
+}
@@ -16,6 +16,8 @@ package common
 import (
 	"github.com/perses/common/set"
 
+	"github.com/perses/perses-operator/api/v1alpha2"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -71,3 +73,40 @@ var _ = Describe("ParseTags", func() {
 			set.New("oncall")),
 	)
 })
+
+var _ = Describe("WithManagedTag", func() {
+	It("creates the set when there are no tags", func() {
+		Expect(WithManagedTag(nil)).To(Equal(set.New(ManagedTag)))
+	})
+
+	It("keeps the existing tags", func() {
+		tags := WithManagedTag(ParseTags(map[string]string{TagsAnnotation: "oncall"}))
+		Expect(tags).To(Equal(set.New("oncall", ManagedTag)))
+		Expect(IsManaged(tags)).To(BeTrue())
+	})
+
+	It("does not consider untagged objects as managed", func() {
+		Expect(IsManaged(nil)).To(BeFalse())
+		Expect(IsManaged(set.New("oncall"))).To(BeFalse())
+	})
+})
+
+var _ = Describe("ClaimOwnership", func() {
+	DescribeTable("decides whether the object written to Perses is managed",
+		func(policy v1alpha2.ConflictPolicy, exists bool, existingTags set.Set[string], expectedManaged bool, expectedErr bool) {
+			managed, err := ClaimOwnership(policy, "dashboard", "overview", exists, existingTags)
+			Expect(managed).To(Equal(expectedManaged))
+			if expectedErr {
+				Expect(err).To(MatchError(ContainSubstring(`dashboard "overview" already exists`)))
+			} else {
+				Expect(err).ToNot(HaveOccurred())
+			}
+		},
+		Entry("new object", v1alpha2.ConflictPolicyFail, false, nil, true, false),
+		Entry("managed object", v1alpha2.ConflictPolicyFail, true, set.New(ManagedTag), true, false),
+		Entry("unmanaged object with Adopt", v1alpha2.ConflictPolicyAdopt, true, set.New("oncall"), true, false),
+		Entry("unmanaged object without policy", v1alpha2.ConflictPolicy(""), true, nil, true, false),
+		Entry("unmanaged object with Overwrite", v1alpha2.ConflictPolicyOverwrite, true, nil, false, false),
+		Entry("unmanaged object with Fail", v1alpha2.ConflictPolicyFail, true, nil, false, true),
+	)
+})
@@ -25,6 +25,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // GetVolumes returns the volumes needed for the Perses container
@@ -225,3 +226,72 @@ func GetProvisioningHash(perses *v1alpha2.Perses) (string, error) {
 
 	return rand.SafeEncodeString(fmt.Sprint(sha256.Sum256(data))), nil
 }
+
+// ConfigSource is a Secret or ConfigMap, in the namespace of the Perses instance, whose content
+// is mounted in or injected into the Perses container.
+type ConfigSource struct {
+	Type v1alpha2.SecretSourceType `json:"type"`
+	Name string                    `json:"name"`
+	// Data is the content of the source, filled in by the caller. The values of a ConfigMap
+	// are stored as bytes, like the ones of a Secret.
+	Data map[string][]byte `json:"data,omitempty"`
+	// Missing is true when the source does not exist.
+	Missing bool `json:"missing,omitempty"`
+}
+
+// GetConfigSources returns the Secrets and ConfigMaps the Perses container reads its
+// configuration from: the TLS certificates when TLS is enabled and the envFrom sources.
+func GetConfigSources(perses *v1alpha2.Perses) []ConfigSource {
+	var sources []ConfigSource
+
+	if isTLSEnabled(perses) {
+		for _, cert := range []*v1alpha2.Certificate{perses.Spec.TLS.CaCert, perses.Spec.TLS.UserCert} {
+			if cert == nil || cert.Name == nil || *cert.Name == "" {
+				continue
+			}
+			if cert.Type == v1alpha2.SecretSourceTypeSecret || cert.Type == v1alpha2.SecretSourceTypeConfigMap {
+				sources = append(sources, ConfigSource{Type: cert.Type, Name: *cert.Name})
+			}
+		}
+	}
+
+	for _, envFrom := range perses.Spec.EnvFrom {
+		if envFrom.SecretRef != nil {
+			sources = append(sources, ConfigSource{Type: v1alpha2.SecretSourceTypeSecret, Name: envFrom.SecretRef.Name})
+		}
+		if envFrom.ConfigMapRef != nil {
+			sources = append(sources, ConfigSource{Type: v1alpha2.SecretSourceTypeConfigMap, Name: envFrom.ConfigMapRef.Name})
+		}
+	}
+
+	return sources
+}
+
+// ConfigReferences reports whether the Perses container reads its configuration from the
+// given Secret or ConfigMap, depending on sourceType.
+func ConfigReferences(perses *v1alpha2.Perses, sourceType v1alpha2.SecretSourceType, obj client.Object) bool {
+	if perses.Namespace != obj.GetNamespace() {
+		return false
+	}
+	for _, source := range GetConfigSources(perses) {
+		if source.Type == sourceType && source.Name == obj.GetName() {
+			return true
+		}
+	}
+	return false
+}
+
+// GetConfigChecksum generates a hash of the rendered config.yaml and of the content of the
+// sources returned by GetConfigSources, so that any change of the effective configuration
+// changes the pod template.
+func GetConfigChecksum(config []byte, sources []ConfigSource) (string, error) {
+	data, err := json.Marshal(struct {
+		Config  []byte         `json:"config"`
+		Sources []ConfigSource `json:"sources"`
+	}{Config: config, Sources: sources})
+	if err != nil {
+		return "", err
+	}
+
+	return rand.SafeEncodeString(fmt.Sprint(sha256.Sum256(data))), nil
+}
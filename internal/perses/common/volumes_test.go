@@ -18,6 +18,7 @@ import (
 	persesconfig "github.com/perses/perses/pkg/model/api/config"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -160,3 +161,83 @@ var _ = Describe("GetVolumeMounts", func() {
 		),
 	)
 })
+
+var _ = Describe("GetConfigSources", func() {
+	It("returns the TLS certificates and the envFrom sources", func() {
+		perses := &v1alpha2.Perses{
+			ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			Spec: v1alpha2.PersesSpec{
+				TLS: &v1alpha2.TLS{
+					Enable: ptr.To(true),
+					CaCert: &v1alpha2.Certificate{
+						SecretSource: v1alpha2.SecretSource{Type: v1alpha2.SecretSourceTypeConfigMap, Name: ptr.To("ca")},
+						CertPath:     "ca.crt",
+					},
+					UserCert: &v1alpha2.Certificate{
+						SecretSource: v1alpha2.SecretSource{Type: v1alpha2.SecretSourceTypeSecret, Name: ptr.To("tls")},
+						CertPath:     "tls.crt",
+					},
+				},
+				EnvFrom: []corev1.EnvFromSource{
+					{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "env-secret"}}},
+					{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "env-config"}}},
+				},
+			},
+		}
+
+		Expect(GetConfigSources(perses)).To(Equal([]ConfigSource{
+			{Type: v1alpha2.SecretSourceTypeConfigMap, Name: "ca"},
+			{Type: v1alpha2.SecretSourceTypeSecret, Name: "tls"},
+			{Type: v1alpha2.SecretSourceTypeSecret, Name: "env-secret"},
+			{Type: v1alpha2.SecretSourceTypeConfigMap, Name: "env-config"},
+		}))
+	})
+
+	It("ignores the certificates when TLS is disabled or read from files", func() {
+		perses := &v1alpha2.Perses{
+			ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			Spec: v1alpha2.PersesSpec{
+				TLS: &v1alpha2.TLS{
+					Enable: ptr.To(true),
+					UserCert: &v1alpha2.Certificate{
+						SecretSource: v1alpha2.SecretSource{Type: v1alpha2.SecretSourceTypeFile},
+						CertPath:     "/certs/tls.crt",
+					},
+				},
+			},
+		}
+		Expect(GetConfigSources(perses)).To(BeEmpty())
+
+		perses.Spec.TLS.Enable = ptr.To(false)
+		perses.Spec.TLS.UserCert = &v1alpha2.Certificate{
+			SecretSource: v1alpha2.SecretSource{Type: v1alpha2.SecretSourceTypeSecret, Name: ptr.To("tls")},
+			CertPath:     "tls.crt",
+		}
+		Expect(GetConfigSources(perses)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("GetConfigChecksum", func() {
+	It("changes with the config and the content of the sources", func() {
+		sources := []ConfigSource{{Type: v1alpha2.SecretSourceTypeSecret, Name: "env", Data: map[string][]byte{"KEY": []byte("a")}}}
+
+		checksum, err := GetConfigChecksum([]byte("config"), sources)
+		Expect(err).NotTo(HaveOccurred())
+
+		again, err := GetConfigChecksum([]byte("config"), sources)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(again).To(Equal(checksum))
+
+		otherConfig, err := GetConfigChecksum([]byte("other"), sources)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(otherConfig).NotTo(Equal(checksum))
+
+		otherData, err := GetConfigChecksum([]byte("config"), []ConfigSource{{Type: v1alpha2.SecretSourceTypeSecret, Name: "env", Data: map[string][]byte{"KEY": []byte("b")}}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(otherData).NotTo(Equal(checksum))
+
+		missing, err := GetConfigChecksum([]byte("config"), []ConfigSource{{Type: v1alpha2.SecretSourceTypeSecret, Name: "env", Missing: true}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(missing).NotTo(Equal(checksum))
+	})
+})
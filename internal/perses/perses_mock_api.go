@@ -31,6 +31,8 @@ type MockClient struct {
 	v1.ClientInterface
 	mock.Mock
 	restClient *perseshttp.RESTClient
+	// Projects, when set, is returned by Project() instead of a stub that reports every project as existing
+	Projects v1.ProjectInterface
 }
 
 func (c *MockClient) RESTClient() *perseshttp.RESTClient {
@@ -88,9 +90,52 @@ func (p *project) Get(name string) (*modelv1.Project, error) {
 }
 
 func (c *MockClient) Project() v1.ProjectInterface {
+	if c.Projects != nil {
+		return c.Projects
+	}
 	return &project{}
 }
 
+type MockProject struct {
+	v1.ProjectInterface
+	mock.Mock
+}
+
+func (p *MockProject) Get(name string) (*modelv1.Project, error) {
+	args := p.Called(name)
+	return args.Get(0).(*modelv1.Project), args.Error(1)
+}
+
+func (p *MockProject) Create(project *modelv1.Project) (*modelv1.Project, error) {
+	args := p.Called(project)
+	return args.Get(0).(*modelv1.Project), args.Error(1)
+}
+
+func (p *MockProject) Update(project *modelv1.Project) (*modelv1.Project, error) {
+	args := p.Called(project)
+	return args.Get(0).(*modelv1.Project), args.Error(1)
+}
+
+func (p *MockProject) Delete(name string) error {
+	args := p.Called(name)
+	return args.Error(0)
+}
+
+type MockHealth struct {
+	v1.HealthInterface
+	mock.Mock
+}
+
+func (c *MockClient) Health() v1.HealthInterface {
+	args := c.Called()
+	return args.Get(0).(v1.HealthInterface)
+}
+
+func (h *MockHealth) Check() (*modelv1.Health, error) {
+	args := h.Called()
+	return args.Get(0).(*modelv1.Health), args.Error(1)
+}
+
 type MockDashboard struct {
 	v1.DashboardInterface
 	mock.Mock
@@ -121,6 +166,11 @@ func (d *MockDashboard) Create(dashboard *modelv1.Dashboard) (*modelv1.Dashboard
 	return args.Get(0).(*modelv1.Dashboard), args.Error(1)
 }
 
+func (d *MockDashboard) List(prefix string) ([]*modelv1.Dashboard, error) {
+	args := d.Called(prefix)
+	return args.Get(0).([]*modelv1.Dashboard), args.Error(1)
+}
+
 type MockDatasource struct {
 	v1.DatasourceInterface
 	mock.Mock
@@ -151,6 +201,11 @@ func (d *MockDatasource) Create(datasource *modelv1.Datasource) (*modelv1.Dataso
 	return args.Get(0).(*modelv1.Datasource), args.Error(1)
 }
 
+func (d *MockDatasource) List(prefix string) ([]*modelv1.Datasource, error) {
+	args := d.Called(prefix)
+	return args.Get(0).([]*modelv1.Datasource), args.Error(1)
+}
+
 type MockGlobalDatasource struct {
 	v1.GlobalDatasourceInterface
 	mock.Mock
@@ -181,6 +236,196 @@ func (d *MockGlobalDatasource) Create(datasource *modelv1.GlobalDatasource) (*mo
 	return args.Get(0).(*modelv1.GlobalDatasource), args.Error(1)
 }
 
+func (d *MockGlobalDatasource) List(prefix string) ([]*modelv1.GlobalDatasource, error) {
+	args := d.Called(prefix)
+	return args.Get(0).([]*modelv1.GlobalDatasource), args.Error(1)
+}
+
+type MockVariable struct {
+	v1.VariableInterface
+	mock.Mock
+}
+
+func (c *MockClient) Variable(project string) v1.VariableInterface {
+	args := c.Called(project)
+	return args.Get(0).(v1.VariableInterface)
+}
+
+func (d *MockVariable) Get(name string) (*modelv1.Variable, error) {
+	args := d.Called(name)
+	return args.Get(0).(*modelv1.Variable), args.Error(1)
+}
+
+func (d *MockVariable) Update(variable *modelv1.Variable) (*modelv1.Variable, error) {
+	args := d.Called(variable)
+	return args.Get(0).(*modelv1.Variable), args.Error(1)
+}
+
+func (d *MockVariable) Delete(name string) error {
+	args := d.Called(name)
+	return args.Error(0)
+}
+
+func (d *MockVariable) Create(variable *modelv1.Variable) (*modelv1.Variable, error) {
+	args := d.Called(variable)
+	return args.Get(0).(*modelv1.Variable), args.Error(1)
+}
+
+func (d *MockVariable) List(prefix string) ([]*modelv1.Variable, error) {
+	args := d.Called(prefix)
+	return args.Get(0).([]*modelv1.Variable), args.Error(1)
+}
+
+type MockGlobalVariable struct {
+	v1.GlobalVariableInterface
+	mock.Mock
+}
+
+func (c *MockClient) GlobalVariable() v1.GlobalVariableInterface {
+	args := c.Called()
+	return args.Get(0).(v1.GlobalVariableInterface)
+}
+
+func (d *MockGlobalVariable) Get(name string) (*modelv1.GlobalVariable, error) {
+	args := d.Called(name)
+	return args.Get(0).(*modelv1.GlobalVariable), args.Error(1)
+}
+
+func (d *MockGlobalVariable) Update(variable *modelv1.GlobalVariable) (*modelv1.GlobalVariable, error) {
+	args := d.Called(variable)
+	return args.Get(0).(*modelv1.GlobalVariable), args.Error(1)
+}
+
+func (d *MockGlobalVariable) Delete(name string) error {
+	args := d.Called(name)
+	return args.Error(0)
+}
+
+func (d *MockGlobalVariable) Create(variable *modelv1.GlobalVariable) (*modelv1.GlobalVariable, error) {
+	args := d.Called(variable)
+	return args.Get(0).(*modelv1.GlobalVariable), args.Error(1)
+}
+
+type MockRole struct {
+	v1.RoleInterface
+	mock.Mock
+}
+
+func (c *MockClient) Role(project string) v1.RoleInterface {
+	args := c.Called(project)
+	return args.Get(0).(v1.RoleInterface)
+}
+
+func (d *MockRole) Get(name string) (*modelv1.Role, error) {
+	args := d.Called(name)
+	return args.Get(0).(*modelv1.Role), args.Error(1)
+}
+
+func (d *MockRole) Update(role *modelv1.Role) (*modelv1.Role, error) {
+	args := d.Called(role)
+	return args.Get(0).(*modelv1.Role), args.Error(1)
+}
+
+func (d *MockRole) Delete(name string) error {
+	args := d.Called(name)
+	return args.Error(0)
+}
+
+func (d *MockRole) Create(role *modelv1.Role) (*modelv1.Role, error) {
+	args := d.Called(role)
+	return args.Get(0).(*modelv1.Role), args.Error(1)
+}
+
+type MockRoleBinding struct {
+	v1.RoleBindingInterface
+	mock.Mock
+}
+
+func (c *MockClient) RoleBinding(project string) v1.RoleBindingInterface {
+	args := c.Called(project)
+	return args.Get(0).(v1.RoleBindingInterface)
+}
+
+func (d *MockRoleBinding) Get(name string) (*modelv1.RoleBinding, error) {
+	args := d.Called(name)
+	return args.Get(0).(*modelv1.RoleBinding), args.Error(1)
+}
+
+func (d *MockRoleBinding) Update(roleBinding *modelv1.RoleBinding) (*modelv1.RoleBinding, error) {
+	args := d.Called(roleBinding)
+	return args.Get(0).(*modelv1.RoleBinding), args.Error(1)
+}
+
+func (d *MockRoleBinding) Delete(name string) error {
+	args := d.Called(name)
+	return args.Error(0)
+}
+
+func (d *MockRoleBinding) Create(roleBinding *modelv1.RoleBinding) (*modelv1.RoleBinding, error) {
+	args := d.Called(roleBinding)
+	return args.Get(0).(*modelv1.RoleBinding), args.Error(1)
+}
+
+type MockGlobalRole struct {
+	v1.GlobalRoleInterface
+	mock.Mock
+}
+
+func (c *MockClient) GlobalRole() v1.GlobalRoleInterface {
+	args := c.Called()
+	return args.Get(0).(v1.GlobalRoleInterface)
+}
+
+func (d *MockGlobalRole) Get(name string) (*modelv1.GlobalRole, error) {
+	args := d.Called(name)
+	return args.Get(0).(*modelv1.GlobalRole), args.Error(1)
+}
+
+func (d *MockGlobalRole) Update(role *modelv1.GlobalRole) (*modelv1.GlobalRole, error) {
+	args := d.Called(role)
+	return args.Get(0).(*modelv1.GlobalRole), args.Error(1)
+}
+
+func (d *MockGlobalRole) Delete(name string) error {
+	args := d.Called(name)
+	return args.Error(0)
+}
+
+func (d *MockGlobalRole) Create(role *modelv1.GlobalRole) (*modelv1.GlobalRole, error) {
+	args := d.Called(role)
+	return args.Get(0).(*modelv1.GlobalRole), args.Error(1)
+}
+
+type MockGlobalRoleBinding struct {
+	v1.GlobalRoleBindingInterface
+	mock.Mock
+}
+
+func (c *MockClient) GlobalRoleBinding() v1.GlobalRoleBindingInterface {
+	args := c.Called()
+	return args.Get(0).(v1.GlobalRoleBindingInterface)
+}
+
+func (d *MockGlobalRoleBinding) Get(name string) (*modelv1.GlobalRoleBinding, error) {
+	args := d.Called(name)
+	return args.Get(0).(*modelv1.GlobalRoleBinding), args.Error(1)
+}
+
+func (d *MockGlobalRoleBinding) Update(roleBinding *modelv1.GlobalRoleBinding) (*modelv1.GlobalRoleBinding, error) {
+	args := d.Called(roleBinding)
+	return args.Get(0).(*modelv1.GlobalRoleBinding), args.Error(1)
+}
+
+func (d *MockGlobalRoleBinding) Delete(name string) error {
+	args := d.Called(name)
+	return args.Error(0)
+}
+
+func (d *MockGlobalRoleBinding) Create(roleBinding *modelv1.GlobalRoleBinding) (*modelv1.GlobalRoleBinding, error) {
+	args := d.Called(roleBinding)
+	return args.Get(0).(*modelv1.GlobalRoleBinding), args.Error(1)
+}
+
 type MockSecret struct {
 	v1.SecretInterface
 	mock.Mock
@@ -196,6 +441,11 @@ func (c *MockSecret) Create(secret *modelv1.Secret) (*modelv1.Secret, error) {
 	return args.Get(0).(*modelv1.Secret), args.Error(1)
 }
 
+func (c *MockSecret) Update(secret *modelv1.Secret) (*modelv1.Secret, error) {
+	args := c.Called(secret)
+	return args.Get(0).(*modelv1.Secret), args.Error(1)
+}
+
 func (c *MockSecret) Get(name string) (*modelv1.Secret, error) {
 	args := c.Called(name)
 	return args.Get(0).(*modelv1.Secret), args.Error(1)
@@ -206,6 +456,11 @@ func (c *MockSecret) Delete(name string) error {
 	return args.Error(0)
 }
 
+func (c *MockSecret) List(prefix string) ([]*modelv1.Secret, error) {
+	args := c.Called(prefix)
+	return args.Get(0).([]*modelv1.Secret), args.Error(1)
+}
+
 type MockGlobalSecret struct {
 	v1.GlobalSecretInterface
 	mock.Mock
@@ -221,6 +476,11 @@ func (c *MockGlobalSecret) Create(secret *modelv1.GlobalSecret) (*modelv1.Global
 	return args.Get(0).(*modelv1.GlobalSecret), args.Error(1)
 }
 
+func (c *MockGlobalSecret) Update(secret *modelv1.GlobalSecret) (*modelv1.GlobalSecret, error) {
+	args := c.Called(secret)
+	return args.Get(0).(*modelv1.GlobalSecret), args.Error(1)
+}
+
 func (c *MockGlobalSecret) Get(name string) (*modelv1.GlobalSecret, error) {
 	args := c.Called(name)
 	return args.Get(0).(*modelv1.GlobalSecret), args.Error(1)
@@ -230,3 +490,8 @@ func (c *MockGlobalSecret) Delete(name string) error {
 	args := c.Called(name)
 	return args.Error(0)
 }
+
+func (c *MockGlobalSecret) List(prefix string) ([]*modelv1.GlobalSecret, error) {
+	args := c.Called(prefix)
+	return args.Get(0).([]*modelv1.GlobalSecret), args.Error(1)
+}
@@ -0,0 +1,40 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perses
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/managedfields"
+	"sigs.k8s.io/structured-merge-diff/v6/typed"
+)
+
+// UnstructuredTypeConverter lets the fake client track the fields of the custom resources
+// holding typed Perses specs, such as variables, which it cannot deduce from the Go types.
+// The objects are converted to their unstructured form before their fields are deduced.
+type UnstructuredTypeConverter struct {
+	managedfields.TypeConverter
+}
+
+func NewUnstructuredTypeConverter() UnstructuredTypeConverter {
+	return UnstructuredTypeConverter{managedfields.NewDeducedTypeConverter()}
+}
+
+func (c UnstructuredTypeConverter) ObjectToTyped(obj runtime.Object, opts ...typed.ValidationOptions) (*typed.TypedValue, error) {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return c.TypeConverter.ObjectToTyped(&unstructured.Unstructured{Object: u}, opts...)
+}
@@ -0,0 +1,57 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the \"License\");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an \"AS IS\" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subreconciler
+
+import (
+	"sync"
+
+	"github.com/perses/perses-operator/internal/perses/common"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// Outcome holds the values returned by a sub-reconciler that reports a condition reason.
+type Outcome struct {
+	Result *ctrl.Result
+	Reason common.ConditionStatusReason
+	Err    error
+}
+
+// Halted reports whether the sub-reconciler asked to halt or requeue.
+func (o Outcome) Halted() bool {
+	return ShouldHaltOrRequeue(o.Result, o.Err)
+}
+
+// ForEach calls fn for every item, with at most concurrency calls running at a time,
+// and returns the outcomes in the order of the items. Every item is processed, whatever
+// the outcome of the others. A concurrency lower than 1 processes one item at a time.
+func ForEach[T any](items []T, concurrency int, fn func(T) (*ctrl.Result, common.ConditionStatusReason, error)) []Outcome {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	outcomes := make([]Outcome, len(items))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, item := range items {
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			res, reason, err := fn(item)
+			outcomes[i] = Outcome{Result: res, Reason: reason, Err: err}
+		})
+	}
+	wg.Wait()
+
+	return outcomes
+}
@@ -0,0 +1,71 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subreconciler
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/perses/perses-operator/internal/perses/common"
+)
+
+func TestForEach(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	items := []int{0, 1, 2, 3, 4, 5, 6, 7}
+
+	outcomes := ForEach(items, 3, func(item int) (*ctrl.Result, common.ConditionStatusReason, error) {
+		current := running.Add(1)
+		defer running.Add(-1)
+		for {
+			previous := maxRunning.Load()
+			if current <= previous || maxRunning.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		if item%2 == 1 {
+			return RequeueWithErrorAndReason(fmt.Errorf("item %d failed", item), common.ReasonBackendError)
+		}
+		res, err := ContinueReconciling()
+		return res, "", err
+	})
+
+	assert.LessOrEqual(t, maxRunning.Load(), int32(3))
+	assert.Len(t, outcomes, len(items))
+	for i, outcome := range outcomes {
+		if i%2 == 1 {
+			assert.True(t, outcome.Halted())
+			assert.Equal(t, common.ReasonBackendError, outcome.Reason)
+			assert.EqualError(t, outcome.Err, fmt.Sprintf("item %d failed", i))
+		} else {
+			assert.False(t, outcome.Halted())
+		}
+	}
+}
+
+func TestForEachWithoutConcurrency(t *testing.T) {
+	var order []int
+	ForEach([]int{0, 1, 2}, 0, func(item int) (*ctrl.Result, common.ConditionStatusReason, error) {
+		order = append(order, item)
+		res, err := ContinueReconciling()
+		return res, "", err
+	})
+	assert.Equal(t, []int{0, 1, 2}, order)
+}
@@ -31,6 +31,15 @@ function(params) {
   '0persesdashboardsCustomResourceDefinition': import 'generated/perses.dev_persesdashboards-crd.json',
   '0persesdatasourcesCustomResourceDefinition': import 'generated/perses.dev_persesdatasources-crd.json',
   '0persesglobaldatasourcesCustomResourceDefinition': import 'generated/perses.dev_persesglobaldatasources-crd.json',
+  '0persesprojectsCustomResourceDefinition': import 'generated/perses.dev_persesprojects-crd.json',
+  '0persesvariablesCustomResourceDefinition': import 'generated/perses.dev_persesvariables-crd.json',
+  '0persesglobalvariablesCustomResourceDefinition': import 'generated/perses.dev_persesglobalvariables-crd.json',
+  '0persesrolesCustomResourceDefinition': import 'generated/perses.dev_persesroles-crd.json',
+  '0persesrolebindingsCustomResourceDefinition': import 'generated/perses.dev_persesrolebindings-crd.json',
+  '0persesglobalrolesCustomResourceDefinition': import 'generated/perses.dev_persesglobalroles-crd.json',
+  '0persesglobalrolebindingsCustomResourceDefinition': import 'generated/perses.dev_persesglobalrolebindings-crd.json',
+  '0persessecretsCustomResourceDefinition': import 'generated/perses.dev_persessecrets-crd.json',
+  '0persesglobalsecretsCustomResourceDefinition': import 'generated/perses.dev_persesglobalsecrets-crd.json',
 
   local deployment_gen = import 'generated/manager.json',
   local service_account_gen = import 'generated/service_account.json',
@@ -42,6 +51,24 @@ function(params) {
   local persesdatasource_editor_role_gen = import 'generated/persesdatasource_editor_role.json',
   local persesglobaldatasource_viewer_role_gen = import 'generated/persesglobaldatasource_viewer_role.json',
   local persesglobaldatasource_editor_role_gen = import 'generated/persesglobaldatasource_editor_role.json',
+  local persesproject_viewer_role_gen = import 'generated/persesproject_viewer_role.json',
+  local persesproject_editor_role_gen = import 'generated/persesproject_editor_role.json',
+  local persesvariable_viewer_role_gen = import 'generated/persesvariable_viewer_role.json',
+  local persesvariable_editor_role_gen = import 'generated/persesvariable_editor_role.json',
+  local persesglobalvariable_viewer_role_gen = import 'generated/persesglobalvariable_viewer_role.json',
+  local persesglobalvariable_editor_role_gen = import 'generated/persesglobalvariable_editor_role.json',
+  local persesrole_viewer_role_gen = import 'generated/persesrole_viewer_role.json',
+  local persesrole_editor_role_gen = import 'generated/persesrole_editor_role.json',
+  local persesrolebinding_viewer_role_gen = import 'generated/persesrolebinding_viewer_role.json',
+  local persesrolebinding_editor_role_gen = import 'generated/persesrolebinding_editor_role.json',
+  local persesglobalrole_viewer_role_gen = import 'generated/persesglobalrole_viewer_role.json',
+  local persesglobalrole_editor_role_gen = import 'generated/persesglobalrole_editor_role.json',
+  local persesglobalrolebinding_viewer_role_gen = import 'generated/persesglobalrolebinding_viewer_role.json',
+  local persesglobalrolebinding_editor_role_gen = import 'generated/persesglobalrolebinding_editor_role.json',
+  local persessecret_viewer_role_gen = import 'generated/persessecret_viewer_role.json',
+  local persessecret_editor_role_gen = import 'generated/persessecret_editor_role.json',
+  local persesglobalsecret_viewer_role_gen = import 'generated/persesglobalsecret_viewer_role.json',
+  local persesglobalsecret_editor_role_gen = import 'generated/persesglobalsecret_editor_role.json',
   local leader_election_role_gen = import 'generated/leader_election_role.json',
   local leader_election_role_binding_gen = import 'generated/leader_election_role_binding.json',
   local role_binding_gen = import 'generated/role_binding.json',
@@ -161,6 +188,186 @@ function(params) {
     },
   },
 
+  persesProjectEditorRole: persesproject_editor_role_gen {
+    metadata+: {
+      name: 'persesproject-editor-role',
+      labels: po.config.commonLabels {
+        'app.kubernetes.io/component': 'rbac',
+        'app.kubernetes.io/instance': 'persesproject-editor-role',
+      },
+    },
+  },
+
+  persesProjectViewerRole: persesproject_viewer_role_gen {
+    metadata+: {
+      name: 'persesproject-viewer-role',
+      labels: po.config.commonLabels {
+        'app.kubernetes.io/component': 'rbac',
+        'app.kubernetes.io/instance': 'persesproject-viewer-role',
+      },
+    },
+  },
+
+  persesVariableEditorRole: persesvariable_editor_role_gen {
+    metadata+: {
+      name: 'persesvariable-editor-role',
+      labels: po.config.commonLabels {
+        'app.kubernetes.io/component': 'rbac',
+        'app.kubernetes.io/instance': 'persesvariable-editor-role',
+      },
+    },
+  },
+
+  persesVariableViewerRole: persesvariable_viewer_role_gen {
+    metadata+: {
+      name: 'persesvariable-viewer-role',
+      labels: po.config.commonLabels {
+        'app.kubernetes.io/component': 'rbac',
+        'app.kubernetes.io/instance': 'persesvariable-viewer-role',
+      },
+    },
+  },
+
+  persesGlobalVariableEditorRole: persesglobalvariable_editor_role_gen {
+    metadata+: {
+      name: 'persesglobalvariable-editor-role',
+      labels: po.config.commonLabels {
+        'app.kubernetes.io/component': 'rbac',
+        'app.kubernetes.io/instance': 'persesglobalvariable-editor-role',
+      },
+    },
+  },
+
+  persesGlobalVariableViewerRole: persesglobalvariable_viewer_role_gen {
+    metadata+: {
+      name: 'persesglobalvariable-viewer-role',
+      labels: po.config.commonLabels {
+        'app.kubernetes.io/component': 'rbac',
+        'app.kubernetes.io/instance': 'persesglobalvariable-viewer-role',
+      },
+    },
+  },
+
+  persesRoleEditorRole: persesrole_editor_role_gen {
+    metadata+: {
+      name: 'persesrole-editor-role',
+      labels: po.config.commonLabels {
+        'app.kubernetes.io/component': 'rbac',
+        'app.kubernetes.io/instance': 'persesrole-editor-role',
+      },
+    },
+  },
+
+  persesRoleViewerRole: persesrole_viewer_role_gen {
+    metadata+: {
+      name: 'persesrole-viewer-role',
+      labels: po.config.commonLabels {
+        'app.kubernetes.io/component': 'rbac',
+        'app.kubernetes.io/instance': 'persesrole-viewer-role',
+      },
+    },
+  },
+
+  persesRoleBindingEditorRole: persesrolebinding_editor_role_gen {
+    metadata+: {
+      name: 'persesrolebinding-editor-role',
+      labels: po.config.commonLabels {
+        'app.kubernetes.io/component': 'rbac',
+        'app.kubernetes.io/instance': 'persesrolebinding-editor-role',
+      },
+    },
+  },
+
+  persesRoleBindingViewerRole: persesrolebinding_viewer_role_gen {
+    metadata+: {
+      name: 'persesrolebinding-viewer-role',
+      labels: po.config.commonLabels {
+        'app.kubernetes.io/component': 'rbac',
+        'app.kubernetes.io/instance': 'persesrolebinding-viewer-role',
+      },
+    },
+  },
+
+  persesGlobalRoleEditorRole: persesglobalrole_editor_role_gen {
+    metadata+: {
+      name: 'persesglobalrole-editor-role',
+      labels: po.config.commonLabels {
+        'app.kubernetes.io/component': 'rbac',
+        'app.kubernetes.io/instance': 'persesglobalrole-editor-role',
+      },
+    },
+  },
+
+  persesGlobalRoleViewerRole: persesglobalrole_viewer_role_gen {
+    metadata+: {
+      name: 'persesglobalrole-viewer-role',
+      labels: po.config.commonLabels {
+        'app.kubernetes.io/component': 'rbac',
+        'app.kubernetes.io/instance': 'persesglobalrole-viewer-role',
+      },
+    },
+  },
+
+  persesGlobalRoleBindingEditorRole: persesglobalrolebinding_editor_role_gen {
+    metadata+: {
+      name: 'persesglobalrolebinding-editor-role',
+      labels: po.config.commonLabels {
+        'app.kubernetes.io/component': 'rbac',
+        'app.kubernetes.io/instance': 'persesglobalrolebinding-editor-role',
+      },
+    },
+  },
+
+  persesGlobalRoleBindingViewerRole: persesglobalrolebinding_viewer_role_gen {
+    metadata+: {
+      name: 'persesglobalrolebinding-viewer-role',
+      labels: po.config.commonLabels {
+        'app.kubernetes.io/component': 'rbac',
+        'app.kubernetes.io/instance': 'persesglobalrolebinding-viewer-role',
+      },
+    },
+  },
+
+  persesSecretEditorRole: persessecret_editor_role_gen {
+    metadata+: {
+      name: 'persessecret-editor-role',
+      labels: po.config.commonLabels {
+        'app.kubernetes.io/component': 'rbac',
+        'app.kubernetes.io/instance': 'persessecret-editor-role',
+      },
+    },
+  },
+
+  persesSecretViewerRole: persessecret_viewer_role_gen {
+    metadata+: {
+      name: 'persessecret-viewer-role',
+      labels: po.config.commonLabels {
+        'app.kubernetes.io/component': 'rbac',
+        'app.kubernetes.io/instance': 'persessecret-viewer-role',
+      },
+    },
+  },
+
+  persesGlobalSecretEditorRole: persesglobalsecret_editor_role_gen {
+    metadata+: {
+      name: 'persesglobalsecret-editor-role',
+      labels: po.config.commonLabels {
+        'app.kubernetes.io/component': 'rbac',
+        'app.kubernetes.io/instance': 'persesglobalsecret-editor-role',
+      },
+    },
+  },
+
+  persesGlobalSecretViewerRole: persesglobalsecret_viewer_role_gen {
+    metadata+: {
+      name: 'persesglobalsecret-viewer-role',
+      labels: po.config.commonLabels {
+        'app.kubernetes.io/component': 'rbac',
+        'app.kubernetes.io/instance': 'persesglobalsecret-viewer-role',
+      },
+    },
+  },
+
   roleBinding: role_binding_gen {
     metadata+: {
       name: po.config.name,
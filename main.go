@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -27,30 +28,45 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	k8sapiflag "k8s.io/component-base/cli/flag"
 	ctrl "sigs.k8s.io/controller-runtime"
 
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	configv1 "github.com/openshift/api/config/v1"
+	routev1 "github.com/openshift/api/route/v1"
 	persesv1alpha1 "github.com/perses/perses-operator/api/v1alpha1"
 	persesv1alpha2 "github.com/perses/perses-operator/api/v1alpha2"
 	dashboardcontroller "github.com/perses/perses-operator/controllers/dashboards"
 	datasourcecontroller "github.com/perses/perses-operator/controllers/datasources"
 	globaldatasourcecontroller "github.com/perses/perses-operator/controllers/globaldatasources"
+	globalrolebindingcontroller "github.com/perses/perses-operator/controllers/globalrolebindings"
+	globalrolecontroller "github.com/perses/perses-operator/controllers/globalroles"
+	globalsecretcontroller "github.com/perses/perses-operator/controllers/globalsecrets"
+	globalvariablecontroller "github.com/perses/perses-operator/controllers/globalvariables"
+	orphancontroller "github.com/perses/perses-operator/controllers/orphans"
 	persescontroller "github.com/perses/perses-operator/controllers/perses"
+	projectcontroller "github.com/perses/perses-operator/controllers/projects"
+	rolebindingcontroller "github.com/perses/perses-operator/controllers/rolebindings"
+	rolecontroller "github.com/perses/perses-operator/controllers/roles"
+	secretcontroller "github.com/perses/perses-operator/controllers/secrets"
+	variablecontroller "github.com/perses/perses-operator/controllers/variables"
 	internalcache "github.com/perses/perses-operator/internal/cache"
 	operatormetrics "github.com/perses/perses-operator/internal/metrics"
 	internalopenshift "github.com/perses/perses-operator/internal/openshift"
 	"github.com/perses/perses-operator/internal/operator"
 	"github.com/perses/perses-operator/internal/perses/common"
 	operatortls "github.com/perses/perses-operator/internal/tls"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -83,6 +99,12 @@ func main() {
 	var tlsCipherSuites string
 	var tlsClusterProfile bool
 	var tlsConfigureOperands bool
+	var orphanGCInterval time.Duration
+	var orphanGCDryRun bool
+	var resyncPeriod time.Duration
+	var verificationPeriod time.Duration
+	var instanceSyncConcurrency int
+	var dryRun bool
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8082", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -106,6 +128,18 @@ func main() {
 			"Watches for changes and restarts the operator. Requires an OpenShift cluster.")
 	flag.BoolVar(&tlsConfigureOperands, common.TLSConfigureOperandsFlag, false,
 		"Propagate TLS settings to managed Perses pods. Without this flag, TLS only applies to the operator itself.")
+	flag.DurationVar(&orphanGCInterval, common.OrphanGCIntervalFlag, 0,
+		"Interval between two removals of the operator-managed dashboards, datasources and secrets left in Perses without a custom resource. 0 disables it.")
+	flag.BoolVar(&orphanGCDryRun, common.OrphanGCDryRunFlag, false,
+		"Only log and count the orphans found in Perses, without removing them.")
+	flag.DurationVar(&resyncPeriod, common.ResyncPeriodFlag, 0,
+		"Period after which dashboards, datasources and global datasources are synced again to correct changes made directly in Perses. 0 disables it.")
+	flag.DurationVar(&verificationPeriod, common.VerificationPeriodFlag, 10*time.Minute,
+		"Period during which a dashboard, datasource or global datasource already applied to a Perses instance is not compared with it again, unless its spec or tags change. 0 compares it on every reconciliation.")
+	flag.IntVar(&instanceSyncConcurrency, common.InstanceSyncConcurrencyFlag, 5,
+		"Maximum number of Perses instances a dashboard, datasource, global datasource, variable, global variable, role or role binding is synced to at the same time.")
+	flag.BoolVar(&dryRun, common.DryRunFlag, false,
+		"Only plan the changes to the Perses workloads and to the resources synced to Perses, recording them as events without applying them. Implies --orphan-gc-dry-run.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -122,7 +156,32 @@ func main() {
 	if watchAllSecrets && watchSecretLabelsFlag != "" {
 		setupLog.Info("--watch-all-secrets is set, --watch-secret-labels will be ignored")
 	}
-	cacheOpts := internalcache.BuildCacheOptions(secretSelector, watchAllSecrets, tlsClusterProfile)
+
+	// The Routes and HTTPRoutes exposing the Perses instances, and the ServiceMonitors and PodMonitors
+	// scraping them, are only managed when their API is served.
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(ctrl.GetConfigOrDie())
+	if err != nil {
+		setupLog.Error(err, "unable to create discovery client")
+		os.Exit(1)
+	}
+	apis, err := operator.DiscoverAPIs(discoveryClient)
+	if err != nil {
+		setupLog.Error(err, "unable to discover the APIs served by the cluster")
+		os.Exit(1)
+	}
+	if apis.Route {
+		utilruntime.Must(routev1.Install(scheme))
+	}
+	if apis.HTTPRoute {
+		utilruntime.Must(gatewayv1.Install(scheme))
+	}
+	if apis.ServiceMonitor || apis.PodMonitor {
+		utilruntime.Must(monitoringv1.AddToScheme(scheme))
+	}
+	setupLog.Info("discovered optional APIs", "route", apis.Route, "httpRoute", apis.HTTPRoute,
+		"serviceMonitor", apis.ServiceMonitor, "podMonitor", apis.PodMonitor)
+
+	cacheOpts := internalcache.BuildCacheOptions(secretSelector, watchAllSecrets, tlsClusterProfile, apis)
 
 	// Parse and validate TLS settings
 	parsedTLSMinVersion, err := operatortls.ParseTLSVersion(tlsMinVersion)
@@ -236,18 +295,35 @@ func main() {
 
 	persesClientFactory := common.NewWithConfig()
 
+	// The manager cache only holds the ConfigMaps created by the operator,
+	// the ConfigMaps referenced by Perses instances and datasources are watched through a dedicated cache.
+	configMapCache, err := cache.New(mgr.GetConfig(), internalcache.BuildWatchedConfigMapCacheOptions(mgr.GetScheme(), mgr.GetRESTMapper()))
+	if err != nil {
+		setupLog.Error(err, "unable to create ConfigMap cache")
+		os.Exit(1)
+	}
+	if err := mgr.Add(configMapCache); err != nil {
+		setupLog.Error(err, "unable to add ConfigMap cache to the manager")
+		os.Exit(1)
+	}
+
 	if err = (&persescontroller.PersesReconciler{
 		Client:                 mgr.GetClient(),
 		APIReader:              mgr.GetAPIReader(),
 		Scheme:                 mgr.GetScheme(),
+		Recorder:               mgr.GetEventRecorderFor("perses-controller"), //nolint:staticcheck
 		Metrics:                opMetrics,
 		ReconciliationTracker:  reconciliationTracker,
 		ClientCacheInvalidator: persesClientFactory,
+		ClientFactory:          persesClientFactory,
+		ConfigMapCache:         configMapCache,
 		Config: persescontroller.Config{
 			PersesImage:          persesImage,
 			TLSMinVersion:        tlsMinVersion,
 			TLSCipherSuites:      tlsCipherSuites,
 			TLSConfigureOperands: tlsConfigureOperands,
+			DryRun:               dryRun,
+			APIs:                 apis,
 		},
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Perses")
@@ -255,41 +331,207 @@ func main() {
 	}
 
 	if err = (&dashboardcontroller.PersesDashboardReconciler{
+		Client:                  mgr.GetClient(),
+		APIReader:               mgr.GetAPIReader(),
+		Scheme:                  mgr.GetScheme(),
+		Recorder:                mgr.GetEventRecorderFor("persesdashboard-controller"), //nolint:staticcheck
+		Metrics:                 opMetrics,
+		ReconciliationTracker:   reconciliationTracker,
+		ClientFactory:           persesClientFactory,
+		ResyncPeriod:            resyncPeriod,
+		VerificationPeriod:      verificationPeriod,
+		InstanceSyncConcurrency: instanceSyncConcurrency,
+		DryRun:                  dryRun,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PersesDashboard")
+		os.Exit(1)
+	}
+
+	if err = (&datasourcecontroller.PersesDatasourceReconciler{
+		Client:                  mgr.GetClient(),
+		APIReader:               mgr.GetAPIReader(),
+		Scheme:                  mgr.GetScheme(),
+		Recorder:                mgr.GetEventRecorderFor("persesdatasource-controller"), //nolint:staticcheck
+		Metrics:                 opMetrics,
+		ReconciliationTracker:   reconciliationTracker,
+		ClientFactory:           persesClientFactory,
+		ResyncPeriod:            resyncPeriod,
+		VerificationPeriod:      verificationPeriod,
+		InstanceSyncConcurrency: instanceSyncConcurrency,
+		DryRun:                  dryRun,
+		ConfigMapCache:          configMapCache,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PersesDatasource")
+		os.Exit(1)
+	}
+
+	if err = (&globaldatasourcecontroller.PersesGlobalDatasourceReconciler{
+		Client:                  mgr.GetClient(),
+		APIReader:               mgr.GetAPIReader(),
+		Scheme:                  mgr.GetScheme(),
+		Recorder:                mgr.GetEventRecorderFor("persesglobaldatasource-controller"), //nolint:staticcheck
+		Metrics:                 opMetrics,
+		ReconciliationTracker:   reconciliationTracker,
+		ClientFactory:           persesClientFactory,
+		ResyncPeriod:            resyncPeriod,
+		VerificationPeriod:      verificationPeriod,
+		InstanceSyncConcurrency: instanceSyncConcurrency,
+		DryRun:                  dryRun,
+		ConfigMapCache:          configMapCache,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PersesGlobalDatasource")
+		os.Exit(1)
+	}
+
+	if err = (&projectcontroller.PersesProjectReconciler{
 		Client:                mgr.GetClient(),
 		APIReader:             mgr.GetAPIReader(),
 		Scheme:                mgr.GetScheme(),
+		Recorder:              mgr.GetEventRecorderFor("persesproject-controller"), //nolint:staticcheck
 		Metrics:               opMetrics,
 		ReconciliationTracker: reconciliationTracker,
 		ClientFactory:         persesClientFactory,
+		DryRun:                dryRun,
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "PersesDashboard")
+		setupLog.Error(err, "unable to create controller", "controller", "PersesProject")
 		os.Exit(1)
 	}
 
-	if err = (&datasourcecontroller.PersesDatasourceReconciler{
+	if err = (&variablecontroller.PersesVariableReconciler{
+		Client:                  mgr.GetClient(),
+		APIReader:               mgr.GetAPIReader(),
+		Scheme:                  mgr.GetScheme(),
+		Recorder:                mgr.GetEventRecorderFor("persesvariable-controller"), //nolint:staticcheck
+		Metrics:                 opMetrics,
+		ReconciliationTracker:   reconciliationTracker,
+		ClientFactory:           persesClientFactory,
+		InstanceSyncConcurrency: instanceSyncConcurrency,
+		DryRun:                  dryRun,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PersesVariable")
+		os.Exit(1)
+	}
+
+	if err = (&globalvariablecontroller.PersesGlobalVariableReconciler{
+		Client:                  mgr.GetClient(),
+		APIReader:               mgr.GetAPIReader(),
+		Scheme:                  mgr.GetScheme(),
+		Recorder:                mgr.GetEventRecorderFor("persesglobalvariable-controller"), //nolint:staticcheck
+		Metrics:                 opMetrics,
+		ReconciliationTracker:   reconciliationTracker,
+		ClientFactory:           persesClientFactory,
+		InstanceSyncConcurrency: instanceSyncConcurrency,
+		DryRun:                  dryRun,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PersesGlobalVariable")
+		os.Exit(1)
+	}
+
+	if err = (&rolecontroller.PersesRoleReconciler{
+		Client:                  mgr.GetClient(),
+		APIReader:               mgr.GetAPIReader(),
+		Scheme:                  mgr.GetScheme(),
+		Recorder:                mgr.GetEventRecorderFor("persesrole-controller"), //nolint:staticcheck
+		Metrics:                 opMetrics,
+		ReconciliationTracker:   reconciliationTracker,
+		ClientFactory:           persesClientFactory,
+		InstanceSyncConcurrency: instanceSyncConcurrency,
+		DryRun:                  dryRun,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PersesRole")
+		os.Exit(1)
+	}
+
+	if err = (&rolebindingcontroller.PersesRoleBindingReconciler{
+		Client:                  mgr.GetClient(),
+		APIReader:               mgr.GetAPIReader(),
+		Scheme:                  mgr.GetScheme(),
+		Recorder:                mgr.GetEventRecorderFor("persesrolebinding-controller"), //nolint:staticcheck
+		Metrics:                 opMetrics,
+		ReconciliationTracker:   reconciliationTracker,
+		ClientFactory:           persesClientFactory,
+		InstanceSyncConcurrency: instanceSyncConcurrency,
+		DryRun:                  dryRun,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PersesRoleBinding")
+		os.Exit(1)
+	}
+
+	if err = (&globalrolecontroller.PersesGlobalRoleReconciler{
+		Client:                  mgr.GetClient(),
+		APIReader:               mgr.GetAPIReader(),
+		Scheme:                  mgr.GetScheme(),
+		Recorder:                mgr.GetEventRecorderFor("persesglobalrole-controller"), //nolint:staticcheck
+		Metrics:                 opMetrics,
+		ReconciliationTracker:   reconciliationTracker,
+		ClientFactory:           persesClientFactory,
+		InstanceSyncConcurrency: instanceSyncConcurrency,
+		DryRun:                  dryRun,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PersesGlobalRole")
+		os.Exit(1)
+	}
+
+	if err = (&globalrolebindingcontroller.PersesGlobalRoleBindingReconciler{
+		Client:                  mgr.GetClient(),
+		APIReader:               mgr.GetAPIReader(),
+		Scheme:                  mgr.GetScheme(),
+		Recorder:                mgr.GetEventRecorderFor("persesglobalrolebinding-controller"), //nolint:staticcheck
+		Metrics:                 opMetrics,
+		ReconciliationTracker:   reconciliationTracker,
+		ClientFactory:           persesClientFactory,
+		InstanceSyncConcurrency: instanceSyncConcurrency,
+		DryRun:                  dryRun,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PersesGlobalRoleBinding")
+		os.Exit(1)
+	}
+
+	if err = (&secretcontroller.PersesSecretReconciler{
 		Client:                mgr.GetClient(),
 		APIReader:             mgr.GetAPIReader(),
 		Scheme:                mgr.GetScheme(),
+		Recorder:              mgr.GetEventRecorderFor("persessecret-controller"), //nolint:staticcheck
 		Metrics:               opMetrics,
 		ReconciliationTracker: reconciliationTracker,
 		ClientFactory:         persesClientFactory,
+		DryRun:                dryRun,
+		ConfigMapCache:        configMapCache,
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "PersesDatasource")
+		setupLog.Error(err, "unable to create controller", "controller", "PersesSecret")
 		os.Exit(1)
 	}
 
-	if err = (&globaldatasourcecontroller.PersesGlobalDatasourceReconciler{
+	if err = (&globalsecretcontroller.PersesGlobalSecretReconciler{
 		Client:                mgr.GetClient(),
 		APIReader:             mgr.GetAPIReader(),
 		Scheme:                mgr.GetScheme(),
+		Recorder:              mgr.GetEventRecorderFor("persesglobalsecret-controller"), //nolint:staticcheck
 		Metrics:               opMetrics,
 		ReconciliationTracker: reconciliationTracker,
 		ClientFactory:         persesClientFactory,
+		DryRun:                dryRun,
+		ConfigMapCache:        configMapCache,
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "PersesGlobalDatasource")
+		setupLog.Error(err, "unable to create controller", "controller", "PersesGlobalSecret")
 		os.Exit(1)
 	}
 
+	if orphanGCInterval > 0 {
+		if err = (&orphancontroller.Sweeper{
+			APIReader:     mgr.GetAPIReader(),
+			ClientFactory: persesClientFactory,
+			Metrics:       opMetrics,
+			Interval:      orphanGCInterval,
+			DryRun:        orphanGCDryRun || dryRun,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create orphan sweeper")
+			os.Exit(1)
+		}
+	} else if orphanGCDryRun {
+		setupLog.Info("--orphan-gc-dry-run is set but --orphan-gc-interval is 0; the orphan sweeper is disabled")
+	}
+
 	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
 		if err = (&persesv1alpha1.Perses{}).SetupWebhookWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "Perses")
@@ -322,6 +564,15 @@ func main() {
 	opMetrics.Ready("persesdashboard").Set(1)
 	opMetrics.Ready("persesdatasource").Set(1)
 	opMetrics.Ready("persesglobaldatasource").Set(1)
+	opMetrics.Ready("persesproject").Set(1)
+	opMetrics.Ready("persesvariable").Set(1)
+	opMetrics.Ready("persesglobalvariable").Set(1)
+	opMetrics.Ready("persesrole").Set(1)
+	opMetrics.Ready("persesrolebinding").Set(1)
+	opMetrics.Ready("persesglobalrole").Set(1)
+	opMetrics.Ready("persesglobalrolebinding").Set(1)
+	opMetrics.Ready("persessecret").Set(1)
+	opMetrics.Ready("persesglobalsecret").Set(1)
 
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctx); err != nil {
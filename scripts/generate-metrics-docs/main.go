@@ -63,7 +63,7 @@ func parseMetrics() []Metric {
 		{
 			Name:   "perses_operator_managed_resources",
 			Type:   "Gauge",
-			Help:   "Number of resources managed by the operator per state (synced/failed)",
+			Help:   "Number of resources managed by the operator per state (synced/failed/suspended)",
 			Labels: []string{"resource", "state", "resource_namespace"},
 		},
 		{
@@ -90,6 +90,20 @@ func parseMetrics() []Metric {
 			Help:   "Whether the operator is ready (1=yes, 0=no)",
 			Labels: []string{"controller"},
 		},
+		{
+			Name:        "perses_operator_orphans_pruned_total",
+			Type:        "Counter",
+			Help:        "Total number of operator-managed objects removed from Perses because no custom resource backs them anymore",
+			Labels:      []string{"resource", "dry_run"},
+			Description: "Incremented by the orphan sweeper enabled with `--orphan-gc-interval`. With `--orphan-gc-dry-run`, the orphans are counted with `dry_run=\"true\"` at every sweep, without being removed.",
+		},
+		{
+			Name:        "perses_operator_drift_corrections_total",
+			Type:        "Counter",
+			Help:        "Total number of objects modified directly in Perses and restored from their custom resource",
+			Labels:      []string{"resource"},
+			Description: "Incremented once per Perses instance in which a dashboard, datasource or global datasource already synced at its current generation was found modified and restored. Drift is checked on every reconciliation, and periodically with `--resync-period` or `spec.resyncPeriodSeconds`.",
+		},
 	}
 
 	return metrics